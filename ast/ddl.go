@@ -15,6 +15,7 @@ package ast
 
 import (
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -23,6 +24,7 @@ var (
 	_ DDLNode = &CreateDatabaseStmt{}
 	_ DDLNode = &CreateIndexStmt{}
 	_ DDLNode = &CreateTableStmt{}
+	_ DDLNode = &CreateViewStmt{}
 	_ DDLNode = &DropDatabaseStmt{}
 	_ DDLNode = &DropIndexStmt{}
 	_ DDLNode = &DropTableStmt{}
@@ -100,11 +102,13 @@ func (n *DropDatabaseStmt) Accept(v Visitor) (Node, bool) {
 }
 
 // IndexColName is used for parsing index column name from SQL.
+// For the expression key part like `INDEX idx((a + 1))`, Column is nil and Expr is set.
 type IndexColName struct {
 	node
 
 	Column *ColumnName
 	Length int
+	Expr   ExprNode
 }
 
 // Accept implements Node Accept interface.
@@ -114,11 +118,20 @@ func (n *IndexColName) Accept(v Visitor) (Node, bool) {
 		return v.Leave(newNode)
 	}
 	n = newNode.(*IndexColName)
-	node, ok := n.Column.Accept(v)
-	if !ok {
-		return n, false
+	if n.Column != nil {
+		node, ok := n.Column.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Column = node.(*ColumnName)
+	}
+	if n.Expr != nil {
+		node, ok := n.Expr.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Expr = node.(ExprNode)
 	}
-	n.Column = node.(*ColumnName)
 	return v.Leave(n)
 }
 
@@ -309,6 +322,7 @@ const (
 	ConstraintUniqIndex
 	ConstraintForeignKey
 	ConstraintFulltext
+	ConstraintSpatial
 )
 
 // Constraint is constraint for table definition.
@@ -398,6 +412,7 @@ type CreateTableStmt struct {
 	Cols        []*ColumnDef
 	Constraints []*Constraint
 	Options     []*TableOption
+	Partition   *PartitionOptions
 }
 
 // Accept implements Node Accept interface.
@@ -443,6 +458,8 @@ type DropTableStmt struct {
 
 	IfExists bool
 	Tables   []*TableName
+	// IsView is true if it's a DROP VIEW statement.
+	IsView bool
 }
 
 // Accept implements Node Accept interface.
@@ -462,6 +479,46 @@ func (n *DropTableStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// CreateViewStmt is a statement to create a view.
+// See https://dev.mysql.com/doc/refman/5.7/en/create-view.html
+type CreateViewStmt struct {
+	ddlNode
+
+	OrReplace bool
+	ViewName  *TableName
+	Cols      []model.CIStr
+	Select    StmtNode
+	Algorithm model.ViewAlgorithm
+	// Definer is nil if it's not specified or it's CURRENT_USER.
+	Definer     *auth.UserIdentity
+	Security    model.ViewSecurity
+	CheckOption model.ViewCheckOption
+
+	// SchemaCols is the columns of the view, it's filled by the plan builder
+	// with the names and the types of the select fields.
+	SchemaCols []*model.ColumnInfo
+}
+
+// Accept implements Node Accept interface.
+func (n *CreateViewStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CreateViewStmt)
+	node, ok := n.ViewName.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.ViewName = node.(*TableName)
+	node, ok = n.Select.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Select = node.(StmtNode)
+	return v.Leave(n)
+}
+
 // RenameTableStmt is a statement to rename a table.
 // See http://dev.mysql.com/doc/refman/5.7/en/rename-table.html
 type RenameTableStmt struct {
@@ -538,6 +595,7 @@ type CreateIndexStmt struct {
 	IndexName     string
 	Table         *TableName
 	Unique        bool
+	Spatial       bool
 	IndexColNames []*IndexColName
 	IndexOption   *IndexOption
 }
@@ -637,6 +695,24 @@ type TableOption struct {
 	UintValue uint64
 }
 
+// PartitionDefinition defines a single partition.
+type PartitionDefinition struct {
+	Name model.CIStr
+	// LessThan is the values of VALUES LESS THAN, it is empty if MaxValue is true.
+	LessThan []ExprNode
+	MaxValue bool
+	// InValues is the values of VALUES IN for the LIST partition.
+	InValues []ExprNode
+}
+
+// PartitionOptions specifies the partition options.
+type PartitionOptions struct {
+	Tp          model.PartitionType
+	Expr        ExprNode
+	Num         uint64
+	Definitions []*PartitionDefinition
+}
+
 // ColumnPositionType is the type for ColumnPosition.
 type ColumnPositionType int
 
@@ -690,6 +766,9 @@ const (
 	AlterTableRenameTable
 	AlterTableAlterColumn
 	AlterTableLock
+	AlterTableAddPartitions
+	AlterTableDropPartition
+	AlterTableTruncatePartition
 
 // TODO: Add more actions
 )
@@ -719,6 +798,8 @@ type AlterTableSpec struct {
 	OldColumnName *ColumnName
 	Position      *ColumnPosition
 	LockType      LockType
+	// PartDefinitions is the partitions to add by ALTER TABLE ADD PARTITION.
+	PartDefinitions []*PartitionDefinition
 }
 
 // Accept implements Node Accept interface.
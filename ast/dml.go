@@ -30,6 +30,7 @@ var (
 
 	_ Node = &Assignment{}
 	_ Node = &ByItem{}
+	_ Node = &CommonTableExpression{}
 	_ Node = &FieldList{}
 	_ Node = &GroupByClause{}
 	_ Node = &HavingClause{}
@@ -43,6 +44,7 @@ var (
 	_ Node = &TableSource{}
 	_ Node = &UnionSelectList{}
 	_ Node = &WildCardField{}
+	_ Node = &WithClause{}
 )
 
 // JoinType is join type, including cross/left/right/full.
@@ -115,6 +117,8 @@ type TableName struct {
 
 	DBInfo    *model.DBInfo
 	TableInfo *model.TableInfo
+	// CTE is set by the resolver when the table name refers to a common table expression.
+	CTE *CommonTableExpression
 
 	IndexHints []*IndexHint
 }
@@ -456,12 +460,67 @@ func (n *OrderByClause) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// CommonTableExpression is a named temporary result set defined in a WITH clause.
+// See https://dev.mysql.com/doc/refman/8.0/en/with.html
+type CommonTableExpression struct {
+	node
+
+	Name        model.CIStr
+	ColNameList []model.CIStr
+	Query       *SubqueryExpr
+	// IsRecursive is true if the CTE is defined in a WITH RECURSIVE clause.
+	// Such a CTE can refer to itself in its query.
+	IsRecursive bool
+}
+
+// Accept implements Node Accept interface.
+func (n *CommonTableExpression) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CommonTableExpression)
+	node, ok := n.Query.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Query = node.(*SubqueryExpr)
+	return v.Leave(n)
+}
+
+// WithClause is the WITH clause of a SELECT or UNION statement.
+type WithClause struct {
+	node
+
+	IsRecursive bool
+	CTEs        []*CommonTableExpression
+}
+
+// Accept implements Node Accept interface.
+func (n *WithClause) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*WithClause)
+	for i, cte := range n.CTEs {
+		node, ok := cte.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.CTEs[i] = node.(*CommonTableExpression)
+	}
+	return v.Leave(n)
+}
+
 // SelectStmt represents the select query node.
 // See https://dev.mysql.com/doc/refman/5.7/en/select.html
 type SelectStmt struct {
 	dmlNode
 	resultSetNode
 
+	// With is the WITH clause of the query.
+	With *WithClause
 	// SelectStmtOpts wraps around select hints and switches.
 	*SelectStmtOpts
 	// Distinct represents whether the select has distinct option.
@@ -494,6 +553,14 @@ func (n *SelectStmt) Accept(v Visitor) (Node, bool) {
 	}
 
 	n = newNode.(*SelectStmt)
+	if n.With != nil {
+		node, ok := n.With.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.With = node.(*WithClause)
+	}
+
 	if n.TableHints != nil && len(n.TableHints) != 0 {
 		newHints := make([]*TableOptimizerHint, len(n.TableHints))
 		for i, hint := range n.TableHints {
@@ -595,6 +662,7 @@ type UnionStmt struct {
 	dmlNode
 	resultSetNode
 
+	With       *WithClause
 	Distinct   bool
 	SelectList *UnionSelectList
 	OrderBy    *OrderByClause
@@ -608,6 +676,13 @@ func (n *UnionStmt) Accept(v Visitor) (Node, bool) {
 		return v.Leave(newNode)
 	}
 	n = newNode.(*UnionStmt)
+	if n.With != nil {
+		node, ok := n.With.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.With = node.(*WithClause)
+	}
 	if n.SelectList != nil {
 		node, ok := n.SelectList.Accept(v)
 		if !ok {
@@ -959,6 +1034,7 @@ const (
 	ShowIndex
 	ShowProcessList
 	ShowCreateDatabase
+	ShowCreateView
 	ShowEvents
 	ShowStatsMeta
 	ShowStatsHistograms
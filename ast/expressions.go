@@ -556,6 +556,8 @@ func (n *PatternLikeExpr) Accept(v Visitor) (Node, bool) {
 type ParamMarkerExpr struct {
 	exprNode
 	Offset int
+	// Order is the position of the parameter marker among all the parameter markers in the statement.
+	Order int
 }
 
 // Accept implements Node Accept interface.
@@ -35,6 +35,8 @@ func (f *flagSetter) Leave(in Node) (Node, bool) {
 	switch x := in.(type) {
 	case *AggregateFuncExpr:
 		f.aggregateFunc(x)
+	case *WindowFuncExpr:
+		f.windowFunc(x)
 	case *BetweenExpr:
 		x.SetFlag(x.Expr.GetFlag() | x.Left.GetFlag() | x.Right.GetFlag())
 	case *BinaryOperationExpr:
@@ -154,3 +156,17 @@ func (f *flagSetter) aggregateFunc(x *AggregateFuncExpr) {
 	}
 	x.SetFlag(flag)
 }
+
+func (f *flagSetter) windowFunc(x *WindowFuncExpr) {
+	flag := FlagHasFunc
+	for _, val := range x.Args {
+		flag |= val.GetFlag()
+	}
+	for _, item := range x.Spec.PartitionBy {
+		flag |= item.Expr.GetFlag()
+	}
+	for _, item := range x.Spec.OrderBy {
+		flag |= item.Expr.GetFlag()
+	}
+	x.SetFlag(flag)
+}
@@ -20,6 +20,7 @@ import (
 
 var (
 	_ FuncNode = &AggregateFuncExpr{}
+	_ FuncNode = &WindowFuncExpr{}
 	_ FuncNode = &FuncCallExpr{}
 	_ FuncNode = &FuncCastExpr{}
 )
@@ -62,6 +63,7 @@ const (
 	RowFunc    = "row"
 	SetVar     = "setvar"
 	GetVar     = "getvar"
+	GetParam   = "getparam"
 	Values     = "values"
 	BitCount   = "bit_count"
 
@@ -282,18 +284,41 @@ const (
 	ValidatePasswordStrength = "validate_password_strength"
 
 	// json functions
-	JSONType     = "json_type"
-	JSONExtract  = "json_extract"
-	JSONUnquote  = "json_unquote"
-	JSONArray    = "json_array"
-	JSONObject   = "json_object"
-	JSONMerge    = "json_merge"
-	JSONValid    = "json_valid"
-	JSONSet      = "json_set"
-	JSONInsert   = "json_insert"
-	JSONReplace  = "json_replace"
-	JSONRemove   = "json_remove"
-	JSONContains = "json_contains"
+	JSONType         = "json_type"
+	JSONExtract      = "json_extract"
+	JSONUnquote      = "json_unquote"
+	JSONArray        = "json_array"
+	JSONObject       = "json_object"
+	JSONMerge        = "json_merge"
+	JSONValid        = "json_valid"
+	JSONSet          = "json_set"
+	JSONInsert       = "json_insert"
+	JSONReplace      = "json_replace"
+	JSONRemove       = "json_remove"
+	JSONContains     = "json_contains"
+	JSONContainsPath = "json_contains_path"
+	JSONKeys         = "json_keys"
+	JSONLength       = "json_length"
+	JSONDepth        = "json_depth"
+	JSONSearch       = "json_search"
+	JSONArrayAppend  = "json_array_append"
+
+	// spatial functions
+	MBRContains    = "mbrcontains"
+	MBRIntersects  = "mbrintersects"
+	MBRWithin      = "mbrwithin"
+	Point          = "point"
+	STAsBinary     = "st_asbinary"
+	STAsText       = "st_astext"
+	STContains     = "st_contains"
+	STDistance     = "st_distance"
+	STEnvelope     = "st_envelope"
+	STGeomFromText = "st_geomfromtext"
+	STGeomFromWKB  = "st_geomfromwkb"
+	STGeometryType = "st_geometrytype"
+	STWithin       = "st_within"
+	STX            = "st_x"
+	STY            = "st_y"
 )
 
 // FuncCallExpr is for function expression.
@@ -433,3 +458,159 @@ func (n *AggregateFuncExpr) Accept(v Visitor) (Node, bool) {
 	}
 	return v.Leave(n)
 }
+
+const (
+	// WindowFuncRowNumber is the name of row_number function.
+	WindowFuncRowNumber = "row_number"
+	// WindowFuncRank is the name of rank function.
+	WindowFuncRank = "rank"
+	// WindowFuncDenseRank is the name of dense_rank function.
+	WindowFuncDenseRank = "dense_rank"
+	// WindowFuncLag is the name of lag function.
+	WindowFuncLag = "lag"
+	// WindowFuncLead is the name of lead function.
+	WindowFuncLead = "lead"
+	// WindowFuncFirstValue is the name of first_value function.
+	WindowFuncFirstValue = "first_value"
+	// WindowFuncLastValue is the name of last_value function.
+	WindowFuncLastValue = "last_value"
+)
+
+// WindowFuncExpr represents window function expression, e.g. "row_number() over (partition by a order by b)".
+// Aggregate functions followed by an OVER clause are represented by WindowFuncExpr too.
+type WindowFuncExpr struct {
+	funcNode
+	// F is the function name.
+	F string
+	// Args is the function args.
+	Args []ExprNode
+	// Distinct is only valid for aggregate functions.
+	Distinct bool
+	// Spec is the window specification.
+	Spec WindowSpec
+}
+
+// Accept implements Node Accept interface.
+func (n *WindowFuncExpr) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*WindowFuncExpr)
+	for i, val := range n.Args {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Args[i] = node.(ExprNode)
+	}
+	node, ok := n.Spec.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Spec = *node.(*WindowSpec)
+	return v.Leave(n)
+}
+
+// WindowSpec is the specification of a window, it is written in the OVER clause.
+type WindowSpec struct {
+	node
+
+	// PartitionBy is the PARTITION BY items, rows having equal values of them are in the same partition.
+	PartitionBy []*ByItem
+	// OrderBy is the ORDER BY items which decides the order of rows inside a partition.
+	OrderBy []*ByItem
+	// Frame is the frame clause, it is nil if not specified.
+	Frame *FrameClause
+}
+
+// Accept implements Node Accept interface.
+func (n *WindowSpec) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*WindowSpec)
+	for i, val := range n.PartitionBy {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.PartitionBy[i] = node.(*ByItem)
+	}
+	for i, val := range n.OrderBy {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.OrderBy[i] = node.(*ByItem)
+	}
+	if n.Frame != nil {
+		node, ok := n.Frame.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Frame = node.(*FrameClause)
+	}
+	return v.Leave(n)
+}
+
+// FrameType is the type of a window frame.
+type FrameType int
+
+// Window frame types.
+const (
+	Rows FrameType = iota
+	Ranges
+)
+
+// FrameClause represents the frame of a window, e.g. "ROWS BETWEEN 1 PRECEDING AND CURRENT ROW".
+type FrameClause struct {
+	node
+
+	Type  FrameType
+	Start FrameBound
+	End   FrameBound
+}
+
+// Accept implements Node Accept interface.
+func (n *FrameClause) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*FrameClause)
+	if n.Start.Expr != nil {
+		node, ok := n.Start.Expr.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Start.Expr = node.(ExprNode)
+	}
+	if n.End.Expr != nil {
+		node, ok := n.End.Expr.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.End.Expr = node.(ExprNode)
+	}
+	return v.Leave(n)
+}
+
+// BoundType is the type of a frame bound.
+type BoundType int
+
+// Frame bound types.
+const (
+	Following BoundType = iota
+	Preceding
+	CurrentRow
+)
+
+// FrameBound represents a bound of a window frame.
+type FrameBound struct {
+	Type      BoundType
+	UnBounded bool
+	// Expr is the offset of the bound, it is nil if the bound is UNBOUNDED or CURRENT ROW.
+	Expr ExprNode
+}
@@ -73,9 +73,21 @@ type AuthOption struct {
 	ByAuthString bool
 	AuthString   string
 	HashString   string
-	// TODO: support auth_plugin
+	// AuthPlugin is the authentication plugin specified by IDENTIFIED WITH, HashString is the
+	// authentication string if it's specified by AS.
+	AuthPlugin string
 }
 
+// Explain formats.
+const (
+	// ExplainFormatROW is the default tabular format.
+	ExplainFormatROW = "row"
+	// ExplainFormatJSON formats the plan as a JSON tree.
+	ExplainFormatJSON = "json"
+	// ExplainFormatDOT formats the plan in the DOT language of Graphviz.
+	ExplainFormatDOT = "dot"
+)
+
 // ExplainStmt is a statement to provide information about how is SQL statement executed
 // or get columns information in a table.
 // See https://dev.mysql.com/doc/refman/5.7/en/explain.html
@@ -83,6 +95,10 @@ type ExplainStmt struct {
 	stmtNode
 
 	Stmt StmtNode
+	// Analyze means the statement is executed, and the runtime statistics of the executors are reported.
+	Analyze bool
+	// Format is the lower case name of the output format, it's ExplainFormatROW by default.
+	Format string
 }
 
 // Accept implements Node Accept interface.
@@ -436,6 +452,19 @@ func (u *UserSpec) SecurityString() string {
 	return u.User.String()
 }
 
+// RequireType is the type of REQUIRE clause in account management statements.
+// See https://dev.mysql.com/doc/refman/5.7/en/create-user.html#create-user-tls
+type RequireType int
+
+// RequireType values.
+const (
+	// RequireUnspecified means there is no REQUIRE clause.
+	RequireUnspecified RequireType = iota
+	RequireNone
+	RequireSSL
+	RequireX509
+)
+
 // CreateUserStmt creates user account.
 // See https://dev.mysql.com/doc/refman/5.7/en/create-user.html
 type CreateUserStmt struct {
@@ -443,6 +472,7 @@ type CreateUserStmt struct {
 
 	IfNotExists bool
 	Specs       []*UserSpec
+	Require     RequireType
 }
 
 // Accept implements Node Accept interface.
@@ -463,6 +493,7 @@ type AlterUserStmt struct {
 	IfExists    bool
 	CurrentAuth *AuthOption
 	Specs       []*UserSpec
+	Require     RequireType
 }
 
 // Accept implements Node Accept interface.
@@ -646,6 +677,7 @@ type GrantStmt struct {
 	ObjectType ObjectTypeType
 	Level      *GrantLevel
 	Users      []*UserSpec
+	Require    RequireType
 	WithGrant  bool
 }
 
@@ -13,7 +13,10 @@
 
 package config
 
-import "sync"
+import (
+	"os"
+	"sync"
+)
 
 // Config contains configuration options.
 type Config struct {
@@ -28,6 +31,15 @@ type Config struct {
 	SlowThreshold  int    `json:"slow_threshold" toml:"slow_threshold"`
 	QueryLogMaxlen int    `json:"query_log_max_len" toml:"query_log_max_len"`
 	TCPKeepAlive   bool   `json:"tcp_keep_alive" toml:"tcp_keep_alive"`
+	TempDir        string `json:"tmp_dir" toml:"tmp_dir"`
+	// SSLCA, SSLCert and SSLKey are the paths of the CA certificate, the server certificate
+	// and the server private key. TLS is enabled only if SSLCert and SSLKey are set, and the
+	// client certificates are verified by SSLCA if it's set.
+	SSLCA   string `json:"ssl_ca" toml:"ssl_ca"`
+	SSLCert string `json:"ssl_cert" toml:"ssl_cert"`
+	SSLKey  string `json:"ssl_key" toml:"ssl_key"`
+	// DESKeyFile is the path of the file of the keys used by DES_ENCRYPT and DES_DECRYPT.
+	DESKeyFile string `json:"des_key_file" toml:"des_key_file"`
 }
 
 var cfg *Config
@@ -41,6 +53,7 @@ func GetGlobalConfig() *Config {
 		cfg = &Config{
 			SlowThreshold:  300,
 			QueryLogMaxlen: 2048,
+			TempDir:        os.TempDir(),
 		}
 	})
 	return cfg
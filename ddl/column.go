@@ -14,6 +14,7 @@
 package ddl
 
 import (
+	"strings"
 	"time"
 
 	"github.com/juju/errors"
@@ -26,6 +27,7 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -226,7 +228,11 @@ func (d *ddl) onDropColumn(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 		}
 
 		// Finish this job.
-		job.State = model.JobDone
+		if job.State == model.JobRollback {
+			job.State = model.JobRollbackDone
+		} else {
+			job.State = model.JobDone
+		}
 		job.BinlogInfo.AddTableInfo(ver, tblInfo)
 		d.asyncNotifyEvent(&Event{Tp: model.ActionDropColumn, TableInfo: tblInfo, ColumnInfo: colInfo})
 	default:
@@ -244,15 +250,10 @@ func (d *ddl) onDropColumn(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 //  4. If not deleted, check whether column data has existed, if existed, skip to next row.
 //  5. If column data doesn't exist, backfill the column with default value and then continue to handle next row.
 func (d *ddl) addTableColumn(t table.Table, columnInfo *model.ColumnInfo, reorgInfo *reorgInfo, job *model.Job) error {
-	seekHandle := reorgInfo.Handle
-	version := reorgInfo.SnapshotVer
-	count := job.GetRowCount()
 	ctx := d.newContext()
-
 	colMeta := &columnMeta{
 		colID:     columnInfo.ID,
 		oldColMap: make(map[int64]*types.FieldType)}
-	handles := make([]int64, 0, defaultBatchCnt)
 	// Get column default value.
 	var err error
 	if columnInfo.DefaultValue != nil {
@@ -268,11 +269,39 @@ func (d *ddl) addTableColumn(t table.Table, columnInfo *model.ColumnInfo, reorgI
 	for _, col := range t.Meta().Columns {
 		colMeta.oldColMap[col.ID] = &col.FieldType
 	}
+	return errors.Trace(d.backfillTableColumn(ctx, t, colMeta, reorgInfo, job))
+}
+
+// convertTableColumn backfills the changing column with the values converted from the origin column,
+// and backfills the changing indices with the converted values at the same time.
+func (d *ddl) convertTableColumn(t table.Table, originCol, changingCol *model.ColumnInfo, changingIdxs []*model.IndexInfo,
+	reorgInfo *reorgInfo, job *model.Job) error {
+	colMeta := &columnMeta{
+		colID:       changingCol.ID,
+		oldColMap:   make(map[int64]*types.FieldType),
+		originCol:   originCol,
+		changingCol: changingCol,
+		defaultVals: make([]types.Datum, len(t.Cols())),
+	}
+	for _, col := range t.Meta().Columns {
+		colMeta.oldColMap[col.ID] = &col.FieldType
+	}
+	for _, idxInfo := range changingIdxs {
+		colMeta.changingIdxs = append(colMeta.changingIdxs, tables.NewIndex(t.Meta().ID, t.Meta(), idxInfo))
+	}
+	return errors.Trace(d.backfillTableColumn(d.newContext(), t, colMeta, reorgInfo, job))
+}
 
+// backfillTableColumn traverses the snapshot in batches, and backfills the column of the rows in every batch.
+func (d *ddl) backfillTableColumn(ctx context.Context, t table.Table, colMeta *columnMeta, reorgInfo *reorgInfo, job *model.Job) error {
+	seekHandle := reorgInfo.Handle
+	version := reorgInfo.SnapshotVer
+	count := job.GetRowCount()
+	handles := make([]int64, 0, defaultBatchCnt)
 	for {
 		startTime := time.Now()
 		handles = handles[:0]
-		err = d.iterateSnapshotRows(t, version, seekHandle,
+		err := d.iterateSnapshotRows(t, version, seekHandle,
 			func(h int64, rowKey kv.Key, rawRecord []byte) (bool, error) {
 				handles = append(handles, h)
 				if len(handles) == defaultBatchCnt {
@@ -291,19 +320,19 @@ func (d *ddl) addTableColumn(t table.Table, columnInfo *model.ColumnInfo, reorgI
 		sub := time.Since(startTime).Seconds()
 		err = d.backfillColumn(ctx, t, colMeta, handles, reorgInfo)
 		if err != nil {
-			log.Warnf("[ddl] added column for %v rows failed, take time %v", count, sub)
+			log.Warnf("[ddl] backfilled column for %v rows failed, take time %v", count, sub)
 			return errors.Trace(err)
 		}
 
 		d.setReorgRowCount(count)
 		batchHandleDataHistogram.WithLabelValues(batchAddCol).Observe(sub)
-		log.Infof("[ddl] added column for %v rows, take time %v", count, sub)
+		log.Infof("[ddl] backfilled column for %v rows, take time %v", count, sub)
 	}
 }
 
 // backfillColumnInTxn deals with a part of backfilling column data in a Transaction.
 // This part of the column data rows is defaultSmallBatchCnt.
-func (d *ddl) backfillColumnInTxn(t table.Table, colMeta *columnMeta, handles []int64, txn kv.Transaction) (int64, error) {
+func (d *ddl) backfillColumnInTxn(ctx context.Context, t table.Table, colMeta *columnMeta, handles []int64, txn kv.Transaction) (int64, error) {
 	nextHandle := handles[0]
 	for _, handle := range handles {
 		log.Debug("[ddl] backfill column...", handle)
@@ -325,6 +354,17 @@ func (d *ddl) backfillColumnInTxn(t table.Table, colMeta *columnMeta, handles []
 			// The column is already added by update or insert statement, skip it.
 			continue
 		}
+		val := colMeta.defaultVal
+		if colMeta.changingCol != nil {
+			val, err = convertOriginColumnValue(ctx, t, colMeta, rowColumns, handle)
+			if err != nil {
+				return 0, errors.Trace(err)
+			}
+			err = createChangingIndexEntries(ctx, t, colMeta, rowColumns, val, handle, txn)
+			if err != nil {
+				return 0, errors.Trace(err)
+			}
+		}
 
 		newColumnIDs := make([]int64, 0, len(rowColumns)+1)
 		newRow := make([]types.Datum, 0, len(rowColumns)+1)
@@ -333,7 +373,7 @@ func (d *ddl) backfillColumnInTxn(t table.Table, colMeta *columnMeta, handles []
 			newRow = append(newRow, val)
 		}
 		newColumnIDs = append(newColumnIDs, colMeta.colID)
-		newRow = append(newRow, colMeta.defaultVal)
+		newRow = append(newRow, val)
 		newRowVal, err := tablecodec.EncodeRow(newRow, newColumnIDs, time.UTC)
 		if err != nil {
 			return 0, errors.Trace(err)
@@ -351,6 +391,77 @@ type columnMeta struct {
 	colID      int64
 	defaultVal types.Datum
 	oldColMap  map[int64]*types.FieldType
+	// originCol and changingCol are only set when the column values are converted from the origin column.
+	originCol    *model.ColumnInfo
+	changingCol  *model.ColumnInfo
+	changingIdxs []table.Index
+	defaultVals  []types.Datum
+}
+
+// convertOriginColumnValue converts the value of the origin column in a row to the type of the changing column.
+func convertOriginColumnValue(ctx context.Context, t table.Table, colMeta *columnMeta, rowColumns map[int64]types.Datum, handle int64) (types.Datum, error) {
+	isPKHandle := t.Meta().PKIsHandle && mysql.HasPriKeyFlag(colMeta.originCol.Flag)
+	val, ok := rowColumns[colMeta.originCol.ID]
+	if isPKHandle {
+		// The value of the primary key handle column is the handle.
+		val = types.NewIntDatum(handle)
+		if mysql.HasUnsignedFlag(colMeta.originCol.Flag) {
+			val = types.NewUintDatum(uint64(handle))
+		}
+	} else if !ok {
+		// The row is added before the origin column is added.
+		var err error
+		val, err = table.GetColOriginDefaultValue(ctx, colMeta.originCol)
+		if err != nil {
+			return val, errors.Trace(err)
+		}
+	}
+	converted, err := table.CastValue(ctx, val, colMeta.changingCol)
+	if err != nil {
+		return converted, errModifyColumnConvert.GenByArgs(colMeta.originCol.Name, handle, err)
+	}
+	if isPKHandle && converted.GetInt64() != handle {
+		// The changing column becomes the primary key handle column, so the handle must not be changed.
+		return converted, errModifyColumnConvert.GenByArgs(colMeta.originCol.Name, handle, "the handle is changed")
+	}
+	return converted, nil
+}
+
+// createChangingIndexEntries creates the entries of the changing indices for a row whose changing column is backfilled.
+func createChangingIndexEntries(ctx context.Context, t table.Table, colMeta *columnMeta, rowColumns map[int64]types.Datum,
+	converted types.Datum, handle int64, txn kv.Transaction) error {
+	if len(colMeta.changingIdxs) == 0 {
+		return nil
+	}
+	row := make([]types.Datum, len(t.Meta().Columns))
+	for _, col := range t.Cols() {
+		if col.IsGenerated() && !col.GeneratedStored {
+			continue
+		}
+		var err error
+		row[col.Offset], err = getColumnVal(ctx, t, col, handle, rowColumns, colMeta.defaultVals)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	row[colMeta.changingCol.Offset] = converted
+	if err := tables.FillVirtualColumnValues(ctx, t, row); err != nil {
+		return errors.Trace(err)
+	}
+	for _, idx := range colMeta.changingIdxs {
+		idxVals, err := idx.FetchValues(row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		_, err = idx.Create(txn, idxVals, handle)
+		if kv.ErrKeyExists.Equal(err) {
+			// The converted values are duplicated in the unique index.
+			return errModifyColumnConvert.GenByArgs(colMeta.originCol.Name, handle, err)
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
 }
 
 func (d *ddl) backfillColumn(ctx context.Context, t table.Table, colMeta *columnMeta, handles []int64, reorgInfo *reorgInfo) error {
@@ -367,7 +478,7 @@ func (d *ddl) backfillColumn(ctx context.Context, t table.Table, colMeta *column
 				return errors.Trace(err)
 			}
 
-			nextHandle, err1 := d.backfillColumnInTxn(t, colMeta, handles[:endIdx], txn)
+			nextHandle, err1 := d.backfillColumnInTxn(ctx, t, colMeta, handles[:endIdx], txn)
 			if err1 != nil {
 				return errors.Trace(err1)
 			}
@@ -395,47 +506,42 @@ func (d *ddl) onSetDefaultValue(t *meta.Meta, job *model.Job) (ver int64, _ erro
 }
 
 func (d *ddl) onModifyColumn(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	// Handle rollback job, the changing column and the changing indices are dropped.
+	if job.State == model.JobRollback {
+		return d.rollbackModifyColumn(t, job)
+	}
+
 	newCol := &model.ColumnInfo{}
 	oldColName := &model.CIStr{}
 	pos := &ast.ColumnPosition{}
-	err := job.DecodeArgs(newCol, oldColName, pos)
+	needReorg := false
+	err := job.DecodeArgs(newCol, oldColName, pos, &needReorg)
 	if err != nil {
 		job.State = model.JobCancelled
 		return ver, errors.Trace(err)
 	}
 
+	if needReorg {
+		return d.doModifyColumnWithReorg(t, job, newCol, oldColName, pos)
+	}
 	return d.doModifyColumn(t, job, newCol, oldColName, pos)
 }
 
-// doModifyColumn updates the column information and reorders all columns.
-func (d *ddl) doModifyColumn(t *meta.Meta, job *model.Job, col *model.ColumnInfo, oldName *model.CIStr, pos *ast.ColumnPosition) (ver int64, _ error) {
-	tblInfo, err := getTableInfo(t, job, job.SchemaID)
-	if err != nil {
-		return ver, errors.Trace(err)
-	}
-
-	oldCol := findCol(tblInfo.Columns, oldName.L)
-	if oldCol == nil || oldCol.State != model.StatePublic {
-		job.State = model.JobCancelled
-		return ver, infoschema.ErrColumnNotExists.GenByArgs(oldName, tblInfo.Name)
-	}
-
-	// Calculate column's new position.
-	oldPos, newPos := oldCol.Offset, oldCol.Offset
+// getModifiedColumnPosition calculates the new position of the modified column.
+func getModifiedColumnPosition(tblInfo *model.TableInfo, oldCol *model.ColumnInfo, oldName *model.CIStr, pos *ast.ColumnPosition) (int, error) {
+	newPos := oldCol.Offset
 	if pos.Tp == ast.ColumnPositionAfter {
 		if oldName.L == pos.RelativeColumn.Name.L {
-			// `alter table tableName modify column b int after b` will return ver,ErrColumnNotExists.
-			job.State = model.JobCancelled
-			return ver, infoschema.ErrColumnNotExists.GenByArgs(oldName, tblInfo.Name)
+			// `alter table tableName modify column b int after b` will return ErrColumnNotExists.
+			return 0, infoschema.ErrColumnNotExists.GenByArgs(oldName, tblInfo.Name)
 		}
 
 		relative := findCol(tblInfo.Columns, pos.RelativeColumn.Name.L)
 		if relative == nil || relative.State != model.StatePublic {
-			job.State = model.JobCancelled
-			return ver, infoschema.ErrColumnNotExists.GenByArgs(pos.RelativeColumn, tblInfo.Name)
+			return 0, infoschema.ErrColumnNotExists.GenByArgs(pos.RelativeColumn, tblInfo.Name)
 		}
 
-		if relative.Offset < oldPos {
+		if relative.Offset < oldCol.Offset {
 			newPos = relative.Offset + 1
 		} else {
 			newPos = relative.Offset
@@ -443,7 +549,12 @@ func (d *ddl) doModifyColumn(t *meta.Meta, job *model.Job, col *model.ColumnInfo
 	} else if pos.Tp == ast.ColumnPositionFirst {
 		newPos = 0
 	}
+	return newPos, nil
+}
 
+// moveModifiedColumn puts the modified column at the new position, reorders all columns and changes the
+// offsets and names in indices.
+func moveModifiedColumn(tblInfo *model.TableInfo, col *model.ColumnInfo, oldName *model.CIStr, oldPos, newPos int) {
 	columnChanged := make(map[string]*model.ColumnInfo)
 	columnChanged[oldName.L] = col
 
@@ -477,6 +588,29 @@ func (d *ddl) doModifyColumn(t *meta.Meta, job *model.Job, col *model.ColumnInfo
 			}
 		}
 	}
+}
+
+// doModifyColumn updates the column information and reorders all columns.
+func (d *ddl) doModifyColumn(t *meta.Meta, job *model.Job, col *model.ColumnInfo, oldName *model.CIStr, pos *ast.ColumnPosition) (ver int64, _ error) {
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	oldCol := findCol(tblInfo.Columns, oldName.L)
+	if oldCol == nil || oldCol.State != model.StatePublic {
+		job.State = model.JobCancelled
+		return ver, infoschema.ErrColumnNotExists.GenByArgs(oldName, tblInfo.Name)
+	}
+
+	// Calculate column's new position.
+	oldPos := oldCol.Offset
+	newPos, err := getModifiedColumnPosition(tblInfo, oldCol, oldName, pos)
+	if err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	moveModifiedColumn(tblInfo, col, oldName, oldPos, newPos)
 
 	originalState := job.SchemaState
 	job.SchemaState = model.StatePublic
@@ -491,6 +625,350 @@ func (d *ddl) doModifyColumn(t *meta.Meta, job *model.Job, col *model.ColumnInfo
 	return ver, nil
 }
 
+// changingColumnPrefix is the name prefix of the changing column, which is added by a modify column job
+// that needs to convert the data.
+const changingColumnPrefix = "_Col$_"
+
+// changingIndexPrefix is the name prefix of the changing index, which is added for an index covering the origin
+// column. After the changing column takes the place of the origin column, the prefix names the origin index.
+const changingIndexPrefix = "_Idx$_"
+
+func getChangingColumnName(name model.CIStr) model.CIStr {
+	return model.NewCIStr(changingColumnPrefix + name.O)
+}
+
+func getChangingIndexName(name model.CIStr) model.CIStr {
+	return model.NewCIStr(changingIndexPrefix + name.O)
+}
+
+// addChangingIndices adds a changing index for every index covering the origin column, the changing index
+// covers the changing column instead.
+func addChangingIndices(tblInfo *model.TableInfo, originCol, changingCol *model.ColumnInfo) {
+	for _, idx := range tblInfo.Indices {
+		if findIndexColumn(idx, originCol.Name.L) < 0 {
+			continue
+		}
+		changingIdx := idx.Clone()
+		changingIdx.ID = allocateIndexID(tblInfo)
+		changingIdx.Name = getChangingIndexName(idx.Name)
+		changingIdx.State = model.StateNone
+		for _, ic := range changingIdx.Columns {
+			if ic.Name.L == originCol.Name.L {
+				ic.Name = changingCol.Name
+				ic.Offset = changingCol.Offset
+			}
+		}
+		tblInfo.Indices = append(tblInfo.Indices, changingIdx)
+	}
+}
+
+// findChangingIndices finds the indices whose names have the changing index prefix.
+func findChangingIndices(tblInfo *model.TableInfo) []*model.IndexInfo {
+	var idxs []*model.IndexInfo
+	for _, idx := range tblInfo.Indices {
+		if strings.HasPrefix(idx.Name.L, strings.ToLower(changingIndexPrefix)) {
+			idxs = append(idxs, idx)
+		}
+	}
+	return idxs
+}
+
+func setIndicesState(idxs []*model.IndexInfo, state model.SchemaState) {
+	for _, idx := range idxs {
+		idx.State = state
+	}
+}
+
+// removeIndices removes the indices from the table, and returns the IDs of the removed indices.
+func removeIndices(tblInfo *model.TableInfo, idxs []*model.IndexInfo) []int64 {
+	ids := make([]int64, 0, len(idxs))
+	for _, idx := range idxs {
+		newIndices := make([]*model.IndexInfo, 0, len(tblInfo.Indices))
+		for _, index := range tblInfo.Indices {
+			if index != idx {
+				newIndices = append(newIndices, index)
+			}
+		}
+		tblInfo.Indices = newIndices
+		ids = append(ids, idx.ID)
+	}
+	return ids
+}
+
+// swapChangingIndices lets the changing indices take the places of the origin indices. The changing index gets
+// the name of the origin index and becomes public, the origin index gets the changing index name and becomes
+// write only, so it's still maintained for the servers which haven't loaded the new schema.
+func swapChangingIndices(tblInfo *model.TableInfo, changingIdxs []*model.IndexInfo, originCol, changingCol *model.ColumnInfo) {
+	for _, changingIdx := range changingIdxs {
+		originName := model.NewCIStr(strings.TrimPrefix(changingIdx.Name.O, changingIndexPrefix))
+		originIdx := findIndexByName(originName.L, tblInfo.Indices)
+		originIdx.Name, changingIdx.Name = changingIdx.Name, originIdx.Name
+		originIdx.State = model.StateWriteOnly
+		changingIdx.State = model.StatePublic
+		// The offsets of the origin column and the changing column have been swapped.
+		for _, ic := range originIdx.Columns {
+			if ic.Offset == changingCol.Offset {
+				ic.Name = originCol.Name
+				ic.Offset = originCol.Offset
+			}
+		}
+		for _, ic := range changingIdx.Columns {
+			if ic.Offset == originCol.Offset {
+				ic.Name = changingCol.Name
+				ic.Offset = changingCol.Offset
+			}
+		}
+	}
+}
+
+// doModifyColumnWithReorg modifies the column whose data must be converted.
+// How to modify the column with data conversion?
+//  1. Add a changing column with the new definition, whose values are converted from the origin column.
+//     For every index covering the origin column, add a changing index covering the changing column.
+//  2. Backfill the changing column and the changing indices of the existing rows with the converted values
+//     in reorganization state.
+//  3. If a value can't be converted, or the converted values are duplicated in a unique index, convert the job
+//     to a rollback job, which drops the changing column and the changing indices.
+//  4. Swap the changing column and the origin column, then the changing column is public with the new name.
+//     The changing indices take the places of the origin indices in the same way. The origin column is write only
+//     and its values are converted back from the new column, because the servers still on the previous schema
+//     version read the origin column and write the changing column converted from it.
+//  5. Drop the origin column and the origin indices like dropping a column.
+func (d *ddl) doModifyColumnWithReorg(t *meta.Meta, job *model.Job, newCol *model.ColumnInfo, oldName *model.CIStr, pos *ast.ColumnPosition) (ver int64, _ error) {
+	schemaID := job.SchemaID
+	tblInfo, err := getTableInfo(t, job, schemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	changingName := getChangingColumnName(*oldName)
+	changingCol := findCol(tblInfo.Columns, changingName.L)
+	if changingCol != nil && changingCol.ChangeStateInfo != nil && changingCol.ChangeStateInfo.Origin {
+		// The columns have been swapped, so it's the origin column now.
+		return d.dropOriginColumn(t, job, tblInfo, changingCol)
+	}
+
+	oldCol := findCol(tblInfo.Columns, oldName.L)
+	if oldCol == nil || oldCol.State != model.StatePublic {
+		job.State = model.JobCancelled
+		return ver, infoschema.ErrColumnNotExists.GenByArgs(oldName, tblInfo.Name)
+	}
+	if changingCol == nil {
+		// Check the new position before reorganizing the data.
+		if _, err = getModifiedColumnPosition(tblInfo, oldCol, oldName, pos); err != nil {
+			job.State = model.JobCancelled
+			return ver, errors.Trace(err)
+		}
+		changingCol = newCol.Clone()
+		changingCol.ID = allocateColumnID(tblInfo)
+		changingCol.Name = changingName
+		changingCol.Offset = len(tblInfo.Columns)
+		changingCol.State = model.StateNone
+		changingCol.ChangeStateInfo = &model.ChangeStateInfo{DependencyColumnOffset: oldCol.Offset}
+		// The changing column isn't the primary key handle column until it takes the place of the origin column.
+		changingCol.Flag &= ^uint(mysql.PriKeyFlag)
+		tblInfo.Columns = append(tblInfo.Columns, changingCol)
+		addChangingIndices(tblInfo, oldCol, changingCol)
+	}
+	changingIdxs := findChangingIndices(tblInfo)
+
+	originalState := changingCol.State
+	switch changingCol.State {
+	case model.StateNone:
+		// none -> delete only
+		job.SchemaState = model.StateDeleteOnly
+		changingCol.State = model.StateDeleteOnly
+		setIndicesState(changingIdxs, model.StateDeleteOnly)
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateDeleteOnly:
+		// delete only -> write only
+		job.SchemaState = model.StateWriteOnly
+		changingCol.State = model.StateWriteOnly
+		setIndicesState(changingIdxs, model.StateWriteOnly)
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateWriteOnly:
+		// write only -> reorganization
+		job.SchemaState = model.StateWriteReorganization
+		changingCol.State = model.StateWriteReorganization
+		setIndicesState(changingIdxs, model.StateWriteReorganization)
+		// Initialize SnapshotVer to 0 for later reorganization check.
+		job.SnapshotVer = 0
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateWriteReorganization:
+		var reorgInfo *reorgInfo
+		reorgInfo, err = d.getReorgInfo(t, job)
+		if err != nil || reorgInfo.first {
+			// If we run reorg firstly, we should update the job snapshot version
+			// and then run the reorg next time.
+			return ver, errors.Trace(err)
+		}
+
+		var tbl table.Table
+		tbl, err = d.getTable(schemaID, tblInfo)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+
+		err = d.runReorgJob(job, func() error {
+			return d.convertTableColumn(tbl, oldCol, changingCol, changingIdxs, reorgInfo, job)
+		})
+		if err != nil {
+			if errWaitReorgTimeout.Equal(err) {
+				// if timeout, we should return, check for the owner and re-wait job done.
+				return ver, nil
+			}
+			if errModifyColumnConvert.Equal(err) {
+				log.Warnf("[ddl] run DDL job %v err %v, convert job to rollback job", job, err)
+				ver, err = d.convertModifyColumn2RollbackJob(t, job, tblInfo, changingCol, changingIdxs, err)
+			}
+			return ver, errors.Trace(err)
+		}
+
+		// reorganization -> public
+		// Swap the changing column and the origin column, the origin column becomes write only.
+		oldPos := oldCol.Offset
+		tblInfo.Columns[oldCol.Offset], tblInfo.Columns[changingCol.Offset] = changingCol, oldCol
+		oldCol.Offset, changingCol.Offset = changingCol.Offset, oldCol.Offset
+		oldCol.Name, changingCol.Name = changingCol.Name, newCol.Name
+		oldCol.State = model.StateWriteOnly
+		oldCol.Flag &= ^uint(mysql.PriKeyFlag)
+		changingCol.State = model.StatePublic
+		changingCol.Flag = newCol.Flag
+		changingCol.ChangeStateInfo = nil
+		swapChangingIndices(tblInfo, changingIdxs, oldCol, changingCol)
+		var newPos int
+		newPos, err = getModifiedColumnPosition(tblInfo, changingCol, oldName, pos)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		moveModifiedColumn(tblInfo, changingCol, &changingCol.Name, oldPos, newPos)
+		oldCol.ChangeStateInfo = &model.ChangeStateInfo{DependencyColumnOffset: changingCol.Offset, Origin: true}
+
+		job.SchemaState = model.StateWriteOnly
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	default:
+		err = ErrInvalidColumnState.Gen("invalid column state %v", changingCol.State)
+	}
+	return ver, errors.Trace(err)
+}
+
+// dropOriginColumn drops the origin column and the origin indices after the changing column takes its place.
+func (d *ddl) dropOriginColumn(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, originCol *model.ColumnInfo) (ver int64, err error) {
+	originIdxs := findChangingIndices(tblInfo)
+	originalState := originCol.State
+	switch originCol.State {
+	case model.StateWriteOnly:
+		// write only -> delete only
+		job.SchemaState = model.StateDeleteOnly
+		originCol.State = model.StateDeleteOnly
+		setIndicesState(originIdxs, model.StateDeleteOnly)
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateDeleteOnly:
+		// delete only -> reorganization
+		job.SchemaState = model.StateDeleteReorganization
+		originCol.State = model.StateDeleteReorganization
+		setIndicesState(originIdxs, model.StateDeleteReorganization)
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateDeleteReorganization:
+		// reorganization -> absent
+		newColumns := make([]*model.ColumnInfo, 0, len(tblInfo.Columns))
+		for _, col := range tblInfo.Columns {
+			if col != originCol {
+				newColumns = append(newColumns, col)
+			}
+		}
+		tblInfo.Columns = newColumns
+		indexIDs := removeIndices(tblInfo, originIdxs)
+		job.SchemaState = model.StatePublic
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+
+		// Finish this job.
+		job.State = model.JobDone
+		job.BinlogInfo.AddTableInfo(ver, tblInfo)
+		// The data of the origin indices is deleted by the delete-range worker.
+		job.Args = append(job.Args, indexIDs)
+	default:
+		err = ErrInvalidColumnState.Gen("invalid column state %v", originCol.State)
+	}
+	return ver, errors.Trace(err)
+}
+
+// convertModifyColumn2RollbackJob converts the modify column job to a rollback job, which drops the changing column
+// and the changing indices.
+func (d *ddl) convertModifyColumn2RollbackJob(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, changingCol *model.ColumnInfo,
+	changingIdxs []*model.IndexInfo, convertErr error) (ver int64, _ error) {
+	job.State = model.JobRollback
+	job.Args = []interface{}{changingCol.Name}
+	// The write reorganization state of the changing column likes the write only state of a dropping column.
+	// So the next state is delete only state.
+	originalState := changingCol.State
+	changingCol.State = model.StateDeleteOnly
+	setIndicesState(changingIdxs, model.StateDeleteOnly)
+	job.SchemaState = model.StateDeleteOnly
+	ver, err := updateTableInfo(t, job, tblInfo, originalState)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	return ver, errors.Trace(convertErr)
+}
+
+// rollbackModifyColumn drops the changing column and the changing indices of the rollback job.
+func (d *ddl) rollbackModifyColumn(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	var changingName model.CIStr
+	if err = job.DecodeArgs(&changingName); err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	changingCol := findCol(tblInfo.Columns, changingName.L)
+	if changingCol == nil {
+		job.State = model.JobCancelled
+		return ver, ErrCantDropFieldOrKey.Gen("column %s doesn't exist", changingName)
+	}
+	changingIdxs := findChangingIndices(tblInfo)
+
+	originalState := changingCol.State
+	switch changingCol.State {
+	case model.StateDeleteOnly:
+		// delete only -> reorganization
+		job.SchemaState = model.StateDeleteReorganization
+		changingCol.State = model.StateDeleteReorganization
+		setIndicesState(changingIdxs, model.StateDeleteReorganization)
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateDeleteReorganization:
+		// reorganization -> absent
+		newColumns := make([]*model.ColumnInfo, 0, len(tblInfo.Columns))
+		for _, col := range tblInfo.Columns {
+			if col != changingCol {
+				newColumns = append(newColumns, col)
+			}
+		}
+		tblInfo.Columns = newColumns
+		indexIDs := removeIndices(tblInfo, changingIdxs)
+		job.SchemaState = model.StateNone
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+
+		// Finish this job.
+		job.State = model.JobRollbackDone
+		job.BinlogInfo.AddTableInfo(ver, tblInfo)
+		// The data of the changing indices is deleted by the delete-range worker.
+		job.Args = append(job.Args, indexIDs)
+	default:
+		err = ErrInvalidColumnState.Gen("invalid column state %v", changingCol.State)
+	}
+	return ver, errors.Trace(err)
+}
+
 func (d *ddl) updateColumn(t *meta.Meta, job *model.Job, newCol *model.ColumnInfo, oldColName *model.CIStr) (ver int64, _ error) {
 	tblInfo, err := getTableInfo(t, job, job.SchemaID)
 	if err != nil {
@@ -67,13 +67,19 @@ var (
 	errUnsupportedPKHandle     = terror.ClassDDL.New(codeUnsupportedDropPKHandle,
 		"unsupported drop integer primary key")
 	errUnsupportedCharset = terror.ClassDDL.New(codeUnsupportedCharset, "unsupported charset %s collate %s")
+	// errModifyColumnConvert means the value of a row can't be converted to the new column type.
+	errModifyColumnConvert = terror.ClassDDL.New(codeModifyColumnConvert, "modify column %s failed when converting the row %d, err %v")
+	// errUnsupportedPartitionOp means the operation isn't supported on partitioned tables yet.
+	errUnsupportedPartitionOp = terror.ClassDDL.New(codeUnsupportedPartitionOp, "unsupported %s on partitioned table")
+	// ErrUnsupportedSpatialIndex means the spatial indices aren't supported, the MBR predicates are evaluated
+	// row by row.
+	ErrUnsupportedSpatialIndex = terror.ClassDDL.New(codeUnsupportedSpatialIndex, "unsupported spatial index")
 
 	errBlobKeyWithoutLength = terror.ClassDDL.New(codeBlobKeyWithoutLength, "index for BLOB/TEXT column must specificate a key length")
 	errIncorrectPrefixKey   = terror.ClassDDL.New(codeIncorrectPrefixKey, "Incorrect prefix key; the used key part isn't a string, the used length is longer than the key part, or the storage engine doesn't support unique prefix keys")
 	errTooLongKey           = terror.ClassDDL.New(codeTooLongKey,
 		fmt.Sprintf("Specified key was too long; max key length is %d bytes", maxPrefixLength))
 	errKeyColumnDoesNotExits = terror.ClassDDL.New(codeKeyColumnDoesNotExits, "this key column doesn't exist in table")
-	errDupKeyName            = terror.ClassDDL.New(codeDupKeyName, "duplicate key name")
 	errUnknownTypeLength     = terror.ClassDDL.New(codeUnknownTypeLength, "Unknown length for type tp %d")
 	errUnknownFractionLength = terror.ClassDDL.New(codeUnknownFractionLength, "Unknown Length for type tp %d and fraction %d")
 	errInvalidJobVersion     = terror.ClassDDL.New(codeInvalidJobVersion, "DDL job with version %d greater than current %d")
@@ -92,6 +98,16 @@ var (
 	errDependentByGeneratedColumn = terror.ClassDDL.New(codeDependentByGeneratedColumn, mysql.MySQLErrName[mysql.ErrDependentByGeneratedColumn])
 	// errJSONUsedAsKey forbiddens to use JSON as key or index.
 	errJSONUsedAsKey = terror.ClassDDL.New(codeJSONUsedAsKey, mysql.MySQLErrName[mysql.ErrJSONUsedAsKey])
+	// errFunctionalIndexOnJSONOrGeometryFunction forbiddens to index an expression which returns JSON or GEOMETRY.
+	errFunctionalIndexOnJSONOrGeometryFunction = terror.ClassDDL.New(codeFunctionalIndexOnJSONOrGeometryFunction, mysql.MySQLErrName[mysql.ErrFunctionalIndexOnJSONOrGeometryFunction])
+	// errFunctionalIndexOnField forbiddens to use a single column as the expression key part.
+	errFunctionalIndexOnField = terror.ClassDDL.New(codeFunctionalIndexOnField, mysql.MySQLErrName[mysql.ErrFunctionalIndexOnField])
+	// ErrFunctionalIndexPrimaryKey forbiddens to use the expression key part in the primary key.
+	ErrFunctionalIndexPrimaryKey = terror.ClassDDL.New(codeFunctionalIndexPrimaryKey, mysql.MySQLErrName[mysql.ErrFunctionalIndexPrimaryKey])
+	// errFunctionalIndexOnLob forbiddens to index an expression which returns BLOB or TEXT.
+	errFunctionalIndexOnLob = terror.ClassDDL.New(codeFunctionalIndexOnLob, mysql.MySQLErrName[mysql.ErrFunctionalIndexOnLob])
+	// errDependentByFunctionalIndex forbiddens to delete columns which are dependent by the expression key parts.
+	errDependentByFunctionalIndex = terror.ClassDDL.New(codeDependentByFunctionalIndex, mysql.MySQLErrName[mysql.ErrDependentByFunctionalIndex])
 	// errBlobCantHaveDefault forbiddens to give not null default value to TEXT/BLOB/JSON.
 	errBlobCantHaveDefault = terror.ClassDDL.New(codeBlobCantHaveDefault, mysql.MySQLErrName[mysql.ErrBlobCantHaveDefault])
 
@@ -113,6 +129,8 @@ var (
 	ErrColumnBadNull = terror.ClassDDL.New(codeBadNull, "column cann't be null")
 	// ErrCantRemoveAllFields returns for deleting all columns.
 	ErrCantRemoveAllFields = terror.ClassDDL.New(codeCantRemoveAllFields, "can't delete all columns with ALTER TABLE")
+	// ErrDupKeyName returns for adding an index whose name exists.
+	ErrDupKeyName = terror.ClassDDL.New(codeDupKeyName, "duplicate key name")
 	// ErrCantDropFieldOrKey returns for dropping a non-existent field or key.
 	ErrCantDropFieldOrKey = terror.ClassDDL.New(codeCantDropFieldOrKey, "can't drop field; check that column/key exists")
 	// ErrInvalidOnUpdate returns for invalid ON UPDATE clause.
@@ -127,6 +145,32 @@ var (
 	ErrWrongColumnName = terror.ClassDDL.New(codeWrongColumnName, mysql.MySQLErrName[mysql.ErrWrongColumnName])
 	// ErrWrongNameForIndex returns for wrong index name.
 	ErrWrongNameForIndex = terror.ClassDDL.New(codeWrongNameForIndex, mysql.MySQLErrName[mysql.ErrWrongNameForIndex])
+	// ErrPartitionRequiresValues returns for a RANGE or LIST partition without VALUES.
+	ErrPartitionRequiresValues = terror.ClassDDL.New(codePartitionRequiresValues, mysql.MySQLErrName[mysql.ErrPartitionRequiresValues])
+	// ErrPartitionWrongValues returns for VALUES which don't match the partition type.
+	ErrPartitionWrongValues = terror.ClassDDL.New(codePartitionWrongValues, mysql.MySQLErrName[mysql.ErrPartitionWrongValues])
+	// ErrPartitionMaxvalue returns for MAXVALUE which isn't in the last partition.
+	ErrPartitionMaxvalue = terror.ClassDDL.New(codePartitionMaxvalue, mysql.MySQLErrName[mysql.ErrPartitionMaxvalue])
+	// ErrPartitionsMustBeDefined returns for a RANGE or LIST partitioned table without partition definitions.
+	ErrPartitionsMustBeDefined = terror.ClassDDL.New(codePartitionsMustBeDefined, mysql.MySQLErrName[mysql.ErrPartitionsMustBeDefined])
+	// ErrRangeNotIncreasing returns for VALUES LESS THAN values which aren't strictly increasing.
+	ErrRangeNotIncreasing = terror.ClassDDL.New(codeRangeNotIncreasing, mysql.MySQLErrName[mysql.ErrRangeNotIncreasing])
+	// ErrMultipleDefConstInListPart returns for a value defined in more than one LIST partition.
+	ErrMultipleDefConstInListPart = terror.ClassDDL.New(codeMultipleDefConstInListPart, mysql.MySQLErrName[mysql.ErrMultipleDefConstInListPart])
+	// ErrPartitionMgmtOnNonpartitioned returns for partition management on a table which isn't partitioned.
+	ErrPartitionMgmtOnNonpartitioned = terror.ClassDDL.New(codePartitionMgmtOnNonpartitioned, mysql.MySQLErrName[mysql.ErrPartitionMgmtOnNonpartitioned])
+	// ErrDropPartitionNonExistent returns for a partition which doesn't exist.
+	ErrDropPartitionNonExistent = terror.ClassDDL.New(codeDropPartitionNonExistent, mysql.MySQLErrName[mysql.ErrDropPartitionNonExistent])
+	// ErrDropLastPartition returns for dropping the only partition of a table.
+	ErrDropLastPartition = terror.ClassDDL.New(codeDropLastPartition, mysql.MySQLErrName[mysql.ErrDropLastPartition])
+	// ErrOnlyOnRangeListPartition returns for ADD or DROP PARTITION on a HASH partitioned table.
+	ErrOnlyOnRangeListPartition = terror.ClassDDL.New(codeOnlyOnRangeListPartition, mysql.MySQLErrName[mysql.ErrOnlyOnRangeListPartition])
+	// ErrSameNamePartition returns for duplicate partition names.
+	ErrSameNamePartition = terror.ClassDDL.New(codeSameNamePartition, mysql.MySQLErrName[mysql.ErrSameNamePartition])
+	// ErrUniqueKeyNeedAllFieldsInPf returns for a unique key which doesn't include all the columns of the partition expression.
+	ErrUniqueKeyNeedAllFieldsInPf = terror.ClassDDL.New(codeUniqueKeyNeedAllFieldsInPf, mysql.MySQLErrName[mysql.ErrUniqueKeyNeedAllFieldsInPf])
+	// ErrWrongObject returns for a view used as a base table, or a base table used as a view.
+	ErrWrongObject = terror.ClassDDL.New(codeWrongObject, mysql.MySQLErrName[mysql.ErrWrongObject])
 )
 
 // DDL is responsible for updating schema in data store and maintaining in-memory InfoSchema cache.
@@ -134,9 +178,11 @@ type DDL interface {
 	CreateSchema(ctx context.Context, name model.CIStr, charsetInfo *ast.CharsetOpt) error
 	DropSchema(ctx context.Context, schema model.CIStr) error
 	CreateTable(ctx context.Context, ident ast.Ident, cols []*ast.ColumnDef,
-		constrs []*ast.Constraint, options []*ast.TableOption) error
+		constrs []*ast.Constraint, options []*ast.TableOption, partition *ast.PartitionOptions) error
 	CreateTableWithLike(ctx context.Context, ident, referIdent ast.Ident) error
 	DropTable(ctx context.Context, tableIdent ast.Ident) (err error)
+	CreateView(ctx context.Context, stmt *ast.CreateViewStmt) error
+	DropView(ctx context.Context, tableIdent ast.Ident) error
 	CreateIndex(ctx context.Context, tableIdent ast.Ident, unique bool, indexName model.CIStr,
 		columnNames []*ast.IndexColName, indexOption *ast.IndexOption) error
 	DropIndex(ctx context.Context, tableIdent ast.Ident, indexName model.CIStr) error
@@ -526,6 +572,9 @@ const (
 	codeUnsupportedDropPKHandle     = 204
 	codeUnsupportedCharset          = 205
 	codeUnsupportedModifyPrimaryKey = 206
+	codeModifyColumnConvert         = 207
+	codeUnsupportedPartitionOp      = 208
+	codeUnsupportedSpatialIndex     = 209
 
 	codeFileNotFound                 = 1017
 	codeErrorOnRename                = 1025
@@ -551,34 +600,73 @@ const (
 	codeDependentByGeneratedColumn   = 3108
 	codeJSONUsedAsKey                = 3152
 	codeWrongNameForIndex            = terror.ErrCode(mysql.ErrWrongNameForIndex)
+
+	codePartitionRequiresValues       = terror.ErrCode(mysql.ErrPartitionRequiresValues)
+	codePartitionWrongValues          = terror.ErrCode(mysql.ErrPartitionWrongValues)
+	codePartitionMaxvalue             = terror.ErrCode(mysql.ErrPartitionMaxvalue)
+	codePartitionsMustBeDefined       = terror.ErrCode(mysql.ErrPartitionsMustBeDefined)
+	codeRangeNotIncreasing            = terror.ErrCode(mysql.ErrRangeNotIncreasing)
+	codeMultipleDefConstInListPart    = terror.ErrCode(mysql.ErrMultipleDefConstInListPart)
+	codePartitionMgmtOnNonpartitioned = terror.ErrCode(mysql.ErrPartitionMgmtOnNonpartitioned)
+	codeDropPartitionNonExistent      = terror.ErrCode(mysql.ErrDropPartitionNonExistent)
+	codeDropLastPartition             = terror.ErrCode(mysql.ErrDropLastPartition)
+	codeOnlyOnRangeListPartition      = terror.ErrCode(mysql.ErrOnlyOnRangeListPartition)
+	codeSameNamePartition             = terror.ErrCode(mysql.ErrSameNamePartition)
+	codeUniqueKeyNeedAllFieldsInPf    = terror.ErrCode(mysql.ErrUniqueKeyNeedAllFieldsInPf)
+	codeWrongObject                   = terror.ErrCode(mysql.ErrWrongObject)
+
+	codeFunctionalIndexOnJSONOrGeometryFunction = terror.ErrCode(mysql.ErrFunctionalIndexOnJSONOrGeometryFunction)
+	codeFunctionalIndexOnField                  = terror.ErrCode(mysql.ErrFunctionalIndexOnField)
+	codeFunctionalIndexPrimaryKey               = terror.ErrCode(mysql.ErrFunctionalIndexPrimaryKey)
+	codeFunctionalIndexOnLob                    = terror.ErrCode(mysql.ErrFunctionalIndexOnLob)
+	codeDependentByFunctionalIndex              = terror.ErrCode(mysql.ErrDependentByFunctionalIndex)
 )
 
 func init() {
 	ddlMySQLErrCodes := map[terror.ErrCode]uint16{
-		codeBadNull:                      mysql.ErrBadNull,
-		codeCantRemoveAllFields:          mysql.ErrCantRemoveAllFields,
-		codeCantDropFieldOrKey:           mysql.ErrCantDropFieldOrKey,
-		codeInvalidOnUpdate:              mysql.ErrInvalidOnUpdate,
-		codeBlobKeyWithoutLength:         mysql.ErrBlobKeyWithoutLength,
-		codeIncorrectPrefixKey:           mysql.ErrWrongSubKey,
-		codeTooLongIdent:                 mysql.ErrTooLongIdent,
-		codeTooLongKey:                   mysql.ErrTooLongKey,
-		codeKeyColumnDoesNotExits:        mysql.ErrKeyColumnDoesNotExits,
-		codeDupKeyName:                   mysql.ErrDupKeyName,
-		codeWrongDBName:                  mysql.ErrWrongDBName,
-		codeWrongTableName:               mysql.ErrWrongTableName,
-		codeFileNotFound:                 mysql.ErrFileNotFound,
-		codeErrorOnRename:                mysql.ErrErrorOnRename,
-		codeBadField:                     mysql.ErrBadField,
-		codeInvalidUseOfNull:             mysql.ErrInvalidUseOfNull,
-		codeUnsupportedOnGeneratedColumn: mysql.ErrUnsupportedOnGeneratedColumn,
-		codeGeneratedColumnNonPrior:      mysql.ErrGeneratedColumnNonPrior,
-		codeDependentByGeneratedColumn:   mysql.ErrDependentByGeneratedColumn,
-		codeJSONUsedAsKey:                mysql.ErrJSONUsedAsKey,
-		codeBlobCantHaveDefault:          mysql.ErrBlobCantHaveDefault,
-		codeWrongColumnName:              mysql.ErrWrongColumnName,
-		codeWrongKeyColumn:               mysql.ErrWrongKeyColumn,
-		codeWrongNameForIndex:            mysql.ErrWrongNameForIndex,
+		codeBadNull:                       mysql.ErrBadNull,
+		codeCantRemoveAllFields:           mysql.ErrCantRemoveAllFields,
+		codeCantDropFieldOrKey:            mysql.ErrCantDropFieldOrKey,
+		codeInvalidOnUpdate:               mysql.ErrInvalidOnUpdate,
+		codeBlobKeyWithoutLength:          mysql.ErrBlobKeyWithoutLength,
+		codeIncorrectPrefixKey:            mysql.ErrWrongSubKey,
+		codeTooLongIdent:                  mysql.ErrTooLongIdent,
+		codeTooLongKey:                    mysql.ErrTooLongKey,
+		codeKeyColumnDoesNotExits:         mysql.ErrKeyColumnDoesNotExits,
+		codeDupKeyName:                    mysql.ErrDupKeyName,
+		codeWrongDBName:                   mysql.ErrWrongDBName,
+		codeWrongTableName:                mysql.ErrWrongTableName,
+		codeFileNotFound:                  mysql.ErrFileNotFound,
+		codeErrorOnRename:                 mysql.ErrErrorOnRename,
+		codeBadField:                      mysql.ErrBadField,
+		codeInvalidUseOfNull:              mysql.ErrInvalidUseOfNull,
+		codeUnsupportedOnGeneratedColumn:  mysql.ErrUnsupportedOnGeneratedColumn,
+		codeGeneratedColumnNonPrior:       mysql.ErrGeneratedColumnNonPrior,
+		codeDependentByGeneratedColumn:    mysql.ErrDependentByGeneratedColumn,
+		codeJSONUsedAsKey:                 mysql.ErrJSONUsedAsKey,
+		codeBlobCantHaveDefault:           mysql.ErrBlobCantHaveDefault,
+		codeWrongColumnName:               mysql.ErrWrongColumnName,
+		codeWrongKeyColumn:                mysql.ErrWrongKeyColumn,
+		codeWrongNameForIndex:             mysql.ErrWrongNameForIndex,
+		codePartitionRequiresValues:       mysql.ErrPartitionRequiresValues,
+		codePartitionWrongValues:          mysql.ErrPartitionWrongValues,
+		codePartitionMaxvalue:             mysql.ErrPartitionMaxvalue,
+		codePartitionsMustBeDefined:       mysql.ErrPartitionsMustBeDefined,
+		codeRangeNotIncreasing:            mysql.ErrRangeNotIncreasing,
+		codeMultipleDefConstInListPart:    mysql.ErrMultipleDefConstInListPart,
+		codePartitionMgmtOnNonpartitioned: mysql.ErrPartitionMgmtOnNonpartitioned,
+		codeDropPartitionNonExistent:      mysql.ErrDropPartitionNonExistent,
+		codeDropLastPartition:             mysql.ErrDropLastPartition,
+		codeOnlyOnRangeListPartition:      mysql.ErrOnlyOnRangeListPartition,
+		codeSameNamePartition:             mysql.ErrSameNamePartition,
+		codeUniqueKeyNeedAllFieldsInPf:    mysql.ErrUniqueKeyNeedAllFieldsInPf,
+		codeWrongObject:                   mysql.ErrWrongObject,
+
+		codeFunctionalIndexOnJSONOrGeometryFunction: mysql.ErrFunctionalIndexOnJSONOrGeometryFunction,
+		codeFunctionalIndexOnField:                  mysql.ErrFunctionalIndexOnField,
+		codeFunctionalIndexPrimaryKey:               mysql.ErrFunctionalIndexPrimaryKey,
+		codeFunctionalIndexOnLob:                    mysql.ErrFunctionalIndexOnLob,
+		codeDependentByFunctionalIndex:              mysql.ErrDependentByFunctionalIndex,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassDDL] = ddlMySQLErrCodes
 }
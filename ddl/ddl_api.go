@@ -117,6 +117,9 @@ func setColumnFlagWithConstraint(colMap map[string]*table.Column, v *ast.Constra
 	switch v.Tp {
 	case ast.ConstraintPrimaryKey:
 		for _, key := range v.Keys {
+			if key.Column == nil {
+				continue
+			}
 			c, ok := colMap[key.Column.Name.L]
 			if !ok {
 				continue
@@ -127,6 +130,9 @@ func setColumnFlagWithConstraint(colMap map[string]*table.Column, v *ast.Constra
 		}
 	case ast.ConstraintUniq, ast.ConstraintUniqIndex, ast.ConstraintUniqKey:
 		for i, key := range v.Keys {
+			if key.Column == nil {
+				continue
+			}
 			c, ok := colMap[key.Column.Name.L]
 			if !ok {
 				continue
@@ -145,6 +151,9 @@ func setColumnFlagWithConstraint(colMap map[string]*table.Column, v *ast.Constra
 		}
 	case ast.ConstraintKey, ast.ConstraintIndex:
 		for i, key := range v.Keys {
+			if key.Column == nil {
+				continue
+			}
 			c, ok := colMap[key.Column.Name.L]
 			if !ok {
 				continue
@@ -226,12 +235,12 @@ func buildColumnAndConstraint(ctx context.Context, offset int,
 }
 
 // checkColumnCantHaveDefaultValue checks the column can have value as default or not.
-// Now, TEXT/BLOB/JSON can't have not null value as default.
+// Now, TEXT/BLOB/JSON/GEOMETRY can't have not null value as default.
 func checkColumnCantHaveDefaultValue(col *table.Column, value interface{}) (err error) {
-	if value != nil && (col.Tp == mysql.TypeJSON ||
+	if value != nil && (col.Tp == mysql.TypeJSON || col.Tp == mysql.TypeGeometry ||
 		col.Tp == mysql.TypeTinyBlob || col.Tp == mysql.TypeMediumBlob ||
 		col.Tp == mysql.TypeLongBlob || col.Tp == mysql.TypeBlob) {
-		// TEXT/BLOB/JSON can't have not null default values.
+		// TEXT/BLOB/JSON/GEOMETRY can't have not null default values.
 		return errBlobCantHaveDefault.GenByArgs(col.Name.O)
 	}
 	return nil
@@ -310,7 +319,7 @@ func columnDefToCol(ctx context.Context, offset int, colDef *ast.ColumnDef) (*ta
 					return nil, nil, errors.Trace(err)
 				}
 			case ast.ColumnOptionGenerated:
-				col.GeneratedExprString = stringutil.RemoveBlanks(v.Expr.Text())
+				col.GeneratedExprString = stringutil.RemoveExprBlanks(v.Expr.Text())
 				col.GeneratedStored = v.Stored
 				_, dependColNames := findDependedColumnNames(colDef)
 				col.Dependences = dependColNames
@@ -487,7 +496,7 @@ func checkDuplicateConstraint(namesMap map[string]bool, name string, foreign boo
 		if foreign {
 			return infoschema.ErrCannotAddForeign
 		}
-		return errDupKeyName.Gen("duplicate key name %s", name)
+		return ErrDupKeyName.Gen("duplicate key name %s", name)
 	}
 	namesMap[nameLower] = true
 	return nil
@@ -495,7 +504,10 @@ func checkDuplicateConstraint(namesMap map[string]bool, name string, foreign boo
 
 func setEmptyConstraintName(namesMap map[string]bool, constr *ast.Constraint, foreign bool) {
 	if constr.Name == "" && len(constr.Keys) > 0 {
-		colName := constr.Keys[0].Column.Name.L
+		colName := anonymousFunctionalIndexName.L
+		if constr.Keys[0].Column != nil {
+			colName = constr.Keys[0].Column.Name.L
+		}
 		constrName := colName
 		i := 2
 		if strings.EqualFold(constrName, mysql.PrimaryKeyName) {
@@ -547,6 +559,40 @@ func checkConstraintNames(constraints []*ast.Constraint) error {
 	return nil
 }
 
+// buildHiddenColumns appends the hidden columns for the expression key parts of the indices to cols,
+// and replaces the expression key parts of the constraints with the hidden columns.
+func buildHiddenColumns(ctx context.Context, tblName model.CIStr, cols []*table.Column, constraints []*ast.Constraint) ([]*table.Column, error) {
+	for i, constr := range constraints {
+		if constr.Tp == ast.ConstraintForeignKey {
+			continue
+		}
+		colInfos := make([]*model.ColumnInfo, 0, len(cols))
+		for _, col := range cols {
+			colInfos = append(colInfos, col.ToInfo())
+		}
+		colDefs, keys, err := buildHiddenColumnDefs(ctx, tblName, colInfos, constr.Name, constr.Keys)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if len(colDefs) == 0 {
+			continue
+		}
+		for _, colDef := range colDefs {
+			col, _, err := buildColumnAndConstraint(ctx, len(cols), colDef)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			col.State = model.StatePublic
+			col.Hidden = true
+			cols = append(cols, col)
+		}
+		newConstr := *constr
+		newConstr.Keys = keys
+		constraints[i] = &newConstr
+	}
+	return cols, nil
+}
+
 func (d *ddl) buildTableInfo(tableName model.CIStr, cols []*table.Column, constraints []*ast.Constraint) (tbInfo *model.TableInfo, err error) {
 	tbInfo = &model.TableInfo{
 		Name: tableName,
@@ -571,9 +617,15 @@ func (d *ddl) buildTableInfo(tableName model.CIStr, cols []*table.Column, constr
 			fk.RefTable = constr.Refer.Table.Name
 			fk.State = model.StatePublic
 			for _, key := range constr.Keys {
+				if key.Column == nil {
+					return nil, infoschema.ErrCannotAddForeign
+				}
 				fk.Cols = append(fk.Cols, key.Column.Name)
 			}
 			for _, key := range constr.Refer.IndexColNames {
+				if key.Column == nil {
+					return nil, infoschema.ErrCannotAddForeign
+				}
 				fk.RefCols = append(fk.RefCols, key.Column.Name)
 			}
 			fk.OnDelete = int(constr.Refer.OnDelete.ReferOpt)
@@ -657,6 +709,9 @@ func (d *ddl) CreateTableWithLike(ctx context.Context, ident, referIdent ast.Ide
 	if err != nil {
 		return infoschema.ErrTableNotExists.GenByArgs(referIdent.Schema, referIdent.Name)
 	}
+	if referTbl.Meta().IsView() {
+		return ErrWrongObject.GenByArgs(referIdent.Schema, referIdent.Name, "BASE TABLE")
+	}
 	schema, ok := is.SchemaByName(ident.Schema)
 	if !ok {
 		return infoschema.ErrDatabaseNotExists.GenByArgs(ident.Schema)
@@ -673,6 +728,16 @@ func (d *ddl) CreateTableWithLike(ctx context.Context, ident, referIdent ast.Ide
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if tblInfo.Partition != nil {
+		// The partitions of the new table can't share the data of the referred table.
+		tblInfo.Partition = tblInfo.Partition.Clone()
+		for i := range tblInfo.Partition.Definitions {
+			tblInfo.Partition.Definitions[i].ID, err = d.genGlobalID()
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
 	job := &model.Job{
 		SchemaID:   schema.ID,
 		TableID:    tblInfo.ID,
@@ -687,7 +752,7 @@ func (d *ddl) CreateTableWithLike(ctx context.Context, ident, referIdent ast.Ide
 }
 
 func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.ColumnDef,
-	constraints []*ast.Constraint, options []*ast.TableOption) (err error) {
+	constraints []*ast.Constraint, options []*ast.TableOption, partition *ast.PartitionOptions) (err error) {
 	is := d.GetInformationSchema()
 	schema, ok := is.SchemaByName(ident.Schema)
 	if !ok {
@@ -719,10 +784,21 @@ func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.C
 		return errors.Trace(err)
 	}
 
+	cols, err = buildHiddenColumns(ctx, ident.Name, cols, newConstraints)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
 	tbInfo, err := d.buildTableInfo(ident.Name, cols, newConstraints)
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if partition != nil {
+		tbInfo.Partition, err = d.buildPartitionInfo(ctx, tbInfo, partition)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
 
 	job := &model.Job{
 		SchemaID:   schema.ID,
@@ -745,6 +821,72 @@ func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.C
 	return errors.Trace(err)
 }
 
+// CreateView creates a view, or replaces the view with the same name if OR REPLACE is specified.
+func (d *ddl) CreateView(ctx context.Context, s *ast.CreateViewStmt) (err error) {
+	ident := ast.Ident{Schema: s.ViewName.Schema, Name: s.ViewName.Name}
+	is := d.GetInformationSchema()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ident.Schema)
+	}
+	if err = checkTooLongTable(ident.Name); err != nil {
+		return errors.Trace(err)
+	}
+	var oldViewID int64
+	if oldTbl, err1 := is.TableByName(ident.Schema, ident.Name); err1 == nil {
+		if !s.OrReplace {
+			return infoschema.ErrTableExists.GenByArgs(ident)
+		}
+		if !oldTbl.Meta().IsView() {
+			return ErrWrongObject.GenByArgs(ident.Schema, ident.Name, "VIEW")
+		}
+		oldViewID = oldTbl.Meta().ID
+	}
+
+	cols := make([]*table.Column, 0, len(s.SchemaCols))
+	for _, colInfo := range s.SchemaCols {
+		cols = append(cols, table.ToColumn(colInfo))
+	}
+	tbInfo, err := d.buildTableInfo(ident.Name, cols, nil)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if oldViewID != 0 {
+		// The replaced view keeps its ID.
+		tbInfo.ID = oldViewID
+	}
+	definer := s.Definer
+	if definer == nil && ctx.GetSessionVars().User != nil {
+		// The default definer is the account which the current user is authenticated as.
+		definer = ctx.GetSessionVars().User.AuthIdentity()
+	}
+	charset, collate := ctx.GetSessionVars().GetCharsetInfo()
+	if charset == "" {
+		charset, collate = mysql.DefaultCharset, mysql.DefaultCollationName
+	}
+	tbInfo.View = &model.ViewInfo{
+		Algorithm:   s.Algorithm,
+		Definer:     definer,
+		Security:    s.Security,
+		SelectStmt:  s.Select.Text(),
+		CheckOption: s.CheckOption,
+		Cols:        s.Cols,
+		Charset:     charset,
+		Collate:     collate,
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tbInfo.ID,
+		Type:       model.ActionCreateView,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{tbInfo, oldViewID},
+	}
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
 // handleAutoIncID handles auto_increment option in DDL. It creates a ID counter for the table and initiates the counter to a proper value.
 // For example if the option sets auto_increment to 10. The counter will be set to 9. So the next allocated ID will be 10.
 func (d *ddl) handleAutoIncID(tbInfo *model.TableInfo, schemaID int64) error {
@@ -798,6 +940,10 @@ func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.Alte
 		// Now we only allow one schema changing at the same time.
 		return errRunMultiSchemaChanges
 	}
+	is := d.GetInformationSchema()
+	if tb, err1 := is.TableByName(ident.Schema, ident.Name); err1 == nil && tb.Meta().IsView() {
+		return ErrWrongObject.GenByArgs(ident.Schema, ident.Name, "BASE TABLE")
+	}
 
 	for _, spec := range validSpecs {
 		switch spec.Tp {
@@ -834,6 +980,12 @@ func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.Alte
 			err = d.RenameTable(ctx, ident, newIdent)
 		case ast.AlterTableDropPrimaryKey:
 			err = ErrUnsupportedModifyPrimaryKey.GenByArgs("drop")
+		case ast.AlterTableAddPartitions:
+			err = d.AddTablePartitions(ctx, ident, spec)
+		case ast.AlterTableDropPartition:
+			err = d.DropTablePartition(ctx, ident, spec)
+		case ast.AlterTableTruncatePartition:
+			err = d.TruncateTablePartition(ctx, ident, spec)
 		default:
 			// Nothing to do now.
 		}
@@ -951,7 +1103,8 @@ func (d *ddl) DropColumn(ctx context.Context, ti ast.Ident, colName model.CIStr)
 
 	// Check whether dropped column has existed.
 	col := table.FindCol(t.Cols(), colName.L)
-	if col == nil {
+	// The hidden columns are dropped with their index.
+	if col == nil || col.Hidden {
 		return ErrCantDropFieldOrKey.Gen("column %s doesn't exist", colName)
 	}
 
@@ -959,6 +1112,15 @@ func (d *ddl) DropColumn(ctx context.Context, ti ast.Ident, colName model.CIStr)
 	if err = isDroppableColumn(tblInfo, colName); err != nil {
 		return errors.Trace(err)
 	}
+	if tblInfo.Partition != nil {
+		used, err := isColumnInPartitionExpr(tblInfo, colName)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if used {
+			return errUnsupportedPartitionOp.GenByArgs("drop the column of the partition expression")
+		}
+	}
 	// We don't support dropping column with PK handle covered now.
 	if col.IsPKHandleColumn(tblInfo) {
 		return errUnsupportedPKHandle
@@ -1019,6 +1181,15 @@ func modifiable(origin *types.FieldType, to *types.FieldType) error {
 		}
 	case mysql.TypeEnum:
 		return errUnsupportedModifyColumn.GenByArgs("modify enum column is not supported")
+	case mysql.TypeGeometry:
+		// The values of a POINT column can be stored into a GEOMETRY column, but not vice versa.
+		if to.Tp == mysql.TypeGeometry {
+			if to.GeomType.Accepts(origin.GeomType) {
+				return nil
+			}
+			msg := fmt.Sprintf("type %s not match origin %s", to.CompactStr(), origin.CompactStr())
+			return errUnsupportedModifyColumn.GenByArgs(msg)
+		}
 	default:
 		if origin.Tp == to.Tp {
 			return nil
@@ -1028,6 +1199,60 @@ func modifiable(origin *types.FieldType, to *types.FieldType) error {
 	return errUnsupportedModifyColumn.GenByArgs(msg)
 }
 
+// modifiableWithReorg checks if the 'origin' column can be modified to 'to' type by converting the existing data
+// in the table. The converted data is backfilled into a changing column in the reorganization state, and the
+// changing column takes the place of the origin column at last.
+func modifiableWithReorg(tblInfo *model.TableInfo, origin *table.Column, to *types.FieldType) error {
+	for _, tp := range []byte{origin.Tp, to.Tp} {
+		switch tp {
+		case mysql.TypeEnum, mysql.TypeSet, mysql.TypeBit, mysql.TypeJSON, mysql.TypeGeometry:
+			msg := fmt.Sprintf("converting the data of %s column", types.TypeStr(tp))
+			return errUnsupportedModifyColumn.GenByArgs(msg)
+		}
+	}
+	if origin.IsGenerated() {
+		return errUnsupportedOnGeneratedColumn.GenByArgs("Converting the data of generated column")
+	}
+	for _, col := range tblInfo.Columns {
+		if _, ok := col.Dependences[origin.Name.L]; ok {
+			if col.Hidden {
+				return errDependentByFunctionalIndex.GenByArgs(origin.Name)
+			}
+			return errDependentByGeneratedColumn.GenByArgs(origin.Name)
+		}
+	}
+	// The changing column becomes the primary key handle column, so the handle must be kept.
+	if origin.IsPKHandleColumn(tblInfo) {
+		switch to.Tp {
+		case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
+		default:
+			return errUnsupportedModifyColumn.GenByArgs("converting the primary key handle column to non-integer type")
+		}
+	}
+	// The indices covering the column are rebuilt on the changing column, check the new index columns.
+	columns := make([]*model.ColumnInfo, 0, len(tblInfo.Columns))
+	for _, col := range tblInfo.Columns {
+		if col.ID == origin.ID {
+			col = col.Clone()
+			col.FieldType = *to
+		}
+		columns = append(columns, col)
+	}
+	for _, idx := range tblInfo.Indices {
+		if findIndexColumn(idx, origin.Name.L) < 0 {
+			continue
+		}
+		idxColNames := make([]*ast.IndexColName, 0, len(idx.Columns))
+		for _, ic := range idx.Columns {
+			idxColNames = append(idxColNames, &ast.IndexColName{Column: &ast.ColumnName{Name: ic.Name}, Length: ic.Length})
+		}
+		if _, err := buildIndexColumns(columns, idxColNames); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 func setDefaultValue(ctx context.Context, col *table.Column, option *ast.ColumnOption) error {
 	value, err := getDefaultValue(ctx, option, col.Tp, col.Decimal)
 	if err != nil {
@@ -1085,7 +1310,7 @@ func setDefaultAndComment(ctx context.Context, col *table.Column, options []*ast
 			col.Flag |= mysql.OnUpdateNowFlag
 			setOnUpdateNow = true
 		case ast.ColumnOptionGenerated:
-			col.GeneratedExprString = stringutil.RemoveBlanks(opt.Expr.Text())
+			col.GeneratedExprString = stringutil.RemoveExprBlanks(opt.Expr.Text())
 			col.GeneratedStored = opt.Stored
 			col.Dependences = make(map[string]struct{})
 			for _, colName := range findColumnNamesInExpr(opt.Expr) {
@@ -1123,7 +1348,7 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, ident ast.Ident, origi
 	}
 
 	col := table.FindCol(t.Cols(), originalColName.L)
-	if col == nil {
+	if col == nil || col.Hidden {
 		return nil, infoschema.ErrColumnNotExists.GenByArgs(originalColName, ident.Name)
 	}
 
@@ -1147,9 +1372,28 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, ident ast.Ident, origi
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	needReorg := false
 	err = modifiable(&col.FieldType, &newCol.FieldType)
 	if err != nil {
-		return nil, errors.Trace(err)
+		// The existing data must be converted, so the job needs to reorganize the data.
+		if err = modifiableWithReorg(t.Meta(), col, &newCol.FieldType); err != nil {
+			return nil, errors.Trace(err)
+		}
+		needReorg = true
+	}
+	if tblInfo := t.Meta(); tblInfo.Partition != nil {
+		if needReorg {
+			return nil, errUnsupportedPartitionOp.GenByArgs("modify column with data conversion")
+		}
+		if newCol.Name.L != originalColName.L {
+			used, err := isColumnInPartitionExpr(tblInfo, originalColName)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if used {
+				return nil, errUnsupportedPartitionOp.GenByArgs("rename the column of the partition expression")
+			}
+		}
 	}
 	if err = setDefaultAndComment(ctx, newCol, spec.NewColumn.Options); err != nil {
 		return nil, errors.Trace(err)
@@ -1182,14 +1426,13 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, ident ast.Ident, origi
 		TableID:    t.Meta().ID,
 		Type:       model.ActionModifyColumn,
 		BinlogInfo: &model.HistoryInfo{},
-		Args:       []interface{}{&newCol, originalColName, spec.Position},
+		Args:       []interface{}{&newCol, originalColName, spec.Position, needReorg},
 	}
 	return job, nil
 }
 
-// ChangeColumn renames an existing column and modifies the column's definition,
-// currently we only support limited kind of changes
-// that do not need to change or check data on the table.
+// ChangeColumn renames an existing column and modifies the column's definition.
+// If the existing data must be converted, the data is reorganized in the background.
 func (d *ddl) ChangeColumn(ctx context.Context, ident ast.Ident, spec *ast.AlterTableSpec) error {
 	if len(spec.NewColumn.Name.Schema.O) != 0 && ident.Schema.L != spec.NewColumn.Name.Schema.L {
 		return ErrWrongDBName.GenByArgs(spec.NewColumn.Name.Schema.O)
@@ -1214,8 +1457,8 @@ func (d *ddl) ChangeColumn(ctx context.Context, ident ast.Ident, spec *ast.Alter
 	return errors.Trace(err)
 }
 
-// ModifyColumn does modification on an existing column, currently we only support limited kind of changes
-// that do not need to change or check data on the table.
+// ModifyColumn does modification on an existing column.
+// If the existing data must be converted, the data is reorganized in the background.
 func (d *ddl) ModifyColumn(ctx context.Context, ident ast.Ident, spec *ast.AlterTableSpec) error {
 	if len(spec.NewColumn.Name.Schema.O) != 0 && ident.Schema.L != spec.NewColumn.Name.Schema.L {
 		return ErrWrongDBName.GenByArgs(spec.NewColumn.Name.Schema.O)
@@ -1249,7 +1492,7 @@ func (d *ddl) AlterColumn(ctx context.Context, ident ast.Ident, spec *ast.AlterT
 	colName := spec.NewColumn.Name.Name
 	// Check whether alter column has existed.
 	col := table.FindCol(t.Cols(), colName.L)
-	if col == nil {
+	if col == nil || col.Hidden {
 		return errBadField.GenByArgs(colName, ident.Name)
 	}
 
@@ -1290,6 +1533,9 @@ func (d *ddl) DropTable(ctx context.Context, ti ast.Ident) (err error) {
 	if err != nil {
 		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
 	}
+	if tb.Meta().IsView() {
+		return ErrWrongObject.GenByArgs(ti.Schema, ti.Name, "BASE TABLE")
+	}
 
 	job := &model.Job{
 		SchemaID:   schema.ID,
@@ -1303,6 +1549,34 @@ func (d *ddl) DropTable(ctx context.Context, ti ast.Ident) (err error) {
 	return errors.Trace(err)
 }
 
+// DropView drops a view.
+func (d *ddl) DropView(ctx context.Context, ti ast.Ident) (err error) {
+	is := d.GetInformationSchema()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ti.Schema)
+	}
+
+	tb, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
+	}
+	if !tb.Meta().IsView() {
+		return ErrWrongObject.GenByArgs(ti.Schema, ti.Name, "VIEW")
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tb.Meta().ID,
+		Type:       model.ActionDropView,
+		BinlogInfo: &model.HistoryInfo{},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
 func (d *ddl) TruncateTable(ctx context.Context, ti ast.Ident) error {
 	is := d.GetInformationSchema()
 	schema, ok := is.SchemaByName(ti.Schema)
@@ -1313,16 +1587,146 @@ func (d *ddl) TruncateTable(ctx context.Context, ti ast.Ident) error {
 	if err != nil {
 		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
 	}
+	if tb.Meta().IsView() {
+		return ErrWrongObject.GenByArgs(ti.Schema, ti.Name, "BASE TABLE")
+	}
 	newTableID, err := d.genGlobalID()
 	if err != nil {
 		return errors.Trace(err)
 	}
+	// The partitions get new IDs too.
+	var newPartitionIDs []int64
+	for range getPartitionIDs(tb.Meta()) {
+		id, err := d.genGlobalID()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		newPartitionIDs = append(newPartitionIDs, id)
+	}
 	job := &model.Job{
 		SchemaID:   schema.ID,
 		TableID:    tb.Meta().ID,
 		Type:       model.ActionTruncateTable,
 		BinlogInfo: &model.HistoryInfo{},
-		Args:       []interface{}{newTableID},
+		Args:       []interface{}{newTableID, newPartitionIDs},
+	}
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// AddTablePartitions adds the partitions to a RANGE or LIST partitioned table.
+func (d *ddl) AddTablePartitions(ctx context.Context, ident ast.Ident, spec *ast.AlterTableSpec) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return errors.Trace(infoschema.ErrDatabaseNotExists)
+	}
+	t, err := is.TableByName(ident.Schema, ident.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ident.Schema, ident.Name))
+	}
+	pi := t.Meta().Partition
+	if pi == nil {
+		return errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	if pi.Type == model.PartitionTypeHash {
+		return ErrOnlyOnRangeListPartition.GenByArgs("ADD")
+	}
+
+	defs, err := buildPartitionDefinitions(ctx, pi.Type, spec.PartDefinitions)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	newPi := pi.Clone()
+	newPi.Definitions = append(newPi.Definitions, defs...)
+	if err = checkPartitionDefinitions(newPi); err != nil {
+		return errors.Trace(err)
+	}
+	for i := range defs {
+		defs[i].ID, err = d.genGlobalID()
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionAddTablePartition,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{defs},
+	}
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// DropTablePartition drops a partition and its data from a RANGE or LIST partitioned table.
+func (d *ddl) DropTablePartition(ctx context.Context, ident ast.Ident, spec *ast.AlterTableSpec) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return errors.Trace(infoschema.ErrDatabaseNotExists)
+	}
+	t, err := is.TableByName(ident.Schema, ident.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ident.Schema, ident.Name))
+	}
+	pi := t.Meta().Partition
+	if pi == nil {
+		return errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	if pi.Type == model.PartitionTypeHash {
+		return ErrOnlyOnRangeListPartition.GenByArgs("DROP")
+	}
+	partName := model.NewCIStr(spec.Name)
+	if pi.FindPartitionDefinitionByName(partName.L) < 0 {
+		return ErrDropPartitionNonExistent.GenByArgs("DROP")
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionDropTablePartition,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{partName},
+	}
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// TruncateTablePartition deletes all the data of a partition.
+func (d *ddl) TruncateTablePartition(ctx context.Context, ident ast.Ident, spec *ast.AlterTableSpec) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return errors.Trace(infoschema.ErrDatabaseNotExists)
+	}
+	t, err := is.TableByName(ident.Schema, ident.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ident.Schema, ident.Name))
+	}
+	pi := t.Meta().Partition
+	if pi == nil {
+		return errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	partName := model.NewCIStr(spec.Name)
+	if pi.FindPartitionDefinitionByName(partName.L) < 0 {
+		return ErrDropPartitionNonExistent.GenByArgs("TRUNCATE")
+	}
+	newPartitionID, err := d.genGlobalID()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionTruncateTablePartition,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{partName, newPartitionID},
 	}
 	err = d.doDDLJob(ctx, job)
 	err = d.callHookOnChanged(err)
@@ -1385,14 +1789,47 @@ func (d *ddl) CreateIndex(ctx context.Context, ti ast.Ident, unique bool, indexN
 	if err != nil {
 		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
 	}
+	if t.Meta().IsView() {
+		return ErrWrongObject.GenByArgs(ti.Schema, ti.Name, "BASE TABLE")
+	}
 
 	// Deal with anonymous index.
 	if len(indexName.L) == 0 {
-		indexName = getAnonymousIndex(t, idxColNames[0].Column.Name)
+		colName := anonymousFunctionalIndexName
+		if idxColNames[0].Column != nil {
+			colName = idxColNames[0].Column.Name
+		}
+		indexName = getAnonymousIndex(t, colName)
 	}
 
 	if indexInfo := findIndexByName(indexName.L, t.Meta().Indices); indexInfo != nil {
-		return errDupKeyName.Gen("index already exist %s", indexName)
+		return ErrDupKeyName.Gen("index already exist %s", indexName)
+	}
+	if unique && t.Meta().Partition != nil {
+		if err = checkUniqueIndexInPartitionExpr(t.Meta(), idxColNames); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	colInfos := make([]*model.ColumnInfo, 0, len(t.Cols()))
+	for _, col := range t.Cols() {
+		colInfos = append(colInfos, col.ToInfo())
+	}
+	hiddenColDefs, idxColNames, err := buildHiddenColumnDefs(ctx, t.Meta().Name, colInfos, indexName.O, idxColNames)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// The hidden columns are added in the same job as the index, so they are rolled back together.
+	hiddenCols := make([]*model.ColumnInfo, 0, len(hiddenColDefs))
+	for _, colDef := range hiddenColDefs {
+		if len(colDef.Name.Name.O) > mysql.MaxColumnNameLength {
+			return ErrTooLongIdent.Gen("too long column %s", colDef.Name.Name)
+		}
+		col, _, err := buildColumnAndConstraint(ctx, len(t.Cols()), colDef)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		col.Hidden = true
+		hiddenCols = append(hiddenCols, col.ToInfo())
 	}
 
 	job := &model.Job{
@@ -1400,7 +1837,7 @@ func (d *ddl) CreateIndex(ctx context.Context, ti ast.Ident, unique bool, indexN
 		TableID:    t.Meta().ID,
 		Type:       model.ActionAddIndex,
 		BinlogInfo: &model.HistoryInfo{},
-		Args:       []interface{}{unique, indexName, idxColNames, indexOption},
+		Args:       []interface{}{unique, indexName, idxColNames, indexOption, hiddenCols},
 	}
 
 	err = d.doDDLJob(ctx, job)
@@ -1415,11 +1852,17 @@ func buildFKInfo(fkName model.CIStr, keys []*ast.IndexColName, refer *ast.Refere
 
 	fkInfo.Cols = make([]model.CIStr, len(keys))
 	for i, key := range keys {
+		if key.Column == nil {
+			return nil, infoschema.ErrCannotAddForeign
+		}
 		fkInfo.Cols[i] = key.Column.Name
 	}
 
 	fkInfo.RefCols = make([]model.CIStr, len(refer.IndexColNames))
 	for i, key := range refer.IndexColNames {
+		if key.Column == nil {
+			return nil, infoschema.ErrCannotAddForeign
+		}
 		fkInfo.RefCols[i] = key.Column.Name
 	}
 
@@ -1497,7 +1940,8 @@ func (d *ddl) DropIndex(ctx context.Context, ti ast.Ident, indexName model.CIStr
 		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
 	}
 
-	if indexInfo := findIndexByName(indexName.L, t.Meta().Indices); indexInfo == nil {
+	indexInfo := findIndexByName(indexName.L, t.Meta().Indices)
+	if indexInfo == nil {
 		return ErrCantDropFieldOrKey.Gen("index %s doesn't exist", indexName)
 	}
 
@@ -1531,6 +1975,9 @@ func isDroppableColumn(tblInfo *model.TableInfo, colName model.CIStr) error {
 	for _, col := range tblInfo.Columns {
 		for dep := range col.Dependences {
 			if dep == colName.L {
+				if col.Hidden {
+					return errDependentByFunctionalIndex.GenByArgs(colName.O)
+				}
 				return errDependentByGeneratedColumn.GenByArgs(dep)
 			}
 		}
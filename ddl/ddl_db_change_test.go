@@ -99,6 +99,135 @@ func (s *testStateChangeSuite) TestTwoStates(c *C) {
 	// TODO: Add more DDL statements.
 }
 
+func (s *testStateChangeSuite) TestModifyColumnWithReorg(c *C) {
+	cnt := 5
+	// New the testExecInfo.
+	testInfo := &testExecInfo{
+		execCases: cnt,
+		sqlInfos:  make([]*sqlInfo, 3),
+	}
+	for i := 0; i < len(testInfo.sqlInfos); i++ {
+		sqlInfo := &sqlInfo{cases: make([]*stateCase, cnt)}
+		for j := 0; j < cnt; j++ {
+			sqlInfo.cases[j] = new(stateCase)
+		}
+		testInfo.sqlInfos[i] = sqlInfo
+	}
+	err := testInfo.createSessions(s.store, "test_db_state")
+	c.Assert(err, IsNil)
+	// Fill the SQLs and expected error messages.
+	testInfo.sqlInfos[0].sql = "insert into t (c1, c2, c3, c4) value(2, 'b', 'N', '2017-07-02')"
+	testInfo.sqlInfos[1].sql = "update t set c4 = '2017-07-10 10:00:00' where c1 = 1"
+	testInfo.sqlInfos[2].sql = "replace into t values(5, 'e', 'N', '2017-07-05')"
+	// The values of c4 are converted to datetime, and the changing column is backfilled.
+	alterTableSQL := "alter table t modify column c4 datetime"
+	s.test(c, "", alterTableSQL, testInfo)
+}
+
+func (s *testStateChangeSuite) TestModifyIndexedColumnWithReorg(c *C) {
+	cnt := 5
+	// New the testExecInfo.
+	testInfo := &testExecInfo{
+		execCases: cnt,
+		sqlInfos:  make([]*sqlInfo, 4),
+	}
+	for i := 0; i < len(testInfo.sqlInfos); i++ {
+		sqlInfo := &sqlInfo{cases: make([]*stateCase, cnt)}
+		for j := 0; j < cnt; j++ {
+			sqlInfo.cases[j] = new(stateCase)
+		}
+		testInfo.sqlInfos[i] = sqlInfo
+	}
+	err := testInfo.createSessions(s.store, "test_db_state")
+	c.Assert(err, IsNil)
+	// Fill the SQLs and expected error messages.
+	testInfo.sqlInfos[0].sql = "insert into t (c1, c2, c3, c4) value(2, 'b', 'N', '2017-07-02')"
+	testInfo.sqlInfos[1].sql = "update t set c2 = 'c' where c2 = 'b'"
+	testInfo.sqlInfos[2].sql = "delete from t where c2 = 'c' and c1 = 2 limit 1"
+	testInfo.sqlInfos[3].sql = "replace into t values(5, 'e', 'N', '2017-07-05')"
+	// The index key(c1, c2) is rebuilt with the converted values of c2.
+	alterTableSQL := "alter table t modify column c2 varchar(10)"
+	s.test(c, "", alterTableSQL, testInfo)
+}
+
+// TestModifyColumnAcrossSwap runs DML with the schema versions before and after the changing column takes the place
+// of the origin column, the values written by the new schema mustn't be overwritten by the old schema.
+func (s *testStateChangeSuite) TestModifyColumnAcrossSwap(c *C) {
+	defer testleak.AfterTest(c)()
+	_, err := s.se.Execute("create table t (c1 int, c2 varchar(64), c3 int, key(c2))")
+	c.Assert(err, IsNil)
+	defer s.se.Execute("drop table t")
+	_, err = s.se.Execute("insert into t values(1, '10', 1)")
+	c.Assert(err, IsNil)
+
+	// oldInfo is compiled with the schema before the swap, newInfo is compiled with the schema after the swap.
+	oldInfo := &testExecInfo{execCases: 1, sqlInfos: []*sqlInfo{{cases: []*stateCase{{}}}}}
+	newInfo := &testExecInfo{execCases: 1, sqlInfos: []*sqlInfo{{cases: []*stateCase{{}}}}}
+	c.Assert(oldInfo.createSessions(s.store, "test_db_state"), IsNil)
+	c.Assert(newInfo.createSessions(s.store, "test_db_state"), IsNil)
+	oldInfo.sqlInfos[0].sql = "update t set c3 = 3 where c1 = 1"
+	newInfo.sqlInfos[0].sql = "update t set c2 = 20 where c1 = 1"
+	c.Assert(oldInfo.parseSQLs(s.p), IsNil)
+	c.Assert(newInfo.parseSQLs(s.p), IsNil)
+
+	callback := &ddl.TestDDLCallback{}
+	var checkErr error
+	reorged, compiled, swapped := false, false, false
+	// The hook is called before the servers load the schema of the job state.
+	callback.OnJobUpdatedExported = func(job *model.Job) {
+		if checkErr != nil {
+			return
+		}
+		switch job.SchemaState {
+		case model.StateWriteReorganization:
+			reorged = true
+		case model.StateWriteOnly:
+			// The schema is in write reorganization state, the columns are swapped in the job state.
+			if !reorged || compiled {
+				return
+			}
+			compiled = true
+			if checkErr = oldInfo.compileSQL(0); checkErr != nil {
+				return
+			}
+			// The statement is executed after the swap, so it mustn't use the transaction started before.
+			checkErr = oldInfo.sqlInfos[0].cases[0].session.RollbackTxn()
+		case model.StateDeleteOnly:
+			// The schema is the one after the swap.
+			if !compiled || swapped {
+				return
+			}
+			swapped = true
+			if checkErr = newInfo.compileSQL(0); checkErr != nil {
+				return
+			}
+			if checkErr = newInfo.execSQL(0); checkErr != nil {
+				return
+			}
+			// Mock the server hasn't loaded the schema after the swap.
+			oldInfo.sqlInfos[0].cases[0].session.PrepareTxnCtx()
+			checkErr = oldInfo.execSQL(0)
+		}
+	}
+	d := s.dom.DDL()
+	d.SetHook(callback)
+	_, err = s.se.Execute("alter table t modify column c2 int")
+	c.Assert(err, IsNil)
+	d.SetHook(&ddl.TestDDLCallback{})
+	c.Assert(errors.ErrorStack(checkErr), Equals, "")
+	c.Assert(swapped, IsTrue)
+
+	rs, err := s.se.Execute("select c1, c2, c3 from t")
+	c.Assert(err, IsNil)
+	row, err := rs[0].Next()
+	c.Assert(err, IsNil)
+	c.Assert(row.Data[1].GetInt64(), Equals, int64(20))
+	c.Assert(row.Data[2].GetInt64(), Equals, int64(3))
+	c.Assert(rs[0].Close(), IsNil)
+	_, err = s.se.Execute("admin check table t")
+	c.Assert(err, IsNil)
+}
+
 func (s *testStateChangeSuite) test(c *C, tableName, alterTableSQL string, testInfo *testExecInfo) {
 	defer testleak.AfterTest(c)()
 	_, err := s.se.Execute(`create table t (
@@ -178,6 +307,8 @@ func (s *testStateChangeSuite) test(c *C, tableName, alterTableSQL string, testI
 	err = testInfo.execSQL(3)
 	c.Assert(err, IsNil)
 	c.Assert(errors.ErrorStack(checkErr), Equals, "")
+	_, err = s.se.Execute("admin check table t")
+	c.Assert(err, IsNil)
 	callback = &ddl.TestDDLCallback{}
 	d.SetHook(callback)
 }
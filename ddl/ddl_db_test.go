@@ -35,6 +35,7 @@ import (
 	tmysql "github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/store/localstore"
+	"github.com/pingcap/tidb/store/localstore/goleveldb"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
@@ -57,13 +58,16 @@ type testDBSuite struct {
 	lease      time.Duration
 }
 
+// shortSafePointDriver opens the stores which remove the dropped indices and tables soon after the DDL jobs are done.
+var shortSafePointDriver = localstore.Driver{Driver: goleveldb.MemoryDriver{}, SafePoint: 500}
+
 func (s *testDBSuite) SetUpSuite(c *C) {
 	var err error
 
 	s.lease = 200 * time.Millisecond
 	tidb.SetSchemaLease(s.lease)
 	s.schemaName = "test_db"
-	s.store, err = tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	s.store, err = shortSafePointDriver.Open("memory://test_db_suite")
 	c.Assert(err, IsNil)
 	localstore.MockRemoteStore = true
 
@@ -527,7 +531,7 @@ LOOP:
 	}
 	c.Assert(nidx, IsNil)
 
-	idx := tables.NewIndex(t.Meta(), c3idx.Meta())
+	idx := tables.NewIndex(t.Meta().ID, t.Meta(), c3idx.Meta())
 	f := func() map[int64]struct{} {
 		handles := make(map[int64]struct{})
 
@@ -1154,7 +1158,7 @@ func (s *testDBSuite) TestCreateTable(c *C) {
 
 func (s *testDBSuite) TestTruncateTable(c *C) {
 	defer testleak.AfterTest(c)
-	store, err := tidb.NewStore("memory://truncate_table")
+	store, err := shortSafePointDriver.Open("memory://truncate_table")
 	c.Assert(err, IsNil)
 	_, err = tidb.BootstrapSession(store)
 	c.Assert(err, IsNil)
@@ -1450,3 +1454,92 @@ func (s *testDBSuite) TestGeneratedColumnDDL(c *C) {
 	result = s.tk.MustQuery(`DESC test_gv_ddl`)
 	result.Check(testkit.Rows(`a int(11) YES  <nil> `, `b bigint(20) YES  <nil> VIRTUAL GENERATED`, `cnew bigint(20) YES  <nil> `))
 }
+
+func (s *testDBSuite) TestFunctionalIndexDDL(c *C) {
+	defer func() {
+		testleak.AfterTest(c)()
+	}()
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use test")
+
+	// Check create table with functional indices.
+	s.tk.MustExec(`create table test_fi_ddl (a int, b varchar(20), c json, index ia((a + 1)), index ib((lower(b))), index ic((cast(c->'$.a' as signed)), a))`)
+	result := s.tk.MustQuery(`desc test_fi_ddl`)
+	result.Check(testkit.Rows(`a int(11) YES  <nil> `, `b varchar(20) YES  <nil> `, `c json YES  <nil> `))
+	result = s.tk.MustQuery(`show create table test_fi_ddl`)
+	result.Check(testkit.Rows(
+		"test_fi_ddl CREATE TABLE `test_fi_ddl` (\n  `a` int(11) DEFAULT NULL,\n  `b` varchar(20) DEFAULT NULL,\n  `c` json DEFAULT NULL,\n" +
+			"  KEY `ia` ((a+1)),\n  KEY `ib` ((lower(b))),\n  KEY `ic` ((cast(c->'$.a' as signed)),`a`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin",
+	))
+
+	// Check create index with functional key parts on a table with data.
+	s.tk.MustExec(`insert into test_fi_ddl values (1, 'Abc', '{"a": 1}'), (2, 'xYz', '{"a": 2}')`)
+	s.tk.MustExec(`create index id on test_fi_ddl ((a * 2))`)
+	s.tk.MustExec(`alter table test_fi_ddl add index ie ((concat(b, 'x')))`)
+	s.tk.MustExec(`admin check table test_fi_ddl`)
+	s.tk.MustQuery(`select a from test_fi_ddl use index(id) where a * 2 = 4`).Check(testkit.Rows("2"))
+
+	// Check the hidden columns are added and dropped in the same job as the index.
+	historyJobCount := func() int {
+		var jobs []*model.Job
+		err := kv.RunInNewTxn(s.store, false, func(txn kv.Transaction) error {
+			var err error
+			jobs, err = meta.NewMeta(txn).GetAllHistoryDDLJobs()
+			return errors.Trace(err)
+		})
+		c.Assert(err, IsNil)
+		return len(jobs)
+	}
+	jobCount := historyJobCount()
+	s.tk.MustExec(`create index ig on test_fi_ddl ((a + 2), (upper(b)))`)
+	c.Assert(historyJobCount(), Equals, jobCount+1)
+	s.tk.MustExec(`admin check table test_fi_ddl`)
+	s.tk.MustExec(`drop index ig on test_fi_ddl`)
+	c.Assert(historyJobCount(), Equals, jobCount+2)
+	// The hidden columns are rolled back with the index.
+	s.testErrorCode(c, `create unique index iu on test_fi_ddl ((a * 0))`, tmysql.ErrDupEntry)
+	c.Assert(historyJobCount(), Equals, jobCount+3)
+
+	// Check drop index removes the hidden columns.
+	s.tk.MustExec(`drop index id on test_fi_ddl`)
+	s.tk.MustExec(`alter table test_fi_ddl drop index ie`)
+	ctx := s.tk.Se.(context.Context)
+	is := sessionctx.GetDomain(ctx).InfoSchema()
+	tbl, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("test_fi_ddl"))
+	c.Assert(err, IsNil)
+	c.Assert(tbl.Cols(), HasLen, 6)
+	for _, col := range tbl.Cols()[3:] {
+		c.Assert(col.Hidden, IsTrue)
+		c.Assert(col.IsGenerated(), IsTrue)
+	}
+
+	genExprTests := []struct {
+		stmt string
+		err  int
+	}{
+		// key parts of json or geometry type.
+		{`create index if1 on test_fi_ddl ((c->'$.a'))`, mysql.ErrFunctionalIndexOnJSONOrGeometryFunction},
+		// key parts of a bare column.
+		{`create index if2 on test_fi_ddl ((a))`, mysql.ErrFunctionalIndexOnField},
+		// functional primary key.
+		{`create table test_fi_ddl_bad (a int, primary key((a + 1)))`, mysql.ErrFunctionalIndexPrimaryKey},
+		{`alter table test_fi_ddl add primary key((a + 1))`, mysql.ErrFunctionalIndexPrimaryKey},
+		// key parts of lob type.
+		{`create index if3 on test_fi_ddl ((c->>'$.a'))`, mysql.ErrFunctionalIndexOnLob},
+		// drop/modify columns dependent by functional indices.
+		{`alter table test_fi_ddl drop column b`, mysql.ErrDependentByFunctionalIndex},
+		{`alter table test_fi_ddl modify column b varchar(10)`, mysql.ErrDependentByFunctionalIndex},
+		// hidden columns are invisible.
+		{`alter table test_fi_ddl drop column _V$_ia_0`, mysql.ErrCantDropFieldOrKey},
+		{`alter table test_fi_ddl modify column _V$_ia_0 bigint`, mysql.ErrBadField},
+		// refer not exist columns.
+		{`create index if4 on test_fi_ddl ((d + 1))`, mysql.ErrBadField},
+	}
+	for _, tt := range genExprTests {
+		s.testErrorCode(c, tt.stmt, tt.err)
+	}
+	is = sessionctx.GetDomain(ctx).InfoSchema()
+	tbl, err = is.TableByName(model.NewCIStr("test"), model.NewCIStr("test_fi_ddl"))
+	c.Assert(err, IsNil)
+	c.Assert(tbl.Cols(), HasLen, 6)
+}
@@ -109,7 +109,8 @@ func (d *ddl) updateDDLJob(t *meta.Meta, job *model.Job, updateTS uint64) error
 // If the DDL job need to handle in background, it will prepare a background job.
 func (d *ddl) finishDDLJob(t *meta.Meta, job *model.Job) (err error) {
 	switch job.Type {
-	case model.ActionDropSchema, model.ActionDropTable, model.ActionTruncateTable, model.ActionDropIndex:
+	case model.ActionDropSchema, model.ActionDropTable, model.ActionTruncateTable, model.ActionDropIndex,
+		model.ActionDropTablePartition, model.ActionTruncateTablePartition, model.ActionModifyColumn:
 		if job.Version <= currentVersion {
 			err = d.delRangeManager.addDelRangeJob(job)
 		} else {
@@ -277,6 +278,16 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) (ver int64) {
 		ver, err = d.onRenameTable(t, job)
 	case model.ActionSetDefaultValue:
 		ver, err = d.onSetDefaultValue(t, job)
+	case model.ActionAddTablePartition:
+		ver, err = d.onAddTablePartition(t, job)
+	case model.ActionDropTablePartition:
+		ver, err = d.onDropTablePartition(t, job)
+	case model.ActionTruncateTablePartition:
+		ver, err = d.onTruncateTablePartition(t, job)
+	case model.ActionCreateView:
+		ver, err = d.onCreateView(t, job)
+	case model.ActionDropView:
+		ver, err = d.onDropView(t, job)
 	default:
 		// Invalid job, cancel it.
 		job.State = model.JobCancelled
@@ -18,6 +18,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
@@ -39,6 +40,10 @@ const (
 
 	delBatchSize int = 65536
 	delBackLog       = 128
+
+	// delRangeCheckInterval is the interval of checking the delete-range jobs which have passed the safe point
+	// of the rangeDeleter.
+	delRangeCheckInterval = 500 * time.Millisecond
 )
 
 type delRangeManager interface {
@@ -48,10 +53,23 @@ type delRangeManager interface {
 	clear()
 }
 
+// rangeDeleter is implemented by the storages which can delete the keys in a range by
+// themselves, like localstore. The delete-range jobs of them are done by delRange instead
+// of the GC worker.
+type rangeDeleter interface {
+	// DeleteRange deletes all the versions of the keys in the range [startKey, endKey).
+	DeleteRange(startKey, endKey kv.Key) error
+	// SafePoint returns the time before which the versions may be removed. The ranges are
+	// deleted after the safe point passes the time they are added, so the snapshots taken
+	// before the DDL jobs are done can still read them.
+	SafePoint() time.Time
+}
+
 type delRange struct {
 	d            *ddl
 	ctxPool      *pools.ResourcePool
 	storeSupport bool
+	deleter      rangeDeleter
 	emulatorCh   chan struct{}
 	keys         []kv.Key
 }
@@ -63,13 +81,22 @@ func newDelRangeManager(d *ddl, ctxPool *pools.ResourcePool, supportDelRange boo
 		ctxPool:      ctxPool,
 		storeSupport: supportDelRange,
 	}
-	if !dr.storeSupport {
-		dr.emulatorCh = make(chan struct{}, delBackLog)
+	if dr.storeSupport {
+		dr.deleter, _ = d.store.(rangeDeleter)
+	} else {
 		dr.keys = make([]kv.Key, 0, delBatchSize)
 	}
+	if dr.runLocally() {
+		dr.emulatorCh = make(chan struct{}, delBackLog)
+	}
 	return dr
 }
 
+// runLocally returns whether the delete-range jobs are done by delRange itself.
+func (dr *delRange) runLocally() bool {
+	return !dr.storeSupport || dr.deleter != nil
+}
+
 // addDelRangeJob implements delRangeManager interface.
 func (dr *delRange) addDelRangeJob(job *model.Job) error {
 	resource, err := dr.ctxPool.Get()
@@ -85,7 +112,7 @@ func (dr *delRange) addDelRangeJob(job *model.Job) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
-	if !dr.storeSupport {
+	if dr.runLocally() {
 		dr.emulatorCh <- struct{}{}
 	}
 	log.Infof("[ddl] add job (%d,%s) into delete-range table", job.ID, job.Type.String())
@@ -94,7 +121,7 @@ func (dr *delRange) addDelRangeJob(job *model.Job) error {
 
 // start implements delRangeManager interface.
 func (dr *delRange) start() {
-	if !dr.storeSupport {
+	if dr.runLocally() {
 		dr.d.wait.Add(1)
 		go dr.startEmulator()
 	}
@@ -107,14 +134,23 @@ func (dr *delRange) clear() {
 }
 
 // startEmulator is only used for those storage engines which don't support
-// delete-range. The emulator fetches records from gc_delete_range table and
-// deletes all keys in each DelRangeTask.
+// delete-range or delete the ranges by themselves. The emulator fetches records
+// from gc_delete_range table and deletes all keys in each DelRangeTask.
 func (dr *delRange) startEmulator() {
 	defer dr.d.wait.Done()
 	log.Infof("[ddl] start delRange emulator")
+	// The ranges of the rangeDeleter are kept in gc_delete_range until they pass the safe point,
+	// so they are checked periodically, including the ones left by the previous run.
+	var tickerCh <-chan time.Time
+	if dr.deleter != nil {
+		ticker := time.NewTicker(delRangeCheckInterval)
+		defer ticker.Stop()
+		tickerCh = ticker.C
+	}
 	for {
 		select {
 		case <-dr.emulatorCh:
+		case <-tickerCh:
 		case <-dr.d.quitCh:
 			return
 		}
@@ -133,7 +169,12 @@ func (dr *delRange) doDelRangeWork() error {
 	ctx.GetSessionVars().SetStatusFlag(mysql.ServerStatusAutocommit, true)
 	ctx.GetSessionVars().InRestrictedSQL = true
 
-	ranges, err := LoadDeleteRanges(ctx, math.MaxInt64)
+	var safePoint uint64 = math.MaxInt64
+	if dr.deleter != nil {
+		// The ts of the tasks is in second.
+		safePoint = uint64(dr.deleter.SafePoint().Unix())
+	}
+	ranges, err := LoadDeleteRanges(ctx, safePoint)
 	if err != nil {
 		log.Errorf("[dd] delRange emulator load tasks fail: %s", err)
 		return errors.Trace(err)
@@ -149,6 +190,19 @@ func (dr *delRange) doDelRangeWork() error {
 }
 
 func (dr *delRange) doTask(ctx context.Context, r DelRangeTask) error {
+	if dr.deleter != nil {
+		// The task is completed after the keys are deleted, so it's done again if the server restarts before.
+		if err := dr.deleter.DeleteRange(r.startKey, r.endKey); err != nil {
+			return errors.Trace(err)
+		}
+		if err := CompleteDeleteRange(ctx, r); err != nil {
+			log.Errorf("[ddl] delRange complete task fail: %s", err)
+			return errors.Trace(err)
+		}
+		log.Infof("[ddl] delRange complete task: (%d, %d)", r.jobID, r.elementID)
+		return nil
+	}
+
 	var oldStartKey, newStartKey kv.Key
 	oldStartKey = r.startKey
 	for {
@@ -206,8 +260,8 @@ func (dr *delRange) doTask(ctx context.Context, r DelRangeTask) error {
 }
 
 // insertJobIntoDeleteRangeTable parses the job into delete-range arguments,
-// and inserts a new record into gc_delete_range table. The primary key is
-// job ID, so we ignore key conflict error.
+// and inserts a new record into gc_delete_range table. The unique key is
+// (job ID, element ID), so we ignore key conflict error.
 func insertJobIntoDeleteRangeTable(ctx context.Context, job *model.Job) error {
 	now, err := getNowTS(ctx)
 	if err != nil {
@@ -230,19 +284,71 @@ func insertJobIntoDeleteRangeTable(ctx context.Context, job *model.Job) error {
 		}
 	case model.ActionDropTable, model.ActionTruncateTable:
 		tableID := job.TableID
-		startKey := tablecodec.EncodeTablePrefix(tableID)
+		// The partition IDs are only set for partitioned tables.
+		var startKey kv.Key
+		var partitionIDs []int64
+		if err := job.DecodeArgs(&startKey, &partitionIDs); err != nil {
+			return errors.Trace(err)
+		}
+		for _, pid := range partitionIDs {
+			startKey = tablecodec.EncodeTablePrefix(pid)
+			endKey := tablecodec.EncodeTablePrefix(pid + 1)
+			if err := doInsert(s, job.ID, pid, startKey, endKey, now); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		startKey = tablecodec.EncodeTablePrefix(tableID)
 		endKey := tablecodec.EncodeTablePrefix(tableID + 1)
 		return doInsert(s, job.ID, tableID, startKey, endKey, now)
+	case model.ActionDropTablePartition, model.ActionTruncateTablePartition:
+		var partitionID int64
+		if err := job.DecodeArgs(&partitionID); err != nil {
+			return errors.Trace(err)
+		}
+		startKey := tablecodec.EncodeTablePrefix(partitionID)
+		endKey := tablecodec.EncodeTablePrefix(partitionID + 1)
+		return doInsert(s, job.ID, partitionID, startKey, endKey, now)
 	case model.ActionDropIndex:
 		tableID := job.TableID
 		var indexName interface{}
 		var indexID int64
-		if err := job.DecodeArgs(&indexName, &indexID); err != nil {
+		var partitionIDs []int64
+		if err := job.DecodeArgs(&indexName, &indexID, &partitionIDs); err != nil {
 			return errors.Trace(err)
 		}
+		for _, pid := range partitionIDs {
+			startKey := tablecodec.EncodeTableIndexPrefix(pid, indexID)
+			endKey := tablecodec.EncodeTableIndexPrefix(pid, indexID+1)
+			if err := doInsert(s, job.ID, pid, startKey, endKey, now); err != nil {
+				return errors.Trace(err)
+			}
+		}
 		startKey := tablecodec.EncodeTableIndexPrefix(tableID, indexID)
 		endKey := tablecodec.EncodeTableIndexPrefix(tableID, indexID+1)
 		return doInsert(s, job.ID, indexID, startKey, endKey, now)
+	case model.ActionModifyColumn:
+		// The origin indices are dropped if the job is done, and the changing indices are dropped if the job is
+		// rolled back. Only the job converting the data of the column with index covered has the index IDs.
+		var indexIDs []int64
+		var err error
+		if job.State == model.JobRollbackDone {
+			var changingName interface{}
+			err = job.DecodeArgs(&changingName, &indexIDs)
+		} else {
+			var newCol, oldName, pos, needReorg interface{}
+			err = job.DecodeArgs(&newCol, &oldName, &pos, &needReorg, &indexIDs)
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+		tableID := job.TableID
+		for _, indexID := range indexIDs {
+			startKey := tablecodec.EncodeTableIndexPrefix(tableID, indexID)
+			endKey := tablecodec.EncodeTableIndexPrefix(tableID, indexID+1)
+			if err := doInsert(s, job.ID, indexID, startKey, endKey, now); err != nil {
+				return errors.Trace(err)
+			}
+		}
 	}
 	return nil
 }
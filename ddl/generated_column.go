@@ -14,9 +14,17 @@
 package ddl
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/types"
 )
 
 // columnGenerationInDDL is a struct for validating generated columns in DDL.
@@ -99,6 +107,22 @@ func (c *generatedColumnChecker) Leave(inNode ast.Node) (node ast.Node, ok bool)
 	return inNode, true
 }
 
+// columnReferResolver sets the referred columns of the column name expressions by cols.
+type columnReferResolver struct {
+	cols []*model.ColumnInfo
+}
+
+func (r *columnReferResolver) Enter(inNode ast.Node) (outNode ast.Node, skipChildren bool) {
+	return inNode, false
+}
+
+func (r *columnReferResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
+	if x, ok := inNode.(*ast.ColumnNameExpr); ok {
+		x.Refer = &ast.ResultField{Column: findCol(r.cols, x.Name.Name.L)}
+	}
+	return inNode, true
+}
+
 // checkModifyGeneratedColumn checks the modification between
 // old and new is valid or not by such rules:
 //  1. the modification can't change stored status;
@@ -153,3 +177,80 @@ func checkModifyGeneratedColumn(originCols []*table.Column, oldCol, newCol *tabl
 	}
 	return nil
 }
+
+// hiddenColumnPrefix is the name prefix of the hidden columns, which are the virtual generated columns
+// added for the expression key parts of the indices.
+const hiddenColumnPrefix = "_V$_"
+
+// anonymousFunctionalIndexName is used to name the anonymous index whose first key part is an expression.
+var anonymousFunctionalIndexName = model.NewCIStr("functional_index")
+
+// buildHiddenColumnDefs builds the definitions of the hidden columns for the expression key parts of
+// the index indexName. It returns the key parts in which the expressions are replaced by the hidden
+// columns. cols are the columns which the expressions can refer to.
+func buildHiddenColumnDefs(ctx context.Context, tblName model.CIStr, cols []*model.ColumnInfo, indexName string,
+	keys []*ast.IndexColName) ([]*ast.ColumnDef, []*ast.IndexColName, error) {
+	var colDefs []*ast.ColumnDef
+	newKeys := make([]*ast.IndexColName, 0, len(keys))
+	for i, key := range keys {
+		if key.Expr == nil {
+			newKeys = append(newKeys, key)
+			continue
+		}
+		if _, ok := key.Expr.(*ast.ColumnNameExpr); ok {
+			return nil, nil, errFunctionalIndexOnField
+		}
+		for _, depCol := range findColumnNamesInExpr(key.Expr) {
+			if findCol(cols, depCol.Name.L) == nil {
+				return nil, nil, errBadField.GenByArgs(depCol.Name.O, "functional index")
+			}
+		}
+		// The type of the hidden column is the type of the expression, so resolve the columns and infer it.
+		key.Expr.Accept(&columnReferResolver{cols: cols})
+		if err := expression.InferType(ctx.GetSessionVars().StmtCtx, key.Expr); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		schema := expression.NewSchema(expression.ColumnInfos2Columns(tblName, cols)...)
+		expr, err := expression.RewriteAstExpr(key.Expr, schema, ctx)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		tp, err := hiddenColumnType(expr.GetType())
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		colName := &ast.ColumnName{Name: model.NewCIStr(fmt.Sprintf("%s%s_%d", hiddenColumnPrefix, indexName, i))}
+		colDefs = append(colDefs, &ast.ColumnDef{
+			Name:    colName,
+			Tp:      tp,
+			Options: []*ast.ColumnOption{{Tp: ast.ColumnOptionGenerated, Expr: key.Expr}},
+		})
+		newKeys = append(newKeys, &ast.IndexColName{Column: colName, Length: types.UnspecifiedLength})
+	}
+	return colDefs, newKeys, nil
+}
+
+// hiddenColumnType returns the type of the hidden column whose generation expression is of type tp.
+func hiddenColumnType(tp *types.FieldType) (*types.FieldType, error) {
+	if tp.Tp == mysql.TypeJSON || tp.Tp == mysql.TypeGeometry {
+		return nil, errFunctionalIndexOnJSONOrGeometryFunction
+	}
+	if types.IsTypeBlob(tp.Tp) {
+		return nil, errFunctionalIndexOnLob
+	}
+	newTp := *tp
+	if (types.IsTypeChar(newTp.Tp) || types.IsTypeVarchar(newTp.Tp)) &&
+		(newTp.Flen == types.UnspecifiedLength || newTp.Flen > mysql.MaxFieldVarCharLength) {
+		return nil, errFunctionalIndexOnLob
+	}
+	if newTp.Tp == mysql.TypeVarString {
+		newTp.Tp = mysql.TypeVarchar
+	}
+	newTp.Flag &= mysql.UnsignedFlag | mysql.BinaryFlag
+	return &newTp, nil
+}
+
+// isHiddenColumnName checks whether name is the name of a hidden column of indexName.
+func isHiddenColumnName(name model.CIStr, indexName model.CIStr) bool {
+	return strings.HasPrefix(name.L, strings.ToLower(hiddenColumnPrefix)+indexName.L+"_")
+}
@@ -22,6 +22,8 @@ import (
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
@@ -57,8 +59,8 @@ func buildIndexColumns(columns []*model.ColumnInfo, idxColNames []*ast.IndexColN
 			return nil, errors.Trace(errJSONUsedAsKey.GenByArgs(col.Name.O))
 		}
 
-		// Length must be specified for BLOB and TEXT column indexes.
-		if types.IsTypeBlob(col.FieldType.Tp) && ic.Length == types.UnspecifiedLength {
+		// Length must be specified for BLOB, TEXT and GEOMETRY column indexes.
+		if (types.IsTypeBlob(col.FieldType.Tp) || col.FieldType.Tp == mysql.TypeGeometry) && ic.Length == types.UnspecifiedLength {
 			return nil, errors.Trace(errBlobKeyWithoutLength)
 		}
 
@@ -194,8 +196,9 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) (ver int64, err error)
 		indexName   model.CIStr
 		idxColNames []*ast.IndexColName
 		indexOption *ast.IndexOption
+		hiddenCols  []*model.ColumnInfo
 	)
-	err = job.DecodeArgs(&unique, &indexName, &idxColNames, &indexOption)
+	err = job.DecodeArgs(&unique, &indexName, &idxColNames, &indexOption, &hiddenCols)
 	if err != nil {
 		job.State = model.JobCancelled
 		return ver, errors.Trace(err)
@@ -204,10 +207,23 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) (ver int64, err error)
 	indexInfo := findIndexByName(indexName.L, tblInfo.Indices)
 	if indexInfo != nil && indexInfo.State == model.StatePublic {
 		job.State = model.JobCancelled
-		return ver, errDupKeyName.Gen("index already exist %s", indexName)
+		return ver, ErrDupKeyName.Gen("index already exist %s", indexName)
 	}
 
 	if indexInfo == nil {
+		// The hidden columns are virtual, so they are public at once, there are no values to be filled.
+		for _, col := range hiddenCols {
+			if findCol(tblInfo.Columns, col.Name.L) != nil {
+				job.State = model.JobCancelled
+				return ver, infoschema.ErrColumnExists.GenByArgs(col.Name)
+			}
+			_, _, err = d.createColumnInfo(tblInfo, col, &ast.ColumnPosition{Tp: ast.ColumnPositionNone})
+			if err != nil {
+				job.State = model.JobCancelled
+				return ver, errors.Trace(err)
+			}
+			col.State = model.StatePublic
+		}
 		indexInfo, err = buildIndexInfo(tblInfo, indexName, idxColNames, model.StateNone)
 		if err != nil {
 			job.State = model.JobCancelled
@@ -365,6 +381,7 @@ func (d *ddl) onDropIndex(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 		tblInfo.Indices = newIndices
 		// Set column index flag.
 		dropIndexColumnFlag(tblInfo, indexInfo)
+		hiddenCols := dropHiddenColumns(tblInfo, indexInfo)
 
 		job.SchemaState = model.StateNone
 		ver, err = updateTableInfo(t, job, tblInfo, originalState)
@@ -379,14 +396,53 @@ func (d *ddl) onDropIndex(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 			job.State = model.JobDone
 		}
 		job.BinlogInfo.AddTableInfo(ver, tblInfo)
-		job.Args = append(job.Args, indexInfo.ID)
+		// The partition IDs are only set for partitioned tables.
+		job.Args = append(job.Args, indexInfo.ID, getPartitionIDs(tblInfo))
 		d.asyncNotifyEvent(&Event{Tp: model.ActionDropIndex, TableInfo: tblInfo, IndexInfo: indexInfo})
+		for _, col := range hiddenCols {
+			d.asyncNotifyEvent(&Event{Tp: model.ActionDropColumn, TableInfo: tblInfo, ColumnInfo: col})
+		}
 	default:
 		err = ErrInvalidTableState.Gen("invalid table state %v", tblInfo.State)
 	}
 	return ver, errors.Trace(err)
 }
 
+// dropHiddenColumns removes the hidden columns of the index from the table and returns them,
+// the offsets of the remaining columns and the index columns are adjusted.
+func dropHiddenColumns(tblInfo *model.TableInfo, indexInfo *model.IndexInfo) []*model.ColumnInfo {
+	var hiddenCols []*model.ColumnInfo
+	for _, idxCol := range indexInfo.Columns {
+		col := findCol(tblInfo.Columns, idxCol.Name.L)
+		if col != nil && col.Hidden && isHiddenColumnName(col.Name, indexInfo.Name) {
+			hiddenCols = append(hiddenCols, col)
+		}
+	}
+	if len(hiddenCols) == 0 {
+		return nil
+	}
+
+	offsetChanged := make(map[int]int)
+	newColumns := make([]*model.ColumnInfo, 0, len(tblInfo.Columns)-len(hiddenCols))
+	for _, col := range tblInfo.Columns {
+		if col.Hidden && isHiddenColumnName(col.Name, indexInfo.Name) {
+			continue
+		}
+		offsetChanged[col.Offset] = len(newColumns)
+		col.Offset = len(newColumns)
+		newColumns = append(newColumns, col)
+	}
+	tblInfo.Columns = newColumns
+	for _, idx := range tblInfo.Indices {
+		for _, col := range idx.Columns {
+			if newOffset, ok := offsetChanged[col.Offset]; ok {
+				col.Offset = newOffset
+			}
+		}
+	}
+	return hiddenCols
+}
+
 func (d *ddl) fetchRowColVals(txn kv.Transaction, t table.Table, taskOpInfo *indexTaskOpInfo, handleInfo *handleInfo) (
 	[]*indexRecord, *taskResult) {
 	startTime := time.Now()
@@ -445,33 +501,71 @@ func (d *ddl) getIndexRecords(t table.Table, taskOpInfo *indexTaskOpInfo, rawRec
 		if err != nil {
 			return errors.Trace(err)
 		}
+		if taskOpInfo.hasVirtualCol {
+			idxRecord.vals, err = getIndexValsWithVirtualCols(ctx, t, idxInfo, idxRecord.handle, rowMap, defaultVals)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			continue
+		}
 		idxVal := make([]types.Datum, len(idxInfo.Columns))
 		for j, v := range idxInfo.Columns {
 			col := cols[v.Offset]
-			if col.IsPKHandleColumn(t.Meta()) {
-				if mysql.HasUnsignedFlag(col.Flag) {
-					idxVal[j].SetUint64(uint64(idxRecord.handle))
-				} else {
-					idxVal[j].SetInt64(idxRecord.handle)
-				}
-				continue
-			}
-			idxColumnVal := rowMap[col.ID]
-			if _, ok := rowMap[col.ID]; ok {
-				idxVal[j] = idxColumnVal
-				continue
-			}
-			idxColumnVal, err = tables.GetColDefaultValue(ctx, col, defaultVals)
+			idxVal[j], err = getColumnVal(ctx, t, col, idxRecord.handle, rowMap, defaultVals)
 			if err != nil {
 				return errors.Trace(err)
 			}
-			idxVal[j] = idxColumnVal
 		}
 		idxRecord.vals = idxVal
 	}
 	return nil
 }
 
+// getColumnVal gets the value of the stored column from the decoded row.
+func getColumnVal(ctx context.Context, t table.Table, col *table.Column, handle int64, rowMap map[int64]types.Datum,
+	defaultVals []types.Datum) (types.Datum, error) {
+	var val types.Datum
+	if col.IsPKHandleColumn(t.Meta()) {
+		if mysql.HasUnsignedFlag(col.Flag) {
+			val.SetUint64(uint64(handle))
+		} else {
+			val.SetInt64(handle)
+		}
+		return val, nil
+	}
+	if val, ok := rowMap[col.ID]; ok {
+		return val, nil
+	}
+	val, err := tables.GetColDefaultValue(ctx, col, defaultVals)
+	return val, errors.Trace(err)
+}
+
+// getIndexValsWithVirtualCols gets the index values when the index contains virtual generated columns,
+// the virtual generated columns are evaluated with the whole row.
+func getIndexValsWithVirtualCols(ctx context.Context, t table.Table, idxInfo *model.IndexInfo, handle int64,
+	rowMap map[int64]types.Datum, defaultVals []types.Datum) ([]types.Datum, error) {
+	cols := t.Cols()
+	row := make([]types.Datum, len(cols))
+	for i, col := range cols {
+		if col.IsGenerated() && !col.GeneratedStored {
+			continue
+		}
+		var err error
+		row[i], err = getColumnVal(ctx, t, col, handle, rowMap, defaultVals)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if err := tables.FillVirtualColumnValues(ctx, t, row); err != nil {
+		return nil, errors.Trace(err)
+	}
+	idxVal := make([]types.Datum, len(idxInfo.Columns))
+	for j, v := range idxInfo.Columns {
+		idxVal[j] = row[v.Offset]
+	}
+	return idxVal, nil
+}
+
 const (
 	defaultBatchCnt      = 1024
 	defaultSmallBatchCnt = 128
@@ -501,10 +595,11 @@ type indexRecord struct {
 
 // indexTaskOpInfo records the information that is needed in the task.
 type indexTaskOpInfo struct {
-	tblIndex  table.Index
-	colMap    map[int64]*types.FieldType // It's the index columns map.
-	taskRetCh chan *taskResult           // Get the results of all tasks.
-	nextCh    chan int64                 // It notifies to start the next task.
+	tblIndex      table.Index
+	colMap        map[int64]*types.FieldType // It's the index columns map.
+	hasVirtualCol bool                       // It's true if the index contains virtual generated columns.
+	taskRetCh     chan *taskResult           // Get the results of all tasks.
+	nextCh        chan int64                 // It notifies to start the next task.
 }
 
 // addTableIndex adds index into table.
@@ -526,22 +621,72 @@ type indexTaskOpInfo struct {
 // task results, get the total number of rows in the concurrent task and update the processed handle value. If
 // an error message is displayed, exit the traversal.
 // Finally, update the concurrent processing of the total number of rows, and store the completed handle value.
+// The index of a partitioned table is added partition by partition, the partition being processed is stored with
+// the handle, so the reorganization resumes from it.
 func (d *ddl) addTableIndex(t table.Table, indexInfo *model.IndexInfo, reorgInfo *reorgInfo, job *model.Job) error {
+	addedCount := job.GetRowCount()
+	pt, ok := t.(table.PartitionedTable)
+	if !ok {
+		_, err := d.addPhysicalTableIndex(t, t.Meta().ID, indexInfo, reorgInfo, addedCount)
+		return errors.Trace(err)
+	}
+	defs := t.Meta().Partition.Definitions
+	start := 0
+	for i, def := range defs {
+		if def.ID == reorgInfo.PhysicalTableID {
+			start = i
+			break
+		}
+	}
+	for _, def := range defs[start:] {
+		if def.ID != reorgInfo.PhysicalTableID {
+			err := kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
+				return errors.Trace(reorgInfo.UpdatePhysicalTableID(txn, def.ID))
+			})
+			if err != nil {
+				return errors.Trace(err)
+			}
+			reorgInfo.PhysicalTableID, reorgInfo.Handle = def.ID, 0
+		}
+		var err error
+		addedCount, err = d.addPhysicalTableIndex(pt.GetPartition(def.ID), def.ID, indexInfo, reorgInfo, addedCount)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// addPhysicalTableIndex adds the index into a non-partitioned table or a partition, it returns the total count of
+// the added rows.
+func (d *ddl) addPhysicalTableIndex(t table.Table, physicalID int64, indexInfo *model.IndexInfo, reorgInfo *reorgInfo,
+	addedCount int64) (int64, error) {
 	cols := t.Cols()
 	colMap := make(map[int64]*types.FieldType)
+	idxCols := make([]*table.Column, 0, len(indexInfo.Columns))
 	for _, v := range indexInfo.Columns {
 		col := cols[v.Offset]
 		colMap[col.ID] = &col.FieldType
+		idxCols = append(idxCols, col)
+	}
+	hasVirtualCol := tables.HasVirtualColumn(idxCols)
+	if hasVirtualCol {
+		// The virtual generated columns depend on the other columns, so decode all the stored columns.
+		for _, col := range cols {
+			if !col.IsGenerated() || col.GeneratedStored {
+				colMap[col.ID] = &col.FieldType
+			}
+		}
 	}
 	taskCnt := defaultTaskCnt
 	taskOpInfo := &indexTaskOpInfo{
-		tblIndex:  tables.NewIndex(t.Meta(), indexInfo),
-		colMap:    colMap,
-		nextCh:    make(chan int64, 1),
-		taskRetCh: make(chan *taskResult, taskCnt),
+		tblIndex:      tables.NewIndex(physicalID, t.Meta(), indexInfo),
+		colMap:        colMap,
+		hasVirtualCol: hasVirtualCol,
+		nextCh:        make(chan int64, 1),
+		taskRetCh:     make(chan *taskResult, taskCnt),
 	}
 
-	addedCount := job.GetRowCount()
 	taskStartHandle := reorgInfo.Handle
 
 	for {
@@ -580,7 +725,7 @@ func (d *ddl) addTableIndex(t table.Table, indexInfo *model.IndexInfo, reorgInfo
 		if err != nil {
 			log.Warnf("[ddl] total added index for %d rows, this task add index for %d failed, take time %v",
 				addedCount, taskAddedCount, sub)
-			return errors.Trace(err)
+			return addedCount, errors.Trace(err)
 		}
 		d.setReorgRowCount(addedCount)
 		batchHandleDataHistogram.WithLabelValues(batchAddIdx).Observe(sub)
@@ -588,7 +733,7 @@ func (d *ddl) addTableIndex(t table.Table, indexInfo *model.IndexInfo, reorgInfo
 			addedCount, taskAddedCount, sub)
 
 		if retCnt < taskCnt {
-			return nil
+			return addedCount, nil
 		}
 	}
 }
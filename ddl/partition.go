@@ -0,0 +1,404 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/table"
+)
+
+// buildPartitionInfo builds the partition info of the table from the PARTITION BY clause.
+func (d *ddl) buildPartitionInfo(ctx context.Context, tbInfo *model.TableInfo, opts *ast.PartitionOptions) (*model.PartitionInfo, error) {
+	if err := checkPartitionExpr(ctx, tbInfo, opts.Expr); err != nil {
+		return nil, errors.Trace(err)
+	}
+	pi := &model.PartitionInfo{
+		Type: opts.Tp,
+		Expr: strings.TrimSpace(opts.Expr.Text()),
+		Num:  opts.Num,
+	}
+	if len(opts.Definitions) == 0 {
+		if opts.Tp != model.PartitionTypeHash {
+			return nil, ErrPartitionsMustBeDefined.GenByArgs(opts.Tp)
+		}
+		if pi.Num == 0 {
+			pi.Num = 1
+		}
+		for i := uint64(0); i < pi.Num; i++ {
+			pi.Definitions = append(pi.Definitions, model.PartitionDefinition{Name: model.NewCIStr(fmt.Sprintf("p%d", i))})
+		}
+	} else {
+		defs, err := buildPartitionDefinitions(ctx, opts.Tp, opts.Definitions)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		pi.Definitions = defs
+		pi.Num = uint64(len(defs))
+	}
+	if err := checkPartitionDefinitions(pi); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := checkUniqueKeysInPartitionExpr(tbInfo, opts.Expr); err != nil {
+		return nil, errors.Trace(err)
+	}
+	for i := range pi.Definitions {
+		id, err := d.genGlobalID()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		pi.Definitions[i].ID = id
+	}
+	return pi, nil
+}
+
+// checkPartitionExpr checks that the partition expression only refers to the columns of the table.
+func checkPartitionExpr(ctx context.Context, tbInfo *model.TableInfo, expr ast.ExprNode) error {
+	if colExpr, ok := expr.(*ast.ColumnNameExpr); ok {
+		if findCol(tbInfo.Columns, colExpr.Name.Name.L) == nil {
+			return errBadField.GenByArgs(colExpr.Name.Name.O, "partition function")
+		}
+		return nil
+	}
+	schema := expression.NewSchema(expression.ColumnInfos2Columns(tbInfo.Name, tbInfo.Columns)...)
+	_, err := expression.RewriteAstExpr(expr, schema, ctx)
+	return errors.Trace(err)
+}
+
+// checkUniqueKeysInPartitionExpr checks that every unique key includes all the columns of the partition expression,
+// because the uniqueness is only checked in each partition.
+func checkUniqueKeysInPartitionExpr(tbInfo *model.TableInfo, expr ast.ExprNode) error {
+	partCols := findColumnNamesInExpr(expr)
+	if tbInfo.PKIsHandle {
+		pkCol := tbInfo.GetPkColInfo()
+		for _, partCol := range partCols {
+			if partCol.Name.L != pkCol.Name.L {
+				return ErrUniqueKeyNeedAllFieldsInPf.GenByArgs("PRIMARY KEY")
+			}
+		}
+	}
+	for _, idx := range tbInfo.Indices {
+		if !idx.Unique {
+			continue
+		}
+		for _, partCol := range partCols {
+			if findIndexColumn(idx, partCol.Name.L) < 0 {
+				if idx.Primary {
+					return ErrUniqueKeyNeedAllFieldsInPf.GenByArgs("PRIMARY KEY")
+				}
+				return ErrUniqueKeyNeedAllFieldsInPf.GenByArgs("UNIQUE INDEX")
+			}
+		}
+	}
+	return nil
+}
+
+func findIndexColumn(idx *model.IndexInfo, name string) int {
+	for i, col := range idx.Columns {
+		if col.Name.L == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// buildPartitionDefinitions evaluates the VALUES of the partition definitions.
+func buildPartitionDefinitions(ctx context.Context, tp model.PartitionType, defs []*ast.PartitionDefinition) ([]model.PartitionDefinition, error) {
+	partDefs := make([]model.PartitionDefinition, 0, len(defs))
+	for _, def := range defs {
+		partDef := model.PartitionDefinition{Name: def.Name}
+		switch tp {
+		case model.PartitionTypeRange:
+			if len(def.InValues) > 0 {
+				return nil, ErrPartitionWrongValues.GenByArgs("LIST", "IN")
+			}
+			if def.MaxValue {
+				partDef.LessThan = []string{model.PartitionMaxValue}
+				break
+			}
+			if len(def.LessThan) == 0 {
+				return nil, ErrPartitionRequiresValues.GenByArgs("RANGE", "LESS THAN")
+			}
+			for _, expr := range def.LessThan {
+				str, err := evalPartitionValue(ctx, expr)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+				partDef.LessThan = append(partDef.LessThan, str)
+			}
+		case model.PartitionTypeList:
+			if len(def.LessThan) > 0 || def.MaxValue {
+				return nil, ErrPartitionWrongValues.GenByArgs("RANGE", "LESS THAN")
+			}
+			if len(def.InValues) == 0 {
+				return nil, ErrPartitionRequiresValues.GenByArgs("LIST", "IN")
+			}
+			for _, expr := range def.InValues {
+				str, err := evalPartitionValue(ctx, expr)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+				partDef.InValues = append(partDef.InValues, str)
+			}
+		default:
+			if len(def.LessThan) > 0 || def.MaxValue {
+				return nil, ErrPartitionWrongValues.GenByArgs("RANGE", "LESS THAN")
+			}
+			if len(def.InValues) > 0 {
+				return nil, ErrPartitionWrongValues.GenByArgs("LIST", "IN")
+			}
+		}
+		partDefs = append(partDefs, partDef)
+	}
+	return partDefs, nil
+}
+
+// evalPartitionValue evaluates a partition value to the string of an integer, or NULL.
+func evalPartitionValue(ctx context.Context, expr ast.ExprNode) (string, error) {
+	v, err := expression.EvalAstExpr(expr, ctx)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if v.IsNull() {
+		return model.PartitionNullValue, nil
+	}
+	i, err := v.ToInt64(ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return strconv.FormatInt(i, 10), nil
+}
+
+// checkPartitionDefinitions checks the partition names and values of the partition info.
+func checkPartitionDefinitions(pi *model.PartitionInfo) error {
+	names := make(map[string]struct{}, len(pi.Definitions))
+	for _, def := range pi.Definitions {
+		if _, ok := names[def.Name.L]; ok {
+			return ErrSameNamePartition.GenByArgs(def.Name.O)
+		}
+		names[def.Name.L] = struct{}{}
+	}
+
+	switch pi.Type {
+	case model.PartitionTypeRange:
+		for i, def := range pi.Definitions {
+			if def.LessThan[0] == model.PartitionMaxValue && i != len(pi.Definitions)-1 {
+				return ErrPartitionMaxvalue
+			}
+			if def.LessThan[0] == model.PartitionNullValue {
+				return ErrPartitionWrongValues.GenByArgs("LIST", "IN")
+			}
+		}
+		bounds, _, err := table.RangePartitionBounds(pi)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for i := 1; i < len(bounds); i++ {
+			if bounds[i] <= bounds[i-1] {
+				return ErrRangeNotIncreasing
+			}
+		}
+	case model.PartitionTypeList:
+		values := make(map[string]struct{})
+		for _, def := range pi.Definitions {
+			for _, v := range def.InValues {
+				if _, ok := values[v]; ok {
+					return ErrMultipleDefConstInListPart
+				}
+				values[v] = struct{}{}
+			}
+		}
+	}
+	return nil
+}
+
+// getPartitionIDs returns the IDs of the partitions, or nil if the table isn't partitioned.
+func getPartitionIDs(tblInfo *model.TableInfo) []int64 {
+	if tblInfo.Partition == nil {
+		return nil
+	}
+	ids := make([]int64, 0, len(tblInfo.Partition.Definitions))
+	for _, def := range tblInfo.Partition.Definitions {
+		ids = append(ids, def.ID)
+	}
+	return ids
+}
+
+// isColumnInPartitionExpr checks whether the column is used by the partition expression.
+func isColumnInPartitionExpr(tblInfo *model.TableInfo, colName model.CIStr) (bool, error) {
+	stmts, err := parser.New().Parse(fmt.Sprintf("select %s", tblInfo.Partition.Expr), "", "")
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	expr := stmts[0].(*ast.SelectStmt).Fields.Fields[0].Expr
+	for _, name := range findColumnNamesInExpr(expr) {
+		if name.Name.L == colName.L {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkUniqueIndexInPartitionExpr checks that a unique index added to a partitioned table
+// covers all the columns used by the partition expression.
+func checkUniqueIndexInPartitionExpr(tblInfo *model.TableInfo, idxColNames []*ast.IndexColName) error {
+	stmts, err := parser.New().Parse(fmt.Sprintf("select %s", tblInfo.Partition.Expr), "", "")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	expr := stmts[0].(*ast.SelectStmt).Fields.Fields[0].Expr
+	for _, name := range findColumnNamesInExpr(expr) {
+		found := false
+		for _, idxCol := range idxColNames {
+			if idxCol.Column != nil && idxCol.Column.Name.L == name.Name.L {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ErrUniqueKeyNeedAllFieldsInPf.GenByArgs("UNIQUE INDEX")
+		}
+	}
+	return nil
+}
+
+func (d *ddl) onAddTablePartition(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	var defs []model.PartitionDefinition
+	if err := job.DecodeArgs(&defs); err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if tblInfo.Partition == nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+
+	pi := tblInfo.Partition.Clone()
+	pi.Definitions = append(pi.Definitions, defs...)
+	pi.Num = uint64(len(pi.Definitions))
+	if err = checkPartitionDefinitions(pi); err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	tblInfo.Partition = pi
+
+	ver, err = updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if err = t.UpdateTable(job.SchemaID, tblInfo); err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobDone
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	return ver, nil
+}
+
+func (d *ddl) onDropTablePartition(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	var partName model.CIStr
+	if err := job.DecodeArgs(&partName); err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if tblInfo.Partition == nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	pi := tblInfo.Partition
+	offset := pi.FindPartitionDefinitionByName(partName.L)
+	if offset < 0 {
+		job.State = model.JobCancelled
+		return ver, ErrDropPartitionNonExistent.GenByArgs("DROP")
+	}
+	if len(pi.Definitions) == 1 {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(ErrDropLastPartition)
+	}
+
+	partitionID := pi.Definitions[offset].ID
+	pi.Definitions = append(pi.Definitions[:offset], pi.Definitions[offset+1:]...)
+	pi.Num = uint64(len(pi.Definitions))
+
+	ver, err = updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if err = t.UpdateTable(job.SchemaID, tblInfo); err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	// Finish this job. The data of the partition is deleted in the background.
+	job.State = model.JobDone
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	job.Args = []interface{}{partitionID}
+	return ver, nil
+}
+
+func (d *ddl) onTruncateTablePartition(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	var partName model.CIStr
+	var newPartitionID int64
+	if err := job.DecodeArgs(&partName, &newPartitionID); err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if tblInfo.Partition == nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	pi := tblInfo.Partition
+	offset := pi.FindPartitionDefinitionByName(partName.L)
+	if offset < 0 {
+		job.State = model.JobCancelled
+		return ver, ErrDropPartitionNonExistent.GenByArgs("TRUNCATE")
+	}
+
+	// The truncated partition gets a new ID, so the old data becomes invisible at once.
+	oldPartitionID := pi.Definitions[offset].ID
+	pi.Definitions[offset].ID = newPartitionID
+
+	ver, err = updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if err = t.UpdateTable(job.SchemaID, tblInfo); err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobDone
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	job.Args = []interface{}{oldPartitionID}
+	return ver, nil
+}
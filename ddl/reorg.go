@@ -106,8 +106,11 @@ func (d *ddl) isReorgRunnable(txn kv.Transaction) error {
 type reorgInfo struct {
 	*model.Job
 	Handle int64
-	d      *ddl
-	first  bool
+	// PhysicalTableID is the ID of the partition being reorganized if the table is partitioned, it's 0 before
+	// the first partition is started.
+	PhysicalTableID int64
+	d               *ddl
+	first           bool
 }
 
 func (d *ddl) getReorgInfo(t *meta.Meta, job *model.Job) (*reorgInfo, error) {
@@ -135,6 +138,10 @@ func (d *ddl) getReorgInfo(t *meta.Meta, job *model.Job) (*reorgInfo, error) {
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		info.PhysicalTableID, err = t.GetDDLReorgPhysicalID(job)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
 	}
 
 	if info.Handle > 0 {
@@ -149,3 +156,12 @@ func (r *reorgInfo) UpdateHandle(txn kv.Transaction, handle int64) error {
 	t := meta.NewMeta(txn)
 	return errors.Trace(t.UpdateDDLReorgHandle(r.Job, handle))
 }
+
+// UpdatePhysicalTableID saves the partition to reorganize next, and resets the handle to its beginning.
+func (r *reorgInfo) UpdatePhysicalTableID(txn kv.Transaction, physicalID int64) error {
+	t := meta.NewMeta(txn)
+	if err := t.UpdateDDLReorgPhysicalID(r.Job, physicalID); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(t.UpdateDDLReorgHandle(r.Job, 0))
+}
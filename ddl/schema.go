@@ -136,6 +136,7 @@ func getIDs(tables []*model.TableInfo) []int64 {
 	ids := make([]int64, 0, len(tables))
 	for _, t := range tables {
 		ids = append(ids, t.ID)
+		ids = append(ids, getPartitionIDs(t)...)
 	}
 
 	return ids
@@ -115,7 +115,7 @@ func (d *ddl) onDropTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 		job.State = model.JobDone
 		job.BinlogInfo.AddTableInfo(ver, tblInfo)
 		startKey := tablecodec.EncodeTablePrefix(tableID)
-		job.Args = append(job.Args, startKey)
+		job.Args = append(job.Args, startKey, getPartitionIDs(tblInfo))
 		d.asyncNotifyEvent(&Event{Tp: model.ActionDropTable, TableInfo: tblInfo})
 	default:
 		err = ErrInvalidTableState.Gen("invalid table state %v", tblInfo.State)
@@ -124,6 +124,70 @@ func (d *ddl) onDropTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	return ver, errors.Trace(err)
 }
 
+func (d *ddl) onCreateView(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	schemaID := job.SchemaID
+	tbInfo := &model.TableInfo{}
+	var oldViewID int64
+	if err := job.DecodeArgs(tbInfo, &oldViewID); err != nil {
+		// Invalid arguments, cancel this job.
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+
+	var err error
+	if oldViewID == 0 {
+		err = checkTableNotExists(t, job, schemaID, tbInfo.Name.L)
+	} else {
+		// The view to replace must still exist.
+		_, err = getTableInfo(t, job, schemaID)
+	}
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	ver, err = updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	// A view has no data, so it becomes public at once.
+	job.SchemaState = model.StatePublic
+	tbInfo.State = model.StatePublic
+	if oldViewID == 0 {
+		err = t.CreateTable(schemaID, tbInfo)
+	} else {
+		err = t.UpdateTable(schemaID, tbInfo)
+	}
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	// Finish this job.
+	job.State = model.JobDone
+	job.BinlogInfo.AddTableInfo(ver, tbInfo)
+	return ver, nil
+}
+
+func (d *ddl) onDropView(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	ver, err = updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	// A view has no data, so it's dropped at once.
+	if err = t.DropTable(job.SchemaID, job.TableID, true); err != nil {
+		return ver, errors.Trace(err)
+	}
+	// Finish this job.
+	tblInfo.State = model.StateNone
+	job.SchemaState = model.StateNone
+	job.State = model.JobDone
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	return ver, nil
+}
+
 // Maximum number of keys to delete for each reorg table job run.
 var reorgTableDeleteLimit = 65536
 
@@ -170,7 +234,8 @@ func (d *ddl) onTruncateTable(t *meta.Meta, job *model.Job) (ver int64, _ error)
 	schemaID := job.SchemaID
 	tableID := job.TableID
 	var newTableID int64
-	err := job.DecodeArgs(&newTableID)
+	var newPartitionIDs []int64
+	err := job.DecodeArgs(&newTableID, &newPartitionIDs)
 	if err != nil {
 		job.State = model.JobCancelled
 		return ver, errors.Trace(err)
@@ -179,6 +244,11 @@ func (d *ddl) onTruncateTable(t *meta.Meta, job *model.Job) (ver int64, _ error)
 	if err != nil {
 		return ver, errors.Trace(err)
 	}
+	oldPartitionIDs := getPartitionIDs(tblInfo)
+	if len(oldPartitionIDs) != len(newPartitionIDs) {
+		job.State = model.JobCancelled
+		return ver, errors.Errorf("the number of new partition IDs %d doesn't match the partitions %d", len(newPartitionIDs), len(oldPartitionIDs))
+	}
 
 	err = t.DropTable(schemaID, tableID, true)
 	if err != nil {
@@ -186,6 +256,9 @@ func (d *ddl) onTruncateTable(t *meta.Meta, job *model.Job) (ver int64, _ error)
 		return ver, errors.Trace(err)
 	}
 	tblInfo.ID = newTableID
+	for i, id := range newPartitionIDs {
+		tblInfo.Partition.Definitions[i].ID = id
+	}
 	err = t.CreateTable(schemaID, tblInfo)
 	if err != nil {
 		job.State = model.JobCancelled
@@ -199,7 +272,7 @@ func (d *ddl) onTruncateTable(t *meta.Meta, job *model.Job) (ver int64, _ error)
 	job.State = model.JobDone
 	job.BinlogInfo.AddTableInfo(ver, tblInfo)
 	startKey := tablecodec.EncodeTablePrefix(tableID)
-	job.Args = []interface{}{startKey}
+	job.Args = []interface{}{startKey, oldPartitionIDs}
 	return ver, nil
 }
 
@@ -35,6 +35,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/userlock"
 	goctx "golang.org/x/net/context"
 )
 
@@ -52,6 +53,7 @@ type Domain struct {
 	sysSessionPool  *pools.ResourcePool
 	exit            chan struct{}
 	etcdClient      *clientv3.Client
+	userLockManager *userlock.Manager
 
 	MockReloadFailed MockFailure // It mocks reload failed.
 }
@@ -350,6 +352,9 @@ func (do *Domain) loadSchemaInLoop(lease time.Duration) {
 func (do *Domain) Close() {
 	do.ddl.Stop()
 	close(do.exit)
+	if do.userLockManager != nil {
+		do.userLockManager.Close()
+	}
 	if do.etcdClient != nil {
 		do.etcdClient.Close()
 	}
@@ -452,6 +457,7 @@ func NewDomain(store kv.Storage, ddlLease time.Duration, statsLease time.Duratio
 	if err = d.Reload(); err != nil {
 		return nil, errors.Trace(err)
 	}
+	d.userLockManager = userlock.NewManager(d.store, d.ddl.OwnerManager().ID())
 
 	// Only when the store is local that the lease value is 0.
 	// If the store is local, it doesn't need loadSchemaInLoop.
@@ -463,6 +469,11 @@ func NewDomain(store kv.Storage, ddlLease time.Duration, statsLease time.Duratio
 	return d, nil
 }
 
+// UserLockManager returns the user-level lock manager.
+func (do *Domain) UserLockManager() *userlock.Manager {
+	return do.userLockManager
+}
+
 // SysSessionPool returns the system session pool.
 func (do *Domain) SysSessionPool() *pools.ResourcePool {
 	return do.sysSessionPool
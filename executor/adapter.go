@@ -42,6 +42,9 @@ type recordSet struct {
 	stmt        *statement
 	processinfo processinfoSetter
 	err         error
+	// started is true after the first Next call.
+	started  bool
+	retryCnt int
 }
 
 func (a *recordSet) Fields() ([]*ast.ResultField, error) {
@@ -64,7 +67,20 @@ func (a *recordSet) Fields() ([]*ast.ResultField, error) {
 
 func (a *recordSet) Next() (*ast.Row, error) {
 	row, err := a.executor.Next()
+	for err != nil && !a.started && a.stmt != nil && a.stmt.needRetryPessimistic(err, a.retryCnt) {
+		// The SELECT FOR UPDATE statement is retried with a new for update ts.
+		a.executor, err = a.stmt.rebuildExecutor(a.executor)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		a.retryCnt++
+		row, err = a.executor.Next()
+	}
+	a.started = true
 	if err != nil {
+		if a.stmt != nil {
+			err = a.stmt.handlePessimisticError(err)
+		}
 		return nil, errors.Trace(err)
 	}
 	if row == nil {
@@ -162,14 +178,8 @@ func (a *statement) Exec(ctx context.Context) (ast.RecordSet, error) {
 }
 
 func (a *statement) handleNoDelayExecutor(e Executor, ctx context.Context, pi processinfoSetter) (ast.RecordSet, error) {
-	// Check if "tidb_snapshot" is set for the write executors.
-	// In history read mode, we can not do write operations.
-	switch e.(type) {
-	case *DeleteExec, *InsertExec, *UpdateExec, *ReplaceExec, *LoadData, *DDLExec:
-		snapshotTS := ctx.GetSessionVars().SnapshotTS
-		if snapshotTS != 0 {
-			return nil, errors.New("can not execute write statement when 'tidb_snapshot' is set")
-		}
+	if err := checkSnapshotWrite(ctx, e); err != nil {
+		return nil, errors.Trace(err)
 	}
 
 	defer func() {
@@ -179,19 +189,107 @@ func (a *statement) handleNoDelayExecutor(e Executor, ctx context.Context, pi pr
 		e.Close()
 		a.logSlowQuery()
 	}()
-	for {
+	for retryCnt, first := 0, true; ; first = false {
 		row, err := e.Next()
 		if err != nil {
-			return nil, errors.Trace(err)
+			if first && a.needRetryPessimistic(err, retryCnt) {
+				e, err = a.rebuildExecutor(e)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+				retryCnt, first = retryCnt+1, true
+				continue
+			}
+			return nil, errors.Trace(a.handlePessimisticError(err))
 		}
 		// Even though there isn't any result set, the row is still used to indicate if there is
 		// more work to do.
 		// For example, the UPDATE statement updates a single row on a Next call, we keep calling Next until
 		// There is no more rows to update.
 		if row == nil {
-			return nil, nil
+			break
+		}
+	}
+	if err := lockWrittenKeys(ctx, e); err != nil {
+		return nil, errors.Trace(a.handlePessimisticError(err))
+	}
+	return nil, nil
+}
+
+// checkSnapshotWrite checks if "tidb_snapshot" is set for the write executors.
+// In history read mode, we can not do write operations.
+func checkSnapshotWrite(ctx context.Context, e Executor) error {
+	switch e.(type) {
+	case *DeleteExec, *InsertExec, *UpdateExec, *ReplaceExec, *LoadData, *DDLExec:
+		snapshotTS := ctx.GetSessionVars().SnapshotTS
+		if snapshotTS != 0 {
+			return errors.New("can not execute write statement when 'tidb_snapshot' is set")
+		}
+	}
+	return nil
+}
+
+// lockWrittenKeys locks the keys written by the write executors in a pessimistic transaction,
+// so that they are locked before the statement returns.
+func lockWrittenKeys(ctx context.Context, e Executor) error {
+	switch e.(type) {
+	case *DeleteExec, *InsertExec, *UpdateExec, *ReplaceExec:
+		if ctx.GetSessionVars().TxnCtx.IsPessimistic && ctx.Txn() != nil && ctx.Txn().Valid() {
+			return errors.Trace(ctx.Txn().LockKeys())
+		}
+	}
+	return nil
+}
+
+// resetForUpdateTS makes the statement read at the start ts of the pessimistic transaction, the for update ts
+// only applies to the statement which locks rows.
+func resetForUpdateTS(ctx context.Context) {
+	if !ctx.GetSessionVars().TxnCtx.IsPessimistic {
+		return
+	}
+	if txn := ctx.Txn(); txn != nil && txn.Valid() {
+		txn.DelOption(kv.ForUpdateTS)
+	}
+}
+
+// pessimisticRetryLimit is the max number of times a statement in a pessimistic transaction retries on write conflicts.
+const pessimisticRetryLimit = 100
+
+// needRetryPessimistic checks whether the statement should be retried with a new for update ts.
+// It's only safe before the statement writes any row, the rows are locked in the first Next call.
+func (a *statement) needRetryPessimistic(err error, retryCnt int) bool {
+	return a.ctx.GetSessionVars().TxnCtx.IsPessimistic && kv.ErrWriteConflict.Equal(err) && retryCnt < pessimisticRetryLimit
+}
+
+// rebuildExecutor closes the executor and builds a new one with a new for update ts.
+func (a *statement) rebuildExecutor(e Executor) (Executor, error) {
+	log.Infof("[%d] pessimistic write conflict, retry statement: %s", a.ctx.GetSessionVars().ConnectionID, a.text)
+	e.Close()
+	a.ctx.GetSessionVars().StmtCtx.ForUpdateTS = 0
+	e, err := a.buildExecutor(a.ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err = e.Open(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return e, nil
+}
+
+// handlePessimisticError rolls back the pessimistic transaction on deadlock, like MySQL does.
+func (a *statement) handlePessimisticError(err error) error {
+	sessVars := a.ctx.GetSessionVars()
+	if !sessVars.TxnCtx.IsPessimistic || !kv.ErrDeadlock.Equal(err) {
+		return err
+	}
+	log.Infof("[%d] deadlock found, rollback transaction", sessVars.ConnectionID)
+	sessVars.SetStatusFlag(mysql.ServerStatusInTrans, false)
+	if txn := a.ctx.Txn(); txn != nil && txn.Valid() {
+		if err1 := txn.Rollback(); err1 != nil {
+			log.Errorf("[%d] rollback error: %v", sessVars.ConnectionID, err1)
 		}
 	}
+	return err
 }
 
 // buildExecutor build a executor from plan, prepared statement may need additional procedure.
@@ -212,6 +310,7 @@ func (a *statement) buildExecutor(ctx context.Context) (Executor, error) {
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		resetForUpdateTS(ctx)
 
 		if stmtPri := ctx.GetSessionVars().StmtCtx.Priority; stmtPri != mysql.NoPriority {
 			priority = int(stmtPri)
@@ -49,6 +49,14 @@ func (e *HashAggExec) Close() error {
 	return errors.Trace(e.children[0].Close())
 }
 
+// memoryUsage implements the memoryUsageReporter interface.
+func (e *HashAggExec) memoryUsage() int64 {
+	if e.groupMap == nil {
+		return 0
+	}
+	return e.groupMap.MemoryUsage()
+}
+
 // Open implements the Executor Open interface.
 func (e *HashAggExec) Open() error {
 	e.executed = false
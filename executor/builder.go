@@ -30,6 +30,7 @@ import (
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/execdetails"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
 	goctx "golang.org/x/net/context"
@@ -43,6 +44,8 @@ type executorBuilder struct {
 	priority int
 	// err is set when there is error happened during Executor building process.
 	err error
+	// cteStorages stores the storage of each common table expression, which is shared by all its readers.
+	cteStorages map[*plan.CTEDefinition]*cteStorage
 }
 
 func newExecutorBuilder(ctx context.Context, is infoschema.InfoSchema, priority int) *executorBuilder {
@@ -54,6 +57,16 @@ func newExecutorBuilder(ctx context.Context, is infoschema.InfoSchema, priority
 }
 
 func (b *executorBuilder) build(p plan.Plan) Executor {
+	// For EXPLAIN ANALYZE, the executors of the explained statement are wrapped to collect their runtime statistics.
+	coll := b.ctx.GetSessionVars().StmtCtx.RuntimeStatsColl
+	e := b.buildExec(p)
+	if coll == nil || e == nil {
+		return e
+	}
+	return newAnalyzeExec(e, coll.GetRootStats(p.ID()))
+}
+
+func (b *executorBuilder) buildExec(p plan.Plan) Executor {
 	switch v := p.(type) {
 	case nil:
 		return nil
@@ -91,6 +104,12 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildSet(v)
 	case *plan.Sort:
 		return b.buildSort(v)
+	case *plan.PhysicalWindow:
+		return b.buildWindow(v)
+	case *plan.PhysicalCTE:
+		return b.buildCTE(v)
+	case *plan.CTETable:
+		return b.buildCTETableReader(v)
 	case *plan.TopN:
 		return b.buildTopN(v)
 	case *plan.Union:
@@ -103,6 +122,8 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildHashJoin(v)
 	case *plan.PhysicalMergeJoin:
 		return b.buildMergeJoin(v)
+	case *plan.PhysicalCrossJoin:
+		return b.buildCrossJoin(v)
 	case *plan.PhysicalHashSemiJoin:
 		return b.buildSemiJoin(v)
 	case *plan.PhysicalIndexJoin:
@@ -137,6 +158,8 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildIndexReader(v)
 	case *plan.PhysicalIndexLookUpReader:
 		return b.buildIndexLookUpReader(v)
+	case *plan.PhysicalIndexMergeReader:
+		return b.buildIndexMergeReader(v)
 	default:
 		b.err = ErrUnknownPlan.Gen("Unknown Plan %T", p)
 		return nil
@@ -207,13 +230,18 @@ func (b *executorBuilder) buildDeallocate(v *plan.Deallocate) Executor {
 }
 
 func (b *executorBuilder) buildSelectLock(v *plan.SelectLock) Executor {
-	src := b.build(v.Children()[0])
 	if !b.ctx.GetSessionVars().InTxn() {
 		// Locking of rows for update using SELECT FOR UPDATE only applies when autocommit
 		// is disabled (either by beginning transaction with START TRANSACTION or by setting
 		// autocommit to 0. If autocommit is enabled, the rows matching the specification are not locked.
 		// See https://dev.mysql.com/doc/refman/5.7/en/innodb-locking-reads.html
-		return src
+		return b.build(v.Children()[0])
+	}
+	if v.Lock == ast.SelectLockForUpdate {
+		b.refreshForUpdateTS()
+		if b.err != nil {
+			return nil
+		}
 	}
 	e := &SelectLockExec{
 		baseExecutor: newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
@@ -222,6 +250,36 @@ func (b *executorBuilder) buildSelectLock(v *plan.SelectLock) Executor {
 	return e
 }
 
+// refreshForUpdateTS gets a new for update ts for the statement in a pessimistic transaction.
+// The statement reads and locks the rows at the for update ts, so it sees the latest committed rows.
+func (b *executorBuilder) refreshForUpdateTS() {
+	sessVars := b.ctx.GetSessionVars()
+	if !sessVars.TxnCtx.IsPessimistic || sessVars.StmtCtx.ForUpdateTS != 0 {
+		return
+	}
+	ver, err := b.ctx.GetStore().CurrentVersion()
+	if err != nil {
+		b.err = errors.Trace(err)
+		return
+	}
+	sessVars.StmtCtx.ForUpdateTS = ver.Ver
+	txn := b.ctx.Txn()
+	txn.SetOption(kv.ForUpdateTS, ver.Ver)
+	txn.SetOption(kv.LockWaitTimeout, sessVars.LockWaitTimeout)
+}
+
+// buildPessimisticLock locks the rows read by UPDATE and DELETE statements in a pessimistic transaction.
+func (b *executorBuilder) buildPessimisticLock(child plan.Plan, src Executor) Executor {
+	sessVars := b.ctx.GetSessionVars()
+	if !sessVars.TxnCtx.IsPessimistic || !sessVars.InTxn() || len(child.Schema().TblID2Handle) == 0 {
+		return src
+	}
+	return &SelectLockExec{
+		baseExecutor: newBaseExecutor(child.Schema(), b.ctx, src),
+		Lock:         ast.SelectLockForUpdate,
+	}
+}
+
 func (b *executorBuilder) buildLimit(v *plan.Limit) Executor {
 	e := &LimitExec{
 		baseExecutor: newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
@@ -291,6 +349,10 @@ func (b *executorBuilder) buildSet(v *plan.Set) Executor {
 }
 
 func (b *executorBuilder) buildInsert(v *plan.Insert) Executor {
+	b.refreshForUpdateTS()
+	if b.err != nil {
+		return nil
+	}
 	ivs := &InsertValues{
 		ctx:        b.ctx,
 		Columns:    v.Columns,
@@ -338,7 +400,7 @@ func (b *executorBuilder) buildLoadData(v *plan.LoadData) Executor {
 	return &LoadData{
 		IsLocal: v.IsLocal,
 		loadDataInfo: &LoadDataInfo{
-			row:        make([]types.Datum, len(columns)),
+			row:        make([]types.Datum, len(columns)-hiddenColumnsCount(columns)),
 			insertVal:  insertVal,
 			Path:       v.Path,
 			Table:      tbl,
@@ -363,6 +425,7 @@ func (b *executorBuilder) buildGrant(grant *ast.GrantStmt) Executor {
 		ObjectType: grant.ObjectType,
 		Level:      grant.Level,
 		Users:      grant.Users,
+		Require:    grant.Require,
 		WithGrant:  grant.WithGrant,
 		is:         b.is,
 	}
@@ -387,6 +450,16 @@ func (b *executorBuilder) buildExplain(v *plan.Explain) Executor {
 	exec := &ExplainExec{
 		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
 	}
+	if v.Analyze {
+		exec.runtimeStatsColl = execdetails.NewRuntimeStatsColl()
+		b.ctx.GetSessionVars().StmtCtx.RuntimeStatsColl = exec.runtimeStatsColl
+		exec.explain = v
+		exec.analyzeExec = b.build(v.StmtPlan)
+		if b.err != nil {
+			return nil
+		}
+		return exec
+	}
 	exec.rows = make([]Row, 0, len(v.Rows))
 	for _, row := range v.Rows {
 		exec.rows = append(exec.rows, row)
@@ -412,16 +485,18 @@ func (b *executorBuilder) buildUnionScanExec(v *plan.PhysicalUnionScan) Executor
 			}
 		}
 	}
-	switch x := src.(type) {
+	switch x := unwrapAnalyzeExec(src).(type) {
 	case *XSelectTableExec:
 		us.desc = x.desc
 		us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.table.Meta().ID)
+		us.partitionedTable = b.getPartitionedTable(x.table)
 		us.conditions = v.Conditions
 		us.columns = x.Columns
 		us.buildAndSortAddedRows(x.table)
 	case *TableReaderExecutor:
 		us.desc = x.desc
 		us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.table.Meta().ID)
+		us.partitionedTable = b.getPartitionedTable(x.table)
 		us.conditions = v.Conditions
 		us.columns = x.columns
 		us.buildAndSortAddedRows(x.table)
@@ -436,6 +511,7 @@ func (b *executorBuilder) buildUnionScanExec(v *plan.PhysicalUnionScan) Executor
 			}
 		}
 		us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.table.Meta().ID)
+		us.partitionedTable = b.getPartitionedTable(x.table)
 		us.conditions = v.Conditions
 		us.columns = x.columns
 		us.buildAndSortAddedRows(x.table)
@@ -450,6 +526,7 @@ func (b *executorBuilder) buildUnionScanExec(v *plan.PhysicalUnionScan) Executor
 			}
 		}
 		us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.table.Meta().ID)
+		us.partitionedTable = b.getPartitionedTable(x.table)
 		us.conditions = v.Conditions
 		us.columns = x.columns
 		us.buildAndSortAddedRows(x.table)
@@ -464,6 +541,7 @@ func (b *executorBuilder) buildUnionScanExec(v *plan.PhysicalUnionScan) Executor
 			}
 		}
 		us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.table.Meta().ID)
+		us.partitionedTable = b.getPartitionedTable(x.table)
 		us.conditions = v.Conditions
 		us.columns = x.columns
 		us.buildAndSortAddedRows(x.table)
@@ -474,6 +552,16 @@ func (b *executorBuilder) buildUnionScanExec(v *plan.PhysicalUnionScan) Executor
 	return us
 }
 
+// getPartitionedTable returns the partitioned table if t is one of its partitions, otherwise it returns nil.
+func (b *executorBuilder) getPartitionedTable(t table.Table) table.PartitionedTable {
+	if t.Meta().Partition == nil {
+		return nil
+	}
+	tbl, _ := b.is.TableByID(t.Meta().ID)
+	pt, _ := tbl.(table.PartitionedTable)
+	return pt
+}
+
 // buildMergeJoin builds SortMergeJoin executor.
 // TODO: Refactor against different join strategies by extracting common code base
 func (b *executorBuilder) buildMergeJoin(v *plan.PhysicalMergeJoin) Executor {
@@ -556,6 +644,26 @@ func (b *executorBuilder) buildHashJoin(v *plan.PhysicalHashJoin) Executor {
 	return e
 }
 
+func (b *executorBuilder) buildCrossJoin(v *plan.PhysicalCrossJoin) Executor {
+	e := &CrossJoinExec{
+		baseExecutor:  newBaseExecutor(v.Schema(), b.ctx),
+		outerExec:     b.build(v.Children()[1-v.InnerChildIdx]),
+		innerExec:     b.build(v.Children()[v.InnerChildIdx]),
+		innerIsLeft:   v.InnerChildIdx == 0,
+		otherFilter:   v.OtherConditions,
+		outer:         v.JoinType != plan.InnerJoin,
+		defaultValues: v.DefaultValues,
+		broadcast:     v.Broadcast,
+		concurrency:   v.Concurrency,
+	}
+	if e.innerIsLeft {
+		e.outerFilter, e.innerFilter = v.RightConditions, v.LeftConditions
+	} else {
+		e.outerFilter, e.innerFilter = v.LeftConditions, v.RightConditions
+	}
+	return e
+}
+
 func (b *executorBuilder) buildSemiJoin(v *plan.PhysicalHashSemiJoin) *HashSemiJoinExec {
 	var leftHashKey, rightHashKey []*expression.Column
 	for _, eqCond := range v.EqualConditions {
@@ -625,6 +733,9 @@ func (b *executorBuilder) buildTableDual(v *plan.TableDual) Executor {
 
 func (b *executorBuilder) getStartTS() uint64 {
 	startTS := b.ctx.GetSessionVars().SnapshotTS
+	if startTS == 0 {
+		startTS = b.ctx.GetSessionVars().StmtCtx.ForUpdateTS
+	}
 	if startTS == 0 {
 		startTS = b.ctx.Txn().StartTS()
 	}
@@ -645,12 +756,27 @@ func (b *executorBuilder) buildMemTable(v *plan.PhysicalMemTable) Executor {
 	return ts
 }
 
+// getPhysicalTable returns the partition to read and its ID if the table is partitioned,
+// otherwise it returns the table itself and the table ID.
+func (b *executorBuilder) getPhysicalTable(tblInfo *model.TableInfo, physicalID int64) (table.Table, int64) {
+	tbl, _ := b.is.TableByID(tblInfo.ID)
+	if physicalID == 0 {
+		physicalID = tblInfo.ID
+	}
+	if pt, ok := tbl.(table.PartitionedTable); ok {
+		if p := pt.GetPartition(physicalID); p != nil {
+			return p, physicalID
+		}
+	}
+	return tbl, physicalID
+}
+
 func (b *executorBuilder) buildTableScan(v *plan.PhysicalTableScan) Executor {
 	startTS := b.getStartTS()
 	if b.err != nil {
 		return nil
 	}
-	table, _ := b.is.TableByID(v.Table.ID)
+	table, tableID := b.getPhysicalTable(v.Table, v.PhysicalTableID)
 	client := b.ctx.GetClient()
 	supportDesc := client.IsRequestTypeSupported(kv.ReqTypeSelect, kv.ReqSubTypeDesc)
 	var handleCol *expression.Column
@@ -663,6 +789,7 @@ func (b *executorBuilder) buildTableScan(v *plan.PhysicalTableScan) Executor {
 		startTS:     startTS,
 		supportDesc: supportDesc,
 		table:       table,
+		tableID:     tableID,
 		schema:      v.Schema(),
 		Columns:     v.Columns,
 		ranges:      v.Ranges,
@@ -685,7 +812,7 @@ func (b *executorBuilder) buildIndexScan(v *plan.PhysicalIndexScan) Executor {
 	if b.err != nil {
 		return nil
 	}
-	table, _ := b.is.TableByID(v.Table.ID)
+	table, tableID := b.getPhysicalTable(v.Table, v.PhysicalTableID)
 	client := b.ctx.GetClient()
 	supportDesc := client.IsRequestTypeSupported(kv.ReqTypeIndex, kv.ReqSubTypeDesc)
 	var handleCol *expression.Column
@@ -697,6 +824,7 @@ func (b *executorBuilder) buildIndexScan(v *plan.PhysicalIndexScan) Executor {
 		ctx:                  b.ctx,
 		supportDesc:          supportDesc,
 		table:                table,
+		tableID:              tableID,
 		singleReadMode:       !v.DoubleRead,
 		startTS:              startTS,
 		where:                v.TableConditionPBExpr,
@@ -760,6 +888,63 @@ func (b *executorBuilder) buildSort(v *plan.Sort) Executor {
 	return &sortExec
 }
 
+func (b *executorBuilder) buildWindow(v *plan.PhysicalWindow) Executor {
+	return &WindowExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
+		StmtCtx:      b.ctx.GetSessionVars().StmtCtx,
+		WindowFunc:   v.WindowFunc,
+		PartitionBy:  v.PartitionBy,
+		OrderBy:      v.OrderBy,
+		Frame:        v.Frame,
+	}
+}
+
+func (b *executorBuilder) buildCTE(v *plan.PhysicalCTE) Executor {
+	storage, ok := b.cteStorages[v.CTE]
+	if !ok {
+		fieldTypes := make([]*types.FieldType, 0, v.Schema().Len())
+		for _, col := range v.Schema().Columns {
+			fieldTypes = append(fieldTypes, col.RetType)
+		}
+		storage = &cteStorage{
+			def:        v.CTE,
+			ctx:        b.ctx,
+			fieldTypes: fieldTypes,
+		}
+		if b.cteStorages == nil {
+			b.cteStorages = make(map[*plan.CTEDefinition]*cteStorage)
+		}
+		// The storage must be registered before building the recursive part, which reads from it.
+		b.cteStorages[v.CTE] = storage
+		storage.seedExec = b.build(v.CTE.SeedPlan)
+		if b.err != nil {
+			return nil
+		}
+		if v.CTE.RecursivePlan != nil {
+			storage.recursiveExec = b.build(v.CTE.RecursivePlan)
+			if b.err != nil {
+				return nil
+			}
+		}
+	}
+	return &CTEExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		storage:      storage,
+	}
+}
+
+func (b *executorBuilder) buildCTETableReader(v *plan.CTETable) Executor {
+	storage, ok := b.cteStorages[v.CTE]
+	if !ok {
+		b.err = errors.Errorf("the storage of common table expression %s is not built", v.CTE.Name)
+		return nil
+	}
+	return &CTETableReaderExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		storage:      storage,
+	}
+}
+
 func (b *executorBuilder) buildTopN(v *plan.TopN) Executor {
 	sortExec := SortExec{
 		baseExecutor: newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
@@ -851,13 +1036,18 @@ func (b *executorBuilder) buildUnion(v *plan.Union) Executor {
 }
 
 func (b *executorBuilder) buildUpdate(v *plan.Update) Executor {
+	b.refreshForUpdateTS()
+	if b.err != nil {
+		return nil
+	}
 	tblID2table := make(map[int64]table.Table)
 	for id := range v.Schema().TblID2Handle {
 		tblID2table[id], _ = b.is.TableByID(id)
 	}
+	selExec := b.buildPessimisticLock(v.Children()[0], b.build(v.Children()[0]))
 	return &UpdateExec{
 		baseExecutor: newBaseExecutor(nil, b.ctx),
-		SelectExec:   b.build(v.Children()[0]),
+		SelectExec:   selExec,
 		OrderedList:  v.OrderedList,
 		tblID2table:  tblID2table,
 		IgnoreErr:    v.IgnoreErr,
@@ -865,13 +1055,18 @@ func (b *executorBuilder) buildUpdate(v *plan.Update) Executor {
 }
 
 func (b *executorBuilder) buildDelete(v *plan.Delete) Executor {
+	b.refreshForUpdateTS()
+	if b.err != nil {
+		return nil
+	}
 	tblID2table := make(map[int64]table.Table)
 	for id := range v.Schema().TblID2Handle {
 		tblID2table[id], _ = b.is.TableByID(id)
 	}
+	selExec := b.buildPessimisticLock(v.Children()[0], b.build(v.Children()[0]))
 	return &DeleteExec{
 		baseExecutor: newBaseExecutor(nil, b.ctx),
-		SelectExec:   b.build(v.Children()[0]),
+		SelectExec:   selExec,
 		Tables:       v.Tables,
 		IsMultiTable: v.IsMultiTable,
 		tblID2Table:  tblID2table,
@@ -1068,7 +1263,7 @@ func (b *executorBuilder) buildTableReader(v *plan.PhysicalTableReader) Executor
 		return nil
 	}
 	ts := v.TablePlans[0].(*plan.PhysicalTableScan)
-	table, _ := b.is.TableByID(ts.Table.ID)
+	table, tableID := b.getPhysicalTable(ts.Table, ts.PhysicalTableID)
 	var handleCol *expression.Column
 	if v.NeedColHandle {
 		handleCol = v.Schema().TblID2Handle[ts.Table.ID][0]
@@ -1077,7 +1272,7 @@ func (b *executorBuilder) buildTableReader(v *plan.PhysicalTableReader) Executor
 		ctx:       b.ctx,
 		schema:    v.Schema(),
 		dagPB:     dagReq,
-		tableID:   ts.Table.ID,
+		tableID:   tableID,
 		table:     table,
 		keepOrder: ts.KeepOrder,
 		desc:      ts.Desc,
@@ -1085,6 +1280,7 @@ func (b *executorBuilder) buildTableReader(v *plan.PhysicalTableReader) Executor
 		columns:   ts.Columns,
 		handleCol: handleCol,
 		priority:  b.priority,
+		copStats:  b.copStats(v.TablePlans),
 	}
 
 	for i := range v.Schema().Columns {
@@ -1103,7 +1299,7 @@ func (b *executorBuilder) buildIndexReader(v *plan.PhysicalIndexReader) Executor
 		return nil
 	}
 	is := v.IndexPlans[0].(*plan.PhysicalIndexScan)
-	table, _ := b.is.TableByID(is.Table.ID)
+	table, tableID := b.getPhysicalTable(is.Table, is.PhysicalTableID)
 	var handleCol *expression.Column
 	if v.NeedColHandle {
 		handleCol = v.Schema().TblID2Handle[is.Table.ID][0]
@@ -1112,7 +1308,7 @@ func (b *executorBuilder) buildIndexReader(v *plan.PhysicalIndexReader) Executor
 		ctx:       b.ctx,
 		schema:    v.Schema(),
 		dagPB:     dagReq,
-		tableID:   is.Table.ID,
+		tableID:   tableID,
 		table:     table,
 		index:     is.Index,
 		keepOrder: !is.OutOfOrder,
@@ -1121,6 +1317,7 @@ func (b *executorBuilder) buildIndexReader(v *plan.PhysicalIndexReader) Executor
 		columns:   is.Columns,
 		handleCol: handleCol,
 		priority:  b.priority,
+		copStats:  b.copStats(v.IndexPlans),
 	}
 
 	for _, col := range v.OutputColumns {
@@ -1144,7 +1341,7 @@ func (b *executorBuilder) buildIndexLookUpReader(v *plan.PhysicalIndexLookUpRead
 		return nil
 	}
 	is := v.IndexPlans[0].(*plan.PhysicalIndexScan)
-	table, _ := b.is.TableByID(is.Table.ID)
+	table, tableID := b.getPhysicalTable(is.Table, is.PhysicalTableID)
 	var handleCol *expression.Column
 	if v.NeedColHandle {
 		handleCol = v.Schema().TblID2Handle[is.Table.ID][0]
@@ -1160,19 +1357,73 @@ func (b *executorBuilder) buildIndexLookUpReader(v *plan.PhysicalIndexLookUpRead
 	}
 
 	e := &IndexLookUpExecutor{
-		ctx:          b.ctx,
-		schema:       v.Schema(),
-		dagPB:        indexReq,
-		tableID:      is.Table.ID,
-		table:        table,
-		index:        is.Index,
-		keepOrder:    !is.OutOfOrder,
-		desc:         is.Desc,
-		ranges:       is.Ranges,
-		tableRequest: tableReq,
-		columns:      is.Columns,
-		handleCol:    handleCol,
-		priority:     b.priority,
+		ctx:           b.ctx,
+		schema:        v.Schema(),
+		dagPB:         indexReq,
+		tableID:       tableID,
+		table:         table,
+		index:         is.Index,
+		keepOrder:     !is.OutOfOrder,
+		desc:          is.Desc,
+		ranges:        is.Ranges,
+		tableRequest:  tableReq,
+		columns:       is.Columns,
+		handleCol:     handleCol,
+		priority:      b.priority,
+		copStats:      b.copStats(v.IndexPlans),
+		tableCopStats: b.copStats(v.TablePlans),
 	}
 	return e
 }
+
+func (b *executorBuilder) buildIndexMergeReader(v *plan.PhysicalIndexMergeReader) Executor {
+	tableReq := b.constructDAGReq(v.TablePlans)
+	if b.err != nil {
+		return nil
+	}
+	e := &IndexMergeReaderExecutor{
+		ctx:           b.ctx,
+		schema:        v.Schema(),
+		intersection:  v.Type == plan.IndexMergeIntersection,
+		tableRequest:  tableReq,
+		priority:      b.priority,
+		tableCopStats: b.copStats(v.TablePlans),
+	}
+	for _, partialPlans := range v.PartialPlans {
+		indexReq := b.constructDAGReq(partialPlans)
+		if b.err != nil {
+			return nil
+		}
+		is := partialPlans[0].(*plan.PhysicalIndexScan)
+		e.dagPBs = append(e.dagPBs, indexReq)
+		e.indexes = append(e.indexes, is.Index)
+		e.ranges = append(e.ranges, is.Ranges)
+		if copStats := b.copStats(partialPlans); copStats != nil {
+			e.copStats = append(e.copStats, copStats)
+		}
+	}
+	is := v.PartialPlans[0][0].(*plan.PhysicalIndexScan)
+	e.table, e.tableID = b.getPhysicalTable(is.Table, is.PhysicalTableID)
+	if v.NeedColHandle {
+		e.handleCol = v.Schema().TblID2Handle[is.Table.ID][0]
+	}
+
+	len := v.Schema().Len()
+	if handleIsExtra(e.handleCol) {
+		len--
+	}
+	for i := 0; i < len; i++ {
+		tableReq.OutputOffsets = append(tableReq.OutputOffsets, uint32(i))
+	}
+	return e
+}
+
+// copStats returns the runtime statistics of the coprocessor tasks which execute the pushed down plans,
+// it's nil if they are not collected. The statistics are reported on the topmost pushed down plan.
+func (b *executorBuilder) copStats(plans []plan.PhysicalPlan) *execdetails.CopRuntimeStats {
+	coll := b.ctx.GetSessionVars().StmtCtx.RuntimeStatsColl
+	if coll == nil {
+		return nil
+	}
+	return coll.GetCopStats(plans[len(plans)-1].ID())
+}
@@ -0,0 +1,275 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/util/types"
+)
+
+var _ Executor = &CrossJoinExec{}
+
+// crossJoinBlockSize is the number of the outer rows which are joined with the cached inner rows at a time.
+const crossJoinBlockSize = 256
+
+// CrossJoinExec implements the block nested-loop join for the inner/ outer join without equal conditions.
+// It caches all the rows of the inner child, then joins every block of the outer rows with the cached rows.
+// If broadcast is true, the cached rows are broadcast to several workers, each of which joins a part of
+// the outer blocks concurrently, so the order of the outer rows is not kept.
+type CrossJoinExec struct {
+	baseExecutor
+
+	outerExec     Executor
+	innerExec     Executor
+	innerIsLeft   bool
+	outerFilter   expression.CNFExprs
+	innerFilter   expression.CNFExprs
+	otherFilter   expression.CNFExprs
+	outer         bool
+	defaultValues []types.Datum
+	broadcast     bool
+	concurrency   int
+
+	prepared  bool
+	outerDone bool
+	innerRows []Row
+	rows      []Row
+	cursor    int
+
+	// The fields below are only used by the broadcast join.
+	finished atomic.Value
+	wg       sync.WaitGroup
+	blockCh  chan *execResult
+	resultCh chan *execResult
+	closeCh  chan struct{}
+}
+
+// Open implements the Executor Open interface.
+func (e *CrossJoinExec) Open() error {
+	e.prepared = false
+	e.outerDone = false
+	e.innerRows = nil
+	e.rows = nil
+	e.cursor = 0
+	e.finished.Store(false)
+	return errors.Trace(e.outerExec.Open())
+}
+
+// Close implements the Executor Close interface.
+func (e *CrossJoinExec) Close() error {
+	if e.broadcast && e.prepared {
+		e.finished.Store(true)
+		for range e.resultCh {
+		}
+		<-e.closeCh
+	}
+	e.prepared = false
+	e.innerRows = nil
+	e.rows = nil
+	return errors.Trace(e.outerExec.Close())
+}
+
+// Next implements the Executor Next interface.
+func (e *CrossJoinExec) Next() (Row, error) {
+	if !e.prepared {
+		if err := e.prepare(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	for {
+		if e.cursor < len(e.rows) {
+			row := e.rows[e.cursor]
+			e.cursor++
+			return row, nil
+		}
+		if e.broadcast {
+			result, ok := <-e.resultCh
+			if !ok {
+				return nil, nil
+			}
+			if result.err != nil {
+				e.finished.Store(true)
+				return nil, errors.Trace(result.err)
+			}
+			e.rows = result.rows
+		} else {
+			block, err := e.fetchOuterBlock()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if len(block) == 0 {
+				return nil, nil
+			}
+			e.rows, err = e.joinBlock(block, e.outerFilter, e.otherFilter)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		e.cursor = 0
+	}
+}
+
+// prepare caches the inner rows, and starts the workers for the broadcast join.
+func (e *CrossJoinExec) prepare() error {
+	err := e.fetchInnerRows()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if e.broadcast {
+		e.blockCh = make(chan *execResult, e.concurrency)
+		e.resultCh = make(chan *execResult, e.concurrency)
+		e.closeCh = make(chan struct{})
+		e.wg = sync.WaitGroup{}
+		e.wg.Add(1)
+		go e.fetchOuterBlocks()
+		for i := 0; i < e.concurrency; i++ {
+			e.wg.Add(1)
+			// The filters are cloned because the expressions can't be evaluated concurrently.
+			go e.runJoinWorker(e.outerFilter.Clone(), e.otherFilter.Clone())
+		}
+		go e.waitJoinWorkersAndCloseResultChan()
+	}
+	e.prepared = true
+	return nil
+}
+
+// fetchInnerRows reads all the rows matching the inner filter from the inner child.
+func (e *CrossJoinExec) fetchInnerRows() error {
+	err := e.innerExec.Open()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer e.innerExec.Close()
+	e.innerRows = e.innerRows[:0]
+	for {
+		row, err := e.innerExec.Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			return nil
+		}
+		matched, err := expression.EvalBool(e.innerFilter, row, e.ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if matched {
+			e.innerRows = append(e.innerRows, row)
+		}
+	}
+}
+
+// fetchOuterBlock reads at most crossJoinBlockSize rows from the outer child.
+func (e *CrossJoinExec) fetchOuterBlock() ([]Row, error) {
+	if e.outerDone {
+		return nil, nil
+	}
+	block := make([]Row, 0, crossJoinBlockSize)
+	for len(block) < crossJoinBlockSize {
+		row, err := e.outerExec.Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row == nil {
+			e.outerDone = true
+			break
+		}
+		block = append(block, row)
+	}
+	return block, nil
+}
+
+// fetchOuterBlocks fetches the outer blocks in a background goroutine and sends them to the join workers.
+func (e *CrossJoinExec) fetchOuterBlocks() {
+	defer func() {
+		close(e.blockCh)
+		e.wg.Done()
+	}()
+	for !e.finished.Load().(bool) {
+		block, err := e.fetchOuterBlock()
+		if err != nil {
+			e.blockCh <- &execResult{err: errors.Trace(err)}
+			return
+		}
+		if len(block) == 0 {
+			return
+		}
+		e.blockCh <- &execResult{rows: block}
+	}
+}
+
+// runJoinWorker joins the outer blocks with the cached inner rows in one goroutine.
+func (e *CrossJoinExec) runJoinWorker(outerFilter, otherFilter expression.CNFExprs) {
+	defer e.wg.Done()
+	for block := range e.blockCh {
+		// Keep draining the blocks after the executor is finished, so the fetcher won't be blocked.
+		if e.finished.Load().(bool) {
+			continue
+		}
+		if block.err != nil {
+			e.resultCh <- block
+			continue
+		}
+		rows, err := e.joinBlock(block.rows, outerFilter, otherFilter)
+		e.resultCh <- &execResult{rows: rows, err: errors.Trace(err)}
+	}
+}
+
+func (e *CrossJoinExec) waitJoinWorkersAndCloseResultChan() {
+	e.wg.Wait()
+	close(e.resultCh)
+	close(e.closeCh)
+}
+
+// joinBlock joins a block of the outer rows with all the cached inner rows.
+// For outer join, an outer row without any matched inner row is joined with the default values.
+func (e *CrossJoinExec) joinBlock(block []Row, outerFilter, otherFilter expression.CNFExprs) ([]Row, error) {
+	var rows []Row
+	for _, outerRow := range block {
+		matched, err := expression.EvalBool(outerFilter, outerRow, e.ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		numRows := len(rows)
+		if matched {
+			for _, innerRow := range e.innerRows {
+				joinedRow := e.makeJoinRow(outerRow, innerRow)
+				matched, err = expression.EvalBool(otherFilter, joinedRow, e.ctx)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+				if matched {
+					rows = append(rows, joinedRow)
+				}
+			}
+		}
+		if e.outer && len(rows) == numRows {
+			innerRow := make([]types.Datum, e.innerExec.Schema().Len())
+			copy(innerRow, e.defaultValues)
+			rows = append(rows, e.makeJoinRow(outerRow, innerRow))
+		}
+	}
+	return rows, nil
+}
+
+func (e *CrossJoinExec) makeJoinRow(outerRow, innerRow Row) Row {
+	if e.innerIsLeft {
+		return makeJoinRow(innerRow, outerRow)
+	}
+	return makeJoinRow(outerRow, innerRow)
+}
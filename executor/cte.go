@@ -0,0 +1,468 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+var (
+	_ Executor = &CTEExec{}
+	_ Executor = &CTETableReaderExec{}
+)
+
+// CTEExec reads the rows of a common table expression.
+// All the CTEExecs of the same common table expression share a cteStorage, so the CTE is only evaluated once.
+type CTEExec struct {
+	baseExecutor
+
+	storage *cteStorage
+	iter    *cteRowIterator
+	opened  bool
+}
+
+// Open implements the Executor Open interface.
+func (e *CTEExec) Open() error {
+	if e.opened {
+		return errors.Trace(e.closeIter())
+	}
+	e.storage.open()
+	e.opened = true
+	return nil
+}
+
+// Next implements the Executor Next interface.
+func (e *CTEExec) Next() (Row, error) {
+	if e.iter == nil {
+		err := e.storage.materialize()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		e.iter, err = e.storage.result.iterator()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	row, err := e.iter.next()
+	return row, errors.Trace(err)
+}
+
+// Close implements the Executor Close interface.
+func (e *CTEExec) Close() error {
+	err := e.closeIter()
+	if e.opened {
+		e.opened = false
+		if releaseErr := e.storage.release(); err == nil {
+			err = releaseErr
+		}
+	}
+	return errors.Trace(err)
+}
+
+func (e *CTEExec) closeIter() error {
+	if e.iter == nil {
+		return nil
+	}
+	err := e.iter.close()
+	e.iter = nil
+	return errors.Trace(err)
+}
+
+// CTETableReaderExec is used in the recursive part of a recursive common table expression,
+// it reads the rows produced by the last iteration.
+type CTETableReaderExec struct {
+	baseExecutor
+
+	storage *cteStorage
+	iter    *cteRowIterator
+}
+
+// Open implements the Executor Open interface.
+func (e *CTETableReaderExec) Open() error {
+	return errors.Trace(e.Close())
+}
+
+// Next implements the Executor Next interface.
+func (e *CTETableReaderExec) Next() (Row, error) {
+	if e.iter == nil {
+		var err error
+		e.iter, err = e.storage.iterInput.iterator()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	row, err := e.iter.next()
+	return row, errors.Trace(err)
+}
+
+// Close implements the Executor Close interface.
+func (e *CTETableReaderExec) Close() error {
+	if e.iter == nil {
+		return nil
+	}
+	err := e.iter.close()
+	e.iter = nil
+	return errors.Trace(err)
+}
+
+// cteStorage evaluates a common table expression and holds its rows.
+type cteStorage struct {
+	sync.Mutex
+
+	def           *plan.CTEDefinition
+	ctx           context.Context
+	fieldTypes    []*types.FieldType
+	seedExec      Executor
+	recursiveExec Executor
+
+	// refCount is the number of the opened CTEExecs, the rows are released when it drops to zero.
+	refCount     int
+	materialized bool
+	err          error
+	// result holds all the rows of the common table expression.
+	result *cteRowContainer
+	// iterInput holds the rows produced by the last iteration, which are read by the CTETableReaderExecs.
+	iterInput *cteRowContainer
+	// distinctKeys is used to discard the duplicated rows when the query blocks are combined by UNION DISTINCT.
+	// The keys can't be spilled to disk, so the statement fails once their size exceeds the memory quota.
+	distinctKeys     map[string]struct{}
+	distinctMemUsage int64
+	memQuota         int64
+}
+
+// distinctKeyMemOverhead is the estimated memory usage in bytes of a distinct key besides its data.
+const distinctKeyMemOverhead = 32
+
+func (s *cteStorage) open() {
+	s.Lock()
+	s.refCount++
+	s.Unlock()
+}
+
+func (s *cteStorage) release() error {
+	s.Lock()
+	defer s.Unlock()
+	s.refCount--
+	if s.refCount > 0 {
+		return nil
+	}
+	err := s.reset()
+	s.materialized = false
+	s.err = nil
+	return errors.Trace(err)
+}
+
+func (s *cteStorage) reset() error {
+	var err error
+	if s.result != nil {
+		err = s.result.close()
+		s.result = nil
+	}
+	if s.iterInput != nil {
+		if closeErr := s.iterInput.close(); err == nil {
+			err = closeErr
+		}
+		s.iterInput = nil
+	}
+	s.distinctKeys = nil
+	s.distinctMemUsage = 0
+	return errors.Trace(err)
+}
+
+// materialize evaluates the common table expression on the first call, the later calls only return its error.
+func (s *cteStorage) materialize() error {
+	s.Lock()
+	defer s.Unlock()
+	if !s.materialized {
+		s.materialized = true
+		s.err = s.doMaterialize()
+	}
+	return errors.Trace(s.err)
+}
+
+// doMaterialize runs the seed part, then runs the recursive part repeatedly with the rows produced by
+// the last iteration, until an iteration produces no new rows.
+func (s *cteStorage) doMaterialize() error {
+	vars := s.ctx.GetSessionVars()
+	s.memQuota = vars.MemQuotaCTE
+	s.result = newCTERowContainer(s.fieldTypes, vars.MemQuotaCTE)
+	if s.def.IsDistinct {
+		s.distinctKeys = make(map[string]struct{})
+	}
+	iterOutput := newCTERowContainer(s.fieldTypes, vars.MemQuotaCTE)
+	err := s.fetchAll(s.seedExec, iterOutput)
+	for iter := 1; err == nil && s.recursiveExec != nil && iterOutput.len() > 0; iter++ {
+		if iter > vars.CTEMaxRecursionDepth {
+			err = ErrCTEMaxRecursionDepth.GenByArgs(iter)
+			break
+		}
+		if s.iterInput != nil {
+			err = s.iterInput.close()
+			if err != nil {
+				break
+			}
+		}
+		s.iterInput = iterOutput
+		iterOutput = newCTERowContainer(s.fieldTypes, vars.MemQuotaCTE)
+		err = s.fetchAll(s.recursiveExec, iterOutput)
+	}
+	if closeErr := iterOutput.close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = s.result.finish()
+	}
+	return errors.Trace(err)
+}
+
+// fetchAll reads all the rows from exec, and adds the new ones into both the result and output.
+func (s *cteStorage) fetchAll(exec Executor, output *cteRowContainer) error {
+	err := exec.Open()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for {
+		var row Row
+		row, err = exec.Next()
+		if err != nil || row == nil {
+			break
+		}
+		if s.distinctKeys != nil {
+			var key []byte
+			key, err = codec.EncodeValue(nil, row...)
+			if err != nil {
+				break
+			}
+			if _, ok := s.distinctKeys[string(key)]; ok {
+				continue
+			}
+			s.distinctKeys[string(key)] = struct{}{}
+			s.distinctMemUsage += int64(len(key)) + distinctKeyMemOverhead
+			if s.distinctMemUsage > s.memQuota {
+				err = ErrMemQuotaExceeded.GenByArgs("common table expression", variable.TiDBMemQuotaCTE, s.memQuota)
+				break
+			}
+		}
+		err = s.result.add(row)
+		if err != nil {
+			break
+		}
+		err = output.add(row)
+		if err != nil {
+			break
+		}
+	}
+	if closeErr := exec.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = output.finish()
+	}
+	return errors.Trace(err)
+}
+
+// cteRowContainer holds rows in memory, and moves them into a temporary file
+// once their estimated size exceeds the memory quota.
+type cteRowContainer struct {
+	fieldTypes []*types.FieldType
+	memQuota   int64
+	memUsage   int64
+	numRows    int
+	rows       []Row
+
+	file   *os.File
+	writer *bufio.Writer
+}
+
+func newCTERowContainer(fieldTypes []*types.FieldType, memQuota int64) *cteRowContainer {
+	return &cteRowContainer{
+		fieldTypes: fieldTypes,
+		memQuota:   memQuota,
+	}
+}
+
+func (c *cteRowContainer) len() int {
+	return c.numRows
+}
+
+func (c *cteRowContainer) add(row Row) error {
+	c.numRows++
+	if c.file != nil {
+		return errors.Trace(c.writeRow(row))
+	}
+	c.rows = append(c.rows, row)
+	c.memUsage += estimateRowSize(row)
+	if c.memUsage > c.memQuota {
+		return errors.Trace(c.spillToDisk())
+	}
+	return nil
+}
+
+// spillToDisk creates a temporary file in the configured temporary directory, and moves all the rows into it.
+func (c *cteRowContainer) spillToDisk() error {
+	file, err := ioutil.TempFile(config.GetGlobalConfig().TempDir, "tidb-cte-")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	c.file = file
+	c.writer = bufio.NewWriter(file)
+	for _, row := range c.rows {
+		err = c.writeRow(row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	c.rows = nil
+	c.memUsage = 0
+	return nil
+}
+
+// writeRow encodes the values of a row one by one, so they can be restored with the field types,
+// and writes them with a length prefix.
+func (c *cteRowContainer) writeRow(row Row) error {
+	vals := make([]types.Datum, len(row))
+	for i, d := range row {
+		b, err := tablecodec.EncodeValue(d, time.UTC)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		vals[i].SetBytes(b)
+	}
+	data, err := codec.EncodeValue(nil, vals...)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var lenBuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lenBuf[:], uint64(len(data)))
+	_, err = c.writer.Write(lenBuf[:n])
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = c.writer.Write(data)
+	return errors.Trace(err)
+}
+
+// finish flushes the buffered data into the temporary file, it must be called before the rows are read.
+func (c *cteRowContainer) finish() error {
+	if c.writer == nil {
+		return nil
+	}
+	return errors.Trace(c.writer.Flush())
+}
+
+func (c *cteRowContainer) iterator() (*cteRowIterator, error) {
+	it := &cteRowIterator{c: c}
+	if c.file != nil {
+		file, err := os.Open(c.file.Name())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		it.file = file
+		it.reader = bufio.NewReader(file)
+	}
+	return it, nil
+}
+
+// close releases the rows and removes the temporary file.
+func (c *cteRowContainer) close() error {
+	c.rows = nil
+	if c.file == nil {
+		return nil
+	}
+	err := c.file.Close()
+	if removeErr := os.Remove(c.file.Name()); err == nil {
+		err = removeErr
+	}
+	c.file = nil
+	c.writer = nil
+	return errors.Trace(err)
+}
+
+// cteRowIterator reads the rows of a cteRowContainer, each iterator has its own cursor.
+type cteRowIterator struct {
+	c   *cteRowContainer
+	idx int
+
+	file   *os.File
+	reader *bufio.Reader
+}
+
+func (it *cteRowIterator) next() (Row, error) {
+	if it.reader == nil {
+		if it.idx >= len(it.c.rows) {
+			return nil, nil
+		}
+		row := it.c.rows[it.idx]
+		it.idx++
+		return row, nil
+	}
+	length, err := binary.ReadUvarint(it.reader)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	data := make([]byte, length)
+	_, err = io.ReadFull(it.reader, data)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	vals, err := codec.Decode(data, len(it.c.fieldTypes))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	row := make(Row, len(vals))
+	for i, d := range vals {
+		row[i], err = tablecodec.DecodeColumnValue(d.GetBytes(), it.c.fieldTypes[i], time.UTC)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return row, nil
+}
+
+func (it *cteRowIterator) close() error {
+	if it.file == nil {
+		return nil
+	}
+	err := it.file.Close()
+	it.file = nil
+	it.reader = nil
+	return errors.Trace(err)
+}
+
+// estimateRowSize estimates the memory usage of a row.
+func estimateRowSize(row Row) int64 {
+	size := datumSize * int64(len(row))
+	for i := range row {
+		size += int64(len(row[i].GetBytes()))
+	}
+	return size
+}
@@ -0,0 +1,102 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor_test
+
+import (
+	"github.com/juju/errors"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testkit"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func (s *testSuite) TestCTE(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b varchar(10))")
+	tk.MustExec("insert into t values (1, 'a'), (2, 'b'), (3, 'c')")
+
+	result := tk.MustQuery("with cte as (select a, b from t where a > 1) select * from cte order by a")
+	result.Check(testkit.Rows("2 b", "3 c"))
+	result = tk.MustQuery("with cte (x, y) as (select a, b from t) select c1.x, c2.y from cte c1 join cte c2 on c1.x = c2.x + 1 order by c1.x")
+	result.Check(testkit.Rows("2 a", "3 b"))
+	result = tk.MustQuery("with c1 as (select a from t), c2 as (select a * 10 as a from c1) select a from c2 order by a")
+	result.Check(testkit.Rows("10", "20", "30"))
+	result = tk.MustQuery("select * from (with cte as (select 1 as a) select a from cte) x")
+	result.Check(testkit.Rows("1"))
+	result = tk.MustQuery("with cte as (select a from t) select a from t where a in (select a + 1 from cte) order by a")
+	result.Check(testkit.Rows("2", "3"))
+
+	// Recursive common table expressions.
+	result = tk.MustQuery("with recursive seq (n) as (select 1 union all select n + 1 from seq where n < 5) select * from seq")
+	result.Check(testkit.Rows("1", "2", "3", "4", "5"))
+	result = tk.MustQuery("with recursive seq as (select a as n, b from t where a = 1 union all select n + 1, concat(b, 'x') from seq where n < 3) select * from seq")
+	result.Check(testkit.Rows("1 a", "2 ax", "3 axx"))
+	result = tk.MustQuery("with recursive cte (n) as (select 1 union select (n + 1) % 3 from cte) select * from cte order by n")
+	result.Check(testkit.Rows("0", "1", "2"))
+	result = tk.MustQuery("with recursive cte (n) as (select 1 union all select n + 1 from cte where n < 3) select count(*) from cte c1, cte c2")
+	result.Check(testkit.Rows("9"))
+
+	// The rows of the common table expressions are spilled to disk.
+	tk.MustExec("set @@tidb_mem_quota_cte = 1")
+	result = tk.MustQuery("with recursive seq (n, s) as (select 1, 'a' union all select n + 1, concat(s, 'a') from seq where n < 4) select * from seq")
+	result.Check(testkit.Rows("1 a", "2 aa", "3 aaa", "4 aaaa"))
+	result = tk.MustQuery("with cte as (select a, b from t) select c1.a, c2.b from cte c1 join cte c2 on c1.a = c2.a order by c1.a")
+	result.Check(testkit.Rows("1 a", "2 b", "3 c"))
+	// The distinct keys can't be spilled, so the statement fails.
+	rs, err := tk.Exec("with recursive cte (n) as (select 1 union select n + 1 from cte where n < 4) select * from cte")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err, NotNil)
+	c.Assert(rs.Close(), IsNil)
+	c.Assert(terror.ErrorEqual(err, executor.ErrMemQuotaExceeded), IsTrue, Commentf("err %v", err))
+	tk.MustExec("set @@tidb_mem_quota_cte = 1073741824")
+	result = tk.MustQuery("with recursive cte (n) as (select 1 union select n + 1 from cte where n < 4) select * from cte")
+	result.Check(testkit.Rows("1", "2", "3", "4"))
+
+	tk.MustExec("set @@cte_max_recursion_depth = 10")
+	result = tk.MustQuery("with recursive seq (n) as (select 1 union all select n + 1 from seq where n < 10) select count(*) from seq")
+	result.Check(testkit.Rows("10"))
+	rs, err = tk.Exec("with recursive seq (n) as (select 1 union all select n + 1 from seq where n < 20) select count(*) from seq")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err, NotNil)
+	c.Assert(rs.Close(), IsNil)
+	c.Assert(terror.ErrorEqual(err, executor.ErrCTEMaxRecursionDepth), IsTrue, Commentf("err %v", err))
+
+	errCases := []struct {
+		sql  string
+		code int
+	}{
+		{"with cte as (select 1), cte as (select 2) select * from cte", mysql.ErrNonuniqTable},
+		{"with cte (x, y) as (select 1) select * from cte", mysql.ErrViewWrongList},
+		{"with recursive cte as (select n + 1 from cte) select * from cte", mysql.ErrCTERecursiveRequiresUnion},
+		{"with recursive cte (n) as (select n + 1 from cte union all select 1) select * from cte", mysql.ErrCTERecursiveRequiresNonRecursiveFirst},
+		{"with recursive cte (n) as (select 1 union all select 2 from t where a in (select n from cte) union all select 3) select * from cte", mysql.ErrCTERecursiveRequiresNonRecursiveFirst},
+		{"with recursive cte (n) as (select 1 union all select max(n) + 1 from cte) select * from cte", mysql.ErrCTERecursiveForbidsAggregation},
+	}
+	for _, ca := range errCases {
+		_, err := tk.Exec(ca.sql)
+		c.Assert(err, NotNil, Commentf("sql: %s", ca.sql))
+		terr := errors.Cause(err).(*terror.Error)
+		c.Assert(terr.ToSQLError().Code, Equals, uint16(ca.code), Commentf("sql: %s", ca.sql))
+	}
+}
@@ -195,11 +195,7 @@ func (s *testSuite) TestAlterTableModifyColumn(c *C) {
 	c.Assert(err, NotNil)
 	tk.MustExec("alter table mc modify column c1 bigint")
 
-	_, err = tk.Exec("alter table mc modify column c2 blob")
-	c.Assert(err, NotNil)
-
-	_, err = tk.Exec("alter table mc modify column c2 varchar(8)")
-	c.Assert(err, NotNil)
+	tk.MustExec("alter table mc modify column c2 varchar(8)")
 	tk.MustExec("alter table mc modify column c2 varchar(11)")
 	tk.MustExec("alter table mc modify column c2 text(13)")
 	tk.MustExec("alter table mc modify column c2 text")
@@ -278,3 +274,86 @@ func (s *testSuite) TestUnsupportedCharset(c *C) {
 	}
 	tk.MustExec("drop database " + dbName)
 }
+
+func (s *testSuite) TestAlterTableModifyColumnWithReorg(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists mc")
+	tk.MustExec("create table mc(a int, b varchar(10), c varchar(20))")
+	tk.MustExec("insert into mc values (1, '12', '2017-09-01 10:00:00'), (2, 'abc', '2017-09-02')")
+
+	// Shrink the varchar column.
+	tk.MustExec("alter table mc modify column b varchar(3)")
+	tk.MustQuery("select b from mc order by a").Check(testkit.Rows("12", "abc"))
+	_, err := tk.Exec("insert into mc (b) values ('abcd')")
+	c.Assert(err, NotNil)
+	// Convert the string column to datetime.
+	tk.MustExec("alter table mc modify column c datetime")
+	tk.MustQuery("select c from mc order by a").Check(testkit.Rows("2017-09-01 10:00:00", "2017-09-02 00:00:00"))
+	// The value 'abc' can't be converted, so the job is rolled back.
+	_, err = tk.Exec("alter table mc modify column b int")
+	c.Assert(err, NotNil)
+	tk.MustQuery("select b from mc order by a").Check(testkit.Rows("12", "abc"))
+	tk.MustExec("delete from mc where b = 'abc'")
+	tk.MustExec("alter table mc modify column b int")
+	// Change the column name, type and position together.
+	tk.MustExec("alter table mc change column a d bigint unsigned after c")
+	tk.MustExec("insert into mc values (34, '2017-09-03', 3)")
+	tk.MustQuery("select * from mc order by d").Check(testkit.Rows("12 2017-09-01 10:00:00 1", "34 2017-09-03 00:00:00 3"))
+	result := tk.MustQuery("show create table mc")
+	c.Assert(result.Rows()[0][1], Equals, "CREATE TABLE `mc` (\n  `b` int(11) DEFAULT NULL,\n  `c` datetime DEFAULT NULL,\n  `d` bigint(20) UNSIGNED DEFAULT NULL\n) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin")
+
+	// The indices covering the column are rebuilt with the converted values.
+	tk.MustExec("alter table mc add index idx_b(b)")
+	tk.MustExec("alter table mc add unique index idx_bd(b, d)")
+	tk.MustExec("alter table mc modify column b varchar(5)")
+	tk.MustQuery("select b, d from mc use index(idx_b) where b = '34'").Check(testkit.Rows("34 3"))
+	tk.MustQuery("select b, d from mc use index(idx_bd) where b > '1' order by b").Check(testkit.Rows("12 1", "34 3"))
+	tk.MustExec("admin check table mc")
+	_, err = tk.Exec("insert into mc (b, d) values ('34', 3)")
+	c.Assert(err, NotNil)
+	// The value can't be converted, so the job is rolled back and the indices are kept.
+	tk.MustExec("insert into mc (b, d) values ('abc', 4)")
+	_, err = tk.Exec("alter table mc modify column b int")
+	c.Assert(err, NotNil)
+	tk.MustQuery("select b from mc use index(idx_b) where b = 'abc'").Check(testkit.Rows("abc"))
+	tk.MustExec("admin check table mc")
+	// The converted values are duplicated in the unique index, so the job is rolled back.
+	tk.MustExec("update mc set b = '012' where b = 'abc'")
+	tk.MustExec("update mc set d = 1 where b = '012'")
+	_, err = tk.Exec("alter table mc modify column b int")
+	c.Assert(err, NotNil)
+	tk.MustQuery("select b from mc use index(idx_bd) where b = '012'").Check(testkit.Rows("012"))
+	tk.MustExec("delete from mc where b = '012'")
+	tk.MustExec("alter table mc modify column b int")
+	tk.MustQuery("select b, d from mc use index(idx_b) where b = 34").Check(testkit.Rows("34 3"))
+	tk.MustExec("admin check table mc")
+	result = tk.MustQuery("show create table mc")
+	c.Assert(result.Rows()[0][1], Equals, "CREATE TABLE `mc` (\n  `b` int(11) DEFAULT NULL,\n  `c` datetime DEFAULT NULL,\n  `d` bigint(20) UNSIGNED DEFAULT NULL,\n  KEY `idx_b` (`b`),\n  UNIQUE KEY `idx_bd` (`b`,`d`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin")
+	// The prefix index can't cover the column of non-string type.
+	tk.MustExec("alter table mc add index idx_c(c)")
+	tk.MustExec("alter table mc modify column c varchar(20)")
+	tk.MustExec("alter table mc drop index idx_c")
+	tk.MustExec("alter table mc add index idx_c(c(10))")
+	_, err = tk.Exec("alter table mc modify column c datetime")
+	c.Assert(err, NotNil)
+
+	// The primary key handle column is converted between the integer types.
+	tk.MustExec("drop table if exists mc_pk")
+	tk.MustExec("create table mc_pk(a int primary key, b int)")
+	tk.MustExec("insert into mc_pk values (1, 10), (1000, 20)")
+	// The value 1000 overflows the new type, so the job is rolled back.
+	_, err = tk.Exec("alter table mc_pk modify column a tinyint(2)")
+	c.Assert(err, NotNil)
+	tk.MustQuery("select b from mc_pk where a = 1000").Check(testkit.Rows("20"))
+	tk.MustExec("alter table mc_pk modify column a int unsigned")
+	tk.MustQuery("select b from mc_pk where a = 1000").Check(testkit.Rows("20"))
+	tk.MustQuery("select a from mc_pk where a > 1").Check(testkit.Rows("1000"))
+	_, err = tk.Exec("insert into mc_pk values (1000, 30)")
+	c.Assert(err, NotNil)
+	result = tk.MustQuery("show create table mc_pk")
+	c.Assert(result.Rows()[0][1], Equals, "CREATE TABLE `mc_pk` (\n  `a` int(11) UNSIGNED NOT NULL,\n  `b` int(11) DEFAULT NULL,\n  PRIMARY KEY (`a`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin")
+	_, err = tk.Exec("alter table mc_pk modify column a varchar(10)")
+	c.Assert(err, NotNil)
+}
@@ -338,6 +338,7 @@ func closeAll(objs ...Closeable) error {
 type XSelectIndexExec struct {
 	tableInfo      *model.TableInfo
 	table          table.Table
+	tableID        int64
 	ctx            context.Context
 	supportDesc    bool
 	isMemDB        bool
@@ -663,7 +664,7 @@ func (e *XSelectIndexExec) doIndexRequest() (distsql.SelectResult, error) {
 	}
 	sv := e.ctx.GetSessionVars()
 	sc := sv.StmtCtx
-	keyRanges, err := indexRangesToKVRanges(sc, e.table.Meta().ID, e.index.ID, e.ranges, fieldTypes)
+	keyRanges, err := indexRangesToKVRanges(sc, e.tableID, e.index.ID, e.ranges, fieldTypes)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -827,7 +828,7 @@ func (e *XSelectIndexExec) doTableRequest(handles []int64) (distsql.SelectResult
 	selTableReq.TimeZoneOffset = timeZoneOffset(e.ctx)
 	selTableReq.Flags = statementContextToFlags(e.ctx.GetSessionVars().StmtCtx)
 	selTableReq.TableInfo = &tipb.TableInfo{
-		TableId: e.table.Meta().ID,
+		TableId: e.tableID,
 	}
 	selTableReq.TableInfo.Columns = distsql.ColumnsToProto(e.columns, e.table.Meta().PKIsHandle)
 	err := setPBColumnsDefaultValue(e.ctx, selTableReq.TableInfo.Columns, e.columns)
@@ -838,7 +839,7 @@ func (e *XSelectIndexExec) doTableRequest(handles []int64) (distsql.SelectResult
 	// Aggregate Info
 	selTableReq.Aggregates = e.aggFuncs
 	selTableReq.GroupBy = e.byItems
-	keyRanges := tableHandlesToKVRanges(e.table.Meta().ID, handles)
+	keyRanges := tableHandlesToKVRanges(e.tableID, handles)
 	// Use the table scan concurrency variable to do table request.
 	concurrency := e.ctx.GetSessionVars().DistSQLScanConcurrency
 	resp, err := distsql.Select(e.ctx.GetClient(), goctx.Background(), selTableReq, keyRanges, concurrency, false, getIsolationLevel(e.ctx.GetSessionVars()), e.priority)
@@ -854,6 +855,7 @@ func (e *XSelectIndexExec) doTableRequest(handles []int64) (distsql.SelectResult
 type XSelectTableExec struct {
 	tableInfo   *model.TableInfo
 	table       table.Table
+	tableID     int64
 	ctx         context.Context
 	supportDesc bool
 	isMemDB     bool
@@ -906,7 +908,7 @@ func (e *XSelectTableExec) doRequest() error {
 	selReq.Flags = statementContextToFlags(e.ctx.GetSessionVars().StmtCtx)
 	selReq.Where = e.where
 	selReq.TableInfo = &tipb.TableInfo{
-		TableId: e.tableInfo.ID,
+		TableId: e.tableID,
 	}
 	selReq.TableInfo.Columns = distsql.ColumnsToProto(e.Columns, e.tableInfo.PKIsHandle)
 	err := setPBColumnsDefaultValue(e.ctx, selReq.TableInfo.Columns, e.Columns)
@@ -923,7 +925,7 @@ func (e *XSelectTableExec) doRequest() error {
 	selReq.Aggregates = e.aggFuncs
 	selReq.GroupBy = e.byItems
 
-	kvRanges := tableRangesToKVRanges(e.table.Meta().ID, e.ranges)
+	kvRanges := tableRangesToKVRanges(e.tableID, e.ranges)
 	e.result, err = distsql.Select(e.ctx.GetClient(), goctx.Background(), selReq, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, getIsolationLevel(e.ctx.GetSessionVars()), e.priority)
 	if err != nil {
 		return errors.Trace(err)
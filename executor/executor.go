@@ -67,6 +67,9 @@ var (
 	ErrBuildExecutor        = terror.ClassExecutor.New(codeErrBuildExec, "Failed to build executor")
 	ErrBatchInsertFail      = terror.ClassExecutor.New(codeBatchInsertFail, "Batch insert failed, please clean the table and try again.")
 	ErrWrongValueCountOnRow = terror.ClassExecutor.New(codeWrongValueCountOnRow, "Column count doesn't match value count at row %d")
+	ErrCTEMaxRecursionDepth = terror.ClassExecutor.New(codeCTEMaxRecursionDepth, mysql.MySQLErrName[mysql.ErrCTEMaxRecursionDepth])
+	ErrPluginIsNotLoaded    = terror.ClassExecutor.New(codePluginIsNotLoaded, mysql.MySQLErrName[mysql.ErrPluginIsNotLoaded])
+	ErrMemQuotaExceeded     = terror.ClassExecutor.New(codeMemQuotaExceeded, "Out of memory quota: %s uses more than %s = %d bytes")
 )
 
 // Error codes.
@@ -81,9 +84,12 @@ const (
 	codeResultIsEmpty        terror.ErrCode = 8
 	codeErrBuildExec         terror.ErrCode = 9
 	codeBatchInsertFail      terror.ErrCode = 10
+	codeMemQuotaExceeded     terror.ErrCode = 11
 	CodePasswordNoMatch      terror.ErrCode = 1133 // MySQL error code
 	CodeCannotUser           terror.ErrCode = 1396 // MySQL error code
 	codeWrongValueCountOnRow terror.ErrCode = 1136 // MySQL error code
+	codeCTEMaxRecursionDepth terror.ErrCode = 3636 // MySQL error code
+	codePluginIsNotLoaded    terror.ErrCode = 1524 // MySQL error code
 )
 
 // Row represents a result set row, it may be returned from a table, a join, or a projection.
@@ -227,12 +233,19 @@ func (e *CheckTableExec) Next() (Row, error) {
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		for _, idx := range tb.Indices() {
-			txn := e.ctx.Txn()
-			err = inspectkv.CompareIndexData(txn, tb, idx)
-			if err != nil {
-				return nil, errors.Errorf("%v err:%v", t.Name, err)
+		if pi := tb.Meta().Partition; pi != nil {
+			// The records and indices of a partitioned table are stored in its partitions.
+			for _, def := range pi.Definitions {
+				err = e.checkIndices(t.Name, tb.(table.PartitionedTable).GetPartition(def.ID))
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
 			}
+			continue
+		}
+		err = e.checkIndices(t.Name, tb)
+		if err != nil {
+			return nil, errors.Trace(err)
 		}
 	}
 	e.done = true
@@ -240,6 +253,17 @@ func (e *CheckTableExec) Next() (Row, error) {
 	return nil, nil
 }
 
+func (e *CheckTableExec) checkIndices(name model.CIStr, tb table.Table) error {
+	for _, idx := range tb.Indices() {
+		txn := e.ctx.Txn()
+		err := inspectkv.CompareIndexData(e.ctx, txn, tb, idx)
+		if err != nil {
+			return errors.Errorf("%v err:%v", name, err)
+		}
+	}
+	return nil
+}
+
 // Close implements plan.Plan Close interface.
 func (e *CheckTableExec) Close() error {
 	return nil
@@ -251,14 +275,34 @@ func (e *CheckTableExec) Close() error {
 // After the execution, the keys are buffered in transaction, and will be sent to KV
 // when doing commit. If there is any key already locked by another transaction,
 // the transaction will rollback and retry.
+// In a pessimistic transaction, the keys are locked when the statement is executed,
+// and the statement waits for the locks held by other transactions.
 type SelectLockExec struct {
 	baseExecutor
 
 	Lock ast.SelectLockType
+
+	// rows buffers the locked rows in a pessimistic transaction.
+	rows   []Row
+	cursor int
+	locked bool
+}
+
+// Open implements the Executor Open interface.
+func (e *SelectLockExec) Open() error {
+	e.rows, e.cursor, e.locked = nil, 0, false
+	return errors.Trace(e.children[0].Open())
 }
 
 // Next implements the Executor Next interface.
 func (e *SelectLockExec) Next() (Row, error) {
+	// If there's no handle or it isn't a `select for update`.
+	if len(e.Schema().TblID2Handle) == 0 || e.Lock != ast.SelectLockForUpdate {
+		return e.children[0].Next()
+	}
+	if e.ctx.GetSessionVars().TxnCtx.IsPessimistic {
+		return e.pessimisticNext()
+	}
 	row, err := e.children[0].Next()
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -266,26 +310,59 @@ func (e *SelectLockExec) Next() (Row, error) {
 	if row == nil {
 		return nil, nil
 	}
-	// If there's no handle or it isn't a `select for update`.
-	if len(e.Schema().TblID2Handle) == 0 || e.Lock != ast.SelectLockForUpdate {
-		return row, nil
+	err = e.ctx.Txn().LockKeys(e.rowKeys(row)...)
+	if err != nil {
+		return nil, errors.Trace(err)
 	}
-	txn := e.ctx.Txn()
+	return row, nil
+}
+
+// pessimisticNext reads all the rows from the child and locks them in one request,
+// the rows are returned after they are all locked.
+func (e *SelectLockExec) pessimisticNext() (Row, error) {
+	if !e.locked {
+		var keys []kv.Key
+		for {
+			row, err := e.children[0].Next()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if row == nil {
+				break
+			}
+			e.rows = append(e.rows, row)
+			keys = append(keys, e.rowKeys(row)...)
+		}
+		e.locked = true
+		if len(keys) > 0 {
+			err := e.ctx.Txn().LockKeys(keys...)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+	}
+	if e.cursor >= len(e.rows) {
+		return nil, nil
+	}
+	row := e.rows[e.cursor]
+	e.cursor++
+	return row, nil
+}
+
+// rowKeys returns the keys to lock for the row.
+func (e *SelectLockExec) rowKeys(row Row) []kv.Key {
 	txnCtx := e.ctx.GetSessionVars().TxnCtx
 	txnCtx.ForUpdate = true
+	var keys []kv.Key
 	for id, cols := range e.Schema().TblID2Handle {
 		for _, col := range cols {
 			handle := row[col.Index].GetInt64()
-			lockKey := tablecodec.EncodeRowKeyWithHandle(id, handle)
-			err = txn.LockKeys(lockKey)
-			if err != nil {
-				return nil, errors.Trace(err)
-			}
+			keys = append(keys, tablecodec.EncodeRowKeyWithHandle(id, handle))
 			// This operation is only for schema validator check.
 			txnCtx.UpdateDeltaForTable(id, 0, 0)
 		}
 	}
-	return row, nil
+	return keys
 }
 
 // LimitExec represents limit executor
@@ -363,6 +440,8 @@ func init() {
 		CodeCannotUser:           mysql.ErrCannotUser,
 		CodePasswordNoMatch:      mysql.ErrPasswordNoMatch,
 		codeWrongValueCountOnRow: mysql.ErrWrongValueCountOnRow,
+		codeCTEMaxRecursionDepth: mysql.ErrCTEMaxRecursionDepth,
+		codePluginIsNotLoaded:    mysql.ErrPluginIsNotLoaded,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassExecutor] = tableMySQLErrCodes
 }
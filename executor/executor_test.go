@@ -47,6 +47,7 @@ import (
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
 	"github.com/pingcap/tidb/util/types"
+	"github.com/prometheus/client_golang/prometheus"
 	goctx "golang.org/x/net/context"
 )
 
@@ -102,10 +103,14 @@ func (s *testSuite) TearDownSuite(c *C) {
 func (s *testSuite) cleanEnv(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	tk.MustExec("use test")
-	r := tk.MustQuery("show tables")
+	r := tk.MustQuery("show full tables")
 	for _, tb := range r.Rows() {
 		tableName := tb[0]
-		tk.MustExec(fmt.Sprintf("drop table %v", tableName))
+		if tb[1] == "VIEW" {
+			tk.MustExec(fmt.Sprintf("drop view %v", tableName))
+		} else {
+			tk.MustExec(fmt.Sprintf("drop table %v", tableName))
+		}
 	}
 }
 
@@ -674,6 +679,49 @@ func (s *testSuite) TestSelectOrderBy(c *C) {
 	tk.MustQuery("select * from t use index(idx) order by a desc limit 1").Check(testkit.Rows("3 1 3"))
 }
 
+func (s *testSuite) TestSortSpillToDisk(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b varchar(20), c datetime, d decimal(10, 2), e time, f enum('x', 'y'))")
+	for i := 0; i < 100; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t values (%d, 'str%02d', '2017-01-01 00:00:%02d', %d.5, '10:00:%02d', '%s')",
+			i%10, i, i%60, i, i%60, []string{"x", "y"}[i%2]))
+	}
+	tk.MustExec("insert into t values (null, null, null, null, null, null)")
+	spilled := sortSpillCount(c)
+	expected := tk.MustQuery("select * from t order by a desc, b").Rows()
+	c.Assert(expected, HasLen, 101)
+	c.Assert(sortSpillCount(c), Equals, spilled)
+
+	// Every row exceeds the memory quota, so the sort is spilled to disk.
+	tk.MustExec("set @@tidb_mem_quota_sort = 1")
+	result := tk.MustQuery("select * from t order by a desc, b")
+	result.Check(expected)
+	c.Assert(sortSpillCount(c), Equals, spilled+1)
+	// The TopN operator is not spilled.
+	tk.MustQuery("select a, b from t order by d limit 2").Check(testkit.Rows("<nil> <nil>", "0 str00"))
+	tk.MustQuery("select count(*) from (select * from t order by c) tmp").Check(testkit.Rows("101"))
+	c.Assert(sortSpillCount(c), Equals, spilled+2)
+}
+
+// sortSpillCount returns the number of the sort operators spilled to disk.
+func sortSpillCount(c *C) float64 {
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	c.Assert(err, IsNil)
+	for _, mf := range mfs {
+		if mf.GetName() == "tidb_executor_sort_spill_total" {
+			return mf.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+	c.Fatal("the sort spill counter is not registered")
+	return 0
+}
+
 func (s *testSuite) TestSelectErrorRow(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -1075,6 +1123,70 @@ func (s *testSuite) TestIndexReverseOrder(c *C) {
 	result.Check(testkit.Rows("0 2", "0 1", "0 0", "1 2", "1 1", "1 0", "2 2", "2 1", "2 0"))
 }
 
+func (s *testSuite) TestIndexMergeReader(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b int, c int, d int, index idx_b (b), index idx_c (c))")
+	tk.MustExec("insert t values (1, 1, 1, 1), (2, 2, 1, 2), (3, 3, 3, 3), (4, 4, 4, 4), (5, 1, 5, 5), (6, 6, 6, 6)")
+	tk.MustQuery("explain select * from t where b = 1 or c = 1").Check(testkit.Rows(
+		"IndexScan_15   cop table:t, index:b, range:[1,1], out of order:true 10",
+		"IndexScan_16   cop table:t, index:c, range:[1,1], out of order:true 10",
+		"TableScan_17   cop table:t, keep order:false 20",
+		"IndexMerge_18   root type:union, partial:[IndexScan_15, IndexScan_16], table:TableScan_17 20",
+	))
+	// The handles read by both the index scans are only looked up once.
+	tk.MustQuery("select * from t where b = 1 or c = 1 order by a").Check(testkit.Rows("1 1 1 1", "2 2 1 2", "5 1 5 5"))
+	tk.MustQuery("select a from t where b = 1 or c > 4 order by a").Check(testkit.Rows("1", "5", "6"))
+	tk.MustQuery("select a from t where (b = 1 or c = 1) and d > 1 order by a").Check(testkit.Rows("2", "5"))
+	tk.MustQuery("select a from t where b = 10 or c = 10").Check(testkit.Rows())
+	tk.MustQuery("select count(*) from t where b = 1 or c = 1").Check(testkit.Rows("3"))
+
+	tk.MustExec("update t set d = 10 where b = 1 or c = 3")
+	tk.MustQuery("select a from t where d = 10 order by a").Check(testkit.Rows("1", "3", "5"))
+	tk.MustExec("delete from t where b = 4 or c = 6")
+	tk.MustQuery("select a from t").Check(testkit.Rows("1", "2", "3", "5"))
+
+	// The index merge reader isn't used in a dirty transaction, the union scan reads the uncommitted rows.
+	tk.MustExec("begin")
+	tk.MustExec("insert t values (7, 1, 7, 7)")
+	tk.MustQuery("select a from t where b = 1 or c = 3 order by a").Check(testkit.Rows("1", "3", "5", "7"))
+	tk.MustExec("rollback")
+
+	// The handles are looked up batch by batch.
+	tk.MustExec("set @@tidb_index_lookup_size = 1")
+	tk.MustQuery("select a from t where b = 1 or c = 3 order by a").Check(testkit.Rows("1", "3", "5"))
+	tk.MustQuery("select a from t where b = 1 and c = 1").Check(testkit.Rows("1"))
+	tk.MustExec("set @@tidb_index_lookup_size = 20000")
+
+	// The handles held for the merge are limited by the memory quota.
+	tk.MustExec("set @@tidb_mem_quota_index_merge = 16")
+	rs, err := tk.Exec("select a from t where b = 1 or c = 3")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(rs.Close(), IsNil)
+	c.Assert(terror.ErrorEqual(err, executor.ErrMemQuotaExceeded), IsTrue, Commentf("err %v", err))
+	tk.MustExec("set @@tidb_mem_quota_index_merge = 1073741824")
+
+	// The conditions matching the functional indices are converted to the ranges.
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b int, c varchar(20), index idx_b ((b + 1)), index idx_c ((lower(c))))")
+	tk.MustExec("insert t values (1, 1, 'A'), (2, 2, 'b'), (3, 3, 'a'), (4, 0, 'c')")
+	tk.MustQuery("explain select a from t where b + 1 = 1 or lower(c) = 'a'").Check(testkit.Rows(
+		"IndexScan_13   cop table:t, index:_V$_idx_b_0, range:[1,1], out of order:true 10",
+		"IndexScan_14   cop table:t, index:_V$_idx_c_0, range:[a,a], out of order:true 10",
+		"TableScan_15   cop table:t, keep order:false 20",
+		"IndexMerge_16 Selection_3  root type:union, partial:[IndexScan_13, IndexScan_14], table:TableScan_15 20",
+		"Selection_3 Projection_4 IndexMerge_16 root or(eq(plus(test.t.b, 1), 1), eq(lower(test.t.c), a)) 6400",
+		"Projection_4  Selection_3 root test.t.a 6400",
+	))
+	tk.MustQuery("select a from t where b + 1 = 1 or lower(c) = 'a' order by a").Check(testkit.Rows("1", "3", "4"))
+}
+
 func (s *testSuite) TestTableReverseOrder(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -1173,6 +1285,24 @@ func (s *testSuite) TestJSON(c *C) {
 	result.Check(testkit.Rows(`bb true`))
 	result = tk.MustQuery(`select a->'$.a[2].aa' as x, a->>'$.b' as y from test_json having x is not null order by id`)
 	result.Check(testkit.Rows(`"bb" true`))
+	result = tk.MustQuery(`select tj.a->>'$.a[2].aa' from test_json tj where tj.a->'$.b' = true`)
+	result.Check(testkit.Rows(`bb`))
+	result = tk.MustQuery(`select test.test_json.a->'$.a[0]' from test_json where id = 1`)
+	result.Check(testkit.Rows(`1`))
+
+	// Check json search and containment functions.
+	result = tk.MustQuery(`select id, json_length(a), json_depth(a), json_keys(a) from test_json order by id`)
+	result.Check(testkit.Rows(`1 2 4 ["a","b"]`, "2 1 1 <nil>", "3 <nil> <nil> <nil>", "4 1 1 <nil>", "5 1 1 <nil>", "5 1 1 <nil>", "6 1 1 <nil>"))
+	result = tk.MustQuery(`select id from test_json where json_contains(a, '{"aa": "bb"}', '$.a') order by id`)
+	result.Check(testkit.Rows("1"))
+	result = tk.MustQuery(`select id from test_json where json_contains(a, '3') or json_contains_path(a, 'all', '$.a', '$.b') order by id`)
+	result.Check(testkit.Rows("1", "5"))
+	result = tk.MustQuery(`select json_search(a, 'all', 'b%'), json_search(a, 'one', '_'), json_length(a, '$.a') from test_json where id = 1`)
+	result.Check(testkit.Rows(`"$.a[2].aa" "$.a[1]" 4`))
+	result = tk.MustQuery(`select json_array_append(a, '$.a', 5, '$.b', 'x'), json_valid(a) from test_json where id = 1`)
+	result.Check(testkit.Rows(`{"a":[1,"2",{"aa":"bb"},4,5],"b":[true,"x"]} 1`))
+	result = tk.MustQuery(`select json_valid('{"a": 1}'), json_valid('{"a"}'), json_valid(1), json_valid(null)`)
+	result.Check(testkit.Rows("1 0 0 <nil>"))
 
 	// Check some DDL limits for TEXT/BLOB/JSON column.
 	var err error
@@ -1405,6 +1535,64 @@ func (s *testSuite) TestGeneratedColumnRead(c *C) {
 	}
 }
 
+func (s *testSuite) TestFunctionalIndex(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec(`CREATE TABLE test_fi(a int, b int, c varchar(20), d json, index ab((a + b)), index c((lower(c)), a), index d((cast(d->'$.a' as signed))))`)
+
+	// Insert without the column list, the hidden columns are calculated.
+	tk.MustExec(`INSERT INTO test_fi VALUES (1, 2, 'Abc', '{"a": 1}'), (3, 4, 'XyZ', '{"a": 2}')`)
+	tk.MustExec(`INSERT INTO test_fi (a, b, c, d) VALUES (5, 6, 'xyz', '{"a": 3}')`)
+	tk.MustExec(`INSERT INTO test_fi SET a = 7, b = 8`)
+	tk.MustExec(`INSERT INTO test_fi SELECT a + 10, b, c, d FROM test_fi WHERE a = 1`)
+	tk.MustQuery(`SELECT * FROM test_fi ORDER BY a`).Check(testkit.Rows(`1 2 Abc {"a":1}`, `3 4 XyZ {"a":2}`,
+		`5 6 xyz {"a":3}`, `7 8 <nil> <nil>`, `11 2 Abc {"a":1}`))
+	tk.MustExec(`ADMIN CHECK TABLE test_fi`)
+
+	// The hidden columns can't be referred.
+	_, err := tk.Exec(`INSERT INTO test_fi (a, _V$_ab_0) VALUES (1, 2)`)
+	c.Assert(err, NotNil)
+	_, err = tk.Exec(`INSERT INTO test_fi VALUES (1, 2, 'a', '{}', 3)`)
+	c.Assert(err, NotNil)
+
+	// Test where-conditions matching the functional indices.
+	tk.MustQuery(`SELECT a, b FROM test_fi WHERE a + b = 7`).Check(testkit.Rows(`3 4`))
+	tk.MustQuery(`SELECT a FROM test_fi WHERE lower(c) = 'xyz' ORDER BY a`).Check(testkit.Rows(`3`, `5`))
+	tk.MustQuery(`SELECT a FROM test_fi WHERE cast(d->'$.a' as signed) >= 2 ORDER BY a`).Check(testkit.Rows(`3`, `5`))
+	tk.MustQuery(`SELECT a FROM test_fi USE INDEX(ab) WHERE a + b > 10 ORDER BY a`).Check(testkit.Rows(`5`, `7`, `11`))
+
+	// Test update and delete keep the functional indices consistent.
+	tk.MustExec(`UPDATE test_fi SET a = 10 WHERE a + b = 7`)
+	tk.MustQuery(`SELECT a, b FROM test_fi WHERE a + b = 14`).Check(testkit.Rows(`10 4`))
+	tk.MustExec(`UPDATE test_fi SET c = 'ABC' WHERE lower(c) = 'xyz'`)
+	tk.MustQuery(`SELECT a FROM test_fi WHERE lower(c) = 'abc' ORDER BY a`).Check(testkit.Rows(`1`, `5`, `10`, `11`))
+	tk.MustExec(`ADMIN CHECK TABLE test_fi`)
+	tk.MustExec(`DELETE FROM test_fi WHERE a + b = 14`)
+	tk.MustQuery(`SELECT a FROM test_fi ORDER BY a`).Check(testkit.Rows(`1`, `5`, `7`, `11`))
+	tk.MustExec(`ADMIN CHECK TABLE test_fi`)
+
+	// Test create and drop index on a table with data.
+	tk.MustExec(`CREATE INDEX ab2 ON test_fi ((a * b))`)
+	tk.MustQuery(`SELECT a FROM test_fi WHERE a * b = 30`).Check(testkit.Rows(`5`))
+	tk.MustExec(`ADMIN CHECK TABLE test_fi`)
+	tk.MustExec(`DROP INDEX ab2 ON test_fi`)
+	tk.MustQuery(`SELECT a FROM test_fi WHERE a * b = 30`).Check(testkit.Rows(`5`))
+	tk.MustExec(`ADMIN CHECK TABLE test_fi`)
+
+	// Test the conditions on json paths matching the indexed virtual generated columns.
+	tk.MustExec(`CREATE TABLE test_fi_json(a json, b int as (a->'$.a'), c varchar(20) as (a->>'$.c'), index b(b), index c(c))`)
+	tk.MustExec(`INSERT INTO test_fi_json (a) VALUES ('{"a": 2, "c": "abc"}'), ('{"a": 2.4, "c": "x"}'), ('{"a": "2", "c": 3}')`)
+	tk.MustQuery(`SELECT a FROM test_fi_json WHERE a->'$.a' = 2`).Check(testkit.Rows(`{"a":2,"c":"abc"}`, `{"a":"2","c":3}`))
+	tk.MustQuery(`SELECT a FROM test_fi_json WHERE a->'$.c' = 'abc'`).Check(testkit.Rows(`{"a":2,"c":"abc"}`))
+	tk.MustQuery(`SELECT a FROM test_fi_json WHERE a->'$.c' = 3`).Check(testkit.Rows(`{"a":"2","c":3}`))
+	tk.MustQuery(`SELECT a FROM test_fi_json WHERE a->>'$.c' = '3'`).Check(testkit.Rows(`{"a":"2","c":3}`))
+	tk.MustQuery(`SELECT a FROM test_fi WHERE d->'$.a' = 3`).Check(testkit.Rows(`5`))
+}
+
 func (s *testSuite) TestToPBExpr(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -1975,6 +2163,85 @@ func (s *testSuite) TestSelectForUpdate(c *C) {
 
 }
 
+func (s *testSuite) TestPessimisticTxn(c *C) {
+	if !*mockTikv {
+		c.Skip("pessimistic transactions need the tikv store")
+	}
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use test")
+
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (k int primary key, v int)")
+	tk.MustExec("insert t values (1, 1), (2, 2)")
+	tk.Se.GetSessionVars().TxnMode = variable.TxnModePessimistic
+	tk.MustExec("set innodb_lock_wait_timeout = 1")
+	tk1.Se.GetSessionVars().TxnMode = variable.TxnModePessimistic
+	tk1.MustExec("set innodb_lock_wait_timeout = 1")
+
+	// The lock wait times out, and the rows not locked are not blocked.
+	tk.MustExec("begin")
+	tk.MustQuery("select * from t where k = 1 for update").Check(testkit.Rows("1 1"))
+	tk1.MustExec("begin")
+	_, err := tk1.Exec("update t set v = v + 1 where k = 1")
+	c.Assert(terror.ErrorEqual(err, kv.ErrLockWaitTimeout), IsTrue, Commentf("err %v", err))
+	tk1.MustExec("update t set v = v + 1 where k = 2")
+
+	// The waiting statement is woken up when the lock is released, and it updates the latest committed row.
+	tk.MustExec("update t set v = 100 where k = 1")
+	ch := make(chan error, 1)
+	go func() {
+		_, err1 := tk1.Exec("update t set v = v + 10 where k = 1")
+		ch <- err1
+	}()
+	time.Sleep(100 * time.Millisecond)
+	tk.MustExec("commit")
+	c.Assert(<-ch, IsNil)
+	tk1.MustQuery("select * from t").Check(testkit.Rows("1 110", "2 3"))
+	tk1.MustExec("commit")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 110", "2 3"))
+
+	// The transaction which finds the deadlock is rolled back.
+	tk.MustExec("begin")
+	tk1.MustExec("begin")
+	tk.MustExec("update t set v = 1 where k = 1")
+	tk1.MustExec("update t set v = 2 where k = 2")
+	go func() {
+		_, err1 := tk.Exec("update t set v = 1 where k = 2")
+		ch <- err1
+	}()
+	time.Sleep(100 * time.Millisecond)
+	_, err = tk1.Exec("update t set v = 2 where k = 1")
+	c.Assert(terror.ErrorEqual(err, kv.ErrDeadlock), IsTrue, Commentf("err %v", err))
+	c.Assert(<-ch, IsNil)
+	tk.MustExec("commit")
+	tk1.MustQuery("select * from t").Check(testkit.Rows("1 1", "2 1"))
+
+	// The statements which don't lock rows read at the start ts, even after a locking statement.
+	tk.MustExec("begin")
+	tk1.MustExec("insert t values (3, 3)")
+	tk.MustExec("update t set v = 10 where k = 1")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 10", "2 1"))
+	tk.MustQuery("select * from t where k = 3").Check(testkit.Rows())
+	tk.MustExec("commit")
+	tk.MustExec("delete from t where k = 3")
+
+	// An optimistic transaction doesn't lock the rows, it retries on conflict when it commits.
+	tk.Se.GetSessionVars().TxnMode = ""
+	tk.MustExec("begin")
+	tk.MustExec("update t set v = v + 3 where k = 1")
+	tk1.MustExec("begin")
+	tk1.MustExec("update t set v = v + 4 where k = 1")
+	tk1.MustExec("commit")
+	tk.MustExec("commit")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 17", "2 1"))
+}
+
 func (s *testSuite) TestEmptyEnum(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	defer func() {
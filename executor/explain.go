@@ -14,7 +14,14 @@
 package executor
 
 import (
+	"time"
+
+	"github.com/juju/errors"
 	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util/execdetails"
+	"github.com/pingcap/tidb/util/types"
+	goctx "golang.org/x/net/context"
 )
 
 // ExplainExec represents an explain executor.
@@ -23,6 +30,13 @@ type ExplainExec struct {
 
 	rows   []Row
 	cursor int
+
+	// explain, analyzeExec and runtimeStatsColl are only set for EXPLAIN ANALYZE, analyzeExec is the
+	// executor of the explained statement, it's executed before the rows are rendered. runtimeStatsColl
+	// is kept because the statement context may be reset after the executor is built, e.g. by EXECUTE.
+	explain          *plan.Explain
+	analyzeExec      Executor
+	runtimeStatsColl *execdetails.RuntimeStatsColl
 }
 
 // Schema implements the Executor Schema interface.
@@ -30,6 +44,18 @@ func (e *ExplainExec) Schema() *expression.Schema {
 	return e.schema
 }
 
+// Open implements the Executor Open interface.
+// For EXPLAIN ANALYZE, the explained statement is executed here, because the statement should be finished
+// before the transaction is committed, which may happen before Next is called.
+func (e *ExplainExec) Open() error {
+	if e.analyzeExec == nil {
+		return nil
+	}
+	err := e.executeAnalyze()
+	e.analyzeExec = nil
+	return errors.Trace(err)
+}
+
 // Next implements Execution Next interface.
 func (e *ExplainExec) Next() (Row, error) {
 	if e.cursor >= len(e.rows) {
@@ -43,5 +69,123 @@ func (e *ExplainExec) Next() (Row, error) {
 // Close implements the Executor Close interface.
 func (e *ExplainExec) Close() error {
 	e.rows = nil
+	if e.analyzeExec != nil {
+		err := e.analyzeExec.Close()
+		e.analyzeExec = nil
+		return errors.Trace(err)
+	}
 	return nil
 }
+
+// executeAnalyze executes the explained statement and renders the rows with the runtime statistics.
+func (e *ExplainExec) executeAnalyze() error {
+	exec := unwrapAnalyzeExec(e.analyzeExec)
+	if err := checkSnapshotWrite(e.ctx, exec); err != nil {
+		return errors.Trace(err)
+	}
+	if err := e.analyzeExec.Open(); err != nil {
+		return errors.Trace(err)
+	}
+	for {
+		row, err := e.analyzeExec.Next()
+		if err != nil {
+			e.analyzeExec.Close()
+			return errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+	}
+	if err := e.analyzeExec.Close(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := lockWrittenKeys(e.ctx, exec); err != nil {
+		return errors.Trace(err)
+	}
+	if err := e.explain.RenderResult(e.runtimeStatsColl); err != nil {
+		return errors.Trace(err)
+	}
+	e.rows = make([]Row, 0, len(e.explain.Rows))
+	for _, row := range e.explain.Rows {
+		e.rows = append(e.rows, row)
+	}
+	return nil
+}
+
+// memoryUsageReporter is implemented by the executors which can report their memory usage for EXPLAIN ANALYZE.
+type memoryUsageReporter interface {
+	// memoryUsage returns the bytes of the memory held by the executor currently.
+	memoryUsage() int64
+}
+
+// analyzeExec wraps an executor built for EXPLAIN ANALYZE, it collects the runtime statistics of the executor.
+type analyzeExec struct {
+	Executor
+
+	stats *execdetails.RuntimeStats
+}
+
+// newAnalyzeExec wraps e with the runtime statistics stats, the result implements DataReader if e does.
+func newAnalyzeExec(e Executor, stats *execdetails.RuntimeStats) Executor {
+	exec := &analyzeExec{Executor: e, stats: stats}
+	if _, ok := e.(DataReader); ok {
+		return &analyzeDataReader{exec}
+	}
+	return exec
+}
+
+// unwrapAnalyzeExec returns the executor wrapped by analyzeExec, or e itself if it's not wrapped.
+func unwrapAnalyzeExec(e Executor) Executor {
+	switch x := e.(type) {
+	case *analyzeExec:
+		return x.Executor
+	case *analyzeDataReader:
+		return x.Executor
+	}
+	return e
+}
+
+// Open implements the Executor Open interface.
+func (e *analyzeExec) Open() error {
+	start := time.Now()
+	err := e.Executor.Open()
+	e.stats.RecordOpen(time.Since(start))
+	return errors.Trace(err)
+}
+
+// Next implements the Executor Next interface.
+func (e *analyzeExec) Next() (Row, error) {
+	start := time.Now()
+	row, err := e.Executor.Next()
+	rowNum := 0
+	if row != nil {
+		rowNum = 1
+	}
+	e.stats.Record(time.Since(start), rowNum)
+	e.recordMemory()
+	return row, errors.Trace(err)
+}
+
+// Close implements the Executor Close interface.
+func (e *analyzeExec) Close() error {
+	e.recordMemory()
+	return errors.Trace(e.Executor.Close())
+}
+
+func (e *analyzeExec) recordMemory() {
+	if reporter, ok := e.Executor.(memoryUsageReporter); ok {
+		e.stats.RecordMemory(reporter.memoryUsage())
+	}
+}
+
+// analyzeDataReader is the analyzeExec of a DataReader, each request is counted as a loop.
+type analyzeDataReader struct {
+	*analyzeExec
+}
+
+func (e *analyzeDataReader) doRequestForDatums(datums [][]types.Datum, goCtx goctx.Context) error {
+	start := time.Now()
+	err := e.Executor.(DataReader).doRequestForDatums(datums, goCtx)
+	e.stats.RecordOpen(time.Since(start))
+	return errors.Trace(err)
+}
@@ -0,0 +1,103 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor_test
+
+import (
+	"strings"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/util/testkit"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func (s *testSuite) TestExplainAnalyze(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, s")
+	tk.MustExec("create table t (a int primary key, b int, index idx(b))")
+	tk.MustExec("insert into t values (1, 1), (2, 2), (3, 3), (4, 4), (5, 5)")
+	tk.MustExec("create table s (a int primary key, b int)")
+	tk.MustExec("insert into s values (1, 1), (2, 2)")
+
+	// explainInfo returns the execution info and the memory of the plan whose id starts with prefix.
+	explainInfo := func(rows [][]interface{}, prefix string) (string, string) {
+		for _, row := range rows {
+			c.Assert(row, HasLen, 8)
+			if strings.HasPrefix(row[0].(string), prefix) {
+				return row[6].(string), row[7].(string)
+			}
+		}
+		c.Fatalf("%s is not found in %v", prefix, rows)
+		return "", ""
+	}
+
+	rows := tk.MustQuery("explain analyze select * from t where b > 1").Rows()
+	c.Assert(rows, HasLen, 2)
+	info, memory := explainInfo(rows, "IndexReader")
+	c.Assert(info, Matches, "time:.*, loops:1, rows:4")
+	c.Assert(memory, Equals, "N/A")
+	info, _ = explainInfo(rows, "IndexScan")
+	c.Assert(info, Matches, `cop_task:\{num:1, max:.*, avg:.*, regions:\[.*@store1:.*\]\}`)
+
+	rows = tk.MustQuery("explain analyze select b, count(*) from t group by b order by b").Rows()
+	info, memory = explainInfo(rows, "Sort")
+	c.Assert(info, Matches, "time:.*, loops:1, rows:5")
+	c.Assert(memory, Not(Equals), "N/A")
+	info, memory = explainInfo(rows, "HashAgg_7")
+	c.Assert(info, Matches, "time:.*, loops:1, rows:5")
+	c.Assert(memory, Matches, ".*Bytes|.*KB")
+
+	rows = tk.MustQuery("explain analyze select * from t join s on t.b = s.b").Rows()
+	info, memory = explainInfo(rows, "HashLeftJoin")
+	c.Assert(info, Matches, "time:.*, loops:1, rows:2")
+	c.Assert(memory, Not(Equals), "N/A")
+
+	// The explained DML statements are executed.
+	rows = tk.MustQuery("explain analyze update t set b = b + 1 where a > 3").Rows()
+	info, _ = explainInfo(rows, "TableReader")
+	c.Assert(info, Matches, "time:.*, loops:1, rows:2")
+	tk.MustQuery("explain analyze delete from s where a = 1")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 1", "2 2", "3 3", "4 5", "5 6"))
+	tk.MustQuery("select * from s").Check(testkit.Rows("2 2"))
+
+	// The coprocessor tasks are sent to every region.
+	if s.cluster != nil {
+		tbl, err := sessionctx.GetDomain(tk.Se).InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+		c.Assert(err, IsNil)
+		s.cluster.SplitTable(s.mvccStore, tbl.Meta().ID, 3)
+		rows = tk.MustQuery("explain analyze select * from t").Rows()
+		info, _ = explainInfo(rows, "TableScan")
+		c.Assert(info, Matches, `cop_task:\{num:3, .*\}`)
+		info, _ = explainInfo(rows, "TableReader")
+		c.Assert(info, Matches, "time:.*, loops:1, rows:5")
+	}
+
+	// The runtime statistics are kept when the statement context is reset by EXECUTE.
+	tk.MustExec("prepare stmt from 'explain analyze select * from t where b > ?'")
+	tk.MustExec("set @b = 4")
+	rows = tk.MustQuery("execute stmt using @b").Rows()
+	info, _ = explainInfo(rows, "IndexReader")
+	c.Assert(info, Matches, "time:.*, loops:1, rows:2")
+
+	// EXPLAIN without ANALYZE doesn't execute the statement.
+	rows = tk.MustQuery("explain delete from t").Rows()
+	c.Assert(rows[0], HasLen, 6)
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("5"))
+}
@@ -26,7 +26,6 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/table"
-	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/sqlexec"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -45,6 +44,7 @@ type GrantExec struct {
 	ObjectType ast.ObjectTypeType
 	Level      *ast.GrantLevel
 	Users      []*ast.UserSpec
+	Require    ast.RequireType
 	WithGrant  bool
 
 	ctx  context.Context
@@ -74,21 +74,24 @@ func (e *GrantExec) Next() (Row, error) {
 			return nil, errors.Trace(err)
 		}
 		if !exists {
-			pwd := ""
-			if user.AuthOpt != nil {
-				if user.AuthOpt.ByAuthString {
-					pwd = auth.EncodePassword(user.AuthOpt.AuthString)
-				} else {
-					pwd = auth.EncodePassword(user.AuthOpt.HashString)
-				}
+			pwd, authString, plugin, err1 := authColumns(user.AuthOpt)
+			if err1 != nil {
+				return nil, errors.Trace(err1)
 			}
-
-			user := fmt.Sprintf(`("%s", "%s", "%s")`, user.User.Hostname, user.User.Username, pwd)
-			sql := fmt.Sprintf(`INSERT INTO %s.%s (Host, User, Password) VALUES %s;`, mysql.SystemDB, mysql.UserTable, user)
+			user := fmt.Sprintf(`("%s", "%s", "%s", "%s", "%s", "%s")`, user.User.Hostname, user.User.Username, pwd, authString, plugin, sslType(e.Require))
+			sql := fmt.Sprintf(`INSERT INTO %s.%s (Host, User, Password, authentication_string, plugin, ssl_type) VALUES %s;`,
+				mysql.SystemDB, mysql.UserTable, user)
 			_, err := e.ctx.(sqlexec.SQLExecutor).Execute(sql)
 			if err != nil {
 				return nil, errors.Trace(err)
 			}
+		} else if e.Require != ast.RequireUnspecified {
+			sql := fmt.Sprintf(`UPDATE %s.%s SET ssl_type = "%s" WHERE Host = "%s" and User = "%s";`,
+				mysql.SystemDB, mysql.UserTable, sslType(e.Require), user.User.Hostname, user.User.Username)
+			_, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
 		}
 
 		// If there is no privilege entry in corresponding table, insert a new one.
@@ -65,6 +65,8 @@ type HashJoinExec struct {
 
 	// Channels for output.
 	resultCh chan *execResult
+	// hashTableMemUsage is the memory usage of the hash table, it's set once the hash table is built.
+	hashTableMemUsage int64
 }
 
 // hashJoinCtx holds the variables needed to do a hash join in one of many concurrent goroutines.
@@ -241,6 +243,7 @@ func (e *HashJoinExec) prepare() error {
 		}
 		e.hashTable.Put(joinKey, buffer)
 	}
+	e.hashTableMemUsage = e.hashTable.MemoryUsage()
 
 	e.resultCh = make(chan *execResult, e.concurrency)
 
@@ -254,6 +257,11 @@ func (e *HashJoinExec) prepare() error {
 	return nil
 }
 
+// memoryUsage implements the memoryUsageReporter interface.
+func (e *HashJoinExec) memoryUsage() int64 {
+	return e.hashTableMemUsage
+}
+
 func (e *HashJoinExec) encodeRow(b []byte, row Row) ([]byte, error) {
 	loc := e.ctx.GetSessionVars().GetTimeZone()
 	for _, datum := range row {
@@ -98,6 +98,48 @@ func (s *testSuite) TestJoinPanic(c *C) {
 	tk.MustQuery("SELECT * FROM events e JOIN (SELECT MAX(clock) AS clock FROM events e2 GROUP BY e2.source) e3 ON e3.clock=e.clock")
 }
 
+func (s *testSuite) TestCrossJoin(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1 (a int, b int)")
+	tk.MustExec("create table t2 (a int, b int)")
+	tk.MustExec("insert into t1 values (1, 1), (2, 2), (3, 3)")
+	tk.MustExec("insert into t2 values (1, 10), (3, 30)")
+
+	result := tk.MustQuery("select * from t1, t2 order by t1.a, t2.a")
+	result.Check(testkit.Rows("1 1 1 10", "1 1 3 30", "2 2 1 10", "2 2 3 30", "3 3 1 10", "3 3 3 30"))
+	result = tk.MustQuery("select * from t1 join t2 on t1.a > t2.a order by t1.a, t2.a")
+	result.Check(testkit.Rows("2 2 1 10", "3 3 1 10"))
+	result = tk.MustQuery("select * from t1 left join t2 on t1.a < t2.a and t2.b > 10 order by t1.a, t2.a")
+	result.Check(testkit.Rows("1 1 3 30", "2 2 3 30", "3 3 <nil> <nil>"))
+	result = tk.MustQuery("select * from t1 right join t2 on t1.a > t2.a and t1.b < 3 order by t2.a, t1.a")
+	result.Check(testkit.Rows("2 2 1 10", "<nil> <nil> 3 30"))
+	result = tk.MustQuery("select * from t1 left join t2 on t1.a > 1 order by t1.a, t2.a")
+	result.Check(testkit.Rows("1 1 <nil> <nil>", "2 2 1 10", "2 2 3 30", "3 3 1 10", "3 3 3 30"))
+
+	// The outer rows span several blocks.
+	for i := 0; i < 300; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t1 values (%d, %d)", i+10, i+10))
+	}
+	result = tk.MustQuery("select count(*), sum(t1.a) from t1, t2 where t1.a > t2.a")
+	result.Check(testkit.Rows("602 95705"))
+	result = tk.MustQuery("select t1.a, t2.a from t1 left join t2 on t1.a < t2.a order by t1.a limit 3")
+	result.Check(testkit.Rows("1 3", "2 3", "3 <nil>"))
+
+	// The estimated row count of the cartesian product is limited by tidb_max_cartesian_product_rows.
+	tk.MustExec("set @@tidb_max_cartesian_product_rows = 100")
+	_, err := tk.Exec("select * from t1, t2")
+	c.Assert(plan.ErrCartesianProductRowsExceeded.Equal(err), IsTrue, Commentf("err %v", err))
+	tk.MustQuery("select * from t1 join t2 on t1.a = t2.a order by t1.a").Check(testkit.Rows("1 1 1 10", "3 3 3 30"))
+	tk.MustExec("set @@tidb_max_cartesian_product_rows = 0")
+	tk.MustQuery("select count(*) from t1, t2").Check(testkit.Rows("606"))
+}
+
 func (s *testSuite) TestJoin(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -179,14 +221,14 @@ func (s *testSuite) TestJoin(c *C) {
 	result = tk.MustQuery("select a.c1 from t a , (select * from t1 limit 3) b where a.c1 = b.c1 order by b.c1;")
 	result.Check(testkit.Rows("1", "2", "3"))
 
-	plan.AllowCartesianProduct = false
+	tk.MustExec("set @@tidb_max_cartesian_product_rows = 1")
 	_, err := tk.Exec("select * from t, t1")
-	c.Check(plan.ErrCartesianProductUnsupported.Equal(err), IsTrue)
+	c.Check(plan.ErrCartesianProductRowsExceeded.Equal(err), IsTrue)
 	_, err = tk.Exec("select * from t left join t1 on 1")
-	c.Check(plan.ErrCartesianProductUnsupported.Equal(err), IsTrue)
+	c.Check(plan.ErrCartesianProductRowsExceeded.Equal(err), IsTrue)
 	_, err = tk.Exec("select * from t right join t1 on 1")
-	c.Check(plan.ErrCartesianProductUnsupported.Equal(err), IsTrue)
-	plan.AllowCartesianProduct = true
+	c.Check(plan.ErrCartesianProductRowsExceeded.Equal(err), IsTrue)
+	tk.MustExec("set @@tidb_max_cartesian_product_rows = 0")
 	tk.MustExec("drop table if exists t,t2,t1")
 	tk.MustExec("create table t(c1 int)")
 	tk.MustExec("create table t1(c1 int, c2 int)")
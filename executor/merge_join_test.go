@@ -294,14 +294,14 @@ func (s *testSuite) TestMergeJoin(c *C) {
 	result = tk.MustQuery("select /*+ TIDB_SMJ(a, b) */ a.c1 from t a , (select * from t1 limit 3) b where a.c1 = b.c1 order by b.c1;")
 	result.Check(testkit.Rows("1", "2", "3"))
 
-	plan.AllowCartesianProduct = false
+	tk.MustExec("set @@tidb_max_cartesian_product_rows = 1")
 	_, err := tk.Exec("select /*+ TIDB_SMJ(t,t1) */ * from t, t1")
-	c.Check(plan.ErrCartesianProductUnsupported.Equal(err), IsTrue)
+	c.Check(plan.ErrCartesianProductRowsExceeded.Equal(err), IsTrue)
 	_, err = tk.Exec("select /*+ TIDB_SMJ(t,t1) */ * from t left join t1 on 1")
-	c.Check(plan.ErrCartesianProductUnsupported.Equal(err), IsTrue)
+	c.Check(plan.ErrCartesianProductRowsExceeded.Equal(err), IsTrue)
 	_, err = tk.Exec("select /*+ TIDB_SMJ(t,t1) */ * from t right join t1 on 1")
-	c.Check(plan.ErrCartesianProductUnsupported.Equal(err), IsTrue)
-	plan.AllowCartesianProduct = true
+	c.Check(plan.ErrCartesianProductRowsExceeded.Equal(err), IsTrue)
+	tk.MustExec("set @@tidb_max_cartesian_product_rows = 0")
 	tk.MustExec("drop table if exists t")
 	tk.MustExec("drop table if exists t1")
 	tk.MustExec("create table t(c1 int)")
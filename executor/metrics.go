@@ -40,11 +40,27 @@ var (
 			Name:      "expensive_query_total",
 			Help:      "Counter of expensive query.",
 		}, []string{"type"})
+	planCacheCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "executor",
+			Name:      "plan_cache_total",
+			Help:      "Counter of the prepared plan cache hits and misses.",
+		}, []string{"type"})
+	sortSpillCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "executor",
+			Name:      "sort_spill_total",
+			Help:      "Counter of the sort operators spilled to disk.",
+		})
 )
 
 func init() {
 	prometheus.MustRegister(stmtNodeCounter)
 	prometheus.MustRegister(expensiveQueryCounter)
+	prometheus.MustRegister(planCacheCounter)
+	prometheus.MustRegister(sortSpillCounter)
 }
 
 func stmtCount(node ast.StmtNode, p plan.Plan, inRestrictedSQL bool) bool {
@@ -77,6 +93,8 @@ const (
 	CreateTable = "CreateTable"
 	// CreateUser represents create user statements.
 	CreateUser = "CreateUser"
+	// CreateView represents create view statements.
+	CreateView = "CreateView"
 	// Delete represents delete statements.
 	Delete = "Delete"
 	// DropDatabase represents drop database statements.
@@ -85,6 +103,8 @@ const (
 	DropIndex = "DropIndex"
 	// DropTable represents drop table statements.
 	DropTable = "DropTable"
+	// DropView represents drop view statements.
+	DropView = "DropView"
 	// Explain represents explain statements.
 	Explain = "Explain"
 	// Replace represents replace statements.
@@ -128,6 +148,8 @@ func StatementLabel(node ast.StmtNode, p plan.Plan, isExpensive *bool) string {
 		return CreateTable
 	case *ast.CreateUserStmt:
 		return CreateUser
+	case *ast.CreateViewStmt:
+		return CreateView
 	case *ast.DeleteStmt:
 		return getDeleteStmtLabel(x, p, isExpensive)
 	case *ast.DropDatabaseStmt:
@@ -135,6 +157,9 @@ func StatementLabel(node ast.StmtNode, p plan.Plan, isExpensive *bool) string {
 	case *ast.DropIndexStmt:
 		return DropIndex
 	case *ast.DropTableStmt:
+		if x.IsView {
+			return DropView
+		}
 		return DropTable
 	case *ast.ExplainStmt:
 		return Explain
@@ -242,6 +267,8 @@ func (pa *stmtAttributes) fromPlan(p plan.Plan) {
 		pa.hasAggregate = true
 	case *plan.PhysicalHashJoin:
 		pa.hasJoin = true
+	case *plan.PhysicalCrossJoin:
+		pa.hasJoin = true
 	case *plan.PhysicalTableScan:
 		pa.hasTableScan = true
 		if len(x.AccessCondition) > 0 {
@@ -272,6 +299,16 @@ func (pa *stmtAttributes) fromPlan(p plan.Plan) {
 			pa.fromPlan(child)
 		}
 		pa.hasIndexDouble = true
+	case *plan.PhysicalIndexMergeReader:
+		for _, partialPlans := range x.PartialPlans {
+			for _, child := range partialPlans {
+				pa.fromPlan(child)
+			}
+		}
+		for _, child := range x.TablePlans {
+			pa.fromPlan(child)
+		}
+		pa.hasIndexDouble = true
 	}
 	children := p.Children()
 	for _, child := range children {
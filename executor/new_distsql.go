@@ -15,15 +15,19 @@ package executor
 
 import (
 	"sort"
+	"sync"
 	"sync/atomic"
 
 	"github.com/juju/errors"
+	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/distsql"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/execdetails"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
 	goctx "golang.org/x/net/context"
@@ -33,6 +37,7 @@ var (
 	_ Executor = &TableReaderExecutor{}
 	_ Executor = &IndexReaderExecutor{}
 	_ Executor = &IndexLookUpExecutor{}
+	_ Executor = &IndexMergeReaderExecutor{}
 )
 
 // DataReader can send requests which ranges are constructed by datums.
@@ -69,6 +74,8 @@ type TableReaderExecutor struct {
 	result        distsql.SelectResult
 	partialResult distsql.PartialResult
 	priority      int
+	// copStats collects the runtime statistics of the coprocessor tasks for EXPLAIN ANALYZE.
+	copStats *execdetails.CopRuntimeStats
 }
 
 // Schema implements the Executor Schema interface.
@@ -132,7 +139,7 @@ func (e *TableReaderExecutor) Next() (Row, error) {
 func (e *TableReaderExecutor) Open() error {
 	kvRanges := tableRangesToKVRanges(e.tableID, e.ranges)
 	var err error
-	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), goctx.Background(), e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc, getIsolationLevel(e.ctx.GetSessionVars()), e.priority)
+	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), goctx.Background(), e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc, getIsolationLevel(e.ctx.GetSessionVars()), e.priority, e.copStats)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -145,7 +152,7 @@ func (e *TableReaderExecutor) doRequestForHandles(handles []int64, goCtx goctx.C
 	sort.Sort(int64Slice(handles))
 	kvRanges := tableHandlesToKVRanges(e.tableID, handles)
 	var err error
-	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), goCtx, e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc, getIsolationLevel(e.ctx.GetSessionVars()), e.priority)
+	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), goCtx, e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc, getIsolationLevel(e.ctx.GetSessionVars()), e.priority, e.copStats)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -183,6 +190,8 @@ type IndexReaderExecutor struct {
 	// columns are only required by union scan.
 	columns  []*model.ColumnInfo
 	priority int
+	// copStats collects the runtime statistics of the coprocessor tasks for EXPLAIN ANALYZE.
+	copStats *execdetails.CopRuntimeStats
 }
 
 // Schema implements the Executor Schema interface.
@@ -252,7 +261,7 @@ func (e *IndexReaderExecutor) Open() error {
 	if err != nil {
 		return errors.Trace(err)
 	}
-	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), e.ctx.GoCtx(), e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc, getIsolationLevel(e.ctx.GetSessionVars()), e.priority)
+	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), e.ctx.GoCtx(), e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc, getIsolationLevel(e.ctx.GetSessionVars()), e.priority, e.copStats)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -266,7 +275,7 @@ func (e *IndexReaderExecutor) doRequestForDatums(values [][]types.Datum, goCtx g
 	if err != nil {
 		return errors.Trace(err)
 	}
-	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), e.ctx.GoCtx(), e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc, getIsolationLevel(e.ctx.GetSessionVars()), e.priority)
+	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), e.ctx.GoCtx(), e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc, getIsolationLevel(e.ctx.GetSessionVars()), e.priority, e.copStats)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -300,6 +309,10 @@ type IndexLookUpExecutor struct {
 	columns  []*model.ColumnInfo
 	priority int
 	finished chan struct{}
+	// copStats and tableCopStats collect the runtime statistics of the coprocessor tasks
+	// of the index requests and the table requests for EXPLAIN ANALYZE.
+	copStats      *execdetails.CopRuntimeStats
+	tableCopStats *execdetails.CopRuntimeStats
 }
 
 // Open implements the Executor Open interface.
@@ -313,7 +326,7 @@ func (e *IndexLookUpExecutor) Open() error {
 	if err != nil {
 		return errors.Trace(err)
 	}
-	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), e.ctx.GoCtx(), e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc, getIsolationLevel(e.ctx.GetSessionVars()), e.priority)
+	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), e.ctx.GoCtx(), e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc, getIsolationLevel(e.ctx.GetSessionVars()), e.priority, e.copStats)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -334,7 +347,7 @@ func (e *IndexLookUpExecutor) doRequestForDatums(values [][]types.Datum, goCtx g
 	if err != nil {
 		return errors.Trace(err)
 	}
-	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), e.ctx.GoCtx(), e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc, getIsolationLevel(e.ctx.GetSessionVars()), e.priority)
+	e.result, err = distsql.SelectDAG(e.ctx.GetClient(), e.ctx.GoCtx(), e.dagPB, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, e.desc, getIsolationLevel(e.ctx.GetSessionVars()), e.priority, e.copStats)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -371,6 +384,7 @@ func (e *IndexLookUpExecutor) executeTask(task *lookupTableTask, goCtx goctx.Con
 		schema:    schema,
 		ctx:       e.ctx,
 		handleCol: handleCol,
+		copStats:  e.tableCopStats,
 	}
 	err = tableReader.doRequestForHandles(task.handles, goCtx)
 	if err != nil {
@@ -527,3 +541,299 @@ func (e *IndexLookUpExecutor) Next() (Row, error) {
 		e.taskCurr = nil
 	}
 }
+
+// indexMergeHandleMemUsage is the estimated memory usage in bytes of a handle held by an index merge reader.
+const indexMergeHandleMemUsage = 16
+
+// IndexMergeReaderExecutor reads the handles by several index scans concurrently, merges them by union or
+// intersection, and then looks up the rows of the merged handles in the table.
+type IndexMergeReaderExecutor struct {
+	table   table.Table
+	tableID int64
+	indexes []*model.IndexInfo
+	ranges  [][]*types.IndexRange
+	dagPBs  []*tipb.DAGRequest
+	// intersection indicates that only the handles read by all the index scans are looked up.
+	intersection bool
+	ctx          context.Context
+	schema       *expression.Schema
+	// This is the column that represent the handle, we can use handleCol.Index to know its position.
+	handleCol *expression.Column
+
+	// results are the results of the index scans.
+	results []distsql.SelectResult
+
+	taskChan chan *lookupTableTask
+	tasksErr error
+	taskCurr *lookupTableTask
+
+	tableRequest *tipb.DAGRequest
+	priority     int
+	finished     chan struct{}
+	// copStats and tableCopStats collect the runtime statistics of the coprocessor tasks
+	// of the index requests and the table requests for EXPLAIN ANALYZE.
+	copStats      []*execdetails.CopRuntimeStats
+	tableCopStats *execdetails.CopRuntimeStats
+}
+
+// Schema implements the Executor Schema interface.
+func (e *IndexMergeReaderExecutor) Schema() *expression.Schema {
+	return e.schema
+}
+
+// Open implements the Executor Open interface.
+func (e *IndexMergeReaderExecutor) Open() error {
+	e.results = make([]distsql.SelectResult, 0, len(e.indexes))
+	for i := range e.indexes {
+		result, err := e.doPartialRequest(i)
+		if err != nil {
+			if closeErr := e.closeResults(); closeErr != nil {
+				log.Error(errors.ErrorStack(closeErr))
+			}
+			return errors.Trace(err)
+		}
+		e.results = append(e.results, result)
+	}
+	e.finished = make(chan struct{})
+	e.taskChan = make(chan *lookupTableTask, atomic.LoadInt32(&LookupTableTaskChannelSize))
+	go e.fetchHandlesAndStartWorkers()
+	return nil
+}
+
+// doPartialRequest sends the request of the i-th index scan.
+func (e *IndexMergeReaderExecutor) doPartialRequest(i int) (distsql.SelectResult, error) {
+	index := e.indexes[i]
+	fieldTypes := make([]*types.FieldType, len(index.Columns))
+	for j, v := range index.Columns {
+		fieldTypes[j] = &(e.table.Cols()[v.Offset].FieldType)
+	}
+	kvRanges, err := indexRangesToKVRanges(e.ctx.GetSessionVars().StmtCtx, e.tableID, index.ID, e.ranges[i], fieldTypes)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var copStats *execdetails.CopRuntimeStats
+	if e.copStats != nil {
+		copStats = e.copStats[i]
+	}
+	result, err := distsql.SelectDAG(e.ctx.GetClient(), e.ctx.GoCtx(), e.dagPBs[i], kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, false, false, getIsolationLevel(e.ctx.GetSessionVars()), e.priority, copStats)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result.Fetch(e.ctx.GoCtx())
+	return result, nil
+}
+
+// fetchHandlesAndStartWorkers merges the handles read by the index scans and builds the table lookup tasks.
+// For union, the handles which are not read before are put into the tasks as soon as they are read, so the
+// table lookup runs concurrently with the index scans. For intersection, the tasks are built after all the
+// index scans finish. The handles held for the merge are limited by tidb_mem_quota_index_merge.
+func (e *IndexMergeReaderExecutor) fetchHandlesAndStartWorkers() {
+	workCh := make(chan *lookupTableTask, 1)
+	// stopCh stops the index scans when the merge is aborted.
+	stopCh := make(chan struct{})
+	handleCh := make(chan []int64, len(e.results))
+	errs := make([]error, len(e.results))
+	var wg sync.WaitGroup
+	defer func() {
+		close(stopCh)
+		// Wait for the index scans, so the results are not used any more after e.taskChan is closed.
+		wg.Wait()
+		close(workCh)
+		close(e.taskChan)
+	}()
+
+	txnCtx := e.ctx.GoCtx()
+	for i := 0; i < e.ctx.GetSessionVars().IndexLookupConcurrency; i++ {
+		go e.pickAndExecTask(workCh, txnCtx)
+	}
+	for i, result := range e.results {
+		wg.Add(1)
+		go func(i int, result distsql.SelectResult) {
+			defer wg.Done()
+			errs[i] = e.fetchPartialHandles(result, handleCh, stopCh)
+		}(i, result)
+	}
+	go func() {
+		wg.Wait()
+		close(handleCh)
+	}()
+
+	memQuota := e.ctx.GetSessionVars().MemQuotaIndexMerge
+	batchSize := e.ctx.GetSessionVars().IndexLookupSize
+	// The handles read by an index scan are distinct, so a handle read by all the index scans is counted
+	// len(e.results) times.
+	counts := make(map[int64]int)
+	var batch []int64
+	for handles := range handleCh {
+		for _, h := range handles {
+			counts[h]++
+			if !e.intersection && counts[h] == 1 {
+				batch = append(batch, h)
+			}
+		}
+		if int64(len(counts))*indexMergeHandleMemUsage > memQuota {
+			e.tasksErr = ErrMemQuotaExceeded.GenByArgs("index merge reader", variable.TiDBMemQuotaIndexMerge, memQuota)
+			return
+		}
+		for len(batch) >= batchSize {
+			if !e.sendTask(batch[:batchSize], workCh, txnCtx) {
+				return
+			}
+			batch = batch[batchSize:]
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			e.tasksErr = errors.Trace(err)
+			return
+		}
+	}
+	if e.intersection {
+		for h, cnt := range counts {
+			if cnt == len(e.results) {
+				batch = append(batch, h)
+			}
+		}
+	}
+	for len(batch) > 0 {
+		size := batchSize
+		if size > len(batch) {
+			size = len(batch)
+		}
+		if !e.sendTask(batch[:size], workCh, txnCtx) {
+			return
+		}
+		batch = batch[size:]
+	}
+}
+
+// fetchPartialHandles reads the handles by an index scan and sends them to handleCh batch by batch.
+func (e *IndexMergeReaderExecutor) fetchPartialHandles(result distsql.SelectResult, handleCh chan<- []int64, stopCh <-chan struct{}) error {
+	for {
+		handles, finish, err := extractHandlesFromIndexResult(result)
+		if err != nil || finish {
+			return errors.Trace(err)
+		}
+		select {
+		case <-stopCh:
+			return nil
+		case <-e.finished:
+			return nil
+		case handleCh <- handles:
+		}
+	}
+}
+
+// sendTask builds a table lookup task of the handles and sends it to the workers, it returns false if the
+// executor is closed.
+func (e *IndexMergeReaderExecutor) sendTask(handles []int64, workCh chan<- *lookupTableTask, txnCtx goctx.Context) bool {
+	task := &lookupTableTask{
+		handles: handles,
+		doneCh:  make(chan error, 1),
+	}
+	select {
+	case <-txnCtx.Done():
+		return false
+	case <-e.finished:
+		return false
+	case workCh <- task:
+	}
+	e.taskChan <- task
+	return true
+}
+
+func (e *IndexMergeReaderExecutor) pickAndExecTask(workCh <-chan *lookupTableTask, txnCtx goctx.Context) {
+	childCtx, cancel := goctx.WithCancel(txnCtx)
+	defer cancel()
+	for {
+		select {
+		case task := <-workCh:
+			if task == nil {
+				return
+			}
+			e.executeTask(task, childCtx)
+		case <-childCtx.Done():
+			return
+		case <-e.finished:
+			return
+		}
+	}
+}
+
+// executeTask looks up the rows of the handles of the task in the table.
+func (e *IndexMergeReaderExecutor) executeTask(task *lookupTableTask, goCtx goctx.Context) {
+	var err error
+	defer func() {
+		task.doneCh <- errors.Trace(err)
+	}()
+	tableReader := &TableReaderExecutor{
+		table:     e.table,
+		tableID:   e.tableID,
+		dagPB:     e.tableRequest,
+		schema:    e.schema,
+		ctx:       e.ctx,
+		handleCol: e.handleCol,
+		priority:  e.priority,
+		copStats:  e.tableCopStats,
+	}
+	err = tableReader.doRequestForHandles(task.handles, goCtx)
+	if err != nil {
+		return
+	}
+	for {
+		var row Row
+		row, err = tableReader.Next()
+		if err != nil || row == nil {
+			break
+		}
+		task.rows = append(task.rows, row)
+	}
+	if closeErr := tableReader.Close(); err == nil {
+		err = closeErr
+	}
+}
+
+// Next implements the Executor Next interface.
+func (e *IndexMergeReaderExecutor) Next() (Row, error) {
+	for {
+		if e.taskCurr == nil {
+			taskCurr, ok := <-e.taskChan
+			if !ok {
+				return nil, e.tasksErr
+			}
+			e.taskCurr = taskCurr
+		}
+		row, err := e.taskCurr.getRow()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row != nil {
+			return row, nil
+		}
+		e.taskCurr = nil
+	}
+}
+
+// Close implements the Executor Close interface.
+func (e *IndexMergeReaderExecutor) Close() error {
+	// If this executor is closed once, we should not close it second time.
+	if e.taskChan == nil {
+		return nil
+	}
+	close(e.finished)
+	// Consume the task channel in case channel is full.
+	for range e.taskChan {
+	}
+	e.taskChan = nil
+	e.taskCurr = nil
+	return errors.Trace(e.closeResults())
+}
+
+func (e *IndexMergeReaderExecutor) closeResults() error {
+	objs := make([]Closeable, 0, len(e.results))
+	for _, result := range e.results {
+		objs = append(objs, result)
+	}
+	e.results = nil
+	return errors.Trace(closeAll(objs...))
+}
@@ -0,0 +1,174 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor_test
+
+import (
+	"fmt"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ddl"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testkit"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func (s *testSuite) TestRangePartition(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec(`create table t (a int, b varchar(10)) partition by range (a) (
+		partition p0 values less than (10),
+		partition p1 values less than (20),
+		partition p2 values less than maxvalue)`)
+	tk.MustExec("insert into t values (1, 'a'), (11, 'b'), (21, 'c'), (null, 'd')")
+	tk.MustQuery("select * from t order by b").Check(testkit.Rows("1 a", "11 b", "21 c", "<nil> d"))
+	tk.MustQuery("select * from t where a < 10").Check(testkit.Rows("1 a"))
+	tk.MustQuery("select * from t where a is null").Check(testkit.Rows("<nil> d"))
+	tk.MustQuery("select b from t where a >= 11 and a < 21").Check(testkit.Rows("b"))
+	tk.MustQuery("select count(*) from t where a > 100").Check(testkit.Rows("0"))
+
+	// The row is moved to another partition.
+	tk.MustExec("update t set a = 15 where b = 'a'")
+	tk.MustQuery("select * from t where a < 20 order by b").Check(testkit.Rows("15 a", "11 b"))
+	tk.MustExec("delete from t where a = 21")
+	tk.MustQuery("select b from t order by b").Check(testkit.Rows("a", "b", "d"))
+	tk.MustExec("admin check table t")
+
+	// The uncommitted rows are read from the right partition.
+	tk.MustExec("begin")
+	tk.MustExec("insert into t values (2, 'e'), (30, 'f')")
+	tk.MustQuery("select b from t where a < 10").Check(testkit.Rows("e"))
+	tk.MustQuery("select b from t order by b").Check(testkit.Rows("a", "b", "d", "e", "f"))
+	tk.MustExec("rollback")
+
+	tk.MustExec("alter table t truncate partition p1")
+	tk.MustQuery("select b from t order by b").Check(testkit.Rows("d"))
+	tk.MustExec("alter table t drop partition p2")
+	_, err := tk.Exec("insert into t values (30, 'g')")
+	c.Assert(terror.ErrorEqual(err, table.ErrNoPartitionForGivenValue), IsTrue, Commentf("err %v", err))
+	tk.MustExec("alter table t add partition (partition p3 values less than (40))")
+	tk.MustExec("insert into t values (30, 'g')")
+	tk.MustQuery("select b from t where a = 30").Check(testkit.Rows("g"))
+	tk.MustQuery("show create table t").Check(testkit.Rows("t CREATE TABLE `t` (\n" +
+		"  `a` int(11) DEFAULT NULL,\n" +
+		"  `b` varchar(10) DEFAULT NULL\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin\n" +
+		"PARTITION BY RANGE ( a ) (\n" +
+		"  PARTITION `p0` VALUES LESS THAN (10),\n" +
+		"  PARTITION `p1` VALUES LESS THAN (20),\n" +
+		"  PARTITION `p3` VALUES LESS THAN (40)\n" +
+		")"))
+
+	tk.MustExec("truncate table t")
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("0"))
+	tk.MustExec("drop table t")
+}
+
+func (s *testSuite) TestHashAndListPartition(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b int) partition by hash (a) partitions 4")
+	tk.MustExec("insert into t values (1, 1), (2, 2), (-3, 3), (8, 4)")
+	tk.MustQuery("select * from t order by a").Check(testkit.Rows("-3 3", "1 1", "2 2", "8 4"))
+	tk.MustQuery("select b from t where a = -3").Check(testkit.Rows("3"))
+	tk.MustQuery("select b from t where a > 0 and a < 5 order by b").Check(testkit.Rows("1", "2"))
+	_, err := tk.Exec("insert into t values (5, 5), (1, 6)")
+	c.Assert(err, NotNil)
+	tk.MustExec("update t set a = 7 where a = 8")
+	tk.MustQuery("select * from t where a = 7").Check(testkit.Rows("7 4"))
+	_, err = tk.Exec("alter table t drop partition p0")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrOnlyOnRangeListPartition), IsTrue, Commentf("err %v", err))
+
+	tk.MustExec("drop table t")
+	tk.MustExec(`create table t (a int, b int, key idx (b)) partition by list (a + 1) (
+		partition p0 values in (1, 3, null),
+		partition p1 values in (2, 4))`)
+	tk.MustExec("insert into t values (0, 1), (1, 2), (2, 3), (null, 4)")
+	tk.MustQuery("select a from t where b > 1 order by b").Check(testkit.Rows("1", "2", "<nil>"))
+	_, err = tk.Exec("insert into t values (5, 5)")
+	c.Assert(terror.ErrorEqual(err, table.ErrNoPartitionForGivenValue), IsTrue, Commentf("err %v", err))
+	tk.MustExec("admin check table t")
+	tk.MustExec("drop table t")
+
+	// The partition definitions are checked.
+	_, err = tk.Exec("create table t (a int) partition by range (a) (partition p0 values less than (10), partition p1 values less than (5))")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrRangeNotIncreasing), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("create table t (a int) partition by range (a) (partition p0 values less than (10), partition p0 values less than (20))")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrSameNamePartition), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("create table t (a int) partition by list (a) (partition p0 values in (1), partition p1 values in (1))")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrMultipleDefConstInListPart), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("create table t (a int, b int, unique key (b)) partition by hash (a) partitions 2")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrUniqueKeyNeedAllFieldsInPf), IsTrue, Commentf("err %v", err))
+	tk.MustExec("create table t (a int)")
+	_, err = tk.Exec("alter table t drop partition p0")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrPartitionMgmtOnNonpartitioned), IsTrue, Commentf("err %v", err))
+}
+
+func (s *testSuite) TestPartitionIndexAndMonotonicPruning(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec(`create table t (a int, b int) partition by range (a) (
+		partition p0 values less than (10),
+		partition p1 values less than (20),
+		partition p2 values less than maxvalue)`)
+	tk.MustExec("insert into t values (1, 1), (11, 2), (21, 3), (2, 4)")
+
+	// The existing rows of every partition are backfilled.
+	tk.MustExec("alter table t add index idx_b (b)")
+	tk.MustExec("admin check table t")
+	tk.MustQuery("select a from t use index (idx_b) where b > 1 order by b").Check(testkit.Rows("11", "21", "2"))
+	tk.MustExec("alter table t add unique index idx_ab (a, b)")
+	tk.MustExec("admin check table t")
+	_, err := tk.Exec("insert into t values (11, 2)")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("alter table t add unique index idx_u (b)")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrUniqueKeyNeedAllFieldsInPf), IsTrue, Commentf("err %v", err))
+	tk.MustExec("alter table t drop index idx_b")
+	tk.MustExec("alter table t drop index idx_ab")
+	tk.MustExec("admin check table t")
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("4"))
+
+	tk.MustExec("drop table t")
+	tk.MustExec(`create table t (d date, b int) partition by range (year(d)) (
+		partition p0 values less than (2000),
+		partition p1 values less than (2010),
+		partition p2 values less than maxvalue)`)
+	tk.MustExec("insert into t values ('1999-12-31', 1), ('2000-01-01', 2), ('2009-06-01', 3), ('2018-01-01', 4)")
+	tk.MustQuery("select b from t where d < '2000-01-01'").Check(testkit.Rows("1"))
+	tk.MustQuery("select b from t where d >= '2000-01-01' and d < '2010-01-01' order by b").Check(testkit.Rows("2", "3"))
+	tk.MustQuery("select b from t where d > '2017-01-01'").Check(testkit.Rows("4"))
+	// Only p0 is read, so the plan doesn't union the partitions.
+	tk.MustQuery("explain select b from t where d < '1999-01-01'").Check(testkit.Rows(
+		"TableScan_4 Selection_5  cop table:t, range:(-inf,+inf), keep order:false 3333.333333333333",
+		"Selection_5  TableScan_4 cop lt(test.t.d, 1999-01-01 00:00:00.000000) 3333.333333333333",
+		"TableReader_6 Projection_3  root data:Selection_5 3333.333333333333",
+		"Projection_3  TableReader_6 root test.t.b 3333.333333333333"))
+	rows := tk.MustQuery("explain select b from t where d < '2005-01-01'").Rows()
+	c.Assert(fmt.Sprintf("%v", rows), Matches, ".*Union.*")
+}
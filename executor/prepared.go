@@ -26,7 +26,9 @@ import (
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/kvcache"
 	"github.com/pingcap/tidb/util/sqlexec"
+	"github.com/pingcap/tidb/util/types"
 )
 
 var (
@@ -71,6 +73,10 @@ type Prepared struct {
 	Stmt          ast.StmtNode
 	Params        []*ast.ParamMarkerExpr
 	SchemaVersion int64
+	// UseCache means whether the plan of the statement can be cached.
+	UseCache bool
+	// cacheKeys are the keys of the plans of the statement put into the plan cache, indexed by their hashes.
+	cacheKeys map[string]kvcache.Key
 }
 
 // PrepareExec represents a PREPARE executor.
@@ -160,10 +166,14 @@ func (e *PrepareExec) DoPrepare() {
 	sorter := &paramMarkerSorter{markers: extractor.markers}
 	sort.Sort(sorter)
 	e.ParamCount = len(sorter.markers)
+	for i, m := range sorter.markers {
+		m.Order = i
+	}
 	prepared := &Prepared{
 		Stmt:          stmt,
 		Params:        sorter.markers,
 		SchemaVersion: e.IS.SchemaMetaVersion(),
+		UseCache:      plan.Cacheable(stmt),
 	}
 
 	err = plan.PrepareStmt(e.IS, e.Ctx, stmt)
@@ -235,12 +245,16 @@ func (e *ExecuteExec) Build() error {
 		return errors.Trace(ErrWrongParamCount)
 	}
 
+	params := make([]types.Datum, len(e.UsingVars))
+	vars.PreparedParams = make([]interface{}, len(e.UsingVars))
 	for i, usingVar := range e.UsingVars {
 		val, err := usingVar.Eval(nil)
 		if err != nil {
 			return errors.Trace(err)
 		}
 		prepared.Params[i].SetDatum(val)
+		params[i] = val
+		vars.PreparedParams[i] = val
 	}
 	if prepared.SchemaVersion != e.IS.SchemaMetaVersion() {
 		// If the schema version has changed we need to prepare it again,
@@ -251,7 +265,7 @@ func (e *ExecuteExec) Build() error {
 		}
 		prepared.SchemaVersion = e.IS.SchemaMetaVersion()
 	}
-	p, err := plan.Optimize(e.Ctx, prepared.Stmt, e.IS)
+	p, err := e.optimize(prepared, params)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -263,6 +277,7 @@ func (e *ExecuteExec) Build() error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	resetForUpdateTS(e.Ctx)
 	b := newExecutorBuilder(e.Ctx, e.IS, kv.PriorityNormal)
 	stmtExec := b.build(p)
 	if b.err != nil {
@@ -276,6 +291,54 @@ func (e *ExecuteExec) Build() error {
 	return nil
 }
 
+// optimize gets the plan of the prepared statement from the plan cache if possible,
+// otherwise it optimizes the statement and puts the plan into the cache.
+func (e *ExecuteExec) optimize(prepared *Prepared, params []types.Datum) (plan.Plan, error) {
+	cache := e.Ctx.PreparedPlanCache()
+	if cache == nil || !prepared.UseCache {
+		p, err := plan.Optimize(e.Ctx, prepared.Stmt, e.IS)
+		return p, errors.Trace(err)
+	}
+	key := plan.NewPSTMTPlanCacheKey(e.Ctx, e.ID, prepared.SchemaVersion, params)
+	if v, ok := cache.Get(key); ok {
+		p, ok, err := plan.GetCachedPlan(e.Ctx, v.(*plan.PSTMTPlanCacheValue))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if ok {
+			planCacheCounter.WithLabelValues("hit").Inc()
+			return p, nil
+		}
+		cache.Delete(key)
+	}
+	planCacheCounter.WithLabelValues("miss").Inc()
+	p, v, err := plan.OptimizeForCache(e.Ctx, prepared.Stmt, e.IS)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if v != nil {
+		cache.Put(key, v)
+		if prepared.cacheKeys == nil {
+			prepared.cacheKeys = make(map[string]kvcache.Key)
+		}
+		prepared.cacheKeys[string(key.Hash())] = key
+	}
+	return p, nil
+}
+
+// DeleteCachedPlans deletes the cached plans of the prepared statement id from the plan cache of ctx.
+func DeleteCachedPlans(ctx context.Context, id uint32) {
+	prepared, ok := ctx.GetSessionVars().PreparedStmts[id].(*Prepared)
+	cache := ctx.PreparedPlanCache()
+	if !ok || cache == nil {
+		return
+	}
+	for _, key := range prepared.cacheKeys {
+		cache.Delete(key)
+	}
+	prepared.cacheKeys = nil
+}
+
 // DeallocateExec represent a DEALLOCATE executor.
 type DeallocateExec struct {
 	Name string
@@ -295,6 +358,7 @@ func (e *DeallocateExec) Next() (Row, error) {
 	if !ok {
 		return nil, errors.Trace(ErrStmtNotFound)
 	}
+	DeleteCachedPlans(e.ctx, id)
 	delete(vars.PreparedStmtNameToID, e.Name)
 	delete(vars.PreparedStmts, id)
 	return nil, nil
@@ -335,6 +399,10 @@ func ResetStmtCtx(ctx context.Context, s ast.StmtNode) {
 	sc := new(variable.StatementContext)
 	sc.TimeZone = sessVars.GetTimeZone()
 
+	// EXPLAIN ANALYZE executes the explained statement, so the context is reset for it.
+	if explain, ok := s.(*ast.ExplainStmt); ok && explain.Analyze {
+		s = explain.Stmt
+	}
 	switch stmt := s.(type) {
 	case *ast.UpdateStmt, *ast.DeleteStmt:
 		sc.IgnoreTruncate = false
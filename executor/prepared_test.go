@@ -14,6 +14,9 @@
 package executor_test
 
 import (
+	"bytes"
+	"time"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/plan"
@@ -119,3 +122,217 @@ func (s *testSuite) TestPreparedLimitOffset(c *C) {
 	_, err = tk.Se.ExecutePreparedStmt(stmtID, 1)
 	c.Assert(err, IsNil)
 }
+
+func (s *testSuite) TestPreparedPlanCache(c *C) {
+	orgEnable := plan.PreparedPlanCacheEnabled
+	orgCapacity := plan.PreparedPlanCacheCapacity
+	defer func() {
+		plan.PreparedPlanCacheEnabled = orgEnable
+		plan.PreparedPlanCacheCapacity = orgCapacity
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	plan.PreparedPlanCacheEnabled = true
+	plan.PreparedPlanCacheCapacity = 100
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b int, c varchar(20), index idx_b(b), index idx_c(c))")
+	tk.MustExec("insert t values (1, 10, 'a1'), (2, 20, 'a2'), (3, 30, 'b1'), (4, 40, 'b2'), (5, 50, NULL)")
+	cache := tk.Se.PreparedPlanCache()
+	c.Assert(cache, NotNil)
+
+	// The ranges on the handle are rebuilt with the new parameters.
+	tk.MustExec("prepare stmt1 from 'select a from t where a > ? and a <= ?'")
+	tk.MustExec("set @a = 1, @b = 3")
+	tk.MustQuery("execute stmt1 using @a, @b").Check(testkit.Rows("2", "3"))
+	c.Assert(cache.Size(), Equals, 1)
+	tk.MustExec("set @a = 3, @b = 5")
+	tk.MustQuery("execute stmt1 using @a, @b").Check(testkit.Rows("4", "5"))
+	tk.MustExec("set @a = 4, @b = 1")
+	tk.MustQuery("execute stmt1 using @a, @b").Check(testkit.Rows())
+	c.Assert(cache.Size(), Equals, 1)
+
+	// The ranges on the index are rebuilt with the new parameters.
+	tk.MustExec("prepare stmt2 from 'select a, b from t use index(idx_b) where b = ?'")
+	tk.MustExec("set @a = 20")
+	tk.MustQuery("execute stmt2 using @a").Check(testkit.Rows("2 20"))
+	tk.MustExec("set @a = 40")
+	tk.MustQuery("execute stmt2 using @a").Check(testkit.Rows("4 40"))
+	tk.MustExec("set @a = NULL")
+	tk.MustQuery("execute stmt2 using @a").Check(testkit.Rows())
+	tk.MustExec("prepare stmt3 from 'select a from t use index(idx_b) where b in (?, ?) order by a'")
+	tk.MustExec("set @a = 10, @b = 50")
+	tk.MustQuery("execute stmt3 using @a, @b").Check(testkit.Rows("1", "5"))
+	tk.MustExec("set @a = 30, @b = 30")
+	tk.MustQuery("execute stmt3 using @a, @b").Check(testkit.Rows("3"))
+
+	// The plan which can't be rebuilt exactly is optimized again.
+	tk.MustExec("prepare stmt4 from 'select a from t use index(idx_c) where c like ? order by a'")
+	tk.MustExec("set @a = 'a%'")
+	tk.MustQuery("execute stmt4 using @a").Check(testkit.Rows("1", "2"))
+	tk.MustExec("set @a = '%1'")
+	tk.MustQuery("execute stmt4 using @a").Check(testkit.Rows("1", "3"))
+	tk.MustExec("set @a = 'b%'")
+	tk.MustQuery("execute stmt4 using @a").Check(testkit.Rows("3", "4"))
+
+	// The parameters in the projection and the filters are evaluated when executing.
+	tk.MustExec("prepare stmt5 from 'select a + ?, b from t where b > ? and c is not null order by a'")
+	tk.MustExec("set @a = 100, @b = 30")
+	tk.MustQuery("execute stmt5 using @a, @b").Check(testkit.Rows("104 40"))
+	tk.MustExec("set @a = 1000, @b = 10")
+	tk.MustQuery("execute stmt5 using @a, @b").Check(testkit.Rows("1002 20", "1003 30", "1004 40"))
+
+	// The update and delete statements are cached as well.
+	tk.MustExec("prepare stmt6 from 'update t set b = b + ? where a = ?'")
+	tk.MustExec("set @a = 1, @b = 1")
+	tk.MustExec("execute stmt6 using @a, @b")
+	tk.MustExec("set @a = 2, @b = 2")
+	tk.MustExec("execute stmt6 using @a, @b")
+	tk.MustQuery("select b from t where a < 3").Check(testkit.Rows("11", "22"))
+	tk.MustExec("prepare stmt7 from 'delete from t where a = ?'")
+	tk.MustExec("set @a = 5")
+	tk.MustExec("execute stmt7 using @a")
+	tk.MustExec("set @a = 4")
+	tk.MustExec("execute stmt7 using @a")
+	tk.MustQuery("select a from t").Check(testkit.Rows("1", "2", "3"))
+
+	// The statements with parameterized limit are not cached.
+	size := cache.Size()
+	tk.MustExec("prepare stmt8 from 'select a from t limit ?'")
+	tk.MustExec("set @a = 1")
+	tk.MustQuery("execute stmt8 using @a").Check(testkit.Rows("1"))
+	c.Assert(cache.Size(), Equals, size)
+
+	// The plans are invalidated by the schema changes.
+	tk.MustExec("set @a = 1, @b = 3")
+	tk.MustQuery("execute stmt1 using @a, @b").Check(testkit.Rows("2", "3"))
+	size = cache.Size()
+	tk.MustExec("alter table t add column d int default 7")
+	tk.MustQuery("execute stmt1 using @a, @b").Check(testkit.Rows("2", "3"))
+	c.Assert(cache.Size(), Equals, size+1)
+	tk.MustExec("prepare stmt9 from 'select * from t where a = ?'")
+	tk.MustExec("set @a = 3")
+	tk.MustQuery("execute stmt9 using @a").Check(testkit.Rows("3 30 b1 7"))
+	tk.MustExec("alter table t drop column d")
+	tk.MustQuery("execute stmt9 using @a").Check(testkit.Rows("3 30 b1"))
+
+	// The uncommitted changes are read by the plan cached outside the transaction.
+	tk.MustExec("begin")
+	tk.MustExec("insert t values (6, 60, 'c1')")
+	tk.MustExec("set @a = 5, @b = 6")
+	tk.MustQuery("execute stmt1 using @a, @b").Check(testkit.Rows("6"))
+	tk.MustExec("rollback")
+	tk.MustQuery("execute stmt1 using @a, @b").Check(testkit.Rows())
+
+	// The statements with the time functions are not cached, so the functions are evaluated in every execution.
+	size = cache.Size()
+	tk.MustExec("prepare stmt10 from 'select now(6) from t where a = ?'")
+	tk.MustExec("set @a = 1")
+	first := tk.MustQuery("execute stmt10 using @a").Rows()
+	time.Sleep(10 * time.Millisecond)
+	second := tk.MustQuery("execute stmt10 using @a").Rows()
+	c.Assert(first[0][0], Not(Equals), second[0][0])
+	c.Assert(cache.Size(), Equals, size)
+
+	// The statements with validate_password_strength are not cached, so the password policy is read in every execution.
+	tk.MustExec("prepare stmt11 from 'select validate_password_strength(?)'")
+	tk.MustExec("set @a = 'Abxdef1!'")
+	tk.MustQuery("execute stmt11 using @a").Check(testkit.Rows("100"))
+	tk.MustExec("set @@global.validate_password_mixed_case_count = 2")
+	tk.MustQuery("execute stmt11 using @a").Check(testkit.Rows("50"))
+	tk.MustExec("set @@global.validate_password_mixed_case_count = 1")
+	c.Assert(cache.Size(), Equals, size)
+
+	// The parameters of different types are cached with different plans.
+	stmtID, _, _, err := tk.Se.PrepareStmt("select b from t where a = ?")
+	c.Assert(err, IsNil)
+	size = cache.Size()
+	for i, param := range []interface{}{1, 2, "3", "1"} {
+		rs, err := tk.Se.ExecutePreparedStmt(stmtID, param)
+		c.Assert(err, IsNil)
+		row, err := rs.Next()
+		c.Assert(err, IsNil)
+		c.Assert(row.Data[0].GetInt64(), Equals, []int64{11, 22, 30, 11}[i])
+		c.Assert(rs.Close(), IsNil)
+	}
+	c.Assert(cache.Size(), Equals, size+2)
+}
+
+func (s *testSuite) TestPreparedPlanCacheKey(c *C) {
+	orgEnable := plan.PreparedPlanCacheEnabled
+	orgCapacity := plan.PreparedPlanCacheCapacity
+	defer func() {
+		plan.PreparedPlanCacheEnabled = orgEnable
+		plan.PreparedPlanCacheCapacity = orgCapacity
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	plan.PreparedPlanCacheEnabled = true
+	plan.PreparedPlanCacheCapacity = 100
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b int, index idx_b(b))")
+	tk.MustExec("insert t values (1, 10), (2, 20)")
+	cache := tk.Se.PreparedPlanCache()
+	tk.MustExec("prepare stmt from 'select a from t use index(idx_b) where b = ?'")
+	tk.MustExec("set @a = 20")
+	tk.MustQuery("execute stmt using @a").Check(testkit.Rows("2"))
+	size := cache.Size()
+
+	// Every variable which the plan or its executors depend on is a part of the cache key, the plan
+	// is optimized again after the variable is changed.
+	toggles := []struct {
+		set   string
+		reset string
+	}{
+		{"set @@tidb_opt_agg_push_down = 0", "set @@tidb_opt_agg_push_down = 1"},
+		{"set @@tidb_opt_insubquery_unfold = 1", "set @@tidb_opt_insubquery_unfold = 0"},
+		{"set @@tidb_max_cartesian_product_rows = 10", "set @@tidb_max_cartesian_product_rows = 0"},
+		{"set @@tidb_opt_join_reorder_threshold = 5", "set @@tidb_opt_join_reorder_threshold = 0"},
+		{"set @@tidb_max_row_count_for_inlj = 10", "set @@tidb_max_row_count_for_inlj = 128"},
+		{"set @@tidb_index_join_batch_size = 10", "set @@tidb_index_join_batch_size = 25000"},
+		{"set @@tidb_index_lookup_size = 10", "set @@tidb_index_lookup_size = 20000"},
+		{"set @@tidb_index_lookup_concurrency = 1", "set @@tidb_index_lookup_concurrency = 4"},
+		{"set @@tidb_distsql_scan_concurrency = 1", "set @@tidb_distsql_scan_concurrency = 10"},
+		{"set @@tidb_index_serial_scan_concurrency = 2", "set @@tidb_index_serial_scan_concurrency = 1"},
+	}
+	baseKey := plan.NewPSTMTPlanCacheKey(tk.Se, 1, 1, nil).Hash()
+	for _, tt := range toggles {
+		tk.MustExec(tt.set)
+		key := plan.NewPSTMTPlanCacheKey(tk.Se, 1, 1, nil).Hash()
+		c.Assert(bytes.Equal(key, baseKey), IsFalse, Commentf("for %s", tt.set))
+		tk.MustQuery("execute stmt using @a").Check(testkit.Rows("2"))
+		tk.MustExec(tt.reset)
+		key = plan.NewPSTMTPlanCacheKey(tk.Se, 1, 1, nil).Hash()
+		c.Assert(bytes.Equal(key, baseKey), IsTrue, Commentf("for %s", tt.reset))
+	}
+	c.Assert(cache.Size(), Equals, size+len(toggles))
+	tk.MustQuery("execute stmt using @a").Check(testkit.Rows("2"))
+	c.Assert(cache.Size(), Equals, size+len(toggles))
+	// The plans aren't shared between the old and the DAG plan builders, or the optimistic and the
+	// pessimistic transactions.
+	tk.MustExec("set @@tidb_cbo = 0")
+	key := plan.NewPSTMTPlanCacheKey(tk.Se, 1, 1, nil).Hash()
+	c.Assert(bytes.Equal(key, baseKey), IsFalse)
+	tk.MustExec("set @@tidb_cbo = 1")
+	tk.Se.GetSessionVars().TxnCtx.IsPessimistic = true
+	key = plan.NewPSTMTPlanCacheKey(tk.Se, 1, 1, nil).Hash()
+	c.Assert(bytes.Equal(key, baseKey), IsFalse)
+	tk.Se.GetSessionVars().TxnCtx.IsPessimistic = false
+
+	// The cached plans of the statement are deleted when it's deallocated.
+	tk.MustExec("deallocate prepare stmt")
+	c.Assert(cache.Size(), Equals, size-1)
+
+	// The cached plans of the statement are deleted when it's closed by the client.
+	stmtID, _, _, err := tk.Se.PrepareStmt("select a from t where a = ?")
+	c.Assert(err, IsNil)
+	rs, err := tk.Se.ExecutePreparedStmt(stmtID, 1)
+	c.Assert(err, IsNil)
+	c.Assert(rs.Close(), IsNil)
+	c.Assert(cache.Size(), Equals, size)
+	c.Assert(tk.Se.DropPreparedStmt(stmtID), IsNil)
+	c.Assert(cache.Size(), Equals, size-1)
+}
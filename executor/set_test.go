@@ -18,6 +18,7 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 )
@@ -131,6 +132,14 @@ func (s *testSuite) TestSetVar(c *C) {
 	tk.MustExec("set @@tidb_skip_constraint_check = '0'")
 	c.Assert(vars.SkipConstraintCheck, IsFalse)
 
+	// The pessimistic transaction mode can't be set by users yet.
+	_, err = tk.Exec("set @@tidb_txn_mode = 'pessimistic'")
+	c.Assert(terror.ErrorEqual(err, variable.UnknownSystemVar), IsTrue, Commentf("err %v", err))
+	c.Assert(vars.TxnMode, Equals, "")
+	c.Assert(vars.LockWaitTimeout, Equals, int64(variable.DefLockWaitTimeout*1000))
+	tk.MustExec("set @@innodb_lock_wait_timeout = 3")
+	c.Assert(vars.LockWaitTimeout, Equals, int64(3000))
+
 	// Test set transaction isolation level, which is equivalent to setting variable "tx_isolation".
 	tk.MustExec("SET SESSION TRANSACTION ISOLATION LEVEL READ COMMITTED")
 	tk.MustQuery("select @@session.tx_isolation").Check(testkit.Rows("READ-COMMITTED"))
@@ -23,6 +23,7 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
@@ -87,6 +88,8 @@ func (e *ShowExec) fetchAll() error {
 		return e.fetchShowCreateTable()
 	case ast.ShowCreateDatabase:
 		return e.fetchShowCreateDatabase()
+	case ast.ShowCreateView:
+		return e.fetchShowCreateView()
 	case ast.ShowDatabases:
 		return e.fetchShowDatabases()
 	case ast.ShowEngines:
@@ -186,6 +189,7 @@ func (e *ShowExec) fetchShowTables() error {
 	checker := privilege.GetPrivilegeManager(e.ctx)
 	// sort for tables
 	var tableNames []string
+	tableTypes := make(map[string]string)
 	for _, v := range e.is.SchemaTables(e.DBName) {
 		// Test with mysql.AllPrivMask means any privilege would be OK.
 		// TODO: Should consider column privileges, which also make a table visible.
@@ -193,14 +197,17 @@ func (e *ShowExec) fetchShowTables() error {
 			continue
 		}
 		tableNames = append(tableNames, v.Meta().Name.O)
+		if v.Meta().IsView() {
+			tableTypes[v.Meta().Name.O] = "VIEW"
+		} else {
+			tableTypes[v.Meta().Name.O] = "BASE TABLE"
+		}
 	}
 	sort.Strings(tableNames)
 	for _, v := range tableNames {
 		data := types.MakeDatums(v)
 		if e.Full {
-			// TODO: support "VIEW" later if we have supported view feature.
-			// now, just use "BASE TABLE".
-			data = append(data, types.NewDatum("BASE TABLE"))
+			data = append(data, types.NewDatum(tableTypes[v]))
 		}
 		e.rows = append(e.rows, data)
 	}
@@ -232,7 +239,7 @@ func (e *ShowExec) fetchShowColumns() error {
 	}
 	cols := tb.Cols()
 	for _, col := range cols {
-		if e.Column != nil && e.Column.Name.L != col.Name.L {
+		if col.Hidden || (e.Column != nil && e.Column.Name.L != col.Name.L) {
 			continue
 		}
 
@@ -301,6 +308,9 @@ func (e *ShowExec) fetchShowIndex() error {
 		e.rows = append(e.rows, data)
 	}
 	for _, idx := range tb.Indices() {
+		if idx.Meta().State != model.StatePublic {
+			continue
+		}
 		for i, col := range idx.Meta().Columns {
 			nonUniq := 1
 			if idx.Meta().Unique {
@@ -396,12 +406,21 @@ func (e *ShowExec) fetchShowCreateTable() error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if tb.Meta().IsView() {
+		return e.fetchShowCreateView()
+	}
 
 	// TODO: let the result more like MySQL.
 	var buf bytes.Buffer
 	buf.WriteString(fmt.Sprintf("CREATE TABLE `%s` (\n", tb.Meta().Name.O))
 	var pkCol *table.Column
-	for i, col := range tb.Cols() {
+	cols := make([]*table.Column, 0, len(tb.Cols()))
+	for _, col := range tb.Cols() {
+		if !col.Hidden {
+			cols = append(cols, col)
+		}
+	}
+	for i, col := range cols {
 		buf.WriteString(fmt.Sprintf("  `%s` %s", col.Name.O, col.GetTypeDesc()))
 		if col.IsGenerated() {
 			// It's a generated column.
@@ -448,7 +467,7 @@ func (e *ShowExec) fetchShowCreateTable() error {
 		if len(col.Comment) > 0 {
 			buf.WriteString(fmt.Sprintf(" COMMENT '%s'", col.Comment))
 		}
-		if i != len(tb.Cols())-1 {
+		if i != len(cols)-1 {
 			buf.WriteString(",\n")
 		}
 		if tb.Meta().PKIsHandle && mysql.HasPriKeyFlag(col.Flag) {
@@ -462,11 +481,18 @@ func (e *ShowExec) fetchShowCreateTable() error {
 		buf.WriteString(fmt.Sprintf("  PRIMARY KEY (`%s`)", pkCol.Name.O))
 	}
 
-	if len(tb.Indices()) > 0 || len(tb.Meta().ForeignKeys) > 0 {
+	// The indices which aren't public are being added or dropped.
+	publicIndices := make([]table.Index, 0, len(tb.Indices()))
+	for _, idx := range tb.Indices() {
+		if idx.Meta().State == model.StatePublic {
+			publicIndices = append(publicIndices, idx)
+		}
+	}
+	if len(publicIndices) > 0 || len(tb.Meta().ForeignKeys) > 0 {
 		buf.WriteString(",\n")
 	}
 
-	for i, idx := range tb.Indices() {
+	for i, idx := range publicIndices {
 		idxInfo := idx.Meta()
 		if idxInfo.Primary {
 			buf.WriteString("  PRIMARY KEY ")
@@ -476,17 +502,22 @@ func (e *ShowExec) fetchShowCreateTable() error {
 			buf.WriteString(fmt.Sprintf("  KEY `%s` ", idxInfo.Name.O))
 		}
 
-		cols := make([]string, 0, len(idxInfo.Columns))
+		keyParts := make([]string, 0, len(idxInfo.Columns))
 		for _, c := range idxInfo.Columns {
-			cols = append(cols, c.Name.O)
+			// The hidden column is shown as the expression key part.
+			if col := tb.Cols()[c.Offset]; col.Hidden {
+				keyParts = append(keyParts, fmt.Sprintf("(%s)", col.GeneratedExprString))
+			} else {
+				keyParts = append(keyParts, fmt.Sprintf("`%s`", c.Name.O))
+			}
 		}
-		buf.WriteString(fmt.Sprintf("(`%s`)", strings.Join(cols, "`,`")))
-		if i != len(tb.Indices())-1 {
+		buf.WriteString(fmt.Sprintf("(%s)", strings.Join(keyParts, ",")))
+		if i != len(publicIndices)-1 {
 			buf.WriteString(",\n")
 		}
 	}
 
-	if len(tb.Indices()) > 0 && len(tb.Meta().ForeignKeys) > 0 {
+	if len(publicIndices) > 0 && len(tb.Meta().ForeignKeys) > 0 {
 		buf.WriteString(",\n")
 	}
 
@@ -542,12 +573,42 @@ func (e *ShowExec) fetchShowCreateTable() error {
 	if len(tb.Meta().Comment) > 0 {
 		buf.WriteString(fmt.Sprintf(" COMMENT='%s'", format.OutputFormat(tb.Meta().Comment)))
 	}
+	appendPartitionInfo(tb.Meta().Partition, &buf)
 
 	data := types.MakeDatums(tb.Meta().Name.O, buf.String())
 	e.rows = append(e.rows, data)
 	return nil
 }
 
+// appendPartitionInfo appends the PARTITION BY clause of the partitioned table to buf.
+func appendPartitionInfo(pi *model.PartitionInfo, buf *bytes.Buffer) {
+	if pi == nil {
+		return
+	}
+	buf.WriteString(fmt.Sprintf("\nPARTITION BY %s ( %s )", pi.Type, pi.Expr))
+	if pi.Type == model.PartitionTypeHash {
+		buf.WriteString(fmt.Sprintf("\nPARTITIONS %d", pi.Num))
+		return
+	}
+	buf.WriteString(" (\n")
+	for i, def := range pi.Definitions {
+		buf.WriteString(fmt.Sprintf("  PARTITION `%s`", def.Name.O))
+		if pi.Type == model.PartitionTypeRange {
+			if def.LessThan[0] == model.PartitionMaxValue {
+				buf.WriteString(" VALUES LESS THAN MAXVALUE")
+			} else {
+				buf.WriteString(fmt.Sprintf(" VALUES LESS THAN (%s)", strings.Join(def.LessThan, ",")))
+			}
+		} else {
+			buf.WriteString(fmt.Sprintf(" VALUES IN (%s)", strings.Join(def.InValues, ",")))
+		}
+		if i < len(pi.Definitions)-1 {
+			buf.WriteString(",\n")
+		}
+	}
+	buf.WriteString("\n)")
+}
+
 // fetchShowCreateDatabase composes show create database result.
 func (e *ShowExec) fetchShowCreateDatabase() error {
 	db, ok := e.is.SchemaByName(e.DBName)
@@ -566,6 +627,44 @@ func (e *ShowExec) fetchShowCreateDatabase() error {
 	return nil
 }
 
+// fetchShowCreateView composes show create view result.
+func (e *ShowExec) fetchShowCreateView() error {
+	tb, err := e.getTable()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	tblInfo := tb.Meta()
+	if !tblInfo.IsView() {
+		return ddl.ErrWrongObject.GenByArgs(e.DBName.O, tblInfo.Name.O, "VIEW")
+	}
+
+	view := tblInfo.View
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "CREATE ALGORITHM=%s ", view.Algorithm)
+	if view.Definer != nil {
+		fmt.Fprintf(&buf, "DEFINER=`%s`@`%s` ", view.Definer.Username, view.Definer.Hostname)
+	}
+	fmt.Fprintf(&buf, "SQL SECURITY %s VIEW `%s` ", view.Security, tblInfo.Name.O)
+	if len(view.Cols) > 0 {
+		buf.WriteString("(")
+		for i, col := range view.Cols {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(&buf, "`%s`", col.O)
+		}
+		buf.WriteString(") ")
+	}
+	fmt.Fprintf(&buf, "AS %s", view.SelectStmt)
+	if view.CheckOption != model.CheckOptionNone {
+		fmt.Fprintf(&buf, " WITH %s CHECK OPTION", view.CheckOption)
+	}
+
+	data := types.MakeDatums(tblInfo.Name.O, buf.String(), view.Charset, view.Collate)
+	e.rows = append(e.rows, data)
+	return nil
+}
+
 func (e *ShowExec) fetchShowCollation() error {
 	collations := charset.GetCollations()
 	for _, v := range collations {
@@ -23,6 +23,7 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx"
@@ -115,6 +116,12 @@ func (e *SimpleExec) executeBegin(s *ast.BeginStmt) error {
 	// the transaction with COMMIT or ROLLBACK. The autocommit mode then
 	// reverts to its previous state.
 	e.ctx.GetSessionVars().SetStatusFlag(mysql.ServerStatusInTrans, true)
+	// The transaction mode is decided when the transaction begins.
+	txnCtx = e.ctx.GetSessionVars().TxnCtx
+	txnCtx.IsPessimistic = e.ctx.GetSessionVars().TxnMode == variable.TxnModePessimistic
+	if txnCtx.IsPessimistic {
+		e.ctx.Txn().SetOption(kv.Pessimistic, true)
+	}
 	return nil
 }
 
@@ -145,21 +152,18 @@ func (e *SimpleExec) executeCreateUser(s *ast.CreateUserStmt) error {
 			}
 			continue
 		}
-		pwd := ""
-		if spec.AuthOpt != nil {
-			if spec.AuthOpt.ByAuthString {
-				pwd = auth.EncodePassword(spec.AuthOpt.AuthString)
-			} else {
-				pwd = auth.EncodePassword(spec.AuthOpt.HashString)
-			}
+		pwd, authString, plugin, err1 := authColumns(spec.AuthOpt)
+		if err1 != nil {
+			return errors.Trace(err1)
 		}
-		user := fmt.Sprintf(`("%s", "%s", "%s")`, spec.User.Hostname, spec.User.Username, pwd)
+		user := fmt.Sprintf(`("%s", "%s", "%s", "%s", "%s", "%s")`, spec.User.Hostname, spec.User.Username, pwd, authString, plugin, sslType(s.Require))
 		users = append(users, user)
 	}
 	if len(users) == 0 {
 		return nil
 	}
-	sql := fmt.Sprintf(`INSERT INTO %s.%s (Host, User, Password) VALUES %s;`, mysql.SystemDB, mysql.UserTable, strings.Join(users, ", "))
+	sql := fmt.Sprintf(`INSERT INTO %s.%s (Host, User, Password, authentication_string, plugin, ssl_type) VALUES %s;`,
+		mysql.SystemDB, mysql.UserTable, strings.Join(users, ", "))
 	_, err := e.ctx.(sqlexec.SQLExecutor).Execute(sql)
 	if err != nil {
 		return errors.Trace(err)
@@ -194,16 +198,20 @@ func (e *SimpleExec) executeAlterUser(s *ast.AlterUserStmt) error {
 			}
 			continue
 		}
-		pwd := ""
-		if spec.AuthOpt != nil {
-			if spec.AuthOpt.ByAuthString {
-				pwd = auth.EncodePassword(spec.AuthOpt.AuthString)
-			} else {
-				pwd = auth.EncodePassword(spec.AuthOpt.HashString)
-			}
+		pwd, authString, plugin, err := authColumns(spec.AuthOpt)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		// The password is kept unchanged if only the REQUIRE clause is specified.
+		sets := make([]string, 0, 4)
+		if spec.AuthOpt != nil || s.Require == ast.RequireUnspecified {
+			sets = append(sets, fmt.Sprintf(`Password = "%s", authentication_string = "%s", plugin = "%s"`, pwd, authString, plugin))
+		}
+		if s.Require != ast.RequireUnspecified {
+			sets = append(sets, fmt.Sprintf(`ssl_type = "%s"`, sslType(s.Require)))
 		}
-		sql := fmt.Sprintf(`UPDATE %s.%s SET Password = "%s" WHERE Host = "%s" and User = "%s";`,
-			mysql.SystemDB, mysql.UserTable, pwd, spec.User.Hostname, spec.User.Username)
+		sql := fmt.Sprintf(`UPDATE %s.%s SET %s WHERE Host = "%s" and User = "%s";`,
+			mysql.SystemDB, mysql.UserTable, strings.Join(sets, ", "), spec.User.Hostname, spec.User.Username)
 		_, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
 		if err != nil {
 			failedUsers = append(failedUsers, spec.User.String())
@@ -252,6 +260,44 @@ func (e *SimpleExec) executeDropUser(s *ast.DropUserStmt) error {
 	return nil
 }
 
+// sslType returns the value of ssl_type column in mysql.user table for the REQUIRE clause.
+func sslType(require ast.RequireType) string {
+	switch require {
+	case ast.RequireSSL:
+		return "ANY"
+	case ast.RequireX509:
+		return "X509"
+	}
+	return ""
+}
+
+// authColumns returns the values of the Password, authentication_string and plugin columns of mysql.user
+// for the auth option. The password of mysql_native_password is kept in the Password column.
+func authColumns(opt *ast.AuthOption) (pwd, authString, plugin string, err error) {
+	plugin = auth.MySQLNativePassword
+	if opt != nil && len(opt.AuthPlugin) > 0 {
+		plugin = opt.AuthPlugin
+	}
+	p, ok := auth.GetAuthPlugin(plugin)
+	if !ok {
+		return "", "", "", ErrPluginIsNotLoaded.GenByArgs(plugin)
+	}
+	switch {
+	case opt == nil:
+	case opt.ByAuthString:
+		authString = p.EncodePassword(opt.AuthString)
+	case len(opt.AuthPlugin) > 0:
+		// IDENTIFIED WITH plugin AS 'auth_string' specifies the authentication string directly.
+		authString = opt.HashString
+	default:
+		authString = auth.EncodePassword(opt.HashString)
+	}
+	if plugin == auth.MySQLNativePassword {
+		return authString, "", plugin, nil
+	}
+	return "", authString, plugin, nil
+}
+
 func userExists(ctx context.Context, name string, host string) (bool, error) {
 	sql := fmt.Sprintf(`SELECT * FROM %s.%s WHERE User="%s" AND Host="%s";`, mysql.SystemDB, mysql.UserTable, name, host)
 	rows, _, err := ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, sql)
@@ -269,16 +315,27 @@ func (e *SimpleExec) executeSetPwd(s *ast.SetPwdStmt) error {
 			return errors.New("Session error is empty")
 		}
 	}
-	exists, err := userExists(e.ctx, s.User.Username, s.User.Hostname)
+	sql := fmt.Sprintf(`SELECT plugin FROM %s.%s WHERE User="%s" AND Host="%s";`, mysql.SystemDB, mysql.UserTable, s.User.Username, s.User.Hostname)
+	rows, _, err := e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
 	if err != nil {
 		return errors.Trace(err)
 	}
-	if !exists {
+	if len(rows) == 0 {
 		return errors.Trace(ErrPasswordNoMatch)
 	}
 
+	// The password is encoded by the authentication plugin of the user.
+	pwd, authString, plugin, err := authColumns(&ast.AuthOption{
+		AuthPlugin:   rows[0].Data[0].GetString(),
+		AuthString:   s.Password,
+		ByAuthString: true,
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
 	// update mysql.user
-	sql := fmt.Sprintf(`UPDATE %s.%s SET password="%s" WHERE User="%s" AND Host="%s";`, mysql.SystemDB, mysql.UserTable, auth.EncodePassword(s.Password), s.User.Username, s.User.Hostname)
+	sql = fmt.Sprintf(`UPDATE %s.%s SET password="%s", authentication_string="%s", plugin="%s" WHERE User="%s" AND Host="%s";`,
+		mysql.SystemDB, mysql.UserTable, pwd, authString, plugin, s.User.Username, s.User.Hostname)
 	_, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
 	sessionctx.GetDomain(e.ctx).NotifyUpdatePrivilege(e.ctx)
 	return errors.Trace(err)
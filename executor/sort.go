@@ -15,14 +15,28 @@ package executor
 
 import (
 	"container/heap"
+	"io/ioutil"
+	"os"
 	"sort"
+	"time"
+	"unsafe"
 
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/filesort"
 	"github.com/pingcap/tidb/util/types"
 )
 
+// sortSpillWorkers is the number of workers used by the file sorter when a sort operator spills to disk.
+const sortSpillWorkers = 4
+
+// datumSize is the in-memory size of a types.Datum struct, used to estimate the memory usage of the buffered rows.
+var datumSize = int64(unsafe.Sizeof(types.Datum{}))
+
 // orderByRow binds a row to its order values, so it can be sorted.
 type orderByRow struct {
 	key []types.Datum
@@ -39,12 +53,28 @@ type SortExec struct {
 	fetched bool
 	err     error
 	schema  *expression.Schema
+
+	// memUsage is the estimated memory usage of the buffered rows.
+	memUsage int64
+	// peakMemUsage is the max memUsage before the rows are spilled to disk.
+	peakMemUsage int64
+	// fileSorter is not nil once the buffered rows exceed the memory quota and the sort is spilled to disk.
+	fileSorter *filesort.FileSorter
 }
 
 // Close implements the Executor Close interface.
 func (e *SortExec) Close() error {
 	e.Rows = nil
-	return errors.Trace(e.children[0].Close())
+	e.memUsage = 0
+	err := e.children[0].Close()
+	if e.fileSorter != nil {
+		// Close removes the temporary files of the FileSorter.
+		if closeErr := e.fileSorter.Close(); err == nil {
+			err = closeErr
+		}
+		e.fileSorter = nil
+	}
+	return errors.Trace(err)
 }
 
 // Open implements the Executor Open interface.
@@ -52,9 +82,16 @@ func (e *SortExec) Open() error {
 	e.fetched = false
 	e.Idx = 0
 	e.Rows = nil
+	e.memUsage = 0
+	e.peakMemUsage = 0
 	return errors.Trace(e.children[0].Open())
 }
 
+// memoryUsage implements the memoryUsageReporter interface.
+func (e *SortExec) memoryUsage() int64 {
+	return e.peakMemUsage
+}
+
 // Len returns the number of rows.
 func (e *SortExec) Len() int {
 	return len(e.Rows)
@@ -95,6 +132,7 @@ func (e *SortExec) Less(i, j int) bool {
 // Next implements the Executor Next interface.
 func (e *SortExec) Next() (Row, error) {
 	if !e.fetched {
+		memQuota := e.ctx.GetSessionVars().MemQuotaSort
 		for {
 			srcRow, err := e.children[0].Next()
 			if err != nil {
@@ -113,14 +151,36 @@ func (e *SortExec) Next() (Row, error) {
 					return nil, errors.Trace(err)
 				}
 			}
+			if e.fileSorter != nil {
+				err = e.inputFileSorter(orderRow)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+				continue
+			}
 			e.Rows = append(e.Rows, orderRow)
+			e.memUsage += estimateOrderByRowSize(orderRow)
+			if e.memUsage > e.peakMemUsage {
+				e.peakMemUsage = e.memUsage
+			}
+			if e.memUsage > memQuota {
+				err = e.spillToDisk(memQuota)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+			}
+		}
+		if e.fileSorter == nil {
+			sort.Sort(e)
 		}
-		sort.Sort(e)
 		e.fetched = true
 	}
 	if e.err != nil {
 		return nil, errors.Trace(e.err)
 	}
+	if e.fileSorter != nil {
+		return e.outputFileSorter()
+	}
 	if e.Idx >= len(e.Rows) {
 		return nil, nil
 	}
@@ -129,6 +189,106 @@ func (e *SortExec) Next() (Row, error) {
 	return row, nil
 }
 
+// spillToDisk creates a FileSorter in the configured temporary directory, and moves all the buffered rows into it.
+// The rows that come after are sent to the FileSorter directly.
+func (e *SortExec) spillToDisk(memQuota int64) error {
+	tmpDir, err := ioutil.TempDir(config.GetGlobalConfig().TempDir, "tidb-sort-")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	byDesc := make([]bool, len(e.ByItems))
+	for i, byItem := range e.ByItems {
+		byDesc[i] = byItem.Desc
+	}
+	// The buffer of the FileSorter is shared by its workers, and a worker flushing its rows to a file holds
+	// the encoded rows as well, so the buffered rows are limited to half of the memory quota.
+	avgRowSize := e.memUsage / int64(len(e.Rows))
+	bufSize := int(memQuota / avgRowSize / 2)
+	if bufSize < sortSpillWorkers {
+		bufSize = sortSpillWorkers
+	}
+	fs, err := new(filesort.Builder).
+		SetSC(e.ctx.GetSessionVars().StmtCtx).
+		SetSchema(len(e.ByItems), e.children[0].Schema().Len()).
+		SetBuf(bufSize).
+		SetWorkers(sortSpillWorkers).
+		SetDesc(byDesc).
+		SetDir(tmpDir).
+		Build()
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return errors.Trace(err)
+	}
+	e.fileSorter = fs
+	sortSpillCounter.Inc()
+	for _, orderRow := range e.Rows {
+		err = e.inputFileSorter(orderRow)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	e.Rows = nil
+	e.memUsage = 0
+	return nil
+}
+
+// inputFileSorter flattens the order values and the row of orderRow, and adds them into the FileSorter.
+// The row values are encoded one by one, so they can be restored with the field types of the child schema.
+func (e *SortExec) inputFileSorter(orderRow *orderByRow) error {
+	key := make([]types.Datum, len(orderRow.key))
+	for i, d := range orderRow.key {
+		b, err := tablecodec.EncodeValue(d, time.UTC)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		_, key[i], err = codec.DecodeOne(b)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	val := make([]types.Datum, len(orderRow.row))
+	for i, d := range orderRow.row {
+		b, err := tablecodec.EncodeValue(d, time.UTC)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		val[i].SetBytes(b)
+	}
+	return errors.Trace(e.fileSorter.Input(key, val, 0))
+}
+
+// outputFileSorter gets the next sorted row from the FileSorter and decodes it.
+func (e *SortExec) outputFileSorter() (Row, error) {
+	_, val, _, err := e.fileSorter.Output()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if val == nil {
+		return nil, nil
+	}
+	cols := e.children[0].Schema().Columns
+	row := make(Row, len(val))
+	for i, d := range val {
+		row[i], err = tablecodec.DecodeColumnValue(d.GetBytes(), cols[i].RetType, time.UTC)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return row, nil
+}
+
+// estimateOrderByRowSize estimates the memory usage of an orderByRow.
+func estimateOrderByRowSize(orderRow *orderByRow) int64 {
+	size := datumSize * int64(len(orderRow.key)+len(orderRow.row))
+	for i := range orderRow.key {
+		size += int64(len(orderRow.key[i].GetBytes()))
+	}
+	for i := range orderRow.row {
+		size += int64(len(orderRow.row[i].GetBytes()))
+	}
+	return size
+}
+
 // TopNExec implements a Top-N algorithm and it is built from a SELECT statement with ORDER BY and LIMIT.
 // Instead of sorting all the rows fetched from the table, it keeps the Top-N elements only in a heap to reduce memory usage.
 type TopNExec struct {
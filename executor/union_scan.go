@@ -95,6 +95,8 @@ type UnionScanExec struct {
 	desc       bool
 	conditions []expression.Expression
 	columns    []*model.ColumnInfo
+	// partitionedTable is set if the table to read is a partition, the added rows of other partitions are skipped.
+	partitionedTable table.PartitionedTable
 
 	// belowHandleIndex is the handle's position of the below scan plan.
 	belowHandleIndex int
@@ -251,6 +253,15 @@ func (us *UnionScanExec) compare(a, b Row) (int, error) {
 func (us *UnionScanExec) buildAndSortAddedRows(t table.Table) error {
 	us.addedRows = make([]Row, 0, len(us.dirty.addedRows))
 	for h, data := range us.dirty.addedRows {
+		if us.partitionedTable != nil {
+			p, err := us.partitionedTable.GetPartitionByRow(us.ctx, data)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if p.GetPhysicalID() != t.(table.PhysicalTable).GetPhysicalID() {
+				continue
+			}
+		}
 		newData := make([]types.Datum, 0, us.schema.Len())
 		for _, col := range us.columns {
 			if col.ID == model.ExtraHandleID {
@@ -0,0 +1,135 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor_test
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ddl"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testkit"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func (s *testSuite) TestCreateView(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, t1")
+	tk.MustExec("create table t (a int, b int)")
+	tk.MustExec("insert into t values (1, 2), (3, 4), (5, 6)")
+	tk.MustExec("create view v as select a, b + 1 from t where a > 1")
+	tk.MustQuery("select * from v order by a").Check(testkit.Rows("3 5", "5 7"))
+	tk.MustQuery("select `b + 1` from v where a = 3").Check(testkit.Rows("5"))
+
+	// The view column list renames the columns.
+	tk.MustExec("create view v1 (c, d) as select a, b from t")
+	tk.MustQuery("select d from v1 where c = 1").Check(testkit.Rows("2"))
+	tk.MustQuery("select x.c, t.b from v1 x join t on x.c = t.a order by x.c").Check(testkit.Rows("1 2", "3 4", "5 6"))
+	tk.MustQuery("select count(*) from v1 where c in (select a from v)").Check(testkit.Rows("2"))
+
+	// Views are resolved in their own database.
+	tk.MustExec("create database view_db")
+	tk.MustExec("use view_db")
+	tk.MustQuery("select c from test.v1 order by c").Check(testkit.Rows("1", "3", "5"))
+	tk.MustExec("use test")
+	tk.MustExec("drop database view_db")
+
+	// A view on a view sees the changes of the underlying table.
+	tk.MustExec("create view v2 as select c from v1 where d > 2")
+	tk.MustExec("insert into t values (7, 8)")
+	tk.MustQuery("select * from v2 order by c").Check(testkit.Rows("3", "5", "7"))
+
+	tk.MustExec("create or replace view v as select a from t where a < 3")
+	tk.MustQuery("select * from v").Check(testkit.Rows("1"))
+	tk.MustQuery("show full tables like 'v%'").Check(testkit.Rows("v VIEW", "v1 VIEW", "v2 VIEW"))
+	tk.MustQuery("select table_name, table_type from information_schema.tables where table_schema = 'test' and table_name like 'v%' order by table_name").
+		Check(testkit.Rows("v VIEW", "v1 VIEW", "v2 VIEW"))
+
+	_, err := tk.Exec("create view v as select * from t")
+	c.Assert(terror.ErrorEqual(err, infoschema.ErrTableExists), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("create or replace view t as select 1")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrWrongObject), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("create view v3 (x) as select a, b from t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrViewWrongList), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("create view v3 as select a, b as a from t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrDupFieldName), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("create or replace view v1 as select * from v2")
+	c.Assert(terror.ErrorEqual(err, plan.ErrViewRecursive), IsTrue, Commentf("err %v", err))
+
+	// Views can't be written or used as base tables.
+	_, err = tk.Exec("insert into v values (1)")
+	c.Assert(terror.ErrorEqual(err, plan.ErrNonInsertableTable), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("update v set a = 2")
+	c.Assert(terror.ErrorEqual(err, plan.ErrNonUpdatableTable), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("delete from v")
+	c.Assert(terror.ErrorEqual(err, plan.ErrNonUpdatableTable), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("truncate table v")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrWrongObject), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("drop table v")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrWrongObject), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("drop view t")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrWrongObject), IsTrue, Commentf("err %v", err))
+
+	// The view becomes invalid if its table is dropped.
+	tk.MustExec("create table t1 (a int)")
+	tk.MustExec("create view v3 as select a from t1")
+	tk.MustExec("drop table t1")
+	_, err = tk.Exec("select * from v3")
+	c.Assert(terror.ErrorEqual(err, plan.ErrViewInvalid), IsTrue, Commentf("err %v", err))
+
+	tk.MustExec("drop view v, v1, v2, v3")
+	_, err = tk.Exec("drop view v")
+	c.Assert(terror.ErrorEqual(err, infoschema.ErrTableDropExists), IsTrue, Commentf("err %v", err))
+	tk.MustExec("drop view if exists v")
+	tk.MustQuery("show tables").Check(testkit.Rows("t"))
+}
+
+func (s *testSuite) TestShowCreateView(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int)")
+	tk.MustExec("create algorithm = merge definer = 'root'@'localhost' sql security invoker view v (x, y) as select a, b from t where a > 1 with local check option")
+	tk.MustQuery("show create view v").Check(testkit.Rows("v CREATE ALGORITHM=MERGE DEFINER=`root`@`localhost` SQL SECURITY INVOKER VIEW `v` (`x`, `y`) " +
+		"AS select a, b from t where a > 1 WITH LOCAL CHECK OPTION utf8 utf8_bin"))
+	tk.MustQuery("show create table v").Check(testkit.Rows("v CREATE ALGORITHM=MERGE DEFINER=`root`@`localhost` SQL SECURITY INVOKER VIEW `v` (`x`, `y`) " +
+		"AS select a, b from t where a > 1 WITH LOCAL CHECK OPTION utf8 utf8_bin"))
+	tk.MustQuery("select table_name, view_definition, check_option, definer, security_type from information_schema.views where table_schema = 'test'").
+		Check(testkit.Rows("v select a, b from t where a > 1 LOCAL root@localhost INVOKER"))
+
+	// The view is parsed with the charset and the collation used to create it.
+	tk.MustExec("set names latin1")
+	tk.MustExec("create view v_latin1 as select a, 'x' as c from t")
+	tk.MustExec("set names utf8")
+	tk.MustQuery("show create view v_latin1").Check(testkit.Rows("v_latin1 CREATE ALGORITHM=UNDEFINED SQL SECURITY DEFINER VIEW `v_latin1` " +
+		"AS select a, 'x' as c from t latin1 latin1_bin"))
+	tk.MustExec("insert into t values (1, 2)")
+	tk.MustQuery("select * from v_latin1").Check(testkit.Rows("1 x"))
+	tk.MustExec("drop view v_latin1")
+	rs, err := tk.Exec("show create view t")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(rs.Close(), IsNil)
+	c.Assert(terror.ErrorEqual(err, ddl.ErrWrongObject), IsTrue, Commentf("err %v", err))
+	tk.MustExec("drop view v")
+}
@@ -0,0 +1,453 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// WindowExec computes a window function for every row of its child.
+// The child output is sorted by the partition by items and then the order by items,
+// so WindowExec buffers one partition at a time, computes the results of all its rows,
+// and appends the result to the end of every row.
+type WindowExec struct {
+	baseExecutor
+
+	StmtCtx     *variable.StatementContext
+	WindowFunc  *plan.WindowFuncDesc
+	PartitionBy []*plan.ByItems
+	OrderBy     []*plan.ByItems
+	Frame       *plan.WindowFrame
+
+	aggFunc expression.AggregationFunction
+	// rows is the buffered rows of the current partition.
+	rows []Row
+	// orderKeys is the order by values of the buffered rows.
+	orderKeys [][]types.Datum
+	results   []types.Datum
+	cursor    int
+	// peerStart and peerEnd are the range of the last found peer group of the partition.
+	peerStart int
+	peerEnd   int
+
+	partitionKey []types.Datum
+	// nextRow is the first row of the next partition, it has been read from the child.
+	nextRow   Row
+	nextKey   []types.Datum
+	childDone bool
+}
+
+// Open implements the Executor Open interface.
+func (e *WindowExec) Open() error {
+	e.rows = nil
+	e.orderKeys = nil
+	e.results = nil
+	e.cursor = 0
+	e.partitionKey = nil
+	e.nextRow = nil
+	e.nextKey = nil
+	e.childDone = false
+	e.aggFunc = nil
+	if !isNonAggWindowFunc(e.WindowFunc.Name) {
+		e.aggFunc = expression.NewAggFunction(e.WindowFunc.Name, e.WindowFunc.Args, e.WindowFunc.Distinct)
+	}
+	return errors.Trace(e.children[0].Open())
+}
+
+// Close implements the Executor Close interface.
+func (e *WindowExec) Close() error {
+	e.rows = nil
+	e.orderKeys = nil
+	e.results = nil
+	e.nextRow = nil
+	return errors.Trace(e.children[0].Close())
+}
+
+// Next implements the Executor Next interface.
+func (e *WindowExec) Next() (Row, error) {
+	for e.cursor >= len(e.rows) {
+		if e.childDone && e.nextRow == nil {
+			return nil, nil
+		}
+		if err := e.fetchPartition(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	row := make(Row, 0, len(e.rows[e.cursor])+1)
+	row = append(row, e.rows[e.cursor]...)
+	row = append(row, e.results[e.cursor])
+	e.cursor++
+	return row, nil
+}
+
+func isNonAggWindowFunc(name string) bool {
+	switch name {
+	case ast.WindowFuncRowNumber, ast.WindowFuncRank, ast.WindowFuncDenseRank, ast.WindowFuncLag,
+		ast.WindowFuncLead, ast.WindowFuncFirstValue, ast.WindowFuncLastValue:
+		return true
+	}
+	return false
+}
+
+func evalByItems(items []*plan.ByItems, row Row) ([]types.Datum, error) {
+	vals := make([]types.Datum, 0, len(items))
+	for _, item := range items {
+		v, err := item.Expr.Eval(row)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		vals = append(vals, v)
+	}
+	return vals, nil
+}
+
+func (e *WindowExec) equalKeys(a, b []types.Datum) (bool, error) {
+	for i := range a {
+		c, err := a[i].CompareDatum(e.StmtCtx, b[i])
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if c != 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// fetchPartition reads all the rows of the next partition from the child and computes their results.
+func (e *WindowExec) fetchPartition() error {
+	e.rows = e.rows[:0]
+	e.orderKeys = e.orderKeys[:0]
+	e.cursor = 0
+	if e.nextRow != nil {
+		e.rows = append(e.rows, e.nextRow)
+		e.partitionKey = e.nextKey
+		e.nextRow, e.nextKey = nil, nil
+	}
+	for !e.childDone {
+		row, err := e.children[0].Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			e.childDone = true
+			break
+		}
+		key, err := evalByItems(e.PartitionBy, row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if len(e.rows) == 0 {
+			e.partitionKey = key
+		} else {
+			same, err := e.equalKeys(e.partitionKey, key)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if !same {
+				e.nextRow, e.nextKey = row, key
+				break
+			}
+		}
+		e.rows = append(e.rows, row)
+	}
+	for _, row := range e.rows {
+		key, err := evalByItems(e.OrderBy, row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		e.orderKeys = append(e.orderKeys, key)
+	}
+	return errors.Trace(e.computeResults())
+}
+
+func (e *WindowExec) isPeer(i, j int) (bool, error) {
+	return e.equalKeys(e.orderKeys[i], e.orderKeys[j])
+}
+
+func (e *WindowExec) computeResults() error {
+	n := len(e.rows)
+	e.results = e.results[:0]
+	e.peerStart, e.peerEnd = 0, 0
+	switch e.WindowFunc.Name {
+	case ast.WindowFuncRowNumber, ast.WindowFuncRank, ast.WindowFuncDenseRank:
+		var rank, denseRank int64
+		for i := 0; i < n; i++ {
+			peer := false
+			if i > 0 {
+				var err error
+				peer, err = e.isPeer(i, i-1)
+				if err != nil {
+					return errors.Trace(err)
+				}
+			}
+			if !peer {
+				rank = int64(i + 1)
+				denseRank++
+			}
+			var d types.Datum
+			switch e.WindowFunc.Name {
+			case ast.WindowFuncRowNumber:
+				d.SetInt64(int64(i + 1))
+			case ast.WindowFuncRank:
+				d.SetInt64(rank)
+			default:
+				d.SetInt64(denseRank)
+			}
+			e.results = append(e.results, d)
+		}
+	case ast.WindowFuncLag, ast.WindowFuncLead:
+		for i := 0; i < n; i++ {
+			d, err := e.evalLagLead(i)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			e.results = append(e.results, d)
+		}
+	default:
+		return errors.Trace(e.computeFrameResults())
+	}
+	return nil
+}
+
+// evalLagLead evaluates LAG or LEAD for the i-th row of the partition.
+func (e *WindowExec) evalLagLead(i int) (types.Datum, error) {
+	args := e.WindowFunc.Args
+	offset := int64(1)
+	if len(args) > 1 {
+		v, err := args[1].Eval(e.rows[i])
+		if err != nil {
+			return types.Datum{}, errors.Trace(err)
+		}
+		offset, err = v.ToInt64(e.StmtCtx)
+		if err != nil {
+			return types.Datum{}, errors.Trace(err)
+		}
+	}
+	if e.WindowFunc.Name == ast.WindowFuncLag {
+		offset = -offset
+	}
+	idx := int64(i) + offset
+	if idx >= 0 && idx < int64(len(e.rows)) {
+		d, err := args[0].Eval(e.rows[idx])
+		return d, errors.Trace(err)
+	}
+	if len(args) > 2 {
+		d, err := args[2].Eval(e.rows[i])
+		return d, errors.Trace(err)
+	}
+	return types.Datum{}, nil
+}
+
+// computeFrameResults computes FIRST_VALUE, LAST_VALUE and the aggregate functions over the frame of every row.
+func (e *WindowExec) computeFrameResults() error {
+	lastStart, lastEnd := -1, -1
+	var last types.Datum
+	for i := range e.rows {
+		start, end, err := e.frameRange(i)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if start == lastStart && end == lastEnd {
+			e.results = append(e.results, last)
+			continue
+		}
+		switch e.WindowFunc.Name {
+		case ast.WindowFuncFirstValue, ast.WindowFuncLastValue:
+			last = types.Datum{}
+			if start < end {
+				idx := start
+				if e.WindowFunc.Name == ast.WindowFuncLastValue {
+					idx = end - 1
+				}
+				last, err = e.WindowFunc.Args[0].Eval(e.rows[idx])
+				if err != nil {
+					return errors.Trace(err)
+				}
+			}
+		default:
+			// A frame sharing the start of the last one and ending after it only needs the new rows.
+			from := start
+			if start != lastStart || end < lastEnd {
+				e.aggFunc.Reset()
+			} else {
+				from = lastEnd
+			}
+			for j := from; j < end; j++ {
+				if err = e.aggFunc.Update(e.rows[j], nil, e.StmtCtx); err != nil {
+					return errors.Trace(err)
+				}
+			}
+			last = e.aggFunc.GetGroupResult(nil)
+		}
+		lastStart, lastEnd = start, end
+		e.results = append(e.results, last)
+	}
+	return nil
+}
+
+// peerRange returns the range of the peers of the i-th row. The rows are visited in order, so the range of the
+// last peer group is kept and a new one is only searched when the i-th row is out of it.
+func (e *WindowExec) peerRange(i int) (int, int, error) {
+	if i >= e.peerStart && i < e.peerEnd {
+		return e.peerStart, e.peerEnd, nil
+	}
+	// The rows before the last peer group aren't the peers of the rows after it.
+	lower := 0
+	if i >= e.peerEnd {
+		lower = e.peerEnd
+	}
+	start, end := i, i+1
+	for start > lower {
+		peer, err := e.isPeer(start-1, i)
+		if err != nil {
+			return 0, 0, errors.Trace(err)
+		}
+		if !peer {
+			break
+		}
+		start--
+	}
+	for end < len(e.rows) {
+		peer, err := e.isPeer(end, i)
+		if err != nil {
+			return 0, 0, errors.Trace(err)
+		}
+		if !peer {
+			break
+		}
+		end++
+	}
+	e.peerStart, e.peerEnd = start, end
+	return start, end, nil
+}
+
+// frameRange returns the frame [start, end) of the i-th row.
+func (e *WindowExec) frameRange(i int) (int, int, error) {
+	n := len(e.rows)
+	if e.Frame == nil {
+		if len(e.OrderBy) == 0 {
+			return 0, n, nil
+		}
+		// The default frame is RANGE BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW.
+		_, end, err := e.peerRange(i)
+		return 0, end, errors.Trace(err)
+	}
+	start, err := e.boundIndex(i, e.Frame.Start, true)
+	if err != nil {
+		return 0, 0, errors.Trace(err)
+	}
+	end, err := e.boundIndex(i, e.Frame.End, false)
+	if err != nil {
+		return 0, 0, errors.Trace(err)
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end > n {
+		end = n
+	}
+	if start > end {
+		start = end
+	}
+	return start, end, nil
+}
+
+// boundIndex returns the first row index of the frame if isStart is true, otherwise the index after the last row.
+func (e *WindowExec) boundIndex(i int, bound *plan.FrameBound, isStart bool) (int, error) {
+	n := len(e.rows)
+	if bound.UnBounded {
+		if bound.Type == ast.Preceding {
+			return 0, nil
+		}
+		return n, nil
+	}
+	if e.Frame.Type == ast.Rows {
+		idx := i
+		if bound.Type != ast.CurrentRow {
+			offset, err := bound.Num.ToInt64(e.StmtCtx)
+			if err != nil {
+				return 0, errors.Trace(err)
+			}
+			if bound.Type == ast.Preceding {
+				offset = -offset
+			}
+			idx += int(offset)
+		}
+		if !isStart {
+			idx++
+		}
+		if idx < 0 {
+			return 0, nil
+		}
+		return idx, nil
+	}
+	if bound.Type == ast.CurrentRow || e.orderKeys[i][0].IsNull() {
+		start, end, err := e.peerRange(i)
+		if isStart {
+			return start, errors.Trace(err)
+		}
+		return end, errors.Trace(err)
+	}
+	return e.rangeBoundIndex(i, bound, isStart)
+}
+
+// rangeBoundIndex finds the bound of a RANGE frame with an offset by binary search on the only order by item.
+func (e *WindowExec) rangeBoundIndex(i int, bound *plan.FrameBound, isStart bool) (int, error) {
+	cur, err := e.orderKeys[i][0].ToFloat64(e.StmtCtx)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	offset, err := bound.Num.ToFloat64(e.StmtCtx)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	desc := e.OrderBy[0].Desc
+	// A preceding bound is smaller than the current value in ascending order, and larger in descending order.
+	if (bound.Type == ast.Preceding) != desc {
+		offset = -offset
+	}
+	target := cur + offset
+	var searchErr error
+	idx := sort.Search(len(e.rows), func(j int) bool {
+		key := e.orderKeys[j][0]
+		if key.IsNull() {
+			// NULLs are sorted first in ascending order and last in descending order.
+			return desc
+		}
+		v, err := key.ToFloat64(e.StmtCtx)
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		switch {
+		case !desc && isStart:
+			return v >= target
+		case !desc:
+			return v > target
+		case isStart:
+			return v <= target
+		default:
+			return v < target
+		}
+	})
+	return idx, errors.Trace(searchErr)
+}
@@ -0,0 +1,103 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor_test
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/juju/errors"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testkit"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func (s *testSuite) TestWindowFunction(c *C) {
+	// New expression evaluation architecture does not support aggregation functions now.
+	origin := atomic.LoadInt32(&expression.TurnOnNewExprEval)
+	atomic.StoreInt32(&expression.TurnOnNewExprEval, 0)
+	defer func() {
+		atomic.StoreInt32(&expression.TurnOnNewExprEval, origin)
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int, c varchar(10))")
+	tk.MustExec("insert into t values (1, 1, 'a'), (1, 2, 'b'), (1, 2, 'c'), (1, 4, 'd'), (2, 5, 'e'), (2, 7, 'f'), (3, null, 'g')")
+
+	result := tk.MustQuery("select a, b, row_number() over (partition by a order by b, c) from t order by a, b, c")
+	result.Check(testkit.Rows("1 1 1", "1 2 2", "1 2 3", "1 4 4", "2 5 1", "2 7 2", "3 <nil> 1"))
+	result = tk.MustQuery("select a, b, rank() over (partition by a order by b), dense_rank() over (partition by a order by b) from t order by a, b, c")
+	result.Check(testkit.Rows("1 1 1 1", "1 2 2 2", "1 2 2 2", "1 4 4 3", "2 5 1 1", "2 7 2 2", "3 <nil> 1 1"))
+	result = tk.MustQuery("select c, lag(c) over (order by c), lead(c, 2, 'z') over (order by c) from t order by c")
+	result.Check(testkit.Rows("a <nil> c", "b a d", "c b e", "d c f", "e d g", "f e z", "g f z"))
+	result = tk.MustQuery("select c, first_value(c) over (partition by a order by c), last_value(c) over (partition by a) from t order by c")
+	result.Check(testkit.Rows("a a d", "b a d", "c a d", "d a d", "e e f", "f e f", "g g g"))
+
+	// Aggregate functions without a frame use the whole partition, or RANGE UNBOUNDED PRECEDING with ORDER BY.
+	result = tk.MustQuery("select c, sum(b) over (partition by a), sum(b) over (partition by a order by b), count(*) over () from t order by c")
+	result.Check(testkit.Rows("a 9 1 7", "b 9 5 7", "c 9 5 7", "d 9 9 7", "e 12 5 7", "f 12 12 7", "g <nil> <nil> 7"))
+	result = tk.MustQuery("select c, sum(b) over (order by c rows between 1 preceding and 1 following) from t order by c")
+	result.Check(testkit.Rows("a 3", "b 5", "c 8", "d 11", "e 16", "f 12", "g 7"))
+	result = tk.MustQuery("select c, count(b) over (order by b range between 1 preceding and 1 following) from t order by c")
+	result.Check(testkit.Rows("a 3", "b 3", "c 3", "d 2", "e 2", "f 1", "g 0"))
+	result = tk.MustQuery("select c, max(b) over (order by b desc range 2 preceding) from t order by c")
+	result.Check(testkit.Rows("a 2", "b 4", "c 4", "d 5", "e 7", "f 7", "g <nil>"))
+	result = tk.MustQuery("select c, avg(b) over (partition by a order by b rows between current row and unbounded following) from t order by c")
+	result.Check(testkit.Rows("a 2.2500", "b 2.6667", "c 3.0000", "d 4.0000", "e 6.0000", "f 7.0000", "g <nil>"))
+
+	// The frames bounded by the current row end with its peers.
+	result = tk.MustQuery("select c, count(*) over (order by b range between current row and unbounded following), count(*) over (partition by a order by b range current row) from t order by c")
+	result.Check(testkit.Rows("a 6 1", "b 5 2", "c 5 2", "d 3 1", "e 2 1", "f 1 1", "g 7 1"))
+	tk.MustExec("drop table if exists t1")
+	tk.MustExec("create table t1 (a int, b int)")
+	values := make([]string, 0, 2000)
+	for i := 0; i < 2000; i++ {
+		values = append(values, fmt.Sprintf("(%d, %d)", i%2, i))
+	}
+	tk.MustExec("insert into t1 values " + strings.Join(values, ", "))
+	result = tk.MustQuery("select a, cnt, total from (select a, b, count(*) over (order by a) as cnt, sum(b) over (order by a) as total from t1) t2 where b in (0, 1, 1998, 1999) order by b")
+	result.Check(testkit.Rows("0 1000 999000", "1 2000 1999000", "0 1000 999000", "1 2000 1999000"))
+
+	// Window functions can be used in ORDER BY.
+	result = tk.MustQuery("select c from t order by row_number() over (order by c desc) limit 3")
+	result.Check(testkit.Rows("g", "f", "e"))
+	result = tk.MustQuery("select c, row_number() over (order by c desc) as r from t order by r limit 2")
+	result.Check(testkit.Rows("g 1", "f 2"))
+
+	errCases := []struct {
+		sql  string
+		code int
+	}{
+		{"select a from t where row_number() over () > 1", mysql.ErrWindowInvalidWindowFuncUse},
+		{"select a from t group by a having rank() over () > 1", mysql.ErrWindowInvalidWindowFuncUse},
+		{"select sum(row_number() over ()) from t", mysql.ErrWindowInvalidWindowFuncUse},
+		{"select sum(a) over (rows between unbounded following and current row) from t", mysql.ErrWindowFrameStartIllegal},
+		{"select sum(a) over (rows between current row and unbounded preceding) from t", mysql.ErrWindowFrameEndIllegal},
+		{"select sum(a) over (rows 1.5 preceding) from t", mysql.ErrWindowFrameIllegal},
+		{"select sum(a) over (order by c range 1 preceding) from t", mysql.ErrWindowRangeFrameOrderType},
+	}
+	for _, ca := range errCases {
+		_, err := tk.Exec(ca.sql)
+		c.Assert(err, NotNil, Commentf("sql: %s", ca.sql))
+		terr := errors.Cause(err).(*terror.Error)
+		c.Assert(terr.Code(), Equals, terror.ErrCode(ca.code), Commentf("sql: %s", ca.sql))
+	}
+}
@@ -808,6 +808,24 @@ func (e *InsertValues) getColumns(tableCols []*table.Column) ([]*table.Column, e
 	} else {
 		// If e.Columns are empty, use all columns instead.
 		cols = tableCols
+		if hiddenColumnsCount(tableCols) > 0 {
+			// The hidden columns can't be given values, they are appended to calculate their values.
+			cols = make([]*table.Column, 0, len(tableCols))
+			for _, col := range tableCols {
+				if !col.Hidden {
+					cols = append(cols, col)
+				}
+			}
+			columns := make([]string, 0, len(e.GenColumns))
+			for _, v := range e.GenColumns {
+				columns = append(columns, v.Name.O)
+			}
+			genCols, err := table.FindCols(tableCols, columns)
+			if err != nil {
+				return nil, errors.Errorf("INSERT INTO %s: %s", e.Table.Meta().Name.O, err)
+			}
+			cols = append(cols, genCols...)
+		}
 	}
 
 	// Check column whether is specified only once.
@@ -855,7 +873,7 @@ func (e *InsertValues) checkValueCount(insertValueCount, valueCount, genColsCoun
 		}
 		if explicitSetLen > 0 && valueCount+genColsCount != len(cols) {
 			return ErrWrongValueCountOnRow.GenByArgs(num + 1)
-		} else if explicitSetLen == 0 && valueCount != len(cols) {
+		} else if explicitSetLen == 0 && valueCount+hiddenColumnsCount(cols) != len(cols) {
 			return ErrWrongValueCountOnRow.GenByArgs(num + 1)
 		}
 	}
@@ -883,6 +901,17 @@ func (e *InsertValues) getRows(cols []*table.Column, ignoreErr bool) (rows [][]t
 	return
 }
 
+// hiddenColumnsCount returns the count of the hidden columns, their values are always generated.
+func hiddenColumnsCount(cols []*table.Column) int {
+	cnt := 0
+	for _, col := range cols {
+		if col.Hidden {
+			cnt++
+		}
+	}
+	return cnt
+}
+
 func (e *InsertValues) getRow(cols []*table.Column, list []expression.Expression, ignoreErr bool) ([]types.Datum, error) {
 	vals := make([]types.Datum, len(list))
 	for i, expr := range list {
@@ -897,7 +926,7 @@ func (e *InsertValues) getRow(cols []*table.Column, list []expression.Expression
 
 func (e *InsertValues) getRowsSelect(cols []*table.Column, ignoreErr bool) ([][]types.Datum, error) {
 	// process `insert|replace into ... select ... from ...`
-	if e.SelectExec.Schema().Len() != len(cols) {
+	if e.SelectExec.Schema().Len()+hiddenColumnsCount(cols) != len(cols) {
 		return nil, ErrWrongValueCountOnRow.GenByArgs(1)
 	}
 	var rows [][]types.Datum
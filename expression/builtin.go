@@ -150,10 +150,15 @@ func newBaseBuiltinFuncWithTp(args []Expression, ctx context.Context, retType ev
 			Flag:    mysql.BinaryFlag,
 		}
 	case tpString:
+		argFieldTps := make([]*types.FieldType, 0, len(args))
+		for _, arg := range args {
+			argFieldTps = append(argFieldTps, arg.GetType())
+		}
 		fieldType = &types.FieldType{
-			Tp:      mysql.TypeVarString,
-			Flen:    0,
-			Decimal: types.UnspecifiedLength,
+			Tp:         mysql.TypeVarString,
+			Flen:       0,
+			Decimal:    types.UnspecifiedLength,
+			Derivation: types.AggDerivation(argFieldTps),
 		}
 	case tpDatetime:
 		fieldType = &types.FieldType{
@@ -189,7 +194,7 @@ func newBaseBuiltinFuncWithTp(args []Expression, ctx context.Context, retType ev
 	if mysql.HasBinaryFlag(fieldType.Flag) && fieldType.Tp != mysql.TypeJSON {
 		fieldType.Charset, fieldType.Collate = charset.CharsetBin, charset.CollationBin
 	} else {
-		fieldType.Charset, fieldType.Collate = charset.CharsetUTF8, charset.CharsetUTF8
+		fieldType.Charset, fieldType.Collate = charset.CharsetUTF8, charset.CollationUTF8
 	}
 	return baseBuiltinFunc{
 		args:      args,
@@ -924,6 +929,7 @@ var funcs = map[string]functionClass{
 	ast.RowFunc:    &rowFunctionClass{baseFunctionClass{ast.RowFunc, 2, -1}},
 	ast.SetVar:     &setVarFunctionClass{baseFunctionClass{ast.SetVar, 2, 2}},
 	ast.GetVar:     &getVarFunctionClass{baseFunctionClass{ast.GetVar, 1, 1}},
+	ast.GetParam:   &getParamFunctionClass{baseFunctionClass{ast.GetParam, 1, 1}},
 	ast.BitCount:   &bitCountFunctionClass{baseFunctionClass{ast.BitCount, 1, 1}},
 
 	// encryption and compression functions
@@ -947,14 +953,39 @@ var funcs = map[string]functionClass{
 	ast.ValidatePasswordStrength: &validatePasswordStrengthFunctionClass{baseFunctionClass{ast.ValidatePasswordStrength, 1, 1}},
 
 	// json functions
-	ast.JSONType:    &jsonTypeFunctionClass{baseFunctionClass{ast.JSONType, 1, 1}},
-	ast.JSONExtract: &jsonExtractFunctionClass{baseFunctionClass{ast.JSONExtract, 2, -1}},
-	ast.JSONUnquote: &jsonUnquoteFunctionClass{baseFunctionClass{ast.JSONUnquote, 1, 1}},
-	ast.JSONSet:     &jsonSetFunctionClass{baseFunctionClass{ast.JSONSet, 3, -1}},
-	ast.JSONInsert:  &jsonInsertFunctionClass{baseFunctionClass{ast.JSONInsert, 3, -1}},
-	ast.JSONReplace: &jsonReplaceFunctionClass{baseFunctionClass{ast.JSONReplace, 3, -1}},
-	ast.JSONRemove:  &jsonRemoveFunctionClass{baseFunctionClass{ast.JSONRemove, 2, -1}},
-	ast.JSONMerge:   &jsonMergeFunctionClass{baseFunctionClass{ast.JSONMerge, 2, -1}},
-	ast.JSONObject:  &jsonObjectFunctionClass{baseFunctionClass{ast.JSONObject, 2, -1}},
-	ast.JSONArray:   &jsonArrayFunctionClass{baseFunctionClass{ast.JSONArray, 1, -1}},
+	ast.JSONType:         &jsonTypeFunctionClass{baseFunctionClass{ast.JSONType, 1, 1}},
+	ast.JSONExtract:      &jsonExtractFunctionClass{baseFunctionClass{ast.JSONExtract, 2, -1}},
+	ast.JSONUnquote:      &jsonUnquoteFunctionClass{baseFunctionClass{ast.JSONUnquote, 1, 1}},
+	ast.JSONSet:          &jsonSetFunctionClass{baseFunctionClass{ast.JSONSet, 3, -1}},
+	ast.JSONInsert:       &jsonInsertFunctionClass{baseFunctionClass{ast.JSONInsert, 3, -1}},
+	ast.JSONReplace:      &jsonReplaceFunctionClass{baseFunctionClass{ast.JSONReplace, 3, -1}},
+	ast.JSONRemove:       &jsonRemoveFunctionClass{baseFunctionClass{ast.JSONRemove, 2, -1}},
+	ast.JSONMerge:        &jsonMergeFunctionClass{baseFunctionClass{ast.JSONMerge, 2, -1}},
+	ast.JSONObject:       &jsonObjectFunctionClass{baseFunctionClass{ast.JSONObject, 2, -1}},
+	ast.JSONArray:        &jsonArrayFunctionClass{baseFunctionClass{ast.JSONArray, 1, -1}},
+	ast.JSONValid:        &jsonValidFunctionClass{baseFunctionClass{ast.JSONValid, 1, 1}},
+	ast.JSONContains:     &jsonContainsFunctionClass{baseFunctionClass{ast.JSONContains, 2, 3}},
+	ast.JSONContainsPath: &jsonContainsPathFunctionClass{baseFunctionClass{ast.JSONContainsPath, 3, -1}},
+	ast.JSONKeys:         &jsonKeysFunctionClass{baseFunctionClass{ast.JSONKeys, 1, 2}},
+	ast.JSONLength:       &jsonLengthFunctionClass{baseFunctionClass{ast.JSONLength, 1, 2}},
+	ast.JSONDepth:        &jsonDepthFunctionClass{baseFunctionClass{ast.JSONDepth, 1, 1}},
+	ast.JSONSearch:       &jsonSearchFunctionClass{baseFunctionClass{ast.JSONSearch, 3, -1}},
+	ast.JSONArrayAppend:  &jsonArrayAppendFunctionClass{baseFunctionClass{ast.JSONArrayAppend, 3, -1}},
+
+	// spatial functions
+	ast.MBRContains:    &mbrContainsFunctionClass{baseFunctionClass{ast.MBRContains, 2, 2}},
+	ast.MBRIntersects:  &mbrIntersectsFunctionClass{baseFunctionClass{ast.MBRIntersects, 2, 2}},
+	ast.MBRWithin:      &mbrWithinFunctionClass{baseFunctionClass{ast.MBRWithin, 2, 2}},
+	ast.Point:          &pointFunctionClass{baseFunctionClass{ast.Point, 2, 2}},
+	ast.STAsBinary:     &stAsBinaryFunctionClass{baseFunctionClass{ast.STAsBinary, 1, 1}},
+	ast.STAsText:       &stAsTextFunctionClass{baseFunctionClass{ast.STAsText, 1, 1}},
+	ast.STContains:     &stContainsFunctionClass{baseFunctionClass{ast.STContains, 2, 2}},
+	ast.STDistance:     &stDistanceFunctionClass{baseFunctionClass{ast.STDistance, 2, 2}},
+	ast.STEnvelope:     &stEnvelopeFunctionClass{baseFunctionClass{ast.STEnvelope, 1, 1}},
+	ast.STGeomFromText: &stGeomFromTextFunctionClass{baseFunctionClass{ast.STGeomFromText, 1, 2}},
+	ast.STGeomFromWKB:  &stGeomFromWKBFunctionClass{baseFunctionClass{ast.STGeomFromWKB, 1, 2}},
+	ast.STGeometryType: &stGeometryTypeFunctionClass{baseFunctionClass{ast.STGeometryType, 1, 1}},
+	ast.STWithin:       &stWithinFunctionClass{baseFunctionClass{ast.STWithin, 2, 2}},
+	ast.STX:            &stXFunctionClass{baseFunctionClass{ast.STX, 1, 1}},
+	ast.STY:            &stYFunctionClass{baseFunctionClass{ast.STY, 1, 1}},
 }
@@ -1339,6 +1339,8 @@ func WrapWithCastAsString(expr Expression, ctx context.Context) Expression {
 	tp := types.NewFieldType(mysql.TypeVarString)
 	tp.Charset, tp.Collate = charset.CharsetUTF8, charset.CollationUTF8
 	tp.Flen, tp.Decimal = expr.GetType().Flen, types.UnspecifiedLength
+	// The implicit cast doesn't change the derivation of expr.
+	tp.Derivation = expr.GetType().GetDerivation()
 	return buildCastFunction(expr, tp, ctx)
 }
 
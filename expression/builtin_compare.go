@@ -518,6 +518,9 @@ func (c *compareFunctionClass) refineArgs(args []Expression, ctx context.Context
 	arg1IsInt := args[1].GetTypeClass() == types.ClassInt
 	arg0, arg0IsCon := args[0].(*Constant)
 	arg1, arg1IsCon := args[1].(*Constant)
+	// The deferred constants can't be refined, their values are unknown until they are evaluated.
+	arg0IsCon = arg0IsCon && arg0.DeferredExpr == nil
+	arg1IsCon = arg1IsCon && arg1.DeferredExpr == nil
 	// int non-constant [cmp] non-int constant
 	if arg0IsInt && !arg0IsCon && !arg1IsInt && arg1IsCon {
 		arg1 = refineConstantArg(arg1, c.op, ctx)
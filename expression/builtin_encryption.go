@@ -25,10 +25,19 @@ import (
 	"fmt"
 	"hash"
 	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/encrypt"
 	"github.com/pingcap/tidb/util/types"
@@ -58,13 +67,24 @@ var (
 	_ builtinFunc = &builtinAesDecryptSig{}
 	_ builtinFunc = &builtinAesEncryptSig{}
 	_ builtinFunc = &builtinCompressSig{}
+	_ builtinFunc = &builtinDecodeSig{}
+	_ builtinFunc = &builtinDesDecryptSig{}
+	_ builtinFunc = &builtinDesDecryptWithKeyStrSig{}
+	_ builtinFunc = &builtinDesEncryptSig{}
+	_ builtinFunc = &builtinDesEncryptWithKeyNumSig{}
+	_ builtinFunc = &builtinDesEncryptWithKeyStrSig{}
+	_ builtinFunc = &builtinEncodeSig{}
+	_ builtinFunc = &builtinEncryptSig{}
+	_ builtinFunc = &builtinEncryptWithSaltSig{}
 	_ builtinFunc = &builtinMD5Sig{}
+	_ builtinFunc = &builtinOldPasswordSig{}
 	_ builtinFunc = &builtinPasswordSig{}
 	_ builtinFunc = &builtinRandomBytesSig{}
 	_ builtinFunc = &builtinSHA1Sig{}
 	_ builtinFunc = &builtinSHA2Sig{}
 	_ builtinFunc = &builtinUncompressSig{}
 	_ builtinFunc = &builtinUncompressedLengthSig{}
+	_ builtinFunc = &builtinValidatePasswordStrengthSig{}
 )
 
 // TODO: support other mode
@@ -162,7 +182,33 @@ type decodeFunctionClass struct {
 }
 
 func (c *decodeFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("DECODE")
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, tpString, tpString)
+	bf.tp.Flen = args[0].GetType().Flen
+	types.SetBinChsClnFlag(bf.tp)
+	sig := &builtinDecodeSig{baseStringBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinDecodeSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals DECODE(crypt_str, pass_str).
+// See https://dev.mysql.com/doc/refman/5.6/en/encryption-functions.html#function_decode
+func (b *builtinDecodeSig) evalString(row []types.Datum) (string, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	cryptStr, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	passStr, isNull, err := b.args[1].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	return string(encrypt.NewSQLCrypt([]byte(passStr)).Decode([]byte(cryptStr))), false, nil
 }
 
 type desDecryptFunctionClass struct {
@@ -170,7 +216,81 @@ type desDecryptFunctionClass struct {
 }
 
 func (c *desDecryptFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("DES_DECRYPT")
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := make([]evalTp, 0, len(args))
+	for range args {
+		argTps = append(argTps, tpString)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, argTps...)
+	bf.tp.Flen = args[0].GetType().Flen
+	types.SetBinChsClnFlag(bf.tp)
+	var sig builtinFunc
+	if len(args) == 1 {
+		sig = &builtinDesDecryptSig{baseStringBuiltinFunc{bf}}
+	} else {
+		sig = &builtinDesDecryptWithKeyStrSig{baseStringBuiltinFunc{bf}}
+	}
+	return sig.setSelf(sig), nil
+}
+
+type builtinDesDecryptSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals DES_DECRYPT(crypt_str), the key is the one in the DES key file whose
+// number is stored in crypt_str.
+// See https://dev.mysql.com/doc/refman/5.6/en/encryption-functions.html#function_des-decrypt
+func (b *builtinDesDecryptSig) evalString(row []types.Datum) (string, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	cryptStr, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	// According to doc: If the crypt_str argument does not appear to be an encrypted string,
+	// MySQL returns the given crypt_str.
+	if !encrypt.IsDESEncrypted([]byte(cryptStr)) {
+		return cryptStr, false, nil
+	}
+	_, key, ok := encrypt.GetDESKey(encrypt.DESKeyNum([]byte(cryptStr)))
+	if !ok {
+		sc.AppendWarning(errIncorrectArgs.GenByArgs("des_decrypt"))
+		return "", true, nil
+	}
+	return desDecrypt(cryptStr, key)
+}
+
+type builtinDesDecryptWithKeyStrSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals DES_DECRYPT(crypt_str, key_str).
+// See https://dev.mysql.com/doc/refman/5.6/en/encryption-functions.html#function_des-decrypt
+func (b *builtinDesDecryptWithKeyStrSig) evalString(row []types.Datum) (string, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	cryptStr, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	if !encrypt.IsDESEncrypted([]byte(cryptStr)) {
+		return cryptStr, false, nil
+	}
+	keyStr, isNull, err := b.args[1].EvalString(row, sc)
+	if isNull || err != nil {
+		sc.AppendWarning(errIncorrectArgs.GenByArgs("des_decrypt"))
+		return "", true, errors.Trace(err)
+	}
+	return desDecrypt(cryptStr, encrypt.DeriveDESKey([]byte(keyStr)))
+}
+
+func desDecrypt(cryptStr string, key []byte) (string, bool, error) {
+	plainText, err := encrypt.DESDecrypt([]byte(cryptStr), key)
+	if err != nil {
+		// The key is wrong.
+		return "", true, nil
+	}
+	return string(plainText), false, nil
 }
 
 type desEncryptFunctionClass struct {
@@ -178,7 +298,119 @@ type desEncryptFunctionClass struct {
 }
 
 func (c *desEncryptFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("DES_ENCRYPT")
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := []evalTp{tpString}
+	if len(args) == 2 {
+		if fieldTp2EvalTp(args[1].GetType()) == tpInt {
+			argTps = append(argTps, tpInt)
+		} else {
+			argTps = append(argTps, tpString)
+		}
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, argTps...)
+	// The result contains a byte of key number and at most 8 bytes of padding.
+	bf.tp.Flen = args[0].GetType().Flen + 9
+	types.SetBinChsClnFlag(bf.tp)
+	var sig builtinFunc
+	switch {
+	case len(args) == 1:
+		sig = &builtinDesEncryptSig{baseStringBuiltinFunc{bf}}
+	case argTps[1] == tpInt:
+		sig = &builtinDesEncryptWithKeyNumSig{baseStringBuiltinFunc{bf}}
+	default:
+		sig = &builtinDesEncryptWithKeyStrSig{baseStringBuiltinFunc{bf}}
+	}
+	return sig.setSelf(sig), nil
+}
+
+type builtinDesEncryptSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals DES_ENCRYPT(str), the first key in the DES key file is used.
+// See https://dev.mysql.com/doc/refman/5.6/en/encryption-functions.html#function_des-encrypt
+func (b *builtinDesEncryptSig) evalString(row []types.Datum) (string, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	str, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	if len(str) == 0 {
+		return "", false, nil
+	}
+	keyNum, key, ok := encrypt.GetDESKey(-1)
+	if !ok {
+		sc.AppendWarning(errIncorrectArgs.GenByArgs("des_encrypt"))
+		return "", true, nil
+	}
+	return desEncrypt(str, key, keyNum)
+}
+
+type builtinDesEncryptWithKeyNumSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals DES_ENCRYPT(str, key_num), key_num is the number of the key in the DES key file.
+// See https://dev.mysql.com/doc/refman/5.6/en/encryption-functions.html#function_des-encrypt
+func (b *builtinDesEncryptWithKeyNumSig) evalString(row []types.Datum) (string, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	str, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	if len(str) == 0 {
+		return "", false, nil
+	}
+	keyNum, isNull, err := b.args[1].EvalInt(row, sc)
+	if err != nil {
+		return "", true, errors.Trace(err)
+	}
+	if isNull || keyNum < 0 || keyNum > encrypt.MaxDESKeyNum {
+		sc.AppendWarning(errIncorrectArgs.GenByArgs("des_encrypt"))
+		return "", true, nil
+	}
+	_, key, ok := encrypt.GetDESKey(int(keyNum))
+	if !ok {
+		sc.AppendWarning(errIncorrectArgs.GenByArgs("des_encrypt"))
+		return "", true, nil
+	}
+	return desEncrypt(str, key, int(keyNum))
+}
+
+type builtinDesEncryptWithKeyStrSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals DES_ENCRYPT(str, key_str).
+// See https://dev.mysql.com/doc/refman/5.6/en/encryption-functions.html#function_des-encrypt
+func (b *builtinDesEncryptWithKeyStrSig) evalString(row []types.Datum) (string, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	str, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	if len(str) == 0 {
+		return "", false, nil
+	}
+	keyStr, isNull, err := b.args[1].EvalString(row, sc)
+	if err != nil {
+		return "", true, errors.Trace(err)
+	}
+	if isNull {
+		sc.AppendWarning(errIncorrectArgs.GenByArgs("des_encrypt"))
+		return "", true, nil
+	}
+	return desEncrypt(str, encrypt.DeriveDESKey([]byte(keyStr)), encrypt.DESUserKeyNum)
+}
+
+func desEncrypt(str string, key []byte, keyNum int) (string, bool, error) {
+	cipherText, err := encrypt.DESEncrypt([]byte(str), key, keyNum)
+	if err != nil {
+		return "", true, errors.Trace(err)
+	}
+	return string(cipherText), false, nil
 }
 
 type encodeFunctionClass struct {
@@ -186,7 +418,33 @@ type encodeFunctionClass struct {
 }
 
 func (c *encodeFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("ENCODE")
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, tpString, tpString)
+	bf.tp.Flen = args[0].GetType().Flen
+	types.SetBinChsClnFlag(bf.tp)
+	sig := &builtinEncodeSig{baseStringBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinEncodeSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals ENCODE(str, pass_str).
+// See https://dev.mysql.com/doc/refman/5.6/en/encryption-functions.html#function_encode
+func (b *builtinEncodeSig) evalString(row []types.Datum) (string, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	str, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	passStr, isNull, err := b.args[1].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	return string(encrypt.NewSQLCrypt([]byte(passStr)).Encode([]byte(str))), false, nil
 }
 
 type encryptFunctionClass struct {
@@ -194,7 +452,67 @@ type encryptFunctionClass struct {
 }
 
 func (c *encryptFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("ENCRYPT")
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := make([]evalTp, 0, len(args))
+	for range args {
+		argTps = append(argTps, tpString)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, argTps...)
+	bf.tp.Flen = 13
+	var sig builtinFunc
+	if len(args) == 1 {
+		sig = &builtinEncryptSig{baseStringBuiltinFunc{bf}}
+	} else {
+		sig = &builtinEncryptWithSaltSig{baseStringBuiltinFunc{bf}}
+	}
+	return sig.setSelf(sig), nil
+}
+
+type builtinEncryptSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals ENCRYPT(str), the salt is generated from the current time.
+// See https://dev.mysql.com/doc/refman/5.6/en/encryption-functions.html#function_encrypt
+func (b *builtinEncryptSig) evalString(row []types.Datum) (string, bool, error) {
+	str, isNull, err := b.args[0].EvalString(row, b.ctx.GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	return unixCrypt(str, encrypt.CryptSalt(time.Now().Unix()))
+}
+
+type builtinEncryptWithSaltSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals ENCRYPT(str, salt).
+// See https://dev.mysql.com/doc/refman/5.6/en/encryption-functions.html#function_encrypt
+func (b *builtinEncryptWithSaltSig) evalString(row []types.Datum) (string, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	str, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	salt, isNull, err := b.args[1].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	return unixCrypt(str, []byte(salt))
+}
+
+func unixCrypt(str string, salt []byte) (string, bool, error) {
+	if len(str) == 0 {
+		return "", false, nil
+	}
+	crypted, err := encrypt.UnixCrypt([]byte(str), salt)
+	if err != nil {
+		// The salt is invalid, crypt(3) fails.
+		return "", true, nil
+	}
+	return crypted, false, nil
 }
 
 type oldPasswordFunctionClass struct {
@@ -202,7 +520,30 @@ type oldPasswordFunctionClass struct {
 }
 
 func (c *oldPasswordFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("OLD_PASSWORD")
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, tpString)
+	bf.tp.Flen = 16
+	sig := &builtinOldPasswordSig{baseStringBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinOldPasswordSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals OLD_PASSWORD(str).
+// See https://dev.mysql.com/doc/refman/5.6/en/encryption-functions.html#function_old-password
+func (b *builtinOldPasswordSig) evalString(row []types.Datum) (string, bool, error) {
+	pass, isNull, err := b.args[0].EvalString(row, b.ctx.GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	if len(pass) == 0 {
+		return "", false, nil
+	}
+	return encrypt.OldPassword([]byte(pass)), false, nil
 }
 
 type passwordFunctionClass struct {
@@ -570,5 +911,178 @@ type validatePasswordStrengthFunctionClass struct {
 }
 
 func (c *validatePasswordStrengthFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("VALIDATE_PASSWORD_STRENGTH")
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	policy, err := loadPasswordPolicy(ctx.GetSessionVars())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, tpString)
+	bf.tp.Flen = 3
+	sig := &builtinValidatePasswordStrengthSig{baseIntBuiltinFunc{bf}, policy}
+	return sig.setSelf(sig), nil
+}
+
+type builtinValidatePasswordStrengthSig struct {
+	baseIntBuiltinFunc
+	policy *passwordPolicy
+}
+
+const (
+	// minDictionaryWordLength is the min length of the password and the dictionary words.
+	minDictionaryWordLength = 4
+	// maxDictionaryWordLength is the max length of the substrings of the password looked up
+	// in the dictionary.
+	maxDictionaryWordLength = 100
+	// passwordScore is the score of each password policy.
+	passwordScore = 25
+)
+
+// passwordPolicy holds the validate_password_* global variables, they are read once when
+// the function is built, rather than for every row.
+type passwordPolicy struct {
+	length         int64
+	mixedCaseCount int64
+	specialCount   int64
+	numberCount    int64
+	dictFile       string
+}
+
+func loadPasswordPolicy(sessVars *variable.SessionVars) (*passwordPolicy, error) {
+	policy := &passwordPolicy{}
+	for _, v := range []struct {
+		name string
+		val  *int64
+	}{
+		{variable.ValidatePasswordLength, &policy.length},
+		{variable.ValidatePasswordMixedCaseCount, &policy.mixedCaseCount},
+		{variable.ValidatePasswordSpecialCharCount, &policy.specialCount},
+		{variable.ValidatePasswordNumberCount, &policy.numberCount},
+	} {
+		val, err := varsutil.GetGlobalSystemVar(sessVars, v.name)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		*v.val, err = strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	var err error
+	policy.dictFile, err = varsutil.GetGlobalSystemVar(sessVars, variable.ValidatePasswordDictionaryFile)
+	return policy, errors.Trace(err)
+}
+
+// evalInt evals VALIDATE_PASSWORD_STRENGTH(str). The score is 0 if the password is shorter
+// than 4 characters, 25 if it's shorter than validate_password_length, and 50, 75 or 100
+// if it satisfies the LOW, MEDIUM or STRONG policy which is configured by the
+// validate_password_* global variables.
+// See https://dev.mysql.com/doc/refman/5.6/en/encryption-functions.html#function_validate-password-strength
+func (b *builtinValidatePasswordStrengthSig) evalInt(row []types.Datum) (int64, bool, error) {
+	pass, isNull, err := b.args[0].EvalString(row, b.ctx.GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+
+	var nChars, lower, upper, digit, special int64
+	for _, r := range pass {
+		nChars++
+		switch {
+		case unicode.IsLower(r):
+			lower++
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsDigit(r):
+			digit++
+		default:
+			special++
+		}
+	}
+	if nChars < minDictionaryWordLength {
+		return 0, false, nil
+	}
+	if nChars < b.policy.length {
+		return passwordScore, false, nil
+	}
+
+	// The password satisfies the LOW policy.
+	score := int64(2 * passwordScore)
+	if upper < b.policy.mixedCaseCount || lower < b.policy.mixedCaseCount ||
+		special < b.policy.specialCount || digit < b.policy.numberCount {
+		return score, false, nil
+	}
+
+	// The password satisfies the MEDIUM policy.
+	score += passwordScore
+	inDict, err := passwordInDictionary(b.policy.dictFile, pass)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	if !inDict {
+		// The password satisfies the STRONG policy.
+		score += passwordScore
+	}
+	return score, false, nil
+}
+
+// passwordDictionary caches the words of the last loaded password dictionary file, it's
+// reloaded when validate_password_dictionary_file or the file itself is changed.
+var passwordDictionary = struct {
+	sync.Mutex
+	file    string
+	modTime time.Time
+	size    int64
+	words   map[string]struct{}
+}{}
+
+func loadPasswordDictionary(dictFile string) (map[string]struct{}, error) {
+	info, err := os.Stat(dictFile)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	passwordDictionary.Lock()
+	defer passwordDictionary.Unlock()
+	if passwordDictionary.words != nil && passwordDictionary.file == dictFile &&
+		passwordDictionary.modTime.Equal(info.ModTime()) && passwordDictionary.size == info.Size() {
+		return passwordDictionary.words, nil
+	}
+	content, err := ioutil.ReadFile(dictFile)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	words := make(map[string]struct{})
+	for _, word := range strings.Split(string(content), "\n") {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if len(word) > 0 {
+			words[word] = struct{}{}
+		}
+	}
+	passwordDictionary.file = dictFile
+	passwordDictionary.modTime = info.ModTime()
+	passwordDictionary.size = info.Size()
+	passwordDictionary.words = words
+	return words, nil
+}
+
+// passwordInDictionary checks whether any substring of the password whose length is in
+// [minDictionaryWordLength, maxDictionaryWordLength] is a word of the dictionary file,
+// the check is case insensitive.
+func passwordInDictionary(dictFile, pass string) (bool, error) {
+	if dictFile == "" {
+		return false, nil
+	}
+	words, err := loadPasswordDictionary(dictFile)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	runes := []rune(strings.ToLower(pass))
+	for i := range runes {
+		for l := minDictionaryWordLength; l <= maxDictionaryWordLength && i+l <= len(runes); l++ {
+			if _, ok := words[string(runes[i:i+l])]; ok {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
 }
@@ -15,10 +15,13 @@ package expression
 
 import (
 	"encoding/hex"
+	"io/ioutil"
+	"os"
 	"strings"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/util/encrypt"
 	"github.com/pingcap/tidb/util/hack"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
@@ -362,3 +365,136 @@ func (s *testEvaluatorSuite) TestPassword(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(f.canBeFolded(), IsTrue)
 }
+
+func (s *testEvaluatorSuite) TestDESEncryptDecrypt(c *C) {
+	defer testleak.AfterTest(c)()
+	f, err := ioutil.TempFile("", "des_key_file")
+	c.Assert(err, IsNil)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("3 key3\n5 key5\n")
+	c.Assert(err, IsNil)
+	f.Close()
+	c.Assert(encrypt.LoadDESKeyFile(f.Name()), IsNil)
+
+	tests := []struct {
+		args   []interface{}
+		expect interface{}
+	}{
+		{[]interface{}{"hello", "key"}, "FFB6D0B8D9BBB00BEC"},
+		{[]interface{}{"", "key"}, ""},
+		{[]interface{}{nil, "key"}, nil},
+		{[]interface{}{"hello", nil}, nil},
+		{[]interface{}{"hello", 10}, nil},
+		{[]interface{}{"hello", 4}, nil},
+	}
+	for _, t := range tests {
+		f, err := newFunctionForTest(s.ctx, ast.DesEncrypt, primitiveValsToConstants(t.args)...)
+		c.Assert(err, IsNil)
+		d, err := f.Eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(toHex(d), DeepEquals, types.NewDatum(t.expect))
+	}
+
+	// The key number is stored in the result, and the first key is the default one.
+	for _, args := range [][]interface{}{{"hello"}, {"hello", 5}, {"hello", "key"}} {
+		f, err := newFunctionForTest(s.ctx, ast.DesEncrypt, primitiveValsToConstants(args)...)
+		c.Assert(err, IsNil)
+		crypted, err := f.Eval(nil)
+		c.Assert(err, IsNil)
+		f, err = newFunctionForTest(s.ctx, ast.DesDecrypt, datumsToConstants([]types.Datum{crypted})...)
+		c.Assert(err, IsNil)
+		d, err := f.Eval(nil)
+		c.Assert(err, IsNil)
+		if len(args) == 2 && args[1] == "key" {
+			// The user key isn't in the key file.
+			c.Assert(d.IsNull(), IsTrue)
+		} else {
+			c.Assert(d.GetString(), Equals, "hello")
+		}
+	}
+
+	crypted := fromHex("FFB6D0B8D9BBB00BEC")
+	tests = []struct {
+		args   []interface{}
+		expect interface{}
+	}{
+		{[]interface{}{crypted.GetString(), "key"}, "hello"},
+		{[]interface{}{crypted.GetString(), "wrong"}, nil},
+		{[]interface{}{"not encrypted", "key"}, "not encrypted"},
+		{[]interface{}{"not encrypted"}, "not encrypted"},
+		{[]interface{}{nil, "key"}, nil},
+	}
+	for _, t := range tests {
+		f, err := newFunctionForTest(s.ctx, ast.DesDecrypt, primitiveValsToConstants(t.args)...)
+		c.Assert(err, IsNil)
+		d, err := f.Eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, DeepEquals, types.NewDatum(t.expect))
+	}
+}
+
+func (s *testEvaluatorSuite) TestEncodeDecode(c *C) {
+	defer testleak.AfterTest(c)()
+	for _, str := range []interface{}{"", "hello", "你好", nil} {
+		f, err := newFunctionForTest(s.ctx, ast.Encode, primitiveValsToConstants([]interface{}{str, "pass"})...)
+		c.Assert(err, IsNil)
+		encoded, err := f.Eval(nil)
+		c.Assert(err, IsNil)
+		f, err = newFunctionForTest(s.ctx, ast.Decode, datumsToConstants([]types.Datum{encoded, types.NewDatum("pass")})...)
+		c.Assert(err, IsNil)
+		d, err := f.Eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, DeepEquals, types.NewDatum(str))
+	}
+	s.testNullInput(c, ast.Encode)
+	s.testNullInput(c, ast.Decode)
+}
+
+func (s *testEvaluatorSuite) TestEncrypt(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		args   []interface{}
+		expect interface{}
+	}{
+		{[]interface{}{"test", "ab"}, "abgOeLfPimXQo"},
+		{[]interface{}{"test", "abcd"}, "abgOeLfPimXQo"},
+		{[]interface{}{"", "ab"}, ""},
+		{[]interface{}{"test", "a"}, nil},
+		{[]interface{}{"test", "!!"}, nil},
+		{[]interface{}{nil, "ab"}, nil},
+		{[]interface{}{"test", nil}, nil},
+	}
+	for _, t := range tests {
+		f, err := newFunctionForTest(s.ctx, ast.Encrypt, primitiveValsToConstants(t.args)...)
+		c.Assert(err, IsNil)
+		d, err := f.Eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, DeepEquals, types.NewDatum(t.expect))
+	}
+
+	f, err := newFunctionForTest(s.ctx, ast.Encrypt, primitiveValsToConstants([]interface{}{"test"})...)
+	c.Assert(err, IsNil)
+	d, err := f.Eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetString(), HasLen, 13)
+}
+
+func (s *testEvaluatorSuite) TestOldPassword(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		arg    interface{}
+		expect interface{}
+	}{
+		{"mypass", "6f8c114b58f2ce9e"},
+		{"", ""},
+		{123, "773359240eb9a1d9"},
+		{nil, nil},
+	}
+	for _, t := range tests {
+		f, err := newFunctionForTest(s.ctx, ast.OldPassword, primitiveValsToConstants([]interface{}{t.arg})...)
+		c.Assert(err, IsNil)
+		d, err := f.Eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, DeepEquals, types.NewDatum(t.expect))
+	}
+}
@@ -43,6 +43,10 @@ var (
 
 var (
 	_ builtinFunc = &builtinDatabaseSig{}
+	_ builtinFunc = &builtinBenchmarkSig{}
+	_ builtinFunc = &builtinCharsetSig{}
+	_ builtinFunc = &builtinCoercibilitySig{}
+	_ builtinFunc = &builtinCollationSig{}
 	_ builtinFunc = &builtinFoundRowsSig{}
 	_ builtinFunc = &builtinCurrentUserSig{}
 	_ builtinFunc = &builtinUserSig{}
@@ -63,6 +67,7 @@ func (c *databaseFunctionClass) getFunction(ctx context.Context, args []Expressi
 	}
 	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString)
 	bf.tp.Flen = 64
+	bf.tp.Derivation = types.DerivationSysconst
 	bf.foldable = false
 	sig := &builtinDatabaseSig{baseStringBuiltinFunc{bf}}
 	return sig.setSelf(sig), nil
@@ -119,6 +124,7 @@ func (c *currentUserFunctionClass) getFunction(ctx context.Context, args []Expre
 	}
 	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString)
 	bf.tp.Flen = 64
+	bf.tp.Derivation = types.DerivationSysconst
 	bf.foldable = false
 	sig := &builtinCurrentUserSig{baseStringBuiltinFunc{bf}}
 	return sig.setSelf(sig), nil
@@ -151,6 +157,7 @@ func (c *userFunctionClass) getFunction(ctx context.Context, args []Expression)
 	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString)
 	bf.foldable = false
 	bf.tp.Flen = 64
+	bf.tp.Derivation = types.DerivationSysconst
 	sig := &builtinUserSig{baseStringBuiltinFunc{bf}}
 	return sig.setSelf(sig), nil
 }
@@ -260,6 +267,7 @@ func (c *versionFunctionClass) getFunction(ctx context.Context, args []Expressio
 	}
 	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString)
 	bf.tp.Flen = 64
+	bf.tp.Derivation = types.DerivationSysconst
 	bf.foldable = false
 	sig := &builtinVersionSig{baseStringBuiltinFunc{bf}}
 	return sig.setSelf(sig), nil
@@ -285,6 +293,7 @@ func (c *tidbVersionFunctionClass) getFunction(ctx context.Context, args []Expre
 	}
 	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString)
 	bf.tp.Flen = len(printer.GetTiDBInfo())
+	bf.tp.Derivation = types.DerivationSysconst
 	sig := &builtinTiDBVersionSig{baseStringBuiltinFunc{bf}}
 	return sig.setSelf(sig), nil
 }
@@ -304,7 +313,39 @@ type benchmarkFunctionClass struct {
 }
 
 func (c *benchmarkFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("BENCHMARK")
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The expression is evaluated as it is, so it isn't cast to another type.
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, tpInt, fieldTp2EvalTp(args[1].GetType()))
+	bf.tp.Flen = 1
+	bf.foldable = false
+	sig := &builtinBenchmarkSig{baseIntBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinBenchmarkSig struct {
+	baseIntBuiltinFunc
+}
+
+// evalInt evals BENCHMARK(count, expr), it evaluates expr count times and always returns 0.
+// See https://dev.mysql.com/doc/refman/5.7/en/information-functions.html#function_benchmark
+func (b *builtinBenchmarkSig) evalInt(row []types.Datum) (int64, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	count, isNull, err := b.args[0].EvalInt(row, sc)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	if count < 0 {
+		sc.AppendWarning(errIncorrectArgs.GenByArgs("benchmark"))
+		return 0, true, nil
+	}
+	for i := int64(0); i < count; i++ {
+		if _, err = b.args[1].Eval(row); err != nil {
+			return 0, true, errors.Trace(err)
+		}
+	}
+	return 0, false, nil
 }
 
 type charsetFunctionClass struct {
@@ -312,7 +353,28 @@ type charsetFunctionClass struct {
 }
 
 func (c *charsetFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("CHARSET")
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The result only depends on the type of the argument, which isn't evaluated.
+	chs, _ := argCharsetAndCollation(args[0])
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, fieldTp2EvalTp(args[0].GetType()))
+	bf.tp.Flen = 64
+	bf.tp.Derivation = types.DerivationSysconst
+	sig := &builtinCharsetSig{baseStringBuiltinFunc{bf}, chs}
+	return sig.setSelf(sig), nil
+}
+
+type builtinCharsetSig struct {
+	baseStringBuiltinFunc
+
+	charset string
+}
+
+// evalString evals CHARSET(str).
+// See https://dev.mysql.com/doc/refman/5.7/en/information-functions.html#function_charset
+func (b *builtinCharsetSig) evalString(_ []types.Datum) (string, bool, error) {
+	return b.charset, false, nil
 }
 
 type coercibilityFunctionClass struct {
@@ -320,7 +382,27 @@ type coercibilityFunctionClass struct {
 }
 
 func (c *coercibilityFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("COERCIBILITY")
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The result only depends on the type of the argument, which isn't evaluated.
+	coercibility := args[0].GetType().GetDerivation().Coercibility()
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, fieldTp2EvalTp(args[0].GetType()))
+	bf.tp.Flen = 1
+	sig := &builtinCoercibilitySig{baseIntBuiltinFunc{bf}, coercibility}
+	return sig.setSelf(sig), nil
+}
+
+type builtinCoercibilitySig struct {
+	baseIntBuiltinFunc
+
+	coercibility int64
+}
+
+// evalInt evals COERCIBILITY(str).
+// See https://dev.mysql.com/doc/refman/5.7/en/information-functions.html#function_coercibility
+func (b *builtinCoercibilitySig) evalInt(_ []types.Datum) (int64, bool, error) {
+	return b.coercibility, false, nil
 }
 
 type collationFunctionClass struct {
@@ -328,7 +410,38 @@ type collationFunctionClass struct {
 }
 
 func (c *collationFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("COLLATION")
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The result only depends on the type of the argument, which isn't evaluated.
+	_, collation := argCharsetAndCollation(args[0])
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, fieldTp2EvalTp(args[0].GetType()))
+	bf.tp.Flen = 64
+	bf.tp.Derivation = types.DerivationSysconst
+	sig := &builtinCollationSig{baseStringBuiltinFunc{bf}, collation}
+	return sig.setSelf(sig), nil
+}
+
+type builtinCollationSig struct {
+	baseStringBuiltinFunc
+
+	collation string
+}
+
+// evalString evals COLLATION(str).
+// See https://dev.mysql.com/doc/refman/5.7/en/information-functions.html#function_collation
+func (b *builtinCollationSig) evalString(_ []types.Datum) (string, bool, error) {
+	return b.collation, false, nil
+}
+
+// argCharsetAndCollation returns the charset and collation of the argument, the default ones
+// of the type are used if they are unspecified.
+func argCharsetAndCollation(arg Expression) (string, string) {
+	tp := arg.GetType()
+	if tp.Charset == "" {
+		return types.DefaultCharsetForType(tp.Tp)
+	}
+	return tp.Charset, tp.Collate
 }
 
 type rowCountFunctionClass struct {
@@ -125,34 +125,86 @@ func (s *testEvaluatorSuite) TestVersion(c *C) {
 
 func (s *testEvaluatorSuite) TestBenchMark(c *C) {
 	defer testleak.AfterTest(c)()
-	fc := funcs[ast.Benchmark]
-	f, err := fc.getFunction(s.ctx, datumsToConstants(types.MakeDatums(nil, nil)))
-	c.Assert(f, IsNil)
-	c.Assert(err, ErrorMatches, "*FUNCTION BENCHMARK does not exist")
+	cases := []struct {
+		count  interface{}
+		expr   interface{}
+		isNil  bool
+		result int64
+	}{
+		{3, "abc", false, 0},
+		{0, 1.5, false, 0},
+		{nil, 1, true, 0},
+		{-1, 1, true, 0},
+	}
+	for _, t := range cases {
+		f, err := newFunctionForTest(s.ctx, ast.Benchmark, primitiveValsToConstants([]interface{}{t.count, t.expr})...)
+		c.Assert(err, IsNil)
+		d, err := f.Eval(nil)
+		c.Assert(err, IsNil)
+		if t.isNil {
+			c.Assert(d.Kind(), Equals, types.KindNull)
+		} else {
+			c.Assert(d.GetInt64(), Equals, t.result)
+		}
+	}
 }
 
 func (s *testEvaluatorSuite) TestCharset(c *C) {
 	defer testleak.AfterTest(c)()
-	fc := funcs[ast.Charset]
-	f, err := fc.getFunction(s.ctx, datumsToConstants(types.MakeDatums(nil)))
-	c.Assert(f, IsNil)
-	c.Assert(err, ErrorMatches, "*FUNCTION CHARSET does not exist")
+	cases := []struct {
+		arg    interface{}
+		result string
+	}{
+		{"abc", charset.CharsetUTF8},
+		{1, charset.CharsetBin},
+		{nil, charset.CharsetBin},
+	}
+	for _, t := range cases {
+		f, err := newFunctionForTest(s.ctx, ast.Charset, primitiveValsToConstants([]interface{}{t.arg})...)
+		c.Assert(err, IsNil)
+		d, err := f.Eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d.GetString(), Equals, t.result)
+	}
 }
 
 func (s *testEvaluatorSuite) TestCoercibility(c *C) {
 	defer testleak.AfterTest(c)()
-	fc := funcs[ast.Coercibility]
-	f, err := fc.getFunction(s.ctx, datumsToConstants(types.MakeDatums(nil)))
-	c.Assert(f, IsNil)
-	c.Assert(err, ErrorMatches, "*FUNCTION COERCIBILITY does not exist")
+	cases := []struct {
+		arg    interface{}
+		result int64
+	}{
+		// The constants here are not typed by the type inferer, so they are implicit as columns.
+		{"abc", 2},
+		{1, 5},
+		{nil, 6},
+	}
+	for _, t := range cases {
+		f, err := newFunctionForTest(s.ctx, ast.Coercibility, primitiveValsToConstants([]interface{}{t.arg})...)
+		c.Assert(err, IsNil)
+		d, err := f.Eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d.GetInt64(), Equals, t.result)
+	}
 }
 
 func (s *testEvaluatorSuite) TestCollation(c *C) {
 	defer testleak.AfterTest(c)()
-	fc := funcs[ast.Collation]
-	f, err := fc.getFunction(s.ctx, datumsToConstants(types.MakeDatums(nil)))
-	c.Assert(f, IsNil)
-	c.Assert(err, ErrorMatches, "*FUNCTION COLLATION does not exist")
+	cases := []struct {
+		arg    interface{}
+		result string
+	}{
+		{"abc", charset.CollationUTF8},
+		{1, charset.CollationBin},
+		{nil, charset.CollationBin},
+	}
+	for _, t := range cases {
+		f, err := newFunctionForTest(s.ctx, ast.Collation, primitiveValsToConstants([]interface{}{t.arg})...)
+		c.Assert(err, IsNil)
+		d, err := f.Eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d.GetString(), Equals, t.result)
+	}
 }
 
 func (s *testEvaluatorSuite) TestRowCount(c *C) {
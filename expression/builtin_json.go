@@ -14,11 +14,14 @@
 package expression
 
 import (
+	"strings"
+
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/stringutil"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tidb/util/types/json"
 	"github.com/pingcap/tipb/go-tipb"
@@ -51,6 +54,14 @@ var (
 	_ functionClass = &jsonMergeFunctionClass{}
 	_ functionClass = &jsonObjectFunctionClass{}
 	_ functionClass = &jsonArrayFunctionClass{}
+	_ functionClass = &jsonValidFunctionClass{}
+	_ functionClass = &jsonContainsFunctionClass{}
+	_ functionClass = &jsonContainsPathFunctionClass{}
+	_ functionClass = &jsonKeysFunctionClass{}
+	_ functionClass = &jsonLengthFunctionClass{}
+	_ functionClass = &jsonDepthFunctionClass{}
+	_ functionClass = &jsonSearchFunctionClass{}
+	_ functionClass = &jsonArrayAppendFunctionClass{}
 )
 
 // argsAnyNull returns true if args contains any null.
@@ -280,6 +291,236 @@ func JSONArray(args []types.Datum, sc *variable.StatementContext) (d types.Datum
 	return
 }
 
+// parseSinglePathExpr parses the path expression used to locate a single value,
+// which cannot contain any wildcard.
+func parseSinglePathExpr(d types.Datum) (json.PathExpression, error) {
+	pathExpr, err := json.ParseJSONPathExpr(d.GetString())
+	if err != nil {
+		return pathExpr, errors.Trace(err)
+	}
+	if pathExpr.ContainsAnyAsterisk() {
+		return pathExpr, json.ErrInvalidJSONPathWildcard
+	}
+	return pathExpr, nil
+}
+
+// extractSinglePath returns the value in the JSON of args[0] located by the optional path args[1].
+// If the path doesn't exist, found is false.
+func extractSinglePath(args []types.Datum, sc *variable.StatementContext) (j json.JSON, found bool, err error) {
+	j, err = datum2JSON(args[0], sc)
+	if err != nil || len(args) == 1 {
+		return j, err == nil, errors.Trace(err)
+	}
+	pathExpr, err := parseSinglePathExpr(args[1])
+	if err != nil {
+		return j, false, errors.Trace(err)
+	}
+	j, found = j.Extract([]json.PathExpression{pathExpr})
+	return j, found, nil
+}
+
+// parseOneOrAll parses the oneOrAll argument of JSON_CONTAINS_PATH and JSON_SEARCH,
+// returns true if it is 'one'.
+func parseOneOrAll(d types.Datum, funcName string) (bool, error) {
+	switch strings.ToLower(d.GetString()) {
+	case "one":
+		return true, nil
+	case "all":
+		return false, nil
+	}
+	return false, json.ErrInvalidJSONContainsPathType.GenByArgs(funcName)
+}
+
+// JSONValid is for json_valid builtin function.
+func JSONValid(args []types.Datum, sc *variable.StatementContext) (d types.Datum, err error) {
+	switch args[0].Kind() {
+	case types.KindNull:
+		return d, nil
+	case types.KindMysqlJSON:
+		d.SetInt64(1)
+	case types.KindString, types.KindBytes:
+		_, err = json.ParseFromString(args[0].GetString())
+		d.SetInt64(boolToInt64(err == nil))
+	default:
+		d.SetInt64(0)
+	}
+	return d, nil
+}
+
+// JSONContains is for json_contains builtin function.
+func JSONContains(args []types.Datum, sc *variable.StatementContext) (d types.Datum, err error) {
+	if argsAnyNull(args) {
+		return d, nil
+	}
+	target, err := datum2JSON(args[1], sc)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	obj, found, err := extractSinglePath(append([]types.Datum{args[0]}, args[2:]...), sc)
+	if err != nil || !found {
+		return d, errors.Trace(err)
+	}
+	d.SetInt64(boolToInt64(json.ContainsJSON(obj, target)))
+	return d, nil
+}
+
+// JSONContainsPath is for json_contains_path builtin function.
+func JSONContainsPath(args []types.Datum, sc *variable.StatementContext) (d types.Datum, err error) {
+	if argsAnyNull(args) {
+		return d, nil
+	}
+	j, err := datum2JSON(args[0], sc)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	one, err := parseOneOrAll(args[1], ast.JSONContainsPath)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	pathExprs, err := parsePathExprs(args[2:])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	for _, pathExpr := range pathExprs {
+		_, found := j.Extract([]json.PathExpression{pathExpr})
+		if found == one {
+			d.SetInt64(boolToInt64(one))
+			return d, nil
+		}
+	}
+	d.SetInt64(boolToInt64(!one))
+	return d, nil
+}
+
+// JSONKeys is for json_keys builtin function.
+func JSONKeys(args []types.Datum, sc *variable.StatementContext) (d types.Datum, err error) {
+	if argsAnyNull(args) {
+		return d, nil
+	}
+	j, found, err := extractSinglePath(args, sc)
+	if err != nil || !found {
+		return d, errors.Trace(err)
+	}
+	if keys, ok := j.Keys(); ok {
+		d.SetMysqlJSON(keys)
+	}
+	return d, nil
+}
+
+// JSONLength is for json_length builtin function.
+func JSONLength(args []types.Datum, sc *variable.StatementContext) (d types.Datum, err error) {
+	if argsAnyNull(args) {
+		return d, nil
+	}
+	j, found, err := extractSinglePath(args, sc)
+	if err != nil || !found {
+		return d, errors.Trace(err)
+	}
+	d.SetInt64(int64(j.Length()))
+	return d, nil
+}
+
+// JSONDepth is for json_depth builtin function.
+func JSONDepth(args []types.Datum, sc *variable.StatementContext) (d types.Datum, err error) {
+	if argsAnyNull(args) {
+		return d, nil
+	}
+	j, err := datum2JSON(args[0], sc)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	d.SetInt64(int64(j.Depth()))
+	return d, nil
+}
+
+// JSONSearch is for json_search builtin function. The arguments are
+// json_doc, one_or_all, search_str[, escape_char[, path] ...].
+func JSONSearch(args []types.Datum, sc *variable.StatementContext) (d types.Datum, err error) {
+	// A null escape_char means the default escape character.
+	if argsAnyNull(args[:3]) || (len(args) > 4 && argsAnyNull(args[4:])) {
+		return d, nil
+	}
+	j, err := datum2JSON(args[0], sc)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	one, err := parseOneOrAll(args[1], ast.JSONSearch)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	search, err := args[2].ToString()
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	escape := byte('\\')
+	if len(args) > 3 && !args[3].IsNull() {
+		switch s := args[3].GetString(); len(s) {
+		case 0:
+		case 1:
+			escape = s[0]
+		default:
+			return d, errIncorrectArgs.GenByArgs("ESCAPE")
+		}
+	}
+	var pathExprs []json.PathExpression
+	if len(args) > 4 {
+		if pathExprs, err = parsePathExprs(args[4:]); err != nil {
+			return d, errors.Trace(err)
+		}
+	}
+	patChars, patTypes := stringutil.CompilePattern(search, escape)
+	paths := j.Search(pathExprs, func(s string) bool {
+		return stringutil.DoMatch(s, patChars, patTypes)
+	}, one)
+	switch len(paths) {
+	case 0:
+		return d, nil
+	case 1:
+		d.SetMysqlJSON(json.CreateJSON(paths[0].String()))
+	default:
+		array := make([]interface{}, 0, len(paths))
+		for _, path := range paths {
+			array = append(array, path.String())
+		}
+		d.SetMysqlJSON(json.CreateJSON(array))
+	}
+	return d, nil
+}
+
+// JSONArrayAppend is for json_array_append builtin function.
+func JSONArrayAppend(args []types.Datum, sc *variable.StatementContext) (d types.Datum, err error) {
+	if len(args)&1 == 0 {
+		return d, ErrIncorrectParameterCount.GenByArgs(ast.JSONArrayAppend)
+	}
+	pes := make([]types.Datum, 0, len(args)/2)
+	vs := make([]types.Datum, 0, len(args)/2)
+	for i := 1; i < len(args); i += 2 {
+		pes = append(pes, args[i])
+		vs = append(vs, args[i+1])
+	}
+	if args[0].IsNull() || argsAnyNull(pes) {
+		return d, nil
+	}
+	j, err := datum2JSON(args[0], sc)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	pathExprs, err := parsePathExprs(pes)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	values, err := createJSONFromDatums(vs)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	j, err = j.ArrayAppend(pathExprs, values)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	d.SetMysqlJSON(j)
+	return d, nil
+}
+
 type jsonTypeFunctionClass struct {
 	baseFunctionClass
 }
@@ -519,3 +760,195 @@ func (b *builtinJSONArraySig) eval(row []types.Datum) (d types.Datum, err error)
 	}
 	return JSONArray(args, b.ctx.GetSessionVars().StmtCtx)
 }
+
+type jsonValidFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONValidSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonValidFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinJSONValidSig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+func (b *builtinJSONValidSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	return JSONValid(args, b.ctx.GetSessionVars().StmtCtx)
+}
+
+type jsonContainsFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONContainsSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonContainsFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinJSONContainsSig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+func (b *builtinJSONContainsSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	return JSONContains(args, b.ctx.GetSessionVars().StmtCtx)
+}
+
+type jsonContainsPathFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONContainsPathSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonContainsPathFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinJSONContainsPathSig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+func (b *builtinJSONContainsPathSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	return JSONContainsPath(args, b.ctx.GetSessionVars().StmtCtx)
+}
+
+type jsonKeysFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONKeysSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonKeysFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinJSONKeysSig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+func (b *builtinJSONKeysSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	return JSONKeys(args, b.ctx.GetSessionVars().StmtCtx)
+}
+
+type jsonLengthFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONLengthSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonLengthFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinJSONLengthSig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+func (b *builtinJSONLengthSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	return JSONLength(args, b.ctx.GetSessionVars().StmtCtx)
+}
+
+type jsonDepthFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONDepthSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonDepthFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinJSONDepthSig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+func (b *builtinJSONDepthSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	return JSONDepth(args, b.ctx.GetSessionVars().StmtCtx)
+}
+
+type jsonSearchFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONSearchSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonSearchFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinJSONSearchSig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+func (b *builtinJSONSearchSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	return JSONSearch(args, b.ctx.GetSessionVars().StmtCtx)
+}
+
+type jsonArrayAppendFunctionClass struct {
+	baseFunctionClass
+}
+
+type builtinJSONArrayAppendSig struct {
+	baseBuiltinFunc
+}
+
+func (c *jsonArrayAppendFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sig := &builtinJSONArrayAppendSig{newBaseBuiltinFunc(args, ctx)}
+	return sig.setSelf(sig), nil
+}
+
+func (b *builtinJSONArrayAppendSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	return JSONArrayAppend(args, b.ctx.GetSessionVars().StmtCtx)
+}
@@ -297,3 +297,210 @@ func (s *testEvaluatorSuite) TestJSONORemove(c *C) {
 		}
 	}
 }
+
+func (s *testEvaluatorSuite) TestJSONValid(c *C) {
+	defer testleak.AfterTest(c)()
+	fc := funcs[ast.JSONValid]
+	tbl := []struct {
+		Input    interface{}
+		Expected interface{}
+	}{
+		{nil, nil},
+		{`{"a": [1, 2]}`, 1},
+		{`[1, 2`, 0},
+		{``, 0},
+		{1, 0},
+		{json.CreateJSON(nil), 1},
+	}
+	dtbl := tblToDtbl(tbl)
+	for _, t := range dtbl {
+		f, err := fc.getFunction(s.ctx, datumsToConstants(t["Input"]))
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, t["Expected"][0])
+	}
+}
+
+// TestJSONContainsAndPath tests json_contains and json_contains_path.
+func (s *testEvaluatorSuite) TestJSONContainsAndPath(c *C) {
+	defer testleak.AfterTest(c)()
+	jstr := `{"a": [1, 2, {"aa": "xx"}], "b": 3}`
+	tbl := []struct {
+		fc       functionClass
+		Input    []interface{}
+		Expected interface{}
+		Success  bool
+	}{
+		{funcs[ast.JSONContains], []interface{}{jstr, `{"b": 3}`}, int64(1), true},
+		{funcs[ast.JSONContains], []interface{}{jstr, `{"a": [2]}`}, int64(1), true},
+		{funcs[ast.JSONContains], []interface{}{jstr, `{"aa": "xx"}`, `$.a`}, int64(1), true},
+		{funcs[ast.JSONContains], []interface{}{jstr, `{"aa": "yy"}`, `$.a`}, int64(0), true},
+		{funcs[ast.JSONContains], []interface{}{jstr, `3`, `$.c`}, nil, true},
+		{funcs[ast.JSONContains], []interface{}{jstr, nil}, nil, true},
+		{funcs[ast.JSONContains], []interface{}{jstr, `3`, `$.*`}, nil, false},
+		{funcs[ast.JSONContains], []interface{}{jstr, `{"b"}`}, nil, false},
+
+		{funcs[ast.JSONContainsPath], []interface{}{jstr, "one", `$.a`, `$.c`}, int64(1), true},
+		{funcs[ast.JSONContainsPath], []interface{}{jstr, "ALL", `$.a`, `$.c`}, int64(0), true},
+		{funcs[ast.JSONContainsPath], []interface{}{jstr, "all", `$.a[2].aa`, `$.b`}, int64(1), true},
+		{funcs[ast.JSONContainsPath], []interface{}{jstr, "one", `$.*[0]`}, int64(1), true},
+		{funcs[ast.JSONContainsPath], []interface{}{jstr, "one", nil}, nil, true},
+		{funcs[ast.JSONContainsPath], []interface{}{jstr, "any", `$.a`}, nil, false},
+		{funcs[ast.JSONContainsPath], []interface{}{jstr, "one", `$InvalidPath`}, nil, false},
+	}
+	for _, t := range tbl {
+		args := types.MakeDatums(t.Input...)
+		f, err := t.fc.getFunction(s.ctx, datumsToConstants(args))
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		if t.Success {
+			c.Assert(err, IsNil)
+			c.Assert(d, testutil.DatumEquals, types.NewDatum(t.Expected))
+		} else {
+			c.Assert(err, NotNil)
+		}
+	}
+}
+
+// TestJSONKeysLengthDepth tests json_keys, json_length and json_depth.
+func (s *testEvaluatorSuite) TestJSONKeysLengthDepth(c *C) {
+	defer testleak.AfterTest(c)()
+	jstr := `{"b": [1, 2, {"aa": "xx"}], "a": 3}`
+	tbl := []struct {
+		fc       functionClass
+		Input    []interface{}
+		Expected interface{}
+		Success  bool
+	}{
+		{funcs[ast.JSONKeys], []interface{}{jstr}, `["a", "b"]`, true},
+		{funcs[ast.JSONKeys], []interface{}{jstr, `$.b[2]`}, `["aa"]`, true},
+		{funcs[ast.JSONKeys], []interface{}{jstr, `$.a`}, nil, true},
+		{funcs[ast.JSONKeys], []interface{}{jstr, `$.c`}, nil, true},
+		{funcs[ast.JSONKeys], []interface{}{nil}, nil, true},
+		{funcs[ast.JSONKeys], []interface{}{jstr, `$.*`}, nil, false},
+
+		{funcs[ast.JSONLength], []interface{}{jstr}, int64(2), true},
+		{funcs[ast.JSONLength], []interface{}{jstr, `$.b`}, int64(3), true},
+		{funcs[ast.JSONLength], []interface{}{jstr, `$.a`}, int64(1), true},
+		{funcs[ast.JSONLength], []interface{}{jstr, `$.c`}, nil, true},
+		{funcs[ast.JSONLength], []interface{}{jstr, `$**.aa`}, nil, false},
+
+		{funcs[ast.JSONDepth], []interface{}{jstr}, int64(4), true},
+		{funcs[ast.JSONDepth], []interface{}{`[]`}, int64(1), true},
+		{funcs[ast.JSONDepth], []interface{}{`[[]]`}, int64(2), true},
+		{funcs[ast.JSONDepth], []interface{}{nil}, nil, true},
+		{funcs[ast.JSONDepth], []interface{}{`[`}, nil, false},
+	}
+	for _, t := range tbl {
+		args := types.MakeDatums(t.Input...)
+		f, err := t.fc.getFunction(s.ctx, datumsToConstants(args))
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		if !t.Success {
+			c.Assert(err, NotNil)
+			continue
+		}
+		c.Assert(err, IsNil)
+		switch x := t.Expected.(type) {
+		case string:
+			j1, err := json.ParseFromString(x)
+			c.Assert(err, IsNil)
+			cmp, err := json.CompareJSON(j1, d.GetMysqlJSON())
+			c.Assert(err, IsNil)
+			c.Assert(cmp, Equals, 0)
+		default:
+			c.Assert(d, testutil.DatumEquals, types.NewDatum(t.Expected))
+		}
+	}
+}
+
+func (s *testEvaluatorSuite) TestJSONSearch(c *C) {
+	defer testleak.AfterTest(c)()
+	fc := funcs[ast.JSONSearch]
+	jstr := `["abc", [{"k": "10"}, "def"], {"x": "abc"}, {"y": "bcd"}, "a%c"]`
+	tbl := []struct {
+		Input    []interface{}
+		Expected interface{}
+		Success  bool
+	}{
+		{[]interface{}{jstr, "one", "abc"}, `"$[0]"`, true},
+		{[]interface{}{jstr, "all", "abc"}, `["$[0]", "$[2].x"]`, true},
+		{[]interface{}{jstr, "all", "ghi"}, nil, true},
+		{[]interface{}{jstr, "all", "10"}, `"$[1][0].k"`, true},
+		{[]interface{}{jstr, "all", "%b%"}, `["$[0]", "$[2].x", "$[3].y"]`, true},
+		{[]interface{}{jstr, "all", "%b%", nil, "$[3]"}, `"$[3].y"`, true},
+		{[]interface{}{jstr, "all", "%b%", nil, "$[1]", "$[2]"}, `"$[2].x"`, true},
+		{[]interface{}{jstr, "all", "a\\%c"}, `"$[4]"`, true},
+		{[]interface{}{jstr, "all", "a|%c", "|"}, `"$[4]"`, true},
+		{[]interface{}{jstr, "all", "abc", nil, nil}, nil, true},
+		{[]interface{}{nil, "all", "abc"}, nil, true},
+		{[]interface{}{jstr, "all", nil}, nil, true},
+		{[]interface{}{jstr, "one2", "abc"}, nil, false},
+		{[]interface{}{jstr, "one", "abc", "||"}, nil, false},
+		{[]interface{}{jstr, "one", "abc", nil, "$InvalidPath"}, nil, false},
+	}
+	for _, t := range tbl {
+		args := types.MakeDatums(t.Input...)
+		f, err := fc.getFunction(s.ctx, datumsToConstants(args))
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		if !t.Success {
+			c.Assert(err, NotNil)
+			continue
+		}
+		c.Assert(err, IsNil)
+		switch x := t.Expected.(type) {
+		case string:
+			j1, err := json.ParseFromString(x)
+			c.Assert(err, IsNil)
+			cmp, err := json.CompareJSON(j1, d.GetMysqlJSON())
+			c.Assert(err, IsNil)
+			c.Assert(cmp, Equals, 0, Commentf("%v", t.Input))
+		default:
+			c.Assert(d.IsNull(), IsTrue, Commentf("%v", t.Input))
+		}
+	}
+}
+
+func (s *testEvaluatorSuite) TestJSONArrayAppend(c *C) {
+	defer testleak.AfterTest(c)()
+	fc := funcs[ast.JSONArrayAppend]
+	jstr := `{"a": [1, 2], "b": {"c": 3}}`
+	tbl := []struct {
+		Input    []interface{}
+		Expected interface{}
+		Success  bool
+	}{
+		{[]interface{}{jstr, `$.a`, 3}, `{"a": [1, 2, 3], "b": {"c": 3}}`, true},
+		{[]interface{}{jstr, `$.b`, "x"}, `{"a": [1, 2], "b": [{"c": 3}, "x"]}`, true},
+		{[]interface{}{jstr, `$.a`, nil, `$.b.c`, 4}, `{"a": [1, 2, null], "b": {"c": [3, 4]}}`, true},
+		{[]interface{}{jstr, `$.d`, 3}, jstr, true},
+		{[]interface{}{nil, `$.a`, 3}, nil, true},
+		{[]interface{}{jstr, nil, 3}, nil, true},
+		{[]interface{}{jstr, `$.a`, 3, `$.b`}, nil, false},
+		{[]interface{}{jstr, `$.*`, 3}, nil, false},
+		{[]interface{}{jstr, `$InvalidPath`, 3}, nil, false},
+	}
+	for _, t := range tbl {
+		args := types.MakeDatums(t.Input...)
+		f, err := fc.getFunction(s.ctx, datumsToConstants(args))
+		c.Assert(err, IsNil)
+		d, err := f.eval(nil)
+		if !t.Success {
+			c.Assert(err, NotNil)
+			continue
+		}
+		c.Assert(err, IsNil)
+		switch x := t.Expected.(type) {
+		case string:
+			j1, err := json.ParseFromString(x)
+			c.Assert(err, IsNil)
+			cmp, err := json.CompareJSON(j1, d.GetMysqlJSON())
+			c.Assert(err, IsNil)
+			c.Assert(cmp, Equals, 0, Commentf("%v", t.Input))
+		default:
+			c.Assert(d.IsNull(), IsTrue)
+		}
+	}
+}
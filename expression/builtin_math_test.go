@@ -566,7 +566,7 @@ func (s *testEvaluatorSuite) TestConv(c *C) {
 		tp := f.GetType()
 		c.Assert(tp.Tp, Equals, mysql.TypeVarString)
 		c.Assert(tp.Charset, Equals, charset.CharsetUTF8)
-		c.Assert(tp.Collate, Equals, charset.CharsetUTF8)
+		c.Assert(tp.Collate, Equals, charset.CollationUTF8)
 		c.Assert(tp.Flag, Equals, uint(0))
 
 		d, err := f.Eval(nil)
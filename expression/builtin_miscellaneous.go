@@ -24,6 +24,8 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/userlock"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tidb/util/types/json"
@@ -68,10 +70,13 @@ var (
 	_ builtinFunc = &builtinInetNtoaSig{}
 	_ builtinFunc = &builtinInet6AtonSig{}
 	_ builtinFunc = &builtinInet6NtoaSig{}
+	_ builtinFunc = &builtinIsFreeLockSig{}
 	_ builtinFunc = &builtinIsIPv4Sig{}
 	_ builtinFunc = &builtinIsIPv4CompatSig{}
 	_ builtinFunc = &builtinIsIPv4MappedSig{}
 	_ builtinFunc = &builtinIsIPv6Sig{}
+	_ builtinFunc = &builtinIsUsedLockSig{}
+	_ builtinFunc = &builtinReleaseAllLocksSig{}
 	_ builtinFunc = &builtinUUIDSig{}
 )
 
@@ -135,20 +140,69 @@ func (c *lockFunctionClass) getFunction(ctx context.Context, args []Expression)
 		return nil, errors.Trace(err)
 	}
 	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, tpString, tpInt)
-	sig := &builtinLockSig{baseIntBuiltinFunc{bf}}
 	bf.tp.Flen = 1
+	bf.foldable = false
+	sig := &builtinLockSig{baseIntBuiltinFunc{bf}}
 	return sig.setSelf(sig), nil
 }
 
+// getUserLockHolder gets the Holder of the user-level locks of the session.
+func getUserLockHolder(ctx context.Context) (*userlock.Holder, error) {
+	h := userlock.GetHolder(ctx)
+	if h == nil {
+		return nil, errUserLockUnavailable
+	}
+	return h, nil
+}
+
+// evalUserLockName evaluates the name of a user-level lock, the NULL, empty or too long name is invalid.
+func evalUserLockName(arg Expression, row []types.Datum, sc *variable.StatementContext) (string, error) {
+	name, isNull, err := arg.EvalString(row, sc)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if isNull {
+		return "", errUserLockWrongName.GenByArgs("NULL")
+	}
+	lockName, ok := userlock.NormalizeName(name)
+	if !ok {
+		return "", errUserLockWrongName.GenByArgs(name)
+	}
+	return lockName, nil
+}
+
 type builtinLockSig struct {
 	baseIntBuiltinFunc
 }
 
 // evalInt evals a builtinLockSig.
 // See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_get-lock
-// The lock function will do nothing.
-// Warning: get_lock() function is parsed but ignored.
-func (b *builtinLockSig) evalInt(_ []types.Datum) (int64, bool, error) {
+func (b *builtinLockSig) evalInt(row []types.Datum) (int64, bool, error) {
+	sessVars := b.ctx.GetSessionVars()
+	name, err := evalUserLockName(b.args[0], row, sessVars.StmtCtx)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	h, err := getUserLockHolder(b.ctx)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	// The NULL timeout is treated as 0, a negative timeout means waiting forever.
+	timeout, _, err := b.args[1].EvalInt(row, sessVars.StmtCtx)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	dur := time.Duration(-1)
+	if timeout >= 0 && timeout < math.MaxInt64/int64(time.Second) {
+		dur = time.Duration(timeout) * time.Second
+	}
+	acquired, err := h.Acquire(name, sessVars.ConnectionID, dur)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	if !acquired {
+		return 0, false, nil
+	}
 	return 1, false, nil
 }
 
@@ -161,8 +215,9 @@ func (c *releaseLockFunctionClass) getFunction(ctx context.Context, args []Expre
 		return nil, errors.Trace(err)
 	}
 	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, tpString)
-	sig := &builtinReleaseLockSig{baseIntBuiltinFunc{bf}}
 	bf.tp.Flen = 1
+	bf.foldable = false
+	sig := &builtinReleaseLockSig{baseIntBuiltinFunc{bf}}
 	return sig.setSelf(sig), nil
 }
 
@@ -172,9 +227,22 @@ type builtinReleaseLockSig struct {
 
 // evalInt evals a builtinReleaseLockSig.
 // See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_release-lock
-// The release lock function will do nothing.
-// Warning: release_lock() function is parsed but ignored.
-func (b *builtinReleaseLockSig) evalInt(_ []types.Datum) (int64, bool, error) {
+func (b *builtinReleaseLockSig) evalInt(row []types.Datum) (int64, bool, error) {
+	name, err := evalUserLockName(b.args[0], row, b.ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	h, err := getUserLockHolder(b.ctx)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	held, exists, err := h.Release(name)
+	if err != nil || !exists {
+		return 0, true, errors.Trace(err)
+	}
+	if !held {
+		return 0, false, nil
+	}
 	return 1, false, nil
 }
 
@@ -507,7 +575,39 @@ type isFreeLockFunctionClass struct {
 }
 
 func (c *isFreeLockFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("IS_FREE_LOCK")
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, tpString)
+	bf.tp.Flen = 1
+	bf.foldable = false
+	sig := &builtinIsFreeLockSig{baseIntBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinIsFreeLockSig struct {
+	baseIntBuiltinFunc
+}
+
+// evalInt evals a builtinIsFreeLockSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_is-free-lock
+func (b *builtinIsFreeLockSig) evalInt(row []types.Datum) (int64, bool, error) {
+	name, err := evalUserLockName(b.args[0], row, b.ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	h, err := getUserLockHolder(b.ctx)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	lock, err := h.Manager().IsUsed(name)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	if lock != nil {
+		return 0, false, nil
+	}
+	return 1, false, nil
 }
 
 type isIPv4FunctionClass struct {
@@ -683,7 +783,37 @@ type isUsedLockFunctionClass struct {
 }
 
 func (c *isUsedLockFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("IS_USED_LOCK")
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, tpString)
+	bf.tp.Flen = 20
+	bf.tp.Flag |= mysql.UnsignedFlag
+	bf.foldable = false
+	sig := &builtinIsUsedLockSig{baseIntBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinIsUsedLockSig struct {
+	baseIntBuiltinFunc
+}
+
+// evalInt evals a builtinIsUsedLockSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_is-used-lock
+func (b *builtinIsUsedLockSig) evalInt(row []types.Datum) (int64, bool, error) {
+	name, err := evalUserLockName(b.args[0], row, b.ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	h, err := getUserLockHolder(b.ctx)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	lock, err := h.Manager().IsUsed(name)
+	if err != nil || lock == nil {
+		return 0, true, errors.Trace(err)
+	}
+	return int64(lock.ConnectionID), false, nil
 }
 
 type masterPosWaitFunctionClass struct {
@@ -707,7 +837,32 @@ type releaseAllLocksFunctionClass struct {
 }
 
 func (c *releaseAllLocksFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("RELEASE_ALL_LOCKS")
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt)
+	bf.tp.Flen = 21
+	bf.foldable = false
+	sig := &builtinReleaseAllLocksSig{baseIntBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinReleaseAllLocksSig struct {
+	baseIntBuiltinFunc
+}
+
+// evalInt evals a builtinReleaseAllLocksSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_release-all-locks
+func (b *builtinReleaseAllLocksSig) evalInt(_ []types.Datum) (int64, bool, error) {
+	h, err := getUserLockHolder(b.ctx)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	cnt, err := h.ReleaseAll()
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	return cnt, false, nil
 }
 
 type uuidFunctionClass struct {
@@ -25,6 +25,7 @@ var (
 	_ functionClass = &rowFunctionClass{}
 	_ functionClass = &setVarFunctionClass{}
 	_ functionClass = &getVarFunctionClass{}
+	_ functionClass = &getParamFunctionClass{}
 	_ functionClass = &lockFunctionClass{}
 	_ functionClass = &releaseLockFunctionClass{}
 	_ functionClass = &valuesFunctionClass{}
@@ -36,6 +37,7 @@ var (
 	_ builtinFunc = &builtinRowSig{}
 	_ builtinFunc = &builtinSetVarSig{}
 	_ builtinFunc = &builtinGetVarSig{}
+	_ builtinFunc = &builtinGetParamSig{}
 	_ builtinFunc = &builtinLockSig{}
 	_ builtinFunc = &builtinReleaseLockSig{}
 	_ builtinFunc = &builtinValuesSig{}
@@ -135,6 +137,36 @@ func (b *builtinGetVarSig) eval(row []types.Datum) (types.Datum, error) {
 	return types.Datum{}, nil
 }
 
+type getParamFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *getParamFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	err := errors.Trace(c.verifyArgs(args))
+	bt := &builtinGetParamSig{newBaseBuiltinFunc(args, ctx)}
+	bt.foldable = false
+	return bt.setSelf(bt), errors.Trace(err)
+}
+
+// builtinGetParamSig gets the parameter of the executing prepared statement.
+// It is used by the constants of the parameter markers in the cached plans.
+type builtinGetParamSig struct {
+	baseBuiltinFunc
+}
+
+func (b *builtinGetParamSig) eval(row []types.Datum) (types.Datum, error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	params := b.ctx.GetSessionVars().PreparedParams
+	idx := int(args[0].GetInt64())
+	if idx < 0 || idx >= len(params) {
+		return types.Datum{}, errors.Errorf("the parameter %d of the prepared statement is not set", idx)
+	}
+	return params[idx].(types.Datum), nil
+}
+
 type valuesFunctionClass struct {
 	baseFunctionClass
 
@@ -0,0 +1,559 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"math"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/hack"
+	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tidb/util/types/geo"
+)
+
+// The geometry values are passed between the spatial functions as binary strings in the internal
+// format, which is the SRID followed by the WKB, the same as the one stored in the geometry columns.
+// There are no spatial indices, so the MBR predicates can't be used to build index ranges, they are
+// evaluated row by row like the other filters.
+
+var (
+	_ functionClass = &pointFunctionClass{}
+	_ functionClass = &stGeomFromTextFunctionClass{}
+	_ functionClass = &stGeomFromWKBFunctionClass{}
+	_ functionClass = &stAsTextFunctionClass{}
+	_ functionClass = &stAsBinaryFunctionClass{}
+	_ functionClass = &stGeometryTypeFunctionClass{}
+	_ functionClass = &stXFunctionClass{}
+	_ functionClass = &stYFunctionClass{}
+	_ functionClass = &stEnvelopeFunctionClass{}
+	_ functionClass = &stDistanceFunctionClass{}
+	_ functionClass = &stContainsFunctionClass{}
+	_ functionClass = &stWithinFunctionClass{}
+	_ functionClass = &mbrContainsFunctionClass{}
+	_ functionClass = &mbrWithinFunctionClass{}
+	_ functionClass = &mbrIntersectsFunctionClass{}
+)
+
+var (
+	_ builtinFunc = &builtinPointSig{}
+	_ builtinFunc = &builtinSTGeomFromTextSig{}
+	_ builtinFunc = &builtinSTGeomFromWKBSig{}
+	_ builtinFunc = &builtinSTAsTextSig{}
+	_ builtinFunc = &builtinSTAsBinarySig{}
+	_ builtinFunc = &builtinSTGeometryTypeSig{}
+	_ builtinFunc = &builtinSTXSig{}
+	_ builtinFunc = &builtinSTYSig{}
+	_ builtinFunc = &builtinSTEnvelopeSig{}
+	_ builtinFunc = &builtinSTDistanceSig{}
+	_ builtinFunc = &builtinSTContainsSig{}
+	_ builtinFunc = &builtinSTWithinSig{}
+	_ builtinFunc = &builtinMBRContainsSig{}
+	_ builtinFunc = &builtinMBRWithinSig{}
+	_ builtinFunc = &builtinMBRIntersectsSig{}
+)
+
+// setGeometryType sets the return type of a function returning a geometry value.
+func setGeometryType(tp *types.FieldType) {
+	tp.Tp = mysql.TypeGeometry
+	tp.Flen, tp.Decimal = mysql.GetDefaultFieldLengthAndDecimal(mysql.TypeGeometry)
+	types.SetBinChsClnFlag(tp)
+}
+
+// evalGeometry evaluates arg to a geometry value, funcName is used in the error message if the value is invalid.
+func evalGeometry(b *baseBuiltinFunc, arg Expression, row []types.Datum, funcName string) (*geo.Geometry, bool, error) {
+	s, isNull, err := arg.EvalString(row, b.ctx.GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return nil, true, errors.Trace(err)
+	}
+	g, err := geo.Deserialize(hack.Slice(s))
+	if err != nil {
+		return nil, true, geo.ErrInvalidGISData.GenByArgs(funcName)
+	}
+	return g, false, nil
+}
+
+// evalGeometryPair evaluates the arguments of a binary spatial function, they must have the same SRID.
+func evalGeometryPair(b *baseBuiltinFunc, row []types.Datum, funcName string) (g1, g2 *geo.Geometry, isNull bool, err error) {
+	g1, isNull, err = evalGeometry(b, b.args[0], row, funcName)
+	if isNull || err != nil {
+		return nil, nil, true, errors.Trace(err)
+	}
+	g2, isNull, err = evalGeometry(b, b.args[1], row, funcName)
+	if isNull || err != nil {
+		return nil, nil, true, errors.Trace(err)
+	}
+	if g1.SRID != g2.SRID {
+		return nil, nil, true, geo.ErrDifferentSRIDs.GenByArgs(funcName, g1.SRID, g2.SRID)
+	}
+	return g1, g2, false, nil
+}
+
+// evalSRID evaluates the optional SRID argument of the functions creating a geometry value.
+func evalSRID(b *baseBuiltinFunc, row []types.Datum, funcName string) (uint32, bool, error) {
+	if len(b.args) < 2 {
+		return 0, false, nil
+	}
+	srid, isNull, err := b.args[1].EvalInt(row, b.ctx.GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	if srid < 0 || srid > math.MaxUint32 {
+		return 0, true, errIncorrectArgs.GenByArgs(funcName)
+	}
+	return uint32(srid), false, nil
+}
+
+type pointFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *pointFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, tpReal, tpReal)
+	setGeometryType(bf.tp)
+	sig := &builtinPointSig{baseStringBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinPointSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals POINT(x, y).
+// See https://dev.mysql.com/doc/refman/5.7/en/gis-mysql-specific-functions.html#function_point
+func (b *builtinPointSig) evalString(row []types.Datum) (string, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	x, isNull, err := b.args[0].EvalReal(row, sc)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	y, isNull, err := b.args[1].EvalReal(row, sc)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	return string(geo.Serialize(geo.NewPoint(x, y, 0))), false, nil
+}
+
+type stGeomFromTextFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *stGeomFromTextFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := []evalTp{tpString, tpInt}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, argTps[:len(args)]...)
+	setGeometryType(bf.tp)
+	sig := &builtinSTGeomFromTextSig{baseStringBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinSTGeomFromTextSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals ST_GeomFromText(wkt[, srid]).
+// See https://dev.mysql.com/doc/refman/5.7/en/gis-wkt-functions.html#function_st-geomfromtext
+func (b *builtinSTGeomFromTextSig) evalString(row []types.Datum) (string, bool, error) {
+	wkt, isNull, err := b.args[0].EvalString(row, b.ctx.GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	srid, isNull, err := evalSRID(&b.baseBuiltinFunc, row, ast.STGeomFromText)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	g, err := geo.ParseWKT(wkt, srid)
+	if err != nil {
+		return "", true, geo.ErrInvalidGISData.GenByArgs(ast.STGeomFromText)
+	}
+	return string(geo.Serialize(g)), false, nil
+}
+
+type stGeomFromWKBFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *stGeomFromWKBFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := []evalTp{tpString, tpInt}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, argTps[:len(args)]...)
+	setGeometryType(bf.tp)
+	sig := &builtinSTGeomFromWKBSig{baseStringBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinSTGeomFromWKBSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals ST_GeomFromWKB(wkb[, srid]).
+// See https://dev.mysql.com/doc/refman/5.7/en/gis-wkb-functions.html#function_st-geomfromwkb
+func (b *builtinSTGeomFromWKBSig) evalString(row []types.Datum) (string, bool, error) {
+	wkb, isNull, err := b.args[0].EvalString(row, b.ctx.GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	srid, isNull, err := evalSRID(&b.baseBuiltinFunc, row, ast.STGeomFromWKB)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	g, err := geo.ParseWKB(hack.Slice(wkb), srid)
+	if err != nil {
+		return "", true, geo.ErrInvalidGISData.GenByArgs(ast.STGeomFromWKB)
+	}
+	return string(geo.Serialize(g)), false, nil
+}
+
+type stAsTextFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *stAsTextFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, tpString)
+	bf.tp.Flen = mysql.MaxBlobWidth
+	sig := &builtinSTAsTextSig{baseStringBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinSTAsTextSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals ST_AsText(g).
+// See https://dev.mysql.com/doc/refman/5.7/en/gis-format-conversion-functions.html#function_st-astext
+func (b *builtinSTAsTextSig) evalString(row []types.Datum) (string, bool, error) {
+	g, isNull, err := evalGeometry(&b.baseBuiltinFunc, b.args[0], row, ast.STAsText)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	return g.WKT(), false, nil
+}
+
+type stAsBinaryFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *stAsBinaryFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, tpString)
+	bf.tp.Flen = mysql.MaxBlobWidth
+	types.SetBinChsClnFlag(bf.tp)
+	sig := &builtinSTAsBinarySig{baseStringBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinSTAsBinarySig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals ST_AsBinary(g).
+// See https://dev.mysql.com/doc/refman/5.7/en/gis-format-conversion-functions.html#function_st-asbinary
+func (b *builtinSTAsBinarySig) evalString(row []types.Datum) (string, bool, error) {
+	g, isNull, err := evalGeometry(&b.baseBuiltinFunc, b.args[0], row, ast.STAsBinary)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	return string(g.WKB()), false, nil
+}
+
+type stGeometryTypeFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *stGeometryTypeFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, tpString)
+	bf.tp.Flen = 20
+	sig := &builtinSTGeometryTypeSig{baseStringBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinSTGeometryTypeSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals ST_GeometryType(g).
+// See https://dev.mysql.com/doc/refman/5.7/en/gis-general-property-functions.html#function_st-geometrytype
+func (b *builtinSTGeometryTypeSig) evalString(row []types.Datum) (string, bool, error) {
+	g, isNull, err := evalGeometry(&b.baseBuiltinFunc, b.args[0], row, ast.STGeometryType)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	return g.Type.String(), false, nil
+}
+
+type stXFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *stXFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpReal, tpString)
+	sig := &builtinSTXSig{baseRealBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinSTXSig struct {
+	baseRealBuiltinFunc
+}
+
+// evalReal evals ST_X(p), it returns NULL if p isn't a point.
+// See https://dev.mysql.com/doc/refman/5.7/en/gis-point-property-functions.html#function_st-x
+func (b *builtinSTXSig) evalReal(row []types.Datum) (float64, bool, error) {
+	g, isNull, err := evalGeometry(&b.baseBuiltinFunc, b.args[0], row, ast.STX)
+	if isNull || err != nil || g.Type != geo.TypePoint {
+		return 0, true, errors.Trace(err)
+	}
+	return g.Points[0].X, false, nil
+}
+
+type stYFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *stYFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpReal, tpString)
+	sig := &builtinSTYSig{baseRealBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinSTYSig struct {
+	baseRealBuiltinFunc
+}
+
+// evalReal evals ST_Y(p), it returns NULL if p isn't a point.
+// See https://dev.mysql.com/doc/refman/5.7/en/gis-point-property-functions.html#function_st-y
+func (b *builtinSTYSig) evalReal(row []types.Datum) (float64, bool, error) {
+	g, isNull, err := evalGeometry(&b.baseBuiltinFunc, b.args[0], row, ast.STY)
+	if isNull || err != nil || g.Type != geo.TypePoint {
+		return 0, true, errors.Trace(err)
+	}
+	return g.Points[0].Y, false, nil
+}
+
+type stEnvelopeFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *stEnvelopeFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, tpString)
+	setGeometryType(bf.tp)
+	sig := &builtinSTEnvelopeSig{baseStringBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinSTEnvelopeSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals ST_Envelope(g).
+// See https://dev.mysql.com/doc/refman/5.7/en/gis-general-property-functions.html#function_st-envelope
+func (b *builtinSTEnvelopeSig) evalString(row []types.Datum) (string, bool, error) {
+	g, isNull, err := evalGeometry(&b.baseBuiltinFunc, b.args[0], row, ast.STEnvelope)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	return string(geo.Serialize(g.Envelope().Geometry(g.SRID))), false, nil
+}
+
+type stDistanceFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *stDistanceFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpReal, tpString, tpString)
+	sig := &builtinSTDistanceSig{baseRealBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinSTDistanceSig struct {
+	baseRealBuiltinFunc
+}
+
+// evalReal evals ST_Distance(g1, g2).
+// See https://dev.mysql.com/doc/refman/5.7/en/spatial-relation-functions-object-shapes.html#function_st-distance
+func (b *builtinSTDistanceSig) evalReal(row []types.Datum) (float64, bool, error) {
+	g1, g2, isNull, err := evalGeometryPair(&b.baseBuiltinFunc, row, ast.STDistance)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	return geo.Distance(g1, g2), false, nil
+}
+
+type stContainsFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *stContainsFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, tpString, tpString)
+	bf.tp.Flen = 1
+	sig := &builtinSTContainsSig{baseIntBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinSTContainsSig struct {
+	baseIntBuiltinFunc
+}
+
+// evalInt evals ST_Contains(g1, g2).
+// See https://dev.mysql.com/doc/refman/5.7/en/spatial-relation-functions-object-shapes.html#function_st-contains
+func (b *builtinSTContainsSig) evalInt(row []types.Datum) (int64, bool, error) {
+	g1, g2, isNull, err := evalGeometryPair(&b.baseBuiltinFunc, row, ast.STContains)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	return boolToInt64(geo.Contains(g1, g2)), false, nil
+}
+
+type stWithinFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *stWithinFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, tpString, tpString)
+	bf.tp.Flen = 1
+	sig := &builtinSTWithinSig{baseIntBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinSTWithinSig struct {
+	baseIntBuiltinFunc
+}
+
+// evalInt evals ST_Within(g1, g2).
+// See https://dev.mysql.com/doc/refman/5.7/en/spatial-relation-functions-object-shapes.html#function_st-within
+func (b *builtinSTWithinSig) evalInt(row []types.Datum) (int64, bool, error) {
+	g1, g2, isNull, err := evalGeometryPair(&b.baseBuiltinFunc, row, ast.STWithin)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	return boolToInt64(geo.Within(g1, g2)), false, nil
+}
+
+type mbrContainsFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *mbrContainsFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, tpString, tpString)
+	bf.tp.Flen = 1
+	sig := &builtinMBRContainsSig{baseIntBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinMBRContainsSig struct {
+	baseIntBuiltinFunc
+}
+
+// evalInt evals MBRContains(g1, g2).
+// See https://dev.mysql.com/doc/refman/5.7/en/spatial-relation-functions-mbr.html#function_mbrcontains
+func (b *builtinMBRContainsSig) evalInt(row []types.Datum) (int64, bool, error) {
+	g1, g2, isNull, err := evalGeometryPair(&b.baseBuiltinFunc, row, ast.MBRContains)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	return boolToInt64(g1.Envelope().Contains(g2.Envelope())), false, nil
+}
+
+type mbrWithinFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *mbrWithinFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, tpString, tpString)
+	bf.tp.Flen = 1
+	sig := &builtinMBRWithinSig{baseIntBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinMBRWithinSig struct {
+	baseIntBuiltinFunc
+}
+
+// evalInt evals MBRWithin(g1, g2).
+// See https://dev.mysql.com/doc/refman/5.7/en/spatial-relation-functions-mbr.html#function_mbrwithin
+func (b *builtinMBRWithinSig) evalInt(row []types.Datum) (int64, bool, error) {
+	g1, g2, isNull, err := evalGeometryPair(&b.baseBuiltinFunc, row, ast.MBRWithin)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	return boolToInt64(g2.Envelope().Contains(g1.Envelope())), false, nil
+}
+
+type mbrIntersectsFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *mbrIntersectsFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, tpString, tpString)
+	bf.tp.Flen = 1
+	sig := &builtinMBRIntersectsSig{baseIntBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinMBRIntersectsSig struct {
+	baseIntBuiltinFunc
+}
+
+// evalInt evals MBRIntersects(g1, g2).
+// See https://dev.mysql.com/doc/refman/5.7/en/spatial-relation-functions-mbr.html#function_mbrintersects
+func (b *builtinMBRIntersectsSig) evalInt(row []types.Datum) (int64, bool, error) {
+	g1, g2, isNull, err := evalGeometryPair(&b.baseBuiltinFunc, row, ast.MBRIntersects)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	return boolToInt64(g1.Envelope().Intersects(g2.Envelope())), false, nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types/geo"
+)
+
+func (s *testEvaluatorSuite) newGeomFromText(c *C, wkt interface{}) Expression {
+	f, err := newFunctionForTest(s.ctx, ast.STGeomFromText, primitiveValsToConstants([]interface{}{wkt})...)
+	c.Assert(err, IsNil)
+	return f
+}
+
+func (s *testEvaluatorSuite) TestGeomFromText(c *C) {
+	defer testleak.AfterTest(c)()
+	cases := []struct {
+		args   []interface{}
+		isNil  bool
+		getErr bool
+		srid   uint32
+	}{
+		{[]interface{}{"POINT(1 2)"}, false, false, 0},
+		{[]interface{}{"POINT(1 2)", 4326}, false, false, 4326},
+		{[]interface{}{nil}, true, false, 0},
+		{[]interface{}{"POINT(1 2)", nil}, true, false, 0},
+		{[]interface{}{"POINT(1)"}, false, true, 0},
+		{[]interface{}{"POINT(1 2)", -1}, false, true, 0},
+	}
+	for _, t := range cases {
+		f, err := newFunctionForTest(s.ctx, ast.STGeomFromText, primitiveValsToConstants(t.args)...)
+		c.Assert(err, IsNil)
+		d, err := f.Eval(nil)
+		if t.getErr {
+			c.Assert(err, NotNil)
+			continue
+		}
+		c.Assert(err, IsNil)
+		if t.isNil {
+			c.Assert(d.IsNull(), IsTrue)
+			continue
+		}
+		g, err := geo.Deserialize(d.GetBytes())
+		c.Assert(err, IsNil)
+		c.Assert(g.SRID, Equals, t.srid)
+		c.Assert(g.WKT(), Equals, "POINT(1 2)")
+	}
+}
+
+func (s *testEvaluatorSuite) TestSpatialAccessors(c *C) {
+	defer testleak.AfterTest(c)()
+	cases := []struct {
+		wkt      string
+		text     string
+		geomType string
+		x        interface{}
+		envelope string
+	}{
+		{"POINT(1 2)", "POINT(1 2)", "POINT", float64(1), "POINT(1 2)"},
+		{"LINESTRING(3 1,0 5)", "LINESTRING(3 1,0 5)", "LINESTRING", nil, "POLYGON((0 1,3 1,3 5,0 5,0 1))"},
+		{"POLYGON((0 0,2 0,0 2,0 0))", "POLYGON((0 0,2 0,0 2,0 0))", "POLYGON", nil, "POLYGON((0 0,2 0,2 2,0 2,0 0))"},
+	}
+	for _, t := range cases {
+		g := s.newGeomFromText(c, t.wkt)
+		f, err := newFunctionForTest(s.ctx, ast.STAsText, g)
+		c.Assert(err, IsNil)
+		d, err := f.Eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d.GetString(), Equals, t.text)
+
+		f, err = newFunctionForTest(s.ctx, ast.STGeometryType, g)
+		c.Assert(err, IsNil)
+		d, err = f.Eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d.GetString(), Equals, t.geomType)
+
+		f, err = newFunctionForTest(s.ctx, ast.STX, g)
+		c.Assert(err, IsNil)
+		d, err = f.Eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d.GetValue(), Equals, t.x)
+
+		f, err = newFunctionForTest(s.ctx, ast.STEnvelope, g)
+		c.Assert(err, IsNil)
+		f, err = newFunctionForTest(s.ctx, ast.STAsText, f)
+		c.Assert(err, IsNil)
+		d, err = f.Eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d.GetString(), Equals, t.envelope)
+	}
+
+	f, err := newFunctionForTest(s.ctx, ast.STAsText, primitiveValsToConstants([]interface{}{"abc"})...)
+	c.Assert(err, IsNil)
+	_, err = f.Eval(nil)
+	c.Assert(err, NotNil)
+}
+
+func (s *testEvaluatorSuite) TestSpatialRelations(c *C) {
+	defer testleak.AfterTest(c)()
+	square := "POLYGON((0 0,4 0,4 4,0 4,0 0))"
+	cases := []struct {
+		funcName string
+		g1       interface{}
+		g2       interface{}
+		result   interface{}
+	}{
+		{ast.STDistance, "POINT(0 0)", "POINT(3 4)", float64(5)},
+		{ast.STDistance, square, "POINT(5 4)", float64(1)},
+		{ast.STDistance, nil, "POINT(5 4)", nil},
+		{ast.STContains, square, "POINT(1 1)", int64(1)},
+		{ast.STContains, square, "POINT(4 1)", int64(0)},
+		{ast.STWithin, "POINT(1 1)", square, int64(1)},
+		{ast.STWithin, "LINESTRING(1 1,5 1)", square, int64(0)},
+		{ast.MBRContains, square, "POINT(4 1)", int64(1)},
+		{ast.MBRContains, "POLYGON((0 0,4 0,0 4,0 0))", "POINT(3 3)", int64(1)},
+		{ast.MBRWithin, "POINT(5 5)", square, int64(0)},
+		{ast.MBRIntersects, "LINESTRING(4 4,5 5)", square, int64(1)},
+		{ast.MBRIntersects, "POINT(5 5)", square, int64(0)},
+		{ast.MBRIntersects, "POINT(5 5)", nil, nil},
+	}
+	for _, t := range cases {
+		f, err := newFunctionForTest(s.ctx, t.funcName, s.newGeomFromText(c, t.g1), s.newGeomFromText(c, t.g2))
+		c.Assert(err, IsNil)
+		d, err := f.Eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d.GetValue(), Equals, t.result, Commentf("%s(%v, %v)", t.funcName, t.g1, t.g2))
+	}
+
+	g2, err := newFunctionForTest(s.ctx, ast.STGeomFromText, primitiveValsToConstants([]interface{}{"POINT(1 1)", 4326})...)
+	c.Assert(err, IsNil)
+	f, err := newFunctionForTest(s.ctx, ast.STContains, s.newGeomFromText(c, square), g2)
+	c.Assert(err, IsNil)
+	_, err = f.Eval(nil)
+	c.Assert(err, NotNil)
+}
+
+func (s *testEvaluatorSuite) TestPoint(c *C) {
+	defer testleak.AfterTest(c)()
+	f, err := newFunctionForTest(s.ctx, ast.Point, primitiveValsToConstants([]interface{}{1.5, -2})...)
+	c.Assert(err, IsNil)
+	f, err = newFunctionForTest(s.ctx, ast.STAsBinary, f)
+	c.Assert(err, IsNil)
+	d, err := f.Eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetBytes(), DeepEquals, geo.NewPoint(1.5, -2, 0).WKB())
+
+	f, err = newFunctionForTest(s.ctx, ast.Point, primitiveValsToConstants([]interface{}{1, nil})...)
+	c.Assert(err, IsNil)
+	d, err = f.Eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.IsNull(), IsTrue)
+}
@@ -15,6 +15,7 @@ package expression
 
 import (
 	"reflect"
+	"strings"
 
 	"github.com/juju/errors"
 	. "github.com/pingcap/check"
@@ -22,6 +23,8 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/userlock"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
@@ -189,18 +192,20 @@ func (s *testEvaluatorSuite) TestLock(c *C) {
 	defer testleak.AfterTest(c)()
 
 	lock := funcs[ast.GetLock]
-	f, err := lock.getFunction(s.ctx, datumsToConstants(types.MakeDatums(nil, 1)))
-	c.Assert(err, IsNil)
-	v, err := f.eval(nil)
-	c.Assert(err, IsNil)
-	c.Assert(v.GetInt64(), Equals, int64(1))
+	for _, name := range []interface{}{nil, "", strings.Repeat("a", userlock.MaxNameLength+1)} {
+		f, err := lock.getFunction(s.ctx, datumsToConstants(types.MakeDatums(name, 1)))
+		c.Assert(err, IsNil)
+		c.Assert(f.canBeFolded(), IsFalse)
+		_, err = f.eval(nil)
+		c.Assert(terror.ErrorEqual(err, errUserLockWrongName), IsTrue, Commentf("%v", err))
+	}
 
+	// The mock context doesn't have a Holder of user-level locks.
 	releaseLock := funcs[ast.ReleaseLock]
-	f, err = releaseLock.getFunction(s.ctx, datumsToConstants(types.MakeDatums(1)))
-	c.Assert(err, IsNil)
-	v, err = f.eval(nil)
+	f, err := releaseLock.getFunction(s.ctx, datumsToConstants(types.MakeDatums("a")))
 	c.Assert(err, IsNil)
-	c.Assert(v.GetInt64(), Equals, int64(1))
+	_, err = f.eval(nil)
+	c.Assert(terror.ErrorEqual(err, errUserLockUnavailable), IsTrue, Commentf("%v", err))
 }
 
 // newFunctionForTest creates a new ScalarFunction using funcName and arguments,
@@ -1274,7 +1274,7 @@ type strToDateFunctionClass struct {
 
 func (c *strToDateFunctionClass) getRetTp(arg Expression, ctx context.Context) (tp byte, fsp int) {
 	tp = mysql.TypeDatetime
-	if _, ok := arg.(*Constant); !ok {
+	if con, ok := arg.(*Constant); !ok || con.DeferredExpr != nil {
 		return tp, types.MaxFsp
 	}
 	strArg := WrapWithCastAsString(arg, ctx)
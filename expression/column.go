@@ -126,6 +126,8 @@ type Column struct {
 	// IsAggOrSubq means if this column is referenced to a Aggregation column or a Subquery column.
 	// If so, this column's name will be the plain sql text.
 	IsAggOrSubq bool
+	// IsHidden means the column is a hidden column of the table, it can't be expanded by the wildcard.
+	IsHidden bool
 
 	// Index is only used for execution.
 	Index int
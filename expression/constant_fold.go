@@ -24,12 +24,14 @@ func FoldConstant(expr Expression) Expression {
 		return expr
 	}
 	args := scalarFunc.GetArgs()
-	canFold := true
+	canFold, deferred := true, false
 	for i := 0; i < len(args); i++ {
 		foldedArg := FoldConstant(args[i])
 		scalarFunc.GetArgs()[i] = foldedArg
-		if _, ok := foldedArg.(*Constant); !ok {
+		if con, ok := foldedArg.(*Constant); !ok {
 			canFold = false
+		} else if con.DeferredExpr != nil {
+			deferred = true
 		}
 	}
 	if !canFold {
@@ -40,6 +42,14 @@ func FoldConstant(expr Expression) Expression {
 		log.Warnf("fold constant %s: %s", scalarFunc.ExplainInfo(), err.Error())
 		return expr
 	}
+	if deferred {
+		// The value may change when the function is evaluated again with other parameters.
+		return &Constant{
+			Value:        value,
+			RetType:      scalarFunc.RetType,
+			DeferredExpr: scalarFunc,
+		}
+	}
 	return &Constant{
 		Value:   value,
 		RetType: scalarFunc.RetType,
@@ -173,7 +173,7 @@ func (s *propagateConstantSolver) pickNewEQConds(visited []bool) (retMapper map[
 		// Then we check if this CNF item is a false constant. If so, we will set the whole condition to false.
 		ok := false
 		if col == nil {
-			if con, ok = cond.(*Constant); ok {
+			if con, ok = cond.(*Constant); ok && con.DeferredExpr == nil {
 				value, _ := EvalBool([]Expression{con}, nil, s.ctx)
 				if !value {
 					s.setConds2ConstFalse()
@@ -198,12 +198,16 @@ func (s *propagateConstantSolver) pickNewEQConds(visited []bool) (retMapper map[
 // tryToUpdateEQList tries to update the eqList. When the eqList has store this column with a different constant, like
 // a = 1 and a = 2, we set the second return value to false.
 func (s *propagateConstantSolver) tryToUpdateEQList(col *Column, con *Constant) (bool, bool) {
-	if con.Value.IsNull() {
+	if con.Value.IsNull() && con.DeferredExpr == nil {
 		return false, true
 	}
 	id := s.getColID(col)
 	oldCon := s.eqList[id]
 	if oldCon != nil {
+		if oldCon.DeferredExpr != nil || con.DeferredExpr != nil {
+			// The values of the constants are unknown until they are evaluated.
+			return false, false
+		}
 		return false, !oldCon.Equal(con, s.ctx)
 	}
 	s.eqList[id] = con
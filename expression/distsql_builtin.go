@@ -74,16 +74,18 @@ var distFuncs = map[tipb.ExprType]string{
 	tipb.ExprType_Coalesce: ast.Coalesce,
 
 	// for json functions.
-	tipb.ExprType_JsonType:    ast.JSONType,
-	tipb.ExprType_JsonExtract: ast.JSONExtract,
-	tipb.ExprType_JsonUnquote: ast.JSONUnquote,
-	tipb.ExprType_JsonMerge:   ast.JSONMerge,
-	tipb.ExprType_JsonSet:     ast.JSONSet,
-	tipb.ExprType_JsonInsert:  ast.JSONInsert,
-	tipb.ExprType_JsonReplace: ast.JSONReplace,
-	tipb.ExprType_JsonRemove:  ast.JSONRemove,
-	tipb.ExprType_JsonArray:   ast.JSONArray,
-	tipb.ExprType_JsonObject:  ast.JSONObject,
+	tipb.ExprType_JsonType:     ast.JSONType,
+	tipb.ExprType_JsonExtract:  ast.JSONExtract,
+	tipb.ExprType_JsonUnquote:  ast.JSONUnquote,
+	tipb.ExprType_JsonValid:    ast.JSONValid,
+	tipb.ExprType_JsonMerge:    ast.JSONMerge,
+	tipb.ExprType_JsonSet:      ast.JSONSet,
+	tipb.ExprType_JsonInsert:   ast.JSONInsert,
+	tipb.ExprType_JsonReplace:  ast.JSONReplace,
+	tipb.ExprType_JsonRemove:   ast.JSONRemove,
+	tipb.ExprType_JsonArray:    ast.JSONArray,
+	tipb.ExprType_JsonObject:   ast.JSONObject,
+	tipb.ExprType_JsonContains: ast.JSONContains,
 }
 
 func pbTypeToFieldType(tp *tipb.FieldType) *types.FieldType {
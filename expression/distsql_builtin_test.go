@@ -272,6 +272,23 @@ func (s *testEvalSuite) TestEval(c *C) {
 			buildExpr(tipb.ExprType_Mod, types.NewFloat64Datum(3.0), types.NewFloat64Datum(1.9)),
 			types.NewFloat64Datum(1.1),
 		},
+		// Json functions.
+		{
+			buildExpr(tipb.ExprType_JsonValid, types.NewStringDatum(`{"a": 1}`)),
+			types.NewIntDatum(1),
+		},
+		{
+			buildExpr(tipb.ExprType_JsonValid, types.NewStringDatum(`{"a": 1`)),
+			types.NewIntDatum(0),
+		},
+		{
+			buildExpr(tipb.ExprType_JsonContains, types.NewStringDatum(`[1, 2]`), types.NewStringDatum(`1`)),
+			types.NewIntDatum(1),
+		},
+		{
+			buildExpr(tipb.ExprType_JsonContains, types.NewStringDatum(`[1, 2]`), types.NewStringDatum(`3`)),
+			types.NewIntDatum(0),
+		},
 	}
 	sc := new(variable.StatementContext)
 	for _, tt := range tests {
@@ -82,6 +82,14 @@ func (pc pbConverter) constantToPBExpr(con *Constant) *tipb.Expr {
 		d   = con.Value
 		ft  = con.GetType()
 	)
+	if con.DeferredExpr != nil {
+		var err error
+		d, err = con.Eval(nil)
+		if err != nil {
+			log.Errorf("eval deferred constant %s: %v", con, err)
+			return nil
+		}
+	}
 
 	switch d.Kind() {
 	case types.KindNull:
@@ -233,11 +241,11 @@ func (pc pbConverter) likeToPBExpr(expr *ScalarFunction) *tipb.Expr {
 	}
 	// Only patterns like 'abc', '%abc', 'abc%', '%abc%' can be converted to *tipb.Expr for now.
 	escape, ok := expr.GetArgs()[2].(*Constant)
-	if !ok || escape.Value.IsNull() || byte(escape.Value.GetInt64()) != '\\' {
+	if !ok || escape.DeferredExpr != nil || escape.Value.IsNull() || byte(escape.Value.GetInt64()) != '\\' {
 		return nil
 	}
 	pattern, ok := expr.GetArgs()[1].(*Constant)
-	if !ok || pattern.Value.Kind() != types.KindString {
+	if !ok || pattern.DeferredExpr != nil || pattern.Value.Kind() != types.KindString {
 		return nil
 	}
 	for i, b := range pattern.Value.GetString() {
@@ -351,6 +359,9 @@ func (pc pbConverter) constListToPB(list []Expression) *tipb.Expr {
 		if !ok {
 			return nil
 		}
+		if v.DeferredExpr != nil {
+			return nil
+		}
 		d := pc.constantToPBExpr(v)
 		if d == nil {
 			return nil
@@ -37,6 +37,8 @@ var (
 	errZlibZData               = terror.ClassTypes.New(codeZlibZData, "ZLIB: Input data corrupted")
 	errIncorrectArgs           = terror.ClassExpression.New(codeIncorrectArgs, mysql.MySQLErrName[mysql.ErrWrongArguments])
 	ErrIncorrectParameterCount = terror.ClassExpression.New(codeIncorrectParameterCount, "Incorrect parameter count in the call to native function '%s'")
+	errUserLockWrongName       = terror.ClassExpression.New(codeUserLockWrongName, mysql.MySQLErrName[mysql.ErrUserLockWrongName])
+	errUserLockUnavailable     = terror.ClassExpression.New(codeUserLockUnavailable, "user-level lock is not available in this session")
 )
 
 // Error codes.
@@ -46,6 +48,8 @@ const (
 	codeFunctionNotExists                      = 1305
 	codeZlibZData                              = mysql.ErrZlibZData
 	codeIncorrectArgs                          = mysql.ErrWrongArguments
+	codeUserLockWrongName                      = mysql.ErrUserLockWrongName
+	codeUserLockUnavailable                    = 2
 )
 
 func init() {
@@ -54,6 +58,7 @@ func init() {
 		codeFunctionNotExists:       mysql.ErrSpDoesNotExist,
 		codeZlibZData:               mysql.ErrZlibZData,
 		codeIncorrectArgs:           mysql.ErrWrongArguments,
+		codeUserLockWrongName:       mysql.ErrUserLockWrongName,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassExpression] = expressionMySQLErrCodes
 }
@@ -64,6 +69,9 @@ var TurnOnNewExprEval int32
 // EvalAstExpr evaluates ast expression directly.
 var EvalAstExpr func(expr ast.ExprNode, ctx context.Context) (types.Datum, error)
 
+// RewriteAstExpr rewrites ast expression directly, the columns in the expression are resolved by the schema.
+var RewriteAstExpr func(expr ast.ExprNode, schema *Schema, ctx context.Context) (Expression, error)
+
 // Expression represents all scalar expression in SQL.
 type Expression interface {
 	fmt.Stringer
@@ -294,6 +302,10 @@ var Null = &Constant{
 type Constant struct {
 	Value   types.Datum
 	RetType *types.FieldType
+	// DeferredExpr holds the expression which computes the value of the constant when it's evaluated,
+	// it's used by the parameters of the prepared statements whose plans are cached. Value is only the
+	// value when the plan is built.
+	DeferredExpr Expression
 }
 
 // String implements fmt.Stringer interface.
@@ -325,6 +337,10 @@ func (c *Constant) GetTypeClass() types.TypeClass {
 
 // Eval implements Expression interface.
 func (c *Constant) Eval(_ []types.Datum) (types.Datum, error) {
+	if c.DeferredExpr != nil {
+		d, err := c.DeferredExpr.Eval(nil)
+		return d, errors.Trace(err)
+	}
 	return c.Value, nil
 }
 
@@ -394,6 +410,9 @@ func (c *Constant) Equal(b Expression, ctx context.Context) bool {
 	if !ok {
 		return false
 	}
+	if c.DeferredExpr != nil || y.DeferredExpr != nil {
+		return c.DeferredExpr != nil && y.DeferredExpr != nil && c.DeferredExpr.Equal(y.DeferredExpr, ctx)
+	}
 	con, err := c.Value.CompareDatum(ctx.GetSessionVars().StmtCtx, y.Value)
 	if err != nil || con != 0 {
 		return false
@@ -413,6 +432,9 @@ func (c *Constant) Decorrelate(_ *Schema) Expression {
 
 // HashCode implements Expression interface.
 func (c *Constant) HashCode() []byte {
+	if c.DeferredExpr != nil {
+		return c.DeferredExpr.HashCode()
+	}
 	var bytes []byte
 	bytes, _ = codec.EncodeValue(bytes, c.Value)
 	return bytes
@@ -509,7 +531,7 @@ func EvaluateExprWithNull(ctx context.Context, schema *Schema, expr Expression)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		return FoldConstant(newFunc), nil
+		return unknownIfDeferred(FoldConstant(newFunc)), nil
 	case *Column:
 		if !schema.Contains(x) {
 			return x, nil
@@ -517,10 +539,19 @@ func EvaluateExprWithNull(ctx context.Context, schema *Schema, expr Expression)
 		constant := &Constant{Value: types.Datum{}, RetType: types.NewFieldType(mysql.TypeNull)}
 		return constant, nil
 	default:
-		return x.Clone(), nil
+		return unknownIfDeferred(x.Clone()), nil
 	}
 }
 
+// unknownIfDeferred returns the deferred expression of a constant, the value of the constant is unknown
+// until it's evaluated.
+func unknownIfDeferred(expr Expression) Expression {
+	if con, ok := expr.(*Constant); ok && con.DeferredExpr != nil {
+		return con.DeferredExpr
+	}
+	return expr
+}
+
 // TableInfo2Schema converts table info to schema.
 func TableInfo2Schema(tbl *model.TableInfo) *Schema {
 	cols := ColumnInfos2Columns(tbl.Name, tbl.Columns)
@@ -15,6 +15,8 @@ package expression_test
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"strings"
 	"time"
 
@@ -31,6 +33,7 @@ import (
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
 	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tidb/util/types/geo"
 )
 
 var _ = Suite(&testIntegrationSuite{})
@@ -957,6 +960,78 @@ func (s *testIntegrationSuite) TestEncryptionBuiltin(c *C) {
 	result.Check(testkit.Rows("<nil>"))
 }
 
+func (s *testIntegrationSuite) TestLegacyEncryptionBuiltin(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a varchar(20), b int)")
+	tk.MustExec("insert into t values ('hello', 123)")
+
+	// for DES_ENCRYPT and DES_DECRYPT
+	result := tk.MustQuery("select hex(des_encrypt(a, 'key')), des_decrypt(des_encrypt(a, 'key'), 'key'), des_decrypt(des_encrypt(b, 'key'), 'key') from t")
+	result.Check(testkit.Rows("FFB6D0B8D9BBB00BEC hello 123"))
+	result = tk.MustQuery("select des_encrypt(NULL, 'key'), des_encrypt('', 'key'), des_decrypt('hello', 'key'), des_decrypt(NULL, 'key')")
+	result.Check(testkit.Rows("<nil>  hello <nil>"))
+
+	// for ENCODE and DECODE
+	result = tk.MustQuery("select decode(encode(a, 'pass'), 'pass'), length(encode(a, 'pass')), encode(NULL, 'pass'), decode(a, NULL) from t")
+	result.Check(testkit.Rows("hello 5 <nil> <nil>"))
+
+	// for ENCRYPT
+	result = tk.MustQuery("select encrypt('test', 'ab'), encrypt(a, 'xy'), encrypt('', 'ab'), encrypt('test', 'a'), length(encrypt(a)) from t")
+	result.Check(testkit.Rows("abgOeLfPimXQo xyJ5nqog.skwc  <nil> 13"))
+
+	// for OLD_PASSWORD
+	result = tk.MustQuery("select old_password('mypass'), old_password(''), old_password(NULL), old_password(b) from t")
+	result.Check(testkit.Rows("6f8c114b58f2ce9e  <nil> 773359240eb9a1d9"))
+}
+
+func (s *testIntegrationSuite) TestValidatePasswordStrength(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	result := tk.MustQuery("select validate_password_strength('abc'), validate_password_strength('abcd'), validate_password_strength('abcdefgh'), validate_password_strength('Abcdef1!'), validate_password_strength(NULL)")
+	result.Check(testkit.Rows("0 25 50 100 <nil>"))
+	f, err := ioutil.TempFile("", "password_dictionary")
+	c.Assert(err, IsNil)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("BCDE\nfoo\n")
+	c.Assert(err, IsNil)
+	f.Close()
+	tk.MustExec(fmt.Sprintf("set @@global.validate_password_dictionary_file = '%s'", f.Name()))
+	tk.MustExec("set @@global.validate_password_length = 4")
+	result = tk.MustQuery("select validate_password_strength('abcd'), validate_password_strength('Abcdef1!'), validate_password_strength('Abxdef1!')")
+	result.Check(testkit.Rows("50 75 100"))
+	tk.MustExec("set @@global.validate_password_mixed_case_count = 2")
+	result = tk.MustQuery("select validate_password_strength('Abxdef1!'), validate_password_strength('ABxdef1!')")
+	result.Check(testkit.Rows("50 100"))
+	tk.MustExec("set @@global.validate_password_mixed_case_count = 1")
+
+	// The dictionary is reloaded when the file is changed.
+	c.Assert(ioutil.WriteFile(f.Name(), []byte("bxde\n"), 0644), IsNil)
+	result = tk.MustQuery("select validate_password_strength('Abcdef1!'), validate_password_strength('Abxdef1!')")
+	result.Check(testkit.Rows("100 75"))
+	// The dictionary is reloaded when the variable is changed.
+	f2, err := ioutil.TempFile("", "password_dictionary")
+	c.Assert(err, IsNil)
+	defer os.Remove(f2.Name())
+	_, err = f2.WriteString("cdef\n")
+	c.Assert(err, IsNil)
+	f2.Close()
+	tk.MustExec(fmt.Sprintf("set @@global.validate_password_dictionary_file = '%s'", f2.Name()))
+	result = tk.MustQuery("select validate_password_strength('Abcdef1!'), validate_password_strength('Abxdef1!')")
+	result.Check(testkit.Rows("75 100"))
+
+	tk.MustExec("set @@global.validate_password_dictionary_file = ''")
+	tk.MustExec("set @@global.validate_password_length = 8")
+}
+
 func (s *testIntegrationSuite) TestTimeBuiltin(c *C) {
 	originSQLMode := s.ctx.GetSessionVars().StrictSQLMode
 	s.ctx.GetSessionVars().StrictSQLMode = true
@@ -1933,6 +2008,34 @@ func (s *testIntegrationSuite) TestInfoBuiltin(c *C) {
 	// for version
 	result = tk.MustQuery("select version()")
 	result.Check(testkit.Rows(mysql.ServerVersion))
+
+	// for charset, collation and coercibility
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a varchar(10), b int, c datetime, d varchar(10) charset latin1 collate latin1_bin, e blob)")
+	tk.MustExec("insert into t values ('a', 1, '2017-01-01', 'd', 'e')")
+	result = tk.MustQuery("select charset(a), charset(b), charset(c), charset(d), charset(e) from t")
+	result.Check(testkit.Rows("utf8 binary binary latin1 binary"))
+	result = tk.MustQuery("select collation(a), collation(b), collation(d), collation(e) from t")
+	result.Check(testkit.Rows("utf8_bin binary latin1_bin binary"))
+	result = tk.MustQuery("select coercibility(a), coercibility(b), coercibility(c), coercibility(e) from t")
+	result.Check(testkit.Rows("2 5 5 2"))
+	result = tk.MustQuery("select charset('abc'), charset(1), charset(NULL), charset(cast(1 as char)), charset(cast('a' as binary)), charset(user())")
+	result.Check(testkit.Rows("utf8 binary binary utf8 binary utf8"))
+	result = tk.MustQuery("select collation('abc'), collation(1.5), collation(cast(1 as char)), collation(concat('a', 1))")
+	result.Check(testkit.Rows("utf8_bin binary utf8_bin utf8_bin"))
+	result = tk.MustQuery("select coercibility('abc'), coercibility(1), coercibility(1.5), coercibility(NULL), coercibility(now()), coercibility(cast(1 as char))")
+	result.Check(testkit.Rows("4 5 5 6 5 2"))
+	result = tk.MustQuery("select coercibility(user()), coercibility(version()), coercibility(database()), coercibility(charset('a')), coercibility(collation('a'))")
+	result.Check(testkit.Rows("3 3 3 3 3"))
+	result = tk.MustQuery("select coercibility(concat('a', 'b')), coercibility(concat('a', 1)), coercibility(concat(a, 'b')), coercibility(concat(user(), 'a')), coercibility(upper(a)), coercibility(hex(1)) from t")
+	result.Check(testkit.Rows("4 4 2 3 2 4"))
+
+	// for benchmark
+	result = tk.MustQuery("select benchmark(3, a), benchmark(0, 1), benchmark(NULL, 1), benchmark(-1, 1) from t")
+	result.Check(testkit.Rows("0 0 <nil> <nil>"))
+	tk.MustQuery("show warnings").Check(testutil.RowsWithSep("|", "Warning|1210|Incorrect arguments to benchmark"))
+	_, err := tk.Exec("select benchmark(1, 1 + 'a') from t")
+	c.Assert(err, IsNil)
 }
 
 func (s *testIntegrationSuite) TestControlBuiltin(c *C) {
@@ -2308,6 +2411,116 @@ func (s *testIntegrationSuite) TestOtherBuiltin(c *C) {
 	result.Check(testkit.Rows("5 64 <nil> 7"))
 }
 
+func (s *testIntegrationSuite) TestUserLock(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use test")
+	tk1.Se.SetConnectionID(1)
+	tk2 := testkit.NewTestKit(c, s.store)
+	tk2.MustExec("use test")
+	tk2.Se.SetConnectionID(2)
+
+	tk1.MustQuery("select get_lock('l1', 0), get_lock('L1', 0), is_free_lock('l1'), is_used_lock('l1')").Check(testkit.Rows("1 1 0 1"))
+	tk2.MustQuery("select get_lock('l1', 0), is_free_lock('l2'), is_used_lock('l2')").Check(testkit.Rows("0 1 <nil>"))
+	start := time.Now()
+	tk2.MustQuery("select get_lock('l1', 1)").Check(testkit.Rows("0"))
+	c.Assert(time.Since(start) >= time.Second, IsTrue)
+	tk1.MustQuery("select get_lock('l2', 0)").Check(testkit.Rows("1"))
+	tk2.MustQuery("select lock_name, connection_id from information_schema.user_locks").Check(testkit.Rows("l1 1", "l2 1"))
+
+	tk2.MustQuery("select release_lock('l1'), release_lock('l3')").Check(testkit.Rows("0 <nil>"))
+	tk1.MustQuery("select release_lock('l1'), is_used_lock('l1')").Check(testkit.Rows("1 1"))
+	tk1.MustQuery("select release_lock('l1'), is_used_lock('l1'), is_free_lock('l1')").Check(testkit.Rows("1 <nil> 1"))
+
+	// The waiting session gets the lock after it's released.
+	tk2.MustQuery("select get_lock('l2', 0)").Check(testkit.Rows("0"))
+	ch := make(chan struct{})
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		tk1.MustQuery("select release_all_locks()").Check(testkit.Rows("1"))
+		close(ch)
+	}()
+	tk2.MustQuery("select get_lock('l2', -1), is_used_lock('l2')").Check(testkit.Rows("1 2"))
+	<-ch
+
+	// The locks are released when the session is closed.
+	tk2.Se.Close()
+	tk1.MustQuery("select is_free_lock('l2')").Check(testkit.Rows("1"))
+	tk1.MustQuery("select count(*) from information_schema.user_locks").Check(testkit.Rows("0"))
+
+	rs, err := tk1.Exec("select get_lock(null, 0)")
+	c.Assert(err, IsNil)
+	_, err = tidb.GetRows(rs)
+	c.Assert(err, NotNil)
+	terr := errors.Trace(err).(*errors.Err).Cause().(*terror.Error)
+	c.Assert(terr.Code(), Equals, terror.ErrCode(mysql.ErrUserLockWrongName))
+}
+
+func (s *testIntegrationSuite) TestSpatialBuiltin(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int, g geometry, p point, pg polygon)")
+	tk.MustQuery("show create table t").Check(testkit.Rows("t CREATE TABLE `t` (\n" +
+		"  `id` int(11) DEFAULT NULL,\n" +
+		"  `g` geometry DEFAULT NULL,\n" +
+		"  `p` point DEFAULT NULL,\n" +
+		"  `pg` polygon DEFAULT NULL\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+	tk.MustExec(`insert into t values
+		(1, st_geomfromtext('LINESTRING(0 0,10 10)'), point(1, 2), st_geomfromtext('POLYGON((0 0,10 0,10 10,0 10,0 0))')),
+		(2, point(20, 20), st_geomfromtext('POINT(5 5)'), st_geomfromtext('POLYGON((0 0,4 0,4 4,0 4,0 0),(1 1,1 2,2 2,1 1))')),
+		(3, null, null, null)`)
+	tk.MustQuery("select id, st_astext(g), st_astext(p), st_geometrytype(pg), st_x(p), st_y(p) from t").Check(testkit.Rows(
+		"1 LINESTRING(0 0,10 10) POINT(1 2) POLYGON 1 2",
+		"2 POINT(20 20) POINT(5 5) POLYGON 5 5",
+		"3 <nil> <nil> <nil> <nil> <nil>"))
+
+	// The values are stored in the internal binary format.
+	tk.MustQuery("select hex(p), hex(st_asbinary(p)) from t where id = 1").Check(testkit.Rows(
+		"000000000101000000000000000000F03F0000000000000040 0101000000000000000000F03F0000000000000040"))
+	tk.MustQuery("select st_astext(st_geomfromwkb(st_asbinary(g))) from t where id = 2").Check(testkit.Rows("POINT(20 20)"))
+	_, err := tk.Exec("insert into t (p) values ('POINT(1 1)')")
+	c.Assert(terror.ErrorEqual(err, geo.ErrCantCreateGeometryObject), IsTrue, Commentf("%v", err))
+	_, err = tk.Exec("insert into t (p) values (st_geomfromtext('LINESTRING(0 0,1 1)'))")
+	c.Assert(terror.ErrorEqual(err, geo.ErrCantCreateGeometryObject), IsTrue, Commentf("%v", err))
+	_, err = tk.Exec("create table t1 (g geometry default 'a')")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("create index idx on t (g)")
+	c.Assert(err, NotNil)
+
+	// Spatial relations.
+	tk.MustQuery("select id, st_distance(p, pg), st_contains(pg, p), st_within(p, pg), st_contains(pg, g) from t").Check(testkit.Rows(
+		"1 0 1 1 1", "2 1.4142135623730951 0 0 0", "3 <nil> <nil> <nil> <nil>"))
+	tk.MustQuery("select id from t where st_within(point(1.2, 1.5), pg)").Check(testkit.Rows("1"))
+	tk.MustQuery("select id from t where mbrcontains(pg, point(1.2, 1.5))").Check(testkit.Rows("1", "2"))
+	tk.MustQuery("select id from t where mbrwithin(g, st_geomfromtext('POLYGON((0 0,15 0,15 15,0 15,0 0))'))").Check(testkit.Rows("1"))
+	tk.MustQuery("select id from t where mbrintersects(g, pg)").Check(testkit.Rows("1"))
+	tk.MustQuery("select st_astext(st_envelope(g)) from t where id = 1").Check(testkit.Rows("POLYGON((0 0,10 0,10 10,0 10,0 0))"))
+	tk.MustQuery("select st_distance(point(0, 0), point(3, 4)), st_x(st_geomfromtext('LINESTRING(0 0,1 1)'))").Check(testkit.Rows("5 <nil>"))
+
+	// Invalid geometry values and SRIDs.
+	rs, err := tk.Exec("select st_geomfromtext('POINT(1)')")
+	c.Assert(err, IsNil)
+	_, err = tidb.GetRows(rs)
+	c.Assert(terror.ErrorEqual(err, geo.ErrInvalidGISData), IsTrue, Commentf("%v", err))
+	rs, err = tk.Exec("select st_astext('abc')")
+	c.Assert(err, IsNil)
+	_, err = tidb.GetRows(rs)
+	c.Assert(terror.ErrorEqual(err, geo.ErrInvalidGISData), IsTrue, Commentf("%v", err))
+	rs, err = tk.Exec("select st_distance(point(1, 1), st_geomfromtext('POINT(1 1)', 4326))")
+	c.Assert(err, IsNil)
+	_, err = tidb.GetRows(rs)
+	c.Assert(terror.ErrorEqual(err, geo.ErrDifferentSRIDs), IsTrue, Commentf("%v", err))
+}
+
 func (s *testIntegrationSuite) TestDateBuiltin(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -105,10 +105,24 @@ func (s *Schema) FindColumn(astCol *ast.ColumnName) (*Column, error) {
 
 // FindColumnAndIndex finds an Column and its index from schema for a ast.ColumnName.
 // It compares the db/table/column names. If there are more than one result, raise ambiguous error.
+// The hidden columns can't be found by name.
 func (s *Schema) FindColumnAndIndex(astCol *ast.ColumnName) (*Column, int, error) {
+	return s.findColumnAndIndex(astCol, false)
+}
+
+// FindColumnIncludingHidden is like FindColumnAndIndex, but the hidden columns can be found too.
+// It's used to find the columns which are referenced by the system, e.g. the hidden generated columns.
+func (s *Schema) FindColumnIncludingHidden(astCol *ast.ColumnName) (*Column, int, error) {
+	return s.findColumnAndIndex(astCol, true)
+}
+
+func (s *Schema) findColumnAndIndex(astCol *ast.ColumnName, includeHidden bool) (*Column, int, error) {
 	dbName, tblName, colName := astCol.Schema, astCol.Table, astCol.Name
 	idx := -1
 	for i, col := range s.Columns {
+		if col.IsHidden && !includeHidden {
+			continue
+		}
 		if (dbName.L == "" || dbName.L == col.DBName.L) &&
 			(tblName.L == "" || tblName.L == col.TblName.L) &&
 			(colName.L == col.ColName.L) {
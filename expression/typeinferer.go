@@ -41,7 +41,7 @@ type typeInferrer struct {
 
 func (v *typeInferrer) Enter(in ast.Node) (out ast.Node, skipChildren bool) {
 	switch in.(type) {
-	case *ast.ColumnOption:
+	case *ast.ColumnOption, *ast.IndexColName:
 		return in, true
 	}
 	return in, false
@@ -82,12 +82,20 @@ func (v *typeInferrer) Leave(in ast.Node) (out ast.Node, ok bool) {
 		types.SetBinChsClnFlag(&x.Type)
 	case *ast.ParamMarkerExpr:
 		types.DefaultTypeForValue(x.GetValue(), x.GetType())
+		if x.Type.GetDerivation() == types.DerivationImplicit {
+			x.Type.Derivation = types.DerivationCoercible
+		}
 	case *ast.ParenthesesExpr:
 		x.SetType(x.Expr.GetType())
 	case *ast.SelectStmt:
 		v.selectStmt(x)
 	case *ast.UnaryOperationExpr:
 		v.unaryOperation(x)
+	case *ast.ValueExpr:
+		// A string literal is coercible, the derivation of other literals is implied by the type.
+		if x.Type.GetDerivation() == types.DerivationImplicit {
+			x.Type.Derivation = types.DerivationCoercible
+		}
 	case *ast.ValuesExpr:
 		v.handleValuesExpr(x)
 	case *ast.VariableExpr:
@@ -329,16 +337,19 @@ func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 		}
 	// number related
 	case ast.Ln, ast.Log, ast.Log2, ast.Log10, ast.Sqrt, ast.PI, ast.Exp, ast.Degrees, ast.Sin, ast.Cos, ast.Tan,
-		ast.Cot, ast.Acos, ast.Asin, ast.Atan, ast.Pow, ast.Power, ast.Rand, ast.Radians:
+		ast.Cot, ast.Acos, ast.Asin, ast.Atan, ast.Pow, ast.Power, ast.Rand, ast.Radians,
+		ast.STX, ast.STY, ast.STDistance:
 		tp = types.NewFieldType(mysql.TypeDouble)
 	case ast.MicroSecond, ast.Second, ast.Minute, ast.Hour, ast.Day, ast.Week, ast.Month, ast.Year,
 		ast.DayOfWeek, ast.DayOfMonth, ast.DayOfYear, ast.Weekday, ast.WeekOfYear, ast.YearWeek, ast.DateDiff,
 		ast.FoundRows, ast.Length, ast.ASCII, ast.Extract, ast.Locate, ast.UnixTimestamp, ast.Quarter, ast.IsIPv4, ast.ToDays,
 		ast.ToSeconds, ast.Strcmp, ast.IsNull, ast.BitLength, ast.CharLength, ast.CRC32, ast.TimestampDiff,
 		ast.Sign, ast.IsIPv6, ast.Ord, ast.Instr, ast.BitCount, ast.FindInSet, ast.Field,
-		ast.GetLock, ast.ReleaseLock, ast.Interval, ast.Position, ast.PeriodAdd, ast.PeriodDiff, ast.IsIPv4Mapped, ast.IsIPv4Compat, ast.UncompressedLength:
+		ast.GetLock, ast.ReleaseLock, ast.IsFreeLock, ast.ReleaseAllLocks, ast.Interval, ast.Position, ast.PeriodAdd, ast.PeriodDiff, ast.IsIPv4Mapped, ast.IsIPv4Compat, ast.UncompressedLength,
+		ast.Benchmark, ast.Coercibility, ast.STContains, ast.STWithin, ast.MBRContains, ast.MBRWithin, ast.MBRIntersects,
+		ast.JSONValid, ast.JSONContains, ast.JSONContainsPath, ast.JSONLength, ast.JSONDepth:
 		tp = types.NewFieldType(mysql.TypeLonglong)
-	case ast.ConnectionID, ast.InetAton:
+	case ast.ConnectionID, ast.InetAton, ast.IsUsedLock:
 		tp = types.NewFieldType(mysql.TypeLonglong)
 		tp.Flag |= mysql.UnsignedFlag
 	// time related
@@ -387,11 +398,14 @@ func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 		ast.DateFormat, ast.Rpad, ast.Lpad, ast.CharFunc, ast.Conv, ast.MakeSet, ast.Oct, ast.UUID,
 		ast.InsertFunc, ast.Bin, ast.Quote, ast.Format, ast.FromBase64, ast.ToBase64,
 		ast.ExportSet, ast.AesEncrypt, ast.AesDecrypt, ast.SHA2, ast.InetNtoa, ast.Inet6Aton,
-		ast.Inet6Ntoa, ast.PasswordFunc, ast.TiDBVersion:
+		ast.Inet6Ntoa, ast.PasswordFunc, ast.TiDBVersion, ast.Charset, ast.Collation, ast.STAsText, ast.STGeometryType:
 		tp = types.NewFieldType(mysql.TypeVarString)
 		chs = v.defaultCharset
-	case ast.RandomBytes:
+		tp.Derivation = v.funcDerivation(x)
+	case ast.RandomBytes, ast.STAsBinary:
 		tp = types.NewFieldType(mysql.TypeVarString)
+	case ast.Point, ast.STGeomFromText, ast.STGeomFromWKB, ast.STEnvelope:
+		tp = types.NewFieldType(mysql.TypeGeometry)
 	case ast.If:
 		// TODO: fix this
 		// See https://dev.mysql.com/doc/refman/5.5/en/control-flow-functions.html#function_if
@@ -409,7 +423,7 @@ func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 		tp = types.NewFieldType(mysql.TypeVarString)
 		chs = v.defaultCharset
 	case ast.JSONExtract, ast.JSONSet, ast.JSONInsert, ast.JSONReplace, ast.JSONRemove, ast.JSONMerge,
-		ast.JSONObject, ast.JSONArray:
+		ast.JSONObject, ast.JSONArray, ast.JSONKeys, ast.JSONSearch, ast.JSONArrayAppend:
 		tp = types.NewFieldType(mysql.TypeJSON)
 		chs = v.defaultCharset
 	case ast.AnyValue:
@@ -433,6 +447,23 @@ func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 	x.SetType(tp)
 }
 
+// funcDerivation returns the collation derivation of a function which returns a string. The result
+// of a function returning system information is a system constant, and the derivations of the
+// arguments are aggregated for other functions.
+func (v *typeInferrer) funcDerivation(x *ast.FuncCallExpr) types.Derivation {
+	switch x.FnName.L {
+	case ast.Version, ast.Database, ast.Schema, ast.User, ast.CurrentUser, ast.TiDBVersion, ast.Charset, ast.Collation:
+		return types.DerivationSysconst
+	}
+	argTps := make([]*types.FieldType, 0, len(x.Args))
+	for _, arg := range x.Args {
+		if arg.GetType() != nil {
+			argTps = append(argTps, arg.GetType())
+		}
+	}
+	return types.AggDerivation(argTps)
+}
+
 // handleCaseExpr decides the return type of a CASE expression which is the compatible aggregated type of all return values,
 // but also depends on the context in which it is used.
 // If used in a string context, the result is returned as a string.
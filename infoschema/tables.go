@@ -27,6 +27,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/userlock"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -63,6 +64,7 @@ const (
 	tableOptimizerTrace                     = "OPTIMIZER_TRACE"
 	tableTableSpaces                        = "TABLESPACES"
 	tableCollationCharacterSetApplicability = "COLLATION_CHARACTER_SET_APPLICABILITY"
+	tableUserLocks                          = "USER_LOCKS"
 )
 
 type columnInfo struct {
@@ -520,6 +522,14 @@ var tableCollationCharacterSetApplicabilityCols = []columnInfo{
 	{"TABLESPACE_COMMENT", mysql.TypeVarchar, 2048, 0, nil, nil},
 }
 
+// tableUserLocksCols is the columns of USER_LOCKS, it lists the user-level locks held in the cluster.
+var tableUserLocksCols = []columnInfo{
+	{"LOCK_NAME", mysql.TypeVarchar, 64, mysql.NotNullFlag, nil, nil},
+	{"SERVER_ID", mysql.TypeVarchar, 64, mysql.NotNullFlag, nil, nil},
+	{"CONNECTION_ID", mysql.TypeLonglong, 21, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{"EXPIRE_TIME", mysql.TypeDatetime, 19, 0, nil, nil},
+}
+
 func dataForCharacterSets() (records [][]types.Datum) {
 	records = append(records,
 		types.MakeDatums("ascii", "ascii_general_ci", "US ASCII", 1),
@@ -561,6 +571,26 @@ func dataForUserPrivileges(ctx context.Context) [][]types.Datum {
 	return pm.UserPrivilegesTable()
 }
 
+func dataForUserLocks(ctx context.Context) (records [][]types.Datum, err error) {
+	h := userlock.GetHolder(ctx)
+	if h == nil {
+		return nil, nil
+	}
+	locks, err := h.Manager().Locks()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, lock := range locks {
+		expire := types.Time{
+			Time: types.FromGoTime(lock.ExpireTime()),
+			Type: mysql.TypeDatetime,
+		}
+		record := types.MakeDatums(lock.Name, lock.ServerID, lock.ConnectionID, expire)
+		records = append(records, record)
+	}
+	return records, nil
+}
+
 func dataForEngines() (records [][]types.Datum) {
 	records = append(records,
 		types.MakeDatums("InnoDB", "DEFAULT", "Supports transactions, row-level locking, and foreign keys", "YES", "YES", "YES"),
@@ -633,6 +663,21 @@ func dataForTables(schemas []*model.DBInfo) [][]types.Datum {
 	rows := [][]types.Datum{}
 	for _, schema := range schemas {
 		for _, table := range schema.Tables {
+			if table.IsView() {
+				record := types.MakeDatums(
+					catalogVal,    // TABLE_CATALOG
+					schema.Name.O, // TABLE_SCHEMA
+					table.Name.O,  // TABLE_NAME
+					"VIEW",        // TABLE_TYPE
+				)
+				// The other columns are NULL for views, except that TABLE_COMMENT is "VIEW".
+				for i := len(record); i < len(tablesCols)-1; i++ {
+					record = append(record, types.Datum{})
+				}
+				record = append(record, types.NewStringDatum("VIEW"))
+				rows = append(rows, record)
+				continue
+			}
 			record := types.MakeDatums(
 				catalogVal,      // TABLE_CATALOG
 				schema.Name.O,   // TABLE_SCHEMA
@@ -662,6 +707,35 @@ func dataForTables(schemas []*model.DBInfo) [][]types.Datum {
 	return rows
 }
 
+func dataForViews(schemas []*model.DBInfo) [][]types.Datum {
+	rows := [][]types.Datum{}
+	for _, schema := range schemas {
+		for _, table := range schema.Tables {
+			if !table.IsView() {
+				continue
+			}
+			definer := ""
+			if table.View.Definer != nil {
+				definer = table.View.Definer.String()
+			}
+			record := types.MakeDatums(
+				catalogVal,                      // TABLE_CATALOG
+				schema.Name.O,                   // TABLE_SCHEMA
+				table.Name.O,                    // TABLE_NAME
+				table.View.SelectStmt,           // VIEW_DEFINITION
+				table.View.CheckOption.String(), // CHECK_OPTION
+				"NO",                            // IS_UPDATABLE
+				definer,                         // DEFINER
+				table.View.Security.String(),    // SECURITY_TYPE
+				table.View.Charset,              // CHARACTER_SET_CLIENT
+				table.View.Collate,              // COLLATION_CONNECTION
+			)
+			rows = append(rows, record)
+		}
+	}
+	return rows
+}
+
 func dataForColumns(schemas []*model.DBInfo) [][]types.Datum {
 	rows := [][]types.Datum{}
 	for _, schema := range schemas {
@@ -677,7 +751,11 @@ func dataForColumns(schemas []*model.DBInfo) [][]types.Datum {
 
 func dataForColumnsInTable(schema *model.DBInfo, tbl *model.TableInfo) [][]types.Datum {
 	rows := [][]types.Datum{}
-	for i, col := range tbl.Columns {
+	i := 0
+	for _, col := range tbl.Columns {
+		if col.Hidden {
+			continue
+		}
 		colLen, decimal := col.Flen, col.Decimal
 		defaultFlen, defaultDecimal := mysql.GetDefaultFieldLengthAndDecimal(col.Tp)
 		if colLen == types.UnspecifiedLength {
@@ -715,6 +793,7 @@ func dataForColumnsInTable(schema *model.DBInfo, tbl *model.TableInfo) [][]types
 			columnDesc.Comment,                // COLUMN_COMMENT
 		)
 		rows = append(rows, record)
+		i++
 	}
 	return rows
 }
@@ -974,6 +1053,7 @@ var tableNameToColumns = map[string]([]columnInfo){
 	tableOptimizerTrace:                     tableOptimizerTraceCols,
 	tableTableSpaces:                        tableTableSpacesCols,
 	tableCollationCharacterSetApplicability: tableCollationCharacterSetApplicabilityCols,
+	tableUserLocks:                          tableUserLocksCols,
 }
 
 func createInfoSchemaTable(handle *Handle, meta *model.TableInfo) *infoschemaTable {
@@ -1043,6 +1123,9 @@ func (it *infoschemaTable) getRows(ctx context.Context, cols []*table.Column) (f
 	case tableEngines:
 		fullRows = dataForEngines()
 	case tableViews:
+		fullRows = dataForViews(dbs)
+	case tableUserLocks:
+		fullRows, err = dataForUserLocks(ctx)
 	case tableRoutines:
 	// TODO: Fill the following tables.
 	case tableSchemaPrivileges:
@@ -20,11 +20,13 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util"
@@ -179,27 +181,65 @@ func ScanIndexData(txn kv.Transaction, kvIndex table.Index, startVals []types.Da
 // CompareIndexData compares index data one by one.
 // It returns nil if the data from the index is equal to the data from the table columns,
 // otherwise it returns an error with a different set of records.
-func CompareIndexData(txn kv.Transaction, t table.Table, idx table.Index) error {
-	err := checkIndexAndRecord(txn, t, idx)
+// The ctx is used to evaluate the virtual generated columns in the index.
+func CompareIndexData(ctx context.Context, txn kv.Transaction, t table.Table, idx table.Index) error {
+	err := checkIndexAndRecord(ctx, txn, t, idx)
 	if err != nil {
 		return errors.Trace(err)
 	}
 
-	return checkRecordAndIndex(txn, t, idx)
+	return checkRecordAndIndex(ctx, txn, t, idx)
 }
 
-func checkIndexAndRecord(txn kv.Transaction, t table.Table, idx table.Index) error {
+// indexColumns returns the columns of the index, and the columns which need to be read from the records.
+// The virtual generated columns are evaluated with the whole row, so all the columns are read if there is one.
+func indexColumns(t table.Table, idx table.Index) (idxCols, readCols []*table.Column) {
+	idxCols = make([]*table.Column, len(idx.Meta().Columns))
+	for i, col := range idx.Meta().Columns {
+		idxCols[i] = t.Cols()[col.Offset]
+	}
+	if tables.HasVirtualColumn(idxCols) {
+		return idxCols, t.Cols()
+	}
+	return idxCols, idxCols
+}
+
+// indexValues gets the index column values from the record values which are read by the columns from indexColumns.
+func indexValues(ctx context.Context, t table.Table, idx table.Index, idxCols []*table.Column,
+	vals []types.Datum) ([]types.Datum, error) {
+	if !tables.HasVirtualColumn(idxCols) {
+		return vals, nil
+	}
+	if err := tables.FillVirtualColumnValues(ctx, t, vals); err != nil {
+		return nil, errors.Trace(err)
+	}
+	idxVals := make([]types.Datum, len(idxCols))
+	for i, col := range idx.Meta().Columns {
+		idxVals[i] = vals[col.Offset]
+		if !idxCols[i].IsGenerated() || idxCols[i].GeneratedStored {
+			continue
+		}
+		// Encode and decode the evaluated value, so it's the same as the value decoded from kv.
+		b, err := tablecodec.EncodeValue(idxVals[i], time.UTC)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		idxVals[i], err = tablecodec.DecodeColumnValue(b, &idxCols[i].FieldType, time.UTC)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return idxVals, nil
+}
+
+func checkIndexAndRecord(ctx context.Context, txn kv.Transaction, t table.Table, idx table.Index) error {
 	it, err := idx.SeekFirst(txn)
 	if err != nil {
 		return errors.Trace(err)
 	}
 	defer it.Close()
 
-	cols := make([]*table.Column, len(idx.Meta().Columns))
-	for i, col := range idx.Meta().Columns {
-		cols[i] = t.Cols()[col.Offset]
-	}
-
+	idxCols, readCols := indexColumns(t, idx)
 	for {
 		vals1, h, err := it.Next()
 		if terror.ErrorEqual(err, io.EOF) {
@@ -208,7 +248,7 @@ func checkIndexAndRecord(txn kv.Transaction, t table.Table, idx table.Index) err
 			return errors.Trace(err)
 		}
 
-		vals2, err := rowWithCols(txn, t, h, cols)
+		vals2, err := rowWithCols(txn, t, h, readCols)
 		if kv.ErrNotExist.Equal(err) {
 			record := &RecordData{Handle: h, Values: vals1}
 			err = errDateNotEqual.Gen("index:%v != record:%v", record, nil)
@@ -216,6 +256,10 @@ func checkIndexAndRecord(txn kv.Transaction, t table.Table, idx table.Index) err
 		if err != nil {
 			return errors.Trace(err)
 		}
+		vals2, err = indexValues(ctx, t, idx, idxCols, vals2)
+		if err != nil {
+			return errors.Trace(err)
+		}
 		if !reflect.DeepEqual(vals1, vals2) {
 			record1 := &RecordData{Handle: h, Values: vals1}
 			record2 := &RecordData{Handle: h, Values: vals2}
@@ -226,14 +270,15 @@ func checkIndexAndRecord(txn kv.Transaction, t table.Table, idx table.Index) err
 	return nil
 }
 
-func checkRecordAndIndex(txn kv.Transaction, t table.Table, idx table.Index) error {
-	cols := make([]*table.Column, len(idx.Meta().Columns))
-	for i, col := range idx.Meta().Columns {
-		cols[i] = t.Cols()[col.Offset]
-	}
+func checkRecordAndIndex(ctx context.Context, txn kv.Transaction, t table.Table, idx table.Index) error {
+	idxCols, readCols := indexColumns(t, idx)
 
 	startKey := t.RecordKey(0)
 	filterFunc := func(h1 int64, vals1 []types.Datum, cols []*table.Column) (bool, error) {
+		vals1, err := indexValues(ctx, t, idx, idxCols, vals1)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
 		isExist, h2, err := idx.Exist(txn, vals1, h1)
 		if kv.ErrKeyExists.Equal(err) {
 			record1 := &RecordData{Handle: h1, Values: vals1}
@@ -250,7 +295,7 @@ func checkRecordAndIndex(txn kv.Transaction, t table.Table, idx table.Index) err
 
 		return true, nil
 	}
-	err := iterRecords(txn, t, startKey, cols, filterFunc)
+	err := iterRecords(txn, t, startKey, readCols, filterFunc)
 
 	if err != nil {
 		return errors.Trace(err)
@@ -436,7 +481,7 @@ func rowWithCols(txn kv.Retriever, t table.Table, h int64, cols []*table.Column)
 			continue
 		}
 		ri, ok := row[col.ID]
-		if !ok && mysql.HasNotNullFlag(col.Flag) {
+		if !ok && mysql.HasNotNullFlag(col.Flag) && (!col.IsGenerated() || col.GeneratedStored) {
 			return nil, errors.New("Miss")
 		}
 		v[i] = ri
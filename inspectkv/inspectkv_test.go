@@ -274,7 +274,7 @@ func (s *testSuite) TestScan(c *C) {
 
 	idxRow1 := &RecordData{Handle: int64(1), Values: types.MakeDatums(int64(10))}
 	idxRow2 := &RecordData{Handle: int64(2), Values: types.MakeDatums(int64(20))}
-	kvIndex := tables.NewIndex(tb.Meta(), indices[0].Meta())
+	kvIndex := tables.NewIndex(tb.Meta().ID, tb.Meta(), indices[0].Meta())
 	idxRows, nextVals, err := ScanIndexData(txn, kvIndex, idxRow1.Values, 2)
 	c.Assert(err, IsNil)
 	c.Assert(idxRows, DeepEquals, []*RecordData{idxRow1, idxRow2})
@@ -359,7 +359,7 @@ func (s *testSuite) testIndex(c *C, tb table.Table, idx table.Index) {
 	txn, err := s.store.Begin()
 	c.Assert(err, IsNil)
 
-	err = CompareIndexData(txn, tb, idx)
+	err = CompareIndexData(s.ctx, txn, tb, idx)
 	c.Assert(err, IsNil)
 
 	cnt, err := GetIndexRecordsCount(txn, idx, nil)
@@ -378,7 +378,7 @@ func (s *testSuite) testIndex(c *C, tb table.Table, idx table.Index) {
 
 	txn, err = s.store.Begin()
 	c.Assert(err, IsNil)
-	err = CompareIndexData(txn, tb, idx)
+	err = CompareIndexData(s.ctx, txn, tb, idx)
 	c.Assert(err, NotNil)
 	record1 := &RecordData{Handle: int64(3), Values: types.MakeDatums(int64(30))}
 	diffMsg := newDiffRetError("index", record1, nil)
@@ -396,7 +396,7 @@ func (s *testSuite) testIndex(c *C, tb table.Table, idx table.Index) {
 
 	txn, err = s.store.Begin()
 	c.Assert(err, IsNil)
-	err = CompareIndexData(txn, tb, idx)
+	err = CompareIndexData(s.ctx, txn, tb, idx)
 	c.Assert(err, NotNil)
 	record2 := &RecordData{Handle: int64(3), Values: types.MakeDatums(int64(31))}
 	diffMsg = newDiffRetError("index", record1, record2)
@@ -414,7 +414,7 @@ func (s *testSuite) testIndex(c *C, tb table.Table, idx table.Index) {
 
 	txn, err = s.store.Begin()
 	c.Assert(err, IsNil)
-	err = checkRecordAndIndex(txn, tb, idx)
+	err = checkRecordAndIndex(s.ctx, txn, tb, idx)
 	c.Assert(err, NotNil)
 	record2 = &RecordData{Handle: int64(5), Values: types.MakeDatums(int64(30))}
 	diffMsg = newDiffRetError("index", record1, record2)
@@ -432,7 +432,7 @@ func (s *testSuite) testIndex(c *C, tb table.Table, idx table.Index) {
 
 	txn, err = s.store.Begin()
 	c.Assert(err, IsNil)
-	err = CompareIndexData(txn, tb, idx)
+	err = CompareIndexData(s.ctx, txn, tb, idx)
 	c.Assert(err, NotNil)
 	record1 = &RecordData{Handle: int64(4), Values: types.MakeDatums(int64(40))}
 	diffMsg = newDiffRetError("index", record1, nil)
@@ -450,7 +450,7 @@ func (s *testSuite) testIndex(c *C, tb table.Table, idx table.Index) {
 
 	txn, err = s.store.Begin()
 	c.Assert(err, IsNil)
-	err = CompareIndexData(txn, tb, idx)
+	err = CompareIndexData(s.ctx, txn, tb, idx)
 	c.Assert(err, NotNil)
 	diffMsg = newDiffRetError("index", nil, record1)
 	c.Assert(err.Error(), DeepEquals, diffMsg)
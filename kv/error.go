@@ -34,8 +34,11 @@ const (
 	codeNotImplemented                            = 10
 	codeTxnTooLarge                               = 11
 	codeEntryTooLarge                             = 12
+	codeWriteConflict                             = 13
 
-	codeKeyExists = 1062
+	codeKeyExists       = 1062
+	codeLockWaitTimeout = 1205
+	codeDeadlock        = 1213
 )
 
 var (
@@ -67,13 +70,22 @@ var (
 
 	// ErrKeyExists returns when key is already exist.
 	ErrKeyExists = terror.ClassKV.New(codeKeyExists, "key already exist")
+	// ErrWriteConflict is returned when a pessimistic transaction locks a key which has been
+	// committed after the statement's for update timestamp.
+	ErrWriteConflict = terror.ClassKV.New(codeWriteConflict, "write conflict")
+	// ErrLockWaitTimeout is returned when a pessimistic transaction waits for a lock too long.
+	ErrLockWaitTimeout = terror.ClassKV.New(codeLockWaitTimeout, mysql.MySQLErrName[mysql.ErrLockWaitTimeout])
+	// ErrDeadlock is returned when pessimistic transactions wait for the locks of each other.
+	ErrDeadlock = terror.ClassKV.New(codeDeadlock, mysql.MySQLErrName[mysql.ErrLockDeadlock])
 	// ErrNotImplemented returns when a function is not implemented yet.
 	ErrNotImplemented = terror.ClassKV.New(codeNotImplemented, "not implemented")
 )
 
 func init() {
 	kvMySQLErrCodes := map[terror.ErrCode]uint16{
-		codeKeyExists: mysql.ErrDupEntry,
+		codeKeyExists:       mysql.ErrDupEntry,
+		codeLockWaitTimeout: mysql.ErrLockWaitTimeout,
+		codeDeadlock:        mysql.ErrLockDeadlock,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassKV] = kvMySQLErrCodes
 }
@@ -15,6 +15,7 @@ package kv
 
 import (
 	"github.com/pingcap/tidb/store/tikv/oracle"
+	"github.com/pingcap/tidb/util/execdetails"
 	goctx "golang.org/x/net/context"
 )
 
@@ -38,6 +39,15 @@ const (
 	IsolationLevel
 	// Priority marks the priority of this transaction.
 	Priority
+	// Pessimistic makes LockKeys lock the keys in the store immediately, instead of checking them
+	// for conflicts when the transaction commits. The keys written since the last LockKeys call
+	// are locked together.
+	Pessimistic
+	// ForUpdateTS is the timestamp of the statement that locks keys in a pessimistic transaction.
+	// A key committed after this timestamp can not be locked.
+	ForUpdateTS
+	// LockWaitTimeout is the time in milliseconds a pessimistic transaction waits for a locked key.
+	LockWaitTimeout
 )
 
 // Priority value for transaction priority.
@@ -173,6 +183,8 @@ type Request struct {
 	IsolationLevel IsoLevel
 	// Priority is the priority of this KV request, its value may be PriorityNormal/PriorityLow/PriorityHigh.
 	Priority int
+	// CopStats collects the runtime statistics of the coprocessor tasks, it's nil if they are not needed.
+	CopStats *execdetails.CopRuntimeStats
 }
 
 // Response represents the response returned from KV layer.
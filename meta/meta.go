@@ -429,6 +429,7 @@ func (m *Meta) GetTable(dbID int64, tableID int64) (*model.TableInfo, error) {
 //	DDLJobList: list jobs
 //	DDLJobHistory: hash
 //	DDLJobReorg: hash
+//	DDLJobReorgPhysical: hash
 //
 // for multi DDL workers, only one can become the owner
 // to operate DDL jobs, and dispatch them to MR Jobs.
@@ -437,6 +438,8 @@ var (
 	mDDLJobListKey    = []byte("DDLJobList")
 	mDDLJobHistoryKey = []byte("DDLJobHistory")
 	mDDLJobReorgKey   = []byte("DDLJobReorg")
+	// mDDLJobReorgPhysicalKey saves the partition being reorganized by a job on a partitioned table.
+	mDDLJobReorgPhysicalKey = []byte("DDLJobReorgPhysical")
 )
 
 func (m *Meta) enQueueDDLJob(key []byte, job *model.Job, updateRawArgs bool) error {
@@ -598,9 +601,25 @@ func (m *Meta) UpdateDDLReorgHandle(job *model.Job, handle int64) error {
 // RemoveDDLReorgHandle removes the job reorganization handle.
 func (m *Meta) RemoveDDLReorgHandle(job *model.Job) error {
 	err := m.txn.HDel(mDDLJobReorgKey, m.jobIDKey(job.ID))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = m.txn.HDel(mDDLJobReorgPhysicalKey, m.jobIDKey(job.ID))
 	return errors.Trace(err)
 }
 
+// UpdateDDLReorgPhysicalID saves the ID of the partition being reorganized by the job.
+func (m *Meta) UpdateDDLReorgPhysicalID(job *model.Job, physicalID int64) error {
+	err := m.txn.HSet(mDDLJobReorgPhysicalKey, m.jobIDKey(job.ID), []byte(strconv.FormatInt(physicalID, 10)))
+	return errors.Trace(err)
+}
+
+// GetDDLReorgPhysicalID gets the ID of the partition being reorganized by the job, it returns 0 if it isn't saved.
+func (m *Meta) GetDDLReorgPhysicalID(job *model.Job) (int64, error) {
+	value, err := m.txn.HGetInt64(mDDLJobReorgPhysicalKey, m.jobIDKey(job.ID))
+	return value, errors.Trace(err)
+}
+
 // GetDDLReorgHandle gets the latest processed handle.
 func (m *Meta) GetDDLReorgHandle(job *model.Job) (int64, error) {
 	value, err := m.txn.HGetInt64(mDDLJobReorgKey, m.jobIDKey(job.ID))
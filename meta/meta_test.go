@@ -290,8 +290,20 @@ func (s *testSuite) TestDDL(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(h, Equals, int64(1))
 
+	pid, err := t.GetDDLReorgPhysicalID(job)
+	c.Assert(err, IsNil)
+	c.Assert(pid, Equals, int64(0))
+	err = t.UpdateDDLReorgPhysicalID(job, 3)
+	c.Assert(err, IsNil)
+	pid, err = t.GetDDLReorgPhysicalID(job)
+	c.Assert(err, IsNil)
+	c.Assert(pid, Equals, int64(3))
+
 	err = t.RemoveDDLReorgHandle(job)
 	c.Assert(err, IsNil)
+	pid, err = t.GetDDLReorgPhysicalID(job)
+	c.Assert(err, IsNil)
+	c.Assert(pid, Equals, int64(0))
 
 	v, err = t.DeQueueDDLJob()
 	c.Assert(err, IsNil)
@@ -42,6 +42,11 @@ const (
 	ActionModifyColumn
 	ActionRenameTable
 	ActionSetDefaultValue
+	ActionAddTablePartition
+	ActionDropTablePartition
+	ActionTruncateTablePartition
+	ActionCreateView
+	ActionDropView
 )
 
 func (action ActionType) String() string {
@@ -74,6 +79,16 @@ func (action ActionType) String() string {
 		return "rename table"
 	case ActionSetDefaultValue:
 		return "set default value"
+	case ActionAddTablePartition:
+		return "add partition"
+	case ActionDropTablePartition:
+		return "drop partition"
+	case ActionTruncateTablePartition:
+		return "truncate partition"
+	case ActionCreateView:
+		return "create view"
+	case ActionDropView:
+		return "drop view"
 	default:
 		return "none"
 	}
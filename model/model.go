@@ -17,6 +17,7 @@ import (
 	"strings"
 
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -70,6 +71,22 @@ type ColumnInfo struct {
 	types.FieldType     `json:"type"`
 	State               SchemaState `json:"state"`
 	Comment             string      `json:"comment"`
+	// ChangeStateInfo is only set for the changing column and the origin column of a modify column job.
+	ChangeStateInfo *ChangeStateInfo `json:"change_state_info"`
+	// Hidden is set for the virtual generated columns added for the expression key parts of the indices,
+	// they aren't visible to the users.
+	Hidden bool `json:"hidden"`
+}
+
+// ChangeStateInfo is used by the changing column, which is added by a modify column job when the data must be
+// converted. Its values are converted from the values of the origin column when the rows are written.
+// After the changing column takes the place of the origin column, the origin column uses it in reverse, so the
+// servers which haven't loaded the new schema still read the values written by the new column.
+type ChangeStateInfo struct {
+	// DependencyColumnOffset is the offset of the column which the values are converted from.
+	DependencyColumnOffset int `json:"relative_col_offset"`
+	// Origin is true if it's the origin column, whose values are converted back from the new column.
+	Origin bool `json:"origin"`
 }
 
 // Clone clones ColumnInfo.
@@ -108,6 +125,12 @@ type TableInfo struct {
 	// We need to save original schemaID to keep autoID unchanged
 	// while renaming a table from one database to another.
 	OldSchemaID int64 `json:"old_schema_id,omitempty"`
+
+	// Partition is nil if the table is not partitioned.
+	Partition *PartitionInfo `json:"partition"`
+
+	// View is nil if the table is not a view.
+	View *ViewInfo `json:"view"`
 }
 
 // Clone clones TableInfo.
@@ -129,6 +152,14 @@ func (t *TableInfo) Clone() *TableInfo {
 		nt.ForeignKeys[i] = t.ForeignKeys[i].Clone()
 	}
 
+	if t.Partition != nil {
+		nt.Partition = t.Partition.Clone()
+	}
+
+	if t.View != nil {
+		nt.View = t.View.Clone()
+	}
+
 	return &nt
 }
 
@@ -167,6 +198,199 @@ func (t *TableInfo) ColumnIsInIndex(c *ColumnInfo) bool {
 	return false
 }
 
+// PartitionType is the type for PartitionInfo.
+type PartitionType int
+
+// Partition types.
+const (
+	PartitionTypeRange PartitionType = iota + 1
+	PartitionTypeHash
+	PartitionTypeList
+)
+
+// String implements fmt.Stringer interface.
+func (p PartitionType) String() string {
+	switch p {
+	case PartitionTypeRange:
+		return "RANGE"
+	case PartitionTypeHash:
+		return "HASH"
+	case PartitionTypeList:
+		return "LIST"
+	default:
+		return ""
+	}
+}
+
+// PartitionInfo provides table partition info.
+type PartitionInfo struct {
+	Type PartitionType `json:"type"`
+	// Expr is the text of the partition expression.
+	Expr string `json:"expr"`
+	// Num is the number of the partitions, only used by the HASH partition.
+	Num         uint64                `json:"num"`
+	Definitions []PartitionDefinition `json:"definitions"`
+}
+
+// Clone clones PartitionInfo.
+func (pi *PartitionInfo) Clone() *PartitionInfo {
+	newPi := *pi
+	newPi.Definitions = make([]PartitionDefinition, len(pi.Definitions))
+	for i := range pi.Definitions {
+		newPi.Definitions[i] = pi.Definitions[i].Clone()
+	}
+	return &newPi
+}
+
+// GetNameByID gets the partition name by ID.
+func (pi *PartitionInfo) GetNameByID(id int64) string {
+	for _, def := range pi.Definitions {
+		if id == def.ID {
+			return def.Name.L
+		}
+	}
+	return ""
+}
+
+// FindPartitionDefinitionByName finds the offset of the partition definition by its name.
+// It returns -1 if the partition doesn't exist.
+func (pi *PartitionInfo) FindPartitionDefinitionByName(partitionName string) int {
+	lowConstrName := strings.ToLower(partitionName)
+	for i, def := range pi.Definitions {
+		if def.Name.L == lowConstrName {
+			return i
+		}
+	}
+	return -1
+}
+
+// The texts of the special values of the partition definitions.
+const (
+	// PartitionMaxValue is the text of MAXVALUE in VALUES LESS THAN MAXVALUE.
+	PartitionMaxValue = "MAXVALUE"
+	// PartitionNullValue is the text of NULL in VALUES IN.
+	PartitionNullValue = "NULL"
+)
+
+// PartitionDefinition defines a single partition.
+// The ID of a partition is its physical table ID, the records of the partition are stored
+// with the record prefix of the ID.
+type PartitionDefinition struct {
+	ID   int64 `json:"id"`
+	Name CIStr `json:"name"`
+	// LessThan is the texts of the VALUES LESS THAN values.
+	LessThan []string `json:"less_than"`
+	// InValues is the texts of the VALUES IN values.
+	InValues []string `json:"in_values"`
+	Comment  string   `json:"comment,omitempty"`
+}
+
+// Clone clones PartitionDefinition.
+func (pd PartitionDefinition) Clone() PartitionDefinition {
+	newPd := pd
+	newPd.LessThan = make([]string, len(pd.LessThan))
+	copy(newPd.LessThan, pd.LessThan)
+	newPd.InValues = make([]string, len(pd.InValues))
+	copy(newPd.InValues, pd.InValues)
+	return newPd
+}
+
+// IsView checks if the table is a view.
+func (t *TableInfo) IsView() bool {
+	return t.View != nil
+}
+
+// ViewAlgorithm is the algorithm of the view.
+type ViewAlgorithm int
+
+// View algorithms.
+const (
+	AlgorithmUndefined ViewAlgorithm = iota
+	AlgorithmMerge
+	AlgorithmTemptable
+)
+
+// String implements fmt.Stringer interface.
+func (v ViewAlgorithm) String() string {
+	switch v {
+	case AlgorithmMerge:
+		return "MERGE"
+	case AlgorithmTemptable:
+		return "TEMPTABLE"
+	default:
+		return "UNDEFINED"
+	}
+}
+
+// ViewSecurity is the SQL SECURITY of the view.
+type ViewSecurity int
+
+// View securities.
+const (
+	SecurityDefiner ViewSecurity = iota
+	SecurityInvoker
+)
+
+// String implements fmt.Stringer interface.
+func (v ViewSecurity) String() string {
+	switch v {
+	case SecurityInvoker:
+		return "INVOKER"
+	default:
+		return "DEFINER"
+	}
+}
+
+// ViewCheckOption is the WITH CHECK OPTION of the view.
+type ViewCheckOption int
+
+// View check options.
+const (
+	CheckOptionNone ViewCheckOption = iota
+	CheckOptionLocal
+	CheckOptionCascaded
+)
+
+// String implements fmt.Stringer interface.
+func (v ViewCheckOption) String() string {
+	switch v {
+	case CheckOptionLocal:
+		return "LOCAL"
+	case CheckOptionCascaded:
+		return "CASCADED"
+	default:
+		return "NONE"
+	}
+}
+
+// ViewInfo provides view info.
+// The columns of the view are stored in TableInfo.Columns, and their names are the view column names.
+type ViewInfo struct {
+	Algorithm ViewAlgorithm      `json:"view_algorithm"`
+	Definer   *auth.UserIdentity `json:"view_definer"`
+	Security  ViewSecurity       `json:"view_security"`
+	// SelectStmt is the text of the select statement of the view.
+	SelectStmt  string          `json:"view_select"`
+	CheckOption ViewCheckOption `json:"view_checkoption"`
+	// Cols is the column list specified in the CREATE VIEW statement.
+	Cols []CIStr `json:"view_cols"`
+	// Charset and Collate are the connection charset and collation when the view is created.
+	Charset string `json:"view_charset"`
+	Collate string `json:"view_collate"`
+}
+
+// Clone clones ViewInfo.
+func (v *ViewInfo) Clone() *ViewInfo {
+	nv := *v
+	if v.Definer != nil {
+		definer := *v.Definer
+		nv.Definer = &definer
+	}
+	nv.Cols = make([]CIStr, len(v.Cols))
+	copy(nv.Cols, v.Cols)
+	return &nv
+}
+
 // IndexColumn provides index column info.
 type IndexColumn struct {
 	Name   CIStr `json:"name"`   // Index name
@@ -882,6 +882,9 @@ const (
 	ErrMustChangePasswordLogin                                      = 1862
 	ErrRowInWrongPartition                                          = 1863
 	ErrErrorLast                                                    = 1863
+	ErrGISDifferentSRIDs                                            = 3033
+	ErrGISInvalidData                                               = 3037
+	ErrUserLockWrongName                                            = 3057
 	ErrBadGeneratedColumn                                           = 3105
 	ErrUnsupportedOnGeneratedColumn                                 = 3106
 	ErrGeneratedColumnNonPrior                                      = 3107
@@ -889,5 +892,21 @@ const (
 	ErrInvalidJSONText                                              = 3140
 	ErrInvalidJSONPath                                              = 3143
 	ErrInvalidJSONData                                              = 3146
+	ErrInvalidJSONPathWildcard                                      = 3149
 	ErrJSONUsedAsKey                                                = 3152
+	ErrInvalidJSONContainsPathType                                  = 3154
+	ErrCTERecursiveRequiresUnion                                    = 3573
+	ErrCTERecursiveRequiresNonRecursiveFirst                        = 3574
+	ErrCTERecursiveForbidsAggregation                               = 3575
+	ErrWindowFrameStartIllegal                                      = 3584
+	ErrWindowFrameEndIllegal                                        = 3585
+	ErrWindowFrameIllegal                                           = 3586
+	ErrWindowRangeFrameOrderType                                    = 3587
+	ErrWindowInvalidWindowFuncUse                                   = 3593
+	ErrCTEMaxRecursionDepth                                         = 3636
+	ErrFunctionalIndexOnJSONOrGeometryFunction                      = 3753
+	ErrFunctionalIndexOnField                                       = 3754
+	ErrFunctionalIndexPrimaryKey                                    = 3756
+	ErrFunctionalIndexOnLob                                         = 3757
+	ErrDependentByFunctionalIndex                                   = 3837
 )
@@ -246,6 +246,9 @@ var MySQLState = map[uint16]string{
 	ErrAlterOperationNotSupported:          "0A000",
 	ErrAlterOperationNotSupportedReason:    "0A000",
 	ErrDupUnknownInIndex:                   "23000",
+	ErrGISDifferentSRIDs:                   "HY000",
+	ErrGISInvalidData:                      "22023",
+	ErrUserLockWrongName:                   "42000",
 	ErrBadGeneratedColumn:                  "HY000",
 	ErrUnsupportedOnGeneratedColumn:        "HY000",
 	ErrGeneratedColumnNonPrior:             "HY000",
@@ -253,5 +256,7 @@ var MySQLState = map[uint16]string{
 	ErrInvalidJSONText:                     "22032",
 	ErrInvalidJSONPath:                     "42000",
 	ErrInvalidJSONData:                     "22032",
+	ErrInvalidJSONPathWildcard:             "42000",
 	ErrJSONUsedAsKey:                       "42000",
+	ErrInvalidJSONContainsPathType:         "42000",
 }
@@ -323,6 +323,7 @@ var tokenMap = map[string]int{
 	"LESS":                       less,
 	"LEVEL":                      level,
 	"LIKE":                       like,
+	"LIST":                       list,
 	"LIMIT":                      limit,
 	"LINES":                      lines,
 	"LN":                         ln,
@@ -394,6 +395,7 @@ var tokenMap = map[string]int{
 	"RANGE":                      rangeKwd,
 	"RAND":                       rand,
 	"READ":                       read,
+	"RECURSIVE":                  recursive,
 	"REDUNDANT":                  redundant,
 	"REFERENCES":                 references,
 	"REGEXP":                     regexpKwd,
@@ -401,6 +403,7 @@ var tokenMap = map[string]int{
 	"RENAME":                     rename,
 	"REPEAT":                     repeat,
 	"REPEATABLE":                 repeatable,
+	"REQUIRE":                    require,
 	"REPLACE":                    replace,
 	"REVOKE":                     revoke,
 	"RIGHT":                      right,
@@ -495,6 +498,7 @@ var tokenMap = map[string]int{
 	"VIRTUAL":                    virtual,
 	"WARNINGS":                   warnings,
 	"WEEK":                       week,
+	"X509":                       x509,
 	"WEEKDAY":                    weekday,
 	"WEEKOFYEAR":                 weekofyear,
 	"WHEN":                       when,
@@ -514,6 +518,8 @@ var tokenMap = map[string]int{
 	"TINY":                       tinyIntType,
 	"TINYINT":                    tinyIntType,
 	"SMALLINT":                   smallIntType,
+	"SPATIAL":                    spatial,
+	"SSL":                        ssl,
 	"MEDIUMINT":                  mediumIntType,
 	"INT":                        intType,
 	"INTEGER":                    integerType,
@@ -545,7 +551,11 @@ var tokenMap = map[string]int{
 	"BOOL":                       boolType,
 	"BOOLEAN":                    booleanType,
 	"JOBS":                       jobs,
+	"GEOMETRY":                   geometry,
 	"JSON":                       jsonType,
+	"LINESTRING":                 lineString,
+	"POINT":                      point,
+	"POLYGON":                    polygon,
 	"JSON_EXTRACT":               jsonExtract,
 	"JSON_UNQUOTE":               jsonUnquote,
 	"JSON_TYPE":                  jsonTypeFunc,
@@ -556,6 +566,14 @@ var tokenMap = map[string]int{
 	"JSON_MERGE":                 jsonMerge,
 	"JSON_OBJECT":                jsonObject,
 	"JSON_ARRAY":                 jsonArray,
+	"JSON_ARRAY_APPEND":          jsonArrayAppend,
+	"JSON_CONTAINS":              jsonContains,
+	"JSON_CONTAINS_PATH":         jsonContainsPath,
+	"JSON_DEPTH":                 jsonDepth,
+	"JSON_KEYS":                  jsonKeys,
+	"JSON_LENGTH":                jsonLength,
+	"JSON_SEARCH":                jsonSearch,
+	"JSON_VALID":                 jsonValid,
 	"SECOND_MICROSECOND":         secondMicrosecond,
 	"MINUTE_MICROSECOND":         minuteMicrosecond,
 	"MINUTE_SECOND":              minuteSecond,
@@ -573,6 +591,42 @@ var tokenMap = map[string]int{
 	"ACTION":                     action,
 	"PARTITION":                  partition,
 	"PARTITIONS":                 partitions,
+	"OVER":                       over,
+	"ROW_NUMBER":                 rowNumber,
+	"RANK":                       rank,
+	"DENSE_RANK":                 denseRank,
+	"LAG":                        lag,
+	"LEAD":                       lead,
+	"FIRST_VALUE":                firstValue,
+	"LAST_VALUE":                 lastValue,
+	"MBRCONTAINS":                mbrContains,
+	"MBRINTERSECTS":              mbrIntersects,
+	"MBRWITHIN":                  mbrWithin,
+	"ST_ASBINARY":                stAsBinary,
+	"ST_ASTEXT":                  stAsText,
+	"ST_CONTAINS":                stContains,
+	"ST_DISTANCE":                stDistance,
+	"ST_ENVELOPE":                stEnvelope,
+	"ST_GEOMFROMTEXT":            stGeomFromText,
+	"ST_GEOMFROMWKB":             stGeomFromWKB,
+	"ST_GEOMETRYTYPE":            stGeometryType,
+	"ST_WITHIN":                  stWithin,
+	"ST_X":                       stX,
+	"ST_Y":                       stY,
+	"CURRENT":                    current,
+	"FOLLOWING":                  following,
+	"PRECEDING":                  preceding,
+	"ROWS":                       rows,
+	"UNBOUNDED":                  unbounded,
+	"ALGORITHM":                  algorithm,
+	"CASCADED":                   cascaded,
+	"DEFINER":                    definer,
+	"INVOKER":                    invoker,
+	"MERGE":                      merge,
+	"SECURITY":                   security,
+	"SQL":                        sql,
+	"TEMPTABLE":                  temptable,
+	"UNDEFINED":                  undefined,
 	"RPAD":                       rpad,
 	"BIT_COUNT":                  bitCount,
 	"BIT_LENGTH":                 bitLength,
@@ -35,6 +35,7 @@ import (
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tidb/util/types/geo"
 )
 
 %}
@@ -175,6 +176,7 @@ import (
 	ord			"ORD"
 	order			"ORDER"
 	outer			"OUTER"
+	over			"OVER"
 	partition		"PARTITION"
 	partitions		"PARTITIONS"
 	position		"POSITION"
@@ -184,12 +186,14 @@ import (
 	quote			"QUOTE"
 	rangeKwd		"RANGE"
 	read			"READ"
+	recursive		"RECURSIVE"
 	realType		"REAL"
 	references		"REFERENCES"
 	regexpKwd		"REGEXP"
 	rename         		"RENAME"
 	repeat			"REPEAT"
 	replace			"REPLACE"
+	require			"REQUIRE"
 	restrict		"RESTRICT"
 	revoke			"REVOKE"
 	right			"RIGHT"
@@ -201,6 +205,8 @@ import (
 	set			"SET"
 	show			"SHOW"
 	smallIntType		"SMALLINT"
+	spatial			"SPATIAL"
+	ssl			"SSL"
 	starting		"STARTING"
 	tableKwd		"TABLE"
 	stored			"STORED"
@@ -304,6 +310,14 @@ import (
 	jsonMerge			"JSON_MERGE"
 	jsonObject			"JSON_OBJECT"
 	jsonArray			"JSON_ARRAY"
+	jsonArrayAppend			"JSON_ARRAY_APPEND"
+	jsonContains			"JSON_CONTAINS"
+	jsonContainsPath		"JSON_CONTAINS_PATH"
+	jsonDepth			"JSON_DEPTH"
+	jsonKeys			"JSON_KEYS"
+	jsonLength			"JSON_LENGTH"
+	jsonSearch			"JSON_SEARCH"
+	jsonValid			"JSON_VALID"
 	kill				"KILL"
 	lastInsertID			"LAST_INSERT_ID"
 	lcase				"LCASE"
@@ -417,10 +431,45 @@ import (
 	releaseAllLocks			"RELEASE_ALL_LOCKS"
 	uuid				"UUID"
 	uuidShort			"UUID_SHORT"
+	rowNumber			"ROW_NUMBER"
+	rank				"RANK"
+	denseRank			"DENSE_RANK"
+	lag				"LAG"
+	lead				"LEAD"
+	firstValue			"FIRST_VALUE"
+	lastValue			"LAST_VALUE"
+	mbrContains		"MBRCONTAINS"
+	mbrIntersects		"MBRINTERSECTS"
+	mbrWithin		"MBRWITHIN"
+	stAsBinary		"ST_ASBINARY"
+	stAsText		"ST_ASTEXT"
+	stContains		"ST_CONTAINS"
+	stDistance		"ST_DISTANCE"
+	stEnvelope		"ST_ENVELOPE"
+	stGeomFromText		"ST_GEOMFROMTEXT"
+	stGeomFromWKB		"ST_GEOMFROMWKB"
+	stGeometryType		"ST_GEOMETRYTYPE"
+	stWithin		"ST_WITHIN"
+	stX			"ST_X"
+	stY			"ST_Y"
 	underscoreCS			"UNDERSCORE_CHARSET"
 
 	/* the following tokens belong to UnReservedKeyword*/
 	action		"ACTION"
+	current		"CURRENT"
+	following	"FOLLOWING"
+	preceding	"PRECEDING"
+	rows		"ROWS"
+	unbounded	"UNBOUNDED"
+	algorithm	"ALGORITHM"
+	cascaded	"CASCADED"
+	definer		"DEFINER"
+	invoker		"INVOKER"
+	merge		"MERGE"
+	security	"SECURITY"
+	sql		"SQL"
+	temptable	"TEMPTABLE"
+	undefined	"UNDEFINED"
 	after		"AFTER"
 	always		"ALWAYS"
 	any 		"ANY"
@@ -467,6 +516,7 @@ import (
 	fields		"FIELDS"
 	first		"FIRST"
 	fixed		"FIXED"
+	geometry	"GEOMETRY"
 	flush		"FLUSH"
 	full		"FULL"
 	function	"FUNCTION"
@@ -475,9 +525,11 @@ import (
 	isolation	"ISOLATION"
 	indexes		"INDEXES"
 	jsonType	"JSON"
+	lineString	"LINESTRING"
 	keyBlockSize	"KEY_BLOCK_SIZE"
 	local		"LOCAL"
 	less		"LESS"
+	list		"LIST"
 	level		"LEVEL"
 	mode		"MODE"
 	modify		"MODIFY"
@@ -491,6 +543,8 @@ import (
 	only		"ONLY"
 	password	"PASSWORD"
 	plugins		"PLUGINS"
+	point		"POINT"
+	polygon		"POLYGON"
 	prepare		"PREPARE"
 	privileges	"PRIVILEGES"
 	processlist	"PROCESSLIST"
@@ -539,6 +593,7 @@ import (
 	view		"VIEW"
 	warnings	"WARNINGS"
 	week		"WEEK"
+	x509		"X509"
 	yearType	"YEAR"
 
 %token	<item>
@@ -610,6 +665,7 @@ import (
 	DatabaseOptionListOpt		"CREATE Database specification list opt"
 	CreateTableStmt			"CREATE TABLE statement"
 	CreateUserStmt			"CREATE User statement"
+	CreateViewStmt			"CREATE VIEW statement"
 	DBName				"Database Name"
 	DeallocateStmt			"Deallocate prepared statement"
 	DefaultValueExpr		"DefaultValueExpr(Now or Signed Literal)"
@@ -655,6 +711,7 @@ import (
 	FunctionCallConflict		"Function call with reserved keyword as function name"
 	FunctionCallKeyword		"Function call with keyword as function name"
 	FunctionCallNonKeyword		"Function call with nonkeyword as function name"
+	FunctionCallWindow		"Window function call"
 	FuncDatetimePrec		"Function datetime precision"
 	GlobalScope			"The scope of variable"
 	GrantStmt			"Grant statement"
@@ -712,6 +769,14 @@ import (
 	PartitionDefinitionListOpt	"Partition definition list option"
 	PartitionOpt			"Partition option"
 	PartitionNumOpt			"PARTITION NUM option"
+	OrReplace			"or replace"
+	ViewAlgorithm			"view algorithm"
+	ViewCheckOption			"view check option"
+	ViewColumnList			"view column list"
+	ViewDefiner			"view definer"
+	ViewFieldList			"view field list"
+	ViewSelectStmt			"view select statement"
+	ViewSQLSecurity			"view sql security"
 	PartDefValuesOpt		"VALUES {LESS THAN {(expr | value_list) | MAXVALUE} | IN {value_list}"
 	PartDefStorageOpt		"ENGINE = xxx or empty"
 	PasswordOpt			"Password option"
@@ -794,6 +859,7 @@ import (
 	UsernameList		"UsernameList"
 	UserSpec		"Username and auth option"
 	UserSpecList		"Username and auth option list"
+	RequireClauseOpt	"Optional REQUIRE clause of account management statements"
 	UserVariable		"User defined variable name"
 	UserVariableList	"User defined variable name list"
 	UseStmt			"USE statement"
@@ -825,6 +891,7 @@ import (
 	BlobType		"Blob types"
 	TextType		"Text types"
 	DateAndTimeType		"Date and Time types"
+	SpatialType		"Spatial types"
 
 	OptFieldLen		"Field length or empty"
 	FieldLen		"Field length"
@@ -841,6 +908,19 @@ import (
 	TableOptimizerHintOpt	"Table level optimizer hint"
 	TableOptimizerHints	"Table level optimizer hints"
 	TableOptimizerHintList	"Table level optimizer hint list"
+	WindowSpec		"Window specification"
+	WindowPartitionByOpt	"Optional PARTITION BY clause of window specification"
+	WindowOrderByOpt	"Optional ORDER BY clause of window specification"
+	WindowFrameClauseOpt	"Optional frame clause of window specification"
+	WindowFrameUnits	"Window frame units"
+	WindowFrameStart	"Window frame start bound"
+	WindowFrameBound	"Window frame bound"
+	WithClause		"WITH clause"
+	WithList		"Common table expression list of WITH clause"
+	CommonTableExpr		"Common table expression"
+	CTEColumnListOpt	"Optional column list of common table expression"
+	IdentList		"Identifier list"
+	SelectStmtWithClause	"SELECT or UNION statement with WITH clause"
 
 %type	<ident>
 	KeyOrIndex		"{KEY|INDEX}"
@@ -884,6 +964,8 @@ import (
 	FunctionNameConflict		"Built-in function call names which are conflict with keywords"
 	FunctionNameDateArith		"Date arith function call names (date_add or date_sub)"
 	FunctionNameDateArithMultiForms	"Date arith function call names (adddate or subdate)"
+	WindowFuncName			"Window function names which take no arguments"
+	WindowFuncNameWithArgs		"Window function names which take arguments"
 
 %precedence lowestOpt
 %token	tableRefPriority
@@ -999,6 +1081,27 @@ AlterTableSpec:
 			OldColumnName: $3.(*ast.ColumnName),
 		}
 	}
+|	"ADD" "PARTITION" '(' PartitionDefinitionList ')'
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp: ast.AlterTableAddPartitions,
+			PartDefinitions: $4.([]*ast.PartitionDefinition),
+		}
+	}
+|	"DROP" "PARTITION" Identifier
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp: ast.AlterTableDropPartition,
+			Name: $3,
+		}
+	}
+|	"TRUNCATE" "PARTITION" Identifier
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp: ast.AlterTableTruncatePartition,
+			Name: $3,
+		}
+	}
 |	"DROP" "PRIMARY" "KEY"
 	{
 		$$ = &ast.AlterTableSpec{Tp: ast.AlterTableDropPrimaryKey}
@@ -1442,6 +1545,18 @@ ConstraintElem:
 		}
 		$$ = c
 	}
+|	"SPATIAL" KeyOrIndex IndexName '(' IndexColNameList ')' IndexOptionList
+	{
+		c := &ast.Constraint{
+			Tp:	ast.ConstraintSpatial,
+			Keys:	$5.([]*ast.IndexColName),
+			Name:	$3.(string),
+		}
+		if $7 != nil {
+			c.Option = $7.(*ast.IndexOption)
+		}
+		$$ = c
+	}
 |	KeyOrIndex IndexName IndexTypeOpt '(' IndexColNameList ')' IndexOptionList
 	{
 		c := &ast.Constraint{
@@ -1626,6 +1741,20 @@ CreateIndexStmt:
 			IndexOption:   indexOption,
 		}
 	}
+|	"CREATE" "SPATIAL" "INDEX" Identifier "ON" TableName '(' IndexColNameList ')' IndexOptionList
+	{
+		indexOption := &ast.IndexOption{}
+		if $10 != nil {
+			indexOption = $10.(*ast.IndexOption)
+		}
+		$$ = &ast.CreateIndexStmt{
+			Spatial:       true,
+			IndexName:     $4,
+			Table:         $6.(*ast.TableName),
+			IndexColNames: $8.([]*ast.IndexColName),
+			IndexOption:   indexOption,
+		}
+	}
 
 CreateIndexStmtUnique:
 	{
@@ -1642,6 +1771,14 @@ IndexColName:
 		//Order is parsed but just ignored as MySQL did
 		$$ = &ast.IndexColName{Column: $1.(*ast.ColumnName), Length: $2.(int)}
 	}
+|	'(' Expression ')' Order
+	{
+		startOffset := parser.startOffset(&yyS[yypt-2])
+		endOffset := parser.endOffset(&yyS[yypt-1])
+		expr := $2.(ast.ExprNode)
+		expr.SetText(parser.src[startOffset:endOffset])
+		$$ = &ast.IndexColName{Expr: expr}
+	}
 
 IndexColNameList:
 	IndexColName
@@ -1740,13 +1877,17 @@ CreateTableStmt:
 			yylex.Errorf("Column Definition List can't be empty.")
 			return 1
 		}
-		$$ = &ast.CreateTableStmt{
+		stmt := &ast.CreateTableStmt{
 			Table:          $4.(*ast.TableName),
 			IfNotExists:    $3.(bool),
 			Cols:           columnDefs,
 			Constraints:    constraints,
 			Options:        $8.([]*ast.TableOption),
 		}
+		if $9 != nil {
+			stmt.Partition = $9.(*ast.PartitionOptions)
+		}
+		$$ = stmt
 	}
 |	"CREATE" "TABLE" IfNotExists TableName "LIKE" TableName
 	{
@@ -1757,43 +1898,235 @@ CreateTableStmt:
 		}
 	}
 
+/*******************************************************************
+ *
+ *  Create View Statement
+ *
+ *  Example:
+ *      CREATE OR REPLACE ALGORITHM = MERGE DEFINER = 'root'@'localhost' SQL SECURITY INVOKER
+ *          VIEW v (c1, c2) AS SELECT a, b FROM t WITH LOCAL CHECK OPTION
+ *******************************************************************/
+CreateViewStmt:
+	"CREATE" OrReplace ViewAlgorithm ViewDefiner ViewSQLSecurity "VIEW" TableName ViewFieldList "AS" ViewSelectStmt ViewCheckOption
+	{
+		startOffset := parser.startOffset(&yyS[yypt-1])
+		var endOffset int
+		if $11.(model.ViewCheckOption) != model.CheckOptionNone {
+			endOffset = parser.endOffset(&yyS[yypt])
+		} else {
+			// The select statement is the end of the statement, it ends before the lookahead token.
+			endOffset = parser.endOffset(&parser.yylval)
+		}
+		sel := $10.(ast.StmtNode)
+		sel.SetText(parser.src[startOffset:endOffset])
+		stmt := &ast.CreateViewStmt{
+			OrReplace:	$2.(bool),
+			Algorithm:	$3.(model.ViewAlgorithm),
+			Security:	$5.(model.ViewSecurity),
+			ViewName:	$7.(*ast.TableName),
+			Select:		sel,
+			CheckOption:	$11.(model.ViewCheckOption),
+		}
+		if $4 != nil {
+			stmt.Definer = $4.(*auth.UserIdentity)
+		}
+		if $8 != nil {
+			stmt.Cols = $8.([]model.CIStr)
+		}
+		$$ = stmt
+	}
+
+OrReplace:
+	{
+		$$ = false
+	}
+|	"OR" "REPLACE"
+	{
+		$$ = true
+	}
+
+ViewAlgorithm:
+	{
+		$$ = model.AlgorithmUndefined
+	}
+|	"ALGORITHM" eq "UNDEFINED"
+	{
+		$$ = model.AlgorithmUndefined
+	}
+|	"ALGORITHM" eq "MERGE"
+	{
+		$$ = model.AlgorithmMerge
+	}
+|	"ALGORITHM" eq "TEMPTABLE"
+	{
+		$$ = model.AlgorithmTemptable
+	}
+
+ViewDefiner:
+	{
+		$$ = nil
+	}
+|	"DEFINER" eq "CURRENT_USER"
+	{
+		$$ = nil
+	}
+|	"DEFINER" eq "CURRENT_USER" '(' ')'
+	{
+		$$ = nil
+	}
+|	"DEFINER" eq Username
+	{
+		$$ = $3
+	}
+
+ViewSQLSecurity:
+	{
+		$$ = model.SecurityDefiner
+	}
+|	"SQL" "SECURITY" "DEFINER"
+	{
+		$$ = model.SecurityDefiner
+	}
+|	"SQL" "SECURITY" "INVOKER"
+	{
+		$$ = model.SecurityInvoker
+	}
+
+ViewFieldList:
+	{
+		$$ = nil
+	}
+|	'(' ViewColumnList ')'
+	{
+		$$ = $2
+	}
+
+ViewColumnList:
+	Identifier
+	{
+		$$ = []model.CIStr{model.NewCIStr($1)}
+	}
+|	ViewColumnList ',' Identifier
+	{
+		$$ = append($1.([]model.CIStr), model.NewCIStr($3))
+	}
+
+ViewSelectStmt:
+	SelectStmt
+|	UnionStmt
+|	SelectStmtWithClause
+
+ViewCheckOption:
+	{
+		$$ = model.CheckOptionNone
+	}
+|	"WITH" "CASCADED" "CHECK" "OPTION"
+	{
+		$$ = model.CheckOptionCascaded
+	}
+|	"WITH" "LOCAL" "CHECK" "OPTION"
+	{
+		$$ = model.CheckOptionLocal
+	}
+|	"WITH" "CHECK" "OPTION"
+	{
+		$$ = model.CheckOptionCascaded
+	}
+
 DefaultKwdOpt:
 	{}
 |	"DEFAULT"
 
 PartitionOpt:
-	{}
+	{
+		$$ = nil
+	}
 |	"PARTITION" "BY" "HASH" '(' Expression ')' PartitionNumOpt PartitionDefinitionListOpt
-	{}
-|	"PARTITION" "BY" "RANGE" '(' Expression ')' PartitionNumOpt  PartitionDefinitionListOpt
-	{}
+	{
+		expr := $5.(ast.ExprNode)
+		expr.SetText(parser.src[parser.startOffset(&yyS[yypt-3]):parser.endOffset(&yyS[yypt-2])])
+		$$ = &ast.PartitionOptions{
+			Tp:		model.PartitionTypeHash,
+			Expr:		expr,
+			Num:		$7.(uint64),
+			Definitions:	$8.([]*ast.PartitionDefinition),
+		}
+	}
+|	"PARTITION" "BY" "RANGE" '(' Expression ')' PartitionNumOpt PartitionDefinitionListOpt
+	{
+		expr := $5.(ast.ExprNode)
+		expr.SetText(parser.src[parser.startOffset(&yyS[yypt-3]):parser.endOffset(&yyS[yypt-2])])
+		$$ = &ast.PartitionOptions{
+			Tp:		model.PartitionTypeRange,
+			Expr:		expr,
+			Num:		$7.(uint64),
+			Definitions:	$8.([]*ast.PartitionDefinition),
+		}
+	}
+|	"PARTITION" "BY" "LIST" '(' Expression ')' PartitionNumOpt PartitionDefinitionListOpt
+	{
+		expr := $5.(ast.ExprNode)
+		expr.SetText(parser.src[parser.startOffset(&yyS[yypt-3]):parser.endOffset(&yyS[yypt-2])])
+		$$ = &ast.PartitionOptions{
+			Tp:		model.PartitionTypeList,
+			Expr:		expr,
+			Num:		$7.(uint64),
+			Definitions:	$8.([]*ast.PartitionDefinition),
+		}
+	}
 
 PartitionNumOpt:
-	{}
+	{
+		$$ = uint64(0)
+	}
 |	"PARTITIONS" NUM
-	{}
+	{
+		$$ = getUint64FromNUM($2)
+	}
 
 PartitionDefinitionListOpt:
-	{}
+	{
+		$$ = []*ast.PartitionDefinition{}
+	}
 |	'(' PartitionDefinitionList ')'
-	{}
+	{
+		$$ = $2.([]*ast.PartitionDefinition)
+	}
 
 PartitionDefinitionList:
 	PartitionDefinition
-	{}
+	{
+		$$ = []*ast.PartitionDefinition{$1.(*ast.PartitionDefinition)}
+	}
 |	PartitionDefinitionList ',' PartitionDefinition
-	{}
+	{
+		$$ = append($1.([]*ast.PartitionDefinition), $3.(*ast.PartitionDefinition))
+	}
 
 PartitionDefinition:
 	"PARTITION" Identifier PartDefValuesOpt PartDefStorageOpt
-	{}
+	{
+		partDef := $3.(*ast.PartitionDefinition)
+		partDef.Name = model.NewCIStr($2)
+		$$ = partDef
+	}
 
 PartDefValuesOpt:
-	{}
+	{
+		$$ = &ast.PartitionDefinition{}
+	}
 |	"VALUES" "LESS" "THAN" "MAXVALUE"
-	{}
+	{
+		$$ = &ast.PartitionDefinition{MaxValue: true}
+	}
 |	"VALUES" "LESS" "THAN" '(' ExpressionList ')'
-	{}
+	{
+		$$ = &ast.PartitionDefinition{LessThan: $5.([]ast.ExprNode)}
+	}
+|	"VALUES" "IN" '(' ExpressionList ')'
+	{
+		$$ = &ast.PartitionDefinition{InValues: $4.([]ast.ExprNode)}
+	}
 
 PartDefStorageOpt:
 	{}
@@ -1900,9 +2233,13 @@ DropTableStmt:
 	}
 
 DropViewStmt:
-	"DROP" "VIEW" "IF" "EXISTS" TableNameList
+	"DROP" "VIEW" TableNameList
+	{
+		$$ = &ast.DropTableStmt{Tables: $3.([]*ast.TableName), IsView: true}
+	}
+|	"DROP" "VIEW" "IF" "EXISTS" TableNameList
 	{
-		$$ = &ast.DoStmt{}
+		$$ = &ast.DropTableStmt{IfExists: true, Tables: $5.([]*ast.TableName), IsView: true}
 	}
 
 DropUserStmt:
@@ -1960,7 +2297,25 @@ ExplainStmt:
 	}
 |	ExplainSym ExplainableStmt
 	{
-		$$ = &ast.ExplainStmt{Stmt: $2.(ast.StmtNode)}
+		$$ = &ast.ExplainStmt{
+			Stmt:	$2.(ast.StmtNode),
+			Format:	ast.ExplainFormatROW,
+		}
+	}
+|	ExplainSym "ANALYZE" ExplainableStmt
+	{
+		$$ = &ast.ExplainStmt{
+			Stmt:		$3.(ast.StmtNode),
+			Analyze:	true,
+			Format:		ast.ExplainFormatROW,
+		}
+	}
+|	ExplainSym "FORMAT" "=" StringName ExplainableStmt
+	{
+		$$ = &ast.ExplainStmt{
+			Stmt:	$5.(ast.StmtNode),
+			Format:	strings.ToLower($4.(string)),
+		}
 	}
 
 LengthNum:
@@ -2436,7 +2791,7 @@ UnReservedKeyword:
  "ACTION" | "ASCII" | "AUTO_INCREMENT" | "AFTER" | "ALWAYS" | "AT" | "AVG" | "BEGIN" | "BIT" | "BOOL" | "BOOLEAN" | "BTREE" | "CHARSET"
 | "COLUMNS" | "COMMIT" | "COMPACT" | "COMPRESSED" | "CONSISTENT" | "DATA" | "DATE" %prec lowerThanStringLitToken| "DATETIME" | "DEALLOCATE" | "DO"
 | "DYNAMIC"| "END" | "ENGINE" | "ENGINES" | "ESCAPE" | "EXECUTE" | "FIELDS" | "FIRST" | "FIXED" | "FORMAT" | "FULL" |"GLOBAL"
-| "HASH" | "LESS" | "LOCAL" | "NAMES" | "OFFSET" | "PASSWORD" %prec lowerThanEq | "PREPARE" | "QUICK" | "REDUNDANT"
+| "HASH" | "LESS" | "LIST" | "LOCAL" | "NAMES" | "OFFSET" | "PASSWORD" %prec lowerThanEq | "PREPARE" | "QUICK" | "REDUNDANT"
 | "ROLLBACK" | "SESSION" | "SIGNED" | "SNAPSHOT" | "START" | "STATUS" | "TABLES" | "TEXT" | "THAN" | "TIDB" | "TIME" | "TIMESTAMP"
 | "TRANSACTION" | "TRUNCATE" | "UNKNOWN" | "VALUE" | "WARNINGS" | "YEAR" | "MODE"  | "WEEK"  | "ANY" | "SOME" | "USER" | "IDENTIFIED"
 | "COLLATION" | "COMMENT" | "AVG_ROW_LENGTH" | "CONNECTION" | "CHECKSUM" | "COMPRESSION" | "KEY_BLOCK_SIZE" | "MAX_ROWS"
@@ -2444,6 +2799,8 @@ UnReservedKeyword:
 | "REPEATABLE" | "COMMITTED" | "UNCOMMITTED" | "ONLY" | "SERIALIZABLE" | "LEVEL" | "VARIABLES" | "SQL_CACHE" | "INDEXES" | "PROCESSLIST"
 | "SQL_NO_CACHE" | "DISABLE"  | "ENABLE" | "REVERSE" | "SPACE" | "PRIVILEGES" | "NO" | "BINLOG" | "FUNCTION" | "VIEW" | "MODIFY" | "EVENTS" | "PARTITIONS"
 | "TIMESTAMPDIFF" | "NONE" | "SUPER" | "SHARED" | "EXCLUSIVE" | "STATS" | "STATS_META" | "STATS_HISTOGRAMS" | "STATS_BUCKETS"
+| "CURRENT" | "FOLLOWING" | "PRECEDING" | "ROWS" | "UNBOUNDED" | "ALGORITHM" | "CASCADED" | "DEFINER" | "INVOKER" | "MERGE"
+| "SECURITY" | "SQL" | "TEMPTABLE" | "UNDEFINED" | "X509" | "GEOMETRY" | "POINT" | "LINESTRING" | "POLYGON"
 
 ReservedKeyword:
 "ADD" | "ALL" | "ALTER" | "ANALYZE" | "AND" | "AS" | "ASC" | "BETWEEN" | "BIGINT"
@@ -2458,9 +2815,9 @@ ReservedKeyword:
 | "INTERVAL" | "IS" | "JOIN" | "KEY" | "KEYS" | "KILL" | "LEADING" | "LEFT" | "LIKE" | "LIMIT" | "LINES" | "LOAD"
 | "LOCALTIME" | "LOCALTIMESTAMP" | "LOCK" | "LONGBLOB" | "LONGTEXT" | "MAXVALUE" | "MEDIUMBLOB" | "MEDIUMINT" | "MEDIUMTEXT"
 | "MINUTE_MICROSECOND" | "MINUTE_SECOND" | "MOD" | "NOT" | "NO_WRITE_TO_BINLOG" | "NULL" | "NUMERIC"
-| "ON" | "OPTION" | "OR" | "ORDER" | "OUTER" | "PARTITION" | "PRECISION" | "PRIMARY" | "PROCEDURE" | "RANGE" | "READ"
-| "REAL" | "REFERENCES" | "REGEXP" | "RENAME" | "REPEAT" | "REPLACE" | "RESTRICT" | "REVOKE" | "RIGHT" | "RLIKE"
-| "SCHEMA" | "SCHEMAS" | "SECOND_MICROSECOND" | "SELECT" | "SET" | "SHOW" | "SMALLINT"
+| "ON" | "OPTION" | "OR" | "ORDER" | "OUTER" | "OVER" | "PARTITION" | "PRECISION" | "PRIMARY" | "PROCEDURE" | "RANGE" | "READ" | "RECURSIVE"
+| "REAL" | "REFERENCES" | "REGEXP" | "RENAME" | "REPEAT" | "REPLACE" | "REQUIRE" | "RESTRICT" | "REVOKE" | "RIGHT" | "RLIKE"
+| "SCHEMA" | "SCHEMAS" | "SECOND_MICROSECOND" | "SELECT" | "SET" | "SHOW" | "SMALLINT" | "SPATIAL" | "SSL"
 | "STARTING" | "TABLE" | "STORED" | "TERMINATED" | "THEN" | "TINYBLOB" | "TINYINT" | "TINYTEXT" | "TO"
 | "TRAILING" | "TRIGGER" | "TRUE" | "UNION" | "UNIQUE" | "UNLOCK" | "UNSIGNED"
 | "UPDATE" | "USE" | "USING" | "UTC_DATE" | "UTC_TIMESTAMP" | "UTC_TIME" | "VALUES" | "VARBINARY" | "VARCHAR" | "VIRTUAL"
@@ -2483,6 +2840,10 @@ NotKeywordToken:
 |	"ANY_VALUE" | "INET_ATON" | "INET_NTOA" | "INET6_ATON" | "INET6_NTOA" | "IS_FREE_LOCK" | "IS_IPV4" | "IS_IPV4_COMPAT" | "IS_IPV4_MAPPED" | "IS_IPV6" | "IS_USED_LOCK" | "MASTER_POS_WAIT" | "NAME_CONST" | "RELEASE_ALL_LOCKS" | "UUID" | "UUID_SHORT"
 |	"COMPRESS" | "DECODE" | "DES_DECRYPT" | "DES_ENCRYPT" | "ENCODE" | "ENCRYPT" | "MD5" | "OLD_PASSWORD" | "RANDOM_BYTES" | "SHA1" | "SHA" | "SHA2" | "UNCOMPRESS" | "UNCOMPRESSED_LENGTH" | "VALIDATE_PASSWORD_STRENGTH"
 |	"JSON_EXTRACT" | "JSON_UNQUOTE" | "JSON_TYPE" | "JSON_MERGE" | "JSON_SET" | "JSON_INSERT" | "JSON_REPLACE" | "JSON_REMOVE" | "JSON_OBJECT" | "JSON_ARRAY" | "TIDB_VERSION" | "JOBS"
+|	"JSON_ARRAY_APPEND" | "JSON_CONTAINS" | "JSON_CONTAINS_PATH" | "JSON_DEPTH" | "JSON_KEYS" | "JSON_LENGTH" | "JSON_SEARCH" | "JSON_VALID"
+|	"ROW_NUMBER" | "RANK" | "DENSE_RANK" | "LAG" | "LEAD" | "FIRST_VALUE" | "LAST_VALUE"
+|	"MBRCONTAINS" | "MBRINTERSECTS" | "MBRWITHIN" | "ST_ASBINARY" | "ST_ASTEXT" | "ST_CONTAINS" | "ST_DISTANCE" | "ST_ENVELOPE"
+|	"ST_GEOMFROMTEXT" | "ST_GEOMFROMWKB" | "ST_GEOMETRYTYPE" | "ST_WITHIN" | "ST_X" | "ST_Y"
 
 /************************************************************************************
  *
@@ -2844,15 +3205,16 @@ Function:
 |	FunctionCallNonKeyword
 |	FunctionCallConflict
 |	FunctionCallAgg
-|	Identifier jss stringLit
+|	FunctionCallWindow
+|	ColumnName jss stringLit
 	{
-	    col := &ast.ColumnNameExpr{Name: &ast.ColumnName{Name: model.NewCIStr($1)}}
+	    col := &ast.ColumnNameExpr{Name: $1.(*ast.ColumnName)}
 	    expr := ast.NewValueExpr($3)
 	    $$ = &ast.FuncCallExpr{FnName: model.NewCIStr(ast.JSONExtract), Args: []ast.ExprNode{col, expr}}
 	}
-|	Identifier juss stringLit
+|	ColumnName juss stringLit
 	{
-	    col := &ast.ColumnNameExpr{Name: &ast.ColumnName{Name: model.NewCIStr($1)}}
+	    col := &ast.ColumnNameExpr{Name: $1.(*ast.ColumnName)}
 	    expr := ast.NewValueExpr($3)
 	    extract := &ast.FuncCallExpr{FnName: model.NewCIStr(ast.JSONExtract), Args: []ast.ExprNode{col, expr}}
 	    $$ = &ast.FuncCallExpr{FnName: model.NewCIStr(ast.JSONUnquote), Args: []ast.ExprNode{extract}}
@@ -3020,6 +3382,10 @@ FunctionCallKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName:model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
 	}
+|	"POINT" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
 |	"FORMAT" '(' ExpressionListOpt ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName:model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
@@ -3856,6 +4222,62 @@ FunctionCallNonKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
 	}
+|	"MBRCONTAINS" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"MBRINTERSECTS" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"MBRWITHIN" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"ST_ASBINARY" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"ST_ASTEXT" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"ST_CONTAINS" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"ST_DISTANCE" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"ST_ENVELOPE" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"ST_GEOMFROMTEXT" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"ST_GEOMFROMWKB" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"ST_GEOMETRYTYPE" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"ST_WITHIN" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"ST_X" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"ST_Y" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
 |	"JSON_EXTRACT" '(' ExpressionListOpt ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
@@ -3896,6 +4318,38 @@ FunctionCallNonKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
 	}
+|	"JSON_ARRAY_APPEND" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_CONTAINS" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_CONTAINS_PATH" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_DEPTH" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_KEYS" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_LENGTH" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_SEARCH" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"JSON_VALID" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
 |	"TIDB_VERSION" '(' ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1)}
@@ -3987,6 +4441,111 @@ FunctionCallAgg:
 		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
 	}
 
+FunctionCallWindow:
+	FunctionCallAgg "OVER" WindowSpec
+	{
+		agg := $1.(*ast.AggregateFuncExpr)
+		$$ = &ast.WindowFuncExpr{F: agg.F, Args: agg.Args, Distinct: agg.Distinct, Spec: $3.(ast.WindowSpec)}
+	}
+|	WindowFuncName '(' ')' "OVER" WindowSpec
+	{
+		$$ = &ast.WindowFuncExpr{F: $1, Spec: $5.(ast.WindowSpec)}
+	}
+|	WindowFuncNameWithArgs '(' ExpressionList ')' "OVER" WindowSpec
+	{
+		$$ = &ast.WindowFuncExpr{F: $1, Args: $3.([]ast.ExprNode), Spec: $6.(ast.WindowSpec)}
+	}
+
+WindowFuncName:
+	"ROW_NUMBER"
+|	"RANK"
+|	"DENSE_RANK"
+
+WindowFuncNameWithArgs:
+	"LAG"
+|	"LEAD"
+|	"FIRST_VALUE"
+|	"LAST_VALUE"
+
+WindowSpec:
+	'(' WindowPartitionByOpt WindowOrderByOpt WindowFrameClauseOpt ')'
+	{
+		spec := ast.WindowSpec{OrderBy: $3.([]*ast.ByItem)}
+		if $2 != nil {
+			spec.PartitionBy = $2.([]*ast.ByItem)
+		}
+		if $4 != nil {
+			spec.Frame = $4.(*ast.FrameClause)
+		}
+		$$ = spec
+	}
+
+WindowPartitionByOpt:
+	{
+		$$ = nil
+	}
+|	"PARTITION" "BY" ByList
+	{
+		$$ = $3
+	}
+
+WindowOrderByOpt:
+	{
+		$$ = []*ast.ByItem(nil)
+	}
+|	"ORDER" "BY" ByList
+	{
+		$$ = $3
+	}
+
+WindowFrameClauseOpt:
+	{
+		$$ = nil
+	}
+|	WindowFrameUnits WindowFrameStart
+	{
+		$$ = &ast.FrameClause{Type: $1.(ast.FrameType), Start: $2.(ast.FrameBound), End: ast.FrameBound{Type: ast.CurrentRow}}
+	}
+|	WindowFrameUnits "BETWEEN" WindowFrameBound "AND" WindowFrameBound
+	{
+		$$ = &ast.FrameClause{Type: $1.(ast.FrameType), Start: $3.(ast.FrameBound), End: $5.(ast.FrameBound)}
+	}
+
+WindowFrameUnits:
+	"ROWS"
+	{
+		$$ = ast.Rows
+	}
+|	"RANGE"
+	{
+		$$ = ast.Ranges
+	}
+
+WindowFrameStart:
+	"UNBOUNDED" "PRECEDING"
+	{
+		$$ = ast.FrameBound{Type: ast.Preceding, UnBounded: true}
+	}
+|	NumLiteral "PRECEDING"
+	{
+		$$ = ast.FrameBound{Type: ast.Preceding, Expr: ast.NewValueExpr($1)}
+	}
+|	"CURRENT" "ROW"
+	{
+		$$ = ast.FrameBound{Type: ast.CurrentRow}
+	}
+
+WindowFrameBound:
+	WindowFrameStart
+|	"UNBOUNDED" "FOLLOWING"
+	{
+		$$ = ast.FrameBound{Type: ast.Following, UnBounded: true}
+	}
+|	NumLiteral "FOLLOWING"
+	{
+		$$ = ast.FrameBound{Type: ast.Following, Expr: ast.NewValueExpr($1)}
+	}
+
 FuncDatetimePrec:
 	{
 		$$ = nil
@@ -4614,6 +5173,14 @@ TableFactor:
 	{
 		$$ = &ast.TableSource{Source: $2.(*ast.UnionStmt), AsName: $4.(model.CIStr)}
 	}
+|	'(' SelectStmtWithClause ')' TableAsName
+	{
+		if st, ok := $2.(*ast.SelectStmt); ok {
+			endOffset := parser.endOffset(&yyS[yypt-1])
+			parser.setLastSelectFieldText(st, endOffset)
+		}
+		$$ = &ast.TableSource{Source: $2.(ast.ResultSetNode), AsName: $4.(model.CIStr)}
+	}
 |	'(' TableRefs ')'
 	{
 		$$ = $2
@@ -4925,6 +5492,87 @@ SubSelect:
 		s.SetText(src[yyS[yypt-1].offset-1:yyS[yypt].offset-1])
 		$$ = &ast.SubqueryExpr{Query: s}
 	}
+|	'(' SelectStmtWithClause ')'
+	{
+		s := $2.(ast.ResultSetNode)
+		if sel, ok := s.(*ast.SelectStmt); ok {
+			endOffset := parser.endOffset(&yyS[yypt])
+			parser.setLastSelectFieldText(sel, endOffset)
+		}
+		src := parser.src
+		// See the implementation of yyParse function
+		s.SetText(src[yyS[yypt-1].offset-1:yyS[yypt].offset-1])
+		$$ = &ast.SubqueryExpr{Query: s}
+	}
+
+// See https://dev.mysql.com/doc/refman/8.0/en/with.html
+SelectStmtWithClause:
+	WithClause SelectStmt
+	{
+		st := $2.(*ast.SelectStmt)
+		st.With = $1.(*ast.WithClause)
+		$$ = st
+	}
+|	WithClause UnionStmt
+	{
+		st := $2.(*ast.UnionStmt)
+		st.With = $1.(*ast.WithClause)
+		$$ = st
+	}
+
+WithClause:
+	"WITH" WithList
+	{
+		$$ = &ast.WithClause{CTEs: $2.([]*ast.CommonTableExpression)}
+	}
+|	"WITH" "RECURSIVE" WithList
+	{
+		ctes := $3.([]*ast.CommonTableExpression)
+		for _, cte := range ctes {
+			cte.IsRecursive = true
+		}
+		$$ = &ast.WithClause{IsRecursive: true, CTEs: ctes}
+	}
+
+WithList:
+	CommonTableExpr
+	{
+		$$ = []*ast.CommonTableExpression{$1.(*ast.CommonTableExpression)}
+	}
+|	WithList ',' CommonTableExpr
+	{
+		$$ = append($1.([]*ast.CommonTableExpression), $3.(*ast.CommonTableExpression))
+	}
+
+CommonTableExpr:
+	Identifier CTEColumnListOpt "AS" SubSelect
+	{
+		$$ = &ast.CommonTableExpression{
+			Name:        model.NewCIStr($1),
+			ColNameList: $2.([]model.CIStr),
+			Query:       $4.(*ast.SubqueryExpr),
+		}
+	}
+
+CTEColumnListOpt:
+	/* EMPTY */
+	{
+		$$ = []model.CIStr{}
+	}
+|	'(' IdentList ')'
+	{
+		$$ = $2
+	}
+
+IdentList:
+	Identifier
+	{
+		$$ = []model.CIStr{model.NewCIStr($1)}
+	}
+|	IdentList ',' Identifier
+	{
+		$$ = append($1.([]model.CIStr), model.NewCIStr($3))
+	}
 
 // See https://dev.mysql.com/doc/refman/5.7/en/innodb-locking-reads.html
 SelectLockOpt:
@@ -5292,6 +5940,13 @@ ShowStmt:
 			Table:	$4.(*ast.TableName),
 		}
 	}
+|	"SHOW" "CREATE" "VIEW" TableName
+	{
+		$$ = &ast.ShowStmt{
+			Tp:	ast.ShowCreateView,
+			Table:	$4.(*ast.TableName),
+		}
+	}
 |	"SHOW" "CREATE" "DATABASE" DBName
 	{
 		$$ = &ast.ShowStmt{
@@ -5628,6 +6283,7 @@ Statement:
 |	CreateIndexStmt
 |	CreateTableStmt
 |	CreateUserStmt
+|	CreateViewStmt
 |	DoStmt
 |	DropDatabaseStmt
 |	DropIndexStmt
@@ -5652,6 +6308,7 @@ Statement:
 |	TruncateTableStmt
 |	UpdateStmt
 |	UseStmt
+|	SelectStmtWithClause
 |	SubSelect
 	{
 		// `(select 1)`; is a valid select statement
@@ -5663,6 +6320,7 @@ Statement:
 
 ExplainableStmt:
 	SelectStmt
+|	SelectStmtWithClause
 |	DeleteFromStmt
 |	UpdateStmt
 |	InsertIntoStmt
@@ -5881,6 +6539,14 @@ Type:
 	{
 		$$ = $1
 	}
+|	SpatialType
+	{
+		x := types.NewFieldType(mysql.TypeGeometry)
+		x.GeomType = $1.(geo.Type)
+		x.Charset = charset.CharsetBin
+		x.Collate = charset.CollationBin
+		$$ = x
+	}
 
 NumericType:
 	IntegerType OptFieldLen FieldOpts
@@ -6114,6 +6780,24 @@ StringType:
 		$$ = x
 	}
 
+SpatialType:
+	"GEOMETRY"
+	{
+		$$ = geo.TypeGeometry
+	}
+|	"POINT"
+	{
+		$$ = geo.TypePoint
+	}
+|	"LINESTRING"
+	{
+		$$ = geo.TypeLineString
+	}
+|	"POLYGON"
+	{
+		$$ = geo.TypePolygon
+	}
+
 NationalOpt:
 	{}
 |	"NATIONAL"
@@ -6392,22 +7076,24 @@ CommaOpt:
  *  https://dev.mysql.com/doc/refman/5.7/en/account-management-sql.html
  ************************************************************************************/
 CreateUserStmt:
-	"CREATE" "USER" IfNotExists UserSpecList
+	"CREATE" "USER" IfNotExists UserSpecList RequireClauseOpt
 	{
  		// See https://dev.mysql.com/doc/refman/5.7/en/create-user.html
 		$$ = &ast.CreateUserStmt{
 			IfNotExists: $3.(bool),
 			Specs: $4.([]*ast.UserSpec),
+			Require: $5.(ast.RequireType),
 		}
 	}
 
 /* See http://dev.mysql.com/doc/refman/5.7/en/alter-user.html */
 AlterUserStmt:
-	"ALTER" "USER" IfExists UserSpecList
+	"ALTER" "USER" IfExists UserSpecList RequireClauseOpt
 	{
 		$$ = &ast.AlterUserStmt{
 			IfExists: $3.(bool),
 			Specs: $4.([]*ast.UserSpec),
+			Require: $5.(ast.RequireType),
 		}
 	}
 | 	"ALTER" "USER" IfExists "USER" '(' ')' "IDENTIFIED" "BY" AuthString
@@ -6461,6 +7147,27 @@ AuthOption:
 			HashString: $4.(string),
 		}
 	}
+|	"IDENTIFIED" "WITH" StringName
+	{
+		$$ = &ast.AuthOption{
+			AuthPlugin: $3.(string),
+		}
+	}
+|	"IDENTIFIED" "WITH" StringName "BY" AuthString
+	{
+		$$ = &ast.AuthOption{
+			AuthPlugin: $3.(string),
+			AuthString: $5.(string),
+			ByAuthString: true,
+		}
+	}
+|	"IDENTIFIED" "WITH" StringName "AS" HashString
+	{
+		$$ = &ast.AuthOption{
+			AuthPlugin: $3.(string),
+			HashString: $5.(string),
+		}
+	}
 
 HashString:
 	stringLit
@@ -6468,19 +7175,37 @@ HashString:
 		$$ = $1
 	}
 
+RequireClauseOpt:
+	{
+		$$ = ast.RequireUnspecified
+	}
+|	"REQUIRE" "NONE"
+	{
+		$$ = ast.RequireNone
+	}
+|	"REQUIRE" "SSL"
+	{
+		$$ = ast.RequireSSL
+	}
+|	"REQUIRE" "X509"
+	{
+		$$ = ast.RequireX509
+	}
+
 /*************************************************************************************
  * Grant statement
  * See https://dev.mysql.com/doc/refman/5.7/en/grant.html
  *************************************************************************************/
 GrantStmt:
-	 "GRANT" PrivElemList "ON" ObjectType PrivLevel "TO" UserSpecList WithGrantOptionOpt
+	 "GRANT" PrivElemList "ON" ObjectType PrivLevel "TO" UserSpecList RequireClauseOpt WithGrantOptionOpt
 	 {
 		$$ = &ast.GrantStmt{
 			Privs: $2.([]*ast.PrivElem),
 			ObjectType: $4.(ast.ObjectTypeType),
 			Level: $5.(*ast.GrantLevel),
 			Users: $7.([]*ast.UserSpec),
-			WithGrant: $8.(bool),
+			Require: $8.(ast.RequireType),
+			WithGrant: $9.(bool),
 		}
 	 }
 
@@ -21,6 +21,7 @@ import (
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/testleak"
@@ -55,7 +56,7 @@ func (s *testParserSuite) TestSimple(c *C) {
 		"minute_microsecond", "minute_second", "mod", "not", "no_write_to_binlog", "null", "numeric",
 		"on", "option", "or", "order", "outer", "partition", "precision", "primary", "procedure", "range", "read", "real",
 		"references", "regexp", "rename", "repeat", "replace", "revoke", "restrict", "right", "rlike",
-		"schema", "schemas", "second_microsecond", "select", "set", "show", "smallint",
+		"schema", "schemas", "second_microsecond", "select", "set", "show", "smallint", "spatial",
 		"starting", "table", "terminated", "then", "tinyblob", "tinyint", "tinytext", "to",
 		"trailing", "true", "union", "unique", "unlock", "unsigned",
 		"update", "use", "using", "utc_date", "values", "varbinary", "varchar",
@@ -91,7 +92,8 @@ func (s *testParserSuite) TestSimple(c *C) {
 		"curtime", "variables", "dayname", "version", "btree", "hash", "row_format", "dynamic", "fixed", "compressed",
 		"compact", "redundant", "sql_no_cache sql_no_cache", "sql_cache sql_cache", "action", "round",
 		"enable", "disable", "reverse", "space", "privileges", "get_lock", "release_lock", "sleep", "no", "greatest", "least",
-		"binlog", "hex", "unhex", "function", "indexes", "from_unixtime", "processlist", "events", "less", "than", "timediff",
+		"binlog", "hex", "unhex", "function", "indexes", "from_unixtime", "processlist", "events", "less", "than", "timediff", "list",
+		"algorithm", "cascaded", "definer", "invoker", "merge", "security", "sql", "temptable", "undefined",
 		"ln", "log", "log2", "log10", "timestampdiff", "pi", "quote", "none", "super", "default", "shared", "exclusive",
 		"always", "stats", "stats_meta", "stats_histogram", "stats_buckets", "tidb_version",
 	}
@@ -431,6 +433,9 @@ func (s *testParserSuite) TestDBAStmt(c *C) {
 		// for show create table
 		{"show create table test.t", true},
 		{"show create table t", true},
+		// for show create view
+		{"show create view test.v", true},
+		{"show create view v", true},
 		// for show stats_meta.
 		{"show stats_meta", true},
 		{"show stats_meta where table_name = 't'", true},
@@ -1125,15 +1130,27 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{`SELECT UNCOMPRESSED_LENGTH(@compressed_string);`, true},
 		{`SELECT VALIDATE_PASSWORD_STRENGTH(@str);`, true},
 
+		// For spatial functions.
+		{`SELECT POINT(1, 2), ST_X(POINT(1, 2)), ST_Y(@g);`, true},
+		{`SELECT ST_ASTEXT(ST_GEOMFROMTEXT('POINT(1 1)', 4326)), ST_ASBINARY(ST_GEOMFROMWKB(@wkb));`, true},
+		{`SELECT ST_GEOMETRYTYPE(@g), ST_ENVELOPE(@g), ST_DISTANCE(@g1, @g2);`, true},
+		{`SELECT * FROM t WHERE ST_CONTAINS(@g, t.g) AND ST_WITHIN(t.g, @g);`, true},
+		{`SELECT * FROM t WHERE MBRCONTAINS(@g, t.g) OR MBRWITHIN(t.g, @g) OR MBRINTERSECTS(t.g, @g);`, true},
+
 		// For JSON functions.
 		{`SELECT JSON_EXTRACT();`, true},
 		{`SELECT JSON_UNQUOTE();`, true},
 		{`SELECT JSON_TYPE('[123]');`, true},
 		{`SELECT JSON_TYPE();`, true},
+		{`SELECT JSON_VALID('{}'), JSON_DEPTH('[]'), JSON_KEYS('{}'), JSON_KEYS('{}', '$');`, true},
+		{`SELECT JSON_CONTAINS('[1]', '1'), JSON_CONTAINS('[1]', '1', '$'), JSON_CONTAINS_PATH('[1]', 'one', '$[0]', '$[1]');`, true},
+		{`SELECT JSON_LENGTH('[1]'), JSON_LENGTH('[1]', '$[0]'), JSON_ARRAY_APPEND('[1]', '$', 2, '$[0]', 3);`, true},
+		{`SELECT JSON_SEARCH('["a"]', 'all', 'a'), JSON_SEARCH('["a"]', 'all', 'a', NULL, '$[0]');`, true},
 
 		// For two json grammar sugar.
 		{`SELECT a->'$.a' FROM t`, true},
 		{`SELECT a->>'$.a' FROM t`, true},
+		{`SELECT t.a->'$.a', test.t.a->>'$.a' FROM t WHERE t.a->'$.b' = 1`, true},
 		{`SELECT '{}'->'$.a' FROM t`, false},
 		{`SELECT '{}'->>'$.a' FROM t`, false},
 		{`SELECT a->3 FROM t`, false},
@@ -1253,6 +1270,9 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"create table t (c int) PARTITION BY HASH (c) PARTITIONS 32;", true},
 		{"create table t (c int) PARTITION BY RANGE (Year(VDate)) (PARTITION p1980 VALUES LESS THAN (1980) ENGINE = MyISAM, PARTITION p1990 VALUES LESS THAN (1990) ENGINE = MyISAM, PARTITION pothers VALUES LESS THAN MAXVALUE ENGINE = MyISAM)", true},
 		{"create table t (c int, `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '') PARTITION BY RANGE (UNIX_TIMESTAMP(create_time)) (PARTITION p201610 VALUES LESS THAN(1477929600), PARTITION p201611 VALUES LESS THAN(1480521600),PARTITION p201612 VALUES LESS THAN(1483200000),PARTITION p201701 VALUES LESS THAN(1485878400),PARTITION p201702 VALUES LESS THAN(1488297600),PARTITION p201703 VALUES LESS THAN(1490976000))", true},
+		{"create table t (c int) PARTITION BY LIST (c) (PARTITION p0 VALUES IN (1, 3, 5), PARTITION p1 VALUES IN (2, 4, NULL))", true},
+		{"create table t (c int) PARTITION BY LIST (c) (PARTITION p0 VALUES IN ())", false},
+		{"create table t (c int) PARTITION BY LIST (c) (PARTITION p0 VALUES LESS THAN MAXVALUE)", true},
 
 		// for check clause
 		{"create table t (c1 bool, c2 bool, check (c1 in (0, 1)), check (c2 in (0, 1)))", true},
@@ -1278,6 +1298,18 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"drop table if exists xxx", true},
 		{"drop table if not exists xxx", false},
 		{"drop view if exists xxx", true},
+		{"drop view xxx, yyy", true},
+		{"drop view if not exists xxx", false},
+		// for create view
+		{"create view v as select * from t", true},
+		{"create view v (a, b) as select c, d from t", true},
+		{"create or replace view v as select a from t union select b from s", true},
+		{"create algorithm = merge definer = 'root'@'localhost' sql security invoker view v as select 1", true},
+		{"create definer = current_user() sql security definer view v as select 1 with cascaded check option", true},
+		{"create view v as with c as (select 1) select * from c", true},
+		{"create view v as select 1 with check", false},
+		{"create view v () as select 1", false},
+		{"create algorithm = unknown view v as select 1", false},
 		{"drop stats t", true},
 		// for issue 974
 		{`CREATE TABLE address (
@@ -1420,6 +1452,13 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"ALTER TABLE t ADD UNIQUE (a) COMMENT 'a'", true},
 		{"ALTER TABLE t ADD UNIQUE KEY (a) COMMENT 'a'", true},
 		{"ALTER TABLE t ADD UNIQUE INDEX (a) COMMENT 'a'", true},
+		{"ALTER TABLE t ADD PARTITION (PARTITION p2 VALUES LESS THAN (30))", true},
+		{"ALTER TABLE t ADD PARTITION (PARTITION p2 VALUES LESS THAN (30), PARTITION p3 VALUES LESS THAN MAXVALUE)", true},
+		{"ALTER TABLE t ADD PARTITION (PARTITION p2 VALUES IN (7, 8))", true},
+		{"ALTER TABLE t ADD PARTITION", false},
+		{"ALTER TABLE t DROP PARTITION p1", true},
+		{"ALTER TABLE t TRUNCATE PARTITION p1", true},
+		{"ALTER TABLE t TRUNCATE PARTITION", false},
 
 		// For create index statement
 		{"CREATE INDEX idx ON t (a)", true},
@@ -1428,6 +1467,15 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"CREATE INDEX idx ON t (a) USING HASH COMMENT 'foo'", true},
 		{"CREATE INDEX idx USING BTREE ON t (a) USING HASH COMMENT 'foo'", true},
 		{"CREATE INDEX idx USING BTREE ON t (a)", true},
+		{"CREATE INDEX idx ON t ((a + 1))", true},
+		{"CREATE INDEX idx ON t (a, (json_extract(b, '$.c')) DESC)", true},
+		{"CREATE INDEX idx ON t (a + 1)", false},
+		{"CREATE TABLE t (a json, INDEX idx((cast(a->'$.b' as signed))))", true},
+		{"ALTER TABLE t ADD INDEX idx((a * 2), b)", true},
+		{"CREATE SPATIAL INDEX idx ON t (g)", true},
+		{"CREATE SPATIAL INDEX idx USING BTREE ON t (g)", false},
+		{"CREATE TABLE t (g geometry not null, SPATIAL KEY idx (g))", true},
+		{"ALTER TABLE t ADD SPATIAL INDEX (g)", true},
 
 		// for rename table statement
 		{"RENAME TABLE t TO t1", true},
@@ -1517,6 +1565,11 @@ func (s *testParserSuite) TestType(c *C) {
 
 		// for json type
 		{`create table t (a JSON);`, true},
+
+		// for spatial types
+		{`create table t (g geometry, p point not null, l linestring, pg polygon);`, true},
+		{`create table t (p point(10));`, false},
+		{`create table point (point point, polygon int);`, true},
 	}
 	s.RunTest(c, table)
 }
@@ -1556,6 +1609,18 @@ func (s *testParserSuite) TestPrivilege(c *C) {
 		{`ALTER USER IF EXISTS USER() IDENTIFIED BY 'new-password'`, true},
 		{`DROP USER 'root'@'localhost', 'root1'@'localhost'`, true},
 		{`DROP USER IF EXISTS 'root'@'localhost'`, true},
+		{`CREATE USER 'root'@'localhost' IDENTIFIED BY 'new-password' REQUIRE SSL`, true},
+		{`CREATE USER 'root'@'localhost', 'root'@'127.0.0.1' REQUIRE X509`, true},
+		{`CREATE USER 'root'@'localhost' REQUIRE NONE`, true},
+		{`CREATE USER 'root'@'localhost' REQUIRE`, false},
+		{`ALTER USER 'root'@'localhost' REQUIRE SSL`, true},
+		{`CREATE USER 'root'@'localhost' IDENTIFIED WITH sha256_password`, true},
+		{`CREATE USER 'root'@'localhost' IDENTIFIED WITH 'caching_sha2_password' BY 'new-password'`, true},
+		{`CREATE USER 'root'@'localhost' IDENTIFIED WITH auth_ldap AS 'uid=root,dc=example'`, true},
+		{`ALTER USER 'root'@'localhost' IDENTIFIED WITH mysql_native_password BY 'new-password'`, true},
+		{`CREATE USER 'root'@'localhost' IDENTIFIED WITH`, false},
+		{`CREATE TABLE ssl (a int)`, false},
+		{`CREATE TABLE x509 (a int)`, true},
 
 		// for grant statement
 		{"GRANT ALL ON db1.* TO 'jeffrey'@'localhost';", true},
@@ -1570,6 +1635,9 @@ func (s *testParserSuite) TestPrivilege(c *C) {
 		{"GRANT SELECT (col1), INSERT (col1,col2) ON mydb.mytbl TO 'someuser'@'somehost';", true},
 		{"grant all privileges on zabbix.* to 'zabbix'@'localhost' identified by 'password';", true},
 		{"GRANT SELECT ON test.* to 'test'", true}, // For issue 2654.
+		{"GRANT ALL ON db1.* TO 'jeffrey'@'localhost' REQUIRE SSL WITH GRANT OPTION;", true},
+		{"GRANT ALL ON db1.* TO 'jeffrey'@'localhost' IDENTIFIED BY 'password' REQUIRE X509;", true},
+		{"GRANT ALL ON db1.* TO 'jeffrey'@'localhost' WITH GRANT OPTION REQUIRE SSL;", false},
 
 		// for revoke statement
 		{"REVOKE ALL ON db1.* FROM 'jeffrey'@'localhost';", true},
@@ -1584,6 +1652,20 @@ func (s *testParserSuite) TestPrivilege(c *C) {
 		{"REVOKE all privileges on zabbix.* FROM 'zabbix'@'localhost' identified by 'password';", true},
 	}
 	s.RunTest(c, table)
+
+	parser := New()
+	stmt, err := parser.ParseOneStmt("GRANT ALL ON db1.* TO 'u'@'h' REQUIRE X509", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(stmt.(*ast.GrantStmt).Require, Equals, ast.RequireX509)
+	stmt, err = parser.ParseOneStmt("CREATE USER 'u'@'h' REQUIRE SSL", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(stmt.(*ast.CreateUserStmt).Require, Equals, ast.RequireSSL)
+	stmt, err = parser.ParseOneStmt("CREATE USER 'u'@'h' IDENTIFIED WITH 'auth_ldap' AS 'uid=u'", "", "")
+	c.Assert(err, IsNil)
+	authOpt := stmt.(*ast.CreateUserStmt).Specs[0].AuthOpt
+	c.Assert(authOpt.AuthPlugin, Equals, "auth_ldap")
+	c.Assert(authOpt.HashString, Equals, "uid=u")
+	c.Assert(authOpt.ByAuthString, IsFalse)
 }
 
 func (s *testParserSuite) TestComment(c *C) {
@@ -1646,6 +1728,79 @@ func (s *testParserSuite) TestUnion(c *C) {
 	s.RunTest(c, table)
 }
 
+func (s *testParserSuite) TestWindowFunction(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []testCase{
+		{"select row_number() over () from t", true},
+		{"select row_number() over (partition by a order by b) from t", true},
+		{"select rank() over (order by a desc), dense_rank() over (order by a) from t", true},
+		{"select lag(a) over (order by b), lead(a, 2, 0) over (partition by c order by b) from t", true},
+		{"select first_value(a) over (order by b), last_value(a) over (order by b) from t", true},
+		{"select sum(a) over (partition by b) from t", true},
+		{"select count(*) over (partition by a, b order by c) from t", true},
+		{"select avg(distinct a) over (partition by b) from t", true},
+		{"select sum(a) over (order by b rows unbounded preceding) from t", true},
+		{"select sum(a) over (order by b rows between 1 preceding and 1 following) from t", true},
+		{"select sum(a) over (order by b range between unbounded preceding and current row) from t", true},
+		{"select sum(a) over (order by b rows between current row and unbounded following) from t", true},
+		{"select a from t order by row_number() over (order by b)", true},
+		{"select rows, preceding, following, unbounded, current from t", true},
+		{"select row_number() from t", false},
+		{"select row_number(a) over () from t", false},
+		{"select lag() over () from t", false},
+		{"select sum(a) over (order by b rows 1 following) from t", false},
+		{"select sum(a) over (rows between a preceding and current row) from t", false},
+		{"select a over () from t", false},
+	}
+	s.RunTest(c, table)
+
+	// Check the AST of a window function.
+	parser := New()
+	stmt, err := parser.ParseOneStmt("select sum(a) over (partition by b order by c rows between 2 preceding and current row) from t", "", "")
+	c.Assert(err, IsNil)
+	win, ok := stmt.(*ast.SelectStmt).Fields.Fields[0].Expr.(*ast.WindowFuncExpr)
+	c.Assert(ok, IsTrue)
+	c.Assert(win.F, Equals, "sum")
+	c.Assert(win.Args, HasLen, 1)
+	c.Assert(win.Spec.PartitionBy, HasLen, 1)
+	c.Assert(win.Spec.OrderBy, HasLen, 1)
+	c.Assert(win.Spec.Frame.Type, Equals, ast.Rows)
+	c.Assert(win.Spec.Frame.Start.Type, Equals, ast.Preceding)
+	c.Assert(win.Spec.Frame.Start.Expr.GetValue(), Equals, int64(2))
+	c.Assert(win.Spec.Frame.End.Type, Equals, ast.CurrentRow)
+}
+
+func (s *testParserSuite) TestCommonTableExpression(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []testCase{
+		{"with cte as (select 1) select * from cte", true},
+		{"with cte (a, b) as (select 1, 2) select a, b from cte", true},
+		{"with c1 as (select 1), c2 as (select * from c1) select * from c2", true},
+		{"with recursive cte (n) as (select 1 union all select n + 1 from cte where n < 10) select * from cte", true},
+		{"with cte as (select 1) select * from cte union select * from cte", true},
+		{"select * from (with cte as (select 1) select * from cte) t", true},
+		{"select * from t where a in (with cte as (select 1) select * from cte)", true},
+		{"explain with cte as (select 1) select * from cte", true},
+		{"with cte as select 1 select * from cte", false},
+		{"with cte () as (select 1) select * from cte", false},
+		{"with recursive select 1", false},
+	}
+	s.RunTest(c, table)
+
+	parser := New()
+	stmt, err := parser.ParseOneStmt("with recursive cte (n) as (select 1 union all select n + 1 from cte) select * from cte", "", "")
+	c.Assert(err, IsNil)
+	with := stmt.(*ast.SelectStmt).With
+	c.Assert(with, NotNil)
+	c.Assert(with.IsRecursive, IsTrue)
+	c.Assert(with.CTEs, HasLen, 1)
+	c.Assert(with.CTEs[0].Name.L, Equals, "cte")
+	c.Assert(with.CTEs[0].IsRecursive, IsTrue)
+	c.Assert(with.CTEs[0].ColNameList, HasLen, 1)
+	_, ok := with.CTEs[0].Query.Query.(*ast.UnionStmt)
+	c.Assert(ok, IsTrue)
+}
+
 func (s *testParserSuite) TestLikeEscape(c *C) {
 	defer testleak.AfterTest(c)()
 	table := []testCase{
@@ -1762,8 +1917,26 @@ func (s *testParserSuite) TestExplain(c *C) {
 		{"explain replace into foo values (1 || 2)", true},
 		{"explain update t set id = id + 1 order by id desc;", true},
 		{"explain select c1 from t1 union (select c2 from t2) limit 1, 1", true},
+		{"explain analyze select c1 from t1", true},
+		{"explain analyze delete from t1 where c1 > 1", true},
+		{"desc analyze update t set id = id + 1", true},
+		{"explain analyze t1", false},
+		{"explain format = 'json' select c1 from t1", true},
+		{"explain format = json select c1 from t1", true},
+		{"explain format = dot delete from t1 where c1 > 1", true},
+		{"desc format = \"row\" select * from t1", true},
+		{"explain format = json t1", false},
+		{"explain format select c1 from t1", false},
+		{"explain format", true},
 	}
 	s.RunTest(c, table)
+
+	stmt, err := New().ParseOneStmt("explain format = JSON select c1 from t1", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(stmt.(*ast.ExplainStmt).Format, Equals, ast.ExplainFormatJSON)
+	stmt, err = New().ParseOneStmt("explain select c1 from t1", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(stmt.(*ast.ExplainStmt).Format, Equals, ast.ExplainFormatROW)
 }
 
 func (s *testParserSuite) TestTimestampDiffUnit(c *C) {
@@ -1865,6 +2038,48 @@ func (s *testParserSuite) TestDDLStatements(c *C) {
 		c.Assert(colDef.Tp.Collate, Equals, charset.CollationBin)
 		c.Assert(mysql.HasBinaryFlag(colDef.Tp.Flag), IsTrue)
 	}
+
+	createTableStr = `CREATE TABLE t (a int, b int) PARTITION BY RANGE ( a + b ) (
+		PARTITION p0 VALUES LESS THAN (10),
+		PARTITION p1 VALUES LESS THAN MAXVALUE)`
+	stmts, err = parser.Parse(createTableStr, "", "")
+	c.Assert(err, IsNil)
+	stmt = stmts[0].(*ast.CreateTableStmt)
+	c.Assert(stmt.Partition.Tp, Equals, model.PartitionTypeRange)
+	c.Assert(stmt.Partition.Expr.Text(), Equals, "a + b")
+	c.Assert(stmt.Partition.Definitions, HasLen, 2)
+	c.Assert(stmt.Partition.Definitions[0].Name.O, Equals, "p0")
+	c.Assert(stmt.Partition.Definitions[0].LessThan, HasLen, 1)
+	c.Assert(stmt.Partition.Definitions[1].MaxValue, IsTrue)
+
+	stmts, err = parser.Parse("CREATE TABLE t (a int) PARTITION BY HASH (a) PARTITIONS 4", "", "")
+	c.Assert(err, IsNil)
+	stmt = stmts[0].(*ast.CreateTableStmt)
+	c.Assert(stmt.Partition.Tp, Equals, model.PartitionTypeHash)
+	c.Assert(stmt.Partition.Num, Equals, uint64(4))
+	c.Assert(stmt.Partition.Definitions, HasLen, 0)
+
+	stmts, err = parser.Parse("create or replace sql security invoker view v (x) as select a from t where a > 1 with local check option; select 1", "", "")
+	c.Assert(err, IsNil)
+	viewStmt := stmts[0].(*ast.CreateViewStmt)
+	c.Assert(viewStmt.OrReplace, IsTrue)
+	c.Assert(viewStmt.Security, Equals, model.SecurityInvoker)
+	c.Assert(viewStmt.CheckOption, Equals, model.CheckOptionLocal)
+	c.Assert(viewStmt.Cols, DeepEquals, []model.CIStr{model.NewCIStr("x")})
+	c.Assert(viewStmt.Select.Text(), Equals, "select a from t where a > 1")
+	stmts, err = parser.Parse("create definer = 'u'@'%' view v as select 1 ; select 2", "", "")
+	c.Assert(err, IsNil)
+	viewStmt = stmts[0].(*ast.CreateViewStmt)
+	c.Assert(viewStmt.Definer.String(), Equals, "u@%")
+	c.Assert(viewStmt.Select.Text(), Equals, "select 1")
+
+	stmts, err = parser.Parse("create index idx on t (a, ( json_extract(b, '$.c') ) desc)", "", "")
+	c.Assert(err, IsNil)
+	indexStmt := stmts[0].(*ast.CreateIndexStmt)
+	c.Assert(indexStmt.IndexColNames, HasLen, 2)
+	c.Assert(indexStmt.IndexColNames[0].Expr, IsNil)
+	c.Assert(indexStmt.IndexColNames[1].Column, IsNil)
+	c.Assert(indexStmt.IndexColNames[1].Expr.Text(), Equals, "json_extract(b, '$.c')")
 }
 
 func (s *testParserSuite) TestAnalyze(c *C) {
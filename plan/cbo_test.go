@@ -271,6 +271,61 @@ func (s *testAnalyzeSuite) TestAnalyze(c *C) {
 	}
 }
 
+func (s *testAnalyzeSuite) TestJoinReOrderDP(c *C) {
+	defer func() {
+		testleak.AfterTest(c)()
+	}()
+	store, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	testKit := testkit.NewTestKit(c, store)
+	defer func() {
+		store.Close()
+	}()
+	testKit.MustExec("use test")
+	testKit.MustExec("drop table if exists f, big, small")
+	testKit.MustExec("create table f (a int, b int)")
+	testKit.MustExec("create table big (a int, b int)")
+	testKit.MustExec("create table small (a int, b int)")
+	for i := 0; i < 30; i++ {
+		testKit.MustExec(fmt.Sprintf("insert into f values (%d, %d)", i, i%10))
+		testKit.MustExec(fmt.Sprintf("insert into big values (%d, %d)", i, i))
+	}
+	testKit.MustExec("insert into small values (1, 1), (2, 2)")
+	testKit.MustExec("analyze table f, big, small")
+
+	sql := "select * from f, big, small where f.a = big.a and f.b = small.a"
+	tests := []struct {
+		threshold string
+		best      string
+	}{
+		// The greedy algorithm doesn't know the join with small is more selective.
+		{
+			threshold: "0",
+			best:      "LeftHashJoin{LeftHashJoin{TableReader(Table(f))->TableReader(Table(big))}(test.f.a,test.big.a)->TableReader(Table(small))}(test.f.b,test.small.a)",
+		},
+		{
+			threshold: "3",
+			best:      "RightHashJoin{LeftHashJoin{TableReader(Table(f))->TableReader(Table(small))}(test.f.b,test.small.a)->TableReader(Table(big))}(test.f.a,test.big.a)->Projection",
+		},
+	}
+	for _, tt := range tests {
+		testKit.MustExec("set @@tidb_opt_join_reorder_threshold = " + tt.threshold)
+		ctx := testKit.Se.(context.Context)
+		stmts, err := tidb.Parse(ctx, sql)
+		c.Assert(err, IsNil)
+		c.Assert(stmts, HasLen, 1)
+		stmt := stmts[0]
+		is := sessionctx.GetDomain(ctx).InfoSchema()
+		err = plan.ResolveName(stmt, is, ctx)
+		c.Assert(err, IsNil)
+		err = expression.InferType(ctx.GetSessionVars().StmtCtx, stmt)
+		c.Assert(err, IsNil)
+		p, err := plan.Optimize(ctx, stmt, is)
+		c.Assert(err, IsNil)
+		c.Assert(plan.ToString(p), Equals, tt.best, Commentf("for threshold %s", tt.threshold))
+	}
+}
+
 func newStoreWithBootstrap() (kv.Storage, error) {
 	store, err := tikv.NewMockTikvStore()
 	if err != nil {
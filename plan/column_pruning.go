@@ -119,6 +119,29 @@ func (p *LogicalAggregation) PruneColumns(parentUsedCols []*expression.Column) {
 	child.PruneColumns(selfUsedCols)
 }
 
+// PruneColumns implements LogicalPlan interface.
+func (p *LogicalWindow) PruneColumns(parentUsedCols []*expression.Column) {
+	child := p.children[0].(LogicalPlan)
+	windowCol := p.schema.Columns[p.schema.Len()-1]
+	var selfUsedCols []*expression.Column
+	for _, col := range parentUsedCols {
+		if !col.Equal(windowCol, nil) {
+			selfUsedCols = append(selfUsedCols, col)
+		}
+	}
+	for _, arg := range p.WindowFunc.Args {
+		selfUsedCols = append(selfUsedCols, expression.ExtractColumns(arg)...)
+	}
+	for _, item := range p.PartitionBy {
+		selfUsedCols = append(selfUsedCols, expression.ExtractColumns(item.Expr)...)
+	}
+	for _, item := range p.OrderBy {
+		selfUsedCols = append(selfUsedCols, expression.ExtractColumns(item.Expr)...)
+	}
+	child.PruneColumns(selfUsedCols)
+	p.schema.Columns = append(child.Schema().Clone().Columns, windowCol)
+}
+
 // PruneColumns implements LogicalPlan interface.
 func (p *Sort) PruneColumns(parentUsedCols []*expression.Column) {
 	child := p.children[0].(LogicalPlan)
@@ -184,6 +184,77 @@ func (s *testPlanSuite) TestDAGPlanBuilderSimpleCase(c *C) {
 	}
 }
 
+func (s *testPlanSuite) TestDAGPlanBuilderIndexMerge(c *C) {
+	store, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer store.Close()
+	se, err := tidb.CreateSession(store)
+	c.Assert(err, IsNil)
+
+	defer func() {
+		testleak.AfterTest(c)()
+	}()
+	tests := []struct {
+		sql  string
+		best string
+	}{
+		{
+			sql:  "select * from t where c = 1 or f = 2",
+			best: "IndexMerge(union, [Index(t.c_d_e)[[1,1]], Index(t.f)[[2,2]]], Table(t))",
+		},
+		{
+			sql:  "select * from t where (c = 1 and d > 2) or g = 3 or f > 10",
+			best: "IndexMerge(union, [Index(t.c_d_e)[(1 2 +inf,1 +inf +inf]], Index(t.g)[[3,3]], Index(t.f)[(10,+inf]]], Table(t))",
+		},
+		{
+			sql:  "select * from t where (c = 1 or f = 2) and b = 3",
+			best: "IndexMerge(union, [Index(t.c_d_e)[[1,1]], Index(t.f)[[2,2]]], Table(t)->Sel([eq(test.t.b, 3)]))",
+		},
+		{
+			sql:  "select * from t where c = 1 or b = 2",
+			best: "TableReader(Table(t)->Sel([or(eq(test.t.c, 1), eq(test.t.b, 2))]))",
+		},
+		{
+			sql:  "select * from t where c = 1 or c = 2",
+			best: "IndexLookUp(Index(t.c_d_e)[[1,1] [2,2]], Table(t))",
+		},
+		{
+			sql:  "select * from t where c = 1 or f = 2 order by b",
+			best: "IndexMerge(union, [Index(t.c_d_e)[[1,1]], Index(t.f)[[2,2]]], Table(t))->Sort",
+		},
+		{
+			sql:  "select * from t where c_str = 'abcdefghijk' or f = 2",
+			best: "IndexMerge(union, [Index(t.c_d_e_str)[[abcdefghijk,abcdefghijk]], Index(t.f)[[2,2]]], Table(t))",
+		},
+		{
+			sql:  "select * from t where c > 1 and f > 2 and g < 3",
+			best: "IndexLookUp(Index(t.g)[[-inf,3)], Table(t)->Sel([gt(test.t.c, 1) gt(test.t.f, 2)]))",
+		},
+		{
+			sql:  "select * from t where c = 1 and f = 2",
+			best: "IndexLookUp(Index(t.c_d_e)[[1,1]], Table(t)->Sel([eq(test.t.f, 2)]))",
+		},
+		{
+			sql:  "select * from t use index(c_d_e) where c = 1 or f = 2",
+			best: "IndexLookUp(Index(t.c_d_e)[[<nil>,+inf]], Table(t)->Sel([or(eq(test.t.c, 1), eq(test.t.f, 2))]))",
+		},
+	}
+	for _, tt := range tests {
+		comment := Commentf("for %s", tt.sql)
+		stmt, err := s.ParseOneStmt(tt.sql, "", "")
+		c.Assert(err, IsNil, comment)
+
+		err = se.NewTxn()
+		c.Assert(err, IsNil)
+
+		is, err := plan.MockResolve(stmt)
+		c.Assert(err, IsNil)
+		p, err := plan.Optimize(se, stmt, is)
+		c.Assert(err, IsNil)
+		c.Assert(plan.ToString(p), Equals, tt.best, comment)
+	}
+}
+
 func (s *testPlanSuite) TestDAGPlanBuilderJoin(c *C) {
 	store, err := newStoreWithBootstrap()
 	c.Assert(err, IsNil)
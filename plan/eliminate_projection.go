@@ -196,6 +196,18 @@ func (p *LogicalAggregation) replaceExprColumns(replace map[string]*expression.C
 	p.collectGroupByColumns()
 }
 
+func (p *LogicalWindow) replaceExprColumns(replace map[string]*expression.Column) {
+	for _, arg := range p.WindowFunc.Args {
+		resolveExprAndReplace(arg, replace)
+	}
+	for _, item := range p.PartitionBy {
+		resolveExprAndReplace(item.Expr, replace)
+	}
+	for _, item := range p.OrderBy {
+		resolveExprAndReplace(item.Expr, replace)
+	}
+}
+
 func (p *Selection) replaceExprColumns(replace map[string]*expression.Column) {
 	for _, expr := range p.Conditions {
 		resolveExprAndReplace(expr, replace)
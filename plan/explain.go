@@ -17,6 +17,7 @@ import (
 	"bytes"
 	"fmt"
 
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/expression"
 )
 
@@ -34,6 +35,11 @@ func setParents4FinalPlan(plan PhysicalPlan) {
 		case *PhysicalIndexLookUpReader:
 			setParents4FinalPlan(copPlan.indexPlan)
 			setParents4FinalPlan(copPlan.tablePlan)
+		case *PhysicalIndexMergeReader:
+			for _, partialPlan := range copPlan.partialPlans {
+				setParents4FinalPlan(partialPlan)
+			}
+			setParents4FinalPlan(copPlan.tablePlan)
 		}
 		for _, p := range allPlans[pID].Children() {
 			if !planMark[p.ID()] {
@@ -136,6 +142,19 @@ func (p *PhysicalIndexLookUpReader) ExplainInfo() string {
 	return fmt.Sprintf("index:%s, table:%s", p.indexPlan.ID(), p.tablePlan.ID())
 }
 
+// ExplainInfo implements PhysicalPlan interface.
+func (p *PhysicalIndexMergeReader) ExplainInfo() string {
+	buffer := bytes.NewBufferString(fmt.Sprintf("type:%s, partial:[", p.Type))
+	for i, partialPlan := range p.partialPlans {
+		if i > 0 {
+			buffer.WriteString(", ")
+		}
+		buffer.WriteString(partialPlan.ID())
+	}
+	fmt.Fprintf(buffer, "], table:%s", p.tablePlan.ID())
+	return buffer.String()
+}
+
 // ExplainInfo implements PhysicalPlan interface.
 func (p *PhysicalUnionScan) ExplainInfo() string {
 	return string(expression.ExplainExpressionList(p.Conditions))
@@ -156,19 +175,23 @@ func (p *TableDual) ExplainInfo() string {
 	return fmt.Sprintf("rows:%v", p.RowCount)
 }
 
-// ExplainInfo implements PhysicalPlan interface.
-func (p *Sort) ExplainInfo() string {
-	buffer := bytes.NewBufferString("")
-	for i, item := range p.ByItems {
+func explainByItems(buffer *bytes.Buffer, byItems []*ByItems) {
+	for i, item := range byItems {
 		order := "asc"
 		if item.Desc {
 			order = "desc"
 		}
 		buffer.WriteString(fmt.Sprintf("%s:%s", item.Expr.ExplainInfo(), order))
-		if i+1 < len(p.ByItems) {
+		if i+1 < len(byItems) {
 			buffer.WriteString(", ")
 		}
 	}
+}
+
+// ExplainInfo implements PhysicalPlan interface.
+func (p *Sort) ExplainInfo() string {
+	buffer := bytes.NewBufferString("")
+	explainByItems(buffer, p.ByItems)
 	return buffer.String()
 }
 
@@ -194,6 +217,50 @@ func (p *PhysicalAggregation) ExplainInfo() string {
 	return buffer.String()
 }
 
+// ExplainInfo implements PhysicalPlan interface.
+func (p *PhysicalWindow) ExplainInfo() string {
+	buffer := bytes.NewBufferString(p.WindowFunc.String())
+	buffer.WriteString(" over(")
+	if len(p.PartitionBy) > 0 {
+		buffer.WriteString("partition by ")
+		explainByItems(buffer, p.PartitionBy)
+	}
+	if len(p.OrderBy) > 0 {
+		if len(p.PartitionBy) > 0 {
+			buffer.WriteString(" ")
+		}
+		buffer.WriteString("order by ")
+		explainByItems(buffer, p.OrderBy)
+	}
+	if p.Frame != nil {
+		if len(p.PartitionBy)+len(p.OrderBy) > 0 {
+			buffer.WriteString(" ")
+		}
+		if p.Frame.Type == ast.Rows {
+			buffer.WriteString("rows")
+		} else {
+			buffer.WriteString("range")
+		}
+		buffer.WriteString(fmt.Sprintf(" between %s and %s", p.Frame.Start, p.Frame.End))
+	}
+	buffer.WriteString(")")
+	return buffer.String()
+}
+
+// ExplainInfo implements PhysicalPlan interface.
+func (p *PhysicalCTE) ExplainInfo() string {
+	buffer := bytes.NewBufferString(fmt.Sprintf("cte:%s, seed:%s", p.CTE.Name, p.CTE.SeedPlan.ID()))
+	if p.CTE.RecursivePlan != nil {
+		buffer.WriteString(fmt.Sprintf(", recursive:%s", p.CTE.RecursivePlan.ID()))
+	}
+	return buffer.String()
+}
+
+// ExplainInfo implements PhysicalPlan interface.
+func (p *CTETable) ExplainInfo() string {
+	return fmt.Sprintf("cte:%s", p.CTE.Name)
+}
+
 // ExplainInfo implements PhysicalPlan interface.
 func (p *PhysicalApply) ExplainInfo() string {
 	buffer := bytes.NewBufferString(p.PhysicalJoin.ExplainInfo())
@@ -249,6 +316,27 @@ func (p *PhysicalHashJoin) ExplainInfo() string {
 	return buffer.String()
 }
 
+// ExplainInfo implements PhysicalPlan interface.
+func (p *PhysicalCrossJoin) ExplainInfo() string {
+	buffer := bytes.NewBufferString(p.JoinType.String())
+	buffer.WriteString(fmt.Sprintf(", inner:%s", p.Children()[p.InnerChildIdx].ID()))
+	if p.Broadcast {
+		buffer.WriteString(", broadcast")
+	}
+	if len(p.LeftConditions) > 0 {
+		buffer.WriteString(fmt.Sprintf(", left cond:%s", p.LeftConditions))
+	}
+	if len(p.RightConditions) > 0 {
+		buffer.WriteString(fmt.Sprintf(", right cond:%s",
+			expression.ExplainExpressionList(p.RightConditions)))
+	}
+	if len(p.OtherConditions) > 0 {
+		buffer.WriteString(fmt.Sprintf(", other cond:%s",
+			expression.ExplainExpressionList(p.OtherConditions)))
+	}
+	return buffer.String()
+}
+
 // ExplainInfo implements PhysicalPlan interface.
 func (p *PhysicalHashSemiJoin) ExplainInfo() string {
 	buffer := bytes.NewBufferString(fmt.Sprintf("right:%s", p.Children()[1].ID()))
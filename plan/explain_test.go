@@ -14,7 +14,11 @@
 package plan_test
 
 import (
+	"encoding/json"
+	"strings"
+
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 )
@@ -272,3 +276,190 @@ func (s *testExplainSuite) TestExplain(c *C) {
 		result.Check(testkit.Rows(tt.expect...))
 	}
 }
+
+func (s *testExplainSuite) TestExplainFunctionalIndex(c *C) {
+	store, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	tk := testkit.NewTestKit(c, store)
+	defer func() {
+		testleak.AfterTest(c)()
+	}()
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int, c varchar(20), d json, index ab((a + b)), index c((lower(c))), index d((cast(d->'$.a' as signed))))")
+	tk.MustExec("create table tj (a json, b int as (a->'$.a'), c varchar(20) as (a->>'$.c'), index b(b), index c(c))")
+
+	tests := []struct {
+		sql    string
+		expect []string
+	}{
+		{
+			"select * from t where a + b = 7",
+			[]string{
+				"IndexScan_8   cop table:t, index:_V$_ab_0, range:[7,7], out of order:true 10",
+				"TableScan_9   cop table:t, keep order:false 10",
+				"IndexLookUp_10   root index:IndexScan_8, table:TableScan_9 10",
+			},
+		},
+		{
+			"select * from t where lower(c) = 'xyz'",
+			[]string{
+				"IndexScan_10   cop table:t, index:_V$_c_0, range:[xyz,xyz], out of order:true 10",
+				"TableScan_11   cop table:t, keep order:false 10",
+				"IndexLookUp_12 Selection_3  root index:IndexScan_10, table:TableScan_11 10",
+				"Selection_3  IndexLookUp_12 root eq(lower(test.t.c), xyz) 6400",
+			},
+		},
+		{
+			"select * from t where cast(d->'$.a' as signed) > 1",
+			[]string{
+				"IndexScan_13   cop table:t, index:_V$_d_0, range:(1,+inf], out of order:true 3333.3333333333335",
+				"TableScan_14   cop table:t, keep order:false 3333.3333333333335",
+				"IndexLookUp_15 Selection_3  root index:IndexScan_13, table:TableScan_14 3333.3333333333335",
+				"Selection_3  IndexLookUp_15 root gt(cast(json_extract(test.t.d, $.a)), 1) 6400",
+			},
+		},
+		{
+			"select * from t where a + b = 7 and c = 'x'",
+			[]string{
+				"IndexScan_8   cop table:t, index:_V$_ab_0, range:[7,7], out of order:true 10",
+				"TableScan_9 Selection_10  cop table:t, keep order:false 10",
+				"Selection_10  TableScan_9 cop eq(test.t.c, x) 10",
+				"IndexLookUp_11   root index:IndexScan_8, table:Selection_10 10",
+			},
+		},
+		{
+			"select * from t where a + b + 1 = 7",
+			[]string{
+				"TableScan_5 Selection_6  cop table:t, range:(-inf,+inf), keep order:false 8000",
+				"Selection_6  TableScan_5 cop eq(plus(plus(test.t.a, test.t.b), 1), 7) 8000",
+				"TableReader_7   root data:Selection_6 8000",
+			},
+		},
+		{
+			"select * from t where d->'$.a' = 2",
+			[]string{
+				"IndexScan_13   cop table:t, index:_V$_d_0, range:[2,2], out of order:true 10",
+				"TableScan_14   cop table:t, keep order:false 10",
+				"IndexLookUp_15 Selection_3  root index:IndexScan_13, table:TableScan_14 10",
+				"Selection_3  IndexLookUp_15 root eq(cast(json_extract(test.t.d, $.a)), 2) 6400",
+			},
+		},
+		{
+			"select * from tj where a->'$.a' = 2",
+			[]string{
+				"IndexScan_8   cop table:tj, index:b, range:[2,2], out of order:true 10",
+				"TableScan_9   cop table:tj, keep order:false 10",
+				"IndexLookUp_10 Selection_5  root index:IndexScan_8, table:TableScan_9 10",
+				"Selection_5 Projection_2 IndexLookUp_10 root eq(cast(json_extract(test.tj.a, $.a)), 2) 6400",
+				"Projection_2  Selection_5 root test.tj.a, cast(json_extract(test.tj.a, $.a)), cast(json_unquote(json_extract(test.tj.a, $.c))) 6400",
+			},
+		},
+		{
+			"select * from tj where a->'$.a' = 2.5",
+			[]string{
+				"TableScan_6   cop table:tj, range:(-inf,+inf), keep order:false 8000",
+				"TableReader_7 Selection_5  root data:TableScan_6 8000",
+				"Selection_5 Projection_2 TableReader_7 root eq(cast(json_extract(test.tj.a, $.a)), 2.5) 6400",
+				"Projection_2  Selection_5 root test.tj.a, cast(json_extract(test.tj.a, $.a)), cast(json_unquote(json_extract(test.tj.a, $.c))) 6400",
+			},
+		},
+		{
+			"select * from tj where a->'$.c' = 'abc'",
+			[]string{
+				"IndexScan_11   cop table:tj, index:c, range:[abc,abc], out of order:true 10",
+				"TableScan_12   cop table:tj, keep order:false 10",
+				"IndexLookUp_13 Selection_5  root index:IndexScan_11, table:TableScan_12 10",
+				"Selection_5 Projection_2 IndexLookUp_13 root eq(json_extract(test.tj.a, $.c), \"abc\") 6400",
+				"Projection_2  Selection_5 root test.tj.a, cast(json_extract(test.tj.a, $.a)), cast(json_unquote(json_extract(test.tj.a, $.c))) 6400",
+			},
+		},
+		{
+			"select * from tj where a->>'$.c' = 'abc'",
+			[]string{
+				"IndexScan_12   cop table:tj, index:c, range:[abc,abc], out of order:true 10",
+				"TableScan_13 Selection_14  cop table:tj, keep order:false 10",
+				"Selection_14  TableScan_13 cop eq(json_unquote(json_extract(test.tj.a, $.c)), abc) 8000",
+				"IndexLookUp_15 Projection_2  root index:IndexScan_12, table:Selection_14 8000",
+				"Projection_2  IndexLookUp_15 root test.tj.a, cast(json_extract(test.tj.a, $.a)), cast(json_unquote(json_extract(test.tj.a, $.c))) 8000",
+			},
+		},
+	}
+	for _, tt := range tests {
+		result := tk.MustQuery("explain " + tt.sql)
+		result.Check(testkit.Rows(tt.expect...))
+	}
+}
+
+type explainJSONNode struct {
+	ID                   string             `json:"id"`
+	Operator             string             `json:"operator"`
+	Task                 string             `json:"task"`
+	EstimatedRows        float64            `json:"estimated_rows"`
+	Cost                 *float64           `json:"cost"`
+	AccessObject         string             `json:"access_object"`
+	PushedDownConditions []string           `json:"pushed_down_conditions"`
+	Children             []*explainJSONNode `json:"children"`
+}
+
+func (s *testExplainSuite) TestExplainFormat(c *C) {
+	store, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	tk := testkit.NewTestKit(c, store)
+	defer func() {
+		testleak.AfterTest(c)()
+	}()
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1")
+	tk.MustExec("create table t1 (c1 int primary key, c2 int, c3 int, index c2 (c2))")
+
+	// The row format is the default one.
+	tk.MustQuery("explain format = 'row' select * from t1").Check(testkit.Rows(
+		"TableScan_3   cop table:t1, range:(-inf,+inf), keep order:false 8000",
+		"TableReader_4   root data:TableScan_3 8000",
+	))
+
+	rows := tk.MustQuery("explain format = 'json' select c3 from t1 where c2 > 1 and c3 < 10").Rows()
+	c.Assert(rows, HasLen, 1)
+	root := &explainJSONNode{}
+	c.Assert(json.Unmarshal([]byte(rows[0][0].(string)), root), IsNil)
+	c.Assert(root.Task, Equals, "root")
+	c.Assert(root.Cost, NotNil)
+	c.Assert(*root.Cost > 0, IsTrue)
+	var cops []*explainJSONNode
+	var collect func(n *explainJSONNode)
+	collect = func(n *explainJSONNode) {
+		c.Assert(strings.HasPrefix(n.ID, n.Operator+"_"), IsTrue)
+		if n.Task == "cop" {
+			c.Assert(n.Cost, IsNil)
+			cops = append(cops, n)
+		}
+		for _, child := range n.Children {
+			collect(child)
+		}
+	}
+	collect(root)
+	accessObjects := make(map[string]string)
+	var conds []string
+	for _, n := range cops {
+		if n.AccessObject != "" {
+			accessObjects[n.Operator] = n.AccessObject
+		}
+		conds = append(conds, n.PushedDownConditions...)
+	}
+	c.Assert(accessObjects["IndexScan"], Equals, "table:t1, index:c2")
+	c.Assert(accessObjects["TableScan"], Equals, "table:t1")
+	c.Assert(conds, DeepEquals, []string{"gt(test.t1.c2, 1)", "lt(test.t1.c3, 10)"})
+
+	rows = tk.MustQuery("explain format = 'dot' select * from t1 where c3 > 1 order by c2").Rows()
+	c.Assert(rows, HasLen, 1)
+	dot := rows[0][0].(string)
+	c.Assert(strings.HasPrefix(dot, "digraph Plan {\n"), IsTrue)
+	c.Assert(strings.HasSuffix(dot, "}\n"), IsTrue)
+	c.Assert(strings.Contains(dot, "subgraph \"cluster_"), IsTrue)
+	c.Assert(strings.Contains(dot, "->"), IsTrue)
+	c.Assert(strings.Contains(dot, "gt(test.t1.c3, 1)"), IsTrue)
+
+	_, err = tk.Exec("explain format = 'xml' select * from t1")
+	c.Assert(plan.ErrUnknownExplainFormat.Equal(err), IsTrue)
+}
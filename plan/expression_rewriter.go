@@ -38,9 +38,10 @@ func evalAstExpr(expr ast.ExprNode, ctx context.Context) (types.Datum, error) {
 		return val.Datum, nil
 	}
 	b := &planBuilder{
-		ctx:       ctx,
-		allocator: new(idAllocator),
-		colMapper: make(map[*ast.ColumnNameExpr]int),
+		ctx:          ctx,
+		allocator:    new(idAllocator),
+		colMapper:    make(map[*ast.ColumnNameExpr]int),
+		windowMapper: make(map[*ast.WindowFuncExpr]int),
 	}
 	if ctx.GetSessionVars().TxnCtx.InfoSchema != nil {
 		b.is = ctx.GetSessionVars().TxnCtx.InfoSchema.(infoschema.InfoSchema)
@@ -56,6 +57,25 @@ func evalAstExpr(expr ast.ExprNode, ctx context.Context) (types.Datum, error) {
 	return newExpr.Eval(nil)
 }
 
+// rewriteAstExpr rewrites ast expression directly, the columns in the expression are resolved by the schema,
+// and the indices of them are resolved to the offsets in the schema.
+func rewriteAstExpr(expr ast.ExprNode, schema *expression.Schema, ctx context.Context) (expression.Expression, error) {
+	b := &planBuilder{
+		ctx:          ctx,
+		allocator:    new(idAllocator),
+		colMapper:    make(map[*ast.ColumnNameExpr]int),
+		windowMapper: make(map[*ast.WindowFuncExpr]int),
+	}
+	mockPlan := TableDual{}.init(b.allocator, b.ctx)
+	mockPlan.SetSchema(schema)
+	newExpr, _, err := b.rewrite(expr, mockPlan, nil, true)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	newExpr.ResolveIndices(schema)
+	return newExpr, nil
+}
+
 // rewrite function rewrites ast expr to expression.Expression.
 // aggMapper maps ast.AggregateFuncExpr to the columns offset in p's output schema.
 // asScalar means whether this expression must be treated as a scalar expression.
@@ -234,6 +254,14 @@ func (er *expressionRewriter) Enter(inNode ast.Node) (ast.Node, bool) {
 		}
 		er.ctxStack = append(er.ctxStack, er.schema.Columns[index])
 		return inNode, true
+	case *ast.WindowFuncExpr:
+		index, ok := er.b.windowMapper[v]
+		if !ok {
+			er.err = ErrWindowInvalidWindowFuncUse.GenByArgs(strings.ToLower(v.F))
+			return inNode, true
+		}
+		er.ctxStack = append(er.ctxStack, er.schema.Columns[index])
+		return inNode, true
 	case *ast.ColumnNameExpr:
 		if index, ok := er.b.colMapper[v]; ok {
 			er.ctxStack = append(er.ctxStack, er.schema.Columns[index])
@@ -678,13 +706,26 @@ func (er *expressionRewriter) Leave(originInNode ast.Node) (retNode ast.Node, ok
 		inNode = er.preprocess(inNode)
 	}
 	switch v := inNode.(type) {
-	case *ast.AggregateFuncExpr, *ast.ColumnNameExpr, *ast.ParenthesesExpr, *ast.WhenClause,
+	case *ast.AggregateFuncExpr, *ast.WindowFuncExpr, *ast.ColumnNameExpr, *ast.ParenthesesExpr, *ast.WhenClause,
 		*ast.SubqueryExpr, *ast.ExistsSubqueryExpr, *ast.CompareSubqueryExpr, *ast.ValuesExpr:
 	case *ast.ValueExpr:
 		value := &expression.Constant{Value: v.Datum, RetType: &v.Type}
 		er.ctxStack = append(er.ctxStack, value)
 	case *ast.ParamMarkerExpr:
-		value := &expression.Constant{Value: v.Datum, RetType: &v.Type}
+		tp := &v.Type
+		value := &expression.Constant{Value: v.Datum, RetType: tp}
+		if er.ctx.GetSessionVars().StmtCtx.UseCache {
+			// The plan may be cached and executed with other parameter values later, so the constant
+			// refers to the parameter by its position instead of being folded with the current value.
+			tp = new(types.FieldType)
+			*tp = v.Type
+			value.RetType = tp
+			value.DeferredExpr, er.err = expression.NewFunction(er.ctx, ast.GetParam, tp,
+				&expression.Constant{Value: types.NewIntDatum(int64(v.Order)), RetType: types.NewFieldType(mysql.TypeLonglong)})
+			if er.err != nil {
+				return retNode, false
+			}
+		}
 		er.ctxStack = append(er.ctxStack, value)
 	case *ast.VariableExpr:
 		er.rewriteVariable(v)
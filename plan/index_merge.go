@@ -0,0 +1,294 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"math"
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/util/ranger"
+)
+
+// indexMergePath is a partial index scan of an index merge reader.
+type indexMergePath struct {
+	is       *PhysicalIndexScan
+	rowCount float64
+	// filters are the conditions of the path which can't be converted to the ranges.
+	filters []expression.Expression
+}
+
+// convertToIndexMerge tries to convert the DataSource to an index merge reader. A union is considered for every DNF
+// condition whose items can all be accessed by indices and which needs more than one index, an intersection is
+// considered for the CNF conditions accessed by different indices. It returns nil if no index merge can be built.
+func (p *DataSource) convertToIndexMerge(prop *requiredProp, indices []*model.IndexInfo) (task, error) {
+	// The index merge reader reads the handles out of order and can't be pushed down as a cop-task.
+	if !prop.isEmpty() || prop.taskTp != rootTaskType || p.unionScanSchema != nil || len(indices) < 2 {
+		return nil, nil
+	}
+	var best task
+	// The conditions on the indexed virtual generated columns which can't be pushed down are still checked by the
+	// Selection above the reader, so they needn't be checked after the table is read.
+	conds := make([]expression.Expression, 0, len(p.pushedDownConds)+len(p.virtualGenConds))
+	conds = append(conds, p.pushedDownConds...)
+	conds = append(conds, p.virtualGenConds...)
+	for i, cond := range conds {
+		sf, ok := cond.(*expression.ScalarFunction)
+		if !ok || sf.FuncName.L != ast.LogicOr {
+			continue
+		}
+		paths, covered, err := p.buildIndexMergeUnionPaths(sf, indices)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if paths == nil {
+			continue
+		}
+		tableFilters := p.pushedDownConds
+		if i < len(p.pushedDownConds) {
+			tableFilters = make([]expression.Expression, 0, len(p.pushedDownConds))
+			tableFilters = append(tableFilters, p.pushedDownConds[:i]...)
+			if !covered {
+				tableFilters = append(tableFilters, cond)
+			}
+			tableFilters = append(tableFilters, p.pushedDownConds[i+1:]...)
+		}
+		t := p.buildIndexMergeTask(IndexMergeUnion, paths, tableFilters)
+		if best == nil || t.cost() < best.cost() {
+			best = t
+		}
+	}
+	paths, tableFilters, err := p.buildIndexMergeIntersectionPaths(indices, conds)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if paths != nil {
+		t := p.buildIndexMergeTask(IndexMergeIntersection, paths, tableFilters)
+		if best == nil || t.cost() < best.cost() {
+			best = t
+		}
+	}
+	return best, nil
+}
+
+// buildIndexMergeUnionPaths finds the cheapest index scan for every DNF item of cond. covered means all the items
+// are fully converted to the ranges, so cond needn't be checked again after the table is read.
+func (p *DataSource) buildIndexMergeUnionPaths(cond *expression.ScalarFunction, indices []*model.IndexInfo) (paths []*indexMergePath, covered bool, err error) {
+	items := expression.SplitDNFItems(cond)
+	paths = make([]*indexMergePath, 0, len(items))
+	usedIndices := make(map[string]struct{}, len(items))
+	covered = true
+	for _, item := range items {
+		var best *indexMergePath
+		for _, idx := range indices {
+			path, err := p.buildIndexMergePath(idx, expression.SplitCNFItems(item))
+			if err != nil {
+				return nil, false, errors.Trace(err)
+			}
+			if path != nil && (best == nil || path.rowCount < best.rowCount) {
+				best = path
+			}
+		}
+		if best == nil {
+			return nil, false, nil
+		}
+		covered = covered && len(best.filters) == 0
+		usedIndices[best.is.Index.Name.L] = struct{}{}
+		paths = append(paths, best)
+	}
+	// If all the items are accessed by the same index, a single index scan on its ranges is better.
+	if len(usedIndices) < 2 {
+		return nil, false, nil
+	}
+	return paths, covered, nil
+}
+
+// buildIndexMergeIntersectionPaths picks the index scans on conds from the most selective one, skipping the ones which
+// don't access any new condition. The pushed down conditions which are fully converted to the ranges of some picked
+// index scan are removed from the returned table filters.
+func (p *DataSource) buildIndexMergeIntersectionPaths(indices []*model.IndexInfo, conds []expression.Expression) ([]*indexMergePath, []expression.Expression, error) {
+	candidates := make([]*indexMergePath, 0, len(indices))
+	for _, idx := range indices {
+		path, err := p.buildIndexMergePath(idx, conds)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if path != nil {
+			candidates = append(candidates, path)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].rowCount < candidates[j].rowCount
+	})
+	var (
+		paths       []*indexMergePath
+		accessConds []expression.Expression
+	)
+	for _, path := range candidates {
+		newAccess := false
+		for _, cond := range path.is.AccessCondition {
+			if !exprsContain(p.ctx, accessConds, cond) {
+				newAccess = true
+				break
+			}
+		}
+		if !newAccess {
+			continue
+		}
+		paths = append(paths, path)
+		accessConds = append(accessConds, path.is.AccessCondition...)
+	}
+	if len(paths) < 2 {
+		return nil, nil, nil
+	}
+	tableFilters := make([]expression.Expression, 0, len(p.pushedDownConds))
+	for _, cond := range p.pushedDownConds {
+		covered := false
+		for _, path := range paths {
+			if exprsContain(p.ctx, path.is.AccessCondition, cond) && !exprsContain(p.ctx, path.filters, cond) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			tableFilters = append(tableFilters, cond)
+		}
+	}
+	return paths, tableFilters, nil
+}
+
+// buildIndexMergePath builds a partial index scan on idx with the ranges built from conds. The expressions of the
+// indexed virtual generated columns in conds are substituted by the columns, so the functional indices can be used.
+// It returns nil if no range can be built.
+func (p *DataSource) buildIndexMergePath(idx *model.IndexInfo, conds []expression.Expression) (*indexMergePath, error) {
+	schemaCols := p.Schema().Columns
+	if len(p.virtualGenCols) > 0 {
+		schemaCols = make([]*expression.Column, 0, p.Schema().Len()+len(p.virtualGenCols))
+		schemaCols = append(schemaCols, p.Schema().Columns...)
+		schemaCols = append(schemaCols, p.virtualGenCols...)
+	}
+	idxCols, colLengths := expression.IndexInfo2Cols(schemaCols, idx)
+	if len(idxCols) == 0 {
+		return nil, nil
+	}
+	clonedConds := make([]expression.Expression, 0, len(conds))
+	var genConds []expression.Expression
+	for _, cond := range conds {
+		clonedCond := cond.Clone()
+		if len(p.virtualGenCols) > 0 {
+			clonedCond = p.substituteVirtualGenCols(clonedCond)
+			if jsonCond := p.substituteJSONEqualCond(cond); jsonCond != nil {
+				genConds = append(genConds, jsonCond)
+			}
+		}
+		clonedConds = append(clonedConds, clonedCond)
+	}
+	sc := p.ctx.GetSessionVars().StmtCtx
+	ranges, accesses, filters, err := ranger.BuildRange(sc, append(clonedConds, genConds...), ranger.IndexRangeType, idxCols, colLengths)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(p.virtualGenCols) > 0 {
+		filters = p.restoreVirtualGenCols(filters, genConds)
+	}
+	if len(accesses) == 0 {
+		return nil, nil
+	}
+	is := PhysicalIndexScan{
+		Table:               p.tableInfo,
+		PhysicalTableID:     p.physicalTableID,
+		TableAsName:         p.TableAsName,
+		DBName:              p.DBName,
+		Columns:             p.Columns,
+		Index:               idx,
+		OutOfOrder:          true,
+		DoubleRead:          true,
+		dataSourceSchema:    p.schema,
+		physicalTableSource: physicalTableSource{NeedColHandle: p.NeedColHandle},
+	}.init(p.allocator, p.ctx)
+	is.idxCols, is.idxColLens = idxCols, colLengths
+	is.AccessCondition = accesses
+	is.Ranges = ranger.Ranges2IndexRanges(ranges)
+	rowCount, err := p.statisticTable.GetRowCountByIndexRanges(sc, idx.ID, is.Ranges)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	is.SetSchema(p.indexScanSchema(is))
+	is.profile = p.getStatsProfileByFilter(accesses)
+	is.expectedCnt = rowCount
+	return &indexMergePath{is: is, rowCount: rowCount, filters: filters}, nil
+}
+
+// buildIndexMergeTask builds the index merge reader on the paths, the rows read from the table are filtered by
+// tableFilters. Every partial index scan is a separate request, so the cost of starting it is counted.
+func (p *DataSource) buildIndexMergeTask(tp IndexMergeType, paths []*indexMergePath, tableFilters []expression.Expression) task {
+	tableCount := math.Max(float64(p.statisticTable.Count), 1)
+	var (
+		cost      float64
+		handleCnt float64
+	)
+	partialPlans := make([]PhysicalPlan, 0, len(paths))
+	if tp == IndexMergeIntersection {
+		handleCnt = tableCount
+	}
+	for _, path := range paths {
+		cost += netWorkStartFactor + path.rowCount*(scanFactor+netWorkFactor+cpuFactor)
+		if tp == IndexMergeUnion {
+			handleCnt += path.rowCount
+		} else {
+			handleCnt *= path.rowCount / tableCount
+		}
+		partialPlans = append(partialPlans, path.is)
+	}
+	handleCnt = math.Min(handleCnt, tableCount)
+	cost += handleCnt * (scanFactor + netWorkFactor)
+
+	ts := PhysicalTableScan{Columns: p.Columns, Table: p.tableInfo, PhysicalTableID: p.physicalTableID}.init(p.allocator, p.ctx)
+	ts.SetSchema(p.schema)
+	// Only the rows of the handles are read from the table.
+	ts.profile = p.getStatsProfileByFilter(nil)
+	ts.profile = ts.profile.collapse(handleCnt / math.Max(ts.profile.count, 1))
+	ts.expectedCnt = handleCnt
+	var tablePlan PhysicalPlan = ts
+	if len(tableFilters) > 0 {
+		sel := Selection{Conditions: tableFilters}.init(p.allocator, p.ctx)
+		sel.SetSchema(ts.schema)
+		sel.SetChildren(ts)
+		sel.profile = p.getStatsProfileByFilter(p.pushedDownConds)
+		if sel.profile.count > handleCnt {
+			sel.profile = ts.profile
+		}
+		cost += handleCnt * cpuFactor
+		tablePlan = sel
+	}
+	reader := PhysicalIndexMergeReader{Type: tp, partialPlans: partialPlans, tablePlan: tablePlan}.init(p.allocator, p.ctx)
+	reader.profile = tablePlan.statsProfile()
+	t := &rootTask{p: reader, cst: cost}
+	recordCost(t)
+	return t
+}
+
+// exprsContain checks whether exprs contains an expression equal to expr.
+func exprsContain(ctx context.Context, exprs []expression.Expression, expr expression.Expression) bool {
+	for _, e := range exprs {
+		if e.Equal(expr, ctx) {
+			return true
+		}
+	}
+	return false
+}
@@ -31,6 +31,12 @@ const (
 	TypeStreamAgg = "StreamAgg"
 	// TypeHashAgg is the type of HashAgg.
 	TypeHashAgg = "HashAgg"
+	// TypeWindow is the type of Window.
+	TypeWindow = "Window"
+	// TypeCTE is the type of CTE.
+	TypeCTE = "CTE"
+	// TypeCTETable is the type of CTETable.
+	TypeCTETable = "CTETable"
 	// TypeCache is the type of cache.
 	TypeCache = "Cache"
 	// TypeShow is the type of show.
@@ -63,6 +69,8 @@ const (
 	TypeMergeJoin = "MergeJoin"
 	// TypeIndexJoin is the type of index look up join.
 	TypeIndexJoin = "IndexJoin"
+	// TypeCrossJoin is the type of block nested-loop cross join.
+	TypeCrossJoin = "CrossJoin"
 	// TypeApply is the type of Apply.
 	TypeApply = "Apply"
 	// TypeMaxOneRow is the type of MaxOneRow.
@@ -81,6 +89,8 @@ const (
 	TypeDelete = "Delete"
 	// TypeIndexLookUp is the type of IndexLookUp.
 	TypeIndexLookUp = "IndexLookUp"
+	// TypeIndexMerge is the type of IndexMerge.
+	TypeIndexMerge = "IndexMerge"
 	// TypeTableReader is the type of TableReader.
 	TypeTableReader = "TableReader"
 	// TypeIndexReader is the type of IndexReader.
@@ -93,6 +103,37 @@ func (p LogicalAggregation) init(allocator *idAllocator, ctx context.Context) *L
 	return &p
 }
 
+func (p LogicalWindow) init(allocator *idAllocator, ctx context.Context) *LogicalWindow {
+	p.basePlan = newBasePlan(TypeWindow, allocator, ctx, &p)
+	p.baseLogicalPlan = newBaseLogicalPlan(p.basePlan)
+	return &p
+}
+
+func (p PhysicalWindow) init(allocator *idAllocator, ctx context.Context) *PhysicalWindow {
+	p.basePlan = newBasePlan(TypeWindow, allocator, ctx, &p)
+	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
+	return &p
+}
+
+func (p LogicalCTE) init(allocator *idAllocator, ctx context.Context) *LogicalCTE {
+	p.basePlan = newBasePlan(TypeCTE, allocator, ctx, &p)
+	p.baseLogicalPlan = newBaseLogicalPlan(p.basePlan)
+	return &p
+}
+
+func (p PhysicalCTE) init(allocator *idAllocator, ctx context.Context) *PhysicalCTE {
+	p.basePlan = newBasePlan(TypeCTE, allocator, ctx, &p)
+	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
+	return &p
+}
+
+func (p CTETable) init(allocator *idAllocator, ctx context.Context) *CTETable {
+	p.basePlan = newBasePlan(TypeCTETable, allocator, ctx, &p)
+	p.baseLogicalPlan = newBaseLogicalPlan(p.basePlan)
+	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
+	return &p
+}
+
 func (p LogicalJoin) init(allocator *idAllocator, ctx context.Context) *LogicalJoin {
 	p.basePlan = newBasePlan(TypeJoin, allocator, ctx, &p)
 	p.baseLogicalPlan = newBaseLogicalPlan(p.basePlan)
@@ -243,6 +284,12 @@ func (p PhysicalHashSemiJoin) init(allocator *idAllocator, ctx context.Context)
 	return &p
 }
 
+func (p PhysicalCrossJoin) init(allocator *idAllocator, ctx context.Context) *PhysicalCrossJoin {
+	p.basePlan = newBasePlan(TypeCrossJoin, allocator, ctx, &p)
+	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
+	return &p
+}
+
 func (p PhysicalMergeJoin) init(allocator *idAllocator, ctx context.Context) *PhysicalMergeJoin {
 	p.basePlan = newBasePlan(TypeMergeJoin, allocator, ctx, &p)
 	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
@@ -287,6 +334,19 @@ func (p PhysicalIndexLookUpReader) init(allocator *idAllocator, ctx context.Cont
 	return &p
 }
 
+func (p PhysicalIndexMergeReader) init(allocator *idAllocator, ctx context.Context) *PhysicalIndexMergeReader {
+	p.basePlan = newBasePlan(TypeIndexMerge, allocator, ctx, &p)
+	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
+	p.TablePlans = flattenPushDownPlan(p.tablePlan)
+	p.PartialPlans = make([][]PhysicalPlan, 0, len(p.partialPlans))
+	for _, partialPlan := range p.partialPlans {
+		p.PartialPlans = append(p.PartialPlans, flattenPushDownPlan(partialPlan))
+	}
+	p.NeedColHandle = p.PartialPlans[0][0].(*PhysicalIndexScan).NeedColHandle
+	p.schema = p.tablePlan.Schema()
+	return &p
+}
+
 func (p PhysicalTableReader) init(allocator *idAllocator, ctx context.Context) *PhysicalTableReader {
 	p.basePlan = newBasePlan(TypeTableReader, allocator, ctx, &p)
 	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
@@ -0,0 +1,244 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"math"
+	"sort"
+
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+)
+
+// dpJoinEdge is an equal condition which connects two nodes of the join group.
+type dpJoinEdge struct {
+	nodes       [2]int
+	selectivity float64
+}
+
+// dpJoinCond is a condition on the columns of more than one node of the join group, which is not an equal condition
+// between two columns. nodeMask is the set of the nodes it refers to.
+type dpJoinCond struct {
+	nodeMask uint64
+}
+
+// dpJoinNode is the best join tree found for a set of nodes of the join group.
+type dpJoinNode struct {
+	// count is the estimated row count of the join of the set.
+	count float64
+	// cost is the sum of the estimated row counts of all the joins in the tree.
+	cost float64
+	// left and right are the sets of nodes joined by the top join of the tree, they are 0 for a single node.
+	left  uint64
+	right uint64
+}
+
+// reorderJoinByDP reorders the join group by dynamic programming. It enumerates all the connected subsets of the
+// group, and for each subset, all the ways to split it into two connected subsets joined by some equal conditions,
+// keeping the join tree with the least cost. The cost of a tree is the sum of the estimated row counts of its joins,
+// which are estimated by the row counts of the nodes and the selectivity of the conditions on them. If the graph of
+// the equal conditions isn't connected, the best trees of the connected components are made into a bushy join.
+func (e *joinReOrderSolver) reorderJoinByDP(group []LogicalPlan, conds []expression.Expression) {
+	e.group = group
+	counts, edges, otherConds := e.buildDPGraph(group, conds)
+	full := uint64(1)<<uint(len(group)) - 1
+	dp := make([]*dpJoinNode, full+1)
+	for i, count := range counts {
+		dp[1<<uint(i)] = &dpJoinNode{count: count}
+	}
+	for set := uint64(1); set <= full; set++ {
+		if set&(set-1) == 0 {
+			continue
+		}
+		// The left subset always contains the lowest node of the set, so that every split is visited once.
+		lowest := set & -set
+		for left := (set - 1) & set; left > 0; left = (left - 1) & set {
+			right := set ^ left
+			if left&lowest == 0 || dp[left] == nil || dp[right] == nil || !dpSetsConnected(edges, left, right) {
+				continue
+			}
+			if dp[set] == nil {
+				dp[set] = &dpJoinNode{count: estimateDPJoinCount(set, counts, edges, otherConds)}
+			}
+			cost := dp[left].cost + dp[right].cost + dp[set].count
+			if dp[set].left == 0 || cost < dp[set].cost {
+				dp[set].cost = cost
+				dp[set].left = left
+				dp[set].right = right
+			}
+		}
+	}
+	components := dpConnectedComponents(len(group), edges)
+	sort.Slice(components, func(i, j int) bool {
+		return dp[components[i]].count < dp[components[j]].count
+	})
+	cartesianJoinGroup := make([]LogicalPlan, 0, len(components))
+	for _, set := range components {
+		cartesianJoinGroup = append(cartesianJoinGroup, e.buildDPJoinTree(dp, set))
+	}
+	e.makeBushyJoin(cartesianJoinGroup)
+}
+
+// buildDPGraph estimates the row count of every node of the join group after being filtered by the conditions on it,
+// and extracts the edges and the other conditions between the nodes.
+func (e *joinReOrderSolver) buildDPGraph(group []LogicalPlan, conds []expression.Expression) ([]float64, []dpJoinEdge, []dpJoinCond) {
+	nodeConds := make([][]expression.Expression, len(group))
+	var (
+		eqConds    []*expression.ScalarFunction
+		otherConds []dpJoinCond
+	)
+	for _, cond := range conds {
+		if f, ok := cond.(*expression.ScalarFunction); ok && f.FuncName.L == ast.EQ {
+			lCol, lok := f.GetArgs()[0].(*expression.Column)
+			rCol, rok := f.GetArgs()[1].(*expression.Column)
+			if lok && rok && findColumnIndexByGroup(group, lCol) != findColumnIndexByGroup(group, rCol) {
+				eqConds = append(eqConds, f)
+				continue
+			}
+		}
+		var mask uint64
+		for _, col := range expression.ExtractColumns(cond) {
+			if idx := findColumnIndexByGroup(group, col); idx >= 0 {
+				mask |= 1 << uint(idx)
+			}
+		}
+		switch {
+		case mask == 0:
+		case mask&(mask-1) == 0:
+			idx := dpSingleNode(mask)
+			nodeConds[idx] = append(nodeConds[idx], cond)
+		default:
+			otherConds = append(otherConds, dpJoinCond{nodeMask: mask})
+		}
+	}
+	counts := make([]float64, len(group))
+	profiles := make([]*statsProfile, len(group))
+	for i, p := range group {
+		profiles[i] = e.dpNodeProfile(p, nodeConds[i])
+		counts[i] = math.Max(profiles[i].count, 1)
+	}
+	edges := make([]dpJoinEdge, 0, len(eqConds))
+	for _, f := range eqConds {
+		lCol, rCol := f.GetArgs()[0].(*expression.Column), f.GetArgs()[1].(*expression.Column)
+		lID, rID := findColumnIndexByGroup(group, lCol), findColumnIndexByGroup(group, rCol)
+		if lID < 0 || rID < 0 {
+			continue
+		}
+		lNDV := getCardinality([]*expression.Column{lCol}, group[lID].Schema(), profiles[lID])
+		rNDV := getCardinality([]*expression.Column{rCol}, group[rID].Schema(), profiles[rID])
+		edges = append(edges, dpJoinEdge{
+			nodes:       [2]int{lID, rID},
+			selectivity: 1 / math.Max(math.Max(lNDV, rNDV), 1),
+		})
+	}
+	return counts, edges, otherConds
+}
+
+// dpNodeProfile estimates the statistics of a node of the join group, which will be filtered by conds.
+func (e *joinReOrderSolver) dpNodeProfile(p LogicalPlan, conds []expression.Expression) *statsProfile {
+	if ds, ok := p.(*DataSource); ok {
+		filters := make(expression.CNFExprs, 0, len(ds.pushedDownConds)+len(conds))
+		filters = append(filters, ds.pushedDownConds...)
+		filters = append(filters, conds...)
+		return ds.getStatsProfileByFilter(filters)
+	}
+	profile := p.prepareStatsProfile()
+	if len(conds) > 0 {
+		profile = profile.collapse(selectionFactor)
+	}
+	return profile
+}
+
+// estimateDPJoinCount estimates the row count of the join of the nodes in set, applying the selectivity of all the
+// conditions between them.
+func estimateDPJoinCount(set uint64, counts []float64, edges []dpJoinEdge, otherConds []dpJoinCond) float64 {
+	count := 1.0
+	for i, c := range counts {
+		if set&(1<<uint(i)) != 0 {
+			count *= c
+		}
+	}
+	for _, edge := range edges {
+		if set&(1<<uint(edge.nodes[0])) != 0 && set&(1<<uint(edge.nodes[1])) != 0 {
+			count *= edge.selectivity
+		}
+	}
+	for _, cond := range otherConds {
+		if set&cond.nodeMask == cond.nodeMask {
+			count *= selectionFactor
+		}
+	}
+	return math.Max(count, 1)
+}
+
+// dpSetsConnected checks whether there is an edge between the node sets left and right.
+func dpSetsConnected(edges []dpJoinEdge, left, right uint64) bool {
+	for _, edge := range edges {
+		l, r := uint64(1)<<uint(edge.nodes[0]), uint64(1)<<uint(edge.nodes[1])
+		if (left&l != 0 && right&r != 0) || (left&r != 0 && right&l != 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// dpConnectedComponents returns the node sets of the connected components of the graph.
+func dpConnectedComponents(nodeCnt int, edges []dpJoinEdge) []uint64 {
+	parent := make([]int, nodeCnt)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for _, edge := range edges {
+		parent[find(edge.nodes[0])] = find(edge.nodes[1])
+	}
+	sets := make(map[int]uint64, nodeCnt)
+	roots := make([]int, 0, nodeCnt)
+	for i := 0; i < nodeCnt; i++ {
+		root := find(i)
+		if _, ok := sets[root]; !ok {
+			roots = append(roots, root)
+		}
+		sets[root] |= 1 << uint(i)
+	}
+	components := make([]uint64, 0, len(roots))
+	for _, root := range roots {
+		components = append(components, sets[root])
+	}
+	return components
+}
+
+// buildDPJoinTree builds the best join tree of the node set found by dynamic programming.
+func (e *joinReOrderSolver) buildDPJoinTree(dp []*dpJoinNode, set uint64) LogicalPlan {
+	node := dp[set]
+	if node.left == 0 {
+		return e.group[dpSingleNode(set)]
+	}
+	return e.newJoin(e.buildDPJoinTree(dp, node.left), e.buildDPJoinTree(dp, node.right))
+}
+
+// dpSingleNode returns the index of the only node in set.
+func dpSingleNode(set uint64) int {
+	idx := 0
+	for set>>uint(idx) != 1 {
+		idx++
+	}
+	return idx
+}
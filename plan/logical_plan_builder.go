@@ -122,7 +122,11 @@ func (b *planBuilder) buildResultSetNode(node ast.ResultSetNode) LogicalPlan {
 		case *ast.UnionStmt:
 			p = b.buildUnion(v)
 		case *ast.TableName:
-			p = b.buildDataSource(v)
+			if v.CTE != nil {
+				p = b.buildCTE(v)
+			} else {
+				p = b.buildDataSource(v)
+			}
 		default:
 			b.err = ErrUnsupportedType.Gen("unsupported table source type %T", v)
 			return nil
@@ -603,6 +607,167 @@ func (b *planBuilder) buildUnion(union *ast.UnionStmt) LogicalPlan {
 	return p
 }
 
+// buildCTE builds the plan for a table name which refers to a common table expression.
+func (b *planBuilder) buildCTE(tn *ast.TableName) LogicalPlan {
+	if def, ok := b.recursiveCTEs[tn.CTE]; ok {
+		// A reference inside the recursive query blocks reads the rows produced by the last iteration.
+		p := CTETable{CTE: def}.init(b.allocator, b.ctx)
+		p.SetSchema(buildCTESchema(p.id, tn.Name, def))
+		return p
+	}
+	def, ok := b.cteDefs[tn.CTE]
+	if !ok {
+		def = b.buildCTEDefinition(tn.CTE)
+		if b.err != nil {
+			return nil
+		}
+		if b.cteDefs == nil {
+			b.cteDefs = make(map[*ast.CommonTableExpression]*CTEDefinition)
+		}
+		b.cteDefs[tn.CTE] = def
+	}
+	p := LogicalCTE{CTE: def}.init(b.allocator, b.ctx)
+	p.SetSchema(buildCTESchema(p.id, tn.Name, def))
+	return p
+}
+
+func buildCTESchema(id string, tblName model.CIStr, def *CTEDefinition) *expression.Schema {
+	schema := expression.NewSchema(make([]*expression.Column, 0, def.schema.Len())...)
+	for i, col := range def.schema.Columns {
+		schema.Append(&expression.Column{
+			FromID:   id,
+			ColName:  col.ColName,
+			TblName:  tblName,
+			RetType:  col.RetType,
+			Position: i,
+		})
+	}
+	return schema
+}
+
+// buildCTEDefinition builds and optimizes the query of a common table expression.
+// For a recursive CTE, the query blocks of the union which don't refer to the CTE make up the seed part,
+// and the others make up the recursive part.
+func (b *planBuilder) buildCTEDefinition(cte *ast.CommonTableExpression) *CTEDefinition {
+	// The query of a CTE can't refer to the columns of the outer query.
+	outerSchemas := b.outerSchemas
+	b.outerSchemas = nil
+	defer func() {
+		b.outerSchemas = outerSchemas
+	}()
+
+	def := &CTEDefinition{Name: cte.Name}
+	union, ok := cte.Query.Query.(*ast.UnionStmt)
+	var seeds, recursives []*ast.SelectStmt
+	if ok && cte.IsRecursive {
+		for _, sel := range union.SelectList.Selects {
+			checker := &cteRefChecker{cte: cte}
+			sel.Accept(checker)
+			if checker.hasRef {
+				recursives = append(recursives, sel)
+			} else if len(recursives) > 0 {
+				b.err = ErrCTERecursiveRequiresNonRecursiveFirst.GenByArgs(cte.Name.O)
+				return nil
+			} else {
+				seeds = append(seeds, sel)
+			}
+		}
+	}
+	if len(recursives) == 0 {
+		seed := b.buildResultSetNode(cte.Query.Query)
+		if b.err != nil {
+			return nil
+		}
+		def.schema = b.buildCTEColumns(cte, seed.Schema())
+		if b.err != nil {
+			return nil
+		}
+		def.SeedPlan, b.err = doOptimize(b.optFlag, seed, b.ctx, b.allocator)
+		if b.err != nil {
+			return nil
+		}
+		return def
+	}
+
+	if union.OrderBy != nil || union.Limit != nil {
+		b.err = ErrUnsupportedType.Gen("ORDER BY / LIMIT over recursive common table expression")
+		return nil
+	}
+	for _, sel := range recursives {
+		if sel.Distinct || b.detectSelectAgg(sel) || len(b.extractWindowFuncs(sel.Fields.Fields)) > 0 {
+			b.err = ErrCTERecursiveForbidsAggregation.GenByArgs(cte.Name.O)
+			return nil
+		}
+	}
+	seed := b.buildCTEQueryBlocks(union.Distinct, seeds)
+	if b.err != nil {
+		return nil
+	}
+	def.schema = b.buildCTEColumns(cte, seed.Schema())
+	if b.err != nil {
+		return nil
+	}
+	if b.recursiveCTEs == nil {
+		b.recursiveCTEs = make(map[*ast.CommonTableExpression]*CTEDefinition)
+	}
+	b.recursiveCTEs[cte] = def
+	recursive := b.buildCTEQueryBlocks(union.Distinct, recursives)
+	delete(b.recursiveCTEs, cte)
+	if b.err != nil {
+		return nil
+	}
+	if recursive.Schema().Len() != def.schema.Len() {
+		b.err = errors.New("The used SELECT statements have a different number of columns")
+		return nil
+	}
+	for i, col := range def.schema.Columns {
+		col.RetType = joinFieldType(col.RetType, recursive.Schema().Columns[i].RetType)
+	}
+	def.IsDistinct = union.Distinct
+	def.SeedPlan, b.err = doOptimize(b.optFlag, seed, b.ctx, b.allocator)
+	if b.err != nil {
+		return nil
+	}
+	def.RecursivePlan, b.err = doOptimize(b.optFlag, recursive, b.ctx, b.allocator)
+	if b.err != nil {
+		return nil
+	}
+	return def
+}
+
+// buildCTEQueryBlocks builds a part of the query blocks of a recursive common table expression.
+func (b *planBuilder) buildCTEQueryBlocks(distinct bool, sels []*ast.SelectStmt) LogicalPlan {
+	if len(sels) == 1 {
+		return b.buildSelect(sels[0])
+	}
+	return b.buildUnion(&ast.UnionStmt{
+		Distinct:   distinct,
+		SelectList: &ast.UnionSelectList{Selects: sels},
+	})
+}
+
+// buildCTEColumns builds the output columns of a common table expression from its query's schema.
+func (b *planBuilder) buildCTEColumns(cte *ast.CommonTableExpression, schema *expression.Schema) *expression.Schema {
+	if len(cte.ColNameList) > 0 && len(cte.ColNameList) != schema.Len() {
+		b.err = errors.Trace(ErrViewWrongList)
+		return nil
+	}
+	cols := make([]*expression.Column, 0, schema.Len())
+	for i, col := range schema.Columns {
+		name := col.ColName
+		if len(cte.ColNameList) > 0 {
+			name = cte.ColNameList[i]
+		}
+		retType := *col.RetType
+		cols = append(cols, &expression.Column{
+			ColName:  name,
+			RetType:  &retType,
+			Position: i,
+		})
+	}
+	return expression.NewSchema(cols...)
+}
+
 // ByItems wraps a "by" item.
 type ByItems struct {
 	Expr expression.Expression
@@ -758,9 +923,16 @@ type havingAndOrderbyExprResolver struct {
 
 // Enter implements Visitor interface.
 func (a *havingAndOrderbyExprResolver) Enter(n ast.Node) (node ast.Node, skipChildren bool) {
-	switch n.(type) {
+	switch v := n.(type) {
 	case *ast.AggregateFuncExpr:
 		a.inAggFunc = true
+	case *ast.WindowFuncExpr:
+		// Window functions are computed before the projection, so they can only appear in order by clause,
+		// where they are appended to the select fields.
+		if !a.orderBy {
+			a.err = ErrWindowInvalidWindowFuncUse.GenByArgs(strings.ToLower(v.F))
+		}
+		return n, true
 	case *ast.ParamMarkerExpr, *ast.ColumnNameExpr, *ast.ColumnName:
 	case *ast.SubqueryExpr, *ast.ExistsSubqueryExpr:
 		// Enter a new context, skip it.
@@ -802,6 +974,15 @@ func (a *havingAndOrderbyExprResolver) resolveFromSchema(v *ast.ColumnNameExpr,
 // Leave implements Visitor interface.
 func (a *havingAndOrderbyExprResolver) Leave(n ast.Node) (node ast.Node, ok bool) {
 	switch v := n.(type) {
+	case *ast.WindowFuncExpr:
+		if a.err != nil {
+			return node, false
+		}
+		a.selectFields = append(a.selectFields, &ast.SelectField{
+			Auxiliary: true,
+			Expr:      v,
+			AsName:    model.NewCIStr(fmt.Sprintf("sel_window_%d", len(a.selectFields))),
+		})
 	case *ast.AggregateFuncExpr:
 		a.inAggFunc = false
 		a.aggMapper[v] = len(a.selectFields)
@@ -884,7 +1065,7 @@ func (b *planBuilder) resolveHavingAndOrderBy(sel *ast.SelectStmt, p LogicalPlan
 	// Extract agg funcs from having clause.
 	if sel.Having != nil {
 		n, ok := sel.Having.Expr.Accept(extractor)
-		if !ok {
+		if !ok || extractor.err != nil {
 			b.err = errors.Trace(extractor.err)
 			return nil, nil
 		}
@@ -924,6 +1105,148 @@ func (b *planBuilder) extractAggFuncs(fields []*ast.SelectField) ([]*ast.Aggrega
 	return aggList, totalAggMapper
 }
 
+func (b *planBuilder) extractWindowFuncs(fields []*ast.SelectField) []*ast.WindowFuncExpr {
+	extractor := &WindowFuncExtractor{}
+	for _, f := range fields {
+		f.Expr.Accept(extractor)
+	}
+	return extractor.WindowFuncs
+}
+
+// buildWindowFunctions builds a LogicalWindow for every window function, a Sort is added below the window if it has
+// partition by or order by items. It returns the new plan and the offsets of window functions' results in its schema.
+func (b *planBuilder) buildWindowFunctions(p LogicalPlan, windowFuncs []*ast.WindowFuncExpr, aggMapper map[*ast.AggregateFuncExpr]int) (LogicalPlan, map[*ast.WindowFuncExpr]int) {
+	windowMapper := make(map[*ast.WindowFuncExpr]int, len(windowFuncs))
+	for _, windowFunc := range windowFuncs {
+		if _, ok := windowMapper[windowFunc]; ok {
+			continue
+		}
+		args := make([]expression.Expression, 0, len(windowFunc.Args))
+		for _, arg := range windowFunc.Args {
+			newArg, np, err := b.rewrite(arg, p, aggMapper, true)
+			if err != nil {
+				b.err = errors.Trace(err)
+				return nil, nil
+			}
+			p = np
+			args = append(args, newArg)
+		}
+		partitionBy, np, err := b.buildWindowByItems(p, windowFunc.Spec.PartitionBy, aggMapper)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil, nil
+		}
+		orderBy, np, err := b.buildWindowByItems(np, windowFunc.Spec.OrderBy, aggMapper)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil, nil
+		}
+		p = np
+		desc, err := newWindowFuncDesc(windowFunc.F, args, windowFunc.Distinct)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil, nil
+		}
+		frame, err := b.buildWindowFrame(windowFunc.Spec.Frame, orderBy)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil, nil
+		}
+		if len(partitionBy)+len(orderBy) > 0 {
+			sort := Sort{}.init(b.allocator, b.ctx)
+			for _, item := range partitionBy {
+				sort.ByItems = append(sort.ByItems, item.Clone())
+			}
+			for _, item := range orderBy {
+				sort.ByItems = append(sort.ByItems, item.Clone())
+			}
+			addChild(sort, p)
+			sort.SetSchema(p.Schema().Clone())
+			p = sort
+		}
+		window := LogicalWindow{
+			WindowFunc:  desc,
+			PartitionBy: partitionBy,
+			OrderBy:     orderBy,
+			Frame:       frame,
+		}.init(b.allocator, b.ctx)
+		schema := p.Schema().Clone()
+		schema.Append(&expression.Column{
+			FromID:      window.id,
+			ColName:     model.NewCIStr(fmt.Sprintf("%s_col_0", window.id)),
+			Position:    schema.Len(),
+			IsAggOrSubq: true,
+			RetType:     desc.RetTp,
+		})
+		addChild(window, p)
+		window.SetSchema(schema)
+		p = window
+		windowMapper[windowFunc] = schema.Len() - 1
+	}
+	return p, windowMapper
+}
+
+func (b *planBuilder) buildWindowByItems(p LogicalPlan, byItems []*ast.ByItem, aggMapper map[*ast.AggregateFuncExpr]int) ([]*ByItems, LogicalPlan, error) {
+	items := make([]*ByItems, 0, len(byItems))
+	for _, item := range byItems {
+		if _, ok := item.Expr.(*ast.PositionExpr); ok {
+			// The position in a window specification is a constant, which doesn't affect the order.
+			continue
+		}
+		expr, np, err := b.rewrite(item.Expr, p, aggMapper, true)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		p = np
+		items = append(items, &ByItems{Expr: expr, Desc: item.Desc})
+	}
+	return items, p, nil
+}
+
+// unnamedWindow is the window name used in error messages, because named windows are not supported yet.
+const unnamedWindow = "<unnamed window>"
+
+func (b *planBuilder) buildWindowFrame(frame *ast.FrameClause, orderBy []*ByItems) (*WindowFrame, error) {
+	if frame == nil {
+		return nil, nil
+	}
+	if frame.Start.Type == ast.Following && frame.Start.UnBounded {
+		return nil, ErrWindowFrameStartIllegal.GenByArgs(unnamedWindow)
+	}
+	if frame.End.Type == ast.Preceding && frame.End.UnBounded {
+		return nil, ErrWindowFrameEndIllegal.GenByArgs(unnamedWindow)
+	}
+	start, err := b.buildFrameBound(frame.Type, &frame.Start, orderBy)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	end, err := b.buildFrameBound(frame.Type, &frame.End, orderBy)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &WindowFrame{Type: frame.Type, Start: start, End: end}, nil
+}
+
+func (b *planBuilder) buildFrameBound(tp ast.FrameType, bound *ast.FrameBound, orderBy []*ByItems) (*FrameBound, error) {
+	fb := &FrameBound{Type: bound.Type, UnBounded: bound.UnBounded}
+	if bound.Expr == nil {
+		return fb, nil
+	}
+	num := *bound.Expr.GetDatum()
+	switch tp {
+	case ast.Rows:
+		if num.Kind() != types.KindInt64 && num.Kind() != types.KindUint64 {
+			return nil, ErrWindowFrameIllegal.GenByArgs(unnamedWindow)
+		}
+	case ast.Ranges:
+		if len(orderBy) != 1 || orderBy[0].Expr.GetType().ToClass() == types.ClassString {
+			return nil, ErrWindowRangeFrameOrderType.GenByArgs(unnamedWindow)
+		}
+	}
+	fb.Num = num
+	return fb, nil
+}
+
 // gbyResolver resolves group by items from select fields.
 type gbyResolver struct {
 	fields []*ast.SelectField
@@ -1009,7 +1332,7 @@ func (b *planBuilder) unfoldWildStar(p LogicalPlan, selectFields []*ast.SelectFi
 		for _, col := range p.Schema().Columns {
 			if (dbName.L == "" || dbName.L == col.DBName.L) &&
 				(tblName.L == "" || tblName.L == col.TblName.L) &&
-				col.ID != model.ExtraHandleID {
+				col.ID != model.ExtraHandleID && !col.IsHidden {
 				colName := &ast.ColumnNameExpr{
 					Name: &ast.ColumnName{
 						Schema: col.DBName,
@@ -1125,11 +1448,30 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 			return nil
 		}
 	}
+	if windowFuncs := b.extractWindowFuncs(sel.Fields.Fields); len(windowFuncs) > 0 {
+		var windowMapper map[*ast.WindowFuncExpr]int
+		p, windowMapper = b.buildWindowFunctions(p, windowFuncs, totalMap)
+		if b.err != nil {
+			return nil
+		}
+		if b.windowMapper == nil {
+			b.windowMapper = make(map[*ast.WindowFuncExpr]int)
+		}
+		for windowFunc, offset := range windowMapper {
+			b.windowMapper[windowFunc] = offset
+		}
+	}
 	var oldLen int
 	p, oldLen = b.buildProjection(p, sel.Fields.Fields, totalMap)
 	if b.err != nil {
 		return nil
 	}
+	// The window functions in order by clause are resolved from the output of projection.
+	for i, field := range sel.Fields.Fields {
+		if windowFunc, ok := field.Expr.(*ast.WindowFuncExpr); ok {
+			b.windowMapper[windowFunc] = i
+		}
+	}
 	if sel.Having != nil {
 		p = b.buildSelection(p, sel.Having.Expr, havingMap)
 		if b.err != nil {
@@ -1198,15 +1540,22 @@ func (b *planBuilder) buildDataSource(tn *ast.TableName) LogicalPlan {
 		return nil
 	}
 	tableInfo := tbl.Meta()
+	if tableInfo.IsView() {
+		return b.buildDataSourceFromView(schemaName, tableInfo)
+	}
 
 	p := DataSource{
-		indexHints:     tn.IndexHints,
-		tableInfo:      tableInfo,
-		statisticTable: statisticTable,
-		DBName:         schemaName,
-		Columns:        make([]*model.ColumnInfo, 0, len(tableInfo.Columns)),
-		NeedColHandle:  b.needColHandle > 0,
+		indexHints:      tn.IndexHints,
+		tableInfo:       tableInfo,
+		statisticTable:  statisticTable,
+		DBName:          schemaName,
+		Columns:         make([]*model.ColumnInfo, 0, len(tableInfo.Columns)),
+		NeedColHandle:   b.needColHandle > 0,
+		physicalTableID: tableInfo.ID,
 	}.init(b.allocator, b.ctx)
+	if tableInfo.Partition != nil {
+		b.optFlag = b.optFlag | flagPartitionProcessor
+	}
 	b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SelectPriv, schemaName.L, tableInfo.Name.L, "")
 
 	var columns []*table.Column
@@ -1227,7 +1576,8 @@ func (b *planBuilder) buildDataSource(tn *ast.TableName) LogicalPlan {
 			DBName:   schemaName,
 			RetType:  &col.FieldType,
 			Position: i,
-			ID:       col.ID})
+			ID:       col.ID,
+			IsHidden: col.Hidden})
 		if tableInfo.PKIsHandle && mysql.HasPriKeyFlag(col.Flag) {
 			pkCol = schema.Columns[schema.Len()-1]
 		}
@@ -1304,6 +1654,14 @@ func (b *planBuilder) projectVirtualColumns(ds *DataSource, columns []*table.Col
 					b.err = errors.Trace(err)
 					return nil
 				}
+				// The value of the generated column is casted to the column type, so as the index values.
+				if !sameFieldType(expr.GetType(), &column.FieldType) {
+					expr = expression.NewCastFunc(&column.FieldType, expr, b.ctx)
+				}
+				if isIndexedColumn(ds.tableInfo, column.Name) {
+					ds.virtualGenCols = append(ds.virtualGenCols, colExpr.Clone().(*expression.Column))
+					ds.virtualGenExprs = append(ds.virtualGenExprs, expr.Clone())
+				}
 				exprIsGen = true
 			}
 		}
@@ -1318,6 +1676,18 @@ func (b *planBuilder) projectVirtualColumns(ds *DataSource, columns []*table.Col
 	return proj
 }
 
+// isIndexedColumn checks whether the column is a key part of some index of the table.
+func isIndexedColumn(tblInfo *model.TableInfo, colName model.CIStr) bool {
+	for _, idx := range tblInfo.Indices {
+		for _, idxCol := range idx.Columns {
+			if idxCol.Name.L == colName.L {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // buildApplyWithJoinType builds apply plan with outerPlan and innerPlan, which apply join with particular join type for
 // every row from outerPlan and the whole innerPlan.
 func (b *planBuilder) buildApplyWithJoinType(outerPlan, innerPlan LogicalPlan, tp JoinType) LogicalPlan {
@@ -1358,7 +1728,7 @@ out:
 		switch plan := p.(type) {
 		// This can be removed when in exists clause,
 		// e.g. exists(select count(*) from t order by a) is equal to exists t.
-		case *Projection, *Sort:
+		case *Projection, *Sort, *LogicalWindow:
 			p = p.Children()[0].(LogicalPlan)
 			p.SetParents()
 		case *LogicalAggregation:
@@ -1428,6 +1798,10 @@ func (b *planBuilder) buildUpdate(update *ast.UpdateStmt) LogicalPlan {
 	var tableList []*ast.TableName
 	tableList = extractTableList(sel.From.TableRefs, tableList)
 	for _, t := range tableList {
+		if t.TableInfo != nil && t.TableInfo.IsView() {
+			b.err = ErrNonUpdatableTable.GenByArgs(t.Name.O, "UPDATE")
+			return nil
+		}
 		dbName := t.Schema.L
 		if dbName == "" {
 			dbName = b.ctx.GetSessionVars().CurrentDB
@@ -1514,7 +1888,17 @@ func (b *planBuilder) buildUpdateLists(tableList []*ast.TableName, list []*ast.A
 	newList := make([]*expression.Assignment, 0, p.Schema().Len())
 	allAssignments := append(list, virtualAssignments...)
 	for i, assign := range allAssignments {
-		col, _, err := p.findColumn(assign.Column)
+		var col *expression.Column
+		var err error
+		if i < len(list) {
+			col, _, err = p.findColumn(assign.Column)
+		} else {
+			// The generated column may be a hidden column.
+			col, _, err = p.Schema().FindColumnIncludingHidden(assign.Column)
+			if err == nil && col == nil {
+				err = errors.Errorf("column %s not found", assign.Column.Name.O)
+			}
+		}
 		if err != nil {
 			b.err = errors.Trace(err)
 			return nil, nil
@@ -1620,6 +2004,10 @@ func (b *planBuilder) buildDelete(delete *ast.DeleteStmt) LogicalPlan {
 	if delete.Tables != nil {
 		// Delete a, b from a, b, c, d... add a and b.
 		for _, table := range delete.Tables.Tables {
+			if table.TableInfo.IsView() {
+				b.err = ErrNonUpdatableTable.GenByArgs(table.Name.O, "DELETE")
+				return nil
+			}
 			b.visitInfo = appendVisitInfo(b.visitInfo, mysql.DeletePriv, table.Schema.L, table.TableInfo.Name.L, "")
 		}
 	} else {
@@ -1627,6 +2015,10 @@ func (b *planBuilder) buildDelete(delete *ast.DeleteStmt) LogicalPlan {
 		var tableList []*ast.TableName
 		tableList = extractTableList(delete.TableRefs.TableRefs, tableList)
 		for _, v := range tableList {
+			if v.TableInfo != nil && v.TableInfo.IsView() {
+				b.err = ErrNonUpdatableTable.GenByArgs(v.Name.O, "DELETE")
+				return nil
+			}
 			dbName := v.Schema.L
 			if dbName == "" {
 				dbName = b.ctx.GetSessionVars().CurrentDB
@@ -1651,6 +2043,79 @@ func extractTableList(node ast.ResultSetNode, input []*ast.TableName) []*ast.Tab
 	return input
 }
 
+// buildDataSourceFromView expands the view into the plan of its select statement,
+// and renames the output columns to the columns of the view.
+func (b *planBuilder) buildDataSourceFromView(dbName model.CIStr, tableInfo *model.TableInfo) LogicalPlan {
+	viewName := dbName.L + "." + tableInfo.Name.L
+	for _, name := range b.expandingViews {
+		if name == viewName {
+			b.err = ErrViewRecursive.GenByArgs(dbName.O, tableInfo.Name.O)
+			return nil
+		}
+	}
+	b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SelectPriv, dbName.L, tableInfo.Name.L, "")
+
+	viewInfo := tableInfo.View
+	// The select statement is parsed with the charset and the collation used to create the view.
+	stmt, err := parser.New().ParseOneStmt(viewInfo.SelectStmt, viewInfo.Charset, viewInfo.Collate)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	// The tables in the view are resolved in the database of the view rather than the current database.
+	resolver := nameResolver{Info: b.is, Ctx: b.ctx, DefaultSchema: dbName}
+	stmt.Accept(&resolver)
+	if resolver.Err != nil {
+		b.err = ErrViewInvalid.GenByArgs(dbName.O, tableInfo.Name.O)
+		return nil
+	}
+	if err = expression.InferType(b.ctx.GetSessionVars().StmtCtx, stmt); err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+
+	// The select statement of a view can't refer to the columns of the outer query.
+	outerSchemas := b.outerSchemas
+	b.outerSchemas = nil
+	b.expandingViews = append(b.expandingViews, viewName)
+	defer func() {
+		b.outerSchemas = outerSchemas
+		b.expandingViews = b.expandingViews[:len(b.expandingViews)-1]
+	}()
+	start := len(b.visitInfo)
+	sel := b.buildResultSetNode(stmt.(ast.ResultSetNode))
+	if b.err != nil {
+		return nil
+	}
+	if viewInfo.Security == model.SecurityDefiner && viewInfo.Definer != nil {
+		for i := start; i < len(b.visitInfo); i++ {
+			if b.visitInfo[i].user == nil {
+				b.visitInfo[i].user = viewInfo.Definer
+			}
+		}
+	}
+	if sel.Schema().Len() != len(tableInfo.Columns) {
+		b.err = ErrViewInvalid.GenByArgs(dbName.O, tableInfo.Name.O)
+		return nil
+	}
+
+	proj := Projection{Exprs: expression.Column2Exprs(sel.Schema().Columns)}.init(b.allocator, b.ctx)
+	schema := expression.NewSchema(make([]*expression.Column, 0, len(tableInfo.Columns))...)
+	for i, col := range tableInfo.Columns {
+		schema.Append(&expression.Column{
+			FromID:   proj.id,
+			ColName:  col.Name,
+			TblName:  tableInfo.Name,
+			DBName:   dbName,
+			RetType:  sel.Schema().Columns[i].RetType,
+			Position: i,
+		})
+	}
+	proj.SetSchema(schema)
+	addChild(proj, sel)
+	return proj
+}
+
 func appendVisitInfo(vi []visitInfo, priv mysql.PrivilegeType, db, tbl, col string) []visitInfo {
 	return append(vi, visitInfo{
 		privilege: priv,
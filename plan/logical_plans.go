@@ -14,6 +14,9 @@
 package plan
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/expression"
@@ -26,6 +29,9 @@ import (
 var (
 	_ LogicalPlan = &LogicalJoin{}
 	_ LogicalPlan = &LogicalAggregation{}
+	_ LogicalPlan = &LogicalWindow{}
+	_ LogicalPlan = &LogicalCTE{}
+	_ LogicalPlan = &CTETable{}
 	_ LogicalPlan = &Projection{}
 	_ LogicalPlan = &Selection{}
 	_ LogicalPlan = &LogicalApply{}
@@ -199,6 +205,132 @@ func (p *LogicalAggregation) extractCorrelatedCols() []*expression.CorrelatedCol
 	return corCols
 }
 
+// WindowFuncDesc describes a window function, it is either a ranking or value function like row_number and lag,
+// or an aggregate function followed by an OVER clause.
+type WindowFuncDesc struct {
+	// Name is the lower case function name.
+	Name string
+	// Args is the function args.
+	Args []expression.Expression
+	// Distinct is only valid for aggregate functions.
+	Distinct bool
+	// RetTp is the return type of the function.
+	RetTp *types.FieldType
+}
+
+func newWindowFuncDesc(name string, args []expression.Expression, distinct bool) (*WindowFuncDesc, error) {
+	name = strings.ToLower(name)
+	desc := &WindowFuncDesc{Name: name, Args: args, Distinct: distinct}
+	switch name {
+	case ast.WindowFuncRowNumber, ast.WindowFuncRank, ast.WindowFuncDenseRank:
+		desc.RetTp = types.NewFieldType(mysql.TypeLonglong)
+		desc.RetTp.Flen = mysql.MaxIntWidth
+		desc.RetTp.Flag |= mysql.NotNullFlag
+		types.SetBinChsClnFlag(desc.RetTp)
+	case ast.WindowFuncLag, ast.WindowFuncLead:
+		if len(args) > 3 {
+			return nil, expression.ErrIncorrectParameterCount.GenByArgs(name)
+		}
+		if len(args) > 1 {
+			// The offset must be a non-negative integer constant.
+			con, ok := args[1].(*expression.Constant)
+			if !ok || (con.Value.Kind() != types.KindInt64 && con.Value.Kind() != types.KindUint64) || con.Value.GetInt64() < 0 {
+				return nil, ErrWindowFrameIllegal.GenByArgs(unnamedWindow)
+			}
+		}
+		retTp := *args[0].GetType()
+		retTp.Flag &^= mysql.NotNullFlag
+		desc.RetTp = &retTp
+	case ast.WindowFuncFirstValue, ast.WindowFuncLastValue:
+		if len(args) != 1 {
+			return nil, expression.ErrIncorrectParameterCount.GenByArgs(name)
+		}
+		retTp := *args[0].GetType()
+		retTp.Flag &^= mysql.NotNullFlag
+		desc.RetTp = &retTp
+	default:
+		agg := expression.NewAggFunction(name, args, distinct)
+		if agg == nil || name == ast.AggFuncGroupConcat {
+			return nil, ErrWindowInvalidWindowFuncUse.GenByArgs(name)
+		}
+		desc.RetTp = agg.GetType()
+	}
+	return desc, nil
+}
+
+// String implements fmt.Stringer interface.
+func (w *WindowFuncDesc) String() string {
+	return fmt.Sprintf("%s(%s)", w.Name, expression.ExplainExpressionList(w.Args))
+}
+
+// Clone copies a window function description.
+func (w *WindowFuncDesc) Clone() *WindowFuncDesc {
+	nw := *w
+	nw.Args = make([]expression.Expression, 0, len(w.Args))
+	for _, arg := range w.Args {
+		nw.Args = append(nw.Args, arg.Clone())
+	}
+	return &nw
+}
+
+// FrameBound is the bound of a window frame.
+type FrameBound struct {
+	Type      ast.BoundType
+	UnBounded bool
+	// Num is the offset of the bound, it is an integer for ROWS frames, a number for RANGE frames.
+	Num types.Datum
+}
+
+// String implements fmt.Stringer interface.
+func (b *FrameBound) String() string {
+	var str string
+	if b.Type == ast.CurrentRow {
+		return "current row"
+	} else if b.UnBounded {
+		str = "unbounded"
+	} else {
+		str = fmt.Sprintf("%v", b.Num.GetValue())
+	}
+	if b.Type == ast.Preceding {
+		return str + " preceding"
+	}
+	return str + " following"
+}
+
+// WindowFrame is the frame of a window.
+type WindowFrame struct {
+	Type  ast.FrameType
+	Start *FrameBound
+	End   *FrameBound
+}
+
+// LogicalWindow computes a window function for every row of its child. The child must be sorted by the
+// partition by items and then the order by items. The result is appended to the end of the child's output.
+type LogicalWindow struct {
+	*basePlan
+	baseLogicalPlan
+
+	WindowFunc  *WindowFuncDesc
+	PartitionBy []*ByItems
+	OrderBy     []*ByItems
+	// Frame is nil when the window has no frame clause.
+	Frame *WindowFrame
+}
+
+func (p *LogicalWindow) extractCorrelatedCols() []*expression.CorrelatedColumn {
+	corCols := p.basePlan.extractCorrelatedCols()
+	for _, arg := range p.WindowFunc.Args {
+		corCols = append(corCols, extractCorColumns(arg)...)
+	}
+	for _, item := range p.PartitionBy {
+		corCols = append(corCols, extractCorColumns(item.Expr)...)
+	}
+	for _, item := range p.OrderBy {
+		corCols = append(corCols, extractCorColumns(item.Expr)...)
+	}
+	return corCols
+}
+
 // Selection means a filter.
 type Selection struct {
 	*basePlan
@@ -261,6 +393,40 @@ type MaxOneRow struct {
 	basePhysicalPlan
 }
 
+// CTEDefinition is a common table expression in a statement. All the references of the common table expression
+// share the same definition, so its rows are only materialized once.
+type CTEDefinition struct {
+	Name model.CIStr
+	// SeedPlan computes the non-recursive query blocks.
+	SeedPlan PhysicalPlan
+	// RecursivePlan computes the recursive query blocks from the rows produced by the last iteration,
+	// it is nil if the common table expression is not recursive.
+	RecursivePlan PhysicalPlan
+	// IsDistinct is true if the query blocks are combined by UNION DISTINCT,
+	// so the duplicated rows produced by the recursive query blocks are discarded.
+	IsDistinct bool
+
+	schema *expression.Schema
+}
+
+// LogicalCTE reads the rows of a common table expression.
+type LogicalCTE struct {
+	*basePlan
+	baseLogicalPlan
+
+	CTE *CTEDefinition
+}
+
+// CTETable reads the rows produced by the last iteration of a recursive common table expression.
+// It is the reference of the common table expression in its recursive query blocks.
+type CTETable struct {
+	*basePlan
+	baseLogicalPlan
+	basePhysicalPlan
+
+	CTE *CTEDefinition
+}
+
 // TableDual represents a dual table plan.
 type TableDual struct {
 	*basePlan
@@ -294,6 +460,17 @@ type DataSource struct {
 
 	// This is schema the PhysicalUnionScan should be.
 	unionScanSchema *expression.Schema
+
+	// physicalTableID is the ID of the partition to read, or the table ID if the table isn't partitioned.
+	physicalTableID int64
+
+	// virtualGenCols are the indexed virtual generated columns, and virtualGenExprs are their expressions.
+	// They are used to match the conditions with the indices on the virtual generated columns.
+	virtualGenCols  []*expression.Column
+	virtualGenExprs []expression.Expression
+	// virtualGenConds are the conditions which can't be pushed down to coprocessor, but contain the
+	// expressions of the indexed virtual generated columns.
+	virtualGenConds []expression.Expression
 }
 
 func (p *DataSource) getPKIsHandleCol() *expression.Column {
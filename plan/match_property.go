@@ -229,6 +229,15 @@ func (p *PhysicalHashJoin) matchProperty(prop *requiredProperty, childPlanInfo .
 	return &physicalPlanInfo{p: np, cost: cost, count: estimateJoinCount(lRes.count, rRes.count)}
 }
 
+// matchProperty implements PhysicalPlan matchProperty interface.
+func (p *PhysicalCrossJoin) matchProperty(prop *requiredProperty, childPlanInfo ...*physicalPlanInfo) *physicalPlanInfo {
+	lRes, rRes := childPlanInfo[0], childPlanInfo[1]
+	np := p.Copy()
+	np.SetChildren(lRes.p, rRes.p)
+	cost := lRes.cost + rRes.cost + p.getCost(lRes.count, rRes.count)
+	return &physicalPlanInfo{p: np, cost: cost, count: lRes.count * rRes.count}
+}
+
 // matchProperty implements PhysicalPlan matchProperty interface.
 func (p *Union) matchProperty(_ *requiredProperty, childPlanInfo ...*physicalPlanInfo) *physicalPlanInfo {
 	np := p.Copy()
@@ -25,8 +25,10 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/ranger"
 	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tidb/util/types/json"
 )
 
 // wholeTaskTypes records all possible kinds of task that a plan can return. For Agg, TopN and Limit, we will try to get
@@ -271,6 +273,9 @@ func (p *LogicalJoin) generatePhysicalPlans() []PhysicalPlan {
 	case SemiJoin, LeftOuterSemiJoin:
 		return []PhysicalPlan{p.getSemiJoin()}
 	default:
+		if len(p.EqualConditions) == 0 {
+			return p.getCrossJoins()
+		}
 		mj := p.getMergeJoin()
 		if p.preferMergeJoin && len(mj) > 0 {
 			return mj
@@ -412,6 +417,36 @@ func (p *LogicalJoin) getHashJoin(smallTable int) PhysicalPlan {
 	return hashJoin
 }
 
+// getCrossJoins generates the block nested-loop joins for the join without equal conditions.
+// The outer join can only cache its inner side.
+func (p *LogicalJoin) getCrossJoins() []PhysicalPlan {
+	joins := make([]PhysicalPlan, 0, 4)
+	for innerIdx := 0; innerIdx < 2; innerIdx++ {
+		if (innerIdx == 0 && p.JoinType == LeftOuterJoin) || (innerIdx == 1 && p.JoinType == RightOuterJoin) {
+			continue
+		}
+		joins = append(joins, p.getCrossJoin(innerIdx, false), p.getCrossJoin(innerIdx, true))
+	}
+	return joins
+}
+
+func (p *LogicalJoin) getCrossJoin(innerIdx int, broadcast bool) PhysicalPlan {
+	crossJoin := PhysicalCrossJoin{
+		JoinType:        p.JoinType,
+		LeftConditions:  p.LeftConditions,
+		RightConditions: p.RightConditions,
+		OtherConditions: p.OtherConditions,
+		InnerChildIdx:   innerIdx,
+		Broadcast:       broadcast,
+		Concurrency:     JoinConcurrency,
+		DefaultValues:   p.DefaultValues,
+		outerSchema:     p.children[1-innerIdx].Schema(),
+	}.init(p.allocator, p.ctx)
+	crossJoin.SetSchema(p.schema)
+	crossJoin.profile = p.profile
+	return crossJoin
+}
+
 // getPropByOrderByItems will check if this sort property can be pushed or not. In order to simplify the problem, we only
 // consider the case that all expression are columns and all of them are asc or desc.
 func getPropByOrderByItems(items []*ByItems) (*requiredProp, bool) {
@@ -599,7 +634,7 @@ func (p *DataSource) tryToGetMemTask(prop *requiredProp) (task task, err error)
 // tryToGetDualTask will check if the push down predicate has false constant. If so, it will return table dual.
 func (p *DataSource) tryToGetDualTask() (task, error) {
 	for _, cond := range p.pushedDownConds {
-		if _, ok := cond.(*expression.Constant); ok {
+		if con, ok := cond.(*expression.Constant); ok && con.DeferredExpr == nil {
 			result, err := expression.EvalBool([]expression.Expression{cond}, nil, p.ctx)
 			if err != nil {
 				return nil, errors.Trace(err)
@@ -653,7 +688,7 @@ func (p *DataSource) convert2NewPhysicalPlan(prop *requiredProp) (task, error) {
 			return nil, errors.Trace(err)
 		}
 	}
-	if !includeTableScan || len(p.pushedDownConds) > 0 || len(prop.cols) > 0 {
+	if !includeTableScan || len(p.pushedDownConds) > 0 || len(p.virtualGenConds) > 0 || len(prop.cols) > 0 {
 		for _, idx := range indices {
 			idxTask, err := p.convertToIndexScan(prop, idx)
 			if err != nil {
@@ -663,6 +698,13 @@ func (p *DataSource) convert2NewPhysicalPlan(prop *requiredProp) (task, error) {
 				t = idxTask
 			}
 		}
+		mergeTask, err := p.convertToIndexMerge(prop, indices)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if mergeTask != nil && mergeTask.cost() < t.cost() {
+			t = mergeTask
+		}
 	}
 	return t, p.storeTask(prop, t)
 }
@@ -670,6 +712,7 @@ func (p *DataSource) convert2NewPhysicalPlan(prop *requiredProp) (task, error) {
 func (p *DataSource) forceToIndexScan(idx *model.IndexInfo) PhysicalPlan {
 	is := PhysicalIndexScan{
 		Table:               p.tableInfo,
+		PhysicalTableID:     p.physicalTableID,
 		TableAsName:         p.TableAsName,
 		DBName:              p.DBName,
 		Columns:             p.Columns,
@@ -685,11 +728,20 @@ func (p *DataSource) forceToIndexScan(idx *model.IndexInfo) PhysicalPlan {
 	}
 	if !isCoveringIndex(is.Columns, is.Index.Columns, is.Table.PKIsHandle) {
 		// On this way, it's double read case.
-		cop.tablePlan = PhysicalTableScan{Columns: p.Columns, Table: is.Table}.init(p.allocator, p.ctx)
+		cop.tablePlan = PhysicalTableScan{Columns: p.Columns, Table: is.Table, PhysicalTableID: is.PhysicalTableID}.init(p.allocator, p.ctx)
 		cop.tablePlan.SetSchema(is.dataSourceSchema)
 	}
+	is.SetSchema(p.indexScanSchema(is))
+	is.addPushedDownSelection(cop, p, math.MaxFloat64)
+	t := finishCopTask(cop, p.ctx, p.allocator)
+	return t.plan()
+}
+
+// indexScanSchema returns the schema of the index scan, which contains the index columns and the handle column
+// if the primary key is the handle.
+func (p *DataSource) indexScanSchema(is *PhysicalIndexScan) *expression.Schema {
 	var indexCols []*expression.Column
-	for _, col := range idx.Columns {
+	for _, col := range is.Index.Columns {
 		indexCols = append(indexCols, &expression.Column{FromID: p.id, Position: col.Offset})
 	}
 	if is.Table.PKIsHandle {
@@ -700,16 +752,14 @@ func (p *DataSource) forceToIndexScan(idx *model.IndexInfo) PhysicalPlan {
 			}
 		}
 	}
-	is.SetSchema(expression.NewSchema(indexCols...))
-	is.addPushedDownSelection(cop, p, math.MaxFloat64)
-	t := finishCopTask(cop, p.ctx, p.allocator)
-	return t.plan()
+	return expression.NewSchema(indexCols...)
 }
 
 // convertToIndexScan converts the DataSource to index scan with idx.
 func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo) (task task, err error) {
 	is := PhysicalIndexScan{
 		Table:               p.tableInfo,
+		PhysicalTableID:     p.physicalTableID,
 		TableAsName:         p.TableAsName,
 		DBName:              p.DBName,
 		Columns:             p.Columns,
@@ -720,19 +770,44 @@ func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo
 	statsTbl := p.statisticTable
 	rowCount := float64(statsTbl.Count)
 	sc := p.ctx.GetSessionVars().StmtCtx
-	idxCols, colLengths := expression.IndexInfo2Cols(p.Schema().Columns, idx)
+	schemaCols := p.Schema().Columns
+	if len(p.virtualGenCols) > 0 {
+		schemaCols = make([]*expression.Column, 0, p.Schema().Len()+len(p.virtualGenCols))
+		schemaCols = append(schemaCols, p.Schema().Columns...)
+		schemaCols = append(schemaCols, p.virtualGenCols...)
+	}
+	idxCols, colLengths := expression.IndexInfo2Cols(schemaCols, idx)
+	is.idxCols, is.idxColLens = idxCols, colLengths
 	is.Ranges = ranger.FullIndexRange()
-	if len(p.pushedDownConds) > 0 {
+	if len(p.pushedDownConds) > 0 || len(p.virtualGenConds) > 0 {
 		conds := make([]expression.Expression, 0, len(p.pushedDownConds))
 		for _, cond := range p.pushedDownConds {
 			conds = append(conds, cond.Clone())
 		}
 		if len(idxCols) > 0 {
+			var genConds []expression.Expression
+			if len(p.virtualGenCols) > 0 {
+				for i, cond := range conds {
+					conds[i] = p.substituteVirtualGenCols(cond)
+					if jsonCond := p.substituteJSONEqualCond(cond); jsonCond != nil {
+						genConds = append(genConds, jsonCond)
+					}
+				}
+				for _, cond := range p.virtualGenConds {
+					genConds = append(genConds, p.substituteVirtualGenCols(cond.Clone()))
+					if jsonCond := p.substituteJSONEqualCond(cond); jsonCond != nil {
+						genConds = append(genConds, jsonCond)
+					}
+				}
+			}
 			var ranges []types.Range
-			ranges, is.AccessCondition, is.filterCondition, err = ranger.BuildRange(sc, conds, ranger.IndexRangeType, idxCols, colLengths)
+			ranges, is.AccessCondition, is.filterCondition, err = ranger.BuildRange(sc, append(conds, genConds...), ranger.IndexRangeType, idxCols, colLengths)
 			if err != nil {
 				return nil, errors.Trace(err)
 			}
+			if len(p.virtualGenCols) > 0 {
+				is.filterCondition = p.restoreVirtualGenCols(is.filterCondition, genConds)
+			}
 			is.Ranges = ranger.Ranges2IndexRanges(ranges)
 			rowCount, err = statsTbl.GetRowCountByIndexRanges(sc, is.Index.ID, is.Ranges)
 			if err != nil {
@@ -748,7 +823,7 @@ func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo
 	}
 	if !isCoveringIndex(is.Columns, is.Index.Columns, is.Table.PKIsHandle) {
 		// On this way, it's double read case.
-		cop.tablePlan = PhysicalTableScan{Columns: p.Columns, Table: is.Table}.init(p.allocator, p.ctx)
+		cop.tablePlan = PhysicalTableScan{Columns: p.Columns, Table: is.Table, PhysicalTableID: is.PhysicalTableID}.init(p.allocator, p.ctx)
 		cop.tablePlan.SetSchema(is.dataSourceSchema)
 		// If it's parent requires single read task, return max cost.
 		if prop.taskTp == copSingleReadTaskType {
@@ -758,19 +833,7 @@ func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo
 		// If it's parent requires double read task, return max cost.
 		return &copTask{cst: math.MaxFloat64}, nil
 	}
-	var indexCols []*expression.Column
-	for _, col := range idx.Columns {
-		indexCols = append(indexCols, &expression.Column{FromID: p.id, Position: col.Offset})
-	}
-	if is.Table.PKIsHandle {
-		for _, col := range is.Columns {
-			if mysql.HasPriKeyFlag(col.Flag) {
-				indexCols = append(indexCols, &expression.Column{FromID: p.id, Position: col.Offset})
-				break
-			}
-		}
-	}
-	is.SetSchema(expression.NewSchema(indexCols...))
+	is.SetSchema(p.indexScanSchema(is))
 	// Check if this plan matches the property.
 	matchProperty := false
 	if !prop.isEmpty() {
@@ -826,6 +889,167 @@ func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo
 	return task, nil
 }
 
+// substituteVirtualGenCols substitutes the expressions of the indexed virtual generated columns in the condition
+// with the columns, so that the ranges can be built on the indices of the virtual generated columns.
+func (p *DataSource) substituteVirtualGenCols(expr expression.Expression) expression.Expression {
+	for i, genExpr := range p.virtualGenExprs {
+		if expr.Equal(genExpr, p.ctx) && sameFieldType(expr.GetType(), genExpr.GetType()) {
+			return p.virtualGenCols[i].Clone()
+		}
+	}
+	sf, ok := expr.(*expression.ScalarFunction)
+	if !ok {
+		return expr
+	}
+	args := sf.GetArgs()
+	newArgs := make([]expression.Expression, 0, len(args))
+	substituted := false
+	for _, arg := range args {
+		newArg := p.substituteVirtualGenCols(arg)
+		substituted = substituted || newArg != arg
+		newArgs = append(newArgs, newArg)
+	}
+	if !substituted {
+		return expr
+	}
+	if sf.FuncName.L == ast.Cast {
+		newFunc := sf.Clone().(*expression.ScalarFunction)
+		newFunc.GetArgs()[0] = newArgs[0]
+		return newFunc
+	}
+	newFunc, err := expression.NewFunction(p.ctx, sf.FuncName.L, sf.RetType, newArgs...)
+	if err != nil {
+		return expr
+	}
+	return newFunc
+}
+
+// substituteJSONEqualCond builds an equal condition on an indexed virtual generated column from the equal
+// condition on the json value which the column is generated from, e.g. `j->'$.a' = 1` is matched with the
+// column `a int as (j->'$.a')`, and `j->'$.b' = 'x'` is matched with the column `b varchar(10) as (j->>'$.b')`.
+// The json comparison and the column comparison are not the same, so the built condition is only used to
+// narrow the ranges, and the origin condition must still be evaluated. It returns nil if nothing is matched.
+func (p *DataSource) substituteJSONEqualCond(expr expression.Expression) expression.Expression {
+	sf, ok := expr.(*expression.ScalarFunction)
+	if !ok || sf.FuncName.L != ast.EQ {
+		return nil
+	}
+	arg, con := sf.GetArgs()[0], sf.GetArgs()[1]
+	if _, ok := arg.(*expression.Constant); ok {
+		arg, con = con, arg
+	}
+	c, ok := con.(*expression.Constant)
+	if !ok || c.DeferredExpr != nil {
+		return nil
+	}
+	arg = unwrapCast(arg)
+	for i, genExpr := range p.virtualGenExprs {
+		col := p.virtualGenCols[i]
+		genExpr = unwrapCast(genExpr)
+		if genExpr.GetType().Tp == mysql.TypeJSON && arg.Equal(genExpr, p.ctx) &&
+			col.GetTypeClass() == types.ClassInt {
+			// The json value is compared as double with the number constant.
+			if val, ok := jsonEqualIntValue(p.ctx.GetSessionVars().StmtCtx, c.Value); ok {
+				return p.newVirtualGenColEqualCond(sf, col, val, mysql.TypeLonglong)
+			}
+			continue
+		}
+		unquote, ok := genExpr.(*expression.ScalarFunction)
+		if !ok || unquote.FuncName.L != ast.JSONUnquote || col.GetTypeClass() != types.ClassString {
+			continue
+		}
+		var str string
+		if arg.Equal(unquote, p.ctx) && c.Value.Kind() == types.KindString {
+			str = c.Value.GetString()
+		} else if arg.Equal(unquote.GetArgs()[0], p.ctx) && c.Value.Kind() == types.KindMysqlJSON &&
+			c.Value.GetMysqlJSON().TypeCode == json.TypeCodeString {
+			// The json string is compared with the string constant.
+			str = c.Value.GetMysqlJSON().Str
+		} else {
+			continue
+		}
+		// The longer strings are truncated by the column.
+		if col.RetType.Flen != types.UnspecifiedLength && len([]rune(str)) > col.RetType.Flen {
+			continue
+		}
+		return p.newVirtualGenColEqualCond(sf, col, types.NewStringDatum(str), mysql.TypeVarString)
+	}
+	return nil
+}
+
+// newVirtualGenColEqualCond builds the condition `col = val` in the place of the equal condition eq.
+func (p *DataSource) newVirtualGenColEqualCond(eq *expression.ScalarFunction, col *expression.Column, val types.Datum,
+	tp byte) expression.Expression {
+	con := &expression.Constant{Value: val, RetType: types.NewFieldType(tp)}
+	cond, err := expression.NewFunction(p.ctx, ast.EQ, eq.RetType, col.Clone(), con)
+	if err != nil {
+		return nil
+	}
+	return cond
+}
+
+// jsonEqualIntValue returns the integer value of the constant which a json value is compared with,
+// it returns false if the constant is not an integer.
+func jsonEqualIntValue(sc *variable.StatementContext, d types.Datum) (types.Datum, bool) {
+	switch d.Kind() {
+	case types.KindInt64, types.KindUint64, types.KindFloat32, types.KindFloat64, types.KindMysqlDecimal:
+	default:
+		return d, false
+	}
+	f, err := d.ToFloat64(sc)
+	if err != nil || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return d, false
+	}
+	return types.NewIntDatum(int64(f)), true
+}
+
+// unwrapCast returns the argument of the cast function, or the expression itself if it's not a cast.
+func unwrapCast(expr expression.Expression) expression.Expression {
+	if sf, ok := expr.(*expression.ScalarFunction); ok && sf.FuncName.L == ast.Cast {
+		return sf.GetArgs()[0]
+	}
+	return expr
+}
+
+// restoreVirtualGenCols restores the virtual generated columns in the filter conditions to their expressions,
+// because they can't be read from the table. The conditions which can't be pushed down are removed,
+// they are still evaluated by the Selection.
+func (p *DataSource) restoreVirtualGenCols(filters, genConds []expression.Expression) []expression.Expression {
+	genSchema := expression.NewSchema(p.virtualGenCols...)
+	newFilters := make([]expression.Expression, 0, len(filters))
+	for _, filter := range filters {
+		isGenCond := false
+		for _, cond := range genConds {
+			if filter.Equal(cond, p.ctx) {
+				isGenCond = true
+				break
+			}
+		}
+		if !isGenCond {
+			newFilters = append(newFilters, expression.ColumnSubstitute(filter, genSchema, p.virtualGenExprs))
+		}
+	}
+	return newFilters
+}
+
+// sameFieldType checks whether the two field types are the same for the values.
+func sameFieldType(a, b *types.FieldType) bool {
+	if normalizeStringType(a.Tp) != normalizeStringType(b.Tp) || a.Flen != b.Flen ||
+		mysql.HasUnsignedFlag(a.Flag) != mysql.HasUnsignedFlag(b.Flag) {
+		return false
+	}
+	// The decimal of the string types is meaningless.
+	return a.ToClass() == types.ClassString || a.Decimal == b.Decimal
+}
+
+// normalizeStringType treats var_string as varchar, the column can't be var_string.
+func normalizeStringType(tp byte) byte {
+	if tp == mysql.TypeVarString {
+		return mysql.TypeVarchar
+	}
+	return tp
+}
+
 func (is *PhysicalIndexScan) addPushedDownSelection(copTask *copTask, p *DataSource, expectedCnt float64) {
 	// Add filter condition to table plan now.
 	if len(is.filterCondition) > 0 {
@@ -873,6 +1097,7 @@ func matchIndicesProp(idxCols []*model.IndexColumn, propCols []*expression.Colum
 func (p *DataSource) forceToTableScan() PhysicalPlan {
 	ts := PhysicalTableScan{
 		Table:               p.tableInfo,
+		PhysicalTableID:     p.physicalTableID,
 		Columns:             p.Columns,
 		TableAsName:         p.TableAsName,
 		DBName:              p.DBName,
@@ -898,6 +1123,7 @@ func (p *DataSource) convertToTableScan(prop *requiredProp) (task task, err erro
 	}
 	ts := PhysicalTableScan{
 		Table:               p.tableInfo,
+		PhysicalTableID:     p.physicalTableID,
 		Columns:             p.Columns,
 		TableAsName:         p.TableAsName,
 		DBName:              p.DBName,
@@ -1036,6 +1262,26 @@ func (p *PhysicalHashJoin) getChildrenPossibleProps(prop *requiredProp) [][]*req
 	return [][]*requiredProp{{&requiredProp{taskTp: rootTaskType, expectedCnt: prop.expectedCnt}, &requiredProp{taskTp: rootTaskType, expectedCnt: math.MaxFloat64}}}
 }
 
+// getChildrenPossibleProps gets the possible props of the children. The block nested-loop join keeps the order
+// of the outer child unless it is broadcast, so the sort property can be pushed to the outer child.
+func (p *PhysicalCrossJoin) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
+	outerIdx := 1 - p.InnerChildIdx
+	if !prop.isEmpty() {
+		if p.Broadcast {
+			return nil
+		}
+		for _, col := range prop.cols {
+			if p.outerSchema.ColumnIndex(col) == -1 {
+				return nil
+			}
+		}
+	}
+	props := make([]*requiredProp, 2)
+	props[outerIdx] = &requiredProp{taskTp: rootTaskType, cols: prop.cols, desc: prop.desc, expectedCnt: math.MaxFloat64}
+	props[p.InnerChildIdx] = &requiredProp{taskTp: rootTaskType, expectedCnt: math.MaxFloat64}
+	return [][]*requiredProp{props}
+}
+
 func (p *PhysicalHashSemiJoin) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
 	p.expectedCnt = prop.expectedCnt
 	lProp := &requiredProp{taskTp: rootTaskType, cols: prop.cols, expectedCnt: prop.expectedCnt, desc: prop.desc}
@@ -1060,6 +1306,20 @@ func (p *PhysicalApply) getChildrenPossibleProps(prop *requiredProp) [][]*requir
 	return [][]*requiredProp{{lProp, &requiredProp{taskTp: rootTaskType, expectedCnt: math.MaxFloat64}}}
 }
 
+// getChildrenPossibleProps gets the possible props of the children. The rows of the children are
+// returned one child after another, so the union can't keep the order even if all the children are ordered.
+func (p *Union) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
+	if !prop.isEmpty() {
+		return nil
+	}
+	p.expectedCnt = prop.expectedCnt
+	props := make([]*requiredProp, 0, len(p.children))
+	for range p.children {
+		props = append(props, &requiredProp{taskTp: rootTaskType, expectedCnt: prop.expectedCnt})
+	}
+	return [][]*requiredProp{props}
+}
+
 func (p *Limit) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
 	p.expectedCnt = prop.expectedCnt
 	if !prop.isEmpty() {
@@ -1101,6 +1361,57 @@ func (p *LogicalAggregation) generatePhysicalPlans() []PhysicalPlan {
 	return []PhysicalPlan{ha}
 }
 
+func (p *LogicalCTE) newPhysicalCTE() *PhysicalCTE {
+	cte := PhysicalCTE{CTE: p.CTE}.init(p.allocator, p.ctx)
+	cte.SetSchema(p.schema)
+	cte.profile = p.profile
+	return cte
+}
+
+// convert2NewPhysicalPlan implements LogicalPlan interface.
+func (p *LogicalCTE) convert2NewPhysicalPlan(prop *requiredProp) (task, error) {
+	t, err := p.getTask(prop)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if t != nil {
+		return t, nil
+	}
+	t = invalidTask
+	if prop.taskTp != rootTaskType {
+		return t, p.storeTask(prop, t)
+	}
+	t = &rootTask{p: p.newPhysicalCTE()}
+	t = prop.enforceProperty(t, p.ctx, p.allocator)
+	return t, p.storeTask(prop, t)
+}
+
+func (p *LogicalWindow) newPhysicalWindow() *PhysicalWindow {
+	window := PhysicalWindow{
+		WindowFunc:  p.WindowFunc,
+		PartitionBy: p.PartitionBy,
+		OrderBy:     p.OrderBy,
+		Frame:       p.Frame,
+	}.init(p.allocator, p.ctx)
+	window.SetSchema(p.schema)
+	window.profile = p.profile
+	return window
+}
+
+func (p *LogicalWindow) generatePhysicalPlans() []PhysicalPlan {
+	return []PhysicalPlan{p.newPhysicalWindow()}
+}
+
+func (p *PhysicalWindow) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
+	p.expectedCnt = prop.expectedCnt
+	if !prop.isEmpty() {
+		return nil
+	}
+	// The child of window is always a Sort that keeps the order of partition by and order by items, so the window
+	// just requires the whole result of its child in a root task.
+	return [][]*requiredProp{{{taskTp: rootTaskType, expectedCnt: math.MaxFloat64}}}
+}
+
 func (p *PhysicalAggregation) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
 	p.expectedCnt = prop.expectedCnt
 	if !prop.isEmpty() {
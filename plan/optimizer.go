@@ -26,15 +26,13 @@ import (
 	"github.com/pingcap/tidb/terror"
 )
 
-// AllowCartesianProduct means whether tidb allows cartesian join without equal conditions.
-var AllowCartesianProduct = true
-
 const (
 	flagPrunColumns uint64 = 1 << iota
 	flagEliminateProjection
 	flagBuildKeyInfo
 	flagDecorrelate
 	flagPredicatePushDown
+	flagPartitionProcessor
 	flagAggregationOptimize
 	flagPushDownTopN
 )
@@ -45,6 +43,7 @@ var optRuleList = []logicalOptRule{
 	&buildKeySolver{},
 	&decorrelateSolver{},
 	&ppdSolver{},
+	&partitionProcessor{},
 	&aggregationOptimizer{},
 	&pushDownTopNOptimizer{},
 }
@@ -57,34 +56,41 @@ type logicalOptRule interface {
 // Optimize does optimization and creates a Plan.
 // The node must be prepared first.
 func Optimize(ctx context.Context, node ast.Node, is infoschema.InfoSchema) (Plan, error) {
+	p, _, err := optimize(ctx, node, is)
+	return p, errors.Trace(err)
+}
+
+func optimize(ctx context.Context, node ast.Node, is infoschema.InfoSchema) (Plan, []visitInfo, error) {
 	// We have to infer type again because after parameter is set, the expression type may change.
 	if err := expression.InferType(ctx.GetSessionVars().StmtCtx, node); err != nil {
-		return nil, errors.Trace(err)
+		return nil, nil, errors.Trace(err)
 	}
 	allocator := new(idAllocator)
 	builder := &planBuilder{
-		ctx:       ctx,
-		is:        is,
-		colMapper: make(map[*ast.ColumnNameExpr]int),
-		allocator: allocator,
+		ctx:          ctx,
+		is:           is,
+		colMapper:    make(map[*ast.ColumnNameExpr]int),
+		windowMapper: make(map[*ast.WindowFuncExpr]int),
+		allocator:    allocator,
 	}
 	p := builder.build(node)
 	if builder.err != nil {
-		return nil, errors.Trace(builder.err)
+		return nil, nil, errors.Trace(builder.err)
 	}
 
 	// Maybe it's better to move this to Preprocess, but check privilege need table
 	// information, which is collected into visitInfo during logical plan builder.
 	if pm := privilege.GetPrivilegeManager(ctx); pm != nil {
 		if !checkPrivilege(pm, builder.visitInfo) {
-			return nil, errors.New("privilege check fail")
+			return nil, nil, errors.New("privilege check fail")
 		}
 	}
 
 	if logic, ok := p.(LogicalPlan); ok {
-		return doOptimize(builder.optFlag, logic, ctx, allocator)
+		physical, err := doOptimize(builder.optFlag, logic, ctx, allocator)
+		return physical, builder.visitInfo, errors.Trace(err)
 	}
-	return p, nil
+	return p, builder.visitInfo, nil
 }
 
 // BuildLogicalPlan is exported and only used for test.
@@ -94,10 +100,11 @@ func BuildLogicalPlan(ctx context.Context, node ast.Node, is infoschema.InfoSche
 		return nil, errors.Trace(err)
 	}
 	builder := &planBuilder{
-		ctx:       ctx,
-		is:        is,
-		colMapper: make(map[*ast.ColumnNameExpr]int),
-		allocator: new(idAllocator),
+		ctx:          ctx,
+		is:           is,
+		colMapper:    make(map[*ast.ColumnNameExpr]int),
+		windowMapper: make(map[*ast.WindowFuncExpr]int),
+		allocator:    new(idAllocator),
 	}
 	p := builder.build(node)
 	if builder.err != nil {
@@ -108,6 +115,12 @@ func BuildLogicalPlan(ctx context.Context, node ast.Node, is infoschema.InfoSche
 
 func checkPrivilege(pm privilege.Manager, vs []visitInfo) bool {
 	for _, v := range vs {
+		if v.user != nil {
+			if !pm.RequestVerificationWithUser(v.db, v.table, v.column, v.privilege, v.user) {
+				return false
+			}
+			continue
+		}
 		if !pm.RequestVerification(v.db, v.table, v.column, v.privilege) {
 			return false
 		}
@@ -120,8 +133,8 @@ func doOptimize(flag uint64, logic LogicalPlan, ctx context.Context, allocator *
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	if !AllowCartesianProduct && existsCartesianProduct(logic) {
-		return nil, errors.Trace(ErrCartesianProductUnsupported)
+	if err = checkCartesianProductRows(logic, ctx); err != nil {
+		return nil, errors.Trace(err)
 	}
 	var physical PhysicalPlan
 	if UseDAGPlanBuilder(ctx) {
@@ -179,9 +192,16 @@ func physicalOptimize(flag uint64, logic LogicalPlan, allocator *idAllocator) (P
 	return p, nil
 }
 
+func isCartesianProduct(join *LogicalJoin) bool {
+	if len(join.EqualConditions) > 0 {
+		return false
+	}
+	return join.JoinType == InnerJoin || join.JoinType == LeftOuterJoin || join.JoinType == RightOuterJoin
+}
+
 func existsCartesianProduct(p LogicalPlan) bool {
 	if join, ok := p.(*LogicalJoin); ok && len(join.EqualConditions) == 0 {
-		return join.JoinType == InnerJoin || join.JoinType == LeftOuterJoin || join.JoinType == RightOuterJoin
+		return isCartesianProduct(join)
 	}
 	for _, child := range p.Children() {
 		if existsCartesianProduct(child.(LogicalPlan)) {
@@ -191,6 +211,29 @@ func existsCartesianProduct(p LogicalPlan) bool {
 	return false
 }
 
+// checkCartesianProductRows returns an error if the estimated row count of any cartesian product in the plan
+// exceeds the limit set by tidb_max_cartesian_product_rows.
+func checkCartesianProductRows(logic LogicalPlan, ctx context.Context) error {
+	limit := ctx.GetSessionVars().MaxCartesianProductRows
+	if limit <= 0 || !existsCartesianProduct(logic) {
+		return nil
+	}
+	logic.prepareStatsProfile()
+	return errors.Trace(checkCartesianProductCount(logic, limit))
+}
+
+func checkCartesianProductCount(p LogicalPlan, limit int64) error {
+	if join, ok := p.(*LogicalJoin); ok && isCartesianProduct(join) && join.profile.count > float64(limit) {
+		return ErrCartesianProductRowsExceeded.GenByArgs(join.profile.count, limit)
+	}
+	for _, child := range p.Children() {
+		if err := checkCartesianProductCount(child.(LogicalPlan), limit); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // PrepareStmt prepares a raw statement parsed from parser.
 // The statement must be prepared before it can be passed to optimize function.
 // We pass InfoSchema instead of getting from Context in case it is changed after resolving name.
@@ -212,18 +255,50 @@ const (
 	CodeInvalidGroupFuncUse terror.ErrCode = 5
 	CodeIllegalReference    terror.ErrCode = 6
 
+	CodeCartesianProductRowsExceeded terror.ErrCode = 7
+
 	// MySQL error code.
-	CodeNoDB terror.ErrCode = mysql.ErrNoDB
+	CodeNoDB                                  terror.ErrCode = mysql.ErrNoDB
+	CodeWindowFrameStartIllegal               terror.ErrCode = mysql.ErrWindowFrameStartIllegal
+	CodeWindowFrameEndIllegal                 terror.ErrCode = mysql.ErrWindowFrameEndIllegal
+	CodeWindowFrameIllegal                    terror.ErrCode = mysql.ErrWindowFrameIllegal
+	CodeWindowRangeFrameOrderType             terror.ErrCode = mysql.ErrWindowRangeFrameOrderType
+	CodeWindowInvalidWindowFuncUse            terror.ErrCode = mysql.ErrWindowInvalidWindowFuncUse
+	CodeNonuniqTable                          terror.ErrCode = mysql.ErrNonuniqTable
+	CodeViewWrongList                         terror.ErrCode = mysql.ErrViewWrongList
+	CodeCTERecursiveRequiresUnion             terror.ErrCode = mysql.ErrCTERecursiveRequiresUnion
+	CodeCTERecursiveRequiresNonRecursiveFirst terror.ErrCode = mysql.ErrCTERecursiveRequiresNonRecursiveFirst
+	CodeCTERecursiveForbidsAggregation        terror.ErrCode = mysql.ErrCTERecursiveForbidsAggregation
+	CodeViewInvalid                           terror.ErrCode = mysql.ErrViewInvalid
+	CodeViewRecursive                         terror.ErrCode = mysql.ErrViewRecursive
+	CodeNonUpdatableTable                     terror.ErrCode = mysql.ErrNonUpdatableTable
+	CodeNonInsertableTable                    terror.ErrCode = mysql.ErrNonInsertableTable
+	CodeDupFieldName                          terror.ErrCode = mysql.ErrDupFieldName
 )
 
 // Optimizer base errors.
 var (
-	ErrOperandColumns              = terror.ClassOptimizer.New(CodeOperandColumns, "Operand should contain %d column(s)")
-	ErrInvalidWildCard             = terror.ClassOptimizer.New(CodeInvalidWildCard, "Wildcard fields without any table name appears in wrong place")
-	ErrCartesianProductUnsupported = terror.ClassOptimizer.New(CodeUnsupported, "Cartesian product is unsupported")
-	ErrInvalidGroupFuncUse         = terror.ClassOptimizer.New(CodeInvalidGroupFuncUse, "Invalid use of group function")
-	ErrIllegalReference            = terror.ClassOptimizer.New(CodeIllegalReference, "Illegal reference")
-	ErrNoDB                        = terror.ClassOptimizer.New(CodeNoDB, "No database selected")
+	ErrOperandColumns                        = terror.ClassOptimizer.New(CodeOperandColumns, "Operand should contain %d column(s)")
+	ErrInvalidWildCard                       = terror.ClassOptimizer.New(CodeInvalidWildCard, "Wildcard fields without any table name appears in wrong place")
+	ErrCartesianProductRowsExceeded          = terror.ClassOptimizer.New(CodeCartesianProductRowsExceeded, "The estimated row count %.0f of cartesian product exceeds tidb_max_cartesian_product_rows %d")
+	ErrInvalidGroupFuncUse                   = terror.ClassOptimizer.New(CodeInvalidGroupFuncUse, "Invalid use of group function")
+	ErrIllegalReference                      = terror.ClassOptimizer.New(CodeIllegalReference, "Illegal reference")
+	ErrNoDB                                  = terror.ClassOptimizer.New(CodeNoDB, "No database selected")
+	ErrWindowFrameStartIllegal               = terror.ClassOptimizer.New(CodeWindowFrameStartIllegal, mysql.MySQLErrName[mysql.ErrWindowFrameStartIllegal])
+	ErrWindowFrameEndIllegal                 = terror.ClassOptimizer.New(CodeWindowFrameEndIllegal, mysql.MySQLErrName[mysql.ErrWindowFrameEndIllegal])
+	ErrWindowFrameIllegal                    = terror.ClassOptimizer.New(CodeWindowFrameIllegal, mysql.MySQLErrName[mysql.ErrWindowFrameIllegal])
+	ErrWindowRangeFrameOrderType             = terror.ClassOptimizer.New(CodeWindowRangeFrameOrderType, mysql.MySQLErrName[mysql.ErrWindowRangeFrameOrderType])
+	ErrWindowInvalidWindowFuncUse            = terror.ClassOptimizer.New(CodeWindowInvalidWindowFuncUse, mysql.MySQLErrName[mysql.ErrWindowInvalidWindowFuncUse])
+	ErrNonuniqTable                          = terror.ClassOptimizer.New(CodeNonuniqTable, mysql.MySQLErrName[mysql.ErrNonuniqTable])
+	ErrViewWrongList                         = terror.ClassOptimizer.New(CodeViewWrongList, mysql.MySQLErrName[mysql.ErrViewWrongList])
+	ErrCTERecursiveRequiresUnion             = terror.ClassOptimizer.New(CodeCTERecursiveRequiresUnion, mysql.MySQLErrName[mysql.ErrCTERecursiveRequiresUnion])
+	ErrCTERecursiveRequiresNonRecursiveFirst = terror.ClassOptimizer.New(CodeCTERecursiveRequiresNonRecursiveFirst, mysql.MySQLErrName[mysql.ErrCTERecursiveRequiresNonRecursiveFirst])
+	ErrCTERecursiveForbidsAggregation        = terror.ClassOptimizer.New(CodeCTERecursiveForbidsAggregation, mysql.MySQLErrName[mysql.ErrCTERecursiveForbidsAggregation])
+	ErrViewInvalid                           = terror.ClassOptimizer.New(CodeViewInvalid, mysql.MySQLErrName[mysql.ErrViewInvalid])
+	ErrViewRecursive                         = terror.ClassOptimizer.New(CodeViewRecursive, mysql.MySQLErrName[mysql.ErrViewRecursive])
+	ErrNonUpdatableTable                     = terror.ClassOptimizer.New(CodeNonUpdatableTable, mysql.MySQLErrName[mysql.ErrNonUpdatableTable])
+	ErrNonInsertableTable                    = terror.ClassOptimizer.New(CodeNonInsertableTable, mysql.MySQLErrName[mysql.ErrNonInsertableTable])
+	ErrDupFieldName                          = terror.ClassOptimizer.New(CodeDupFieldName, mysql.MySQLErrName[mysql.ErrDupFieldName])
 )
 
 func init() {
@@ -233,7 +308,25 @@ func init() {
 		CodeInvalidGroupFuncUse: mysql.ErrInvalidGroupFuncUse,
 		CodeIllegalReference:    mysql.ErrIllegalReference,
 		CodeNoDB:                mysql.ErrNoDB,
+
+		CodeWindowFrameStartIllegal:    mysql.ErrWindowFrameStartIllegal,
+		CodeWindowFrameEndIllegal:      mysql.ErrWindowFrameEndIllegal,
+		CodeWindowFrameIllegal:         mysql.ErrWindowFrameIllegal,
+		CodeWindowRangeFrameOrderType:  mysql.ErrWindowRangeFrameOrderType,
+		CodeWindowInvalidWindowFuncUse: mysql.ErrWindowInvalidWindowFuncUse,
+
+		CodeNonuniqTable:                          mysql.ErrNonuniqTable,
+		CodeViewWrongList:                         mysql.ErrViewWrongList,
+		CodeCTERecursiveRequiresUnion:             mysql.ErrCTERecursiveRequiresUnion,
+		CodeCTERecursiveRequiresNonRecursiveFirst: mysql.ErrCTERecursiveRequiresNonRecursiveFirst,
+		CodeCTERecursiveForbidsAggregation:        mysql.ErrCTERecursiveForbidsAggregation,
+		CodeViewInvalid:                           mysql.ErrViewInvalid,
+		CodeViewRecursive:                         mysql.ErrViewRecursive,
+		CodeNonUpdatableTable:                     mysql.ErrNonUpdatableTable,
+		CodeNonInsertableTable:                    mysql.ErrNonInsertableTable,
+		CodeDupFieldName:                          mysql.ErrDupFieldName,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassOptimizer] = mySQLErrCodes
 	expression.EvalAstExpr = evalAstExpr
+	expression.RewriteAstExpr = rewriteAstExpr
 }
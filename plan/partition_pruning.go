@@ -0,0 +1,350 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/ranger"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// partitionProcessor rewrites the DataSource of a partitioned table to a Union of the DataSources of its partitions.
+// The partitions which can't match the conditions are pruned if the partition expression is an integer column, or a
+// monotonic function of a column, such as YEAR(date_col).
+type partitionProcessor struct{}
+
+func (s *partitionProcessor) optimize(lp LogicalPlan, _ context.Context, _ *idAllocator) (LogicalPlan, error) {
+	return s.rewriteDataSource(lp, nil)
+}
+
+func (s *partitionProcessor) rewriteDataSource(p LogicalPlan, conds []expression.Expression) (LogicalPlan, error) {
+	if ds, ok := p.(*DataSource); ok {
+		if ds.tableInfo.Partition == nil {
+			return ds, nil
+		}
+		return s.processDataSource(ds, conds)
+	}
+	if sel, ok := p.(*Selection); ok {
+		// The conditions which are not pushed down to the DataSource can prune the partitions too.
+		conds = sel.Conditions
+	} else {
+		conds = nil
+	}
+	newChildren := make([]Plan, 0, len(p.Children()))
+	for _, child := range p.Children() {
+		newChild, err := s.rewriteDataSource(child.(LogicalPlan), conds)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		newChild.SetParents(p)
+		newChildren = append(newChildren, newChild)
+	}
+	p.SetChildren(newChildren...)
+	if sel, ok := p.(*Selection); ok {
+		if _, ok := sel.children[0].(*DataSource); !ok {
+			// The scan controller only works on a DataSource.
+			sel.controllerStatus = notController
+		}
+	}
+	return p, nil
+}
+
+func (s *partitionProcessor) processDataSource(ds *DataSource, conds []expression.Expression) (LogicalPlan, error) {
+	pi := ds.tableInfo.Partition
+	conds = append(conds, ds.pushedDownConds...)
+	used, err := s.prunePartitions(ds, pi, conds)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	usedIDs := make([]int64, 0, len(pi.Definitions))
+	for i, def := range pi.Definitions {
+		if used[i] {
+			usedIDs = append(usedIDs, def.ID)
+		}
+	}
+	if len(usedIDs) == 0 {
+		dual := TableDual{}.init(ds.allocator, ds.ctx)
+		dual.SetSchema(ds.Schema())
+		return dual, nil
+	}
+	if len(usedIDs) == 1 {
+		// The DataSource reads the only partition directly, so the columns keep their identities.
+		ds.physicalTableID = usedIDs[0]
+		return ds, nil
+	}
+	children := make([]Plan, 0, len(usedIDs))
+	for _, id := range usedIDs {
+		children = append(children, s.buildPartitionDataSource(ds, id))
+	}
+	union := Union{}.init(ds.allocator, ds.ctx)
+	// The Union keeps the schema of the DataSource, so the parent plans and the handle columns don't change.
+	union.SetSchema(ds.Schema())
+	for _, child := range children {
+		child.SetParents(union)
+	}
+	union.SetChildren(children...)
+	return union, nil
+}
+
+// buildPartitionDataSource copies the DataSource for the partition.
+func (s *partitionProcessor) buildPartitionDataSource(ds *DataSource, physicalID int64) *DataSource {
+	newDS := DataSource{
+		indexHints:      ds.indexHints,
+		tableInfo:       ds.tableInfo,
+		Columns:         ds.Columns,
+		DBName:          ds.DBName,
+		TableAsName:     ds.TableAsName,
+		LimitCount:      ds.LimitCount,
+		statisticTable:  ds.statisticTable,
+		NeedColHandle:   ds.NeedColHandle,
+		physicalTableID: physicalID,
+	}.init(ds.allocator, ds.ctx)
+	schema := ds.schema.Clone()
+	for _, col := range schema.Columns {
+		col.FromID = newDS.id
+	}
+	newDS.SetSchema(schema)
+	if ds.unionScanSchema != nil {
+		newDS.unionScanSchema = ds.unionScanSchema.Clone()
+		for _, col := range newDS.unionScanSchema.Columns {
+			col.FromID = newDS.id
+		}
+	}
+	newExprs := expression.Column2Exprs(schema.Columns)
+	newDS.pushedDownConds = make([]expression.Expression, 0, len(ds.pushedDownConds))
+	for _, cond := range ds.pushedDownConds {
+		newDS.pushedDownConds = append(newDS.pushedDownConds, expression.ColumnSubstitute(cond, ds.schema, newExprs))
+	}
+	for i, col := range ds.virtualGenCols {
+		newCol := col.Clone().(*expression.Column)
+		newCol.FromID = newDS.id
+		newDS.virtualGenCols = append(newDS.virtualGenCols, newCol)
+		newDS.virtualGenExprs = append(newDS.virtualGenExprs, expression.ColumnSubstitute(ds.virtualGenExprs[i], ds.schema, newExprs))
+	}
+	for _, cond := range ds.virtualGenConds {
+		newDS.virtualGenConds = append(newDS.virtualGenConds, expression.ColumnSubstitute(cond, ds.schema, newExprs))
+	}
+	return newDS
+}
+
+// prunePartitions returns whether each partition may contain the rows matching the conditions.
+func (s *partitionProcessor) prunePartitions(ds *DataSource, pi *model.PartitionInfo, conds []expression.Expression) ([]bool, error) {
+	used := make([]bool, len(pi.Definitions))
+	col, funcName, err := s.findPartitionColumn(ds, pi)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if col == nil || len(conds) == 0 {
+		for i := range used {
+			used[i] = true
+		}
+		return used, nil
+	}
+	var intRanges []types.IntColumnRange
+	sc := ds.ctx.GetSessionVars().StmtCtx
+	if funcName == "" {
+		ranges, _, _, err := ranger.BuildRange(sc, conds, ranger.IntRangeType, []*expression.Column{col}, nil)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		intRanges = ranger.Ranges2IntRanges(ranges)
+	} else {
+		ranges, _, _, err := ranger.BuildRange(sc, conds, ranger.ColumnRangeType, []*expression.Column{col}, nil)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		intRanges = make([]types.IntColumnRange, 0, len(ranges))
+		for _, ran := range ranger.Ranges2ColumnRanges(ranges) {
+			intRanges = append(intRanges, s.evalMonotonicRange(ds, funcName, col, ran))
+		}
+	}
+
+	// NULL is stored in the first RANGE or HASH partition, and the NULL range starts from math.MinInt64.
+	nullOffset := 0
+	switch pi.Type {
+	case model.PartitionTypeRange:
+		bounds, maxValue, err := table.RangePartitionBounds(pi)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, ran := range intRanges {
+			for i := range used {
+				low, high := int64(math.MinInt64), int64(math.MaxInt64)
+				if i > 0 {
+					low = bounds[i-1]
+				}
+				if i < len(bounds) {
+					high = bounds[i] - 1
+				} else if !maxValue {
+					continue
+				}
+				if ran.LowVal <= high && ran.HighVal >= low {
+					used[i] = true
+				}
+			}
+		}
+	case model.PartitionTypeList:
+		values, err := table.ListPartitionValues(pi)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		nullOffset = -1
+		for i, vals := range values {
+			for _, val := range vals {
+				if val.IsNull() {
+					nullOffset = i
+					continue
+				}
+				for _, ran := range intRanges {
+					if v := val.GetInt64(); ran.LowVal <= v && v <= ran.HighVal {
+						used[i] = true
+					}
+				}
+			}
+		}
+	default:
+		num := len(pi.Definitions)
+		for _, ran := range intRanges {
+			if uint64(ran.HighVal-ran.LowVal) >= uint64(num) || ran.HighVal < ran.LowVal {
+				for i := range used {
+					used[i] = true
+				}
+				break
+			}
+			for v := ran.LowVal; ; v++ {
+				used[table.HashPartitionOffset(v, num)] = true
+				if v == ran.HighVal {
+					break
+				}
+			}
+		}
+	}
+	if nullOffset >= 0 {
+		for _, ran := range intRanges {
+			if ran.LowVal == math.MinInt64 {
+				used[nullOffset] = true
+			}
+		}
+	}
+	return used, nil
+}
+
+// monotonicPartitionFuncs are the functions whose results never decrease when the argument increases.
+var monotonicPartitionFuncs = map[string]struct{}{
+	ast.Year:          {},
+	ast.ToDays:        {},
+	ast.ToSeconds:     {},
+	ast.UnixTimestamp: {},
+}
+
+// findPartitionColumn returns the column of the DataSource if the partition expression is an integer column, or the
+// column and the function name if the partition expression is a monotonic function of the column.
+func (s *partitionProcessor) findPartitionColumn(ds *DataSource, pi *model.PartitionInfo) (*expression.Column, string, error) {
+	stmts, err := parser.New().Parse(fmt.Sprintf("select %s", pi.Expr), "", "")
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	var (
+		name     string
+		funcName string
+	)
+	switch expr := stmts[0].(*ast.SelectStmt).Fields.Fields[0].Expr.(type) {
+	case *ast.ColumnNameExpr:
+		name = expr.Name.Name.L
+	case *ast.FuncCallExpr:
+		if _, ok := monotonicPartitionFuncs[expr.FnName.L]; !ok || len(expr.Args) != 1 {
+			return nil, "", nil
+		}
+		arg, ok := expr.Args[0].(*ast.ColumnNameExpr)
+		if !ok {
+			return nil, "", nil
+		}
+		name, funcName = arg.Name.Name.L, expr.FnName.L
+	default:
+		return nil, "", nil
+	}
+	for _, col := range ds.schema.Columns {
+		if col.ColName.L != name {
+			continue
+		}
+		if funcName != "" {
+			switch col.RetType.Tp {
+			case mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp:
+				return col, funcName, nil
+			}
+			return nil, "", nil
+		}
+		if mysql.HasUnsignedFlag(col.RetType.Flag) {
+			return nil, "", nil
+		}
+		switch col.RetType.Tp {
+		case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
+			return col, "", nil
+		}
+		return nil, "", nil
+	}
+	return nil, "", nil
+}
+
+// evalMonotonicRange converts the range of the column to the range of the monotonic partition function by evaluating
+// the function on the bounds. The result may be larger than the exact range, it only needs to cover it. A range
+// including NULL starts from math.MinInt64, the same as the ranges of an integer column.
+func (s *partitionProcessor) evalMonotonicRange(ds *DataSource, funcName string, col *expression.Column, ran *types.ColumnRange) types.IntColumnRange {
+	intRange := types.IntColumnRange{LowVal: math.MinInt64, HighVal: math.MaxInt64}
+	switch ran.Low.Kind() {
+	case types.KindNull:
+	case types.KindMinNotNull:
+		intRange.LowVal = math.MinInt64 + 1
+	default:
+		if v, ok := s.evalMonotonicFunc(ds, funcName, col, ran.Low); ok {
+			intRange.LowVal = v
+		}
+	}
+	if ran.High.Kind() != types.KindMaxValue {
+		if v, ok := s.evalMonotonicFunc(ds, funcName, col, ran.High); ok {
+			intRange.HighVal = v
+		}
+	}
+	return intRange
+}
+
+// evalMonotonicFunc evaluates the monotonic partition function on a value of the column, it returns false if the
+// result isn't an integer.
+func (s *partitionProcessor) evalMonotonicFunc(ds *DataSource, funcName string, col *expression.Column, d types.Datum) (int64, bool) {
+	arg := &expression.Constant{Value: d, RetType: col.RetType}
+	f, err := expression.NewFunction(ds.ctx, funcName, types.NewFieldType(mysql.TypeLonglong), arg)
+	if err != nil {
+		return 0, false
+	}
+	v, err := f.Eval(nil)
+	if err != nil || v.IsNull() {
+		return 0, false
+	}
+	i, err := v.ToInt64(ds.ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return 0, false
+	}
+	return i, true
+}
@@ -39,6 +39,9 @@ const (
 	cpuFactor          = 0.9
 	aggFactor          = 0.1
 	joinFactor         = 0.3
+
+	// crossJoinBroadcastThreshold is the max estimated row count of the inner side for the broadcast cross join.
+	crossJoinBroadcastThreshold = 10000
 )
 
 // JoinConcurrency means the number of goroutines that participate in joining.
@@ -47,9 +50,10 @@ var JoinConcurrency = 5
 func (p *DataSource) convert2TableScan(prop *requiredProperty) (*physicalPlanInfo, error) {
 	client := p.ctx.GetClient()
 	ts := PhysicalTableScan{
-		Table:   p.tableInfo,
-		Columns: p.Columns,
-		DBName:  p.DBName,
+		Table:           p.tableInfo,
+		PhysicalTableID: p.physicalTableID,
+		Columns:         p.Columns,
+		DBName:          p.DBName,
 		physicalTableSource: physicalTableSource{
 			client:          client,
 			NeedColHandle:   p.NeedColHandle,
@@ -114,11 +118,12 @@ func (p *DataSource) convert2TableScan(prop *requiredProperty) (*physicalPlanInf
 func (p *DataSource) convert2IndexScan(prop *requiredProperty, index *model.IndexInfo) (*physicalPlanInfo, error) {
 	client := p.ctx.GetClient()
 	is := PhysicalIndexScan{
-		Index:      index,
-		Table:      p.tableInfo,
-		Columns:    p.Columns,
-		OutOfOrder: true,
-		DBName:     p.DBName,
+		Index:           index,
+		Table:           p.tableInfo,
+		PhysicalTableID: p.physicalTableID,
+		Columns:         p.Columns,
+		OutOfOrder:      true,
+		DBName:          p.DBName,
 		physicalTableSource: physicalTableSource{
 			client:          client,
 			NeedColHandle:   p.NeedColHandle,
@@ -957,6 +962,73 @@ func (p *LogicalJoin) hasEqualConds() bool {
 	return true
 }
 
+// convert2PhysicalCrossJoin converts the join without equal conditions to the block nested-loop join
+// with the lowest cost. The sort property can only be pushed to the outer child of a join which is not broadcast.
+func (p *LogicalJoin) convert2PhysicalCrossJoin(prop *requiredProperty) (*physicalPlanInfo, error) {
+	var bestInfo *physicalPlanInfo
+	for innerIdx := 0; innerIdx < 2; innerIdx++ {
+		if (innerIdx == 0 && p.JoinType == LeftOuterJoin) || (innerIdx == 1 && p.JoinType == RightOuterJoin) {
+			continue
+		}
+		outerChild := p.children[1-innerIdx].(LogicalPlan)
+		innerChild := p.children[innerIdx].(LogicalPlan)
+		allOuter := true
+		for _, col := range prop.props {
+			if !outerChild.Schema().Contains(col.col) {
+				allOuter = false
+			}
+		}
+		innerInfo, err := innerChild.convert2PhysicalPlan(&requiredProperty{})
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, broadcast := range []bool{false, true} {
+			if broadcast && innerInfo.count > crossJoinBroadcastThreshold {
+				continue
+			}
+			outerProp := &requiredProperty{}
+			if allOuter && !broadcast {
+				outerProp = replaceColsInPropBySchema(prop, outerChild.Schema())
+			}
+			if p.JoinType == InnerJoin {
+				outerProp = removeLimit(outerProp)
+			} else {
+				outerProp = convertLimitOffsetToCount(outerProp)
+			}
+			outerInfo, err := outerChild.convert2PhysicalPlan(outerProp)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			join := PhysicalCrossJoin{
+				JoinType:        p.JoinType,
+				LeftConditions:  p.LeftConditions,
+				RightConditions: p.RightConditions,
+				OtherConditions: p.OtherConditions,
+				InnerChildIdx:   innerIdx,
+				Broadcast:       broadcast,
+				Concurrency:     JoinConcurrency,
+				DefaultValues:   p.DefaultValues,
+			}.init(p.allocator, p.ctx)
+			join.SetSchema(p.schema)
+			var info *physicalPlanInfo
+			if innerIdx == 1 {
+				info = join.matchProperty(prop, outerInfo, innerInfo)
+			} else {
+				info = join.matchProperty(prop, innerInfo, outerInfo)
+			}
+			if allOuter && !broadcast {
+				info = enforceProperty(limitProperty(prop.limit), info)
+			} else {
+				info = enforceProperty(prop, info)
+			}
+			if bestInfo == nil || info.cost < bestInfo.cost {
+				bestInfo = info
+			}
+		}
+	}
+	return bestInfo, nil
+}
+
 // convert2PhysicalPlan implements the LogicalPlan convert2PhysicalPlan interface.
 func (p *LogicalJoin) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo, error) {
 	info, err := p.getPlanInfo(prop)
@@ -966,6 +1038,14 @@ func (p *LogicalJoin) convert2PhysicalPlan(prop *requiredProperty) (*physicalPla
 	if info != nil {
 		return info, nil
 	}
+	if !p.hasEqualConds() && p.JoinType != SemiJoin && p.JoinType != LeftOuterSemiJoin {
+		info, err = p.convert2PhysicalCrossJoin(prop)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		p.storePlanInfo(prop, info)
+		return info, nil
+	}
 	switch p.JoinType {
 	case SemiJoin, LeftOuterSemiJoin:
 		info, err = p.convert2PhysicalPlanSemi(prop)
@@ -1200,6 +1280,40 @@ func (p *LogicalAggregation) convert2PhysicalPlan(prop *requiredProperty) (*phys
 	return planInfo, errors.Trace(err)
 }
 
+// convert2PhysicalPlan implements the LogicalPlan convert2PhysicalPlan interface.
+func (p *LogicalWindow) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo, error) {
+	info, err := p.getPlanInfo(prop)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if info != nil {
+		return info, nil
+	}
+	info, err = p.children[0].(LogicalPlan).convert2PhysicalPlan(&requiredProperty{})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	info = addPlanToResponse(p.newPhysicalWindow(), info)
+	info.cost += info.count * cpuFactor
+	info = enforceProperty(prop, info)
+	return info, p.storePlanInfo(prop, info)
+}
+
+// convert2PhysicalPlan implements the LogicalPlan convert2PhysicalPlan interface.
+func (p *LogicalCTE) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo, error) {
+	info, err := p.getPlanInfo(prop)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if info != nil {
+		return info, nil
+	}
+	cte := PhysicalCTE{CTE: p.CTE}.init(p.allocator, p.ctx)
+	cte.SetSchema(p.schema)
+	info = enforceProperty(prop, &physicalPlanInfo{p: cte})
+	return info, p.storePlanInfo(prop, info)
+}
+
 // convert2PhysicalPlan implements the LogicalPlan convert2PhysicalPlan interface.
 func (p *Union) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo, error) {
 	info, err := p.getPlanInfo(prop)
@@ -1258,6 +1372,7 @@ func (p *Selection) makeScanController() *physicalPlanInfo {
 	if p.controllerStatus == controlTableScan {
 		ts := PhysicalTableScan{
 			Table:               ds.tableInfo,
+			PhysicalTableID:     ds.physicalTableID,
 			Columns:             ds.Columns,
 			DBName:              ds.DBName,
 			physicalTableSource: physicalTableSource{client: ds.ctx.GetClient()},
@@ -1283,6 +1398,7 @@ func (p *Selection) makeScanController() *physicalPlanInfo {
 			if chosenPlan == nil || bestEqualCount < accessEqualCount {
 				is := PhysicalIndexScan{
 					Table:               ds.tableInfo,
+					PhysicalTableID:     ds.physicalTableID,
 					Index:               idx,
 					Columns:             ds.Columns,
 					OutOfOrder:          true,
@@ -429,11 +429,11 @@ func (s *testPlanSuite) TestCBO(c *C) {
 		},
 		{
 			sql:  "select * from t t1 left outer join t t2 on true where least(1,2,3,t1.a,t2.b) > 0 order by t2.a limit 10",
-			best: "LeftHashJoin{Table(t)->Table(t)}->Selection->Sort + Limit(10) + Offset(0)",
+			best: "BroadcastLeftCrossJoin{Table(t)->Table(t)}->Selection->Sort + Limit(10) + Offset(0)",
 		},
 		{
 			sql:  "select * from t t1 left outer join t t2 on true where least(1,2,3,t1.a,t2.b) > 0 limit 10",
-			best: "LeftHashJoin{Table(t)->Table(t)}->Selection->Limit",
+			best: "BroadcastLeftCrossJoin{Table(t)->Table(t)}->Selection->Limit",
 		},
 		{
 			sql:  "select count(*) from t where concat(a,b) = 'abc' group by c",
@@ -478,7 +478,7 @@ func (s *testPlanSuite) TestCBO(c *C) {
 		},
 		{
 			sql:  "select * from t t1, t t2 right join t t3 on t2.a = t3.b order by t1.a, t1.b, t2.a, t2.b, t3.a, t3.b",
-			best: "RightHashJoin{Table(t)->RightHashJoin{Table(t)->Table(t)}(t2.a,t3.b)}->Sort",
+			best: "BroadcastRightCrossJoin{Table(t)->RightHashJoin{Table(t)->Table(t)}(t2.a,t3.b)}->Sort",
 		},
 		{
 			sql:  "select * from t a where 1 = a.c and a.d > 1 order by a.d desc limit 2",
@@ -495,19 +495,19 @@ func (s *testPlanSuite) TestCBO(c *C) {
 		//},
 		{
 			sql:  "select * from (select * from t) a left outer join (select * from t) b on 1 order by a.c",
-			best: "LeftHashJoin{Index(t.c_d_e)[[<nil>,+inf]]->Table(t)}",
+			best: "LeftCrossJoin{Index(t.c_d_e)[[<nil>,+inf]]->Table(t)}",
 		},
 		{
 			sql:  "select * from (select * from t) a left outer join (select * from t) b on 1 order by b.c",
-			best: "LeftHashJoin{Table(t)->Table(t)}->Sort",
+			best: "BroadcastLeftCrossJoin{Table(t)->Table(t)}->Sort",
 		},
 		{
 			sql:  "select * from (select * from t) a right outer join (select * from t) b on 1 order by a.c",
-			best: "RightHashJoin{Table(t)->Table(t)}->Sort",
+			best: "BroadcastRightCrossJoin{Table(t)->Table(t)}->Sort",
 		},
 		{
 			sql:  "select * from (select * from t) a right outer join (select * from t) b on 1 order by b.c",
-			best: "RightHashJoin{Table(t)->Index(t.c_d_e)[[<nil>,+inf]]}",
+			best: "RightCrossJoin{Table(t)->Index(t.c_d_e)[[<nil>,+inf]]}",
 		},
 		{
 			sql:  "select * from t a where exists(select * from t b where a.a = b.a) and a.c = 1 order by a.d limit 3",
@@ -605,20 +605,20 @@ func (s *testPlanSuite) TestProjectionElimination(c *C) {
 		},
 		{
 			sql: "select t1.a, t2.b from t t1, t t2 where t1.a > 0 and t2.b < 0",
-			ans: "RightHashJoin{Table(t)->Table(t)}",
+			ans: "BroadcastRightCrossJoin{Table(t)->Table(t)}",
 		},
 		{
 			sql: "select t1.a, t1.b, t2.a, t2.b from t t1, t t2 where t1.a > 0 and t2.b < 0",
-			ans: "RightHashJoin{Table(t)->Table(t)}",
+			ans: "BroadcastRightCrossJoin{Table(t)->Table(t)}",
 		},
 		{
 			sql: "select * from (t t1 join t t2) join (t t3 join t t4)",
-			ans: "LeftHashJoin{LeftHashJoin{Table(t)->Table(t)}->LeftHashJoin{Table(t)->Table(t)}}",
+			ans: "RightCrossJoin{BroadcastRightCrossJoin{Table(t)->Table(t)}->BroadcastRightCrossJoin{Table(t)->Table(t)}}",
 		},
 		// projection can not be eliminated in following cases.
 		{
 			sql: "select t1.b, t1.a, t2.b, t2.a from t t1, t t2 where t1.a > 0 and t2.b < 0",
-			ans: "RightHashJoin{Table(t)->Table(t)}->Projection",
+			ans: "BroadcastRightCrossJoin{Table(t)->Table(t)}->Projection",
 		},
 		{
 			sql: "select d, c, b, a from t where a = b and b = 1",
@@ -638,7 +638,7 @@ func (s *testPlanSuite) TestProjectionElimination(c *C) {
 		},
 		{
 			sql: "select t1.a, t2.b, t2.a, t1.b from t t1, t t2 where t1.a > 0 and t2.b < 0",
-			ans: "RightHashJoin{Table(t)->Table(t)}->Projection",
+			ans: "BroadcastRightCrossJoin{Table(t)->Table(t)}->Projection",
 		},
 		{
 			sql: "select t1.a from t t1 where t1.a in (select t2.a from t t2 where t1.a > 1)",
@@ -646,7 +646,7 @@ func (s *testPlanSuite) TestProjectionElimination(c *C) {
 		},
 		{
 			sql: "select t1.a from t t1, (select @a:=0, @b:=0) t2",
-			ans: "LeftHashJoin{Table(t)->Dual->Projection}->Projection",
+			ans: "LeftCrossJoin{Table(t)->Dual->Projection}->Projection",
 		},
 		{
 			sql: "select count(*) from t order by sum(b);",
@@ -923,11 +923,11 @@ func (s *testPlanSuite) TestJoinAlgorithm(c *C) {
 		},
 		{
 			sql: "select /*+ TIDB_SMJ(t1, t2) */ * from t t1 join t t2 on t1.a > t2.a",
-			ans: "LeftHashJoin{Table(t)->Table(t)}",
+			ans: "BroadcastRightCrossJoin{Table(t)->Table(t)}",
 		},
 		{
 			sql: "select /*+ TIDB_INLJ(t1, t2) */ * from t t1 join t t2 on t1.a > t2.a",
-			ans: "LeftHashJoin{Table(t)->Table(t)}",
+			ans: "BroadcastRightCrossJoin{Table(t)->Table(t)}",
 		},
 		{
 			sql: "select /*+ tidb_inlj(t1, t2) */ * from t t1 right outer join t t2 on t1.a = t2.c",
@@ -935,7 +935,7 @@ func (s *testPlanSuite) TestJoinAlgorithm(c *C) {
 		},
 		{
 			sql: "select /*+ tidb_inlj(t1, t2) */ * from t t1 right outer join t t2 on t1.a > t2.c",
-			ans: "RightHashJoin{Table(t)->Table(t)}",
+			ans: "BroadcastRightCrossJoin{Table(t)->Table(t)}",
 		},
 		{
 			sql: "select /*+ tidb_inlj(t1, t2) */ * from t t1 left outer join t t2 on t1.a = t2.e",
@@ -951,7 +951,7 @@ func (s *testPlanSuite) TestJoinAlgorithm(c *C) {
 		},
 		{
 			sql: "select /*+ tidb_inlj(t, tt) */ * from t tt join t on tt.a>t.f",
-			ans: "LeftHashJoin{Table(t)->Table(t)}",
+			ans: "BroadcastRightCrossJoin{Table(t)->Table(t)}",
 		},
 		{
 			sql: "select /*+ tidb_inlj(t2) */ * from t t1 join t t2 on t1.c=t2.c and t1.d=t2.d and t1.e > t2.e",
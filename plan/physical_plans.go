@@ -61,12 +61,17 @@ var (
 	_ PhysicalPlan = &PhysicalTableReader{}
 	_ PhysicalPlan = &PhysicalIndexReader{}
 	_ PhysicalPlan = &PhysicalIndexLookUpReader{}
+	_ PhysicalPlan = &PhysicalIndexMergeReader{}
 	_ PhysicalPlan = &PhysicalAggregation{}
+	_ PhysicalPlan = &PhysicalWindow{}
+	_ PhysicalPlan = &PhysicalCTE{}
+	_ PhysicalPlan = &CTETable{}
 	_ PhysicalPlan = &PhysicalApply{}
 	_ PhysicalPlan = &PhysicalIndexJoin{}
 	_ PhysicalPlan = &PhysicalHashJoin{}
 	_ PhysicalPlan = &PhysicalHashSemiJoin{}
 	_ PhysicalPlan = &PhysicalMergeJoin{}
+	_ PhysicalPlan = &PhysicalCrossJoin{}
 	_ PhysicalPlan = &PhysicalUnionScan{}
 	_ PhysicalPlan = &Cache{}
 )
@@ -140,6 +145,50 @@ func (p *PhysicalIndexLookUpReader) Copy() PhysicalPlan {
 	return &np
 }
 
+// IndexMergeType is the way the index merge reader merges the handles read by its partial index scans.
+type IndexMergeType int
+
+const (
+	// IndexMergeUnion reads the rows whose handles are read by any of the partial index scans.
+	IndexMergeUnion IndexMergeType = iota
+	// IndexMergeIntersection reads the rows whose handles are read by all the partial index scans.
+	IndexMergeIntersection
+)
+
+// String implements the fmt.Stringer interface.
+func (t IndexMergeType) String() string {
+	if t == IndexMergeIntersection {
+		return "intersection"
+	}
+	return "union"
+}
+
+// PhysicalIndexMergeReader is the index merge reader in tidb. It reads the handles by several index scans,
+// merges them by union or intersection, and then looks up the rows of the handles in the table.
+type PhysicalIndexMergeReader struct {
+	*basePlan
+	basePhysicalPlan
+
+	Type IndexMergeType
+	// PartialPlans flats the partialPlans to construct executor pb.
+	PartialPlans [][]PhysicalPlan
+	// TablePlans flats the tablePlan to construct executor pb.
+	TablePlans   []PhysicalPlan
+	partialPlans []PhysicalPlan
+	tablePlan    PhysicalPlan
+
+	// NeedColHandle is used in execution phase.
+	NeedColHandle bool
+}
+
+// Copy implements the PhysicalPlan Copy interface.
+func (p *PhysicalIndexMergeReader) Copy() PhysicalPlan {
+	np := *p
+	np.basePlan = p.basePlan.copy()
+	np.basePhysicalPlan = newBasePhysicalPlan(np.basePlan)
+	return &np
+}
+
 // PhysicalIndexScan represents an index scan plan.
 type PhysicalIndexScan struct {
 	physicalTableSource
@@ -161,10 +210,17 @@ type PhysicalIndexScan struct {
 	accessEqualCount int
 
 	TableAsName *model.CIStr
+	// PhysicalTableID is the ID of the partition to read, or the table ID if the table isn't partitioned.
+	PhysicalTableID int64
 
 	// dataSourceSchema is the original schema of DataSource. The schema of index scan in KV and index reader in TiDB
 	// will be different. The schema of index scan will decode all columns of index but the TiDB only need some of them.
 	dataSourceSchema *expression.Schema
+
+	// idxCols and idxColLens are the columns and lengths that the ranges are built on,
+	// they are used to rebuild the ranges when a cached plan is executed again.
+	idxCols    []*expression.Column
+	idxColLens []int
 }
 
 // PhysicalMemTable reads memory table.
@@ -458,6 +514,8 @@ type PhysicalTableScan struct {
 	pkCol   *expression.Column
 
 	TableAsName *model.CIStr
+	// PhysicalTableID is the ID of the partition to read, or the table ID if the table isn't partitioned.
+	PhysicalTableID int64
 
 	// KeepOrder is true, if sort data by scanning pkcol,
 	KeepOrder bool
@@ -529,6 +587,29 @@ type PhysicalMergeJoin struct {
 	rightKeys []*expression.Column
 }
 
+// PhysicalCrossJoin represents the block nested-loop join for the inner/ outer join without equal conditions.
+// The rows of the inner child are cached, then every block of the outer rows is joined with all the cached rows.
+type PhysicalCrossJoin struct {
+	*basePlan
+	basePhysicalPlan
+
+	JoinType JoinType
+
+	LeftConditions  []expression.Expression
+	RightConditions []expression.Expression
+	OtherConditions []expression.Expression
+	// InnerChildIdx is the index of the child whose rows are cached.
+	InnerChildIdx int
+	// Broadcast means the cached inner rows are broadcast to several workers,
+	// each of which joins a part of the outer blocks. The order of the outer rows is not kept.
+	Broadcast   bool
+	Concurrency int
+
+	DefaultValues []types.Datum
+
+	outerSchema *expression.Schema
+}
+
 // PhysicalHashSemiJoin represents hash join for semi join.
 type PhysicalHashSemiJoin struct {
 	*basePlan
@@ -581,6 +662,25 @@ type PhysicalAggregation struct {
 	GroupByItems []expression.Expression
 }
 
+// PhysicalCTE is the physical operator of LogicalCTE.
+type PhysicalCTE struct {
+	*basePlan
+	basePhysicalPlan
+
+	CTE *CTEDefinition
+}
+
+// PhysicalWindow is the physical operator of LogicalWindow.
+type PhysicalWindow struct {
+	*basePlan
+	basePhysicalPlan
+
+	WindowFunc  *WindowFuncDesc
+	PartitionBy []*ByItems
+	OrderBy     []*ByItems
+	Frame       *WindowFrame
+}
+
 // PhysicalUnionScan represents a union scan operator.
 type PhysicalUnionScan struct {
 	*basePlan
@@ -679,6 +779,20 @@ func (p *PhysicalApply) extractCorrelatedCols() []*expression.CorrelatedColumn {
 	return corCols
 }
 
+func (p *PhysicalWindow) extractCorrelatedCols() []*expression.CorrelatedColumn {
+	corCols := p.basePlan.extractCorrelatedCols()
+	for _, arg := range p.WindowFunc.Args {
+		corCols = append(corCols, extractCorColumns(arg)...)
+	}
+	for _, item := range p.PartitionBy {
+		corCols = append(corCols, extractCorColumns(item.Expr)...)
+	}
+	for _, item := range p.OrderBy {
+		corCols = append(corCols, extractCorColumns(item.Expr)...)
+	}
+	return corCols
+}
+
 func (p *PhysicalAggregation) extractCorrelatedCols() []*expression.CorrelatedColumn {
 	corCols := p.basePlan.extractCorrelatedCols()
 	for _, expr := range p.GroupByItems {
@@ -866,6 +980,44 @@ func (p *PhysicalMergeJoin) Copy() PhysicalPlan {
 	return &np
 }
 
+// Copy implements the PhysicalPlan Copy interface.
+func (p *PhysicalCrossJoin) Copy() PhysicalPlan {
+	np := *p
+	np.basePlan = p.basePlan.copy()
+	np.basePhysicalPlan = newBasePhysicalPlan(np.basePlan)
+	return &np
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (p *PhysicalCrossJoin) MarshalJSON() ([]byte, error) {
+	leftChild := p.children[0].(PhysicalPlan)
+	rightChild := p.children[1].(PhysicalPlan)
+	leftConds, err := json.Marshal(p.LeftConditions)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	rightConds, err := json.Marshal(p.RightConditions)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	otherConds, err := json.Marshal(p.OtherConditions)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	buffer := bytes.NewBufferString("{")
+	buffer.WriteString(fmt.Sprintf(
+		"\"leftCond\": %s,\n "+
+			"\"rightCond\": %s,\n "+
+			"\"otherCond\": %s,\n"+
+			"\"leftPlan\": \"%s\",\n "+
+			"\"rightPlan\": \"%s\",\n"+
+			"\"innerChild\": %d,\n"+
+			"\"broadcast\": %v"+
+			"}",
+		leftConds, rightConds, otherConds, leftChild.ID(), rightChild.ID(), p.InnerChildIdx, p.Broadcast))
+	return buffer.Bytes(), nil
+}
+
 // MarshalJSON implements json.Marshaler interface.
 func (p *PhysicalHashJoin) MarshalJSON() ([]byte, error) {
 	leftChild := p.children[0].(PhysicalPlan)
@@ -1073,6 +1225,23 @@ func (p *Sort) MarshalJSON() ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
+// Copy implements the PhysicalPlan Copy interface.
+func (p *PhysicalCTE) Copy() PhysicalPlan {
+	np := *p
+	np.basePlan = p.basePlan.copy()
+	np.basePhysicalPlan = newBasePhysicalPlan(np.basePlan)
+	return &np
+}
+
+// Copy implements the PhysicalPlan Copy interface.
+func (p *CTETable) Copy() PhysicalPlan {
+	np := *p
+	np.basePlan = p.basePlan.copy()
+	np.baseLogicalPlan = newBaseLogicalPlan(np.basePlan)
+	np.basePhysicalPlan = newBasePhysicalPlan(np.basePlan)
+	return &np
+}
+
 // Copy implements the PhysicalPlan Copy interface.
 func (p *TableDual) Copy() PhysicalPlan {
 	np := *p
@@ -1117,6 +1286,33 @@ func (p *PhysicalAggregation) MarshalJSON() ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
+// Copy implements the PhysicalPlan Copy interface.
+func (p *PhysicalWindow) Copy() PhysicalPlan {
+	np := *p
+	np.basePlan = p.basePlan.copy()
+	np.basePhysicalPlan = newBasePhysicalPlan(np.basePlan)
+	return &np
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (p *PhysicalWindow) MarshalJSON() ([]byte, error) {
+	partitionBy, err := json.Marshal(p.PartitionBy)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	orderBy, err := json.Marshal(p.OrderBy)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	buffer := bytes.NewBufferString("{")
+	buffer.WriteString(fmt.Sprintf(
+		"\"WindowFunc\": \"%s\",\n"+
+			"\"PartitionBy\": %s,\n"+
+			"\"OrderBy\": %s,\n"+
+			"\"child\": \"%s\"}", p.WindowFunc, partitionBy, orderBy, p.children[0].ID()))
+	return buffer.Bytes(), nil
+}
+
 // Copy implements the PhysicalPlan Copy interface.
 func (p *Update) Copy() PhysicalPlan {
 	np := *p
@@ -1164,7 +1360,7 @@ func buildSchema(p PhysicalPlan) {
 	switch x := p.(type) {
 	case *Limit, *TopN, *Sort, *Selection, *MaxOneRow, *SelectLock:
 		p.SetSchema(p.Children()[0].Schema())
-	case *PhysicalHashJoin, *PhysicalMergeJoin, *PhysicalIndexJoin:
+	case *PhysicalHashJoin, *PhysicalMergeJoin, *PhysicalIndexJoin, *PhysicalCrossJoin:
 		p.SetSchema(expression.MergeSchema(p.Children()[0].Schema(), p.Children()[1].Schema()))
 	case *PhysicalApply:
 		buildSchema(x.PhysicalJoin)
@@ -1192,7 +1388,7 @@ func rebuildSchema(p PhysicalPlan) bool {
 		buildSchema(p)
 	}
 	switch p.(type) {
-	case *PhysicalIndexJoin, *PhysicalHashJoin, *PhysicalMergeJoin:
+	case *PhysicalIndexJoin, *PhysicalHashJoin, *PhysicalMergeJoin, *PhysicalCrossJoin:
 		needRebuild = true
 	case *Projection, *PhysicalAggregation:
 		needRebuild = false
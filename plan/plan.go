@@ -275,6 +275,12 @@ type PhysicalPlan interface {
 
 	// statsProfile will return the stats for this plan.
 	statsProfile() *statsProfile
+
+	// estimatedCost returns the estimated cost of this plan and its children.
+	estimatedCost() float64
+
+	// setEstimatedCost records the estimated cost of this plan and its children.
+	setEstimatedCost(cost float64)
 }
 
 type baseLogicalPlan struct {
@@ -294,6 +300,16 @@ func (bp *basePhysicalPlan) ExplainInfo() string {
 	return ""
 }
 
+// estimatedCost implements PhysicalPlan interface.
+func (bp *basePhysicalPlan) estimatedCost() float64 {
+	return bp.basePlan.cost
+}
+
+// setEstimatedCost implements PhysicalPlan interface.
+func (bp *basePhysicalPlan) setEstimatedCost(cost float64) {
+	bp.basePlan.cost = cost
+}
+
 func (p *baseLogicalPlan) getTask(prop *requiredProp) (task, error) {
 	key, err := prop.getHashKey()
 	if err != nil {
@@ -336,6 +352,7 @@ func (p *baseLogicalPlan) storeTask(prop *requiredProp, task task) error {
 		return errors.Trace(err)
 	}
 	p.taskMap[string(key)] = task
+	recordCost(task)
 	return nil
 }
 
@@ -449,6 +466,9 @@ type basePlan struct {
 	ctx       context.Context
 	self      Plan
 	profile   *statsProfile
+	// cost is the estimated cost of the physical plan and its children, it's recorded when the plan is on top of
+	// a task chosen by the optimizer.
+	cost float64
 }
 
 func (p *basePlan) copy() *basePlan {
@@ -0,0 +1,395 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/privilege"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/kvcache"
+	"github.com/pingcap/tidb/util/ranger"
+	"github.com/pingcap/tidb/util/types"
+)
+
+var (
+	// PreparedPlanCacheEnabled means whether the plans of the prepared statements are cached.
+	PreparedPlanCacheEnabled = false
+	// PreparedPlanCacheCapacity is the number of the plans cached by each session.
+	PreparedPlanCacheCapacity uint = 100
+)
+
+// Cacheable checks whether the plan of the prepared statement can be cached.
+// Only the SELECT, UPDATE and DELETE statements without subqueries, user variables,
+// window functions, common table expressions, parameterized LIMIT and the functions
+// in unCacheableFunctions are cacheable.
+func Cacheable(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.SelectStmt, *ast.UpdateStmt, *ast.DeleteStmt:
+	default:
+		return false
+	}
+	checker := cacheableChecker{cacheable: true}
+	node.Accept(&checker)
+	return checker.cacheable
+}
+
+// unCacheableFunctions are the functions whose results change between the executions of a statement.
+// They may be folded into constants when the plan is built, so the plans using them are not cached.
+var unCacheableFunctions = map[string]struct{}{
+	ast.Now:              {},
+	ast.CurrentTimestamp: {},
+	ast.LocalTime:        {},
+	ast.LocalTimestamp:   {},
+	ast.Curdate:          {},
+	ast.CurrentDate:      {},
+	ast.Curtime:          {},
+	ast.CurrentTime:      {},
+	ast.UTCDate:          {},
+	ast.UTCTime:          {},
+	ast.UTCTimestamp:     {},
+	ast.Sysdate:          {},
+	ast.UnixTimestamp:    {},
+	ast.Rand:             {},
+	ast.RandomBytes:      {},
+	ast.UUID:             {},
+	ast.UUIDShort:        {},
+	ast.LastInsertId:     {},
+	ast.FoundRows:        {},
+	ast.RowCount:         {},
+	ast.Sleep:            {},
+	ast.Benchmark:        {},
+	ast.GetLock:          {},
+	ast.ReleaseLock:      {},
+	ast.ReleaseAllLocks:  {},
+	ast.IsFreeLock:       {},
+	ast.IsUsedLock:       {},
+	// The password policy is loaded when the function is built.
+	ast.ValidatePasswordStrength: {},
+}
+
+// cacheableChecker checks whether a statement contains the expressions whose plans
+// depend on the values of the parameters or the runtime states.
+type cacheableChecker struct {
+	cacheable bool
+}
+
+// Enter implements Visitor interface.
+func (checker *cacheableChecker) Enter(in ast.Node) (out ast.Node, skipChildren bool) {
+	switch node := in.(type) {
+	case *ast.SubqueryExpr, *ast.ExistsSubqueryExpr, *ast.CompareSubqueryExpr, *ast.VariableExpr,
+		*ast.WindowFuncExpr, *ast.WithClause:
+		checker.cacheable = false
+		return in, true
+	case *ast.FuncCallExpr:
+		if _, ok := unCacheableFunctions[node.FnName.L]; ok {
+			checker.cacheable = false
+			return in, true
+		}
+	case *ast.Limit:
+		if _, ok := node.Count.(*ast.ParamMarkerExpr); ok {
+			checker.cacheable = false
+			return in, true
+		}
+		if _, ok := node.Offset.(*ast.ParamMarkerExpr); ok {
+			checker.cacheable = false
+			return in, true
+		}
+	}
+	return in, false
+}
+
+// Leave implements Visitor interface.
+func (checker *cacheableChecker) Leave(in ast.Node) (out ast.Node, ok bool) {
+	return in, checker.cacheable
+}
+
+// pstmtPlanCacheKey is the key of the cached plan of a prepared statement. Besides the statement
+// and the schema version, it contains the session states and the parameter types which the plan
+// depends on, including the variables read when the executors are built from the plan.
+type pstmtPlanCacheKey struct {
+	database                   string
+	pstmtID                    uint32
+	schemaVersion              int64
+	snapshot                   uint64
+	sqlMode                    int64
+	strictSQLMode              bool
+	timezone                   string
+	useDAGPlanBuilder          bool
+	allowAggPushDown           bool
+	allowInSubqueryUnFolding   bool
+	maxCartesianRows           int64
+	joinReorderLimit           int64
+	maxRowCountForINLJ         int64
+	indexJoinBatchSize         int64
+	indexLookupSize            int64
+	indexLookupConcurrency     int64
+	distSQLScanConcurrency     int64
+	indexSerialScanConcurrency int64
+	isPessimistic              bool
+	needUnionScan              bool
+	paramTypes                 []types.FieldType
+	hash                       []byte
+}
+
+// Hash implements Key interface.
+func (key *pstmtPlanCacheKey) Hash() []byte {
+	if key.hash != nil {
+		return key.hash
+	}
+	key.hash = codec.EncodeCompactBytes(key.hash, []byte(key.database))
+	key.hash = codec.EncodeUint(key.hash, uint64(key.pstmtID))
+	key.hash = codec.EncodeInt(key.hash, key.schemaVersion)
+	key.hash = codec.EncodeUint(key.hash, key.snapshot)
+	key.hash = codec.EncodeInt(key.hash, key.sqlMode)
+	key.hash = codec.EncodeInt(key.hash, boolToInt64(key.strictSQLMode))
+	key.hash = codec.EncodeCompactBytes(key.hash, []byte(key.timezone))
+	key.hash = codec.EncodeInt(key.hash, boolToInt64(key.useDAGPlanBuilder))
+	key.hash = codec.EncodeInt(key.hash, boolToInt64(key.allowAggPushDown))
+	key.hash = codec.EncodeInt(key.hash, boolToInt64(key.allowInSubqueryUnFolding))
+	key.hash = codec.EncodeInt(key.hash, key.maxCartesianRows)
+	key.hash = codec.EncodeInt(key.hash, key.joinReorderLimit)
+	key.hash = codec.EncodeInt(key.hash, key.maxRowCountForINLJ)
+	key.hash = codec.EncodeInt(key.hash, key.indexJoinBatchSize)
+	key.hash = codec.EncodeInt(key.hash, key.indexLookupSize)
+	key.hash = codec.EncodeInt(key.hash, key.indexLookupConcurrency)
+	key.hash = codec.EncodeInt(key.hash, key.distSQLScanConcurrency)
+	key.hash = codec.EncodeInt(key.hash, key.indexSerialScanConcurrency)
+	key.hash = codec.EncodeInt(key.hash, boolToInt64(key.isPessimistic))
+	key.hash = codec.EncodeInt(key.hash, boolToInt64(key.needUnionScan))
+	for _, tp := range key.paramTypes {
+		key.hash = append(key.hash, tp.Tp)
+		key.hash = codec.EncodeUint(key.hash, uint64(tp.Flag))
+		key.hash = codec.EncodeInt(key.hash, int64(tp.Decimal))
+		key.hash = codec.EncodeCompactBytes(key.hash, []byte(tp.Charset))
+	}
+	return key.hash
+}
+
+func boolToInt64(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// NewPSTMTPlanCacheKey creates the key of the cached plan of the prepared statement pstmtID,
+// which is executed with the parameters params.
+func NewPSTMTPlanCacheKey(ctx context.Context, pstmtID uint32, schemaVersion int64, params []types.Datum) kvcache.Key {
+	vars := ctx.GetSessionVars()
+	key := &pstmtPlanCacheKey{
+		database:                   vars.CurrentDB,
+		pstmtID:                    pstmtID,
+		schemaVersion:              schemaVersion,
+		snapshot:                   vars.SnapshotTS,
+		sqlMode:                    int64(vars.SQLMode),
+		strictSQLMode:              vars.StrictSQLMode,
+		useDAGPlanBuilder:          UseDAGPlanBuilder(ctx),
+		allowAggPushDown:           vars.AllowAggPushDown,
+		allowInSubqueryUnFolding:   vars.AllowInSubqueryUnFolding,
+		maxCartesianRows:           vars.MaxCartesianProductRows,
+		joinReorderLimit:           int64(vars.JoinReorderThreshold),
+		maxRowCountForINLJ:         int64(vars.MaxRowCountForINLJ),
+		indexJoinBatchSize:         int64(vars.IndexJoinBatchSize),
+		indexLookupSize:            int64(vars.IndexLookupSize),
+		indexLookupConcurrency:     int64(vars.IndexLookupConcurrency),
+		distSQLScanConcurrency:     int64(vars.DistSQLScanConcurrency),
+		indexSerialScanConcurrency: int64(vars.IndexSerialScanConcurrency),
+		isPessimistic:              vars.TxnCtx.IsPessimistic,
+		needUnionScan:              ctx.Txn() != nil && !ctx.Txn().IsReadOnly(),
+		paramTypes:                 make([]types.FieldType, len(params)),
+	}
+	if vars.TimeZone != nil {
+		key.timezone = vars.TimeZone.String()
+	}
+	for i, param := range params {
+		types.DefaultTypeForValue(param.GetValue(), &key.paramTypes[i])
+	}
+	return key
+}
+
+// PSTMTPlanCacheValue is the cached plan of a prepared statement.
+type PSTMTPlanCacheValue struct {
+	Plan      Plan
+	visitInfo []visitInfo
+}
+
+// newPSTMTPlanCacheValue creates the cache value of the plan p, it returns nil if p is not cacheable.
+// The ranges of the scans in p are built with the parameters of the execution which p is optimized for,
+// p is not cacheable if the ranges can't be rebuilt with the other parameters.
+func newPSTMTPlanCacheValue(ctx context.Context, p Plan, vs []visitInfo) *PSTMTPlanCacheValue {
+	if !UseDAGPlanBuilder(ctx) {
+		return nil
+	}
+	if ok, err := rebuildRanges(ctx, p); !ok || err != nil {
+		return nil
+	}
+	return &PSTMTPlanCacheValue{Plan: p, visitInfo: vs}
+}
+
+// GetCachedPlan checks the privileges of the cached plan and rebuilds its ranges with the current
+// parameters. It returns false if the ranges can't be rebuilt, then the statement should be optimized again.
+func GetCachedPlan(ctx context.Context, v *PSTMTPlanCacheValue) (Plan, bool, error) {
+	if pm := privilege.GetPrivilegeManager(ctx); pm != nil {
+		if !checkPrivilege(pm, v.visitInfo) {
+			return nil, false, errors.New("privilege check fail")
+		}
+	}
+	ok, err := rebuildRanges(ctx, v.Plan)
+	if err != nil || !ok {
+		return nil, false, errors.Trace(err)
+	}
+	return v.Plan, true, nil
+}
+
+// OptimizeForCache does the same optimization as Optimize, but the parameter markers are not folded
+// with the current parameters. It also returns the cache value of the plan, which is nil if the plan
+// is not cacheable.
+func OptimizeForCache(ctx context.Context, node ast.Node, is infoschema.InfoSchema) (Plan, *PSTMTPlanCacheValue, error) {
+	ctx.GetSessionVars().StmtCtx.UseCache = true
+	p, vs, err := optimize(ctx, node, is)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return p, newPSTMTPlanCacheValue(ctx, p, vs), nil
+}
+
+// rebuildRanges rebuilds the ranges of the scans in p from their access conditions, in which the
+// constants of the parameter markers are replaced with the current parameters. It returns false
+// if the access conditions can't be converted to the ranges exactly.
+func rebuildRanges(ctx context.Context, p Plan) (bool, error) {
+	switch x := p.(type) {
+	case *PhysicalTableReader:
+		return rebuildPushDownRanges(ctx, x.TablePlans)
+	case *PhysicalIndexReader:
+		return rebuildPushDownRanges(ctx, x.IndexPlans)
+	case *PhysicalIndexLookUpReader:
+		ok, err := rebuildPushDownRanges(ctx, x.IndexPlans)
+		if !ok || err != nil {
+			return ok, errors.Trace(err)
+		}
+		return rebuildPushDownRanges(ctx, x.TablePlans)
+	case *PhysicalIndexMergeReader:
+		for _, partialPlans := range x.PartialPlans {
+			ok, err := rebuildPushDownRanges(ctx, partialPlans)
+			if !ok || err != nil {
+				return ok, errors.Trace(err)
+			}
+		}
+		return rebuildPushDownRanges(ctx, x.TablePlans)
+	}
+	for _, child := range p.Children() {
+		ok, err := rebuildRanges(ctx, child)
+		if !ok || err != nil {
+			return ok, errors.Trace(err)
+		}
+	}
+	return true, nil
+}
+
+func rebuildPushDownRanges(ctx context.Context, plans []PhysicalPlan) (bool, error) {
+	sc := ctx.GetSessionVars().StmtCtx
+	for _, p := range plans {
+		switch x := p.(type) {
+		case *PhysicalTableScan:
+			if x.PhysicalTableID != x.Table.ID || x.Table.Partition != nil {
+				return false, nil
+			}
+			if len(x.AccessCondition) == 0 {
+				continue
+			}
+			pkColInfo := x.Table.GetPkColInfo()
+			if pkColInfo == nil {
+				return false, nil
+			}
+			pkCol := expression.ColInfo2Col(x.schema.Columns, pkColInfo)
+			if pkCol == nil {
+				return false, nil
+			}
+			conds, err := evalDeferredConstants(x.AccessCondition)
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			ranges, accesses, filters, err := ranger.BuildRange(sc, conds, ranger.IntRangeType, []*expression.Column{pkCol}, nil)
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			if len(accesses) != len(x.AccessCondition) || len(filters) > 0 {
+				return false, nil
+			}
+			x.Ranges = ranger.Ranges2IntRanges(ranges)
+		case *PhysicalIndexScan:
+			if x.PhysicalTableID != x.Table.ID || x.Table.Partition != nil {
+				return false, nil
+			}
+			if len(x.AccessCondition) == 0 {
+				continue
+			}
+			conds, err := evalDeferredConstants(x.AccessCondition)
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			ranges, accesses, filters, err := ranger.BuildRange(sc, conds, ranger.IndexRangeType, x.idxCols, x.idxColLens)
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			if len(accesses) != len(x.AccessCondition) || len(filters) > 0 {
+				return false, nil
+			}
+			x.Ranges = ranger.Ranges2IndexRanges(ranges)
+		}
+	}
+	return true, nil
+}
+
+// evalDeferredConstants clones the expressions and replaces the deferred constants in them with their
+// current values, so that the ranges can be built from them.
+func evalDeferredConstants(exprs []expression.Expression) ([]expression.Expression, error) {
+	newExprs := make([]expression.Expression, 0, len(exprs))
+	for _, expr := range exprs {
+		newExpr, err := evalDeferredConstant(expr.Clone())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		newExprs = append(newExprs, newExpr)
+	}
+	return newExprs, nil
+}
+
+func evalDeferredConstant(expr expression.Expression) (expression.Expression, error) {
+	switch x := expr.(type) {
+	case *expression.Constant:
+		if x.DeferredExpr == nil {
+			return x, nil
+		}
+		val, err := x.DeferredExpr.Eval(nil)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return &expression.Constant{Value: val, RetType: x.RetType}, nil
+	case *expression.ScalarFunction:
+		args := x.GetArgs()
+		for i, arg := range args {
+			newArg, err := evalDeferredConstant(arg)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			args[i] = newArg
+		}
+	}
+	return expr, nil
+}
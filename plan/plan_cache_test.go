@@ -0,0 +1,65 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan_test
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+var _ = Suite(&testPlanCacheSuite{})
+
+type testPlanCacheSuite struct {
+}
+
+func (s *testPlanCacheSuite) TestCacheable(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		sql       string
+		cacheable bool
+	}{
+		{"select * from t where a = ?", true},
+		{"select * from t where a in (?, ?) and b > ? order by c", true},
+		{"select count(*) from t group by a having sum(b) > ?", true},
+		{"select * from t limit 10", true},
+		{"update t set a = ? where b = ?", true},
+		{"delete from t where a < ?", true},
+		{"insert into t values (?, ?)", false},
+		{"select * from t limit ?", false},
+		{"select * from t limit 1, ?", false},
+		{"select * from t limit ?, 1", false},
+		{"select * from t where a = @a", false},
+		{"select * from t where a in (select a from t1)", false},
+		{"select * from t where exists (select * from t1 where t1.a = ?)", false},
+		{"select * from t where a > any (select a from t1)", false},
+		{"select a, row_number() over (order by b) from t", false},
+		{"with cte as (select * from t) select * from cte where a = ?", false},
+		{"select * from t union select * from t1", false},
+		{"select now(), a from t where a = ?", false},
+		{"select * from t where b > current_timestamp()", false},
+		{"update t set b = unix_timestamp() where a = ?", false},
+		{"select * from t where b < rand()", false},
+		{"delete from t where b = uuid()", false},
+		{"select validate_password_strength(?)", false},
+		{"select abs(a) from t where a = ?", true},
+	}
+	p := parser.New()
+	for _, tt := range tests {
+		stmt, err := p.ParseOneStmt(tt.sql, "", "")
+		c.Assert(err, IsNil, Commentf("for %s", tt.sql))
+		c.Assert(plan.Cacheable(stmt), Equals, tt.cacheable, Commentf("for %s", tt.sql))
+	}
+}
@@ -76,7 +76,7 @@ func (p *Limit) ToPB(ctx context.Context) (*tipb.Executor, error) {
 // ToPB implements PhysicalPlan ToPB interface.
 func (p *PhysicalTableScan) ToPB(ctx context.Context) (*tipb.Executor, error) {
 	tsExec := &tipb.TableScan{
-		TableId: p.Table.ID,
+		TableId: p.PhysicalTableID,
 		Columns: distsql.ColumnsToProto(p.Columns, p.Table.PKIsHandle),
 		Desc:    p.Desc,
 	}
@@ -91,7 +91,7 @@ func (p *PhysicalIndexScan) ToPB(ctx context.Context) (*tipb.Executor, error) {
 		columns = append(columns, p.Table.Columns[col.Position])
 	}
 	idxExec := &tipb.IndexScan{
-		TableId: p.Table.ID,
+		TableId: p.PhysicalTableID,
 		IndexId: p.Index.ID,
 		Columns: distsql.ColumnsToProto(columns, p.Table.PKIsHandle),
 		Desc:    p.Desc,
@@ -15,6 +15,7 @@ package plan
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
@@ -26,6 +27,7 @@ import (
 	"github.com/pingcap/tidb/parser/opcode"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -40,28 +42,31 @@ var (
 	ErrAnalyzeMissIndex     = terror.ClassOptimizerPlan.New(CodeAnalyzeMissIndex, "Index '%s' in field list does not exist in table '%s'")
 	ErrAlterAutoID          = terror.ClassAutoid.New(CodeAlterAutoID, "No support for setting auto_increment using alter_table")
 	ErrBadGeneratedColumn   = terror.ClassOptimizerPlan.New(CodeBadGeneratedColumn, mysql.MySQLErrName[mysql.ErrBadGeneratedColumn])
+	ErrUnknownExplainFormat = terror.ClassOptimizerPlan.New(CodeUnknownExplainFormat, mysql.MySQLErrName[mysql.ErrUnknownExplainFormat])
 )
 
 // Error codes.
 const (
-	CodeUnsupportedType    terror.ErrCode = 1
-	SystemInternalError                   = 2
-	CodeAlterAutoID                       = 3
-	CodeAnalyzeMissIndex                  = 4
-	CodeAmbiguous                         = 1052
-	CodeUnknownColumn                     = mysql.ErrBadField
-	CodeUnknownTable                      = mysql.ErrBadTable
-	CodeWrongArguments                    = 1210
-	CodeBadGeneratedColumn                = mysql.ErrBadGeneratedColumn
+	CodeUnsupportedType      terror.ErrCode = 1
+	SystemInternalError                     = 2
+	CodeAlterAutoID                         = 3
+	CodeAnalyzeMissIndex                    = 4
+	CodeAmbiguous                           = 1052
+	CodeUnknownColumn                       = mysql.ErrBadField
+	CodeUnknownTable                        = mysql.ErrBadTable
+	CodeWrongArguments                      = 1210
+	CodeBadGeneratedColumn                  = mysql.ErrBadGeneratedColumn
+	CodeUnknownExplainFormat                = mysql.ErrUnknownExplainFormat
 )
 
 func init() {
 	tableMySQLErrCodes := map[terror.ErrCode]uint16{
-		CodeUnknownColumn:      mysql.ErrBadField,
-		CodeUnknownTable:       mysql.ErrBadTable,
-		CodeAmbiguous:          mysql.ErrNonUniq,
-		CodeWrongArguments:     mysql.ErrWrongArguments,
-		CodeBadGeneratedColumn: mysql.ErrBadGeneratedColumn,
+		CodeUnknownColumn:        mysql.ErrBadField,
+		CodeUnknownTable:         mysql.ErrBadTable,
+		CodeAmbiguous:            mysql.ErrNonUniq,
+		CodeWrongArguments:       mysql.ErrWrongArguments,
+		CodeBadGeneratedColumn:   mysql.ErrBadGeneratedColumn,
+		CodeUnknownExplainFormat: mysql.ErrUnknownExplainFormat,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassOptimizerPlan] = tableMySQLErrCodes
 }
@@ -71,6 +76,8 @@ type visitInfo struct {
 	db        string
 	table     string
 	column    string
+	// user is the definer of the view being expanded, it is nil if the privilege is checked for the current user.
+	user *auth.UserIdentity
 }
 
 type tableHintInfo struct {
@@ -127,6 +134,14 @@ type planBuilder struct {
 	needColHandle int
 	// colMapper stores the column that must be pre-resolved.
 	colMapper map[*ast.ColumnNameExpr]int
+	// windowMapper stores the offsets of the window functions' results in the schema of the plan being rewritten.
+	windowMapper map[*ast.WindowFuncExpr]int
+	// cteDefs stores the built common table expressions, so a CTE referenced several times is built only once.
+	cteDefs map[*ast.CommonTableExpression]*CTEDefinition
+	// recursiveCTEs stores the recursive CTEs whose recursive query blocks are being built.
+	recursiveCTEs map[*ast.CommonTableExpression]*CTEDefinition
+	// expandingViews stores the names of the views being expanded, to detect the recursion of views.
+	expandingViews []string
 	// Collect the visit information for privilege check.
 	visitInfo     []visitInfo
 	tableHintInfo []tableHintInfo
@@ -684,6 +699,10 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 		return nil
 	}
 	tableInfo := tn.TableInfo
+	if tableInfo.IsView() {
+		b.err = ErrNonInsertableTable.GenByArgs(tableInfo.Name.O, "INSERT")
+		return nil
+	}
 	schema := expression.TableInfo2Schema(tableInfo)
 	tableInPlan, ok := b.is.TableByID(tableInfo.ID)
 	if !ok {
@@ -707,8 +726,13 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 	})
 
 	columnByName := make(map[string]*table.Column, len(insertPlan.Table.Cols()))
+	// visibleCols are the columns which can be given values without the column list.
+	visibleCols := make([]*model.ColumnInfo, 0, len(tableInfo.Columns))
 	for _, col := range insertPlan.Table.Cols() {
 		columnByName[col.Name.L] = col
+		if !col.Hidden {
+			visibleCols = append(visibleCols, col.ColumnInfo)
+		}
 	}
 
 	// Check insert.Columns contains generated columns or not.
@@ -716,6 +740,10 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 	if len(insert.Columns) > 0 {
 		for _, col := range insert.Columns {
 			if column, ok := columnByName[col.Name.L]; ok {
+				if column.Hidden {
+					b.err = ErrUnknownColumn.GenByArgs(col.Name.O, "field list")
+					return nil
+				}
 				if column.IsGenerated() {
 					b.err = ErrBadGeneratedColumn.GenByArgs(col.Name.O, tableInfo.Name.O)
 					return nil
@@ -761,13 +789,13 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 		// The length of VALUES list maybe exceed table width,
 		// we ignore this here but do checking in executor.
 		var effectiveValuesLen int
-		if maxValuesItemLength <= len(tableInfo.Columns) {
+		if maxValuesItemLength <= len(visibleCols) {
 			effectiveValuesLen = maxValuesItemLength
 		} else {
-			effectiveValuesLen = len(tableInfo.Columns)
+			effectiveValuesLen = len(visibleCols)
 		}
 		for i := 0; i < effectiveValuesLen; i++ {
-			col := tableInfo.Columns[i]
+			col := visibleCols[i]
 			if col.IsGenerated() {
 				b.err = ErrBadGeneratedColumn.GenByArgs(col.Name.O, tableInfo.Name.O)
 				return nil
@@ -787,6 +815,10 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 			b.err = errors.Errorf("Can't find column %s", assign.Column)
 			return nil
 		}
+		if columnByName[assign.Column.Name.L].Hidden {
+			b.err = ErrUnknownColumn.GenByArgs(assign.Column.Name.O, "field list")
+			return nil
+		}
 		// Check set list contains generated column or not.
 		if columnByName[assign.Column.Name.L].IsGenerated() {
 			b.err = ErrBadGeneratedColumn.GenByArgs(assign.Column.Name.O, tableInfo.Name.O)
@@ -839,13 +871,13 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 		}
 		// If the schema of selectPlan contains any generated column, raises error.
 		var effectiveSelectLen int
-		if selectPlan.Schema().Len() <= len(tableInfo.Columns) {
+		if selectPlan.Schema().Len() <= len(visibleCols) {
 			effectiveSelectLen = selectPlan.Schema().Len()
 		} else {
-			effectiveSelectLen = len(tableInfo.Columns)
+			effectiveSelectLen = len(visibleCols)
 		}
 		for i := 0; i < effectiveSelectLen; i++ {
-			col := tableInfo.Columns[i]
+			col := visibleCols[i]
 			if col.IsGenerated() {
 				b.err = ErrBadGeneratedColumn.GenByArgs(col.Name.O, tableInfo.Name.O)
 				return nil
@@ -873,6 +905,10 @@ func (b *planBuilder) buildLoadData(ld *ast.LoadDataStmt) Plan {
 		LinesInfo:  ld.LinesInfo,
 	}
 	tableInfo := p.Table.TableInfo
+	if tableInfo.IsView() {
+		b.err = ErrNonInsertableTable.GenByArgs(tableInfo.Name.O, "LOAD")
+		return nil
+	}
 	tableInPlan, ok := b.is.TableByID(tableInfo.ID)
 	if !ok {
 		db := b.ctx.GetSessionVars().CurrentDB
@@ -919,6 +955,19 @@ func (b *planBuilder) buildDDL(node ast.DDLNode) Plan {
 				table:     v.ReferTable.Name.L,
 			})
 		}
+	case *ast.CreateViewStmt:
+		b.visitInfo = append(b.visitInfo, visitInfo{
+			privilege: mysql.CreatePriv,
+			db:        v.ViewName.Schema.L,
+			table:     v.ViewName.Name.L,
+		})
+		// Only the users with SUPER privilege can create a view whose definer is another user.
+		if v.Definer != nil && !b.isCurrentUser(v.Definer) {
+			b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+		}
+		if !b.buildViewColumns(v) {
+			return nil
+		}
 	case *ast.DropDatabaseStmt:
 		b.visitInfo = append(b.visitInfo, visitInfo{
 			privilege: mysql.DropPriv,
@@ -962,6 +1011,52 @@ func (b *planBuilder) buildDDL(node ast.DDLNode) Plan {
 	return p
 }
 
+// buildViewColumns builds the select statement of the view to check it and to fill the columns of the view.
+// isCurrentUser checks whether the user is the account which the current user is authenticated as.
+func (b *planBuilder) isCurrentUser(user *auth.UserIdentity) bool {
+	sessionUser := b.ctx.GetSessionVars().User
+	if sessionUser == nil {
+		return true
+	}
+	current := sessionUser.AuthIdentity()
+	return user.Username == current.Username && strings.EqualFold(user.Hostname, current.Hostname)
+}
+
+func (b *planBuilder) buildViewColumns(v *ast.CreateViewStmt) bool {
+	// Replacing a view with a select statement referring to the view itself makes the view recursive.
+	b.expandingViews = append(b.expandingViews, v.ViewName.Schema.L+"."+v.ViewName.Name.L)
+	sel := b.buildResultSetNode(v.Select.(ast.ResultSetNode))
+	b.expandingViews = b.expandingViews[:len(b.expandingViews)-1]
+	if b.err != nil {
+		return false
+	}
+	schema := sel.Schema()
+	if len(v.Cols) > 0 && len(v.Cols) != schema.Len() {
+		b.err = errors.Trace(ErrViewWrongList)
+		return false
+	}
+	v.SchemaCols = make([]*model.ColumnInfo, 0, schema.Len())
+	names := make(map[string]struct{}, schema.Len())
+	for i, col := range schema.Columns {
+		name := col.ColName
+		if len(v.Cols) > 0 {
+			name = v.Cols[i]
+		}
+		if _, ok := names[name.L]; ok {
+			b.err = ErrDupFieldName.GenByArgs(name.O)
+			return false
+		}
+		names[name.L] = struct{}{}
+		v.SchemaCols = append(v.SchemaCols, &model.ColumnInfo{
+			Name:      name,
+			Offset:    i,
+			FieldType: *col.RetType,
+			State:     model.StatePublic,
+		})
+	}
+	return true
+}
+
 func (b *planBuilder) buildExplain(explain *ast.ExplainStmt) Plan {
 	if show, ok := explain.Stmt.(*ast.ShowStmt); ok {
 		return b.buildShow(show)
@@ -972,17 +1067,41 @@ func (b *planBuilder) buildExplain(explain *ast.ExplainStmt) Plan {
 		return nil
 	}
 	setParents4FinalPlan(targetPlan.(PhysicalPlan))
-	p := &Explain{StmtPlan: targetPlan}
+	p := &Explain{StmtPlan: targetPlan, Analyze: explain.Analyze, Format: explain.Format}
+	if p.Format == "" {
+		p.Format = ast.ExplainFormatROW
+	}
 	if UseDAGPlanBuilder(b.ctx) {
-		retFields := []string{"id", "parents", "children", "task", "operator info"}
-		schema := expression.NewSchema(make([]*expression.Column, 0, len(retFields))...)
-		for _, fieldName := range retFields {
-			schema.Append(buildColumn("", fieldName, mysql.TypeString, mysql.MaxBlobWidth))
+		schema := expression.NewSchema()
+		switch p.Format {
+		case ast.ExplainFormatROW:
+			retFields := []string{"id", "parents", "children", "task", "operator info"}
+			for _, fieldName := range retFields {
+				schema.Append(buildColumn("", fieldName, mysql.TypeString, mysql.MaxBlobWidth))
+			}
+			schema.Append(buildColumn("", "count", mysql.TypeDouble, mysql.MaxRealWidth))
+			if p.Analyze {
+				schema.Append(buildColumn("", "execution info", mysql.TypeString, mysql.MaxBlobWidth))
+				schema.Append(buildColumn("", "memory", mysql.TypeString, mysql.MaxBlobWidth))
+			}
+		case ast.ExplainFormatJSON:
+			schema.Append(buildColumn("", "EXPLAIN", mysql.TypeString, mysql.MaxBlobWidth))
+		case ast.ExplainFormatDOT:
+			schema.Append(buildColumn("", "dot contents", mysql.TypeString, mysql.MaxBlobWidth))
+		default:
+			b.err = ErrUnknownExplainFormat.GenByArgs(explain.Format)
+			return nil
 		}
-		schema.Append(buildColumn("", "count", mysql.TypeDouble, mysql.MaxRealWidth))
 		p.SetSchema(schema)
-		p.explainedPlans = map[string]bool{}
-		p.prepareRootTaskInfo(p.StmtPlan.(PhysicalPlan))
+		if !p.Analyze {
+			b.err = p.RenderResult(nil)
+			if b.err != nil {
+				return nil
+			}
+		}
+	} else if explain.Analyze || p.Format != ast.ExplainFormatROW {
+		b.err = ErrUnsupportedType.Gen("EXPLAIN ANALYZE and EXPLAIN FORMAT are only supported by the new planner")
+		return nil
 	} else {
 		schema := expression.NewSchema(make([]*expression.Column, 0, 3)...)
 		schema.Append(buildColumn("", "ID", mysql.TypeString, mysql.MaxBlobWidth))
@@ -1132,6 +1251,8 @@ func buildShowSchema(s *ast.ShowStmt) (schema *expression.Schema) {
 		names = []string{"Table", "Create Table"}
 	case ast.ShowCreateDatabase:
 		names = []string{"Database", "Create Database"}
+	case ast.ShowCreateView:
+		names = []string{"View", "Create View", "character_set_client", "collation_connection"}
 	case ast.ShowGrants:
 		names = []string{fmt.Sprintf("Grants for %s", s.User)}
 	case ast.ShowIndex:
@@ -46,6 +46,7 @@ func (s *testPlanBuilderSuite) TestShow(c *C) {
 		ast.ShowIndex,
 		ast.ShowProcessList,
 		ast.ShowCreateDatabase,
+		ast.ShowCreateView,
 		ast.ShowEvents,
 	}
 	for _, tp := range tps {
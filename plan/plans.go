@@ -14,7 +14,9 @@
 package plan
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"strings"
 
 	"github.com/juju/errors"
@@ -24,6 +26,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/util/auth"
+	"github.com/pingcap/tidb/util/execdetails"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -186,6 +189,140 @@ type Explain struct {
 	StmtPlan       Plan
 	Rows           [][]types.Datum
 	explainedPlans map[string]bool
+	// Analyze means the statement is executed, the rows are rendered by RenderResult
+	// with the runtime statistics after the execution.
+	Analyze      bool
+	runtimeStats *execdetails.RuntimeStatsColl
+	// Format is the output format, see ast.ExplainFormatROW, ast.ExplainFormatJSON and ast.ExplainFormatDOT.
+	Format string
+}
+
+// RenderResult generates the explain result rows of the DAG plan. For EXPLAIN ANALYZE, it's called
+// after the statement is executed, and the runtime statistics are taken from coll.
+func (e *Explain) RenderResult(coll *execdetails.RuntimeStatsColl) error {
+	e.Rows = nil
+	e.runtimeStats = coll
+	switch e.Format {
+	case ast.ExplainFormatJSON:
+		node := newExplainNode(e.StmtPlan.(PhysicalPlan), "root")
+		data, err := json.MarshalIndent(node, "", "    ")
+		if err != nil {
+			return errors.Trace(err)
+		}
+		e.Rows = append(e.Rows, types.MakeDatums(string(data)))
+	case ast.ExplainFormatDOT:
+		buffer := bytes.NewBufferString("digraph Plan {\n\tnode [shape=box]\n")
+		newExplainNode(e.StmtPlan.(PhysicalPlan), "root").writeDOT(buffer, "\t")
+		buffer.WriteString("}\n")
+		e.Rows = append(e.Rows, types.MakeDatums(buffer.String()))
+	default:
+		e.explainedPlans = map[string]bool{}
+		e.prepareRootTaskInfo(e.StmtPlan.(PhysicalPlan))
+	}
+	return nil
+}
+
+// explainNode is an operator of the plan tree explained in the JSON and the DOT format.
+type explainNode struct {
+	ID            string  `json:"id"`
+	Operator      string  `json:"operator"`
+	Task          string  `json:"task"`
+	EstimatedRows float64 `json:"estimated_rows"`
+	// Cost is only set for the root-tasks, the cost of the cop-tasks is counted in their readers.
+	Cost                 *float64       `json:"cost,omitempty"`
+	AccessObject         string         `json:"access_object,omitempty"`
+	PushedDownConditions []string       `json:"pushed_down_conditions,omitempty"`
+	OperatorInfo         string         `json:"operator_info,omitempty"`
+	Children             []*explainNode `json:"children,omitempty"`
+}
+
+// newExplainNode builds the explain tree of p, which runs in the task taskType. The cop-tasks
+// of the readers are the children of the readers.
+func newExplainNode(p PhysicalPlan, taskType string) *explainNode {
+	node := &explainNode{
+		ID:            p.ID(),
+		Operator:      p.ID()[:strings.LastIndex(p.ID(), "_")],
+		Task:          taskType,
+		EstimatedRows: p.statsProfile().count,
+		OperatorInfo:  p.ExplainInfo(),
+	}
+	if taskType == "root" {
+		cost := p.estimatedCost()
+		node.Cost = &cost
+	}
+	var conds []expression.Expression
+	switch x := p.(type) {
+	case *PhysicalTableScan:
+		node.AccessObject = fmt.Sprintf("table:%s", explainTableName(x.Table, x.TableAsName))
+		conds = x.AccessCondition
+	case *PhysicalIndexScan:
+		node.AccessObject = fmt.Sprintf("table:%s, index:%s", explainTableName(x.Table, x.TableAsName), x.Index.Name.O)
+		conds = x.AccessCondition
+	case *PhysicalMemTable:
+		node.AccessObject = fmt.Sprintf("table:%s", explainTableName(x.Table, x.TableAsName))
+	case *Selection:
+		conds = x.Conditions
+	case *PhysicalTableReader:
+		node.Children = append(node.Children, newExplainNode(x.tablePlan, "cop"))
+	case *PhysicalIndexReader:
+		node.Children = append(node.Children, newExplainNode(x.indexPlan, "cop"))
+	case *PhysicalIndexLookUpReader:
+		node.Children = append(node.Children, newExplainNode(x.indexPlan, "cop"), newExplainNode(x.tablePlan, "cop"))
+	case *PhysicalIndexMergeReader:
+		for _, partialPlan := range x.partialPlans {
+			node.Children = append(node.Children, newExplainNode(partialPlan, "cop"))
+		}
+		node.Children = append(node.Children, newExplainNode(x.tablePlan, "cop"))
+	}
+	if taskType == "cop" {
+		for _, cond := range conds {
+			node.PushedDownConditions = append(node.PushedDownConditions, cond.ExplainInfo())
+		}
+	}
+	for _, child := range p.Children() {
+		node.Children = append(node.Children, newExplainNode(child.(PhysicalPlan), taskType))
+	}
+	return node
+}
+
+func explainTableName(tbl *model.TableInfo, asName *model.CIStr) string {
+	if asName != nil && asName.O != "" {
+		return asName.O
+	}
+	return tbl.Name.O
+}
+
+// writeDOT writes the node and its children as the statements of a Graphviz graph. The cop-tasks
+// are written in the dashed clusters.
+func (n *explainNode) writeDOT(buffer *bytes.Buffer, indent string) {
+	label := fmt.Sprintf("%s\nrows:%v", n.ID, n.EstimatedRows)
+	if n.Cost != nil {
+		label += fmt.Sprintf(", cost:%v", *n.Cost)
+	}
+	if n.OperatorInfo != "" {
+		label += "\n" + n.OperatorInfo
+	}
+	fmt.Fprintf(buffer, "%s%s [label=%s]\n", indent, dotQuote(n.ID), dotQuote(label))
+	for _, child := range n.Children {
+		if child.Task != n.Task {
+			fmt.Fprintf(buffer, "%ssubgraph %s {\n", indent, dotQuote("cluster_"+child.ID))
+			fmt.Fprintf(buffer, "%s\tlabel=%s\n%s\tstyle=dashed\n", indent, dotQuote(child.Task), indent)
+			child.writeDOT(buffer, indent+"\t")
+			fmt.Fprintf(buffer, "%s}\n", indent)
+			fmt.Fprintf(buffer, "%s%s -> %s [style=dashed]\n", indent, dotQuote(n.ID), dotQuote(child.ID))
+			continue
+		}
+		child.writeDOT(buffer, indent)
+		fmt.Fprintf(buffer, "%s%s -> %s\n", indent, dotQuote(n.ID), dotQuote(child.ID))
+	}
+}
+
+// dotQuote quotes s as a double-quoted string of the DOT language.
+func dotQuote(s string) string {
+	s = strings.Replace(s, "\\", "\\\\", -1)
+	s = strings.Replace(s, "\"", "\\\"", -1)
+	s = strings.Replace(s, "\n", "\\n", -1)
+	return "\"" + s + "\""
 }
 
 func (e *Explain) prepareExplainInfo(p Plan, parent Plan) error {
@@ -225,11 +362,39 @@ func (e *Explain) prepareExplainInfo4DAGTask(p PhysicalPlan, taskType string) {
 	operatorInfo := p.ExplainInfo()
 	count := p.statsProfile().count
 	row := types.MakeDatums(p.ID(), parentInfo, childrenInfo, taskType, operatorInfo, count)
+	if e.Analyze {
+		row = append(row, types.MakeDatums(e.runtimeStatsInfo(p.ID(), taskType))...)
+	}
 	e.Rows = append(e.Rows, row)
 }
 
+// runtimeStatsInfo returns the execution info and the memory usage of the plan planID.
+// The plans which are not executed have empty execution info.
+func (e *Explain) runtimeStatsInfo(planID string, taskType string) (string, string) {
+	if e.runtimeStats == nil {
+		return "", "N/A"
+	}
+	var execInfo string
+	memory := "N/A"
+	if taskType == "cop" {
+		if e.runtimeStats.ExistsCopStats(planID) {
+			execInfo = e.runtimeStats.GetCopStats(planID).String()
+		}
+		return execInfo, memory
+	}
+	if e.runtimeStats.ExistsRootStats(planID) {
+		stats := e.runtimeStats.GetRootStats(planID)
+		execInfo = stats.String()
+		if bytes := stats.Memory(); bytes > 0 {
+			memory = execdetails.FormatBytes(bytes)
+		}
+	}
+	return execInfo, memory
+}
+
 // prepareCopTaskInfo generates explain information for cop-tasks.
-// Only PhysicalTableReader, PhysicalIndexReader and PhysicalIndexLookUpReader have cop-tasks currently.
+// Only PhysicalTableReader, PhysicalIndexReader, PhysicalIndexLookUpReader and PhysicalIndexMergeReader have
+// cop-tasks currently.
 func (e *Explain) prepareCopTaskInfo(plans []PhysicalPlan) {
 	for _, p := range plans {
 		e.prepareExplainInfo4DAGTask(p, "cop")
@@ -253,6 +418,11 @@ func (e *Explain) prepareRootTaskInfo(p PhysicalPlan) {
 	case *PhysicalIndexLookUpReader:
 		e.prepareCopTaskInfo(copPlan.IndexPlans)
 		e.prepareCopTaskInfo(copPlan.TablePlans)
+	case *PhysicalIndexMergeReader:
+		for _, partialPlans := range copPlan.PartialPlans {
+			e.prepareCopTaskInfo(partialPlans)
+		}
+		e.prepareCopTaskInfo(copPlan.TablePlans)
 	}
 	e.prepareExplainInfo4DAGTask(p, "root")
 }
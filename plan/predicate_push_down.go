@@ -57,6 +57,14 @@ func (p *Selection) PredicatePushDown(predicates []expression.Expression) ([]exp
 func (p *DataSource) PredicatePushDown(predicates []expression.Expression) ([]expression.Expression, LogicalPlan, error) {
 	if UseDAGPlanBuilder(p.ctx) {
 		_, p.pushedDownConds, predicates = expression.ExpressionsToPB(p.ctx.GetSessionVars().StmtCtx, predicates, p.ctx.GetClient())
+		// The conditions which can't be pushed down may still be used to build the ranges of
+		// the indices on the virtual generated columns, they are kept in the Selection too.
+		p.virtualGenConds = nil
+		for _, cond := range predicates {
+			if len(p.virtualGenCols) > 0 && (p.substituteVirtualGenCols(cond) != cond || p.substituteJSONEqualCond(cond) != nil) {
+				p.virtualGenConds = append(p.virtualGenConds, cond)
+			}
+		}
 	}
 	return predicates, p, nil
 }
@@ -75,7 +83,11 @@ func (p *LogicalJoin) PredicatePushDown(predicates []expression.Expression) (ret
 	groups, valid := tryToGetJoinGroup(p)
 	if valid {
 		e := joinReOrderSolver{allocator: p.allocator, ctx: p.ctx}
-		e.reorderJoin(groups, predicates)
+		if len(groups) <= p.ctx.GetSessionVars().JoinReorderThreshold {
+			e.reorderJoinByDP(groups, predicates)
+		} else {
+			e.reorderJoin(groups, predicates)
+		}
 		newJoin := e.resultJoin
 		if len(p.parents) > 0 {
 			parent := p.parents[0]
@@ -329,7 +341,8 @@ func (p *Projection) PredicatePushDown(predicates []expression.Expression) (ret
 		extractedCols := expression.ExtractColumns(cond)
 		for _, col := range extractedCols {
 			id := p.Schema().ColumnIndex(col)
-			if _, ok := p.Exprs[id].(*expression.ScalarFunction); ok {
+			// The generated columns can be substituted, the indices on them are matched by the DataSource.
+			if _, ok := p.Exprs[id].(*expression.ScalarFunction); ok && !p.calculateGenCols {
 				canSubstitute = false
 				break
 			}
@@ -418,6 +431,37 @@ func (p *LogicalAggregation) PredicatePushDown(predicates []expression.Expressio
 	return
 }
 
+// PredicatePushDown implements LogicalPlan PredicatePushDown interface.
+// Only the predicates on partition by columns can be pushed down, because filtering whole partitions doesn't affect
+// the window function's results of the other partitions.
+func (p *LogicalWindow) PredicatePushDown(predicates []expression.Expression) (ret []expression.Expression, retPlan LogicalPlan, err error) {
+	var partitionCols []*expression.Column
+	for _, item := range p.PartitionBy {
+		if col, ok := item.Expr.(*expression.Column); ok {
+			partitionCols = append(partitionCols, col)
+		}
+	}
+	partitionSchema := expression.NewSchema(partitionCols...)
+	var condsToPush []expression.Expression
+	for _, cond := range predicates {
+		extractedCols := expression.ExtractColumns(cond)
+		ok := true
+		for _, col := range extractedCols {
+			if !partitionSchema.Contains(col) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			condsToPush = append(condsToPush, cond)
+		} else {
+			ret = append(ret, cond)
+		}
+	}
+	_, _, err = p.baseLogicalPlan.PredicatePushDown(condsToPush)
+	return ret, p, errors.Trace(err)
+}
+
 // PredicatePushDown implements LogicalPlan PredicatePushDown interface.
 func (p *Limit) PredicatePushDown(predicates []expression.Expression) ([]expression.Expression, LogicalPlan, error) {
 	// Limit forbids any condition to push down.
@@ -106,6 +106,22 @@ func (p *PhysicalMergeJoin) ResolveIndices() {
 	}
 }
 
+// ResolveIndices implements Plan interface.
+func (p *PhysicalCrossJoin) ResolveIndices() {
+	p.basePlan.ResolveIndices()
+	lSchema := p.children[0].Schema()
+	rSchema := p.children[1].Schema()
+	for _, expr := range p.LeftConditions {
+		expr.ResolveIndices(lSchema)
+	}
+	for _, expr := range p.RightConditions {
+		expr.ResolveIndices(rSchema)
+	}
+	for _, expr := range p.OtherConditions {
+		expr.ResolveIndices(expression.MergeSchema(lSchema, rSchema))
+	}
+}
+
 // ResolveIndices implements Plan interface.
 func (p *PhysicalIndexJoin) ResolveIndices() {
 	p.basePlan.ResolveIndices()
@@ -159,6 +175,14 @@ func (p *PhysicalIndexLookUpReader) ResolveIndices() {
 	p.indexPlan.ResolveIndices()
 }
 
+// ResolveIndices implements Plan interface.
+func (p *PhysicalIndexMergeReader) ResolveIndices() {
+	p.tablePlan.ResolveIndices()
+	for _, partialPlan := range p.partialPlans {
+		partialPlan.ResolveIndices()
+	}
+}
+
 // ResolveIndices implements Plan interface.
 func (p *Selection) ResolveIndices() {
 	p.basePlan.ResolveIndices()
@@ -193,6 +217,36 @@ func (p *PhysicalAggregation) ResolveIndices() {
 	}
 }
 
+func (w *WindowFuncDesc) resolveIndices(schema *expression.Schema) {
+	for _, arg := range w.Args {
+		arg.ResolveIndices(schema)
+	}
+}
+
+// ResolveIndices implements Plan interface.
+func (p *LogicalWindow) ResolveIndices() {
+	p.basePlan.ResolveIndices()
+	p.WindowFunc.resolveIndices(p.children[0].Schema())
+	for _, item := range p.PartitionBy {
+		item.Expr.ResolveIndices(p.children[0].Schema())
+	}
+	for _, item := range p.OrderBy {
+		item.Expr.ResolveIndices(p.children[0].Schema())
+	}
+}
+
+// ResolveIndices implements Plan interface.
+func (p *PhysicalWindow) ResolveIndices() {
+	p.basePlan.ResolveIndices()
+	p.WindowFunc.resolveIndices(p.children[0].Schema())
+	for _, item := range p.PartitionBy {
+		item.Expr.ResolveIndices(p.children[0].Schema())
+	}
+	for _, item := range p.OrderBy {
+		item.Expr.ResolveIndices(p.children[0].Schema())
+	}
+}
+
 // ResolveIndices implements Plan interface.
 func (p *Sort) ResolveIndices() {
 	p.basePlan.ResolveIndices()
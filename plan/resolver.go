@@ -86,6 +86,8 @@ type resolverContext struct {
 	fieldList []*ast.ResultField
 	// result fields collected in group by clause.
 	groupBy []*ast.ResultField
+	// common table expressions defined in the with clause, they can be referenced in this statement and its subqueries.
+	ctes []*ast.CommonTableExpression
 
 	// The join node stack is used by on condition to find out
 	// available tables to reference. On condition can only
@@ -119,6 +121,8 @@ type resolverContext struct {
 	inShow bool
 	// When visiting create/alter table statement.
 	inColumnOption bool
+	// When visiting the expression key part of an index.
+	inIndexExpr bool
 }
 
 // currentContext gets the current resolverContext.
@@ -195,8 +199,26 @@ func (nr *nameResolver) Enter(inNode ast.Node) (outNode ast.Node, skipChildren b
 	case *ast.CreateTableStmt:
 		nr.pushContext()
 		nr.currentContext().inCreateOrDropTable = true
+	case *ast.CreateViewStmt:
+		// The select statement of the view pushes its own context, so only the view name is skipped.
+		nr.pushContext()
+		nr.currentContext().inCreateOrDropTable = true
 	case *ast.ColumnOption:
 		nr.currentContext().inColumnOption = true
+	case *ast.IndexColName:
+		nr.currentContext().inIndexExpr = v.Expr != nil
+	case *ast.CommonTableExpression:
+		ctx := nr.currentContext()
+		for _, cte := range ctx.ctes {
+			if cte.Name.L == v.Name.L {
+				nr.Err = ErrNonuniqTable.GenByArgs(v.Name.O)
+				return inNode, true
+			}
+		}
+		// A recursive common table expression can be referenced in its own query.
+		if v.IsRecursive {
+			ctx.ctes = append(ctx.ctes, v)
+		}
 	case *ast.DeleteStmt:
 		nr.pushContext()
 	case *ast.DeleteTableList:
@@ -277,8 +299,20 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 		nr.popContext()
 	case *ast.CreateTableStmt:
 		nr.popContext()
+	case *ast.CreateViewStmt:
+		nr.popContext()
 	case *ast.ColumnOption:
 		nr.currentContext().inColumnOption = false
+	case *ast.IndexColName:
+		nr.currentContext().inIndexExpr = false
+	case *ast.CommonTableExpression:
+		if !v.IsRecursive {
+			ctx := nr.currentContext()
+			ctx.ctes = append(ctx.ctes, v)
+		}
+		if len(v.ColNameList) > 0 && len(v.ColNameList) != len(v.Query.GetResultFields()) {
+			nr.Err = errors.Trace(ErrViewWrongList)
+		}
 	case *ast.DeleteTableList:
 		nr.currentContext().inDeleteTableList = false
 	case *ast.DoStmt:
@@ -362,8 +396,11 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 // handleTableName looks up and sets the schema information and result fields for table name.
 func (nr *nameResolver) handleTableName(tn *ast.TableName) {
 	if tn.Schema.L == "" {
-		sessionVars := nr.Ctx.GetSessionVars()
-		if sessionVars.CurrentDB == "" {
+		if cte := nr.findCTE(tn.Name); cte != nil {
+			nr.handleCTEName(tn, cte)
+			return
+		}
+		if nr.DefaultSchema.L == "" {
 			nr.Err = errors.Trace(ErrNoDB)
 			return
 		}
@@ -431,6 +468,65 @@ func (nr *nameResolver) handleTableName(tn *ast.TableName) {
 	return
 }
 
+// findCTE looks up the common table expression with the name from top to bottom in the context stack.
+func (nr *nameResolver) findCTE(name model.CIStr) *ast.CommonTableExpression {
+	for i := len(nr.contextStack) - 1; i >= 0; i-- {
+		ctes := nr.contextStack[i].ctes
+		for j := len(ctes) - 1; j >= 0; j-- {
+			if ctes[j].Name.L == name.L {
+				return ctes[j]
+			}
+		}
+	}
+	return nil
+}
+
+// handleCTEName sets the result fields for a table name which refers to a common table expression.
+func (nr *nameResolver) handleCTEName(tn *ast.TableName, cte *ast.CommonTableExpression) {
+	rfs := cte.Query.GetResultFields()
+	if rfs == nil {
+		// The query of the common table expression is being resolved, so this is a recursive reference,
+		// whose result fields come from the first query block of the union.
+		union, ok := cte.Query.Query.(*ast.UnionStmt)
+		if !ok {
+			nr.Err = ErrCTERecursiveRequiresUnion.GenByArgs(cte.Name.O)
+			return
+		}
+		rfs = union.SelectList.Selects[0].GetResultFields()
+		if rfs == nil {
+			nr.Err = ErrCTERecursiveRequiresNonRecursiveFirst.GenByArgs(cte.Name.O)
+			return
+		}
+	}
+	if len(cte.ColNameList) > 0 && len(cte.ColNameList) != len(rfs) {
+		nr.Err = errors.Trace(ErrViewWrongList)
+		return
+	}
+	tblInfo := &model.TableInfo{Name: tn.Name}
+	fields := make([]*ast.ResultField, 0, len(rfs))
+	for i, rf := range rfs {
+		name := rf.ColumnAsName
+		if len(cte.ColNameList) > 0 {
+			name = cte.ColNameList[i]
+		} else if name.L == "" {
+			name = rf.Column.Name
+		}
+		col := &model.ColumnInfo{Name: name, Offset: i, State: model.StatePublic}
+		col.FieldType = *rf.Expr.GetType()
+		expr := &ast.ValueExpr{}
+		expr.SetType(&col.FieldType)
+		fields = append(fields, &ast.ResultField{
+			Column:       col,
+			ColumnAsName: name,
+			Table:        tblInfo,
+			Expr:         expr,
+			TableName:    tn,
+		})
+	}
+	tn.CTE = cte
+	tn.SetResultFields(fields)
+}
+
 // handleTableSources checks name duplication
 // and puts the table source in current resolverContext.
 // Note:
@@ -506,9 +602,9 @@ func (nr *nameResolver) handleColumnName(cn *ast.ColumnNameExpr) {
 		return
 	}
 
-	if ctx.inColumnOption {
-		// In column option, only columns in current create table statement
-		// is available. But we check it in ddl/ddl_api.go.
+	if ctx.inColumnOption || ctx.inIndexExpr {
+		// In column option and the expression key part of an index, only columns in current
+		// create table statement is available. But we check it in ddl/ddl_api.go.
 		return
 	}
 
@@ -964,6 +1060,8 @@ func (nr *nameResolver) fillShowFields(s *ast.ShowStmt) {
 		names = []string{"Table", "Create Table"}
 	case ast.ShowCreateDatabase:
 		names = []string{"Database", "Create Database"}
+	case ast.ShowCreateView:
+		names = []string{"View", "Create View", "character_set_client", "collation_connection"}
 	case ast.ShowGrants:
 		names = []string{fmt.Sprintf("Grants for %s", s.User)}
 	case ast.ShowTriggers:
@@ -179,6 +179,33 @@ func (p *Projection) prepareStatsProfile() *statsProfile {
 	return p.profile
 }
 
+func (p *LogicalWindow) prepareStatsProfile() *statsProfile {
+	childProfile := p.children[0].(LogicalPlan).prepareStatsProfile()
+	p.profile = &statsProfile{
+		count:       childProfile.count,
+		cardinality: make([]float64, 0, p.schema.Len()),
+	}
+	p.profile.cardinality = append(p.profile.cardinality, childProfile.cardinality...)
+	p.profile.cardinality = append(p.profile.cardinality, childProfile.count)
+	return p.profile
+}
+
+func (p *LogicalCTE) prepareStatsProfile() *statsProfile {
+	// The number of rows produced by the recursive query blocks can't be estimated, so we use the seed's count.
+	count := float64(1)
+	if profile := p.CTE.SeedPlan.statsProfile(); profile != nil {
+		count = profile.count
+	}
+	p.profile = &statsProfile{
+		count:       count,
+		cardinality: make([]float64, p.schema.Len()),
+	}
+	for i := range p.profile.cardinality {
+		p.profile.cardinality[i] = count
+	}
+	return p.profile
+}
+
 func (p *LogicalAggregation) prepareStatsProfile() *statsProfile {
 	childProfile := p.children[0].(LogicalPlan).prepareStatsProfile()
 	var gbyCols []*expression.Column
@@ -26,7 +26,7 @@ func ToString(p Plan) string {
 
 func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 	switch in.(type) {
-	case *LogicalJoin, *Union, *PhysicalHashJoin, *PhysicalHashSemiJoin, *LogicalApply, *PhysicalApply, *PhysicalMergeJoin, *PhysicalIndexJoin:
+	case *LogicalJoin, *Union, *PhysicalHashJoin, *PhysicalHashSemiJoin, *LogicalApply, *PhysicalApply, *PhysicalMergeJoin, *PhysicalIndexJoin, *PhysicalCrossJoin:
 		idxs = append(idxs, len(strs))
 	}
 
@@ -88,6 +88,20 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 			r := eq.GetArgs()[1].String()
 			str += fmt.Sprintf("(%s,%s)", l, r)
 		}
+	case *PhysicalCrossJoin:
+		last := len(idxs) - 1
+		idx := idxs[last]
+		children := strs[idx:]
+		strs = strs[:idx]
+		idxs = idxs[:last]
+		if x.InnerChildIdx == 0 {
+			str = "RightCrossJoin{" + strings.Join(children, "->") + "}"
+		} else {
+			str = "LeftCrossJoin{" + strings.Join(children, "->") + "}"
+		}
+		if x.Broadcast {
+			str = "Broadcast" + str
+		}
 	case *LogicalApply, *PhysicalApply:
 		last := len(idxs) - 1
 		idx := idxs[last]
@@ -162,6 +176,16 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 			}
 		}
 		str += ")"
+	case *LogicalWindow:
+		str = fmt.Sprintf("Window(%s)", x.WindowFunc)
+	case *PhysicalWindow:
+		str = fmt.Sprintf("Window(%s)", x.WindowFunc)
+	case *LogicalCTE:
+		str = fmt.Sprintf("CTE(%s)", x.CTE.Name)
+	case *PhysicalCTE:
+		str = fmt.Sprintf("CTE(%s)", x.CTE.Name)
+	case *CTETable:
+		str = fmt.Sprintf("CTETable(%s)", x.CTE.Name)
 	case *Cache:
 		str = "Cache"
 	case *PhysicalTableReader:
@@ -170,6 +194,12 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 		str = fmt.Sprintf("IndexReader(%s)", ToString(x.indexPlan))
 	case *PhysicalIndexLookUpReader:
 		str = fmt.Sprintf("IndexLookUp(%s, %s)", ToString(x.indexPlan), ToString(x.tablePlan))
+	case *PhysicalIndexMergeReader:
+		partials := make([]string, 0, len(x.partialPlans))
+		for _, partialPlan := range x.partialPlans {
+			partials = append(partials, ToString(partialPlan))
+		}
+		str = fmt.Sprintf("IndexMerge(%s, [%s], %s)", x.Type, strings.Join(partials, ", "), ToString(x.tablePlan))
 	case *PhysicalUnionScan:
 		str = fmt.Sprintf("UnionScan(%s)", x.Conditions)
 	case *PhysicalIndexJoin:
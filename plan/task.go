@@ -175,6 +175,33 @@ func (p *PhysicalHashJoin) attach2Task(tasks ...task) task {
 	}
 }
 
+func (p *PhysicalCrossJoin) getCost(lCnt, rCnt float64) float64 {
+	outerCnt, innerCnt := lCnt, rCnt
+	if p.InnerChildIdx == 0 {
+		outerCnt, innerCnt = rCnt, lCnt
+	}
+	cst := outerCnt + innerCnt*memoryFactor
+	if p.Broadcast {
+		return cst + outerCnt*innerCnt*cpuFactor/float64(p.Concurrency)
+	}
+	return cst + outerCnt*innerCnt*cpuFactor
+}
+
+func (p *PhysicalCrossJoin) attach2Task(tasks ...task) task {
+	// The broadcast variant is only used when the inner side is small.
+	if p.Broadcast && tasks[p.InnerChildIdx].count() > crossJoinBroadcastThreshold {
+		return invalidTask
+	}
+	lTask := finishCopTask(tasks[0].copy(), p.ctx, p.allocator)
+	rTask := finishCopTask(tasks[1].copy(), p.ctx, p.allocator)
+	np := p.Copy()
+	np.SetChildren(lTask.plan(), rTask.plan())
+	return &rootTask{
+		p:   np,
+		cst: lTask.cost() + rTask.cost() + p.getCost(lTask.count(), rTask.count()),
+	}
+}
+
 func (p *PhysicalMergeJoin) getCost(lCnt, rCnt float64) float64 {
 	return lCnt + rCnt
 }
@@ -237,9 +264,18 @@ func finishCopTask(task task, ctx context.Context, allocator *idAllocator) task
 		p.profile = t.tablePlan.statsProfile()
 		newTask.p = p
 	}
+	recordCost(newTask)
 	return newTask
 }
 
+// recordCost records the cost of the root task on its top plan, so it can be explained.
+// The cost of a cop task is only completed when it's finished, so it's recorded on the reader.
+func recordCost(t task) {
+	if rt, ok := t.(*rootTask); ok && rt.p != nil {
+		rt.p.setEstimatedCost(rt.cst)
+	}
+}
+
 // rootTask is the final sink node of a plan graph. It should be a single goroutine on tidb.
 type rootTask struct {
 	p   PhysicalPlan
@@ -45,3 +45,47 @@ func (a *AggregateFuncExtractor) Leave(n ast.Node) (ast.Node, bool) {
 	}
 	return n, true
 }
+
+// WindowFuncExtractor visits Expr tree.
+// It collects WindowFuncExpr, the nested window functions are not collected.
+type WindowFuncExtractor struct {
+	// WindowFuncs is the collected WindowFuncExprs.
+	WindowFuncs []*ast.WindowFuncExpr
+}
+
+// Enter implements Visitor interface.
+func (a *WindowFuncExtractor) Enter(n ast.Node) (ast.Node, bool) {
+	switch v := n.(type) {
+	case *ast.WindowFuncExpr:
+		a.WindowFuncs = append(a.WindowFuncs, v)
+		return n, true
+	case *ast.SelectStmt, *ast.UnionStmt:
+		return n, true
+	}
+	return n, false
+}
+
+// Leave implements Visitor interface.
+func (a *WindowFuncExtractor) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+// cteRefChecker visits a query block and checks whether it refers to the given common table expression.
+type cteRefChecker struct {
+	cte *ast.CommonTableExpression
+	// hasRef is true if a table name which refers to cte is found.
+	hasRef bool
+}
+
+// Enter implements Visitor interface.
+func (c *cteRefChecker) Enter(n ast.Node) (ast.Node, bool) {
+	if tn, ok := n.(*ast.TableName); ok && tn.CTE == c.cte {
+		c.hasRef = true
+	}
+	return n, c.hasRef
+}
+
+// Leave implements Visitor interface.
+func (c *cteRefChecker) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
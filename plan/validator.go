@@ -154,7 +154,7 @@ func isConstraintKeyTp(constraints []*ast.Constraint, colDef *ast.ColumnDef) boo
 		}
 		// If the constraint as follows: primary key(c1, c2)
 		// we only support c1 column can be auto_increment.
-		if colDef.Name.Name.L != c.Keys[0].Column.Name.L {
+		if c.Keys[0].Column == nil || colDef.Name.Name.L != c.Keys[0].Column.Name.L {
 			continue
 		}
 		switch c.Tp {
@@ -277,6 +277,13 @@ func (v *validator) checkCreateTableGrammar(stmt *ast.CreateTableStmt) {
 				v.err = err
 				return
 			}
+			if hasExpressionKeyPart(constraint.Keys) {
+				v.err = ddl.ErrFunctionalIndexPrimaryKey
+				return
+			}
+		case ast.ConstraintSpatial:
+			v.err = ddl.ErrUnsupportedSpatialIndex
+			return
 		}
 	}
 }
@@ -310,6 +317,10 @@ func (v *validator) checkCreateIndexGrammar(stmt *ast.CreateIndexStmt) {
 		v.err = ddl.ErrWrongTableName.GenByArgs(tName)
 		return
 	}
+	if stmt.Spatial {
+		v.err = ddl.ErrUnsupportedSpatialIndex
+		return
+	}
 	v.err = checkIndexInfo(stmt.IndexName, stmt.IndexColNames)
 	return
 }
@@ -349,6 +360,14 @@ func (v *validator) checkAlterTableGrammar(stmt *ast.AlterTableStmt) {
 				if v.err != nil {
 					return
 				}
+			case ast.ConstraintPrimaryKey:
+				if hasExpressionKeyPart(spec.Constraint.Keys) {
+					v.err = ddl.ErrFunctionalIndexPrimaryKey
+					return
+				}
+			case ast.ConstraintSpatial:
+				v.err = ddl.ErrUnsupportedSpatialIndex
+				return
 			default:
 				// Nothing to do now.
 			}
@@ -368,8 +387,14 @@ func (v *validator) checkAlterTableGrammar(stmt *ast.AlterTableStmt) {
 // checkDuplicateColumnName checks if index exists duplicated columns.
 func checkDuplicateColumnName(indexColNames []*ast.IndexColName) error {
 	for i := 0; i < len(indexColNames); i++ {
+		if indexColNames[i].Column == nil {
+			continue
+		}
 		name1 := indexColNames[i].Column.Name
 		for j := i + 1; j < len(indexColNames); j++ {
+			if indexColNames[j].Column == nil {
+				continue
+			}
 			name2 := indexColNames[j].Column.Name
 			if name1.L == name2.L {
 				return infoschema.ErrColumnExists.GenByArgs(name2)
@@ -379,6 +404,16 @@ func checkDuplicateColumnName(indexColNames []*ast.IndexColName) error {
 	return nil
 }
 
+// hasExpressionKeyPart checks if any key part of the index is an expression.
+func hasExpressionKeyPart(indexColNames []*ast.IndexColName) bool {
+	for _, col := range indexColNames {
+		if col.Expr != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // checkIndexInfo checks index name and index column names.
 func checkIndexInfo(indexName string, indexColNames []*ast.IndexColName) error {
 	if strings.EqualFold(indexName, mysql.PrimaryKeyName) {
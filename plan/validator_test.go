@@ -18,6 +18,7 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/terror"
@@ -136,6 +137,9 @@ func (s *testValidatorSuite) TestValidator(c *C) {
 		{"alter table t change column a `a ` int", true, errors.New("[ddl:1166]Incorrect column name 'a '")},
 		{"create index idx on `t ` (a)", true, errors.New("[ddl:1103]Incorrect table name 't '")},
 		{"create index idx on  `` (a)", true, errors.New("[ddl:1103]Incorrect table name ''")},
+		{"create spatial index idx on t (g)", true, ddl.ErrUnsupportedSpatialIndex},
+		{"create table t (g geometry not null, spatial key idx (g))", true, ddl.ErrUnsupportedSpatialIndex},
+		{"alter table t add spatial index (g)", true, ddl.ErrUnsupportedSpatialIndex},
 
 		// issue 3844
 		{`create table t (a set("a, b", "c, d"))`, true, errors.New("[types:1367]Illegal set 'a, b' value found during parsing")},
@@ -14,6 +14,8 @@
 package privilege
 
 import (
+	"crypto/tls"
+
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/auth"
@@ -35,8 +37,15 @@ type Manager interface {
 	// If table is "", only check global/db scope privileges.
 	// If table is not "", check global/db/table scope privileges.
 	RequestVerification(db, table, column string, priv mysql.PrivilegeType) bool
-	// ConnectionVerification verifies user privilege for connection.
-	ConnectionVerification(host, user string, auth, salt []byte) bool
+	// RequestVerificationWithUser verifies the privilege of the specified user for the request.
+	// It is used to check the privileges of the definer of a view.
+	RequestVerificationWithUser(db, table, column string, priv mysql.PrivilegeType, user *auth.UserIdentity) bool
+	// ConnectionVerification verifies user privilege for connection, and returns the user name and the host
+	// of the matched account.
+	// tlsState is the TLS state of the connection, it's nil if the connection doesn't use TLS.
+	ConnectionVerification(user, host string, auth, salt []byte, tlsState *tls.ConnectionState) (u string, h string, success bool)
+	// GetAuthPlugin returns the authentication plugin of the user used for connection.
+	GetAuthPlugin(user, host string) (string, bool)
 
 	// DBIsVisible returns true is the database is visible to current user.
 	DBIsVisible(db string) bool
@@ -14,6 +14,7 @@
 package privileges
 
 import (
+	"crypto/tls"
 	"fmt"
 	"strings"
 	"sync/atomic"
@@ -25,6 +26,7 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/sqlexec"
 	"github.com/pingcap/tidb/util/stringutil"
 	"github.com/pingcap/tidb/util/types"
@@ -50,12 +52,48 @@ type userRecord struct {
 	User       string // max length 16, primary key
 	Password   string // max length 41
 	Privileges mysql.PrivilegeType
+	SSLType    string // the SSL requirement of the user, see SSLType* constants.
+	// AuthPlugin is the authentication plugin of the user, the empty string means mysql_native_password.
+	AuthPlugin string
+	// AuthString is the authentication string of the plugin other than mysql_native_password,
+	// whose password is kept in the Password column.
+	AuthString string
 
 	// patChars is compiled from Host, cached for pattern match performance.
 	patChars []byte
 	patTypes []byte
 }
 
+// The values of the ssl_type column in mysql.user table.
+const (
+	// SSLTypeNone means the user doesn't require secure connection.
+	SSLTypeNone = ""
+	// SSLTypeAny means the user requires TLS connection.
+	SSLTypeAny = "ANY"
+	// SSLTypeX509 means the user requires TLS connection with a valid client certificate.
+	SSLTypeX509 = "X509"
+)
+
+// checkSSL checks whether the connection satisfies the SSL requirement of the user.
+func (record *userRecord) checkSSL(tlsState *tls.ConnectionState) bool {
+	switch record.SSLType {
+	case SSLTypeAny:
+		return tlsState != nil
+	case SSLTypeX509:
+		// The client certificate has been verified during TLS handshake if it's given.
+		return tlsState != nil && len(tlsState.VerifiedChains) > 0
+	}
+	return true
+}
+
+// authString returns the authentication string checked by the authentication plugin of the user.
+func (record *userRecord) authString() string {
+	if len(record.AuthPlugin) == 0 || record.AuthPlugin == auth.MySQLNativePassword {
+		return record.Password
+	}
+	return record.AuthString
+}
+
 type dbRecord struct {
 	Host       string
 	DB         string
@@ -149,7 +187,7 @@ func noSuchTable(err error) bool {
 
 // LoadUserTable loads the mysql.user table from database.
 func (p *MySQLPrivilege) LoadUserTable(ctx context.Context) error {
-	return p.loadTable(ctx, "select Host,User,Password,Select_priv,Insert_priv,Update_priv,Delete_priv,Create_priv,Drop_priv,Process_priv,Grant_priv,References_priv,Alter_priv,Show_db_priv,Super_priv,Execute_priv,Index_priv,Create_user_priv,Trigger_priv from mysql.user order by host, user;", p.decodeUserTableRow)
+	return p.loadTable(ctx, "select Host,User,Password,Select_priv,Insert_priv,Update_priv,Delete_priv,Create_priv,Drop_priv,Process_priv,Grant_priv,References_priv,Alter_priv,Show_db_priv,Super_priv,Execute_priv,Index_priv,Create_user_priv,Trigger_priv,ssl_type,plugin,authentication_string from mysql.user order by host, user;", p.decodeUserTableRow)
 }
 
 // LoadDBTable loads the mysql.db table from database.
@@ -209,6 +247,12 @@ func (p *MySQLPrivilege) decodeUserTableRow(row *ast.Row, fs []*ast.ResultField)
 			value.patChars, value.patTypes = stringutil.CompilePattern(value.Host, '\\')
 		case f.ColumnAsName.L == "password":
 			value.Password = d.GetString()
+		case f.ColumnAsName.L == "ssl_type":
+			value.SSLType = d.GetMysqlEnum().String()
+		case f.ColumnAsName.L == "plugin":
+			value.AuthPlugin = d.GetString()
+		case f.ColumnAsName.L == "authentication_string":
+			value.AuthString = d.GetString()
 		case d.Kind() == types.KindMysqlEnum:
 			ed := d.GetMysqlEnum()
 			if ed.String() != "Y" {
@@ -14,6 +14,7 @@
 package privileges
 
 import (
+	"crypto/tls"
 	"strings"
 
 	"github.com/ngaut/log"
@@ -72,51 +73,72 @@ func (p *UserPrivileges) RequestVerification(db, table, column string, priv mysq
 	return mysqlPriv.RequestVerification(p.user, p.host, db, table, column, priv)
 }
 
-// ConnectionVerification implements the Manager interface.
-func (p *UserPrivileges) ConnectionVerification(user, host string, authentication, salt []byte) bool {
-	if SkipWithGrant {
-		p.user = user
-		p.host = host
+// RequestVerificationWithUser implements the Manager interface.
+func (p *UserPrivileges) RequestVerificationWithUser(db, table, column string, priv mysql.PrivilegeType, user *auth.UserIdentity) bool {
+	if !Enable || SkipWithGrant {
 		return true
 	}
 
-	mysqlPriv := p.Handle.Get()
-	record := mysqlPriv.connectionVerification(user, host)
-	if record == nil {
-		log.Errorf("Get user privilege record fail: user %v, host %v", user, host)
+	if user == nil {
 		return false
 	}
 
-	pwd := record.Password
-	if len(pwd) != 0 && len(pwd) != mysql.PWDHashLen+1 {
-		log.Errorf("User [%s] password from SystemDB not like a sha1sum", user)
-		return false
+	// Skip check for INFORMATION_SCHEMA database.
+	if strings.EqualFold(db, "INFORMATION_SCHEMA") {
+		return true
 	}
 
-	// empty password
-	if len(pwd) == 0 && len(authentication) == 0 {
+	mysqlPriv := p.Handle.Get()
+	return mysqlPriv.RequestVerification(user.Username, user.Hostname, db, table, column, priv)
+}
+
+// ConnectionVerification implements the Manager interface.
+func (p *UserPrivileges) ConnectionVerification(user, host string, authentication, salt []byte, tlsState *tls.ConnectionState) (u string, h string, success bool) {
+	if SkipWithGrant {
 		p.user = user
 		p.host = host
-		return true
+		return user, host, true
 	}
 
-	if len(pwd) == 0 || len(authentication) == 0 {
-		return false
+	mysqlPriv := p.Handle.Get()
+	record := mysqlPriv.connectionVerification(user, host)
+	if record == nil {
+		log.Errorf("Get user privilege record fail: user %v, host %v", user, host)
+		return "", "", false
 	}
 
-	hpwd, err := auth.DecodePassword(pwd)
-	if err != nil {
-		log.Errorf("Decode password string error %v", err)
-		return false
+	if !record.checkSSL(tlsState) {
+		log.Errorf("User [%s] requires SSL type '%s' for connection", user, record.SSLType)
+		return "", "", false
 	}
 
-	if !auth.CheckScrambledPassword(salt, hpwd, authentication) {
-		return false
+	plugin, ok := auth.GetAuthPlugin(record.AuthPlugin)
+	if !ok {
+		log.Errorf("User [%s] uses unknown authentication plugin %s", user, record.AuthPlugin)
+		return "", "", false
+	}
+	if !plugin.Authenticate(user, record.authString(), authentication, salt) {
+		return "", "", false
 	}
 
 	p.user = user
 	p.host = host
-	return true
+	return record.User, record.Host, true
+}
+
+// GetAuthPlugin implements the Manager interface.
+func (p *UserPrivileges) GetAuthPlugin(user, host string) (string, bool) {
+	if SkipWithGrant {
+		return "", false
+	}
+	record := p.Handle.Get().connectionVerification(user, host)
+	if record == nil {
+		return "", false
+	}
+	if len(record.AuthPlugin) == 0 {
+		return auth.MySQLNativePassword, true
+	}
+	return record.AuthPlugin, true
 }
 
 // DBIsVisible implements the Manager interface.
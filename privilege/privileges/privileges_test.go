@@ -14,12 +14,16 @@
 package privileges_test
 
 import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"testing"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/privilege"
@@ -275,6 +279,80 @@ func (s *testPrivilegeSuite) TestCheckAuthenticate(c *C) {
 	c.Assert(se.Auth(&auth.UserIdentity{Username: "u4", Hostname: "localhost"}, nil, nil), IsFalse)
 }
 
+func (s *testPrivilegeSuite) TestRequireSSL(c *C) {
+	defer testleak.AfterTest(c)()
+
+	se := newSession(c, s.store, s.dbName)
+	mustExec(c, se, `CREATE USER 'ssl1'@'localhost' REQUIRE SSL;`)
+	mustExec(c, se, `GRANT SELECT ON *.* TO 'ssl2'@'localhost' REQUIRE X509;`)
+	mustExec(c, se, `CREATE USER 'ssl3'@'localhost';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+	user1 := &auth.UserIdentity{Username: "ssl1", Hostname: "localhost"}
+	user2 := &auth.UserIdentity{Username: "ssl2", Hostname: "localhost"}
+	user3 := &auth.UserIdentity{Username: "ssl3", Hostname: "localhost"}
+	verifiedState := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}
+
+	se.SetTLSState(nil)
+	c.Assert(se.Auth(user1, nil, nil), IsFalse)
+	c.Assert(se.Auth(user2, nil, nil), IsFalse)
+	c.Assert(se.Auth(user3, nil, nil), IsTrue)
+	se.SetTLSState(&tls.ConnectionState{})
+	c.Assert(se.Auth(user1, nil, nil), IsTrue)
+	c.Assert(se.Auth(user2, nil, nil), IsFalse)
+	se.SetTLSState(verifiedState)
+	c.Assert(se.Auth(user1, nil, nil), IsTrue)
+	c.Assert(se.Auth(user2, nil, nil), IsTrue)
+
+	// ALTER USER and GRANT change the requirement of existing users.
+	se1 := newSession(c, s.store, s.dbName)
+	mustExec(c, se1, `ALTER USER 'ssl1'@'localhost' REQUIRE NONE;`)
+	mustExec(c, se1, `GRANT SELECT ON *.* TO 'ssl3'@'localhost' REQUIRE SSL;`)
+	mustExec(c, se1, `FLUSH PRIVILEGES;`)
+	se.SetTLSState(nil)
+	c.Assert(se.Auth(user1, nil, nil), IsTrue)
+	c.Assert(se.Auth(user3, nil, nil), IsFalse)
+	mustExec(c, se1, "drop user 'ssl1'@'localhost', 'ssl2'@'localhost', 'ssl3'@'localhost'")
+}
+
+func (s *testPrivilegeSuite) TestAuthPlugin(c *C) {
+	defer testleak.AfterTest(c)()
+
+	se := newSession(c, s.store, s.dbName)
+	mustExec(c, se, `CREATE USER 'sha2'@'localhost' IDENTIFIED WITH caching_sha2_password BY 'abc';`)
+	mustExec(c, se, `CREATE USER 'sha256'@'localhost' IDENTIFIED WITH 'sha256_password' BY 'abc';`)
+	mustExec(c, se, `CREATE USER 'native'@'localhost' IDENTIFIED BY 'abc';`)
+	_, err := se.Execute(`CREATE USER 'unknown'@'localhost' IDENTIFIED WITH no_such_plugin;`)
+	c.Assert(executor.ErrPluginIsNotLoaded.Equal(err), IsTrue, Commentf("%v", err))
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+	sha2 := &auth.UserIdentity{Username: "sha2", Hostname: "localhost"}
+	sha256 := &auth.UserIdentity{Username: "sha256", Hostname: "localhost"}
+	native := &auth.UserIdentity{Username: "native", Hostname: "localhost"}
+	salt := []byte("0123456789abcdefghij")
+
+	c.Assert(se.AuthPlugin(sha2), Equals, auth.CachingSHA2Password)
+	c.Assert(se.AuthPlugin(sha256), Equals, auth.SHA256Password)
+	c.Assert(se.AuthPlugin(native), Equals, auth.MySQLNativePassword)
+	c.Assert(se.AuthPlugin(&auth.UserIdentity{Username: "unknown", Hostname: "localhost"}), Equals, "")
+	c.Assert(se.Auth(sha2, scrambleSHA2Password(salt, "abc"), salt), IsTrue)
+	c.Assert(se.Auth(sha2, scrambleSHA2Password(salt, "abd"), salt), IsFalse)
+	c.Assert(se.Auth(sha256, []byte("abc"), salt), IsTrue)
+	c.Assert(se.Auth(sha256, []byte("abd"), salt), IsFalse)
+	// The scramble of mysql_native_password is rejected by the SHA2 plugins.
+	c.Assert(se.Auth(sha2, scramblePassword(salt, "abc"), salt), IsFalse)
+	c.Assert(se.Auth(native, scramblePassword(salt, "abc"), salt), IsTrue)
+
+	// SET PASSWORD keeps the plugin, ALTER USER changes it.
+	se1 := newSession(c, s.store, s.dbName)
+	mustExec(c, se1, `SET PASSWORD FOR 'sha2'@'localhost' = 'xyz';`)
+	mustExec(c, se1, `ALTER USER 'sha256'@'localhost' IDENTIFIED BY 'xyz';`)
+	mustExec(c, se1, `FLUSH PRIVILEGES;`)
+	c.Assert(se.AuthPlugin(sha2), Equals, auth.CachingSHA2Password)
+	c.Assert(se.Auth(sha2, scrambleSHA2Password(salt, "xyz"), salt), IsTrue)
+	c.Assert(se.AuthPlugin(sha256), Equals, auth.MySQLNativePassword)
+	c.Assert(se.Auth(sha256, scramblePassword(salt, "xyz"), salt), IsTrue)
+	mustExec(c, se1, "drop user 'sha2'@'localhost', 'sha256'@'localhost', 'native'@'localhost'")
+}
+
 func (s *testPrivilegeSuite) TestInformationSchema(c *C) {
 	defer testleak.AfterTest(c)()
 
@@ -287,6 +365,66 @@ func (s *testPrivilegeSuite) TestInformationSchema(c *C) {
 	mustExec(c, se, `select * from information_schema.key_column_usage`)
 }
 
+func (s *testPrivilegeSuite) TestViewSecurity(c *C) {
+	defer testleak.AfterTest(c)()
+	rootSe := newSession(c, s.store, s.dbName)
+	mustExec(c, rootSe, `CREATE TABLE vt (a int);`)
+	mustExec(c, rootSe, `CREATE USER 'vdefiner'@'localhost';`)
+	mustExec(c, rootSe, `CREATE USER 'vinvoker'@'localhost';`)
+	mustExec(c, rootSe, `GRANT Select ON test.vt TO 'vdefiner'@'localhost';`)
+	mustExec(c, rootSe, `CREATE DEFINER = 'vdefiner'@'localhost' SQL SECURITY DEFINER VIEW v_definer AS SELECT a FROM vt;`)
+	mustExec(c, rootSe, `CREATE DEFINER = 'vdefiner'@'localhost' SQL SECURITY INVOKER VIEW v_invoker AS SELECT a FROM vt;`)
+	mustExec(c, rootSe, `GRANT Select ON test.v_definer TO 'vinvoker'@'localhost';`)
+	mustExec(c, rootSe, `GRANT Select ON test.v_invoker TO 'vinvoker'@'localhost';`)
+	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
+
+	se := newSession(c, s.store, s.dbName)
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "vinvoker", Hostname: "localhost"}, nil, nil), IsTrue)
+	// The table is read with the privileges of the definer.
+	mustExec(c, se, `SELECT * FROM v_definer;`)
+	// The table is read with the privileges of the invoker.
+	_, err := se.Execute(`SELECT * FROM v_invoker;`)
+	c.Assert(err, NotNil)
+	_, err = se.Execute(`SELECT * FROM vt;`)
+	c.Assert(err, NotNil)
+
+	mustExec(c, rootSe, `REVOKE Select ON test.vt FROM 'vdefiner'@'localhost';`)
+	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
+	_, err = se.Execute(`SELECT * FROM v_definer;`)
+	c.Assert(err, NotNil)
+}
+
+func (s *testPrivilegeSuite) TestViewDefiner(c *C) {
+	defer testleak.AfterTest(c)()
+	rootSe := newSession(c, s.store, s.dbName)
+	mustExec(c, rootSe, `CREATE TABLE vdt (a int);`)
+	mustExec(c, rootSe, `CREATE USER 'vcreator'@'%';`)
+	mustExec(c, rootSe, `GRANT Create, Select ON test.* TO 'vcreator'@'%';`)
+	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
+
+	se := newSession(c, s.store, s.dbName)
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "vcreator", Hostname: "localhost"}, nil, nil), IsTrue)
+	// The default definer is the matched account rather than the host of the connection.
+	mustExec(c, se, `CREATE VIEW v_default AS SELECT a FROM vdt;`)
+	rs, err := se.Execute(`SHOW CREATE VIEW v_default;`)
+	c.Assert(err, IsNil)
+	row, err := rs[0].Next()
+	c.Assert(err, IsNil)
+	c.Assert(row.Data[1].GetString(), Matches, ".*DEFINER=`vcreator`@`%`.*")
+	c.Assert(rs[0].Close(), IsNil)
+	mustExec(c, se, `CREATE DEFINER = 'vcreator'@'%' VIEW v_self AS SELECT a FROM vdt;`)
+	mustExec(c, se, `CREATE DEFINER = CURRENT_USER VIEW v_current AS SELECT a FROM vdt;`)
+
+	// The definer which isn't the current user requires SUPER privilege.
+	_, err = se.Execute(`CREATE DEFINER = 'root'@'%' VIEW v_root AS SELECT a FROM vdt;`)
+	c.Assert(err, NotNil)
+	_, err = se.Execute(`CREATE DEFINER = 'vcreator'@'localhost' VIEW v_root AS SELECT a FROM vdt;`)
+	c.Assert(err, NotNil)
+	mustExec(c, rootSe, `GRANT SUPER ON *.* TO 'vcreator'@'%';`)
+	mustExec(c, rootSe, `FLUSH PRIVILEGES;`)
+	mustExec(c, se, `CREATE DEFINER = 'root'@'%' VIEW v_root AS SELECT a FROM vdt;`)
+}
+
 func mustExec(c *C, se tidb.Session, sql string) {
 	_, err := se.Execute(sql)
 	c.Assert(err, IsNil)
@@ -307,3 +445,22 @@ func newSession(c *C, store kv.Storage, dbName string) tidb.Session {
 	mustExec(c, se, "use "+dbName)
 	return se
 }
+
+func scramblePassword(salt []byte, pwd string) []byte {
+	stage1 := auth.Sha1Hash([]byte(pwd))
+	scramble := auth.Sha1Hash(append(append([]byte{}, salt...), auth.Sha1Hash(stage1)...))
+	for i := range scramble {
+		scramble[i] ^= stage1[i]
+	}
+	return scramble
+}
+
+func scrambleSHA2Password(salt []byte, pwd string) []byte {
+	stage1 := sha256.Sum256([]byte(pwd))
+	stage2 := sha256.Sum256(stage1[:])
+	scramble := sha256.Sum256(append(stage2[:], salt...))
+	for i := range scramble {
+		scramble[i] ^= stage1[i]
+	}
+	return scramble[:]
+}
@@ -35,8 +35,15 @@
 package server
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/binary"
+	"encoding/pem"
 	"fmt"
 	"io"
 	"net"
@@ -145,8 +152,9 @@ func (cc *clientConn) writeInitialHandshake() error {
 	data = append(data, cc.salt[0:8]...)
 	// filler [00]
 	data = append(data, 0)
-	// capability flag lower 2 bytes, using default capability here
-	data = append(data, byte(defaultCapability), byte(defaultCapability>>8))
+	capability := cc.server.capability()
+	// capability flag lower 2 bytes
+	data = append(data, byte(capability), byte(capability>>8))
 	// charset
 	if cc.collation == 0 {
 		cc.collation = uint8(mysql.DefaultCollationID)
@@ -155,8 +163,8 @@ func (cc *clientConn) writeInitialHandshake() error {
 	//status
 	data = append(data, dumpUint16(mysql.ServerStatusAutocommit)...)
 	// below 13 byte may not be used
-	// capability flag upper 2 bytes, using default capability here
-	data = append(data, byte(defaultCapability>>16), byte(defaultCapability>>24))
+	// capability flag upper 2 bytes
+	data = append(data, byte(capability>>16), byte(capability>>24))
 	// filler [0x15], for wireshark dump, value is 0x15
 	data = append(data, 0x15)
 	// reserved 10 [00]
@@ -166,7 +174,7 @@ func (cc *clientConn) writeInitialHandshake() error {
 	// filler [00]
 	data = append(data, 0)
 	// auth-plugin name
-	data = append(data, []byte("mysql_native_password")...)
+	data = append(data, []byte(auth.MySQLNativePassword)...)
 	data = append(data, 0)
 	err := cc.writePacket(data)
 	if err != nil {
@@ -189,6 +197,7 @@ type handshakeResponse41 struct {
 	User       string
 	DBName     string
 	Auth       []byte
+	AuthPlugin string
 	Attrs      map[string]string
 }
 
@@ -246,9 +255,11 @@ func handshakeResponseFromData(packet *handshakeResponse41, data []byte) (err er
 	}
 
 	if capability&mysql.ClientPluginAuth > 0 {
-		// TODO: Support mysql.ClientPluginAuth, skip it now
-		idx := bytes.IndexByte(data[pos:], 0)
-		pos = pos + idx + 1
+		// Some clients set the capability without sending the plugin name, see TestIssue1768.
+		if idx := bytes.IndexByte(data[pos:], 0); idx >= 0 {
+			packet.AuthPlugin = string(data[pos : pos+idx])
+			pos = pos + idx + 1
+		}
 	}
 
 	if capability&mysql.ClientConnectAtts > 0 {
@@ -291,17 +302,31 @@ func parseAttrs(data []byte) (map[string]string, error) {
 	return attrs, nil
 }
 
+// sslRequestLen is the length of SSL request packet, which is the beginning of handshake response
+// before user name: capability(4) + max packet size(4) + charset(1) + reserved(23).
+const sslRequestLen = 32
+
 func (cc *clientConn) readHandshakeResponse() error {
 	data, err := cc.readPacket()
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if len(data) == sslRequestLen && binary.LittleEndian.Uint32(data[:4])&mysql.ClientSSL > 0 {
+		// The client sends SSL request instead of handshake response, it sends the handshake
+		// response after the connection is upgraded to TLS.
+		if err = cc.upgradeToTLS(); err != nil {
+			return errors.Trace(err)
+		}
+		if data, err = cc.readPacket(); err != nil {
+			return errors.Trace(err)
+		}
+	}
 
 	var p handshakeResponse41
 	if err = handshakeResponseFromData(&p, data); err != nil {
 		return errors.Trace(err)
 	}
-	cc.capability = p.Capability & defaultCapability
+	cc.capability = p.Capability & cc.server.capability()
 	cc.user = p.User
 	cc.dbname = p.DBName
 	cc.collation = p.Collation
@@ -312,6 +337,10 @@ func (cc *clientConn) readHandshakeResponse() error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if tlsConn, ok := cc.conn.(*tls.Conn); ok {
+		tlsState := tlsConn.ConnectionState()
+		cc.ctx.SetTLSState(&tlsState)
+	}
 	if !cc.server.skipAuth() {
 		// Do Auth
 		addr := cc.conn.RemoteAddr().String()
@@ -319,9 +348,20 @@ func (cc *clientConn) readHandshakeResponse() error {
 		if err1 != nil {
 			return errors.Trace(errAccessDenied.GenByArgs(cc.user, addr, "YES"))
 		}
-		if !cc.ctx.Auth(&auth.UserIdentity{Username: cc.user, Hostname: host}, p.Auth, cc.salt) {
+		user := &auth.UserIdentity{Username: cc.user, Hostname: host}
+		clientPlugin, authData, err1 := cc.readAuthData(user, &p)
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		if !cc.ctx.Auth(user, authData, cc.salt) {
 			return errors.Trace(errAccessDenied.GenByArgs(cc.user, host, "YES"))
 		}
+		if clientPlugin == auth.CachingSHA2Password && len(authData) > 0 {
+			// Tell the client the scramble is verified, it doesn't need to send the password.
+			if err = cc.writePacket([]byte{0, 0, 0, 0, authMoreDataHeader, cachingSHA2FastAuthSuccess}); err != nil {
+				return errors.Trace(err)
+			}
+		}
 	}
 	if cc.dbname != "" {
 		err = cc.useDB(cc.dbname)
@@ -333,6 +373,136 @@ func (cc *clientConn) readHandshakeResponse() error {
 	return nil
 }
 
+const (
+	authSwitchRequestHeader byte = 0xfe
+	authMoreDataHeader      byte = 0x01
+
+	cachingSHA2FastAuthSuccess byte = 3
+	sha256RequestPublicKey     byte = 1
+)
+
+// readAuthData returns the authentication data for the authentication plugin of the user and the client
+// side plugin which generates it. If the client doesn't use the client side plugin the user requires,
+// the client is asked to switch to it.
+func (cc *clientConn) readAuthData(user *auth.UserIdentity, p *handshakeResponse41) (string, []byte, error) {
+	clientPlugin, authData := p.AuthPlugin, p.Auth
+	if len(clientPlugin) == 0 {
+		clientPlugin = auth.MySQLNativePassword
+	}
+	// If the user isn't found, the authentication data is checked as is and fails.
+	if plugin, ok := auth.GetAuthPlugin(cc.ctx.AuthPlugin(user)); ok && plugin.ClientPlugin() != clientPlugin {
+		if cc.capability&mysql.ClientPluginAuth == 0 {
+			return "", nil, errors.Trace(errNotSupportedAuth)
+		}
+		clientPlugin = plugin.ClientPlugin()
+		var err error
+		if authData, err = cc.writeAuthSwitchRequest(clientPlugin); err != nil {
+			return "", nil, errors.Trace(err)
+		}
+	}
+
+	switch clientPlugin {
+	case auth.SHA256Password:
+		password, err := cc.readSHA256Password(authData)
+		return clientPlugin, password, errors.Trace(err)
+	case auth.MySQLClearPassword:
+		return clientPlugin, bytes.TrimSuffix(authData, []byte{0}), nil
+	}
+	return clientPlugin, authData, nil
+}
+
+// writeAuthSwitchRequest asks the client to authenticate with the plugin and returns the new authentication data.
+func (cc *clientConn) writeAuthSwitchRequest(plugin string) ([]byte, error) {
+	data := make([]byte, 4, 4+1+len(plugin)+1+len(cc.salt)+1)
+	data = append(data, authSwitchRequestHeader)
+	data = append(data, plugin...)
+	data = append(data, 0)
+	data = append(data, cc.salt...)
+	data = append(data, 0)
+	if err := cc.writePacket(data); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := cc.flush(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	authData, err := cc.readPacket()
+	return authData, errors.Trace(err)
+}
+
+// readSHA256Password returns the plaintext password sent by the client of sha256_password. The password
+// is sent in plaintext on TLS connection, otherwise it's encrypted by the public key of the server:
+//
+//	CLIENT:  send(0x01) to request the public key if it doesn't have one
+//	SERVER:  send(0x01, public_key)
+//	CLIENT:  send(rsa_encrypt(xor(password\0, public_seed)))
+func (cc *clientConn) readSHA256Password(authData []byte) ([]byte, error) {
+	if _, ok := cc.conn.(*tls.Conn); ok {
+		return bytes.TrimSuffix(authData, []byte{0}), nil
+	}
+	// The empty password isn't encrypted.
+	if len(authData) == 0 || bytes.Equal(authData, []byte{0}) {
+		return nil, nil
+	}
+	key, err := cc.server.rsaKey()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if bytes.Equal(authData, []byte{sha256RequestPublicKey}) {
+		pubKey, err1 := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		if err1 != nil {
+			return nil, errors.Trace(err1)
+		}
+		data := make([]byte, 4, 256)
+		data = append(data, authMoreDataHeader)
+		data = append(data, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubKey})...)
+		if err = cc.writePacket(data); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err = cc.flush(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if authData, err = cc.readPacket(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	password, err := rsa.DecryptOAEP(sha1.New(), rand.Reader, key, authData, nil)
+	if err != nil {
+		return nil, errors.Trace(errAccessDenied.GenByArgs(cc.user, cc.conn.RemoteAddr().String(), "YES"))
+	}
+	for i := range password {
+		password[i] ^= cc.salt[i%len(cc.salt)]
+	}
+	return bytes.TrimSuffix(password, []byte{0}), nil
+}
+
+// upgradeToTLS does TLS handshake on the connection, then the packets are read and written through TLS.
+func (cc *clientConn) upgradeToTLS() error {
+	if cc.server.tlsConfig == nil {
+		return errors.Trace(errSSLNotEnabled)
+	}
+	// The client may have sent the TLS hello right after the SSL request, so
+	// read through the buffered reader to not lose any bytes already buffered.
+	tlsConn := tls.Server(&bufferedConn{Conn: cc.conn, rb: cc.pkt.rb}, cc.server.tlsConfig)
+	if err := tlsConn.Handshake(); err != nil {
+		return errors.Trace(err)
+	}
+	sequence := cc.pkt.sequence
+	cc.conn = tlsConn
+	cc.pkt = newPacketIO(tlsConn)
+	cc.pkt.sequence = sequence
+	return nil
+}
+
+// bufferedConn is a net.Conn that reads through a bufio.Reader.
+type bufferedConn struct {
+	net.Conn
+	rb *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.rb.Read(b)
+}
+
 // Run reads client query and writes query result to client in for loop, if there is a panic during query handling,
 // it will be recovered and log the panic error.
 // This function returns and the connection is closed if there is an IO error or there is a panic.
@@ -16,10 +16,17 @@ package server
 import (
 	"bufio"
 	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
 	"encoding/binary"
+	"encoding/pem"
+	"net"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/auth"
 )
 
 type ConnTestSuite struct{}
@@ -47,6 +54,7 @@ func (ts ConnTestSuite) TestHandshakeResponseFromData(c *C) {
 	err := handshakeResponseFromData(&p, data)
 	c.Assert(err, IsNil)
 	c.Assert(p.Capability&mysql.ClientConnectAtts, Equals, mysql.ClientConnectAtts)
+	c.Assert(p.AuthPlugin, Equals, auth.MySQLNativePassword)
 	eq := mapIdentical(p.Attrs, map[string]string{
 		"_client_version": "5.6.6-m9",
 		"_platform":       "x86_64",
@@ -74,6 +82,7 @@ func (ts ConnTestSuite) TestHandshakeResponseFromData(c *C) {
 	c.Assert(p.Capability&capability, Equals, capability)
 	c.Assert(p.User, Equals, "pam")
 	c.Assert(p.DBName, Equals, "test")
+	c.Assert(p.AuthPlugin, Equals, auth.MySQLNativePassword)
 }
 
 func (ts ConnTestSuite) TestIssue1768(c *C) {
@@ -107,6 +116,7 @@ func (ts ConnTestSuite) TestInitialHandshake(c *C) {
 	cc := &clientConn{
 		connectionID: 1,
 		salt:         []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F, 0x10},
+		server:       &Server{},
 		pkt: &packetIO{
 			wb: bufio.NewWriter(&outBuffer),
 		},
@@ -145,3 +155,59 @@ func mapBelong(m1, m2 map[string]string) bool {
 	}
 	return true
 }
+
+func (ts ConnTestSuite) TestSHA256Password(c *C) {
+	c.Parallel()
+	serverSide, clientSide := net.Pipe()
+	defer serverSide.Close()
+	defer clientSide.Close()
+	salt := []byte("0123456789abcdefghij")
+	cc := &clientConn{
+		conn:   serverSide,
+		pkt:    newPacketIO(serverSide),
+		server: &Server{},
+		salt:   salt,
+	}
+
+	// The client requests the public key and sends the password encrypted by it.
+	done := make(chan error, 1)
+	go func() {
+		pkt := newPacketIO(clientSide)
+		pkt.sequence = 1
+		data, err := pkt.readPacket()
+		if err != nil {
+			done <- err
+			return
+		}
+		block, _ := pem.Decode(data[1:])
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			done <- err
+			return
+		}
+		password := []byte("secret\x00")
+		for i := range password {
+			password[i] ^= salt[i%len(salt)]
+		}
+		encrypted, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, pub.(*rsa.PublicKey), password, nil)
+		if err != nil {
+			done <- err
+			return
+		}
+		err = pkt.writePacket(append(make([]byte, 4), encrypted...))
+		if err == nil {
+			err = pkt.flush()
+		}
+		done <- err
+	}()
+	cc.pkt.sequence = 1
+	password, err := cc.readSHA256Password([]byte{sha256RequestPublicKey})
+	c.Assert(err, IsNil)
+	c.Assert(<-done, IsNil)
+	c.Assert(string(password), Equals, "secret")
+
+	// The empty password isn't encrypted.
+	password, err = cc.readSHA256Password([]byte{0})
+	c.Assert(err, IsNil)
+	c.Assert(password, HasLen, 0)
+}
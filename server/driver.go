@@ -14,6 +14,7 @@
 package server
 
 import (
+	"crypto/tls"
 	"fmt"
 
 	"github.com/pingcap/tidb/util"
@@ -62,6 +63,9 @@ type QueryCtx interface {
 	// SetClientCapability sets client capability flags
 	SetClientCapability(uint32)
 
+	// SetTLSState sets the TLS state of the connection.
+	SetTLSState(*tls.ConnectionState)
+
 	// Prepare prepares a statement.
 	Prepare(sql string) (statement PreparedStatement, columns, params []*ColumnInfo, err error)
 
@@ -77,6 +81,9 @@ type QueryCtx interface {
 	// Auth verifies user's authentication.
 	Auth(user *auth.UserIdentity, auth []byte, salt []byte) bool
 
+	// AuthPlugin returns the authentication plugin of the user, it's empty if the user isn't found.
+	AuthPlugin(user *auth.UserIdentity) string
+
 	// ShowProcess shows the information about the session.
 	ShowProcess() util.ProcessInfo
 
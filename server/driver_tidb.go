@@ -14,6 +14,7 @@
 package server
 
 import (
+	"crypto/tls"
 	"fmt"
 
 	"github.com/juju/errors"
@@ -210,6 +211,11 @@ func (tc *TiDBContext) SetSessionManager(sm util.SessionManager) {
 	tc.session.SetSessionManager(sm)
 }
 
+// SetTLSState implements QueryCtx SetTLSState method.
+func (tc *TiDBContext) SetTLSState(tlsState *tls.ConnectionState) {
+	tc.session.SetTLSState(tlsState)
+}
+
 // SetClientCapability implements QueryCtx SetClientCapability method.
 func (tc *TiDBContext) SetClientCapability(flags uint32) {
 	tc.session.SetClientCapability(flags)
@@ -226,6 +232,11 @@ func (tc *TiDBContext) Auth(user *auth.UserIdentity, auth []byte, salt []byte) b
 	return tc.session.Auth(user, auth, salt)
 }
 
+// AuthPlugin implements QueryCtx AuthPlugin method.
+func (tc *TiDBContext) AuthPlugin(user *auth.UserIdentity) string {
+	return tc.session.AuthPlugin(user)
+}
+
 // FieldList implements QueryCtx FieldList method.
 func (tc *TiDBContext) FieldList(table string) (colums []*ColumnInfo, err error) {
 	rs, err := tc.Execute("SELECT * FROM `" + table + "` LIMIT 0")
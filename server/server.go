@@ -29,6 +29,11 @@
 package server
 
 import (
+	cryptorand "crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
 	"math/rand"
 	"net"
 	"sync"
@@ -41,6 +46,7 @@ import (
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/arena"
@@ -50,6 +56,8 @@ var (
 	baseConnID uint32
 )
 
+const rsaKeyBits = 2048
+
 var (
 	errUnknownFieldType  = terror.ClassServer.New(codeUnknownFieldType, "unknown field type")
 	errInvalidPayloadLen = terror.ClassServer.New(codeInvalidPayloadLen, "invalid payload length")
@@ -57,6 +65,8 @@ var (
 	errInvalidType       = terror.ClassServer.New(codeInvalidType, "invalid type")
 	errNotAllowedCommand = terror.ClassServer.New(codeNotAllowedCommand, "the used command is not allowed with this TiDB version")
 	errAccessDenied      = terror.ClassServer.New(codeAccessDenied, mysql.MySQLErrName[mysql.ErrAccessDenied])
+	errSSLNotEnabled     = terror.ClassServer.New(codeSSLNotEnabled, "SSL connection is not enabled on the server")
+	errNotSupportedAuth  = terror.ClassServer.New(codeNotSupportedAuth, mysql.MySQLErrName[mysql.ErrNotSupportedAuthMode])
 )
 
 // Server is the MySQL protocol server
@@ -67,6 +77,13 @@ type Server struct {
 	rwlock            *sync.RWMutex
 	concurrentLimiter *TokenLimiter
 	clients           map[uint32]*clientConn
+	tlsConfig         *tls.Config // it's nil if TLS isn't enabled.
+
+	// The RSA key is used to exchange password with sha256_password clients on insecure connection,
+	// it's generated the first time it's used.
+	rsaKeyOnce sync.Once
+	rsaKeyPriv *rsa.PrivateKey
+	rsaKeyErr  error
 
 	// When a critical error occurred, we don't want to exit the process, because there may be
 	// a supervisor automatically restart it, then new client connection will be created, but we can't server it.
@@ -131,7 +148,10 @@ func NewServer(cfg *config.Config, driver IDriver) (*Server, error) {
 		stopListenerCh:    make(chan struct{}, 1),
 	}
 
-	var err error
+	err := s.loadTLSConfig()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	if cfg.Socket != "" {
 		cfg.SkipAuth = true
 		if s.listener, err = net.Listen("unix", cfg.Socket); err == nil {
@@ -151,6 +171,60 @@ func NewServer(cfg *config.Config, driver IDriver) (*Server, error) {
 	return s, nil
 }
 
+// loadTLSConfig loads the certificates for TLS connections, TLS is enabled only if both
+// the certificate and the key are set. If the CA is set, the client certificates are
+// verified by it, which is required by the users created with "REQUIRE X509".
+func (s *Server) loadTLSConfig() error {
+	if s.cfg.SSLCert == "" || s.cfg.SSLKey == "" {
+		return nil
+	}
+	cert, err := tls.LoadX509KeyPair(s.cfg.SSLCert, s.cfg.SSLKey)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if s.cfg.SSLCA != "" {
+		caCert, err1 := ioutil.ReadFile(s.cfg.SSLCA)
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caCert) {
+			return errors.Errorf("failed to load CA certificate from %s", s.cfg.SSLCA)
+		}
+		tlsConfig.ClientCAs = certPool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	s.tlsConfig = tlsConfig
+	variable.SysVars["have_ssl"].Value = "YES"
+	variable.SysVars["have_openssl"].Value = "YES"
+	variable.SysVars["ssl_ca"].Value = s.cfg.SSLCA
+	variable.SysVars["ssl_cert"].Value = s.cfg.SSLCert
+	variable.SysVars["ssl_key"].Value = s.cfg.SSLKey
+	log.Infof("Secure connection is enabled with certificate [%s]", s.cfg.SSLCert)
+	return nil
+}
+
+// rsaKey returns the RSA key of the server.
+func (s *Server) rsaKey() (*rsa.PrivateKey, error) {
+	s.rsaKeyOnce.Do(func() {
+		s.rsaKeyPriv, s.rsaKeyErr = rsa.GenerateKey(cryptorand.Reader, rsaKeyBits)
+		if s.rsaKeyErr != nil {
+			log.Errorf("generate RSA key error %v", s.rsaKeyErr)
+		}
+	})
+	return s.rsaKeyPriv, errors.Trace(s.rsaKeyErr)
+}
+
+// capability returns the capability advertised to clients.
+func (s *Server) capability() uint32 {
+	capability := defaultCapability
+	if s.tlsConfig != nil {
+		capability |= mysql.ClientSSL
+	}
+	return capability
+}
+
 // Run runs the server.
 func (s *Server) Run() error {
 	// Start HTTP API to report tidb info such as TPS.
@@ -262,15 +336,18 @@ const (
 	codeInvalidPayloadLen = 2
 	codeInvalidSequence   = 3
 	codeInvalidType       = 4
+	codeSSLNotEnabled     = 5
 
 	codeNotAllowedCommand = 1148
 	codeAccessDenied      = mysql.ErrAccessDenied
+	codeNotSupportedAuth  = mysql.ErrNotSupportedAuthMode
 )
 
 func init() {
 	serverMySQLErrCodes := map[terror.ErrCode]uint16{
 		codeNotAllowedCommand: mysql.ErrNotAllowedCommand,
 		codeAccessDenied:      mysql.ErrAccessDenied,
+		codeNotSupportedAuth:  mysql.ErrNotSupportedAuthMode,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassServer] = serverMySQLErrCodes
 }
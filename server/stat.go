@@ -0,0 +1,58 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/tls"
+
+	"github.com/pingcap/tidb/sessionctx/variable"
+)
+
+var (
+	sslCipher  = "Ssl_cipher"
+	sslVersion = "Ssl_version"
+)
+
+var tlsVersionNames = map[uint16]string{
+	tls.VersionTLS10: "TLSv1",
+	tls.VersionTLS11: "TLSv1.1",
+	tls.VersionTLS12: "TLSv1.2",
+	tls.VersionTLS13: "TLSv1.3",
+}
+
+// tlsStatistics provides the status variables of the TLS connection.
+type tlsStatistics struct{}
+
+// GetScope gets the status variables scope.
+func (s tlsStatistics) GetScope(status string) variable.ScopeFlag {
+	// The TLS state belongs to the connection of the session.
+	return variable.ScopeSession
+}
+
+// Stats returns the TLS status variables, which are empty if the connection doesn't use TLS.
+func (s tlsStatistics) Stats(vars *variable.SessionVars) (map[string]interface{}, error) {
+	m := map[string]interface{}{
+		sslCipher:  "",
+		sslVersion: "",
+	}
+	if tlsState := vars.TLSConnectionState; tlsState != nil {
+		m[sslCipher] = tls.CipherSuiteName(tlsState.CipherSuite)
+		m[sslVersion] = tlsVersionNames[tlsState.Version]
+	}
+	return m, nil
+}
+
+func init() {
+	variable.RegisterStatistics(tlsStatistics{})
+}
@@ -10,11 +10,25 @@
 // distributed under the License is distributed on an "AS IS" BASIS,
 // See the License for the specific language governing permissions and
 // limitations under the License.
+//go:build !race
 // +build !race
 
 package server
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/go-sql-driver/mysql"
@@ -22,6 +36,7 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/util/auth"
 )
 
 type TidbTestSuite struct {
@@ -157,3 +172,180 @@ func (ts *TidbTestSuite) TestClientWithCollation(c *C) {
 	c.Parallel()
 	runTestClientWithCollation(c)
 }
+
+func (ts *TidbTestSuite) TestTLS(c *C) {
+	dir, err := ioutil.TempDir("", "tidb_tls_test")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	caCert, caKey := generateCert(c, dir, "ca", nil, nil)
+	generateCert(c, dir, "server", caCert, caKey)
+	generateCert(c, dir, "client", caCert, caKey)
+	cfg := &config.Config{
+		Addr:     ":4002",
+		LogLevel: "debug",
+		SSLCA:    filepath.Join(dir, "ca-cert.pem"),
+		SSLCert:  filepath.Join(dir, "server-cert.pem"),
+		SSLKey:   filepath.Join(dir, "server-key.pem"),
+	}
+	server, err := NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, IsNil)
+	go server.Run()
+	time.Sleep(time.Millisecond * 100)
+	defer server.Close()
+
+	clientCert, err := tls.LoadX509KeyPair(filepath.Join(dir, "client-cert.pem"), filepath.Join(dir, "client-key.pem"))
+	c.Assert(err, IsNil)
+	err = mysql.RegisterTLSConfig("tidb-test-tls", &tls.Config{InsecureSkipVerify: true})
+	c.Assert(err, IsNil)
+	err = mysql.RegisterTLSConfig("tidb-test-x509", &tls.Config{InsecureSkipVerify: true, Certificates: []tls.Certificate{clientCert}})
+	c.Assert(err, IsNil)
+	connect := func(user, tlsConfig string) (*sql.DB, error) {
+		db, err := sql.Open("mysql", getDSN(func(config *mysql.Config) {
+			config.User = user
+			config.Addr = "127.0.0.1:4002"
+			config.TLSConfig = tlsConfig
+		}))
+		c.Assert(err, IsNil)
+		if err = db.Ping(); err != nil {
+			db.Close()
+			return nil, err
+		}
+		return db, nil
+	}
+	status := func(db *sql.DB, name string) string {
+		var value string
+		err := db.QueryRow("show status like '"+name+"'").Scan(&name, &value)
+		c.Assert(err, IsNil)
+		return value
+	}
+
+	db, err := connect("root", "tidb-test-tls")
+	c.Assert(err, IsNil)
+	c.Assert(status(db, "Ssl_cipher"), Not(Equals), "")
+	c.Assert(status(db, "Ssl_version"), Matches, "TLSv1.*")
+	_, err = db.Exec("CREATE USER 'tls_ssl'@'%' REQUIRE SSL")
+	c.Assert(err, IsNil)
+	_, err = db.Exec("CREATE USER 'tls_x509'@'%' REQUIRE X509")
+	c.Assert(err, IsNil)
+	_, err = db.Exec("FLUSH PRIVILEGES")
+	c.Assert(err, IsNil)
+	db.Close()
+
+	db, err = connect("root", "")
+	c.Assert(err, IsNil)
+	c.Assert(status(db, "Ssl_cipher"), Equals, "")
+	db.Close()
+
+	_, err = connect("tls_ssl", "")
+	c.Assert(err, NotNil)
+	db, err = connect("tls_ssl", "tidb-test-tls")
+	c.Assert(err, IsNil)
+	db.Close()
+
+	_, err = connect("tls_x509", "tidb-test-tls")
+	c.Assert(err, NotNil)
+	db, err = connect("tls_x509", "tidb-test-x509")
+	c.Assert(err, IsNil)
+	db.Close()
+
+	db, err = connect("root", "")
+	c.Assert(err, IsNil)
+	_, err = db.Exec("DROP USER 'tls_ssl'@'%', 'tls_x509'@'%'")
+	c.Assert(err, IsNil)
+	db.Close()
+}
+
+// ldapStubPlugin is an LDAP-style authentication plugin which checks the plaintext password against
+// a stub directory. The authentication string is the DN of the user, it's derived from the user name
+// if it's empty.
+type ldapStubPlugin struct {
+	directory map[string]string
+}
+
+func (p ldapStubPlugin) Name() string {
+	return "authentication_ldap_stub"
+}
+
+func (p ldapStubPlugin) ClientPlugin() string {
+	return auth.MySQLClearPassword
+}
+
+func (p ldapStubPlugin) EncodePassword(pwd string) string {
+	return ""
+}
+
+func (p ldapStubPlugin) Authenticate(user, authString string, authData, salt []byte) bool {
+	dn := authString
+	if len(dn) == 0 {
+		dn = fmt.Sprintf("uid=%s,dc=example,dc=com", user)
+	}
+	pwd, ok := p.directory[dn]
+	return ok && len(authData) > 0 && pwd == string(authData)
+}
+
+func (ts *TidbTestSuite) TestAuthPlugin(c *C) {
+	c.Parallel()
+	err := auth.RegisterAuthPlugin(ldapStubPlugin{directory: map[string]string{
+		"uid=alice,dc=example,dc=com": "alice-pwd",
+		"uid=ldap2,dc=example,dc=com": "ldap2-pwd",
+	}})
+	c.Assert(err, IsNil)
+	runTests(c, nil, func(dbt *DBTest) {
+		dbt.mustExec(`CREATE USER 'ldap1'@'%' IDENTIFIED WITH authentication_ldap_stub AS 'uid=alice,dc=example,dc=com'`)
+		dbt.mustExec(`CREATE USER 'ldap2'@'%' IDENTIFIED WITH authentication_ldap_stub`)
+		dbt.mustExec(`FLUSH PRIVILEGES`)
+	})
+	connect := func(user, passwd string, allowCleartext bool) error {
+		db, err := sql.Open("mysql", getDSN(func(config *mysql.Config) {
+			config.User = user
+			config.Passwd = passwd
+			config.AllowCleartextPasswords = allowCleartext
+		}))
+		c.Assert(err, IsNil)
+		defer db.Close()
+		return db.Ping()
+	}
+
+	// The server asks the client to switch to mysql_clear_password.
+	c.Assert(connect("ldap1", "alice-pwd", true), IsNil)
+	c.Assert(connect("ldap1", "wrong-pwd", true), NotNil)
+	c.Assert(connect("ldap1", "alice-pwd", false), NotNil)
+	c.Assert(connect("ldap2", "ldap2-pwd", true), IsNil)
+	c.Assert(connect("ldap2", "", true), NotNil)
+
+	runTests(c, nil, func(dbt *DBTest) {
+		dbt.mustExec(`DROP USER 'ldap1'@'%', 'ldap2'@'%'`)
+	})
+}
+
+// generateCert generates a certificate and its private key in PEM files "<name>-cert.pem" and
+// "<name>-key.pem" under dir. The certificate is self-signed if parent is nil.
+func generateCert(c *C, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "TiDB test " + name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	c.Assert(err, IsNil)
+	cert, err := x509.ParseCertificate(der)
+	c.Assert(err, IsNil)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	c.Assert(err, IsNil)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, name+"-cert.pem"), certPEM, 0600), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, name+"-key.pem"), keyPEM, 0600), IsNil)
+	return cert, key
+}
@@ -18,6 +18,7 @@
 package tidb
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -38,6 +39,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/perfschema"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/privilege"
 	"github.com/pingcap/tidb/privilege/privileges"
 	"github.com/pingcap/tidb/sessionctx"
@@ -47,9 +49,11 @@ import (
 	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/store/localstore"
 	"github.com/pingcap/tidb/store/tikv/oracle"
+	"github.com/pingcap/tidb/userlock"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/kvcache"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-binlog"
 	goctx "golang.org/x/net/context"
@@ -72,10 +76,13 @@ type Session interface {
 	DropPreparedStmt(stmtID uint32) error
 	SetClientCapability(uint32) // Set client capability flags.
 	SetConnectionID(uint64)
+	SetTLSState(*tls.ConnectionState) // Set the TLS state of the connection, it's nil if TLS isn't used.
 	SetCollation(coID int) error
 	SetSessionManager(util.SessionManager)
 	Close()
 	Auth(user *auth.UserIdentity, auth []byte, salt []byte) bool
+	// AuthPlugin returns the authentication plugin of the user, it's empty if the user isn't found.
+	AuthPlugin(user *auth.UserIdentity) string
 	// Cancel the execution of current transaction.
 	Cancel()
 	ShowProcess() util.ProcessInfo
@@ -135,6 +142,8 @@ type session struct {
 	sessionVars    *variable.SessionVars
 	sessionManager util.SessionManager
 
+	preparedPlanCache *kvcache.SimpleLRUCache
+
 	statsCollector *statistics.SessionStatsCollector
 }
 
@@ -183,6 +192,10 @@ func (s *session) SetConnectionID(connectionID uint64) {
 	s.sessionVars.ConnectionID = connectionID
 }
 
+func (s *session) SetTLSState(tlsState *tls.ConnectionState) {
+	s.sessionVars.TLSConnectionState = tlsState
+}
+
 func (s *session) SetCollation(coID int) error {
 	cs, co, err := charset.GetCharsetInfoByID(coID)
 	if err != nil {
@@ -298,7 +311,8 @@ func (s *session) doCommitWithRetry() error {
 	}
 	err := s.doCommit()
 	if err != nil {
-		if s.isRetryableError(err) {
+		// A pessimistic transaction has locked the rows it read, so it is not retried.
+		if s.isRetryableError(err) && !s.sessionVars.TxnCtx.IsPessimistic {
 			log.Warnf("[%d] retryable error: %v, txn: %v", s.sessionVars.ConnectionID, err, s.txn)
 			// Transactions will retry 2 ~ commitRetryLimit times.
 			// We make larger transactions retry less times to prevent cluster resource outage.
@@ -765,6 +779,7 @@ func (s *session) DropPreparedStmt(stmtID uint32) error {
 	if _, ok := vars.PreparedStmts[stmtID]; !ok {
 		return executor.ErrStmtNotFound
 	}
+	executor.DeleteCachedPlans(s, stmtID)
 	vars.RetryInfo.DroppedPreparedStmtIDs = append(vars.RetryInfo.DroppedPreparedStmtIDs, stmtID)
 	return nil
 }
@@ -850,6 +865,11 @@ func (s *session) Close() {
 	if err := s.RollbackTxn(); err != nil {
 		log.Error("session Close error:", errors.ErrorStack(err))
 	}
+	if h := userlock.GetHolder(s); h != nil {
+		if _, err := h.ReleaseAll(); err != nil {
+			log.Error("session Close error:", errors.ErrorStack(err))
+		}
+	}
 	return
 }
 
@@ -877,17 +897,24 @@ func (s *session) Auth(user *auth.UserIdentity, authentication []byte, salt []by
 	pm := privilege.GetPrivilegeManager(s)
 
 	// Check IP.
-	if pm.ConnectionVerification(user.Username, user.Hostname, authentication, salt) {
-		s.sessionVars.User = user
+	if u, h, ok := pm.ConnectionVerification(user.Username, user.Hostname, authentication, salt, s.sessionVars.TLSConnectionState); ok {
+		s.sessionVars.User = &auth.UserIdentity{
+			Username:     user.Username,
+			Hostname:     user.Hostname,
+			AuthUsername: u,
+			AuthHostname: h,
+		}
 		return true
 	}
 
 	// Check Hostname.
 	for _, addr := range getHostByIP(user.Hostname) {
-		if pm.ConnectionVerification(user.Username, addr, authentication, salt) {
+		if u, h, ok := pm.ConnectionVerification(user.Username, addr, authentication, salt, s.sessionVars.TLSConnectionState); ok {
 			s.sessionVars.User = &auth.UserIdentity{
-				Username: user.Username,
-				Hostname: addr,
+				Username:     user.Username,
+				Hostname:     addr,
+				AuthUsername: u,
+				AuthHostname: h,
 			}
 			return true
 		}
@@ -897,6 +924,19 @@ func (s *session) Auth(user *auth.UserIdentity, authentication []byte, salt []by
 	return false
 }
 
+func (s *session) AuthPlugin(user *auth.UserIdentity) string {
+	pm := privilege.GetPrivilegeManager(s)
+	if plugin, ok := pm.GetAuthPlugin(user.Username, user.Hostname); ok {
+		return plugin
+	}
+	for _, addr := range getHostByIP(user.Hostname) {
+		if plugin, ok := pm.GetAuthPlugin(user.Username, addr); ok {
+			return plugin
+		}
+	}
+	return ""
+}
+
 func getHostByIP(ip string) []string {
 	if ip == "127.0.0.1" {
 		return []string{"localhost"}
@@ -933,6 +973,7 @@ func CreateSession(store kv.Storage) (Session, error) {
 		Handle: do.PrivilegeHandle(),
 	}
 	privilege.BindPrivilegeManager(s, pm)
+	userlock.BindHolder(s, do.UserLockManager().NewHolder())
 
 	// Add statsUpdateHandle.
 	if do.StatsHandle() != nil {
@@ -1015,6 +1056,9 @@ func createSession(store kv.Storage) (*session, error) {
 		parser:      parser.New(),
 		sessionVars: variable.NewSessionVars(),
 	}
+	if plan.PreparedPlanCacheEnabled {
+		s.preparedPlanCache = kvcache.NewSimpleLRUCache(plan.PreparedPlanCacheCapacity)
+	}
 	s.mu.values = make(map[fmt.Stringer]interface{})
 	sessionctx.BindDomain(s, domain)
 	// session implements variable.GlobalVarAccessor. Bind it to ctx.
@@ -1042,7 +1086,7 @@ func createSessionWithDomain(store kv.Storage, dom *domain.Domain) (*session, er
 
 const (
 	notBootstrapped         = 0
-	currentBootstrapVersion = 15
+	currentBootstrapVersion = 18
 )
 
 func getStoreBootstrapVersion(store kv.Storage) int64 {
@@ -1091,6 +1135,7 @@ const loadCommonGlobalVarsSQL = "select HIGH_PRIORITY * from mysql.global_variab
 	variable.AutocommitVar + quoteCommaQuote +
 	variable.SQLModeVar + quoteCommaQuote +
 	variable.MaxAllowedPacket + quoteCommaQuote +
+	variable.LockWaitTimeout + quoteCommaQuote +
 	/* TiDB specific global variables: */
 	variable.TiDBSkipUTF8Check + quoteCommaQuote +
 	variable.TiDBIndexJoinBatchSize + quoteCommaQuote +
@@ -1099,6 +1144,12 @@ const loadCommonGlobalVarsSQL = "select HIGH_PRIORITY * from mysql.global_variab
 	variable.TiDBIndexSerialScanConcurrency + quoteCommaQuote +
 	variable.TiDBMaxRowCountForINLJ + quoteCommaQuote +
 	variable.TiDBCBO + quoteCommaQuote +
+	variable.TiDBMemQuotaSort + quoteCommaQuote +
+	variable.TiDBMemQuotaCTE + quoteCommaQuote +
+	variable.TiDBMemQuotaIndexMerge + quoteCommaQuote +
+	variable.CTEMaxRecursionDepth + quoteCommaQuote +
+	variable.TiDBMaxCartesianProductRows + quoteCommaQuote +
+	variable.TiDBOptJoinReorderThreshold + quoteCommaQuote +
 	variable.TiDBDistSQLScanConcurrency + "')"
 
 // loadCommonGlobalVariablesIfNeeded loads and applies commonly used global variables for the session.
@@ -1205,9 +1256,18 @@ func (s *session) ActivePendingTxn() error {
 	if s.sessionVars.Systems[variable.TxnIsolation] == ast.ReadCommitted {
 		txn.SetOption(kv.IsolationLevel, kv.RC)
 	}
+	if !s.sessionVars.IsAutocommit() && s.sessionVars.TxnMode == variable.TxnModePessimistic {
+		s.sessionVars.TxnCtx.IsPessimistic = true
+		txn.SetOption(kv.Pessimistic, true)
+	}
 	return nil
 }
 
+// PreparedPlanCache implements the context.Context interface.
+func (s *session) PreparedPlanCache() *kvcache.SimpleLRUCache {
+	return s.preparedPlanCache
+}
+
 // InitTxnWithStartTS create a transaction with startTS.
 func (s *session) InitTxnWithStartTS(startTS uint64) error {
 	if s.txn != nil && s.txn.Valid() {
@@ -1269,7 +1329,7 @@ func logCrucialStmt(node ast.StmtNode) {
 		log.Infof("[CRUCIAL OPERATION] %s.", text)
 	case *ast.RevokeStmt:
 		log.Infof("[CRUCIAL OPERATION] %s.", stmt.Text())
-	case *ast.AlterTableStmt, *ast.CreateDatabaseStmt, *ast.CreateIndexStmt, *ast.CreateTableStmt,
+	case *ast.AlterTableStmt, *ast.CreateDatabaseStmt, *ast.CreateIndexStmt, *ast.CreateTableStmt, *ast.CreateViewStmt,
 		*ast.DropDatabaseStmt, *ast.DropIndexStmt, *ast.DropTableStmt, *ast.RenameTableStmt, *ast.TruncateTableStmt:
 		log.Infof("[CRUCIAL OPERATION] %s.", stmt.Text())
 	}
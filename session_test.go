@@ -2151,11 +2151,11 @@ func (s *testSessionSuite) TestMultiColumnIndex(c *C) {
 	mustExecSQL(c, se, "insert into t values (1, 5)")
 
 	sql := "select c1 from t where c1 in (1) and c2 < 10"
-	checkPlan(c, se, sql, "Index(t.idx_c1_c2)[[1 -inf,1 10)]->Projection")
+	checkPlan(c, se, sql, "IndexReader(Index(t.idx_c1_c2)[[1 -inf,1 10)])->Projection")
 	mustExecMatch(c, se, sql, [][]interface{}{{1}})
 
 	sql = "select c1 from t where c1 in (1) and c2 > 3"
-	checkPlan(c, se, sql, "Index(t.idx_c1_c2)[(1 3,1 +inf]]->Projection")
+	checkPlan(c, se, sql, "IndexReader(Index(t.idx_c1_c2)[(1 3,1 +inf]])->Projection")
 	mustExecMatch(c, se, sql, [][]interface{}{{1}})
 
 	// TODO: c2 is int which will be added cast to real when building LT, thus we cannot extract access condition for it.
@@ -2164,7 +2164,7 @@ func (s *testSessionSuite) TestMultiColumnIndex(c *C) {
 	//mustExecMatch(c, se, sql, [][]interface{}{{1}})
 
 	sql = "select c1 from t where c1 in (1.1) and c2 > 3"
-	checkPlan(c, se, sql, "Table(t)->Selection->Projection")
+	checkPlan(c, se, sql, "TableReader(Table(t)->Sel([gt(test_multi_column_index.t.c2, 3)]))->Sel([eq(cast(test_multi_column_index.t.c1), 1.1)])->Projection")
 	mustExecMatch(c, se, sql, [][]interface{}{})
 
 	// Test varchar type.
@@ -2745,3 +2745,34 @@ func (s *testSessionSuite) TestPrepareStmtCommitWhenSchemaChanged(c *C) {
 	// _, err = s2.Execute("commit")
 	// c.Assert(terror.ErrorEqual(err, executor.ErrWrongValueCountOnRow), IsTrue)
 }
+
+func (s *testSessionSuite) TestSnapshotReadDroppedTable(c *C) {
+	defer testleak.AfterTest(c)()
+	se := newSession(c, s.store, s.dbName)
+	mustExecSQL(c, se, "drop table if exists snapshot_drop")
+	mustExecSQL(c, se, "create table snapshot_drop (a int, b int, index idx_b(b))")
+	mustExecSQL(c, se, "insert snapshot_drop values (1, 10), (2, 20)")
+	mustExecSQL(c, se, `INSERT INTO mysql.tidb VALUES ('tikv_gc_safe_point', '20060102-15:04:05 -0700 MST', '')
+		ON DUPLICATE KEY UPDATE variable_value = '20060102-15:04:05 -0700 MST'`)
+
+	is := sessionctx.GetDomain(se).InfoSchema()
+	tbl, err := is.TableByName(model.NewCIStr(s.dbName), model.NewCIStr("snapshot_drop"))
+	c.Assert(err, IsNil)
+
+	time.Sleep(time.Millisecond)
+	snapshotTime := time.Now()
+	time.Sleep(time.Millisecond)
+	mustExecSQL(c, se, "drop table snapshot_drop")
+	// The range of the dropped table is kept in the delete-range table until it passes the safe point
+	// of the store and is deleted.
+	time.Sleep(time.Second)
+	sql := fmt.Sprintf("select count(*) from mysql.gc_delete_range where element_id = %d", tbl.Meta().ID)
+	mustExecMatch(c, se, sql, [][]interface{}{{1}})
+
+	// The data of the dropped table is kept for the snapshots before the drop.
+	mustExecSQL(c, se, "set @@tidb_snapshot = '"+snapshotTime.Format("2006-01-02 15:04:05.999999")+"'")
+	mustExecMatch(c, se, "select * from snapshot_drop order by a", [][]interface{}{{1, 10}, {2, 20}})
+	mustExecMatch(c, se, "select b from snapshot_drop use index(idx_b) where b > 10", [][]interface{}{{20}})
+	mustExecSQL(c, se, "set @@tidb_snapshot = ''")
+	mustExecFailed(c, se, "select * from snapshot_drop")
+}
@@ -14,6 +14,7 @@
 package variable
 
 import (
+	"crypto/tls"
 	"math"
 	"sync"
 	"time"
@@ -21,6 +22,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/auth"
+	"github.com/pingcap/tidb/util/execdetails"
 )
 
 const (
@@ -79,6 +81,7 @@ func (r *RetryInfo) GetCurrAutoIncrementID() (int64, error) {
 // TransactionContext is used to store variables that has transaction scope.
 type TransactionContext struct {
 	ForUpdate     bool
+	IsPessimistic bool
 	DirtyDB       interface{}
 	Binlog        interface{}
 	InfoSchema    interface{}
@@ -112,6 +115,8 @@ type SessionVars struct {
 	PreparedStmtNameToID map[string]uint32
 	// preparedStmtID is id of prepared statement.
 	preparedStmtID uint32
+	// PreparedParams are the parameters of the executing prepared statement, they are types.Datum.
+	PreparedParams []interface{}
 
 	// retry information
 	RetryInfo *RetryInfo
@@ -131,6 +136,9 @@ type SessionVars struct {
 	// ConnectionID is the connection id of the current session.
 	ConnectionID uint64
 
+	// TLSConnectionState is the TLS state of the connection, it's nil if the connection doesn't use TLS.
+	TLSConnectionState *tls.ConnectionState
+
 	// User is the user identity with which the session login.
 	User *auth.UserIdentity
 
@@ -218,6 +226,34 @@ type SessionVars struct {
 
 	// CBO indicates if we use new planner with cbo.
 	CBO bool
+
+	// MemQuotaSort is the memory threshold in bytes of a sort operator, beyond which the sorted rows are spilled to disk.
+	MemQuotaSort int64
+
+	// MemQuotaCTE is the memory threshold in bytes of a common table expression, beyond which the materialized rows
+	// are spilled to disk.
+	MemQuotaCTE int64
+
+	// MemQuotaIndexMerge is the memory threshold in bytes of the handles held by an index merge reader, beyond which
+	// the statement is aborted.
+	MemQuotaIndexMerge int64
+
+	// CTEMaxRecursionDepth is the maximum number of iterations of a recursive common table expression.
+	CTEMaxRecursionDepth int
+
+	// MaxCartesianProductRows is the maximum estimated row count of a cartesian product, 0 means no limit.
+	MaxCartesianProductRows int64
+
+	// JoinReorderThreshold is the maximum number of tables in a join group reordered by dynamic programming.
+	JoinReorderThreshold int
+
+	// TxnMode is the transaction mode of explicit transactions started in the session, "pessimistic" or empty.
+	// It isn't exposed as a system variable, because the tikv client can't send pessimistic lock requests
+	// until the kvproto in use defines them. Only mock-tikv serves them now.
+	TxnMode string
+
+	// LockWaitTimeout is the time in milliseconds a pessimistic transaction waits for a row lock.
+	LockWaitTimeout int64
 }
 
 // NewSessionVars creates a session vars object.
@@ -241,6 +277,13 @@ func NewSessionVars() *SessionVars {
 		DistSQLScanConcurrency:     DefDistSQLScanConcurrency,
 		MaxRowCountForINLJ:         DefMaxRowCountForINLJ,
 		CBO:                        true,
+		MemQuotaSort:               DefMemQuotaSort,
+		MemQuotaCTE:                DefMemQuotaCTE,
+		MemQuotaIndexMerge:         DefMemQuotaIndexMerge,
+		CTEMaxRecursionDepth:       DefCTEMaxRecursionDepth,
+		MaxCartesianProductRows:    DefMaxCartesianProductRows,
+		JoinReorderThreshold:       DefOptJoinReorderThreshold,
+		LockWaitTimeout:            DefLockWaitTimeout * 1000,
 	}
 }
 
@@ -313,12 +356,14 @@ func (s *SessionVars) GetTimeZone() *time.Location {
 
 // special session variables.
 const (
-	SQLModeVar          = "sql_mode"
-	AutocommitVar       = "autocommit"
-	CharacterSetResults = "character_set_results"
-	MaxAllowedPacket    = "max_allowed_packet"
-	TimeZone            = "time_zone"
-	TxnIsolation        = "tx_isolation"
+	SQLModeVar           = "sql_mode"
+	AutocommitVar        = "autocommit"
+	CharacterSetResults  = "character_set_results"
+	MaxAllowedPacket     = "max_allowed_packet"
+	TimeZone             = "time_zone"
+	TxnIsolation         = "tx_isolation"
+	CTEMaxRecursionDepth = "cte_max_recursion_depth"
+	LockWaitTimeout      = "innodb_lock_wait_timeout"
 )
 
 // TableDelta stands for the changed count for one table.
@@ -339,6 +384,10 @@ type StatementContext struct {
 	TruncateAsWarning    bool
 	OverflowAsWarning    bool
 	InShowWarning        bool
+	// UseCache is true if the plan of the prepared statement is built to be cached.
+	UseCache bool
+	// RuntimeStatsColl collects the runtime statistics of the executors, it's only set by EXPLAIN ANALYZE.
+	RuntimeStatsColl *execdetails.RuntimeStatsColl
 
 	// mu struct holds variables that change during execution.
 	mu struct {
@@ -351,6 +400,10 @@ type StatementContext struct {
 	// Copied from SessionVars.TimeZone.
 	TimeZone *time.Location
 	Priority mysql.PriorityEnum
+
+	// ForUpdateTS is the timestamp used to read and lock rows in a pessimistic transaction.
+	// It is 0 if the statement doesn't need to lock rows.
+	ForUpdateTS uint64
 }
 
 // AddAffectedRows adds affected rows.
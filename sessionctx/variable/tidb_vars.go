@@ -103,6 +103,31 @@ const (
 
 	// tidb_cbo uses new planner with cost based optimizer.
 	TiDBCBO = "tidb_cbo"
+
+	// tidb_mem_quota_sort is the memory threshold in bytes of a sort operator in a statement.
+	// When the rows buffered by a sort operator exceed this threshold, the operator spills them into
+	// temporary files and switches to external merge sort.
+	TiDBMemQuotaSort = "tidb_mem_quota_sort"
+
+	// tidb_mem_quota_cte is the memory threshold in bytes of a common table expression in a statement.
+	// When the rows materialized for a common table expression exceed this threshold, they are spilled into
+	// a temporary file.
+	TiDBMemQuotaCTE = "tidb_mem_quota_cte"
+
+	// tidb_mem_quota_index_merge is the memory threshold in bytes of an index merge reader in a statement.
+	// When the handles held by an index merge reader for deduplication or intersection exceed this threshold,
+	// the statement is aborted.
+	TiDBMemQuotaIndexMerge = "tidb_mem_quota_index_merge"
+
+	// tidb_max_cartesian_product_rows is the maximum estimated row count of a join without equal conditions.
+	// A statement containing such a join with a larger estimated row count is rejected by the optimizer.
+	// The default value 0 means there is no limit.
+	TiDBMaxCartesianProductRows = "tidb_max_cartesian_product_rows"
+
+	// tidb_opt_join_reorder_threshold is the maximum number of tables in a join group which is reordered by the
+	// dynamic programming algorithm, larger join groups are reordered by the greedy algorithm.
+	// The default value 0 means the greedy algorithm is always used.
+	TiDBOptJoinReorderThreshold = "tidb_opt_join_reorder_threshold"
 )
 
 // Default TiDB system variable values.
@@ -120,4 +145,19 @@ const (
 	DefBatchInsert                = false
 	DefBatchDelete                = false
 	DefCurretTS                   = 0
+	DefMemQuotaSort               = 1 << 30 // 1GB
+	DefMemQuotaCTE                = 1 << 30 // 1GB
+	DefMemQuotaIndexMerge         = 1 << 30 // 1GB
+	DefCTEMaxRecursionDepth       = 1000
+	DefMaxCartesianProductRows    = 0
+	DefOptJoinReorderThreshold    = 0
+	DefLockWaitTimeout            = 50
 )
+
+// MaxOptJoinReorderThreshold is the maximum value of tidb_opt_join_reorder_threshold. The dynamic programming
+// visits every pair of disjoint subsets of the join group, which is 3^n for n tables, so the join groups which
+// can be reordered by it are kept small.
+const MaxOptJoinReorderThreshold = 12
+
+// TxnModePessimistic is the transaction mode which locks rows when they are read for update or written.
+const TxnModePessimistic = "pessimistic"
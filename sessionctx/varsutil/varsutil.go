@@ -150,6 +150,24 @@ func SetSessionSystemVar(vars *variable.SessionVars, name string, value types.Da
 		vars.MaxRowCountForINLJ = tidbOptPositiveInt(sVal, variable.DefMaxRowCountForINLJ)
 	case variable.TiDBCBO:
 		vars.CBO = tidbOptOn(sVal)
+	case variable.TiDBMemQuotaSort:
+		vars.MemQuotaSort = tidbOptInt64(sVal, variable.DefMemQuotaSort)
+	case variable.TiDBMemQuotaCTE:
+		vars.MemQuotaCTE = tidbOptInt64(sVal, variable.DefMemQuotaCTE)
+	case variable.TiDBMemQuotaIndexMerge:
+		vars.MemQuotaIndexMerge = tidbOptInt64(sVal, variable.DefMemQuotaIndexMerge)
+	case variable.CTEMaxRecursionDepth:
+		vars.CTEMaxRecursionDepth = tidbOptPositiveInt(sVal, variable.DefCTEMaxRecursionDepth)
+	case variable.TiDBMaxCartesianProductRows:
+		vars.MaxCartesianProductRows = tidbOptInt64(sVal, variable.DefMaxCartesianProductRows)
+	case variable.TiDBOptJoinReorderThreshold:
+		val, err := strconv.Atoi(sVal)
+		if err != nil || val < 0 || val > variable.MaxOptJoinReorderThreshold {
+			return variable.ErrWrongValueForVar.GenByArgs(name, sVal)
+		}
+		vars.JoinReorderThreshold = val
+	case variable.LockWaitTimeout:
+		vars.LockWaitTimeout = tidbOptInt64(sVal, variable.DefLockWaitTimeout) * 1000
 	case variable.TiDBCurrentTS:
 		return variable.ErrReadOnly
 	}
@@ -170,6 +188,14 @@ func tidbOptPositiveInt(opt string, defaultVal int) int {
 	return val
 }
 
+func tidbOptInt64(opt string, defaultVal int64) int64 {
+	val, err := strconv.ParseInt(opt, 10, 64)
+	if err != nil || val <= 0 {
+		return defaultVal
+	}
+	return val
+}
+
 func parseTimeZone(s string) (*time.Location, error) {
 	if s == "SYSTEM" {
 		// TODO: Support global time_zone variable, it should be set to global time_zone value.
@@ -156,6 +156,47 @@ func (s *testVarsutilSuite) TestVarsutil(c *C) {
 	c.Assert(v.MaxRowCountForINLJ, Equals, 128)
 	SetSessionSystemVar(v, variable.TiDBMaxRowCountForINLJ, types.NewStringDatum("127"))
 	c.Assert(v.MaxRowCountForINLJ, Equals, 127)
+
+	// Test case for tidb_mem_quota_sort.
+	c.Assert(v.MemQuotaSort, Equals, int64(variable.DefMemQuotaSort))
+	SetSessionSystemVar(v, variable.TiDBMemQuotaSort, types.NewStringDatum("1024"))
+	c.Assert(v.MemQuotaSort, Equals, int64(1024))
+	SetSessionSystemVar(v, variable.TiDBMemQuotaSort, types.NewStringDatum("-1"))
+	c.Assert(v.MemQuotaSort, Equals, int64(variable.DefMemQuotaSort))
+
+	// Test case for tidb_mem_quota_index_merge.
+	c.Assert(v.MemQuotaIndexMerge, Equals, int64(variable.DefMemQuotaIndexMerge))
+	SetSessionSystemVar(v, variable.TiDBMemQuotaIndexMerge, types.NewStringDatum("1024"))
+	c.Assert(v.MemQuotaIndexMerge, Equals, int64(1024))
+
+	// Test case for tidb_mem_quota_cte and cte_max_recursion_depth.
+	c.Assert(v.MemQuotaCTE, Equals, int64(variable.DefMemQuotaCTE))
+	SetSessionSystemVar(v, variable.TiDBMemQuotaCTE, types.NewStringDatum("1024"))
+	c.Assert(v.MemQuotaCTE, Equals, int64(1024))
+	c.Assert(v.CTEMaxRecursionDepth, Equals, variable.DefCTEMaxRecursionDepth)
+	SetSessionSystemVar(v, variable.CTEMaxRecursionDepth, types.NewStringDatum("10"))
+	c.Assert(v.CTEMaxRecursionDepth, Equals, 10)
+	SetSessionSystemVar(v, variable.CTEMaxRecursionDepth, types.NewStringDatum("0"))
+	c.Assert(v.CTEMaxRecursionDepth, Equals, variable.DefCTEMaxRecursionDepth)
+
+	// Test case for tidb_max_cartesian_product_rows.
+	c.Assert(v.MaxCartesianProductRows, Equals, int64(variable.DefMaxCartesianProductRows))
+	SetSessionSystemVar(v, variable.TiDBMaxCartesianProductRows, types.NewStringDatum("1000"))
+	c.Assert(v.MaxCartesianProductRows, Equals, int64(1000))
+
+	// Test case for tidb_opt_join_reorder_threshold.
+	c.Assert(v.JoinReorderThreshold, Equals, variable.DefOptJoinReorderThreshold)
+	err = SetSessionSystemVar(v, variable.TiDBOptJoinReorderThreshold, types.NewStringDatum("5"))
+	c.Assert(err, IsNil)
+	c.Assert(v.JoinReorderThreshold, Equals, 5)
+	err = SetSessionSystemVar(v, variable.TiDBOptJoinReorderThreshold, types.NewStringDatum("12"))
+	c.Assert(err, IsNil)
+	c.Assert(v.JoinReorderThreshold, Equals, 12)
+	err = SetSessionSystemVar(v, variable.TiDBOptJoinReorderThreshold, types.NewStringDatum("13"))
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	err = SetSessionSystemVar(v, variable.TiDBOptJoinReorderThreshold, types.NewStringDatum("-1"))
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	c.Assert(v.JoinReorderThreshold, Equals, 12)
 }
 
 type mockGlobalAccessor struct {
@@ -14,6 +14,7 @@
 package boltdb
 
 import (
+	"bytes"
 	"os"
 	"path"
 
@@ -132,6 +133,26 @@ func (d *db) Commit(b engine.Batch) error {
 	return errors.Trace(err)
 }
 
+func (d *db) DeleteRange(startKey, endKey []byte) error {
+	// The pages freed by the deleted keys are reused by bolt in the later writes.
+	err := d.DB.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketName)
+		// Deleting keys with the cursor may skip the next key, so collect the keys first.
+		var keys [][]byte
+		c := b.Cursor()
+		for k, _ := c.Seek(startKey); k != nil && bytes.Compare(k, endKey) < 0; k, _ = c.Next() {
+			keys = append(keys, cloneBytes(k))
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return nil
+	})
+	return errors.Trace(err)
+}
+
 func (d *db) Close() error {
 	return d.DB.Close()
 }
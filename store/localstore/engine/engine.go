@@ -46,6 +46,8 @@ type DB interface {
 	NewBatch() Batch
 	// Commit writes the changed data in Batch.
 	Commit(b Batch) error
+	// DeleteRange deletes all the keys in the range [startKey, endKey) and reclaims the space they take.
+	DeleteRange(startKey, endKey []byte) error
 	// Close closes database.
 	Close() error
 }
@@ -90,6 +90,33 @@ func (d *db) Commit(b engine.Batch) error {
 	return err
 }
 
+// deleteRangeBatchSize is the max number of keys deleted in one batch by DeleteRange.
+const deleteRangeBatchSize = 4096
+
+func (d *db) DeleteRange(startKey, endKey []byte) error {
+	r := &util.Range{Start: startKey, Limit: endKey}
+	iter := d.DB.NewIterator(r, nil)
+	defer iter.Release()
+	batch := new(leveldb.Batch)
+	for iter.Next() {
+		batch.Delete(iter.Key())
+		if batch.Len() >= deleteRangeBatchSize {
+			if err := d.DB.Write(batch, nil); err != nil {
+				return errors.Trace(err)
+			}
+			batch.Reset()
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := d.DB.Write(batch, nil); err != nil {
+		return errors.Trace(err)
+	}
+	// Compact the range to drop the deleted keys and their tombstones from disk.
+	return errors.Trace(d.DB.CompactRange(*r))
+}
+
 func (d *db) Close() error {
 	return d.DB.Close()
 }
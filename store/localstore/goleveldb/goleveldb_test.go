@@ -147,3 +147,31 @@ func (s *testSuite) TestPrevSeek(c *C) {
 	c.Assert(k, IsNil)
 	c.Assert(v, IsNil)
 }
+
+func (s *testSuite) TestDeleteRange(c *C) {
+	defer testleak.AfterTest(c)()
+	b := s.db.NewBatch()
+	for _, k := range []string{"a", "b", "b1", "c", "d"} {
+		b.Put([]byte(k), []byte(k))
+	}
+	err := s.db.Commit(b)
+	c.Assert(err, IsNil)
+
+	err = s.db.DeleteRange([]byte("b"), []byte("c"))
+	c.Assert(err, IsNil)
+	for _, k := range []string{"b", "b1"} {
+		_, err = s.db.Get([]byte(k))
+		c.Assert(err, NotNil)
+	}
+	k, _, err := s.db.Seek([]byte("a1"))
+	c.Assert(err, IsNil)
+	c.Assert(k, BytesEquals, []byte("c"))
+
+	// Deleting an empty range is fine.
+	err = s.db.DeleteRange([]byte("b"), []byte("c"))
+	c.Assert(err, IsNil)
+	err = s.db.DeleteRange([]byte("a"), []byte("z"))
+	c.Assert(err, IsNil)
+	_, _, err = s.db.Seek(nil)
+	c.Assert(err, NotNil)
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/pingcap/tidb/store/localstore/engine"
 	"github.com/pingcap/tidb/store/tikv/oracle"
 	"github.com/pingcap/tidb/store/tikv/oracle/oracles"
+	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/segmentmap"
 	"github.com/twinj/uuid"
 )
@@ -259,6 +260,9 @@ func init() {
 type Driver struct {
 	// engine.Driver is the engine driver for different local db engine.
 	engine.Driver
+	// SafePoint is the safe point in milliseconds of the compactor of the opened store,
+	// the default one is used if it's 0.
+	SafePoint int
 }
 
 // MockRemoteStore mocks remote store. It makes IsLocalStore return false.
@@ -297,13 +301,17 @@ func (d Driver) Open(path string) (kv.Storage, error) {
 	}
 
 	log.Info("[kv] New store", engineSchema)
+	policy := localCompactDefaultPolicy
+	if d.SafePoint > 0 {
+		policy.SafePoint = d.SafePoint
+	}
 	s := &dbStore{
 		txns:       make(map[uint64]*dbTxn),
 		keysLocked: make(map[string]uint64),
 		uuid:       uuid.NewV4().String(),
 		path:       engineSchema,
 		db:         db,
-		compactor:  newLocalCompactor(localCompactDefaultPolicy, db),
+		compactor:  newLocalCompactor(policy, db),
 		closed:     false,
 		oracle:     oracles.NewLocalOracle(),
 	}
@@ -412,7 +420,30 @@ func (s *dbStore) writeBatch(b engine.Batch) error {
 }
 
 func (s *dbStore) SupportDeleteRange() (supported bool) {
-	return false
+	return true
+}
+
+// DeleteRange deletes all the versions of the keys in the range [startKey, endKey) from
+// the engine. It's used to clean up the data of the dropped tables and indices, the caller
+// must make sure the range isn't read by the snapshots any more, see SafePoint.
+func (s *dbStore) DeleteRange(startKey, endKey kv.Key) error {
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return ErrDBClosed
+	}
+	s.wg.Add(1)
+	s.mu.RUnlock()
+	defer s.wg.Done()
+
+	start := codec.EncodeBytes(nil, startKey)
+	end := codec.EncodeBytes(nil, endKey)
+	return errors.Trace(s.db.DeleteRange(start, end))
+}
+
+// SafePoint returns the time before which the versions may be removed by the compactor.
+func (s *dbStore) SafePoint() time.Time {
+	return time.Now().Add(-time.Duration(s.compactor.policy.SafePoint) * time.Millisecond)
 }
 
 func (s *dbStore) newBatch() engine.Batch {
@@ -44,6 +44,13 @@ func (c *dbClient) IsRequestTypeSupported(reqType, subType int64) bool {
 		default:
 			return supportExpr(tipb.ExprType(subType))
 		}
+	case kv.ReqTypeDAG:
+		switch subType {
+		case kv.ReqSubTypeSignature:
+			return true
+		default:
+			return supportExpr(tipb.ExprType(subType))
+		}
 	}
 	return false
 }
@@ -0,0 +1,333 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localstore
+
+import (
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/distsql"
+	"github.com/pingcap/tidb/distsql/xeval"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tipb/go-tipb"
+)
+
+type dagContext struct {
+	dagReq    *tipb.DAGRequest
+	txn       kv.Transaction
+	keyRanges []kv.KeyRange
+	evalCtx   *evalContext
+}
+
+// handleDAGRequest executes the DAG request in the region, it works the same as the
+// coprocessor of TiKV does. The error occurs in execution is returned in the response.
+func (rs *localRegion) handleDAGRequest(req *regionRequest) (*tipb.SelectResponse, error) {
+	dagReq := new(tipb.DAGRequest)
+	err := proto.Unmarshal(req.data, dagReq)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	sc := xeval.FlagsToStatementContext(dagReq.Flags)
+	sc.TimeZone = time.FixedZone("UTC", int(dagReq.TimeZoneOffset))
+	ctx := &dagContext{
+		dagReq:    dagReq,
+		txn:       newTxn(rs.store, kv.Version{Ver: dagReq.GetStartTs()}),
+		keyRanges: req.ranges,
+		evalCtx:   &evalContext{sc: sc, timeZone: sc.TimeZone},
+	}
+	e, err := rs.buildDAG(ctx, dagReq.Executors)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var chunks []tipb.Chunk
+	for {
+		var (
+			handle int64
+			row    [][]byte
+		)
+		handle, row, err = e.Next()
+		if err != nil || row == nil {
+			break
+		}
+		var data []byte
+		for _, offset := range dagReq.OutputOffsets {
+			data = append(data, row[offset]...)
+		}
+		chunks = appendRow(chunks, handle, data)
+	}
+	return &tipb.SelectResponse{
+		Error:  toPBError(err),
+		Chunks: chunks,
+	}, errors.Trace(err)
+}
+
+func (rs *localRegion) buildExec(ctx *dagContext, curr *tipb.Executor) (executor, error) {
+	var currExec executor
+	var err error
+	switch curr.GetTp() {
+	case tipb.ExecType_TypeTableScan:
+		currExec = rs.buildTableScan(ctx, curr)
+	case tipb.ExecType_TypeIndexScan:
+		currExec = rs.buildIndexScan(ctx, curr)
+	case tipb.ExecType_TypeSelection:
+		currExec, err = rs.buildSelection(ctx, curr)
+	case tipb.ExecType_TypeAggregation:
+		currExec, err = rs.buildAggregation(ctx, curr)
+	case tipb.ExecType_TypeTopN:
+		currExec, err = rs.buildTopN(ctx, curr)
+	case tipb.ExecType_TypeLimit:
+		currExec = &limitExec{limit: curr.Limit.GetLimit()}
+	default:
+		err = errors.Errorf("this exec type %v doesn't support yet.", curr.GetTp())
+	}
+
+	return currExec, errors.Trace(err)
+}
+
+func (rs *localRegion) buildDAG(ctx *dagContext, executors []*tipb.Executor) (executor, error) {
+	var src executor
+	for i := 0; i < len(executors); i++ {
+		curr, err := rs.buildExec(ctx, executors[i])
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		curr.SetSrcExec(src)
+		src = curr
+	}
+	return src, nil
+}
+
+func (rs *localRegion) buildTableScan(ctx *dagContext, executor *tipb.Executor) *tableScanExec {
+	columns := executor.TblScan.Columns
+	ctx.evalCtx.setColumnInfo(columns)
+
+	return &tableScanExec{
+		TableScan: executor.TblScan,
+		kvRanges:  rs.extractKVRanges(ctx.keyRanges, executor.TblScan.Desc),
+		colIDs:    ctx.evalCtx.colIDs,
+		txn:       ctx.txn,
+	}
+}
+
+func (rs *localRegion) buildIndexScan(ctx *dagContext, executor *tipb.Executor) *indexScanExec {
+	columns := executor.IdxScan.Columns
+	ctx.evalCtx.setColumnInfo(columns)
+	length := len(columns)
+	var pkCol *tipb.ColumnInfo
+	// The PKHandle column info has been collected in ctx.
+	if columns[length-1].GetPkHandle() {
+		pkCol = columns[length-1]
+		columns = columns[:length-1]
+	}
+
+	return &indexScanExec{
+		IndexScan: executor.IdxScan,
+		kvRanges:  rs.extractKVRanges(ctx.keyRanges, executor.IdxScan.Desc),
+		colsLen:   len(columns),
+		txn:       ctx.txn,
+		pkCol:     pkCol,
+	}
+}
+
+func (rs *localRegion) buildSelection(ctx *dagContext, executor *tipb.Executor) (*selectionExec, error) {
+	var err error
+	var relatedColOffsets []int
+	pbConds := executor.Selection.Conditions
+	for _, cond := range pbConds {
+		relatedColOffsets, err = extractOffsetsInExpr(cond, relatedColOffsets)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	conds, err := convertToExprs(ctx.evalCtx.sc, ctx.evalCtx.fieldTps, pbConds)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return &selectionExec{
+		evalCtx:           ctx.evalCtx,
+		relatedColOffsets: relatedColOffsets,
+		conditions:        conds,
+		row:               make([]types.Datum, len(ctx.evalCtx.columnInfos)),
+	}, nil
+}
+
+func (rs *localRegion) buildAggregation(ctx *dagContext, executor *tipb.Executor) (*aggregateExec, error) {
+	length := len(executor.Aggregation.AggFunc)
+	aggs := make([]expression.AggregationFunction, 0, length)
+	var err error
+	var relatedColOffsets []int
+	for _, expr := range executor.Aggregation.AggFunc {
+		var aggExpr expression.AggregationFunction
+		aggExpr, err = expression.NewDistAggFunc(expr, ctx.evalCtx.fieldTps, ctx.evalCtx.sc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		aggs = append(aggs, aggExpr)
+		relatedColOffsets, err = extractOffsetsInExpr(expr, relatedColOffsets)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	for _, item := range executor.Aggregation.GroupBy {
+		relatedColOffsets, err = extractOffsetsInExpr(item, relatedColOffsets)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	groupBys, err := convertToExprs(ctx.evalCtx.sc, ctx.evalCtx.fieldTps, executor.Aggregation.GetGroupBy())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return &aggregateExec{
+		evalCtx:           ctx.evalCtx,
+		aggExprs:          aggs,
+		groupByExprs:      groupBys,
+		groups:            make(map[string]struct{}),
+		groupKeys:         make([][]byte, 0),
+		relatedColOffsets: relatedColOffsets,
+		row:               make([]types.Datum, len(ctx.evalCtx.columnInfos)),
+	}, nil
+}
+
+func (rs *localRegion) buildTopN(ctx *dagContext, executor *tipb.Executor) (*topNExec, error) {
+	topN := executor.TopN
+	var err error
+	var relatedColOffsets []int
+	pbConds := make([]*tipb.Expr, len(topN.OrderBy))
+	for i, item := range topN.OrderBy {
+		relatedColOffsets, err = extractOffsetsInExpr(item.Expr, relatedColOffsets)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		pbConds[i] = item.Expr
+	}
+	heap := &topnHeap{
+		totalCount: int(topN.Limit),
+		topnSorter: topnSorter{
+			orderByItems: topN.OrderBy,
+			sc:           ctx.evalCtx.sc,
+		},
+	}
+
+	conds, err := convertToExprs(ctx.evalCtx.sc, ctx.evalCtx.fieldTps, pbConds)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	return &topNExec{
+		heap:              heap,
+		evalCtx:           ctx.evalCtx,
+		relatedColOffsets: relatedColOffsets,
+		orderByExprs:      conds,
+		row:               make([]types.Datum, len(ctx.evalCtx.columnInfos)),
+	}, nil
+}
+
+type evalContext struct {
+	colIDs      map[int64]int
+	columnInfos []*tipb.ColumnInfo
+	fieldTps    []*types.FieldType
+	sc          *variable.StatementContext
+	timeZone    *time.Location
+}
+
+func (e *evalContext) setColumnInfo(cols []*tipb.ColumnInfo) {
+	e.columnInfos = make([]*tipb.ColumnInfo, len(cols))
+	copy(e.columnInfos, cols)
+
+	e.colIDs = make(map[int64]int)
+	e.fieldTps = make([]*types.FieldType, 0, len(e.columnInfos))
+	for i, col := range e.columnInfos {
+		ft := distsql.FieldTypeFromPBColumn(col)
+		e.fieldTps = append(e.fieldTps, ft)
+		e.colIDs[col.GetColumnId()] = i
+	}
+}
+
+// decodeRelatedColumnVals decodes data to Datum slice according to the row information.
+func (e *evalContext) decodeRelatedColumnVals(relatedColOffsets []int, value [][]byte, row []types.Datum) error {
+	var err error
+	for _, offset := range relatedColOffsets {
+		row[offset], err = tablecodec.DecodeColumnValue(value[offset], e.fieldTps[offset], e.timeZone)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func appendRow(chunks []tipb.Chunk, handle int64, data []byte) []tipb.Chunk {
+	if len(chunks) == 0 || len(chunks[len(chunks)-1].RowsMeta) >= chunkSize {
+		chunks = append(chunks, tipb.Chunk{})
+	}
+	cur := &chunks[len(chunks)-1]
+	cur.RowsMeta = append(cur.RowsMeta, tipb.RowMeta{Handle: handle, Length: int64(len(data))})
+	cur.RowsData = append(cur.RowsData, data...)
+	return chunks
+}
+
+func isDuplicated(offsets []int, offset int) bool {
+	for _, idx := range offsets {
+		if idx == offset {
+			return true
+		}
+	}
+	return false
+}
+
+// extractOffsetsInExpr collects the offsets of the columns referred by expr, the column
+// references of DAG requests are the offsets of the columns in the scan executor.
+func extractOffsetsInExpr(expr *tipb.Expr, collector []int) ([]int, error) {
+	if expr == nil {
+		return nil, nil
+	}
+	if expr.GetTp() == tipb.ExprType_ColumnRef {
+		_, idx, err := codec.DecodeInt(expr.Val)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !isDuplicated(collector, int(idx)) {
+			collector = append(collector, int(idx))
+		}
+		return collector, nil
+	}
+	var err error
+	for _, child := range expr.Children {
+		collector, err = extractOffsetsInExpr(child, collector)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return collector, nil
+}
+
+func convertToExprs(sc *variable.StatementContext, fieldTps []*types.FieldType, pbExprs []*tipb.Expr) ([]expression.Expression, error) {
+	exprs := make([]expression.Expression, 0, len(pbExprs))
+	for _, expr := range pbExprs {
+		e, err := expression.PBToExpr(expr, fieldTps, sc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		exprs = append(exprs, e)
+	}
+	return exprs, nil
+}
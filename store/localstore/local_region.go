@@ -50,7 +50,7 @@ const chunkSize = 64
 type sortRow struct {
 	key  []types.Datum
 	meta tipb.RowMeta
-	data []byte
+	data [][]byte
 }
 
 // topnSorter implements sort.Interface. When all rows have been processed, the topnSorter will sort the whole data in heap.
@@ -58,7 +58,7 @@ type topnSorter struct {
 	orderByItems []*tipb.ByItem
 	rows         []*sortRow
 	err          error
-	ctx          *selectContext
+	sc           *variable.StatementContext
 }
 
 func (t *topnSorter) Len() int {
@@ -74,7 +74,7 @@ func (t *topnSorter) Less(i, j int) bool {
 		v1 := t.rows[i].key[index]
 		v2 := t.rows[j].key[index]
 
-		ret, err := v1.CompareDatum(t.ctx.sc, v2)
+		ret, err := v1.CompareDatum(t.sc, v2)
 		if err != nil {
 			t.err = errors.Trace(err)
 			return true
@@ -123,7 +123,7 @@ func (t *topnHeap) Less(i, j int) bool {
 		v1 := t.rows[i].key[index]
 		v2 := t.rows[j].key[index]
 
-		ret, err := v1.CompareDatum(t.ctx.sc, v2)
+		ret, err := v1.CompareDatum(t.sc, v2)
 		if err != nil {
 			t.err = errors.Trace(err)
 			return true
@@ -225,7 +225,7 @@ func (rs *localRegion) Handle(req *regionRequest) (*regionResponse, error) {
 					totalCount: int(*sel.Limit),
 					topnSorter: topnSorter{
 						orderByItems: sel.OrderBy,
-						ctx:          ctx,
+						sc:           ctx.sc,
 					},
 				}
 				ctx.topnColumns = make(map[int64]*tipb.ColumnInfo)
@@ -280,6 +280,17 @@ func (rs *localRegion) Handle(req *regionRequest) (*regionResponse, error) {
 			return nil, errors.Trace(err)
 		}
 		resp.data = data
+	} else if req.Tp == kv.ReqTypeDAG {
+		selResp, err := rs.handleDAGRequest(req)
+		if selResp == nil {
+			return nil, errors.Trace(err)
+		}
+		resp.err = err
+		data, err := proto.Marshal(selResp)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		resp.data = data
 	}
 	if bytes.Compare(rs.startKey, req.startKey) < 0 || bytes.Compare(rs.endKey, req.endKey) > 0 {
 		resp.newStartKey = rs.startKey
@@ -292,7 +303,9 @@ func (rs *localRegion) setTopNDataForCtx(ctx *selectContext) {
 	sort.Sort(&ctx.topnHeap.topnSorter)
 	for _, row := range ctx.topnHeap.rows {
 		chunk := rs.getChunk(ctx)
-		chunk.RowsData = append(chunk.RowsData, row.data...)
+		for _, data := range row.data {
+			chunk.RowsData = append(chunk.RowsData, data...)
+		}
 		chunk.RowsMeta = append(chunk.RowsMeta, row.meta)
 	}
 }
@@ -340,7 +353,7 @@ func (rs *localRegion) getRowsFromSelectReq(ctx *selectContext) error {
 		ctx.colTps[col.GetColumnId()] = distsql.FieldTypeFromPBColumn(col)
 	}
 
-	kvRanges := rs.extractKVRanges(ctx)
+	kvRanges := rs.extractKVRanges(ctx.keyRanges, ctx.descScan)
 	limit := int64(-1)
 	if ctx.sel.Limit != nil {
 		limit = ctx.sel.GetLimit()
@@ -397,9 +410,9 @@ func (rs *localRegion) getRowsFromAgg(ctx *selectContext) error {
 	return nil
 }
 
-// extractKVRanges extracts kv.KeyRanges slice from ctx.keyRanges, and also returns if it is in descending order.
-func (rs *localRegion) extractKVRanges(ctx *selectContext) (kvRanges []kv.KeyRange) {
-	for _, kran := range ctx.keyRanges {
+// extractKVRanges extracts kv.KeyRanges slice in the region from keyRanges, the result is reversed if desc is true.
+func (rs *localRegion) extractKVRanges(keyRanges []kv.KeyRange, desc bool) (kvRanges []kv.KeyRange) {
+	for _, kran := range keyRanges {
 		upperKey := kran.EndKey
 		if bytes.Compare(upperKey, rs.startKey) <= 0 {
 			continue
@@ -421,7 +434,7 @@ func (rs *localRegion) extractKVRanges(ctx *selectContext) (kvRanges []kv.KeyRan
 		}
 		kvRanges = append(kvRanges, kvr)
 	}
-	if ctx.descScan {
+	if desc {
 		reverseKVRanges(kvRanges)
 	}
 	return
@@ -570,7 +583,7 @@ func (rs *localRegion) evalTopN(ctx *selectContext, handle int64, values map[int
 	if ctx.topnHeap.tryToAddRow(newRow) {
 		for _, col := range columns {
 			val := values[col.GetColumnId()]
-			newRow.data = append(newRow.data, val...)
+			newRow.data = append(newRow.data, val)
 			newRow.meta.Length += int64(len(val))
 		}
 	}
@@ -694,7 +707,7 @@ func toPBError(err error) *tipb.Error {
 }
 
 func (rs *localRegion) getRowsFromIndexReq(ctx *selectContext) error {
-	kvRanges := rs.extractKVRanges(ctx)
+	kvRanges := rs.extractKVRanges(ctx.keyRanges, ctx.descScan)
 	limit := int64(-1)
 	if ctx.sel.Limit != nil {
 		limit = ctx.sel.GetLimit()
@@ -33,7 +33,7 @@ func createMemStore(suffix int) kv.Storage {
 	// avoid cache
 	path := fmt.Sprintf("memory://%d", suffix)
 	d := Driver{
-		goleveldb.MemoryDriver{},
+		Driver: goleveldb.MemoryDriver{},
 	}
 	store, err := d.Open(path)
 	if err != nil {
@@ -416,6 +416,57 @@ func (t *testMvccSuite) TestBufferedIterator(c *C) {
 	tx.Commit()
 }
 
+func (t *testMvccSuite) TestDeleteRange(c *C) {
+	// Write another version of the keys.
+	txn, err := t.s.Begin()
+	c.Assert(err, IsNil)
+	for i := 0; i < 5; i++ {
+		err = txn.Set(encodeInt(i), encodeInt(i*10))
+		c.Assert(err, IsNil)
+	}
+	err = txn.Commit()
+	c.Assert(err, IsNil)
+
+	store := t.s.(*dbStore)
+	c.Assert(store.SupportDeleteRange(), IsTrue)
+	err = store.DeleteRange(encodeInt(1), encodeInt(3))
+	c.Assert(err, IsNil)
+
+	// All the versions of the keys in the range are removed from the engine.
+	var keys []int
+	t.scanRawEngine(c, func(k, v []byte) {
+		key, _, err1 := MvccDecode(k)
+		c.Assert(err1, IsNil)
+		keys = append(keys, decodeInt(key))
+	})
+	c.Assert(keys, DeepEquals, []int{0, 0, 3, 3, 4, 4})
+
+	txn, err = t.s.Begin()
+	c.Assert(err, IsNil)
+	_, err = txn.Get(encodeInt(1))
+	c.Assert(kv.IsErrNotFound(err), IsTrue)
+	val, err := txn.Get(encodeInt(3))
+	c.Assert(err, IsNil)
+	c.Assert(val, BytesEquals, encodeInt(30))
+	txn.Commit()
+}
+
+func (t *testMvccSuite) TestSafePoint(c *C) {
+	// The default safe point is used if the driver doesn't set it.
+	safePoint := t.s.(*dbStore).SafePoint()
+	now := time.Now()
+	c.Assert(now.Sub(safePoint) >= time.Duration(localCompactDefaultPolicy.SafePoint)*time.Millisecond, IsTrue)
+
+	d := Driver{Driver: goleveldb.MemoryDriver{}, SafePoint: 500}
+	store, err := d.Open(fmt.Sprintf("memory://safe_point_%d", time.Now().Nanosecond()))
+	c.Assert(err, IsNil)
+	defer store.Close()
+	safePoint = store.(*dbStore).SafePoint()
+	now = time.Now()
+	c.Assert(now.Sub(safePoint) >= 500*time.Millisecond, IsTrue)
+	c.Assert(now.Sub(safePoint) < time.Duration(localCompactDefaultPolicy.SafePoint)*time.Millisecond, IsTrue)
+}
+
 func encodeInt(n int) []byte {
 	return []byte(fmt.Sprintf("%010d", n))
 }
@@ -114,6 +114,143 @@ func (s *testXAPISuite) TestSelect(c *C) {
 	store.Close()
 }
 
+func (s *testXAPISuite) TestDAG(c *C) {
+	defer testleak.AfterTest(c)()
+	store := createMemStore(time.Now().Nanosecond())
+	defer store.Close()
+	count := int64(10)
+	err := prepareTableData(store, tbInfo, count, genValues)
+	c.Check(err, IsNil)
+	txn, err := store.Begin()
+	c.Check(err, IsNil)
+	defer txn.Rollback()
+
+	tblScan := &tipb.Executor{
+		Tp:      tipb.ExecType_TypeTableScan,
+		TblScan: &tipb.TableScan{TableId: tbInfo.tID, Columns: tbInfo.toPBTableInfo().Columns},
+	}
+	// The handle 0 doesn't exist, the scan should go on with the following ranges.
+	ranges := []kv.KeyRange{
+		tablePointRange(tbInfo.tID, 0),
+		tablePointRange(tbInfo.tID, 2),
+		{
+			StartKey: tablecodec.EncodeRowKeyWithHandle(tbInfo.tID, 5),
+			EndKey:   tablecodec.EncodeRowKeyWithHandle(tbInfo.tID, 8),
+		},
+	}
+	chunks := s.sendDAGRequest(c, store, txn.StartTS(), ranges, []*tipb.Executor{tblScan}, 0, 1, 2)
+	c.Assert(chunksHandles(chunks), DeepEquals, []int64{2, 5, 6, 7})
+	expectedDatums := []types.Datum{types.NewDatum(int64(2))}
+	expectedDatums = append(expectedDatums, genValues(2, tbInfo)...)
+	expectedEncoded, err := codec.EncodeValue(nil, expectedDatums...)
+	c.Assert(err, IsNil)
+	c.Assert([]byte(chunks[0].RowsData[:chunks[0].RowsMeta[0].Length]), BytesEquals, expectedEncoded)
+
+	// Selection and limit: c4 > 0.55 limit 1.
+	selection := &tipb.Executor{
+		Tp: tipb.ExecType_TypeSelection,
+		Selection: &tipb.Selection{Conditions: []*tipb.Expr{{
+			Tp: tipb.ExprType_GT,
+			Children: []*tipb.Expr{
+				{Tp: tipb.ExprType_ColumnRef, Val: codec.EncodeInt(nil, 2)},
+				{Tp: tipb.ExprType_Float64, Val: codec.EncodeFloat(nil, 0.55)},
+			},
+		}}},
+	}
+	limit := &tipb.Executor{Tp: tipb.ExecType_TypeLimit, Limit: &tipb.Limit{Limit: 1}}
+	executors := []*tipb.Executor{tblScan, selection, limit}
+	chunks = s.sendDAGRequest(c, store, txn.StartTS(), ranges, executors, 0)
+	c.Assert(chunksHandles(chunks), DeepEquals, []int64{6})
+
+	// Descending TopN: order by c4 desc limit 2.
+	topN := &tipb.Executor{
+		Tp: tipb.ExecType_TypeTopN,
+		TopN: &tipb.TopN{
+			OrderBy: []*tipb.ByItem{{Expr: &tipb.Expr{Tp: tipb.ExprType_ColumnRef, Val: codec.EncodeInt(nil, 2)}, Desc: true}},
+			Limit:   2,
+		},
+	}
+	executors = []*tipb.Executor{tblScan, topN}
+	chunks = s.sendDAGRequest(c, store, txn.StartTS(), ranges, executors, 0)
+	c.Assert(chunksHandles(chunks), DeepEquals, []int64{7, 6})
+
+	// Aggregation: count(c3).
+	agg := &tipb.Executor{
+		Tp: tipb.ExecType_TypeAggregation,
+		Aggregation: &tipb.Aggregation{AggFunc: []*tipb.Expr{{
+			Tp:       tipb.ExprType_Count,
+			Children: []*tipb.Expr{{Tp: tipb.ExprType_ColumnRef, Val: codec.EncodeInt(nil, 1)}},
+		}}},
+	}
+	executors = []*tipb.Executor{tblScan, agg}
+	chunks = s.sendDAGRequest(c, store, txn.StartTS(), ranges, executors, 0)
+	c.Assert(chunks, HasLen, 1)
+	cnt, err := codec.EncodeValue(nil, types.NewIntDatum(4))
+	c.Assert(err, IsNil)
+	c.Assert([]byte(chunks[0].RowsData), BytesEquals, cnt)
+
+	// Index scan.
+	idxInfo := tbInfo.toPBIndexInfo(0)
+	idxScan := &tipb.Executor{
+		Tp:      tipb.ExecType_TypeIndexScan,
+		IdxScan: &tipb.IndexScan{TableId: tbInfo.tID, IndexId: idxInfo.IndexId, Columns: idxInfo.Columns, Desc: true},
+	}
+	ranges = []kv.KeyRange{fullIndexRange(tbInfo.tID, tbInfo.iIDs[0])}
+	chunks = s.sendDAGRequest(c, store, txn.StartTS(), ranges, []*tipb.Executor{idxScan}, 0)
+	handles := chunksHandles(chunks)
+	c.Assert(handles, HasLen, int(count))
+	// The index values are strings, "varchar:9" is the largest one.
+	c.Assert(handles[0], Equals, int64(9))
+}
+
+func (s *testXAPISuite) sendDAGRequest(c *C, store kv.Storage, startTs uint64, ranges []kv.KeyRange,
+	executors []*tipb.Executor, outputOffsets ...uint32) []tipb.Chunk {
+	dagReq := &tipb.DAGRequest{
+		StartTs:       startTs,
+		Executors:     executors,
+		OutputOffsets: outputOffsets,
+	}
+	data, err := proto.Marshal(dagReq)
+	c.Assert(err, IsNil)
+	req := &kv.Request{
+		Tp:          kv.ReqTypeDAG,
+		Concurrency: 1,
+		KeyRanges:   ranges,
+		Data:        data,
+	}
+	resp := store.GetClient().Send(goctx.Background(), req)
+	defer resp.Close()
+	var chunks []tipb.Chunk
+	for {
+		data, err = resp.Next()
+		c.Assert(err, IsNil)
+		if data == nil {
+			break
+		}
+		selResp := new(tipb.SelectResponse)
+		err = proto.Unmarshal(data, selResp)
+		c.Assert(err, IsNil)
+		c.Assert(selResp.Error, IsNil)
+		chunks = append(chunks, selResp.Chunks...)
+	}
+	return chunks
+}
+
+func chunksHandles(chunks []tipb.Chunk) []int64 {
+	var handles []int64
+	for _, chunk := range chunks {
+		for _, meta := range chunk.RowsMeta {
+			handles = append(handles, meta.Handle)
+		}
+	}
+	return handles
+}
+
+func tablePointRange(tid int64, handle int64) kv.KeyRange {
+	key := tablecodec.EncodeRowKeyWithHandle(tid, handle)
+	return kv.KeyRange{StartKey: key, EndKey: key.PrefixNext()}
+}
+
 // simpleTableInfo just have the minimum information enough to describe the table.
 // The first column is pk handle column.
 type simpleTableInfo struct {
@@ -39,6 +39,8 @@ const (
 	actionPrewrite twoPhaseCommitAction = 1
 	actionCommit   twoPhaseCommitAction = 2
 	actionCleanup  twoPhaseCommitAction = 3
+
+	actionPessimisticLock twoPhaseCommitAction = 4
 )
 
 func (ca twoPhaseCommitAction) String() string {
@@ -49,6 +51,8 @@ func (ca twoPhaseCommitAction) String() string {
 		return "commit"
 	case actionCleanup:
 		return "cleanup"
+	case actionPessimisticLock:
+		return "pessimistic_lock"
 	}
 	return "unknown"
 }
@@ -74,6 +78,9 @@ type twoPhaseCommitter struct {
 		undetermined bool
 	}
 	priority pb.CommandPri
+	// forUpdateTS and lockWaitDeadline are used by actionPessimisticLock.
+	forUpdateTS      uint64
+	lockWaitDeadline time.Time
 }
 
 // newTwoPhaseCommitter creates a twoPhaseCommitter.
@@ -126,6 +133,17 @@ func newTwoPhaseCommitter(txn *tikvTxn) (*twoPhaseCommitter, error) {
 	if len(keys) == 0 {
 		return nil, nil
 	}
+	// The primary key of a pessimistic transaction is decided when the first key is locked,
+	// the locks acquired before prewrite point to it.
+	if len(txn.pessimisticKeys) > 0 {
+		primary := txn.pessimisticKeys[0]
+		for i, k := range keys {
+			if bytes.Equal(k, primary) {
+				keys[0], keys[i] = keys[i], keys[0]
+				break
+			}
+		}
+	}
 	entrylimit := atomic.LoadUint64(&kv.TxnEntryCountLimit)
 	if len(keys) > int(entrylimit) || size > kv.TxnTotalSizeLimit {
 		return nil, kv.ErrTxnTooLarge
@@ -208,8 +226,8 @@ func (c *twoPhaseCommitter) doActionOnKeys(bo *Backoffer, action twoPhaseCommitA
 	}
 
 	firstIsPrimary := bytes.Equal(keys[0], c.primary())
-	if firstIsPrimary && (action == actionCommit || action == actionCleanup) {
-		// primary should be committed/cleanup first
+	if firstIsPrimary && (action == actionCommit || action == actionCleanup || action == actionPessimisticLock) {
+		// primary should be committed/cleanup/locked first
 		err = c.doActionOnBatches(bo, action, batches[:1])
 		if err != nil {
 			return errors.Trace(err)
@@ -255,6 +273,8 @@ func (c *twoPhaseCommitter) doActionOnBatches(bo *Backoffer, action twoPhaseComm
 		singleBatchActionFunc = c.commitSingleBatch
 	case actionCleanup:
 		singleBatchActionFunc = c.cleanupSingleBatch
+	case actionPessimisticLock:
+		singleBatchActionFunc = c.pessimisticLockSingleBatch
 	}
 	if len(batches) == 1 {
 		e := singleBatchActionFunc(bo, batches[0])
@@ -264,10 +284,10 @@ func (c *twoPhaseCommitter) doActionOnBatches(bo *Backoffer, action twoPhaseComm
 		return errors.Trace(e)
 	}
 
-	// For prewrite, stop sending other requests after receiving first error.
+	// For prewrite and pessimistic lock, stop sending other requests after receiving first error.
 	backoffer := bo
 	var cancel goctx.CancelFunc
-	if action == actionPrewrite {
+	if action == actionPrewrite || action == actionPessimisticLock {
 		backoffer, cancel = bo.Fork()
 	}
 
@@ -397,6 +417,84 @@ func (c *twoPhaseCommitter) prewriteSingleBatch(bo *Backoffer, batch batchKeys)
 	}
 }
 
+func (c *twoPhaseCommitter) pessimisticLockSingleBatch(bo *Backoffer, batch batchKeys) error {
+	req := &tikvrpc.Request{
+		Type:     tikvrpc.CmdPessimisticLock,
+		Priority: c.priority,
+		PessimisticLock: &tikvrpc.PessimisticLockRequest{
+			Keys:         batch.keys,
+			PrimaryLock:  c.primary(),
+			StartVersion: c.startTS,
+			ForUpdateTs:  c.forUpdateTS,
+			LockTtl:      c.lockTTL,
+		},
+	}
+	for {
+		waitTimeout := c.lockWaitDeadline.Sub(time.Now())
+		if waitTimeout < 0 {
+			waitTimeout = 0
+		}
+		req.PessimisticLock.WaitTimeout = int64(waitTimeout / time.Millisecond)
+		resp, err := c.store.SendReq(bo, req, batch.region, readTimeoutShort+waitTimeout)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		regionErr, err := resp.GetRegionError()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if regionErr != nil {
+			err = bo.Backoff(boRegionMiss, errors.New(regionErr.String()))
+			if err != nil {
+				return errors.Trace(err)
+			}
+			err = c.pessimisticLockKeys(bo, batch.keys)
+			return errors.Trace(err)
+		}
+		lockResp := resp.PessimisticLock
+		if lockResp == nil {
+			return errors.Trace(errBodyMissing)
+		}
+		if lockResp.Deadlock {
+			log.Infof("pessimistic lock deadlock detected, tid: %d, waiting for: %d", c.startTS, lockResp.DeadlockLockTs)
+			return errors.Trace(kv.ErrDeadlock)
+		}
+		keyErrs := lockResp.Errors
+		if len(keyErrs) == 0 {
+			c.mu.Lock()
+			c.mu.writtenKeys = append(c.mu.writtenKeys, batch.keys...)
+			c.mu.Unlock()
+			return nil
+		}
+		var locks []*Lock
+		for _, keyErr := range keyErrs {
+			if keyErr.Retryable != "" {
+				log.Debugf("pessimistic lock encounters write conflict: %s, tid: %d", keyErr.Retryable, c.startTS)
+				return errors.Trace(kv.ErrWriteConflict)
+			}
+			lock, err1 := extractLockFromKeyErr(keyErr)
+			if err1 != nil {
+				return errors.Trace(err1)
+			}
+			locks = append(locks, lock)
+		}
+		// The locks may be left by crashed transactions, they can be resolved after expired.
+		ok, err := c.store.lockResolver.ResolveLocks(bo, locks)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !ok {
+			if !time.Now().Before(c.lockWaitDeadline) {
+				return errors.Trace(kv.ErrLockWaitTimeout)
+			}
+			err = bo.Backoff(boTxnLock, errors.Errorf("pessimistic lock lockedKeys: %d", len(locks)))
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+}
+
 func getTxnPriority(txn *tikvTxn) pb.CommandPri {
 	if pri := txn.us.GetOption(kv.Priority); pri != nil {
 		return kvPriorityToCommandPri(pri.(int))
@@ -524,6 +622,10 @@ func (c *twoPhaseCommitter) cleanupKeys(bo *Backoffer, keys [][]byte) error {
 	return c.doActionOnKeys(bo, actionCleanup, keys)
 }
 
+func (c *twoPhaseCommitter) pessimisticLockKeys(bo *Backoffer, keys [][]byte) error {
+	return c.doActionOnKeys(bo, actionPessimisticLock, keys)
+}
+
 // The max time a Txn may use (in ms) from its startTS to commitTS.
 // We use it to guarantee GC worker will not influence any active txn. The value
 // should be less than `gcRunInterval`.
@@ -23,6 +23,7 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/errorpb"
 	"github.com/pingcap/kvproto/pkg/kvrpcpb"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/store/tikv/mock-tikv"
 	"github.com/pingcap/tidb/store/tikv/tikvrpc"
 	"github.com/pingcap/tidb/terror"
@@ -495,3 +496,91 @@ func (s *testCommitterSuite) TestCommitTimeout(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(len(value), Greater, 0)
 }
+
+func (s *testCommitterSuite) beginPessimistic(c *C, lockWaitTimeout int64) *tikvTxn {
+	txn := s.begin(c)
+	txn.SetOption(kv.Pessimistic, true)
+	txn.SetOption(kv.ForUpdateTS, txn.StartTS())
+	txn.SetOption(kv.LockWaitTimeout, lockWaitTimeout)
+	return txn
+}
+
+func (s *testCommitterSuite) TestPessimisticLock(c *C) {
+	s.mustCommit(c, map[string]string{"a": "a0"})
+
+	txn1 := s.beginPessimistic(c, 0)
+	c.Assert(txn1.LockKeys(kv.Key("a"), kv.Key("b")), IsNil)
+	c.Assert(txn1.pessimisticKeys[0], BytesEquals, []byte("a"))
+
+	// The locked keys can still be read.
+	s.checkValues(c, map[string]string{"a": "a0"})
+
+	// The lock wait times out.
+	txn2 := s.beginPessimistic(c, 100)
+	start := time.Now()
+	err := txn2.LockKeys(kv.Key("a"))
+	c.Assert(kv.ErrLockWaitTimeout.Equal(err), IsTrue)
+	c.Assert(time.Since(start), GreaterEqual, 100*time.Millisecond)
+
+	// The waiting transaction is woken up when txn1 commits, and meets a write conflict.
+	txn2.SetOption(kv.LockWaitTimeout, int64(5000))
+	ch := make(chan error)
+	go func() {
+		ch <- txn2.LockKeys(kv.Key("a"))
+	}()
+	time.Sleep(50 * time.Millisecond)
+	c.Assert(txn1.Set([]byte("a"), []byte("a1")), IsNil)
+	c.Assert(txn1.Commit(), IsNil)
+	err = <-ch
+	c.Assert(kv.ErrWriteConflict.Equal(err), IsTrue)
+
+	// Locking with a new for update ts succeeds.
+	forUpdateTS, err := s.store.oracle.GetTimestamp(goctx.Background())
+	c.Assert(err, IsNil)
+	txn2.SetOption(kv.ForUpdateTS, forUpdateTS)
+	c.Assert(txn2.LockKeys(kv.Key("a")), IsNil)
+	c.Assert(txn2.Set([]byte("a"), []byte("a2")), IsNil)
+	c.Assert(txn2.Commit(), IsNil)
+	s.checkValues(c, map[string]string{"a": "a2"})
+}
+
+func (s *testCommitterSuite) TestPessimisticForUpdateTS(c *C) {
+	txn := s.beginPessimistic(c, 0)
+	s.mustCommit(c, map[string]string{"x": "x1"})
+
+	// The key committed after the start ts is read at the for update ts.
+	forUpdateTS, err := s.store.oracle.GetTimestamp(goctx.Background())
+	c.Assert(err, IsNil)
+	txn.SetOption(kv.ForUpdateTS, forUpdateTS)
+	value, err := txn.Get(kv.Key("x"))
+	c.Assert(err, IsNil)
+	c.Assert(value, BytesEquals, []byte("x1"))
+
+	// It's not visible after the for update ts is deleted.
+	txn.DelOption(kv.ForUpdateTS)
+	_, err = txn.Get(kv.Key("x"))
+	c.Assert(kv.IsErrNotFound(err), IsTrue)
+	c.Assert(txn.Rollback(), IsNil)
+}
+
+func (s *testCommitterSuite) TestPessimisticLockDeadlock(c *C) {
+	txn1 := s.beginPessimistic(c, 5000)
+	txn2 := s.beginPessimistic(c, 5000)
+	c.Assert(txn1.LockKeys(kv.Key("a")), IsNil)
+	c.Assert(txn2.LockKeys(kv.Key("c")), IsNil)
+
+	ch := make(chan error)
+	go func() {
+		ch <- txn1.LockKeys(kv.Key("c"))
+	}()
+	time.Sleep(50 * time.Millisecond)
+	err := txn2.LockKeys(kv.Key("a"))
+	c.Assert(kv.ErrDeadlock.Equal(err), IsTrue)
+
+	// The locks of txn2 are released by rollback, then txn1 gets the lock.
+	c.Assert(txn2.Rollback(), IsNil)
+	c.Assert(<-ch, IsNil)
+	c.Assert(txn1.Set([]byte("c"), []byte("c1")), IsNil)
+	c.Assert(txn1.Commit(), IsNil)
+	s.checkValues(c, map[string]string{"c": "c1"})
+}
@@ -123,6 +123,7 @@ const (
 	copNextMaxBackoff       = 20000
 	getMaxBackoff           = 20000
 	prewriteMaxBackoff      = 20000
+	pessimisticMaxBackoff   = 20000
 	cleanupMaxBackoff       = 20000
 	gcMaxBackoff            = 100000
 	gcResolveLockMaxBackoff = 100000
@@ -264,6 +264,8 @@ func (c *rpcClient) callRPC(ctx goctx.Context, client tikvpb.TikvClient, req *ti
 		}
 		resp.DeleteRange = r
 		return resp, nil
+	case tikvrpc.CmdPessimisticLock:
+		return nil, errors.Errorf("pessimistic lock is not supported by the tikv client")
 	case tikvrpc.CmdRawGet:
 		r, err := client.RawGet(ctx, req.RawGet)
 		if err != nil {
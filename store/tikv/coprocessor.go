@@ -444,6 +444,7 @@ func (it *copIterator) Next() ([]byte, error) {
 func (it *copIterator) handleTask(bo *Backoffer, task *copTask) []copResponse {
 	coprocessorCounter.WithLabelValues("handle_task").Inc()
 	sender := NewRegionRequestSender(it.store.regionCache, it.store.client, pbIsolationLevel(it.req.IsolationLevel))
+	startTime := time.Now()
 	for {
 		select {
 		case <-it.finished:
@@ -491,6 +492,10 @@ func (it *copIterator) handleTask(bo *Backoffer, task *copTask) []copResponse {
 			return []copResponse{{err: errors.Trace(err)}}
 		}
 		task.storeAddr = sender.storeAddr
+		if it.req.CopStats != nil {
+			// The tasks rebuilt on region errors are recorded respectively.
+			it.req.CopStats.RecordOneCopTask(task.region.id, task.storeAddr, time.Since(startTime))
+		}
 		return []copResponse{{Response: resp.Cop}}
 	}
 }
@@ -81,6 +81,10 @@ var defaultLockTTL uint64 = 3000
 // TODO: Consider if it's appropriate.
 var maxLockTTL uint64 = 120000
 
+// pessimisticLockTTL is the base ttl of the locks acquired by pessimistic transactions
+// before prewrite, they are held while the client executes the following statements.
+var pessimisticLockTTL uint64 = 20000
+
 // ttl = ttlFactor * sqrt(writeSizeInMiB)
 var ttlFactor = 6000
 
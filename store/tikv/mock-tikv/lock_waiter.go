@@ -0,0 +1,71 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mocktikv
+
+import "sync"
+
+// lockWaiter makes pessimistic lock requests wait for the locks held by other transactions,
+// and detects deadlocks among the waiting transactions.
+type lockWaiter struct {
+	sync.Mutex
+	// released is closed and replaced when some locks may have been released.
+	released chan struct{}
+	// waitFor maps the start ts of a waiting transaction to the start ts of the transaction
+	// holding the lock it waits for.
+	waitFor map[uint64]uint64
+}
+
+func newLockWaiter() *lockWaiter {
+	return &lockWaiter{
+		released: make(chan struct{}),
+		waitFor:  make(map[uint64]uint64),
+	}
+}
+
+// releasedCh returns a channel which is closed the next time locks are released.
+// It should be fetched before trying to lock keys, so that no release is missed.
+func (w *lockWaiter) releasedCh() <-chan struct{} {
+	w.Lock()
+	defer w.Unlock()
+	return w.released
+}
+
+// notify wakes up all the waiting transactions.
+func (w *lockWaiter) notify() {
+	w.Lock()
+	defer w.Unlock()
+	close(w.released)
+	w.released = make(chan struct{})
+}
+
+// wait records that the transaction startTS waits for the transaction lockTS.
+// It returns false if the waiting forms a cycle, which means a deadlock.
+func (w *lockWaiter) wait(startTS, lockTS uint64) bool {
+	w.Lock()
+	defer w.Unlock()
+	for ts, ok := lockTS, true; ok; ts, ok = w.waitFor[ts] {
+		if ts == startTS {
+			return false
+		}
+	}
+	w.waitFor[startTS] = lockTS
+	return true
+}
+
+// done removes the waiting record of the transaction startTS.
+func (w *lockWaiter) done(startTS uint64) {
+	w.Lock()
+	defer w.Unlock()
+	delete(w.waitFor, startTS)
+}
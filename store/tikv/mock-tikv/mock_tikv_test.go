@@ -352,13 +352,59 @@ func (s *testMockTiKVSuite) TestRC(c *C) {
 	s.mustGetRC(c, "key", 20, "v1")
 }
 
+func (s *testMockTiKVSuite) mustPessimisticLockOK(c *C, key, primary string, startTS, forUpdateTS uint64) {
+	errs := s.store.PessimisticLock([][]byte{[]byte(key)}, []byte(primary), startTS, forUpdateTS, 0)
+	for _, err := range errs {
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *testMockTiKVSuite) TestPessimisticLock(c *C) {
+	s.mustPutOK(c, "x", "v1", 5, 10)
+	s.mustPutOK(c, "y", "v1", 5, 10)
+	// The pessimistic lock does not block reads.
+	s.mustPessimisticLockOK(c, "x", "x", 8, 12)
+	s.mustGetOK(c, "x", 15, "v1")
+	// Locking it again by the same transaction is a no-op.
+	s.mustPessimisticLockOK(c, "x", "x", 8, 12)
+
+	// Other transactions meet the lock.
+	errs := s.store.PessimisticLock([][]byte{[]byte("x")}, []byte("x"), 13, 13, 0)
+	_, ok := errs[0].(*ErrLocked)
+	c.Assert(ok, IsTrue)
+	errs = s.store.Prewrite(putMutations("x", "v2"), []byte("x"), 13, 0)
+	_, ok = errs[0].(*ErrLocked)
+	c.Assert(ok, IsTrue)
+
+	// The write conflict is checked with forUpdateTS, nothing is locked if any key fails.
+	errs = s.store.PessimisticLock([][]byte{[]byte("z"), []byte("y")}, []byte("x"), 8, 9, 0)
+	c.Assert(errs[0], IsNil)
+	s.mustWriteWriteConflict(c, errs, 1)
+	s.mustPessimisticLockOK(c, "y", "x", 8, 12)
+	s.mustScanLock(c, 20, []*kvrpcpb.LockInfo{lock("x", "x", 8), lock("y", "x", 8)})
+
+	// The prewrite replaces the pessimistic lock without checking write conflicts with startTS.
+	s.mustPrewriteOK(c, putMutations("x", "v2"), "x", 8)
+	s.mustGetErr(c, "x", 15)
+	s.mustCommitOK(c, [][]byte{[]byte("x"), []byte("y")}, 8, 16)
+	s.mustGetOK(c, "x", 20, "v2")
+	s.mustGetOK(c, "y", 20, "v1")
+
+	// A rolled back transaction can not lock keys any more.
+	s.mustPessimisticLockOK(c, "x", "x", 18, 18)
+	s.mustRollbackOK(c, [][]byte{[]byte("x")}, 18)
+	errs = s.store.PessimisticLock([][]byte{[]byte("x")}, []byte("x"), 18, 18, 0)
+	c.Assert(errs[0], NotNil)
+}
+
 func (s testMarshal) TestMarshalmvccLock(c *C) {
 	l := mvccLock{
-		startTS: 47,
-		primary: []byte{'a', 'b', 'c'},
-		value:   []byte{'d', 'e'},
-		op:      kvrpcpb.Op_Put,
-		ttl:     444,
+		startTS:     47,
+		primary:     []byte{'a', 'b', 'c'},
+		value:       []byte{'d', 'e'},
+		op:          kvrpcpb.Op_Put,
+		ttl:         444,
+		forUpdateTS: 50,
 	}
 	bin, err := l.MarshalBinary()
 	c.Assert(err, IsNil)
@@ -370,6 +416,7 @@ func (s testMarshal) TestMarshalmvccLock(c *C) {
 	c.Assert(l.startTS, Equals, l1.startTS)
 	c.Assert(l.op, Equals, l1.op)
 	c.Assert(l.ttl, Equals, l1.ttl)
+	c.Assert(l.forUpdateTS, Equals, l1.forUpdateTS)
 	c.Assert(string(l.primary), Equals, string(l1.primary))
 	c.Assert(string(l.value), Equals, string(l1.value))
 }
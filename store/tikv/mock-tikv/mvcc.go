@@ -47,6 +47,8 @@ type mvccLock struct {
 	value   []byte
 	op      kvrpcpb.Op
 	ttl     uint64
+	// forUpdateTS is set for the locks acquired by pessimistic transactions before prewrite.
+	forUpdateTS uint64
 }
 
 type mvccEntry struct {
@@ -66,6 +68,7 @@ func (l *mvccLock) MarshalBinary() ([]byte, error) {
 	mh.WriteSlice(&buf, l.value)
 	mh.WriteNumber(&buf, l.op)
 	mh.WriteNumber(&buf, l.ttl)
+	mh.WriteNumber(&buf, l.forUpdateTS)
 	return buf.Bytes(), errors.Trace(mh.err)
 }
 
@@ -78,6 +81,7 @@ func (l *mvccLock) UnmarshalBinary(data []byte) error {
 	mh.ReadSlice(buf, &l.value)
 	mh.ReadNumber(buf, &l.op)
 	mh.ReadNumber(buf, &l.ttl)
+	mh.ReadNumber(buf, &l.forUpdateTS)
 	return errors.Trace(mh.err)
 }
 
@@ -186,6 +190,12 @@ func newEntry(key MvccKey) *mvccEntry {
 	}
 }
 
+// isPessimistic returns whether the lock is acquired by a pessimistic transaction and not prewritten yet.
+// Such a lock does not block reads.
+func (l *mvccLock) isPessimistic() bool {
+	return l.forUpdateTS != 0
+}
+
 // lockErr returns ErrLocked.
 // Note that parameter key is raw key, while key in ErrLocked is mvcc key.
 func (l *mvccLock) lockErr(key []byte) error {
@@ -210,11 +220,12 @@ func (e *mvccEntry) Clone() *mvccEntry {
 	}
 	if e.lock != nil {
 		entry.lock = &mvccLock{
-			startTS: e.lock.startTS,
-			primary: append([]byte(nil), e.lock.primary...),
-			value:   append([]byte(nil), e.lock.value...),
-			op:      e.lock.op,
-			ttl:     e.lock.ttl,
+			startTS:     e.lock.startTS,
+			primary:     append([]byte(nil), e.lock.primary...),
+			value:       append([]byte(nil), e.lock.value...),
+			op:          e.lock.op,
+			ttl:         e.lock.ttl,
+			forUpdateTS: e.lock.forUpdateTS,
 		}
 	}
 	return &entry
@@ -235,7 +246,7 @@ func (e *mvccEntry) lockErr() error {
 
 func (e *mvccEntry) Get(ts uint64, isoLevel kvrpcpb.IsolationLevel) ([]byte, error) {
 	if isoLevel == kvrpcpb.IsolationLevel_SI {
-		if e.lock != nil && e.lock.startTS <= ts {
+		if e.lock != nil && e.lock.startTS <= ts && !e.lock.isPessimistic() {
 			return nil, e.lockErr()
 		}
 	}
@@ -248,16 +259,20 @@ func (e *mvccEntry) Get(ts uint64, isoLevel kvrpcpb.IsolationLevel) ([]byte, err
 }
 
 func (e *mvccEntry) Prewrite(mutation *kvrpcpb.Mutation, startTS uint64, primary []byte, ttl uint64) error {
-	if len(e.values) > 0 {
-		if e.values[0].commitTS >= startTS {
-			return ErrRetryable("write conflict")
+	// The write conflicts of a key locked by the pessimistic transaction itself have been checked
+	// when the lock was acquired, so the pessimistic lock is replaced directly.
+	if e.lock == nil || e.lock.startTS != startTS || !e.lock.isPessimistic() {
+		if len(e.values) > 0 {
+			if e.values[0].commitTS >= startTS {
+				return ErrRetryable("write conflict")
+			}
 		}
-	}
-	if e.lock != nil {
-		if e.lock.startTS != startTS {
-			return e.lockErr()
+		if e.lock != nil {
+			if e.lock.startTS != startTS {
+				return e.lockErr()
+			}
+			return nil
 		}
-		return nil
 	}
 	e.lock = &mvccLock{
 		startTS: startTS,
@@ -269,6 +284,41 @@ func (e *mvccEntry) Prewrite(mutation *kvrpcpb.Mutation, startTS uint64, primary
 	return nil
 }
 
+// PessimisticLock locks the key for a pessimistic transaction, which reads the key at forUpdateTS.
+func (e *mvccEntry) PessimisticLock(startTS, forUpdateTS uint64, primary []byte, ttl uint64) error {
+	if e.lock != nil {
+		if e.lock.startTS != startTS {
+			return e.lockErr()
+		}
+		return nil
+	}
+	for _, v := range e.values {
+		if v.commitTS < startTS {
+			break
+		}
+		if v.startTS == startTS {
+			return ErrAbort("txn has been rolled back")
+		}
+	}
+	for _, v := range e.values {
+		if v.valueType == typeRollback {
+			continue
+		}
+		if v.commitTS >= forUpdateTS {
+			return ErrRetryable("write conflict")
+		}
+		break
+	}
+	e.lock = &mvccLock{
+		startTS:     startTS,
+		primary:     primary,
+		op:          kvrpcpb.Op_Lock,
+		ttl:         ttl,
+		forUpdateTS: forUpdateTS,
+	}
+	return nil
+}
+
 func (e *mvccEntry) getTxnCommitInfo(startTS uint64) *mvccValue {
 	for _, v := range e.values {
 		if v.startTS == startTS {
@@ -419,6 +469,7 @@ type MVCCStore interface {
 	ReverseScan(startKey, endKey []byte, limit int, startTS uint64, isoLevel kvrpcpb.IsolationLevel) []Pair
 	BatchGet(ks [][]byte, startTS uint64, isoLevel kvrpcpb.IsolationLevel) []Pair
 	Prewrite(mutations []*kvrpcpb.Mutation, primary []byte, startTS uint64, ttl uint64) []error
+	PessimisticLock(keys [][]byte, primary []byte, startTS, forUpdateTS uint64, ttl uint64) []error
 	Commit(keys [][]byte, startTS, commitTS uint64) error
 	Rollback(keys [][]byte, startTS uint64) error
 	Cleanup(key []byte, startTS uint64) error
@@ -598,6 +649,30 @@ func (s *MvccStore) Prewrite(mutations []*kvrpcpb.Mutation, primary []byte, star
 	return errs
 }
 
+// PessimisticLock locks keys for a pessimistic transaction before it prewrites.
+// The keys are locked only if all of them can be locked.
+func (s *MvccStore) PessimisticLock(keys [][]byte, primary []byte, startTS, forUpdateTS uint64, ttl uint64) []error {
+	s.Lock()
+	defer s.Unlock()
+
+	anyError := false
+	errs := make([]error, 0, len(keys))
+	ents := make([]*mvccEntry, 0, len(keys))
+	for _, k := range keys {
+		entry := s.getOrNewEntry(NewMvccKey(k))
+		err := entry.PessimisticLock(startTS, forUpdateTS, primary, ttl)
+		errs = append(errs, err)
+		ents = append(ents, entry)
+		if err != nil {
+			anyError = true
+		}
+	}
+	if !anyError {
+		s.submit(ents...)
+	}
+	return errs
+}
+
 // Commit commits the lock on a key. (2nd phase of 2PC).
 func (s *MvccStore) Commit(keys [][]byte, startTS, commitTS uint64) error {
 	s.Lock()
@@ -305,7 +305,7 @@ func getValue(iter *Iterator, key []byte, startTS uint64, isoLevel kvrpcpb.Isola
 		return nil, errors.Trace(err)
 	}
 	if ok {
-		if isoLevel == kvrpcpb.IsolationLevel_SI && dec1.lock.startTS <= startTS {
+		if isoLevel == kvrpcpb.IsolationLevel_SI && dec1.lock.startTS <= startTS && !dec1.lock.isPessimistic() {
 			return nil, dec1.lock.lockErr(key)
 		}
 	}
@@ -440,23 +440,27 @@ func prewriteMutation(db *leveldb.DB, batch *leveldb.Batch, mutation *kvrpcpb.Mu
 	if err != nil {
 		return errors.Trace(err)
 	}
-	if ok {
-		if dec.lock.startTS != startTS {
-			return dec.lock.lockErr(mutation.Key)
+	// The write conflicts of a key locked by the pessimistic transaction itself have been checked
+	// when the lock was acquired, so the pessimistic lock is replaced directly.
+	if !ok || dec.lock.startTS != startTS || !dec.lock.isPessimistic() {
+		if ok {
+			if dec.lock.startTS != startTS {
+				return dec.lock.lockErr(mutation.Key)
+			}
+			return nil
 		}
-		return nil
-	}
 
-	dec1 := valueDecoder{
-		expectKey: mutation.Key,
-	}
-	ok, err = dec1.Decode(iter)
-	if err != nil {
-		return errors.Trace(err)
-	}
-	// Note that it's a write conflict here, even if the value is a rollback one.
-	if ok && dec1.value.commitTS >= startTS {
-		return ErrRetryable("write conflict")
+		dec1 := valueDecoder{
+			expectKey: mutation.Key,
+		}
+		ok, err = dec1.Decode(iter)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		// Note that it's a write conflict here, even if the value is a rollback one.
+		if ok && dec1.value.commitTS >= startTS {
+			return ErrRetryable("write conflict")
+		}
 	}
 
 	lock := mvccLock{
@@ -475,6 +479,90 @@ func prewriteMutation(db *leveldb.DB, batch *leveldb.Batch, mutation *kvrpcpb.Mu
 	return nil
 }
 
+// PessimisticLock implements the MVCCStore interface.
+func (mvcc *MVCCLevelDB) PessimisticLock(keys [][]byte, primary []byte, startTS, forUpdateTS uint64, ttl uint64) []error {
+	mvcc.mu.Lock()
+	defer mvcc.mu.Unlock()
+
+	anyError := false
+	batch := &leveldb.Batch{}
+	errs := make([]error, 0, len(keys))
+	for _, k := range keys {
+		err := pessimisticLockKey(mvcc.db, batch, k, primary, startTS, forUpdateTS, ttl)
+		errs = append(errs, err)
+		if err != nil {
+			anyError = true
+		}
+	}
+	if anyError {
+		return errs
+	}
+	if err := mvcc.db.Write(batch, nil); err != nil {
+		return nil
+	}
+
+	return errs
+}
+
+func pessimisticLockKey(db *leveldb.DB, batch *leveldb.Batch, key []byte, primary []byte, startTS, forUpdateTS uint64, ttl uint64) error {
+	startKey := mvccEncode(key, lockVer)
+	iter := newIterator(db, &util.Range{
+		Start: startKey,
+	})
+	defer iter.Release()
+
+	dec := lockDecoder{
+		expectKey: key,
+	}
+	ok, err := dec.Decode(iter)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if ok {
+		if dec.lock.startTS != startTS {
+			return dec.lock.lockErr(key)
+		}
+		return nil
+	}
+
+	dec1 := valueDecoder{
+		expectKey: key,
+	}
+	conflictChecked := false
+	for iter.Valid() {
+		ok, err = dec1.Decode(iter)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !ok || dec1.value.commitTS < startTS {
+			break
+		}
+		if dec1.value.startTS == startTS {
+			return ErrAbort("txn has been rolled back")
+		}
+		if !conflictChecked && dec1.value.valueType != typeRollback {
+			if dec1.value.commitTS >= forUpdateTS {
+				return ErrRetryable("write conflict")
+			}
+			conflictChecked = true
+		}
+	}
+
+	lock := mvccLock{
+		startTS:     startTS,
+		primary:     primary,
+		op:          kvrpcpb.Op_Lock,
+		ttl:         ttl,
+		forUpdateTS: forUpdateTS,
+	}
+	writeValue, err := lock.MarshalBinary()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	batch.Put(startKey, writeValue)
+	return nil
+}
+
 // Commit implements the MVCCStore interface.
 func (mvcc *MVCCLevelDB) Commit(keys [][]byte, startTS, commitTS uint64) error {
 	mvcc.mu.Lock()
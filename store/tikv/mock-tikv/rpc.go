@@ -14,6 +14,8 @@
 package mocktikv
 
 import (
+	"time"
+
 	"github.com/golang/protobuf/proto"
 	"github.com/juju/errors"
 	"github.com/pingcap/kvproto/pkg/coprocessor"
@@ -86,8 +88,9 @@ func convertToPbPairs(pairs []Pair) []*kvrpcpb.KvPair {
 }
 
 type rpcHandler struct {
-	cluster   *Cluster
-	mvccStore MVCCStore
+	cluster    *Cluster
+	mvccStore  MVCCStore
+	lockWaiter *lockWaiter
 
 	// store id for current request
 	storeID uint64
@@ -250,6 +253,7 @@ func (h *rpcHandler) handleKvCommit(req *kvrpcpb.CommitRequest) *kvrpcpb.CommitR
 	if err != nil {
 		resp.Error = convertToKeyError(err)
 	}
+	h.lockWaiter.notify()
 	return &resp
 }
 
@@ -259,6 +263,7 @@ func (h *rpcHandler) handleKvCleanup(req *kvrpcpb.CleanupRequest) *kvrpcpb.Clean
 	}
 	var resp kvrpcpb.CleanupResponse
 	err := h.mvccStore.Cleanup(req.Key, req.GetStartVersion())
+	h.lockWaiter.notify()
 	if err != nil {
 		if commitTS, ok := errors.Cause(err).(ErrAlreadyCommitted); ok {
 			resp.CommitVersion = uint64(commitTS)
@@ -311,6 +316,7 @@ func (h *rpcHandler) handleMvccGetByStartTS(req *kvrpcpb.MvccGetByStartTsRequest
 
 func (h *rpcHandler) handleKvBatchRollback(req *kvrpcpb.BatchRollbackRequest) *kvrpcpb.BatchRollbackResponse {
 	err := h.mvccStore.Rollback(req.Keys, req.StartVersion)
+	h.lockWaiter.notify()
 	if err != nil {
 		return &kvrpcpb.BatchRollbackResponse{
 			Error: convertToKeyError(err),
@@ -333,6 +339,7 @@ func (h *rpcHandler) handleKvScanLock(req *kvrpcpb.ScanLockRequest) *kvrpcpb.Sca
 
 func (h *rpcHandler) handleKvResolveLock(req *kvrpcpb.ResolveLockRequest) *kvrpcpb.ResolveLockResponse {
 	err := h.mvccStore.ResolveLock(h.startKey, h.endKey, req.GetStartVersion(), req.GetCommitVersion())
+	h.lockWaiter.notify()
 	if err != nil {
 		return &kvrpcpb.ResolveLockResponse{
 			Error: convertToKeyError(err),
@@ -341,6 +348,52 @@ func (h *rpcHandler) handleKvResolveLock(req *kvrpcpb.ResolveLockRequest) *kvrpc
 	return &kvrpcpb.ResolveLockResponse{}
 }
 
+func (h *rpcHandler) handleKvPessimisticLock(req *tikvrpc.PessimisticLockRequest) *tikvrpc.PessimisticLockResponse {
+	for _, k := range req.Keys {
+		if !h.checkKeyInRegion(k) {
+			panic("KvPessimisticLock: key not in region")
+		}
+	}
+	startTS := req.StartVersion
+	defer h.lockWaiter.done(startTS)
+	deadline := time.Now().Add(time.Duration(req.WaitTimeout) * time.Millisecond)
+	for {
+		released := h.lockWaiter.releasedCh()
+		errs := h.mvccStore.PessimisticLock(req.Keys, req.PrimaryLock, startTS, req.ForUpdateTs, req.LockTtl)
+		var locked *ErrLocked
+		for _, err := range errs {
+			if err == nil {
+				continue
+			}
+			l, ok := errors.Cause(err).(*ErrLocked)
+			if !ok {
+				// Other errors can not be resolved by waiting.
+				locked = nil
+				break
+			}
+			if locked == nil {
+				locked = l
+			}
+		}
+		timeout := deadline.Sub(time.Now())
+		if locked == nil || timeout <= 0 {
+			return &tikvrpc.PessimisticLockResponse{
+				Errors: convertToKeyErrors(errs),
+			}
+		}
+		if !h.lockWaiter.wait(startTS, locked.StartTS) {
+			return &tikvrpc.PessimisticLockResponse{
+				Deadlock:       true,
+				DeadlockLockTs: locked.StartTS,
+			}
+		}
+		select {
+		case <-released:
+		case <-time.After(timeout):
+		}
+	}
+}
+
 func (h *rpcHandler) handleKvDeleteRange(req *kvrpcpb.DeleteRangeRequest) *kvrpcpb.DeleteRangeResponse {
 	return &kvrpcpb.DeleteRangeResponse{
 		Error: "not implemented",
@@ -401,13 +454,16 @@ func (h *rpcHandler) handleKvRawScan(req *kvrpcpb.RawScanRequest) *kvrpcpb.RawSc
 type RPCClient struct {
 	Cluster   *Cluster
 	MvccStore MVCCStore
+
+	lockWaiter *lockWaiter
 }
 
 // NewRPCClient creates an RPCClient.
 func NewRPCClient(cluster *Cluster, mvccStore MVCCStore) *RPCClient {
 	return &RPCClient{
-		Cluster:   cluster,
-		MvccStore: mvccStore,
+		Cluster:    cluster,
+		MvccStore:  mvccStore,
+		lockWaiter: newLockWaiter(),
 	}
 }
 
@@ -436,8 +492,9 @@ func (c *RPCClient) checkArgs(ctx goctx.Context, addr string) (*rpcHandler, erro
 		return nil, err
 	}
 	handler := &rpcHandler{
-		cluster:   c.Cluster,
-		mvccStore: c.MvccStore,
+		cluster:    c.Cluster,
+		mvccStore:  c.MvccStore,
+		lockWaiter: c.lockWaiter,
 		// set store id for current request
 		storeID: store.GetId(),
 	}
@@ -535,6 +592,13 @@ func (c *RPCClient) SendReq(ctx goctx.Context, addr string, req *tikvrpc.Request
 			return resp, nil
 		}
 		resp.DeleteRange = &kvrpcpb.DeleteRangeResponse{}
+	case tikvrpc.CmdPessimisticLock:
+		r := req.PessimisticLock
+		if err := handler.checkRequest(reqCtx, r.Size()); err != nil {
+			resp.PessimisticLock = &tikvrpc.PessimisticLockResponse{RegionError: err}
+			return resp, nil
+		}
+		resp.PessimisticLock = handler.handleKvPessimisticLock(r)
 	case tikvrpc.CmdRawGet:
 		r := req.RawGet
 		if err := handler.checkRequest(reqCtx, r.Size()); err != nil {
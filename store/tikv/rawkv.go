@@ -14,6 +14,8 @@
 package tikv
 
 import (
+	"bytes"
+	"sync"
 	"time"
 
 	"github.com/juju/errors"
@@ -30,8 +32,12 @@ var (
 	ErrMaxScanLimitExceeded = errors.New("limit should be less than MaxRawKVScanLimit")
 )
 
+// rawBatchKeyCount is the maximum number of keys of a region which are sent one by one by a
+// single goroutine of a batch operation.
+const rawBatchKeyCount = 64
+
 // RawKVClient is a client of TiKV server which is used as a key-value storage,
-// only GET/PUT/DELETE commands are supported.
+// only GET/PUT/DELETE/SCAN commands and their batch versions are supported.
 type RawKVClient struct {
 	clusterID   uint64
 	regionCache *RegionCache
@@ -69,16 +75,28 @@ func (c *RawKVClient) Get(key []byte) ([]byte, error) {
 	start := time.Now()
 	defer func() { rawkvCmdHistogram.WithLabelValues("get").Observe(time.Since(start).Seconds()) }()
 
-	req := &tikvrpc.Request{
+	bo := NewBackoffer(rawkvMaxBackoff, goctx.Background())
+	return c.get(bo, key)
+}
+
+func (c *RawKVClient) get(bo *Backoffer, key []byte) ([]byte, error) {
+	resp, _, err := c.sendReq(bo, key, rawGetRequest(key))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return rawGetValue(resp)
+}
+
+func rawGetRequest(key []byte) *tikvrpc.Request {
+	return &tikvrpc.Request{
 		Type: tikvrpc.CmdRawGet,
 		RawGet: &kvrpcpb.RawGetRequest{
 			Key: key,
 		},
 	}
-	resp, _, err := c.sendReq(key, req)
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
+}
+
+func rawGetValue(resp *tikvrpc.Response) ([]byte, error) {
 	cmdResp := resp.RawGet
 	if cmdResp == nil {
 		return nil, errors.Trace(errBodyMissing)
@@ -92,6 +110,34 @@ func (c *RawKVClient) Get(key []byte) ([]byte, error) {
 	return cmdResp.Value, nil
 }
 
+// BatchGet queries values with the keys. The value of a key which does not exist is nil.
+func (c *RawKVClient) BatchGet(keys [][]byte) ([][]byte, error) {
+	start := time.Now()
+	defer func() { rawkvCmdHistogram.WithLabelValues("batch_get").Observe(time.Since(start).Seconds()) }()
+
+	var mu sync.Mutex
+	valueMap := make(map[string][]byte, len(keys))
+	bo := NewBackoffer(rawkvMaxBackoff, goctx.Background())
+	err := c.doBatchReq(bo, keys, rawGetRequest, func(key []byte, resp *tikvrpc.Response) error {
+		value, err := rawGetValue(resp)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		mu.Lock()
+		valueMap[string(key)] = value
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		values[i] = valueMap[string(key)]
+	}
+	return values, nil
+}
+
 // Put stores a key-value pair to TiKV.
 func (c *RawKVClient) Put(key, value []byte) error {
 	start := time.Now()
@@ -103,17 +149,29 @@ func (c *RawKVClient) Put(key, value []byte) error {
 		return errors.New("empty value is not supported")
 	}
 
-	req := &tikvrpc.Request{
+	bo := NewBackoffer(rawkvMaxBackoff, goctx.Background())
+	return c.put(bo, key, value)
+}
+
+func (c *RawKVClient) put(bo *Backoffer, key, value []byte) error {
+	resp, _, err := c.sendReq(bo, key, rawPutRequest(key, value))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return rawPutError(resp)
+}
+
+func rawPutRequest(key, value []byte) *tikvrpc.Request {
+	return &tikvrpc.Request{
 		Type: tikvrpc.CmdRawPut,
 		RawPut: &kvrpcpb.RawPutRequest{
 			Key:   key,
 			Value: value,
 		},
 	}
-	resp, _, err := c.sendReq(key, req)
-	if err != nil {
-		return errors.Trace(err)
-	}
+}
+
+func rawPutError(resp *tikvrpc.Response) error {
 	cmdResp := resp.RawPut
 	if cmdResp == nil {
 		return errors.Trace(errBodyMissing)
@@ -124,21 +182,60 @@ func (c *RawKVClient) Put(key, value []byte) error {
 	return nil
 }
 
+// BatchPut stores key-value pairs to TiKV.
+func (c *RawKVClient) BatchPut(keys, values [][]byte) error {
+	start := time.Now()
+	defer func() { rawkvCmdHistogram.WithLabelValues("batch_put").Observe(time.Since(start).Seconds()) }()
+
+	if len(keys) != len(values) {
+		return errors.New("the len of keys is not equal to the len of values")
+	}
+	valueMap := make(map[string][]byte, len(keys))
+	for i, value := range values {
+		if len(value) == 0 {
+			return errors.New("empty value is not supported")
+		}
+		rawkvSizeHistogram.WithLabelValues("key").Observe(float64(len(keys[i])))
+		rawkvSizeHistogram.WithLabelValues("value").Observe(float64(len(value)))
+		// The last value wins if a key is put more than once.
+		valueMap[string(keys[i])] = value
+	}
+	bo := NewBackoffer(rawkvMaxBackoff, goctx.Background())
+	genReq := func(key []byte) *tikvrpc.Request {
+		return rawPutRequest(key, valueMap[string(key)])
+	}
+	return c.doBatchReq(bo, keys, genReq, func(_ []byte, resp *tikvrpc.Response) error {
+		return rawPutError(resp)
+	})
+}
+
 // Delete deletes a key-value pair from TiKV.
 func (c *RawKVClient) Delete(key []byte) error {
 	start := time.Now()
 	defer func() { rawkvCmdHistogram.WithLabelValues("delete").Observe(time.Since(start).Seconds()) }()
 
-	req := &tikvrpc.Request{
+	bo := NewBackoffer(rawkvMaxBackoff, goctx.Background())
+	return c.delete(bo, key)
+}
+
+func (c *RawKVClient) delete(bo *Backoffer, key []byte) error {
+	resp, _, err := c.sendReq(bo, key, rawDeleteRequest(key))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return rawDeleteError(resp)
+}
+
+func rawDeleteRequest(key []byte) *tikvrpc.Request {
+	return &tikvrpc.Request{
 		Type: tikvrpc.CmdRawDelete,
 		RawDelete: &kvrpcpb.RawDeleteRequest{
 			Key: key,
 		},
 	}
-	resp, _, err := c.sendReq(key, req)
-	if err != nil {
-		return errors.Trace(err)
-	}
+}
+
+func rawDeleteError(resp *tikvrpc.Response) error {
 	cmdResp := resp.RawDelete
 	if cmdResp == nil {
 		return errors.Trace(errBodyMissing)
@@ -149,6 +246,61 @@ func (c *RawKVClient) Delete(key []byte) error {
 	return nil
 }
 
+// BatchDelete deletes key-value pairs from TiKV.
+func (c *RawKVClient) BatchDelete(keys [][]byte) error {
+	start := time.Now()
+	defer func() { rawkvCmdHistogram.WithLabelValues("batch_delete").Observe(time.Since(start).Seconds()) }()
+
+	bo := NewBackoffer(rawkvMaxBackoff, goctx.Background())
+	return c.doBatchReq(bo, keys, rawDeleteRequest, func(_ []byte, resp *tikvrpc.Response) error {
+		return rawDeleteError(resp)
+	})
+}
+
+// DeleteRange deletes all key-value pairs in the range [startKey, endKey) from TiKV.
+// An empty endKey means the end of the keyspace. TiKV has no raw delete-range command, so the
+// keys in the range are scanned and deleted region by region, and the deletion is not atomic.
+func (c *RawKVClient) DeleteRange(startKey, endKey []byte) error {
+	start := time.Now()
+	defer func() { rawkvCmdHistogram.WithLabelValues("delete_range").Observe(time.Since(start).Seconds()) }()
+
+	if len(endKey) > 0 && bytes.Compare(startKey, endKey) >= 0 {
+		return nil
+	}
+	for {
+		bo := NewBackoffer(rawkvMaxBackoff, goctx.Background())
+		pairs, loc, err := c.scanRegion(bo, startKey, MaxRawKVScanLimit)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		keys := make([][]byte, 0, len(pairs))
+		for _, pair := range pairs {
+			if len(endKey) > 0 && bytes.Compare(pair.Key, endKey) >= 0 {
+				break
+			}
+			keys = append(keys, pair.Key)
+		}
+		err = c.doBatchReq(bo, keys, rawDeleteRequest, func(_ []byte, resp *tikvrpc.Response) error {
+			return rawDeleteError(resp)
+		})
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if len(keys) < len(pairs) {
+			return nil
+		}
+		if len(pairs) == MaxRawKVScanLimit {
+			lastKey := pairs[len(pairs)-1].Key
+			startKey = append(append([]byte(nil), lastKey...), 0)
+			continue
+		}
+		startKey = loc.EndKey
+		if len(startKey) == 0 || (len(endKey) > 0 && bytes.Compare(startKey, endKey) >= 0) {
+			return nil
+		}
+	}
+}
+
 // Scan queries continuous kv pairs, starts from startKey, up to limit pairs.
 // If you want to exclude the startKey, append a '\0' to the key: `Scan(append(startKey, '\0'), limit)`.
 func (c *RawKVClient) Scan(startKey []byte, limit int) (keys [][]byte, values [][]byte, err error) {
@@ -160,22 +312,12 @@ func (c *RawKVClient) Scan(startKey []byte, limit int) (keys [][]byte, values []
 	}
 
 	for len(keys) < limit {
-		req := &tikvrpc.Request{
-			Type: tikvrpc.CmdRawScan,
-			RawScan: &kvrpcpb.RawScanRequest{
-				StartKey: startKey,
-				Limit:    uint32(limit - len(keys)),
-			},
-		}
-		resp, loc, err := c.sendReq(startKey, req)
+		bo := NewBackoffer(rawkvMaxBackoff, goctx.Background())
+		pairs, loc, err := c.scanRegion(bo, startKey, limit-len(keys))
 		if err != nil {
 			return nil, nil, errors.Trace(err)
 		}
-		cmdResp := resp.RawScan
-		if cmdResp == nil {
-			return nil, nil, errors.Trace(errBodyMissing)
-		}
-		for _, pair := range cmdResp.Kvs {
+		for _, pair := range pairs {
 			keys = append(keys, pair.Key)
 			values = append(values, pair.Value)
 		}
@@ -187,8 +329,179 @@ func (c *RawKVClient) Scan(startKey []byte, limit int) (keys [][]byte, values []
 	return
 }
 
-func (c *RawKVClient) sendReq(key []byte, req *tikvrpc.Request) (*tikvrpc.Response, *KeyLocation, error) {
+// ReverseScan queries continuous kv pairs in descending order, starts from the key just
+// before endKey, up to limit pairs. An empty endKey means the end of the keyspace.
+// TiKV only scans forward, so every region is scanned forward from the start of the region
+// to the end of the range and the last pairs are kept.
+func (c *RawKVClient) ReverseScan(endKey []byte, limit int) (keys [][]byte, values [][]byte, err error) {
+	start := time.Now()
+	defer func() { rawkvCmdHistogram.WithLabelValues("raw_reverse_scan").Observe(time.Since(start).Seconds()) }()
+
+	if limit > MaxRawKVScanLimit {
+		return nil, nil, errors.Trace(ErrMaxScanLimitExceeded)
+	}
+
 	bo := NewBackoffer(rawkvMaxBackoff, goctx.Background())
+	for len(keys) < limit {
+		loc, err := c.locatePrevRegion(bo, endKey)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		pairs, err := c.scanRange(bo, loc.StartKey, endKey, limit-len(keys))
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		for i := len(pairs) - 1; i >= 0; i-- {
+			keys = append(keys, pairs[i].Key)
+			values = append(values, pairs[i].Value)
+		}
+		endKey = loc.StartKey
+		if len(endKey) == 0 {
+			break
+		}
+	}
+	return
+}
+
+// scanRegion scans the region of startKey forward from startKey, up to limit pairs.
+func (c *RawKVClient) scanRegion(bo *Backoffer, startKey []byte, limit int) ([]*kvrpcpb.KvPair, *KeyLocation, error) {
+	req := &tikvrpc.Request{
+		Type: tikvrpc.CmdRawScan,
+		RawScan: &kvrpcpb.RawScanRequest{
+			StartKey: startKey,
+			Limit:    uint32(limit),
+		},
+	}
+	resp, loc, err := c.sendReq(bo, startKey, req)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	cmdResp := resp.RawScan
+	if cmdResp == nil {
+		return nil, nil, errors.Trace(errBodyMissing)
+	}
+	return cmdResp.Kvs, loc, nil
+}
+
+// scanRange scans the range [startKey, endKey) forward and returns the last limit pairs.
+func (c *RawKVClient) scanRange(bo *Backoffer, startKey, endKey []byte, limit int) ([]*kvrpcpb.KvPair, error) {
+	var result []*kvrpcpb.KvPair
+	for {
+		pairs, loc, err := c.scanRegion(bo, startKey, MaxRawKVScanLimit)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		finished := false
+		for _, pair := range pairs {
+			if len(endKey) > 0 && bytes.Compare(pair.Key, endKey) >= 0 {
+				finished = true
+				break
+			}
+			result = append(result, pair)
+		}
+		if len(result) > limit {
+			result = result[len(result)-limit:]
+		}
+		if len(pairs) == MaxRawKVScanLimit && !finished {
+			lastKey := pairs[len(pairs)-1].Key
+			startKey = append(append([]byte(nil), lastKey...), 0)
+			continue
+		}
+		startKey = loc.EndKey
+		if finished || len(startKey) == 0 || (len(endKey) > 0 && bytes.Compare(startKey, endKey) >= 0) {
+			return result, nil
+		}
+	}
+}
+
+// locatePrevRegion searches for the region which contains the keys just before key. An
+// empty key means the end of the keyspace, and the last region is returned.
+func (c *RawKVClient) locatePrevRegion(bo *Backoffer, key []byte) (*KeyLocation, error) {
+	// The region of a key which is less than key is located first, then the regions are
+	// walked forward until the region ends at key.
+	var searchKey []byte
+	if n := len(key); n > 0 {
+		searchKey = append([]byte(nil), key[:n-1]...)
+		if key[n-1] > 0 {
+			searchKey = append(searchKey, key[n-1]-1)
+		}
+	}
+	for {
+		loc, err := c.regionCache.LocateKey(bo, searchKey)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if len(loc.EndKey) == 0 || (len(key) > 0 && bytes.Compare(loc.EndKey, key) >= 0) {
+			return loc, nil
+		}
+		searchKey = loc.EndKey
+	}
+}
+
+// doBatchReq groups the keys by their regions and splits the groups into batches of at most
+// rawBatchKeyCount keys, then sends the batches concurrently by sendBatchReq. The request of
+// every key is built by genReq, and its response is checked by handle. It returns the first error.
+func (c *RawKVClient) doBatchReq(bo *Backoffer, keys [][]byte, genReq func(key []byte) *tikvrpc.Request, handle func(key []byte, resp *tikvrpc.Response) error) error {
+	groups, _, err := c.regionCache.GroupKeysByRegion(bo, keys)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var batches []batchKeys
+	for id, g := range groups {
+		batches = appendBatchBySize(batches, id, g, func([]byte) int { return 1 }, rawBatchKeyCount)
+	}
+	if len(batches) == 0 {
+		return nil
+	}
+	if len(batches) == 1 {
+		return errors.Trace(c.sendBatchReq(bo, batches[0], genReq, handle))
+	}
+
+	ch := make(chan error, len(batches))
+	for _, batch := range batches {
+		go func(batch batchKeys) {
+			singleBo, cancel := bo.Fork()
+			defer cancel()
+			ch <- c.sendBatchReq(singleBo, batch, genReq, handle)
+		}(batch)
+	}
+	for i := 0; i < len(batches); i++ {
+		if e := <-ch; e != nil && err == nil {
+			err = errors.Trace(e)
+		}
+	}
+	return errors.Trace(err)
+}
+
+// sendBatchReq sends the request of every key in batch to the region of batch. TiKV has no
+// raw batch commands, so the keys are sent one by one. When the region is stale, the keys which
+// are not sent yet are grouped by their new regions and sent again.
+func (c *RawKVClient) sendBatchReq(bo *Backoffer, batch batchKeys, genReq func(key []byte) *tikvrpc.Request, handle func(key []byte, resp *tikvrpc.Response) error) error {
+	sender := NewRegionRequestSender(c.regionCache, c.rpcClient, kvrpcpb.IsolationLevel_SI)
+	for i, key := range batch.keys {
+		resp, err := sender.SendReq(bo, genReq(key), batch.region, readTimeoutShort)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		regionErr, err := resp.GetRegionError()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if regionErr != nil {
+			err = bo.Backoff(boRegionMiss, errors.New(regionErr.String()))
+			if err != nil {
+				return errors.Trace(err)
+			}
+			return errors.Trace(c.doBatchReq(bo, batch.keys[i:], genReq, handle))
+		}
+		if err = handle(key, resp); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (c *RawKVClient) sendReq(bo *Backoffer, key []byte, req *tikvrpc.Request) (*tikvrpc.Response, *KeyLocation, error) {
 	sender := NewRegionRequestSender(c.regionCache, c.rpcClient, kvrpcpb.IsolationLevel_SI)
 	for {
 		loc, err := c.regionCache.LocateKey(bo, key)
@@ -14,8 +14,11 @@
 package tikv
 
 import (
+	"sync"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/store/tikv/mock-tikv"
+	"github.com/pingcap/tidb/store/tikv/tikvrpc"
 	goctx "golang.org/x/net/context"
 )
 
@@ -127,3 +130,122 @@ func (s *testRawKVSuite) TestScan(c *C) {
 	split("k2", "k5")
 	check()
 }
+
+func (s *testRawKVSuite) mustReverseScan(c *C, endKey string, limit int, expect ...string) {
+	keys, values, err := s.client.ReverseScan([]byte(endKey), limit)
+	c.Assert(err, IsNil)
+	c.Assert(len(keys)*2, Equals, len(expect))
+	for i := range keys {
+		c.Assert(string(keys[i]), Equals, expect[i*2])
+		c.Assert(string(values[i]), Equals, expect[i*2+1])
+	}
+}
+
+func (s *testRawKVSuite) split(c *C, regionKey, splitKey string) {
+	region, _ := s.cluster.GetRegionByKey([]byte(regionKey))
+	c.Assert(region, NotNil)
+	newRegionID, peerID := s.cluster.AllocID(), s.cluster.AllocID()
+	s.cluster.SplitRaw(region.GetId(), newRegionID, []byte(splitKey), []uint64{peerID}, peerID)
+}
+
+// countRequestClient counts the requests sent through it by type.
+type countRequestClient struct {
+	Client
+	mu     sync.Mutex
+	counts map[tikvrpc.CmdType]int
+}
+
+func (c *countRequestClient) SendReq(ctx goctx.Context, addr string, req *tikvrpc.Request) (*tikvrpc.Response, error) {
+	c.mu.Lock()
+	c.counts[req.Type]++
+	c.mu.Unlock()
+	return c.Client.SendReq(ctx, addr, req)
+}
+
+func (c *countRequestClient) count(tp tikvrpc.CmdType) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[tp]
+}
+
+func (s *testRawKVSuite) TestBatch(c *C) {
+	client := &countRequestClient{Client: s.client.rpcClient, counts: make(map[tikvrpc.CmdType]int)}
+	s.client.rpcClient = client
+	s.split(c, "k", "k3")
+	s.split(c, "k3", "k5")
+	keys := [][]byte{[]byte("k1"), []byte("k3"), []byte("k5"), []byte("k7")}
+	values := [][]byte{[]byte("v1"), []byte("v3"), []byte("v5"), []byte("v7")}
+	err := s.client.BatchPut(keys, values)
+	c.Assert(err, IsNil)
+	s.mustGet(c, []byte("k3"), []byte("v3"))
+	s.mustScan(c, "", 10, "k1", "v1", "k3", "v3", "k5", "v5", "k7", "v7")
+
+	c.Assert(client.count(tikvrpc.CmdRawPut), Equals, 4)
+
+	// The region of k3 is split after it is cached, the keys sent to the stale region are
+	// grouped by their new regions and sent again.
+	s.split(c, "k3", "k4")
+	gets := client.count(tikvrpc.CmdRawGet)
+	vals, err := s.client.BatchGet([][]byte{[]byte("k7"), []byte("k2"), []byte("k1"), []byte("k3"), []byte("k7")})
+	c.Assert(err, IsNil)
+	c.Assert(vals, DeepEquals, [][]byte{[]byte("v7"), nil, []byte("v1"), []byte("v3"), []byte("v7")})
+	// k3 is sent to the stale region first.
+	c.Assert(client.count(tikvrpc.CmdRawGet), Equals, gets+6)
+
+	err = s.client.BatchDelete([][]byte{[]byte("k1"), []byte("k5"), []byte("k6")})
+	c.Assert(err, IsNil)
+	s.mustNotExist(c, []byte("k1"))
+	s.mustNotExist(c, []byte("k5"))
+	s.mustScan(c, "", 10, "k3", "v3", "k7", "v7")
+
+	err = s.client.BatchPut(keys, values[:1])
+	c.Assert(err, NotNil)
+	err = s.client.BatchPut(keys[:1], [][]byte{{}})
+	c.Assert(err, NotNil)
+}
+
+func (s *testRawKVSuite) TestDeleteRange(c *C) {
+	for _, k := range []string{"k1", "k2", "k3", "k4", "k5", "k6"} {
+		s.mustPut(c, []byte(k), []byte("v"+k[1:]))
+	}
+	s.split(c, "k", "k3")
+	s.split(c, "k3", "k5")
+
+	client := &countRequestClient{Client: s.client.rpcClient, counts: make(map[tikvrpc.CmdType]int)}
+	s.client.rpcClient = client
+	err := s.client.DeleteRange([]byte("k2"), []byte("k5"))
+	c.Assert(err, IsNil)
+	c.Assert(client.count(tikvrpc.CmdRawDelete), Equals, 3)
+	c.Assert(client.count(tikvrpc.CmdDeleteRange), Equals, 0)
+	s.mustScan(c, "", 10, "k1", "v1", "k5", "v5", "k6", "v6")
+	err = s.client.DeleteRange([]byte("k5"), []byte("k5"))
+	c.Assert(err, IsNil)
+	s.mustScan(c, "", 10, "k1", "v1", "k5", "v5", "k6", "v6")
+	err = s.client.DeleteRange([]byte("k1\x00"), nil)
+	c.Assert(err, IsNil)
+	s.mustScan(c, "", 10, "k1", "v1")
+}
+
+func (s *testRawKVSuite) TestReverseScan(c *C) {
+	s.mustPut(c, []byte("k1"), []byte("v1"))
+	s.mustPut(c, []byte("k3"), []byte("v3"))
+	s.mustPut(c, []byte("k5"), []byte("v5"))
+	s.mustPut(c, []byte("k7"), []byte("v7"))
+
+	check := func() {
+		s.mustReverseScan(c, "", 1, "k7", "v7")
+		s.mustReverseScan(c, "k7", 2, "k5", "v5", "k3", "v3")
+		s.mustReverseScan(c, "", 10, "k7", "v7", "k5", "v5", "k3", "v3", "k1", "v1")
+		s.mustReverseScan(c, "k6", 2, "k5", "v5", "k3", "v3")
+		s.mustReverseScan(c, "k5", 3, "k3", "v3", "k1", "v1")
+		s.mustReverseScan(c, "k1", 3)
+	}
+
+	check()
+	s.split(c, "k", "k2")
+	check()
+	s.split(c, "k2", "k5")
+	check()
+	s.split(c, "k5", "k5\x00")
+	check()
+}
@@ -37,6 +37,7 @@ const (
 	CmdResolveLock
 	CmdGC
 	CmdDeleteRange
+	CmdPessimisticLock
 
 	CmdRawGet CmdType = 256 + iota
 	CmdRawPut
@@ -64,6 +65,7 @@ type Request struct {
 	ResolveLock      *kvrpcpb.ResolveLockRequest
 	GC               *kvrpcpb.GCRequest
 	DeleteRange      *kvrpcpb.DeleteRangeRequest
+	PessimisticLock  *PessimisticLockRequest
 	RawGet           *kvrpcpb.RawGetRequest
 	RawPut           *kvrpcpb.RawPutRequest
 	RawDelete        *kvrpcpb.RawDeleteRequest
@@ -99,6 +101,8 @@ func (req *Request) GetContext() (*kvrpcpb.Context, error) {
 		c = req.GC.GetContext()
 	case CmdDeleteRange:
 		c = req.DeleteRange.GetContext()
+	case CmdPessimisticLock:
+		c = req.PessimisticLock.GetContext()
 	case CmdRawGet:
 		c = req.RawGet.GetContext()
 	case CmdRawPut:
@@ -133,6 +137,7 @@ type Response struct {
 	ResolveLock      *kvrpcpb.ResolveLockResponse
 	GC               *kvrpcpb.GCResponse
 	DeleteRange      *kvrpcpb.DeleteRangeResponse
+	PessimisticLock  *PessimisticLockResponse
 	RawGet           *kvrpcpb.RawGetResponse
 	RawPut           *kvrpcpb.RawPutResponse
 	RawDelete        *kvrpcpb.RawDeleteResponse
@@ -168,6 +173,8 @@ func SetContext(req *Request, ctx *kvrpcpb.Context) error {
 		req.GC.Context = ctx
 	case CmdDeleteRange:
 		req.DeleteRange.Context = ctx
+	case CmdPessimisticLock:
+		req.PessimisticLock.Context = ctx
 	case CmdRawGet:
 		req.RawGet.Context = ctx
 	case CmdRawPut:
@@ -238,6 +245,10 @@ func GenRegionErrorResp(req *Request, e *errorpb.Error) (*Response, error) {
 		resp.DeleteRange = &kvrpcpb.DeleteRangeResponse{
 			RegionError: e,
 		}
+	case CmdPessimisticLock:
+		resp.PessimisticLock = &PessimisticLockResponse{
+			RegionError: e,
+		}
 	case CmdRawGet:
 		resp.RawGet = &kvrpcpb.RawGetResponse{
 			RegionError: e,
@@ -298,6 +309,8 @@ func (resp *Response) GetRegionError() (*errorpb.Error, error) {
 		e = resp.GC.GetRegionError()
 	case CmdDeleteRange:
 		e = resp.DeleteRange.GetRegionError()
+	case CmdPessimisticLock:
+		e = resp.PessimisticLock.GetRegionError()
 	case CmdRawGet:
 		e = resp.RawGet.GetRegionError()
 	case CmdRawPut:
@@ -317,3 +330,52 @@ func (resp *Response) GetRegionError() (*errorpb.Error, error) {
 	}
 	return e, nil
 }
+
+// PessimisticLockRequest locks keys for a pessimistic transaction before it prewrites.
+// The kvproto in use does not define the message yet, so it is only served by mock-tikv.
+type PessimisticLockRequest struct {
+	Context      *kvrpcpb.Context
+	Keys         [][]byte
+	PrimaryLock  []byte
+	StartVersion uint64
+	ForUpdateTs  uint64
+	LockTtl      uint64
+	// WaitTimeout is the time in milliseconds the request waits for the locks held by other
+	// transactions to be released.
+	WaitTimeout int64
+}
+
+// GetContext returns the rpc context of the request.
+func (r *PessimisticLockRequest) GetContext() *kvrpcpb.Context {
+	if r != nil {
+		return r.Context
+	}
+	return nil
+}
+
+// Size returns the approximate size of the request.
+func (r *PessimisticLockRequest) Size() int {
+	n := len(r.PrimaryLock)
+	for _, k := range r.Keys {
+		n += len(k)
+	}
+	return n
+}
+
+// PessimisticLockResponse is the response of a PessimisticLockRequest.
+type PessimisticLockResponse struct {
+	RegionError *errorpb.Error
+	Errors      []*kvrpcpb.KeyError
+	// Deadlock is set when waiting for the lock would form a cycle with other waiting transactions.
+	Deadlock bool
+	// DeadlockLockTs is the start timestamp of the transaction holding the lock when Deadlock is set.
+	DeadlockLockTs uint64
+}
+
+// GetRegionError returns the region error of the response.
+func (r *PessimisticLockResponse) GetRegionError() *errorpb.Error {
+	if r != nil {
+		return r.RegionError
+	}
+	return nil
+}
@@ -14,6 +14,7 @@
 package tikv
 
 import (
+	"bytes"
 	"fmt"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tipb/go-binlog"
 	goctx "golang.org/x/net/context"
 )
@@ -40,6 +42,13 @@ type tikvTxn struct {
 	valid     bool
 	lockKeys  [][]byte
 	dirty     bool
+
+	// pessimisticKeys are the keys locked in the store by the pessimistic transaction,
+	// the first one is the primary key.
+	pessimisticKeys   [][]byte
+	pessimisticLocked map[string]struct{}
+	// pendingKeys are the keys written by the pessimistic transaction since the last LockKeys call.
+	pendingKeys [][]byte
 }
 
 func newTiKVTxn(store *tikvStore) (*tikvTxn, error) {
@@ -82,6 +91,9 @@ func (txn *tikvTxn) Set(k kv.Key, v []byte) error {
 	txnCmdCounter.WithLabelValues("set").Inc()
 
 	txn.dirty = true
+	if txn.isPessimistic() {
+		txn.pendingKeys = append(txn.pendingKeys, append([]byte(nil), k...))
+	}
 	return txn.us.Set(k, v)
 }
 
@@ -110,6 +122,9 @@ func (txn *tikvTxn) Delete(k kv.Key) error {
 	txnCmdCounter.WithLabelValues("delete").Inc()
 
 	txn.dirty = true
+	if txn.isPessimistic() {
+		txn.pendingKeys = append(txn.pendingKeys, append([]byte(nil), k...))
+	}
 	return txn.us.Delete(k)
 }
 
@@ -120,13 +135,21 @@ func (txn *tikvTxn) SetOption(opt kv.Option, val interface{}) {
 		txn.snapshot.isolationLevel = val.(kv.IsoLevel)
 	case kv.Priority:
 		txn.snapshot.priority = kvPriorityToCommandPri(val.(int))
+	case kv.ForUpdateTS:
+		// The statement of a pessimistic transaction which locks keys reads them at forUpdateTS,
+		// the option is deleted when the statement finishes.
+		txn.snapshot.version = kv.NewVersion(val.(uint64))
 	}
 }
 
 func (txn *tikvTxn) DelOption(opt kv.Option) {
 	txn.us.DelOption(opt)
-	if opt == kv.IsolationLevel {
+	switch opt {
+	case kv.IsolationLevel:
 		txn.snapshot.isolationLevel = kv.SI
+	case kv.ForUpdateTS:
+		// The other statements read at startTS, so the transaction reads repeatably.
+		txn.snapshot.version = kv.NewVersion(txn.startTS)
 	}
 }
 
@@ -146,6 +169,7 @@ func (txn *tikvTxn) Commit() error {
 
 	committer, err := newTwoPhaseCommitter(txn)
 	if err != nil {
+		txn.cleanupPessimisticLocks()
 		return errors.Trace(err)
 	}
 	if committer == nil {
@@ -154,6 +178,9 @@ func (txn *tikvTxn) Commit() error {
 	err = committer.execute()
 	if err != nil {
 		committer.writeFinishBinlog(binlog.BinlogType_Rollback, 0)
+		if errors.Cause(err) != terror.ErrResultUndetermined {
+			txn.cleanupPessimisticLocks()
+		}
 		return errors.Trace(err)
 	}
 	committer.writeFinishBinlog(binlog.BinlogType_Commit, int64(committer.commitTS))
@@ -173,18 +200,122 @@ func (txn *tikvTxn) Rollback() error {
 	txn.close()
 	log.Infof("[kv] Rollback txn %d", txn.StartTS())
 	txnCmdCounter.WithLabelValues("rollback").Inc()
+	txn.cleanupPessimisticLocks()
 
 	return nil
 }
 
 func (txn *tikvTxn) LockKeys(keys ...kv.Key) error {
 	txnCmdCounter.WithLabelValues("lock_keys").Inc()
+	if txn.isPessimistic() {
+		pending := make([]kv.Key, 0, len(keys)+len(txn.pendingKeys))
+		pending = append(pending, keys...)
+		for _, k := range txn.pendingKeys {
+			pending = append(pending, k)
+		}
+		txn.pendingKeys = nil
+		err := txn.pessimisticLockKeys(pending)
+		// Only the locked keys are prewritten, the others may be skipped by the retried statement.
+		for _, key := range keys {
+			if _, ok := txn.pessimisticLocked[string(key)]; ok {
+				txn.lockKeys = append(txn.lockKeys, key)
+			}
+		}
+		return errors.Trace(err)
+	}
 	for _, key := range keys {
 		txn.lockKeys = append(txn.lockKeys, key)
 	}
 	return nil
 }
 
+func (txn *tikvTxn) isPessimistic() bool {
+	pessimistic, ok := txn.us.GetOption(kv.Pessimistic).(bool)
+	return ok && pessimistic
+}
+
+// pessimisticLockKeys locks the keys in the store, waiting for the locks held by other
+// transactions to be released.
+func (txn *tikvTxn) pessimisticLockKeys(keys []kv.Key) error {
+	if txn.pessimisticLocked == nil {
+		txn.pessimisticLocked = make(map[string]struct{})
+	}
+	var lockKeys [][]byte
+	pending := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		if _, ok := txn.pessimisticLocked[string(k)]; ok {
+			continue
+		}
+		if _, ok := pending[string(k)]; ok {
+			continue
+		}
+		pending[string(k)] = struct{}{}
+		lockKeys = append(lockKeys, k)
+	}
+	if len(lockKeys) == 0 {
+		return nil
+	}
+	if len(txn.pessimisticKeys) > 0 {
+		// Put the primary key in front, it is locked again without any effect.
+		lockKeys = append([][]byte{txn.pessimisticKeys[0]}, lockKeys...)
+	}
+
+	forUpdateTS, ok := txn.us.GetOption(kv.ForUpdateTS).(uint64)
+	if !ok || forUpdateTS < txn.startTS {
+		forUpdateTS = txn.startTS
+	}
+	waitTimeout, ok := txn.us.GetOption(kv.LockWaitTimeout).(int64)
+	if !ok {
+		waitTimeout = 0
+	}
+	elapsed := time.Duration(monotime.Now()-txn.startTime) / time.Millisecond
+	c := &twoPhaseCommitter{
+		store:            txn.store,
+		txn:              txn,
+		startTS:          txn.startTS,
+		keys:             lockKeys,
+		lockTTL:          pessimisticLockTTL + uint64(elapsed),
+		priority:         getTxnPriority(txn),
+		forUpdateTS:      forUpdateTS,
+		lockWaitDeadline: time.Now().Add(time.Duration(waitTimeout) * time.Millisecond),
+	}
+	bo := NewBackoffer(pessimisticMaxBackoff, goctx.Background())
+	err := c.pessimisticLockKeys(bo, lockKeys)
+	// Remember the locked keys even if some of them failed, so that they are released at last.
+	for _, k := range c.mu.writtenKeys {
+		if _, ok := txn.pessimisticLocked[string(k)]; ok {
+			continue
+		}
+		txn.pessimisticLocked[string(k)] = struct{}{}
+		if bytes.Equal(k, lockKeys[0]) {
+			txn.pessimisticKeys = append([][]byte{k}, txn.pessimisticKeys...)
+		} else {
+			txn.pessimisticKeys = append(txn.pessimisticKeys, k)
+		}
+	}
+	return errors.Trace(err)
+}
+
+// cleanupPessimisticLocks releases the locks acquired by the pessimistic transaction in background.
+func (txn *tikvTxn) cleanupPessimisticLocks() {
+	if len(txn.pessimisticKeys) == 0 {
+		return
+	}
+	c := &twoPhaseCommitter{
+		store:   txn.store,
+		txn:     txn,
+		startTS: txn.startTS,
+		keys:    txn.pessimisticKeys,
+	}
+	txn.pessimisticKeys, txn.pessimisticLocked = nil, nil
+	go func() {
+		err := c.cleanupKeys(NewBackoffer(cleanupMaxBackoff, goctx.Background()), c.keys)
+		if err != nil {
+			log.Infof("[kv] cleanup pessimistic locks err: %v, tid: %d", err, c.startTS)
+		}
+	}()
+}
+
 func (txn *tikvTxn) IsReadOnly() bool {
 	return !txn.dirty
 }
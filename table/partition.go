@@ -0,0 +1,76 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package table
+
+import (
+	"strconv"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// RangePartitionBounds returns the VALUES LESS THAN values of the RANGE partitions.
+// If the last partition is VALUES LESS THAN MAXVALUE, maxValue is true and its bound is not returned.
+func RangePartitionBounds(pi *model.PartitionInfo) (bounds []int64, maxValue bool, err error) {
+	bounds = make([]int64, 0, len(pi.Definitions))
+	for i, def := range pi.Definitions {
+		if len(def.LessThan) == 0 {
+			return nil, false, errors.Errorf("partition %s doesn't have VALUES LESS THAN", def.Name)
+		}
+		if def.LessThan[0] == model.PartitionMaxValue {
+			if i != len(pi.Definitions)-1 {
+				return nil, false, errors.Errorf("MAXVALUE of partition %s isn't in the last partition", def.Name)
+			}
+			return bounds, true, nil
+		}
+		bound, err := strconv.ParseInt(def.LessThan[0], 10, 64)
+		if err != nil {
+			return nil, false, errors.Trace(err)
+		}
+		bounds = append(bounds, bound)
+	}
+	return bounds, false, nil
+}
+
+// ListPartitionValues returns the VALUES IN values of every LIST partition.
+// The values are int64 datums, or null datums for NULL.
+func ListPartitionValues(pi *model.PartitionInfo) ([][]types.Datum, error) {
+	values := make([][]types.Datum, 0, len(pi.Definitions))
+	for _, def := range pi.Definitions {
+		vals := make([]types.Datum, 0, len(def.InValues))
+		for _, str := range def.InValues {
+			if str == model.PartitionNullValue {
+				vals = append(vals, types.Datum{})
+				continue
+			}
+			v, err := strconv.ParseInt(str, 10, 64)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			vals = append(vals, types.NewIntDatum(v))
+		}
+		values = append(values, vals)
+	}
+	return values, nil
+}
+
+// HashPartitionOffset returns the offset of the HASH partition which the value belongs to.
+func HashPartitionOffset(v int64, num int) int {
+	offset := int(v % int64(num))
+	if offset < 0 {
+		offset = -offset
+	}
+	return offset
+}
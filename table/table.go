@@ -55,6 +55,8 @@ var (
 	ErrInvalidRecordKey = terror.ClassTable.New(codeInvalidRecordKey, "invalid record key")
 	// ErrTruncateWrongValue returns for truncate wrong value for field.
 	ErrTruncateWrongValue = terror.ClassTable.New(codeTruncateWrongValue, "Incorrect value")
+	// ErrNoPartitionForGivenValue returns when the value of the partition expression is not in any partition.
+	ErrNoPartitionForGivenValue = terror.ClassTable.New(codeNoPartitionForGivenValue, mysql.MySQLErrName[mysql.ErrNoPartitionForGivenValue])
 )
 
 // RecordIterFunc is used for low-level record iteration.
@@ -126,6 +128,27 @@ type Table interface {
 	Seek(ctx context.Context, h int64) (handle int64, found bool, err error)
 }
 
+// PhysicalTable is a table whose records and indices are encoded with one physical table ID.
+// It is either a non-partitioned table or a partition of a partitioned table.
+type PhysicalTable interface {
+	Table
+
+	// GetPhysicalID returns the ID used to encode the record keys and index keys.
+	GetPhysicalID() int64
+}
+
+// PartitionedTable is a table whose records are stored in its partitions.
+// The records are routed to the partitions by the value of the partition expression.
+type PartitionedTable interface {
+	Table
+
+	// GetPartition returns the partition with the physical ID, it returns nil if the partition doesn't exist.
+	GetPartition(physicalID int64) PhysicalTable
+
+	// GetPartitionByRow returns the partition which the row belongs to.
+	GetPartitionByRow(ctx context.Context, r []types.Datum) (PhysicalTable, error)
+}
+
 // TableFromMeta builds a table.Table from *model.TableInfo.
 // Currently, it is assigned to tables.TableFromMeta in tidb package's init function.
 var TableFromMeta func(alloc autoid.Allocator, tblInfo *model.TableInfo) (Table, error)
@@ -150,6 +173,8 @@ const (
 	codeDuplicateColumn    = 1110
 	codeNoDefaultValue     = 1364
 	codeTruncateWrongValue = 1366
+
+	codeNoPartitionForGivenValue = 1526
 )
 
 // Slice is used for table sorting.
@@ -170,6 +195,8 @@ func init() {
 		codeDuplicateColumn:    mysql.ErrFieldSpecifiedTwice,
 		codeNoDefaultValue:     mysql.ErrNoDefaultForField,
 		codeTruncateWrongValue: mysql.ErrTruncatedWrongValueForField,
+
+		codeNoPartitionForGivenValue: mysql.ErrNoPartitionForGivenValue,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassTable] = tableMySQLErrCodes
 }
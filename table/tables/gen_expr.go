@@ -18,10 +18,13 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/types"
 )
 
 // getDefaultCharsetAndCollate is copyed from ddl/ddl_api.go.
@@ -86,3 +89,45 @@ func simpleResolveName(node ast.ExprNode, tblInfo *model.TableInfo) (ast.ExprNod
 	}
 	return node, nil
 }
+
+// HasVirtualColumn checks whether there is a virtual generated column in cols.
+func HasVirtualColumn(cols []*table.Column) bool {
+	for _, col := range cols {
+		if col.IsGenerated() && !col.GeneratedStored {
+			return true
+		}
+	}
+	return false
+}
+
+// FillVirtualColumnValues evaluates the virtual generated columns of the table
+// and fills the values into the row, the row must contain all the columns of the table.
+func FillVirtualColumnValues(ctx context.Context, t table.Table, row []types.Datum) error {
+	cols := t.Cols()
+	if !HasVirtualColumn(cols) {
+		return nil
+	}
+	colInfos := make([]*model.ColumnInfo, 0, len(cols))
+	for _, col := range cols {
+		colInfos = append(colInfos, col.ToInfo())
+	}
+	schema := expression.NewSchema(expression.ColumnInfos2Columns(t.Meta().Name, colInfos)...)
+	for i, col := range cols {
+		if !col.IsGenerated() || col.GeneratedStored {
+			continue
+		}
+		expr, err := expression.RewriteAstExpr(col.GeneratedExpr, schema, ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		val, err := expr.Eval(row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		row[i], err = table.CastValue(ctx, val, colInfos[i])
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
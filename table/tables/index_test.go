@@ -61,7 +61,7 @@ func (s *testIndexSuite) TestIndex(c *C) {
 			},
 		},
 	}
-	index := tables.NewIndex(tblInfo, tblInfo.Indices[0])
+	index := tables.NewIndex(tblInfo.ID, tblInfo, tblInfo.Indices[0])
 
 	// Test ununiq index.
 	txn, err := s.s.Begin()
@@ -145,7 +145,7 @@ func (s *testIndexSuite) TestIndex(c *C) {
 			},
 		},
 	}
-	index = tables.NewIndex(tblInfo, tblInfo.Indices[0])
+	index = tables.NewIndex(tblInfo.ID, tblInfo, tblInfo.Indices[0])
 
 	// Test uniq index.
 	txn, err = s.s.Begin()
@@ -200,7 +200,7 @@ func (s *testIndexSuite) TestCombineIndexSeek(c *C) {
 			},
 		},
 	}
-	index := tables.NewIndex(tblInfo, tblInfo.Indices[0])
+	index := tables.NewIndex(tblInfo.ID, tblInfo, tblInfo.Indices[0])
 
 	txn, err := s.s.Begin()
 	c.Assert(err, IsNil)
@@ -209,7 +209,7 @@ func (s *testIndexSuite) TestCombineIndexSeek(c *C) {
 	_, err = index.Create(txn, values, 1)
 	c.Assert(err, IsNil)
 
-	index2 := tables.NewIndex(tblInfo, tblInfo.Indices[0])
+	index2 := tables.NewIndex(tblInfo.ID, tblInfo, tblInfo.Indices[0])
 	iter, hit, err := index2.Seek(txn, types.MakeDatums("abc", nil))
 	c.Assert(err, IsNil)
 	defer iter.Close()
@@ -0,0 +1,328 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tables
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/types"
+)
+
+var _ table.PartitionedTable = &partitionedTable{}
+
+// partition is a partition of a partitioned table. It shares the meta of the partitioned table,
+// but its records and indices are encoded with the partition ID.
+type partition struct {
+	Table
+}
+
+// partitionedTable implements the table.PartitionedTable interface.
+// The records are not stored with the table ID, but with the IDs of the partitions.
+type partitionedTable struct {
+	*Table
+
+	partitionExpr *partitionExpr
+	partitions    map[int64]*partition
+}
+
+func newPartitionedTable(tbl *Table, tblInfo *model.TableInfo) (table.Table, error) {
+	pe, err := newPartitionExpr(tbl, tblInfo)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	pi := tblInfo.Partition
+	partitions := make(map[int64]*partition, len(pi.Definitions))
+	for _, def := range pi.Definitions {
+		p := &partition{Table: *newTable(def.ID, tbl.Columns, tbl.alloc)}
+		for _, idxInfo := range tblInfo.Indices {
+			p.indices = append(p.indices, NewIndex(def.ID, tblInfo, idxInfo))
+		}
+		p.meta = tblInfo
+		partitions[def.ID] = p
+	}
+	return &partitionedTable{
+		Table:         tbl,
+		partitionExpr: pe,
+		partitions:    partitions,
+	}, nil
+}
+
+// partitionExpr is used to locate the partition which a row belongs to.
+type partitionExpr struct {
+	tp model.PartitionType
+	// columnOffset is the offset of the column if the partition expression is a column, otherwise it's -1.
+	columnOffset int
+	expr         ast.ExprNode
+	schema       *expression.Schema
+
+	// rangeBounds and maxValue are only used by the RANGE partition.
+	rangeBounds []int64
+	maxValue    bool
+	// listOffsets and nullOffset are only used by the LIST partition.
+	listOffsets map[int64]int
+	nullOffset  int
+	// num is only used by the HASH partition.
+	num int
+}
+
+func newPartitionExpr(tbl *Table, tblInfo *model.TableInfo) (*partitionExpr, error) {
+	pi := tblInfo.Partition
+	pe := &partitionExpr{
+		tp:           pi.Type,
+		columnOffset: -1,
+		nullOffset:   -1,
+		num:          len(pi.Definitions),
+	}
+	expr, err := parseExpression(pi.Expr)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if colExpr, ok := expr.(*ast.ColumnNameExpr); ok {
+		col := table.FindCol(tbl.Cols(), colExpr.Name.Name.L)
+		if col == nil {
+			return nil, errors.Errorf("can't find column %s in %s", colExpr.Name.Name.O, tblInfo.Name.O)
+		}
+		pe.columnOffset = col.Offset
+	} else {
+		expr, err = simpleResolveName(expr, tblInfo)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		pe.expr = expr
+		colInfos := make([]*model.ColumnInfo, 0, len(tbl.Cols()))
+		for _, col := range tbl.Cols() {
+			colInfos = append(colInfos, col.ToInfo())
+		}
+		pe.schema = expression.NewSchema(expression.ColumnInfos2Columns(tblInfo.Name, colInfos)...)
+	}
+
+	switch pi.Type {
+	case model.PartitionTypeRange:
+		pe.rangeBounds, pe.maxValue, err = table.RangePartitionBounds(pi)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	case model.PartitionTypeList:
+		values, err := table.ListPartitionValues(pi)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		pe.listOffsets = make(map[int64]int)
+		for i, vals := range values {
+			for _, val := range vals {
+				if val.IsNull() {
+					pe.nullOffset = i
+					continue
+				}
+				pe.listOffsets[val.GetInt64()] = i
+			}
+		}
+	}
+	return pe, nil
+}
+
+// eval evaluates the partition expression with the row.
+func (pe *partitionExpr) eval(ctx context.Context, r []types.Datum) (types.Datum, error) {
+	if pe.columnOffset >= 0 {
+		return r[pe.columnOffset], nil
+	}
+	// The expression is rewritten with the context every time, because the built
+	// expression holds the context and can't be shared by the sessions.
+	expr, err := expression.RewriteAstExpr(pe.expr, pe.schema, ctx)
+	if err != nil {
+		return types.Datum{}, errors.Trace(err)
+	}
+	d, err := expr.Eval(r)
+	return d, errors.Trace(err)
+}
+
+// locatePartition returns the offset of the partition definition which the row belongs to.
+func (pe *partitionExpr) locatePartition(ctx context.Context, r []types.Datum) (int, error) {
+	d, err := pe.eval(ctx, r)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if d.IsNull() {
+		// NULL is treated as less than any other value in the RANGE partition, and 0 in the HASH partition.
+		if pe.tp != model.PartitionTypeList {
+			return 0, nil
+		}
+		if pe.nullOffset < 0 {
+			return 0, table.ErrNoPartitionForGivenValue.GenByArgs(model.PartitionNullValue)
+		}
+		return pe.nullOffset, nil
+	}
+	v, err := d.ToInt64(ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	switch pe.tp {
+	case model.PartitionTypeRange:
+		offset := sort.Search(len(pe.rangeBounds), func(i int) bool { return v < pe.rangeBounds[i] })
+		if offset == len(pe.rangeBounds) && !pe.maxValue {
+			return 0, table.ErrNoPartitionForGivenValue.GenByArgs(strconv.FormatInt(v, 10))
+		}
+		return offset, nil
+	case model.PartitionTypeList:
+		offset, ok := pe.listOffsets[v]
+		if !ok {
+			return 0, table.ErrNoPartitionForGivenValue.GenByArgs(strconv.FormatInt(v, 10))
+		}
+		return offset, nil
+	default:
+		return table.HashPartitionOffset(v, pe.num), nil
+	}
+}
+
+// GetPartition implements table.PartitionedTable GetPartition interface.
+func (t *partitionedTable) GetPartition(physicalID int64) table.PhysicalTable {
+	p, ok := t.partitions[physicalID]
+	if !ok {
+		return nil
+	}
+	return p
+}
+
+// GetPartitionByRow implements table.PartitionedTable GetPartitionByRow interface.
+func (t *partitionedTable) GetPartitionByRow(ctx context.Context, r []types.Datum) (table.PhysicalTable, error) {
+	p, err := t.getPartitionByRow(ctx, r)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return p, nil
+}
+
+func (t *partitionedTable) getPartitionByRow(ctx context.Context, r []types.Datum) (*partition, error) {
+	offset, err := t.partitionExpr.locatePartition(ctx, r)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return t.partitions[t.meta.Partition.Definitions[offset].ID], nil
+}
+
+// AddRecord implements table.Table AddRecord interface.
+func (t *partitionedTable) AddRecord(ctx context.Context, r []types.Datum) (int64, error) {
+	p, err := t.getPartitionByRow(ctx, r)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	h, err := p.AddRecord(ctx, r)
+	return h, errors.Trace(err)
+}
+
+// UpdateRecord implements table.Table UpdateRecord interface.
+// If the new row belongs to another partition, the row is moved to that partition with the same handle.
+func (t *partitionedTable) UpdateRecord(ctx context.Context, h int64, oldData, newData []types.Datum, touched []bool) error {
+	from, err := t.getPartitionByRow(ctx, oldData)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	to, err := t.getPartitionByRow(ctx, newData)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if from == to {
+		return errors.Trace(from.UpdateRecord(ctx, h, oldData, newData, touched))
+	}
+	err = from.RemoveRecord(ctx, h, oldData)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = to.addRecord(ctx, h, newData)
+	return errors.Trace(err)
+}
+
+// RemoveRecord implements table.Table RemoveRecord interface.
+func (t *partitionedTable) RemoveRecord(ctx context.Context, h int64, r []types.Datum) error {
+	p, err := t.getPartitionByRow(ctx, r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(p.RemoveRecord(ctx, h, r))
+}
+
+// RowWithCols implements table.Table RowWithCols interface.
+// The handle is unique in the partitioned table, so the row is searched in every partition.
+func (t *partitionedTable) RowWithCols(ctx context.Context, h int64, cols []*table.Column) ([]types.Datum, error) {
+	for _, def := range t.meta.Partition.Definitions {
+		r, err := t.partitions[def.ID].RowWithCols(ctx, h, cols)
+		if kv.ErrNotExist.Equal(err) {
+			continue
+		}
+		return r, errors.Trace(err)
+	}
+	return nil, errors.Trace(kv.ErrNotExist)
+}
+
+// Row implements table.Table Row interface.
+func (t *partitionedTable) Row(ctx context.Context, h int64) ([]types.Datum, error) {
+	r, err := t.RowWithCols(ctx, h, t.Cols())
+	return r, errors.Trace(err)
+}
+
+// IterRecords implements table.Table IterRecords interface.
+// The partitions are iterated in the order of the partition definitions. If startKey is a record key
+// of a partition, the iteration starts from it, otherwise it starts from the first partition.
+func (t *partitionedTable) IterRecords(ctx context.Context, startKey kv.Key, cols []*table.Column,
+	fn table.RecordIterFunc) error {
+	defs := t.meta.Partition.Definitions
+	start := 0
+	for i, def := range defs {
+		if startKey.HasPrefix(t.partitions[def.ID].RecordPrefix()) {
+			start = i
+			break
+		}
+	}
+	stopped := false
+	iterFn := func(h int64, rec []types.Datum, cols []*table.Column) (bool, error) {
+		more, err := fn(h, rec, cols)
+		stopped = !more
+		return more, errors.Trace(err)
+	}
+	for i := start; i < len(defs) && !stopped; i++ {
+		p := t.partitions[defs[i].ID]
+		seekKey := p.FirstKey()
+		if startKey.HasPrefix(p.RecordPrefix()) {
+			seekKey = startKey
+		}
+		if err := p.IterRecords(ctx, seekKey, cols, iterFn); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// Seek implements table.Table Seek interface.
+func (t *partitionedTable) Seek(ctx context.Context, h int64) (int64, bool, error) {
+	var handle int64
+	var found bool
+	for _, p := range t.partitions {
+		ph, ok, err := p.Seek(ctx, h)
+		if err != nil {
+			return 0, false, errors.Trace(err)
+		}
+		if ok && (!found || ph < handle) {
+			handle, found = ph, true
+		}
+	}
+	return handle, found, nil
+}
@@ -97,11 +97,14 @@ func TableFromMeta(alloc autoid.Allocator, tblInfo *model.TableInfo) (table.Tabl
 			return nil, table.ErrIndexStateCantNone.Gen("index %s can't be in none state", idxInfo.Name)
 		}
 
-		idx := NewIndex(tblInfo, idxInfo)
+		idx := NewIndex(tblInfo.ID, tblInfo, idxInfo)
 		t.indices = append(t.indices, idx)
 	}
 
 	t.meta = tblInfo
+	if tblInfo.Partition != nil {
+		return newPartitionedTable(t, tblInfo)
+	}
 	return t, nil
 }
 
@@ -120,6 +123,11 @@ func newTable(tableID int64, cols []*table.Column, alloc autoid.Allocator) *Tabl
 	return t
 }
 
+// GetPhysicalID implements table.PhysicalTable GetPhysicalID interface.
+func (t *Table) GetPhysicalID() int64 {
+	return t.ID
+}
+
 // Indices implements table.Table Indices interface.
 func (t *Table) Indices() []table.Index {
 	return t.indices
@@ -213,8 +221,19 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData, newData []ty
 	txn := ctx.Txn()
 	bs := kv.NewBufferStore(txn)
 
+	// The old value of the changing column isn't in the indices if it can't be converted.
+	oldData, err := t.fillChangingColumns(ctx, oldData, true)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	newData, err = t.fillChangingColumns(ctx, newData, false)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	touched = t.touchChangingColumns(touched)
+
 	// rebuild index
-	err := t.rebuildIndices(bs, h, touched, oldData, newData)
+	err = t.rebuildIndices(bs, h, touched, oldData, newData)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -231,7 +250,10 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData, newData []ty
 
 	for _, col := range t.WritableCols() {
 		var value types.Datum
-		if col.State != model.StatePublic {
+		if col.ChangeStateInfo != nil {
+			// If col is a changing column, the value is converted from its origin column.
+			value = newData[col.Offset]
+		} else if col.State != model.StatePublic {
 			// If col is in write only or write reorganization state
 			// and the value is not default, keep the original value.
 			value, err = table.GetColOriginDefaultValue(ctx, col.ToInfo())
@@ -321,12 +343,23 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64,
 		}
 	}
 	if !hasRecordID {
-		recordID, err = t.alloc.Alloc(t.ID)
+		recordID, err = t.AllocAutoID()
 		if err != nil {
 			return 0, errors.Trace(err)
 		}
 	}
 
+	h, err := t.addRecord(ctx, recordID, r)
+	if err != nil {
+		return h, errors.Trace(err)
+	}
+	ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
+	ctx.GetSessionVars().TxnCtx.UpdateDeltaForTable(t.meta.ID, 1, 1)
+	return recordID, nil
+}
+
+// addRecord adds the row with the record ID, and inserts the index entries of the row.
+func (t *Table) addRecord(ctx context.Context, recordID int64, r []types.Datum) (int64, error) {
 	txn := ctx.Txn()
 	bs := kv.NewBufferStore(txn)
 
@@ -335,6 +368,10 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64,
 		txn.SetOption(kv.SkipCheckForWrite, true)
 	}
 
+	r, err := t.fillChangingColumns(ctx, r, false)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
 	// Insert new entries into indices.
 	h, err := t.addIndices(ctx, recordID, r, bs)
 	if err != nil {
@@ -348,7 +385,10 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64,
 
 	for _, col := range t.WritableCols() {
 		var value types.Datum
-		if col.State != model.StatePublic {
+		if col.ChangeStateInfo != nil {
+			// If col is a changing column, the value is converted from its origin column.
+			value = r[col.Offset]
+		} else if col.State != model.StatePublic {
 			// If col is in write only or write reorganization state, we must add it with its default value.
 			value, err = table.GetColOriginDefaultValue(ctx, col.ToInfo())
 			if err != nil {
@@ -380,11 +420,60 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64,
 		binlogColIDs = colIDs
 		t.addInsertBinlog(ctx, recordID, binlogRow, binlogColIDs)
 	}
-	ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
-	ctx.GetSessionVars().TxnCtx.UpdateDeltaForTable(t.ID, 1, 1)
 	return recordID, nil
 }
 
+// fillChangingColumns fills the values of the changing columns, which are converted from their origin columns, into
+// the row, so the indices covering the changing columns get the converted values. If the row only has the public
+// columns, the row is extended. If ignoreErr is true, the value which can't be converted is skipped.
+// The origin column of a modify column job is converted back from the new column, a value which can't be converted
+// back is always skipped, because the statement is valid for the new column.
+func (t *Table) fillChangingColumns(ctx context.Context, r []types.Datum, ignoreErr bool) ([]types.Datum, error) {
+	for _, col := range t.Columns {
+		if col.ChangeStateInfo == nil || col.State == model.StateNone {
+			continue
+		}
+		if col.Offset >= len(r) {
+			row := make([]types.Datum, len(t.Columns))
+			copy(row, r)
+			r = row
+		}
+		dependency := r[col.ChangeStateInfo.DependencyColumnOffset]
+		if col.ChangeStateInfo.Origin {
+			value, err := dependency.ConvertTo(ctx.GetSessionVars().StmtCtx, &col.FieldType)
+			if err == nil {
+				r[col.Offset] = value
+			}
+			continue
+		}
+		value, err := table.CastValue(ctx, dependency, col.ToInfo())
+		if err != nil {
+			if ignoreErr {
+				continue
+			}
+			return nil, errors.Trace(err)
+		}
+		r[col.Offset] = value
+	}
+	return r, nil
+}
+
+// touchChangingColumns marks the changing columns touched if their origin columns are touched.
+func (t *Table) touchChangingColumns(touched []bool) []bool {
+	for _, col := range t.Columns {
+		if col.ChangeStateInfo == nil || col.State == model.StateNone {
+			continue
+		}
+		if col.Offset >= len(touched) {
+			newTouched := make([]bool, len(t.Columns))
+			copy(newTouched, touched)
+			touched = newTouched
+		}
+		touched[col.Offset] = touched[col.ChangeStateInfo.DependencyColumnOffset]
+	}
+	return touched
+}
+
 // genIndexKeyStr generates index content string representation.
 func (t *Table) genIndexKeyStr(colVals []types.Datum) (string, error) {
 	// Pass pre-composed error to txn.
@@ -450,6 +539,37 @@ func (t *Table) addIndices(ctx context.Context, recordID int64, r []types.Datum,
 
 // RowWithCols implements table.Table RowWithCols interface.
 func (t *Table) RowWithCols(ctx context.Context, h int64, cols []*table.Column) ([]types.Datum, error) {
+	v, err := t.decodeRowWithCols(ctx, h, cols)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !HasVirtualColumn(cols) {
+		return v, nil
+	}
+	// The virtual generated columns aren't stored, evaluate them with the whole row.
+	row, err := t.decodeRowWithCols(ctx, h, t.Cols())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err = FillVirtualColumnValues(ctx, t, row); err != nil {
+		return nil, errors.Trace(err)
+	}
+	for i, col := range cols {
+		if col == nil || !col.IsGenerated() || col.GeneratedStored {
+			continue
+		}
+		for j, c := range t.Cols() {
+			if c.ID == col.ID {
+				v[i] = row[j]
+				break
+			}
+		}
+	}
+	return v, nil
+}
+
+// decodeRowWithCols gets the row with the columns from kv, the virtual generated columns are left null.
+func (t *Table) decodeRowWithCols(ctx context.Context, h int64, cols []*table.Column) ([]types.Datum, error) {
 	// Get raw row data from kv.
 	key := t.RecordKey(h)
 	value, err := ctx.Txn().Get(key)
@@ -482,7 +602,7 @@ func (t *Table) RowWithCols(ctx context.Context, h int64, cols []*table.Column)
 		if col == nil {
 			continue
 		}
-		if col.IsPKHandleColumn(t.meta) {
+		if col.IsPKHandleColumn(t.meta) || (col.IsGenerated() && !col.GeneratedStored) {
 			continue
 		}
 		ri, ok := rowMap[col.ID]
@@ -513,7 +633,12 @@ func (t *Table) RemoveRecord(ctx context.Context, h int64, r []types.Datum) erro
 	if err != nil {
 		return errors.Trace(err)
 	}
-	err = t.removeRowIndices(ctx, h, r)
+	// The value of the changing column isn't in the indices if it can't be converted.
+	idxRow, err := t.fillChangingColumns(ctx, r, true)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = t.removeRowIndices(ctx, h, idxRow)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -707,7 +832,7 @@ func GetColDefaultValue(ctx context.Context, col *table.Column, defaultVals []ty
 
 // AllocAutoID implements table.Table AllocAutoID interface.
 func (t *Table) AllocAutoID() (int64, error) {
-	return t.alloc.Alloc(t.ID)
+	return t.alloc.Alloc(t.meta.ID)
 }
 
 // Allocator implements table.Table Allocator interface.
@@ -717,7 +842,7 @@ func (t *Table) Allocator() autoid.Allocator {
 
 // RebaseAutoID implements table.Table RebaseAutoID interface.
 func (t *Table) RebaseAutoID(newBase int64, isSetStep bool) error {
-	return t.alloc.Rebase(t.ID, newBase, isSetStep)
+	return t.alloc.Rebase(t.meta.ID, newBase, isSetStep)
 }
 
 // Seek implements table.Table Seek interface.
@@ -745,12 +870,12 @@ func shouldWriteBinlog(ctx context.Context) bool {
 func (t *Table) getMutation(ctx context.Context) *binlog.TableMutation {
 	bin := binloginfo.GetPrewriteValue(ctx, true)
 	for i := range bin.Mutations {
-		if bin.Mutations[i].TableId == t.ID {
+		if bin.Mutations[i].TableId == t.meta.ID {
 			return &bin.Mutations[i]
 		}
 	}
 	idx := len(bin.Mutations)
-	bin.Mutations = append(bin.Mutations, binlog.TableMutation{TableId: t.ID})
+	bin.Mutations = append(bin.Mutations, binlog.TableMutation{TableId: t.meta.ID})
 	return &bin.Mutations[idx]
 }
 
@@ -82,6 +82,8 @@ const (
 	ClassGlobal
 	ClassMockTikv
 	ClassJSON
+	ClassXServer
+	ClassGeo
 	// Add more as needed.
 )
 
@@ -107,6 +109,8 @@ var errClz2Str = map[ErrClass]string{
 	ClassTypes:         "types",
 	ClassGlobal:        "global",
 	ClassMockTikv:      "mocktikv",
+	ClassXServer:       "xserver",
+	ClassGeo:           "geo",
 }
 
 // String implements fmt.Stringer interface.
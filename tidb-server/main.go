@@ -37,6 +37,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/binloginfo"
 	"github.com/pingcap/tidb/store/localstore/boltdb"
 	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/util/encrypt"
 	"github.com/pingcap/tidb/util/printer"
 	"github.com/pingcap/tidb/x-server"
 	"github.com/pingcap/tipb/go-binlog"
@@ -59,12 +60,18 @@ var (
 	statsLease          = flag.String("statsLease", "3s", "stats lease duration, which inflences the time of analyze and stats load.")
 	socket              = flag.String("socket", "", "The socket file to use for connection.")
 	xsocket             = flag.String("xsocket", "", "The socket file to use for x protocol connection.")
+	sslCA               = flag.String("ssl-ca", "", "The CA certificate file to verify client certificates.")
+	sslCert             = flag.String("ssl-cert", "", "The certificate file for TLS connection, TLS is enabled if both certificate and key are set.")
+	sslKey              = flag.String("ssl-key", "", "The private key file for TLS connection.")
+	desKeyFile          = flag.String("des-key-file", "", "The file of the keys used by DES_ENCRYPT and DES_DECRYPT.")
+	xtlsCert            = flag.String("xtls-cert", "", "The certificate file for x protocol TLS connection.")
+	xtlsKey             = flag.String("xtls-key", "", "The private key file for x protocol TLS connection.")
 	enablePS            = flagBoolean("perfschema", false, "If enable performance schema.")
 	enablePrivilege     = flagBoolean("privilege", true, "If enable privilege check feature. This flag will be removed in the future.")
 	reportStatus        = flagBoolean("report-status", true, "If enable status report HTTP service.")
 	logFile             = flag.String("log-file", "", "log file path")
 	joinCon             = flag.Int("join-concurrency", 5, "the number of goroutines that participate joining.")
-	crossJoin           = flagBoolean("cross-join", true, "whether support cartesian product or not.")
+	crossJoin           = flagBoolean("cross-join", true, "Deprecated: only true is accepted, use tidb_max_cartesian_product_rows to limit the cartesian products.")
 	metricsAddr         = flag.String("metrics-addr", "", "prometheus pushgateway address, leaves it empty will disable prometheus push.")
 	metricsInterval     = flag.Int("metrics-interval", 15, "prometheus client push interval in second, set \"0\" to disable prometheus push.")
 	binlogSocket        = flag.String("binlog-socket", "", "socket file to write binlog")
@@ -75,6 +82,9 @@ var (
 	queryLogMaxlen      = flag.Int("query-log-max-len", 2048, "Maximum query length recorded in log")
 	startXServer        = flagBoolean("xserver", false, "start tidb x protocol server")
 	tcpKeepAlive        = flagBoolean("tcp-keep-alive", false, "set keep alive option for tcp connection.")
+	tmpDir              = flag.String("tmp-dir", os.TempDir(), "directory for temporary files, such as the ones spilled by sort operators.")
+	planCache           = flagBoolean("plan-cache", false, "Enable the cache of the plans of the prepared statements.")
+	planCacheCapacity   = flag.Int("plan-cache-capacity", 100, "The number of the plans of the prepared statements cached by each session.")
 	timeJumpBackCounter = prometheus.NewCounter(
 		prometheus.CounterOpts{
 			Namespace: "tidb",
@@ -119,11 +129,18 @@ func main() {
 	cfg.SlowThreshold = *slowThreshold
 	cfg.QueryLogMaxlen = *queryLogMaxlen
 	cfg.TCPKeepAlive = *tcpKeepAlive
+	cfg.TempDir = *tmpDir
+	cfg.SSLCA = *sslCA
+	cfg.SSLCert = *sslCert
+	cfg.SSLKey = *sslKey
+	cfg.DESKeyFile = *desKeyFile
 
 	xcfg := &xserver.Config{
 		Addr:     fmt.Sprintf("%s:%s", *xhost, *xport),
 		Socket:   *socket,
 		LogLevel: *logLevel,
+		TLSCert:  *xtlsCert,
+		TLSKey:   *xtlsKey,
 	}
 
 	// set log options
@@ -139,11 +156,23 @@ func main() {
 	if joinCon != nil && *joinCon > 0 {
 		plan.JoinConcurrency = *joinCon
 	}
-	plan.AllowCartesianProduct = *crossJoin
+	if !*crossJoin {
+		log.Fatal("cross-join=false is no longer supported, set the global variable tidb_max_cartesian_product_rows to limit the cartesian products")
+	}
+	plan.PreparedPlanCacheEnabled = *planCache
+	if *planCacheCapacity > 0 {
+		plan.PreparedPlanCacheCapacity = uint(*planCacheCapacity)
+	}
 	// Call this before setting log level to make sure that TiDB info could be printed.
 	printer.PrintTiDBInfo()
 	log.SetLevelByString(cfg.LogLevel)
 
+	if cfg.DESKeyFile != "" {
+		if err := encrypt.LoadDESKeyFile(cfg.DESKeyFile); err != nil {
+			log.Fatal(errors.ErrorStack(err))
+		}
+	}
+
 	store := createStore()
 
 	if *enablePS {
@@ -170,7 +199,7 @@ func main() {
 	}
 	var xsvr *xserver.Server
 	if *startXServer {
-		xsvr, err = xserver.NewServer(xcfg)
+		xsvr, err = xserver.NewServer(xcfg, driver)
 		if err != nil {
 			log.Fatal(errors.ErrorStack(err))
 		}
@@ -0,0 +1,443 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package userlock implements the user-level locks of GET_LOCK and its friends.
+// A lock is a key in the storage, so it's visible to all the TiDB servers of the
+// cluster. The value of the key records the owner of the lock and the time the
+// lock expires, the server holding the lock keeps renewing it, so the locks of a
+// crashed server are released automatically after TTL.
+package userlock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/store/tikv/oracle"
+)
+
+// TTL is the time a lock is kept after the server holding it stops renewing it.
+// It's a variable so tests can change it.
+var TTL = 30 * time.Second
+
+const (
+	// MaxNameLength is the max length of a lock name.
+	MaxNameLength = 64
+
+	minBackoff = 10 * time.Millisecond
+	maxBackoff = 500 * time.Millisecond
+)
+
+var lockPrefix = []byte("_userlock_")
+
+func lockKey(name string) kv.Key {
+	key := make([]byte, 0, len(lockPrefix)+len(name))
+	key = append(key, lockPrefix...)
+	return append(key, name...)
+}
+
+// NormalizeName checks the lock name and converts it to lower case, lock names are case insensitive.
+func NormalizeName(name string) (string, bool) {
+	if len(name) == 0 || len(name) > MaxNameLength {
+		return "", false
+	}
+	return strings.ToLower(name), true
+}
+
+// Lock is a user-level lock stored in the storage.
+type Lock struct {
+	Name         string `json:"-"`
+	ServerID     string `json:"server_id"`
+	ConnectionID uint64 `json:"conn_id"`
+	HolderID     string `json:"holder_id"`
+	// Expire is the physical time in milliseconds when the lock expires.
+	Expire int64 `json:"expire"`
+}
+
+// ExpireTime returns the time when the lock expires.
+func (l *Lock) ExpireTime() time.Time {
+	return time.Unix(0, l.Expire*int64(time.Millisecond))
+}
+
+// now returns the physical time of the transaction, it's used instead of the local
+// clock so the servers of the cluster agree on whether a lock expires.
+func now(txn kv.Transaction) int64 {
+	return oracle.ExtractPhysical(txn.StartTS())
+}
+
+func getLock(retriever kv.Retriever, name string) (*Lock, error) {
+	val, err := retriever.Get(lockKey(name))
+	if kv.IsErrNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	lock := &Lock{Name: name}
+	if err = json.Unmarshal(val, lock); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return lock, nil
+}
+
+func setLock(txn kv.Transaction, lock *Lock) error {
+	val, err := json.Marshal(lock)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(txn.Set(lockKey(lock.Name), val))
+}
+
+// Manager manages the user-level locks held by the sessions of a TiDB server.
+type Manager struct {
+	store    kv.Storage
+	serverID string
+	nextID   uint64
+	mu       struct {
+		sync.Mutex
+		holders map[*Holder]struct{}
+	}
+	exit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewManager creates a Manager and starts a goroutine renewing the locks.
+// serverID identifies the TiDB server in the cluster.
+func NewManager(store kv.Storage, serverID string) *Manager {
+	m := &Manager{
+		store:    store,
+		serverID: serverID,
+		exit:     make(chan struct{}),
+	}
+	m.mu.holders = make(map[*Holder]struct{})
+	m.wg.Add(1)
+	go m.renewLoop()
+	return m
+}
+
+// Close stops renewing the locks, the locks which are not released expire after TTL.
+func (m *Manager) Close() {
+	close(m.exit)
+	m.wg.Wait()
+}
+
+// NewHolder creates a Holder for a session.
+func (m *Manager) NewHolder() *Holder {
+	return &Holder{
+		m:     m,
+		id:    fmt.Sprintf("%s_%d", m.serverID, atomic.AddUint64(&m.nextID, 1)),
+		locks: make(map[string]int),
+	}
+}
+
+// IsUsed returns the lock if the lock of name is held by someone, or nil if it's free.
+func (m *Manager) IsUsed(name string) (*Lock, error) {
+	var lock *Lock
+	err := kv.RunInNewTxn(m.store, false, func(txn kv.Transaction) error {
+		var err error
+		lock, err = getLock(txn, name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if lock != nil && lock.Expire <= now(txn) {
+			lock = nil
+		}
+		return nil
+	})
+	return lock, errors.Trace(err)
+}
+
+// Locks returns all the locks held in the cluster.
+func (m *Manager) Locks() ([]*Lock, error) {
+	var locks []*Lock
+	err := kv.RunInNewTxn(m.store, false, func(txn kv.Transaction) error {
+		locks = locks[:0]
+		it, err := txn.Seek(lockPrefix)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		defer it.Close()
+		ts := now(txn)
+		for it.Valid() && bytes.HasPrefix(it.Key(), lockPrefix) {
+			lock := &Lock{Name: string(it.Key()[len(lockPrefix):])}
+			if err = json.Unmarshal(it.Value(), lock); err != nil {
+				return errors.Trace(err)
+			}
+			if lock.Expire > ts {
+				locks = append(locks, lock)
+			}
+			if err = it.Next(); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return nil
+	})
+	return locks, errors.Trace(err)
+}
+
+func (m *Manager) register(h *Holder) {
+	m.mu.Lock()
+	m.mu.holders[h] = struct{}{}
+	m.mu.Unlock()
+}
+
+func (m *Manager) unregister(h *Holder) {
+	m.mu.Lock()
+	delete(m.mu.holders, h)
+	m.mu.Unlock()
+}
+
+func (m *Manager) renewLoop() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.exit:
+			return
+		case <-time.After(TTL / 3):
+		}
+		m.mu.Lock()
+		holders := make([]*Holder, 0, len(m.mu.holders))
+		for h := range m.mu.holders {
+			holders = append(holders, h)
+		}
+		m.mu.Unlock()
+		for _, h := range holders {
+			if err := h.renew(); err != nil {
+				log.Warnf("[userlock] renew locks of %s failed %v", h.id, errors.ErrorStack(err))
+			}
+		}
+	}
+}
+
+// Holder holds the user-level locks of a session. A session can acquire the same
+// lock more than once, the lock is released when it is released as many times.
+type Holder struct {
+	m  *Manager
+	id string
+	mu sync.Mutex
+	// locks maps the name of the lock to the times it is acquired.
+	locks map[string]int
+}
+
+// Manager returns the Manager which creates the Holder.
+func (h *Holder) Manager() *Manager {
+	return h.m
+}
+
+// Acquire acquires the lock of name, it waits for the lock until timeout, a negative
+// timeout means waiting forever. It returns false if the lock isn't acquired in time.
+func (h *Holder) Acquire(name string, connID uint64, timeout time.Duration) (bool, error) {
+	h.mu.Lock()
+	if h.locks[name] > 0 {
+		h.locks[name]++
+		h.mu.Unlock()
+		return true, nil
+	}
+	// Don't hold the mutex while waiting, or the other locks can't be renewed.
+	h.mu.Unlock()
+
+	deadline := time.Now().Add(timeout)
+	backoff := minBackoff
+	for {
+		acquired, err := h.tryAcquire(name, connID)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if acquired {
+			h.mu.Lock()
+			if len(h.locks) == 0 {
+				h.m.register(h)
+			}
+			h.locks[name] = 1
+			h.mu.Unlock()
+			return true, nil
+		}
+
+		sleep := backoff
+		if timeout >= 0 {
+			left := deadline.Sub(time.Now())
+			if left <= 0 {
+				return false, nil
+			}
+			if left < sleep {
+				sleep = left
+			}
+		}
+		select {
+		case <-h.m.exit:
+			return false, nil
+		case <-time.After(sleep):
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func (h *Holder) tryAcquire(name string, connID uint64) (bool, error) {
+	var acquired bool
+	err := kv.RunInNewTxn(h.m.store, true, func(txn kv.Transaction) error {
+		acquired = false
+		lock, err := getLock(txn, name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		ts := now(txn)
+		if lock != nil && lock.HolderID != h.id && lock.Expire > ts {
+			return nil
+		}
+		acquired = true
+		return errors.Trace(setLock(txn, &Lock{
+			Name:         name,
+			ServerID:     h.m.serverID,
+			ConnectionID: connID,
+			HolderID:     h.id,
+			Expire:       ts + int64(TTL/time.Millisecond),
+		}))
+	})
+	return acquired, errors.Trace(err)
+}
+
+// Release releases the lock of name once. held reports whether the lock is held by the
+// Holder, exists reports whether the lock is held by anyone.
+func (h *Holder) Release(name string) (held bool, exists bool, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.locks[name] > 1 {
+		h.locks[name]--
+		return true, true, nil
+	}
+	if h.locks[name] == 0 {
+		lock, err := h.m.IsUsed(name)
+		return false, lock != nil, errors.Trace(err)
+	}
+	if err = h.deleteLocks([]string{name}); err != nil {
+		return false, false, errors.Trace(err)
+	}
+	h.forget(name)
+	return true, true, nil
+}
+
+// ReleaseAll releases all the locks held by the Holder, it returns the number of
+// the locks released, including the repeated acquisitions.
+func (h *Holder) ReleaseAll() (int64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.locks) == 0 {
+		return 0, nil
+	}
+	var cnt int64
+	names := make([]string, 0, len(h.locks))
+	for name, n := range h.locks {
+		names = append(names, name)
+		cnt += int64(n)
+	}
+	if err := h.deleteLocks(names); err != nil {
+		return 0, errors.Trace(err)
+	}
+	for _, name := range names {
+		h.forget(name)
+	}
+	return cnt, nil
+}
+
+// deleteLocks deletes the locks from the storage if they are still held by the Holder.
+func (h *Holder) deleteLocks(names []string) error {
+	err := kv.RunInNewTxn(h.m.store, true, func(txn kv.Transaction) error {
+		for _, name := range names {
+			lock, err := getLock(txn, name)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if lock == nil || lock.HolderID != h.id {
+				continue
+			}
+			if err = txn.Delete(lockKey(name)); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return nil
+	})
+	return errors.Trace(err)
+}
+
+func (h *Holder) forget(name string) {
+	delete(h.locks, name)
+	if len(h.locks) == 0 {
+		h.m.unregister(h)
+	}
+}
+
+// renew extends the expire time of the locks held by the Holder. The locks taken by
+// others after they expire are forgotten.
+func (h *Holder) renew() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var lost []string
+	err := kv.RunInNewTxn(h.m.store, true, func(txn kv.Transaction) error {
+		lost = lost[:0]
+		ts := now(txn)
+		for name := range h.locks {
+			lock, err := getLock(txn, name)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if lock == nil || lock.HolderID != h.id {
+				lost = append(lost, name)
+				continue
+			}
+			lock.Expire = ts + int64(TTL/time.Millisecond)
+			if err = setLock(txn, lock); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, name := range lost {
+		log.Warnf("[userlock] lock %s of %s is lost", name, h.id)
+		h.forget(name)
+	}
+	return nil
+}
+
+type keyType int
+
+func (k keyType) String() string {
+	return "userlock-key"
+}
+
+const key keyType = 0
+
+// BindHolder binds Holder to context.
+func BindHolder(ctx context.Context, h *Holder) {
+	ctx.SetValue(key, h)
+}
+
+// GetHolder gets Holder from context.
+func GetHolder(ctx context.Context) *Holder {
+	if v, ok := ctx.Value(key).(*Holder); ok {
+		return v
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package userlock_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/store/localstore"
+	"github.com/pingcap/tidb/store/localstore/goleveldb"
+	"github.com/pingcap/tidb/userlock"
+	"github.com/pingcap/tidb/util/mock"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testUserLockSuite{})
+
+type testUserLockSuite struct {
+	store kv.Storage
+}
+
+func (s *testUserLockSuite) SetUpSuite(c *C) {
+	driver := localstore.Driver{Driver: goleveldb.MemoryDriver{}}
+	store, err := driver.Open("memory")
+	c.Assert(err, IsNil)
+	s.store = store
+}
+
+func (s *testUserLockSuite) TearDownSuite(c *C) {
+	s.store.Close()
+}
+
+func (s *testUserLockSuite) TestNormalizeName(c *C) {
+	defer testleak.AfterTest(c)()
+	name, ok := userlock.NormalizeName("MyLock")
+	c.Assert(ok, IsTrue)
+	c.Assert(name, Equals, "mylock")
+	_, ok = userlock.NormalizeName("")
+	c.Assert(ok, IsFalse)
+	_, ok = userlock.NormalizeName(strings.Repeat("a", userlock.MaxNameLength+1))
+	c.Assert(ok, IsFalse)
+}
+
+func (s *testUserLockSuite) TestAcquireRelease(c *C) {
+	defer testleak.AfterTest(c)()
+	m := userlock.NewManager(s.store, "server1")
+	defer m.Close()
+	h1, h2 := m.NewHolder(), m.NewHolder()
+
+	ok, err := h1.Acquire("l1", 1, 0)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	// The same holder can acquire the lock again.
+	ok, err = h1.Acquire("l1", 1, 0)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+
+	// Other holders wait until timeout.
+	start := time.Now()
+	ok, err = h2.Acquire("l1", 2, 100*time.Millisecond)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+	c.Assert(time.Since(start) >= 100*time.Millisecond, IsTrue)
+
+	lock, err := m.IsUsed("l1")
+	c.Assert(err, IsNil)
+	c.Assert(lock, NotNil)
+	c.Assert(lock.ConnectionID, Equals, uint64(1))
+	c.Assert(lock.ServerID, Equals, "server1")
+
+	held, exists, err := h2.Release("l1")
+	c.Assert(err, IsNil)
+	c.Assert(held, IsFalse)
+	c.Assert(exists, IsTrue)
+	held, exists, err = h2.Release("l2")
+	c.Assert(err, IsNil)
+	c.Assert(held, IsFalse)
+	c.Assert(exists, IsFalse)
+
+	// The lock is released after it's released as many times as it's acquired.
+	held, exists, err = h1.Release("l1")
+	c.Assert(err, IsNil)
+	c.Assert(held, IsTrue)
+	c.Assert(exists, IsTrue)
+	lock, err = m.IsUsed("l1")
+	c.Assert(err, IsNil)
+	c.Assert(lock, NotNil)
+	held, _, err = h1.Release("l1")
+	c.Assert(err, IsNil)
+	c.Assert(held, IsTrue)
+	lock, err = m.IsUsed("l1")
+	c.Assert(err, IsNil)
+	c.Assert(lock, IsNil)
+
+	// The waiting holder gets the lock after it's released.
+	ok, err = h1.Acquire("l1", 1, 0)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		h1.Release("l1")
+	}()
+	ok, err = h2.Acquire("l1", 2, -1)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	cnt, err := h2.ReleaseAll()
+	c.Assert(err, IsNil)
+	c.Assert(cnt, Equals, int64(1))
+}
+
+func (s *testUserLockSuite) TestReleaseAll(c *C) {
+	defer testleak.AfterTest(c)()
+	m := userlock.NewManager(s.store, "server1")
+	defer m.Close()
+	h := m.NewHolder()
+
+	for _, name := range []string{"a", "b", "b", "c"} {
+		ok, err := h.Acquire(name, 1, 0)
+		c.Assert(err, IsNil)
+		c.Assert(ok, IsTrue)
+	}
+	locks, err := m.Locks()
+	c.Assert(err, IsNil)
+	c.Assert(locks, HasLen, 3)
+	c.Assert(locks[0].Name, Equals, "a")
+
+	cnt, err := h.ReleaseAll()
+	c.Assert(err, IsNil)
+	c.Assert(cnt, Equals, int64(4))
+	locks, err = m.Locks()
+	c.Assert(err, IsNil)
+	c.Assert(locks, HasLen, 0)
+	cnt, err = h.ReleaseAll()
+	c.Assert(err, IsNil)
+	c.Assert(cnt, Equals, int64(0))
+}
+
+func (s *testUserLockSuite) TestExpire(c *C) {
+	defer testleak.AfterTest(c)()
+	oldTTL := userlock.TTL
+	userlock.TTL = 300 * time.Millisecond
+	defer func() {
+		userlock.TTL = oldTTL
+	}()
+
+	// The locks of a closed server expire after userlock.TTL.
+	m1 := userlock.NewManager(s.store, "server1")
+	ok, err := m1.NewHolder().Acquire("l", 1, 0)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	m1.Close()
+
+	m2 := userlock.NewManager(s.store, "server2")
+	defer m2.Close()
+	h := m2.NewHolder()
+	ok, err = h.Acquire("l", 2, 0)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+	ok, err = h.Acquire("l", 2, time.Second)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+
+	// The locks of a running server are renewed.
+	time.Sleep(2 * userlock.TTL)
+	lock, err := m2.IsUsed("l")
+	c.Assert(err, IsNil)
+	c.Assert(lock, NotNil)
+	c.Assert(lock.ServerID, Equals, "server2")
+	_, err = h.ReleaseAll()
+	c.Assert(err, IsNil)
+}
+
+func (s *testUserLockSuite) TestBindHolder(c *C) {
+	defer testleak.AfterTest(c)()
+	m := userlock.NewManager(s.store, "server1")
+	defer m.Close()
+	ctx := mock.NewContext()
+	c.Assert(userlock.GetHolder(ctx), IsNil)
+	h := m.NewHolder()
+	userlock.BindHolder(ctx, h)
+	c.Assert(userlock.GetHolder(ctx), Equals, h)
+	c.Assert(userlock.GetHolder(ctx).Manager(), Equals, m)
+}
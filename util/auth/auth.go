@@ -26,6 +26,10 @@ import (
 type UserIdentity struct {
 	Username string
 	Hostname string
+	// AuthUsername and AuthHostname are the user name and the host of the account which the user is
+	// authenticated as, the host may be a pattern like '%'. They're empty if the user isn't authenticated.
+	AuthUsername string
+	AuthHostname string
 }
 
 // String converts UserIdentity to the format user@host.
@@ -34,6 +38,15 @@ func (user *UserIdentity) String() string {
 	return fmt.Sprintf("%s@%s", user.Username, user.Hostname)
 }
 
+// AuthIdentity returns the identity of the account which the user is authenticated as.
+// It's the user itself if the user isn't authenticated.
+func (user *UserIdentity) AuthIdentity() *UserIdentity {
+	if user.AuthUsername == "" && user.AuthHostname == "" {
+		return &UserIdentity{Username: user.Username, Hostname: user.Hostname}
+	}
+	return &UserIdentity{Username: user.AuthUsername, Hostname: user.AuthHostname}
+}
+
 // CheckScrambledPassword check scrambled password received from client.
 // The new authentication is performed in following manner:
 //   SERVER:  public_seed=create_random_string()
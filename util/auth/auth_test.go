@@ -14,10 +14,16 @@
 package auth
 
 import (
+	"testing"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/util/testleak"
 )
 
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
 var _ = Suite(&testAuthSuite{})
 
 type testAuthSuite struct {
@@ -0,0 +1,181 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+)
+
+// Names of the builtin authentication plugins.
+const (
+	MySQLNativePassword = "mysql_native_password"
+	CachingSHA2Password = "caching_sha2_password"
+	SHA256Password      = "sha256_password"
+	// MySQLClearPassword is a client side plugin only, it sends the password in plaintext.
+	MySQLClearPassword = "mysql_clear_password"
+)
+
+// AuthPlugin is the server side of an authentication method. The client side plugin
+// returned by ClientPlugin decides the format of the authentication data:
+//
+//	mysql_native_password: the scramble of SHA1, see CheckScrambledPassword.
+//	caching_sha2_password: the scramble of SHA256, see CheckSHA2ScrambledPassword.
+//	sha256_password, mysql_clear_password: the plaintext password.
+type AuthPlugin interface {
+	// Name returns the name of the plugin, it's stored in the plugin column of mysql.user.
+	Name() string
+	// ClientPlugin returns the name of the client side plugin which generates the authentication data.
+	ClientPlugin() string
+	// EncodePassword converts the plaintext password to the authentication string stored in mysql.user.
+	EncodePassword(pwd string) string
+	// Authenticate checks the authentication data sent by the client for the user against the
+	// stored authentication string. salt is the random data sent to the client in the handshake.
+	Authenticate(user, authString string, authData, salt []byte) bool
+}
+
+var (
+	pluginsMu sync.RWMutex
+	plugins   = map[string]AuthPlugin{
+		MySQLNativePassword: nativePasswordPlugin{},
+		CachingSHA2Password: sha2PasswordPlugin{name: CachingSHA2Password},
+		SHA256Password:      sha2PasswordPlugin{name: SHA256Password},
+	}
+)
+
+// RegisterAuthPlugin registers an authentication plugin, so users can be created with
+// "IDENTIFIED WITH" the plugin name.
+func RegisterAuthPlugin(p AuthPlugin) error {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	if _, ok := plugins[p.Name()]; ok {
+		return errors.Errorf("authentication plugin %s is already registered", p.Name())
+	}
+	plugins[p.Name()] = p
+	return nil
+}
+
+// GetAuthPlugin returns the authentication plugin by name, the empty name means mysql_native_password.
+func GetAuthPlugin(name string) (AuthPlugin, bool) {
+	if len(name) == 0 {
+		name = MySQLNativePassword
+	}
+	pluginsMu.RLock()
+	p, ok := plugins[name]
+	pluginsMu.RUnlock()
+	return p, ok
+}
+
+type nativePasswordPlugin struct{}
+
+func (nativePasswordPlugin) Name() string {
+	return MySQLNativePassword
+}
+
+func (nativePasswordPlugin) ClientPlugin() string {
+	return MySQLNativePassword
+}
+
+func (nativePasswordPlugin) EncodePassword(pwd string) string {
+	return EncodePassword(pwd)
+}
+
+func (nativePasswordPlugin) Authenticate(user, authString string, authData, salt []byte) bool {
+	if len(authString) == 0 || len(authData) == 0 {
+		return len(authString) == 0 && len(authData) == 0
+	}
+	hpwd, err := DecodePassword(authString)
+	if err != nil || len(hpwd) != len(authData) {
+		log.Errorf("User [%s] password from SystemDB not like a sha1sum", user)
+		return false
+	}
+	return CheckScrambledPassword(salt, hpwd, authData)
+}
+
+// sha2PasswordPlugin implements caching_sha2_password and sha256_password, both of them store
+// the SHA256 hash of the SHA256 hash of the password. caching_sha2_password checks the scramble
+// sent by the client like mysql_native_password does, so it doesn't need a cache of the password.
+type sha2PasswordPlugin struct {
+	name string
+}
+
+func (p sha2PasswordPlugin) Name() string {
+	return p.name
+}
+
+func (p sha2PasswordPlugin) ClientPlugin() string {
+	return p.name
+}
+
+func (p sha2PasswordPlugin) EncodePassword(pwd string) string {
+	if len(pwd) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("$SHA2$%X", sha256Hash(sha256Hash([]byte(pwd))))
+}
+
+func (p sha2PasswordPlugin) Authenticate(user, authString string, authData, salt []byte) bool {
+	if len(authString) == 0 || len(authData) == 0 {
+		return len(authString) == 0 && len(authData) == 0
+	}
+	hpwd, err := decodeSHA2Password(authString)
+	if err != nil {
+		log.Errorf("User [%s] password from SystemDB not like a sha256sum", user)
+		return false
+	}
+	if p.name == CachingSHA2Password {
+		return CheckSHA2ScrambledPassword(salt, hpwd, authData)
+	}
+	return bytes.Equal(hpwd, sha256Hash(sha256Hash(authData)))
+}
+
+func decodeSHA2Password(pwd string) ([]byte, error) {
+	const prefix = "$SHA2$"
+	if len(pwd) != len(prefix)+sha256.Size*2 || pwd[:len(prefix)] != prefix {
+		return nil, errors.Errorf("invalid SHA2 password %s", pwd)
+	}
+	x, err := hex.DecodeString(pwd[len(prefix):])
+	return x, errors.Trace(err)
+}
+
+// CheckSHA2ScrambledPassword checks the scramble of caching_sha2_password, it works like
+// CheckScrambledPassword except that SHA256 is used:
+//
+//	CLIENT:  reply=xor(sha256(password), sha256(sha256(sha256(password)), public_seed))
+//	SERVER:  hash_stage1=xor(reply, sha256(hash_stage2, public_seed))
+//	         check(sha256(hash_stage1)==hash_stage2)
+func CheckSHA2ScrambledPassword(salt, hpwd, auth []byte) bool {
+	if len(auth) != sha256.Size {
+		return false
+	}
+	crypt := sha256.New()
+	crypt.Write(hpwd)
+	crypt.Write(salt)
+	hash := crypt.Sum(nil)
+	for i := range hash {
+		hash[i] ^= auth[i]
+	}
+	return bytes.Equal(hpwd, sha256Hash(hash))
+}
+
+func sha256Hash(bs []byte) []byte {
+	hash := sha256.Sum256(bs)
+	return hash[:]
+}
@@ -0,0 +1,91 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+// scrambleSHA2Password computes the scramble like a caching_sha2_password client does.
+func scrambleSHA2Password(salt []byte, pwd string) []byte {
+	stage1 := sha256Hash([]byte(pwd))
+	scramble := sha256Hash(append(sha256Hash(stage1), salt...))
+	for i := range scramble {
+		scramble[i] ^= stage1[i]
+	}
+	return scramble
+}
+
+type testPlugin struct {
+	name string
+}
+
+func (p testPlugin) Name() string                     { return p.name }
+func (p testPlugin) ClientPlugin() string             { return MySQLClearPassword }
+func (p testPlugin) EncodePassword(pwd string) string { return pwd }
+func (p testPlugin) Authenticate(user, authString string, authData, salt []byte) bool {
+	return authString == string(authData)
+}
+
+func (s *testAuthSuite) TestBuiltinPlugins(c *C) {
+	defer testleak.AfterTest(c)()
+	salt := []byte("0123456789abcdefghij")
+
+	native, ok := GetAuthPlugin("")
+	c.Assert(ok, IsTrue)
+	c.Assert(native.Name(), Equals, MySQLNativePassword)
+	authString := native.EncodePassword("abc")
+	c.Assert(authString, Equals, EncodePassword("abc"))
+	c.Assert(native.Authenticate("u", authString, []byte("short"), salt), IsFalse)
+	c.Assert(native.Authenticate("u", "", nil, salt), IsTrue)
+	c.Assert(native.Authenticate("u", authString, nil, salt), IsFalse)
+
+	cachingSHA2, ok := GetAuthPlugin(CachingSHA2Password)
+	c.Assert(ok, IsTrue)
+	c.Assert(cachingSHA2.ClientPlugin(), Equals, CachingSHA2Password)
+	authString = cachingSHA2.EncodePassword("abc")
+	c.Assert(authString, Matches, `\$SHA2\$[0-9A-F]{64}`)
+	c.Assert(cachingSHA2.Authenticate("u", authString, scrambleSHA2Password(salt, "abc"), salt), IsTrue)
+	c.Assert(cachingSHA2.Authenticate("u", authString, scrambleSHA2Password(salt, "abd"), salt), IsFalse)
+	c.Assert(cachingSHA2.Authenticate("u", authString, scrambleSHA2Password([]byte("other salt"), "abc"), salt), IsFalse)
+	c.Assert(cachingSHA2.Authenticate("u", authString, []byte("abc"), salt), IsFalse)
+	c.Assert(cachingSHA2.Authenticate("u", "*invalid", scrambleSHA2Password(salt, "abc"), salt), IsFalse)
+	c.Assert(cachingSHA2.Authenticate("u", cachingSHA2.EncodePassword(""), nil, salt), IsTrue)
+
+	sha256, ok := GetAuthPlugin(SHA256Password)
+	c.Assert(ok, IsTrue)
+	// Both SHA2 plugins store the password the same way.
+	c.Assert(sha256.EncodePassword("abc"), Equals, authString)
+	c.Assert(sha256.Authenticate("u", authString, []byte("abc"), salt), IsTrue)
+	c.Assert(sha256.Authenticate("u", authString, []byte("abd"), salt), IsFalse)
+
+	_, ok = GetAuthPlugin("no_such_plugin")
+	c.Assert(ok, IsFalse)
+}
+
+func (s *testAuthSuite) TestRegisterAuthPlugin(c *C) {
+	defer testleak.AfterTest(c)()
+	err := RegisterAuthPlugin(testPlugin{name: "test_register_plugin"})
+	c.Assert(err, IsNil)
+	err = RegisterAuthPlugin(testPlugin{name: "test_register_plugin"})
+	c.Assert(err, NotNil)
+	err = RegisterAuthPlugin(testPlugin{name: MySQLNativePassword})
+	c.Assert(err, NotNil)
+
+	p, ok := GetAuthPlugin("test_register_plugin")
+	c.Assert(ok, IsTrue)
+	c.Assert(p.ClientPlugin(), Equals, MySQLClearPassword)
+	c.Assert(p.Authenticate("u", "secret", []byte("secret"), nil), IsTrue)
+}
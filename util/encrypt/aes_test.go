@@ -16,6 +16,8 @@ package encrypt
 import (
 	"crypto/aes"
 	"encoding/hex"
+	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
 
@@ -286,3 +288,93 @@ func (s *testEncryptSuite) TestDeriveKeyMySQL(c *C) {
 	p = DeriveKeyMySQL(p, 16)
 	c.Assert(toHex(p), Equals, "22163D0233131607210A001D4C6F6F6F")
 }
+
+func (s *testEncryptSuite) TestUnixCrypt(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		password string
+		salt     string
+		expect   string
+	}{
+		{"test", "ab", "abgOeLfPimXQo"},
+		{"mypassword", "xy", "xyoxiBrqcbujE"},
+		{"", "ab", "abmF1QH4PEr.E"},
+		{"abcdefghijk", "Zz", "ZzrUgYb/Yc/Qs"},
+		{"\x80\xffhello", "..", "..NP8awR3qS82"},
+		{"test", "abc", "abgOeLfPimXQo"},
+	}
+	for _, t := range tests {
+		crypted, err := UnixCrypt([]byte(t.password), []byte(t.salt))
+		c.Assert(err, IsNil)
+		c.Assert(crypted, Equals, t.expect)
+	}
+	for _, salt := range []string{"", "a", "a!", "$1$abc"} {
+		_, err := UnixCrypt([]byte("test"), []byte(salt))
+		c.Assert(err, NotNil)
+	}
+}
+
+func (s *testEncryptSuite) TestDES(c *C) {
+	defer testleak.AfterTest(c)()
+	key := DeriveDESKey([]byte("key"))
+	c.Assert(toHex(key), Equals, "3C6E0B8A9C15224A8228B9A98CA1531DD1E2A35FBA509B64")
+
+	crypted, err := DESEncrypt([]byte("hello"), key, DESUserKeyNum)
+	c.Assert(err, IsNil)
+	c.Assert(toHex(crypted), Equals, "FFB6D0B8D9BBB00BEC")
+	c.Assert(IsDESEncrypted(crypted), IsTrue)
+	c.Assert(DESKeyNum(crypted), Equals, DESUserKeyNum)
+	plain, err := DESDecrypt(crypted, key)
+	c.Assert(err, IsNil)
+	c.Assert(string(plain), Equals, "hello")
+
+	for _, str := range []string{"", "a", "12345678", "123456789abcdefgh"} {
+		crypted, err = DESEncrypt([]byte(str), key, 3)
+		c.Assert(err, IsNil)
+		c.Assert(len(crypted)%8, Equals, 1)
+		c.Assert(DESKeyNum(crypted), Equals, 3)
+		plain, err = DESDecrypt(crypted, key)
+		c.Assert(err, IsNil)
+		c.Assert(string(plain), Equals, str)
+	}
+	c.Assert(IsDESEncrypted([]byte("hello")), IsFalse)
+}
+
+func (s *testEncryptSuite) TestDESKeyFile(c *C) {
+	defer testleak.AfterTest(c)()
+	f, err := ioutil.TempFile("", "des_key_file")
+	c.Assert(err, IsNil)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("# comment\n5 key5\n\nx wrong\n1  key1 \n")
+	c.Assert(err, IsNil)
+	f.Close()
+
+	c.Assert(LoadDESKeyFile(f.Name()), IsNil)
+	num, key, ok := GetDESKey(-1)
+	c.Assert(ok, IsTrue)
+	c.Assert(num, Equals, 5)
+	c.Assert(key, DeepEquals, DeriveDESKey([]byte("key5")))
+	_, key, ok = GetDESKey(1)
+	c.Assert(ok, IsTrue)
+	c.Assert(key, DeepEquals, DeriveDESKey([]byte("key1")))
+	_, _, ok = GetDESKey(2)
+	c.Assert(ok, IsFalse)
+	_, _, ok = GetDESKey(10)
+	c.Assert(ok, IsFalse)
+	c.Assert(LoadDESKeyFile(f.Name()+".not_exist"), NotNil)
+}
+
+func (s *testEncryptSuite) TestSQLCrypt(c *C) {
+	defer testleak.AfterTest(c)()
+	c.Assert(OldPassword([]byte("mypass")), Equals, "6f8c114b58f2ce9e")
+	// Spaces and tabs are ignored.
+	c.Assert(OldPassword([]byte("my pass\t")), Equals, "6f8c114b58f2ce9e")
+
+	for _, str := range []string{"", "a", "hello world", "\x00\xff\x80"} {
+		encoded := NewSQLCrypt([]byte("pass")).Encode([]byte(str))
+		c.Assert(encoded, HasLen, len(str))
+		c.Assert(string(NewSQLCrypt([]byte("pass")).Decode(encoded)), Equals, str)
+	}
+	encoded := NewSQLCrypt([]byte("pass")).Encode([]byte("hello"))
+	c.Assert(string(NewSQLCrypt([]byte("wrong")).Decode(encoded)), Not(Equals), "hello")
+}
@@ -0,0 +1,168 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encrypt
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/md5"
+	"os"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+)
+
+const (
+	// DESKeyLen is the length of the triple DES keys used by DES_ENCRYPT and DES_DECRYPT.
+	DESKeyLen = 24
+	// MaxDESKeyNum is the max key number of the DES key file.
+	MaxDESKeyNum = 9
+	// DESUserKeyNum is the key number stored in the result of DES_ENCRYPT when the key
+	// is given by a string.
+	DESUserKeyNum = 127
+)
+
+// DeriveDESKey derives the triple DES key from the key string in the same way MySQL does,
+// which is EVP_BytesToKey of OpenSSL with MD5, no salt and one iteration.
+func DeriveDESKey(keyStr []byte) []byte {
+	key := make([]byte, 0, DESKeyLen+md5.Size)
+	var prev []byte
+	for len(key) < DESKeyLen {
+		h := md5.New()
+		h.Write(prev)
+		h.Write(keyStr)
+		prev = h.Sum(nil)
+		key = append(key, prev...)
+	}
+	return key[:DESKeyLen]
+}
+
+// DESEncrypt encrypts str with triple DES in CBC mode like DES_ENCRYPT of MySQL does.
+// The first byte of the result is 128|keyNum, and the data is padded by '*'s followed
+// by the length of the padding.
+func DESEncrypt(str, key []byte, keyNum int) ([]byte, error) {
+	block, err := des.NewTripleDESCipher(key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	tail := des.BlockSize - len(str)%des.BlockSize
+	data := make([]byte, len(str)+tail)
+	copy(data, str)
+	for i := len(str); i < len(data)-1; i++ {
+		data[i] = '*'
+	}
+	data[len(data)-1] = byte(tail)
+
+	result := make([]byte, len(data)+1)
+	result[0] = byte(128 | keyNum)
+	iv := make([]byte, des.BlockSize)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(result[1:], data)
+	return result, nil
+}
+
+// IsDESEncrypted checks whether cryptStr looks like a result of DESEncrypt.
+func IsDESEncrypted(cryptStr []byte) bool {
+	return len(cryptStr) >= des.BlockSize+1 && len(cryptStr)%des.BlockSize == 1 && cryptStr[0]&128 != 0
+}
+
+// DESKeyNum returns the key number stored in the result of DESEncrypt.
+func DESKeyNum(cryptStr []byte) int {
+	return int(cryptStr[0] & 127)
+}
+
+// DESDecrypt decrypts the result of DESEncrypt, cryptStr must satisfy IsDESEncrypted.
+// It returns an error if the key is wrong.
+func DESDecrypt(cryptStr, key []byte) ([]byte, error) {
+	block, err := des.NewTripleDESCipher(key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	data := make([]byte, len(cryptStr)-1)
+	iv := make([]byte, des.BlockSize)
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(data, cryptStr[1:])
+	tail := int(data[len(data)-1])
+	if tail > des.BlockSize {
+		return nil, errors.New("wrong DES key")
+	}
+	return data[:len(data)-tail], nil
+}
+
+// desKeyFile holds the keys loaded from the DES key file.
+var desKeyFile = struct {
+	sync.RWMutex
+	keys [MaxDESKeyNum + 1][]byte
+	// defaultKeyNum is the number of the first key in the file, or -1 if there is no key.
+	defaultKeyNum int
+}{defaultKeyNum: -1}
+
+// LoadDESKeyFile loads the keys used by DES_ENCRYPT and DES_DECRYPT from the file, it
+// replaces all the keys loaded before. Each line of the file is in the form of
+// "key_num des_key_str", key_num is a number in [0, 9], and the first key is the default one.
+// See https://dev.mysql.com/doc/refman/5.6/en/server-options.html#option_mysqld_des-key-file
+func LoadDESKeyFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer f.Close()
+
+	var keys [MaxDESKeyNum + 1][]byte
+	defaultKeyNum := -1
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		if len(line) < 2 || line[0] < '0' || line[0] > '9' || line[1] != ' ' {
+			log.Errorf("[encrypt] DES key file %s: found wrong key number: %c", path, line[0])
+			continue
+		}
+		keyStr := bytes.TrimSpace(line[2:])
+		if len(keyStr) == 0 {
+			continue
+		}
+		num := int(line[0] - '0')
+		keys[num] = DeriveDESKey(keyStr)
+		if defaultKeyNum == -1 {
+			defaultKeyNum = num
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return errors.Trace(err)
+	}
+
+	desKeyFile.Lock()
+	desKeyFile.keys = keys
+	desKeyFile.defaultKeyNum = defaultKeyNum
+	desKeyFile.Unlock()
+	return nil
+}
+
+// GetDESKey returns the key of number num loaded from the DES key file.
+// If num is negative, the default key is returned.
+func GetDESKey(num int) (int, []byte, bool) {
+	desKeyFile.RLock()
+	defer desKeyFile.RUnlock()
+	if num < 0 {
+		num = desKeyFile.defaultKeyNum
+	}
+	if num < 0 || num > MaxDESKeyNum || desKeyFile.keys[num] == nil {
+		return num, nil, false
+	}
+	return num, desKeyFile.keys[num], true
+}
@@ -0,0 +1,112 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encrypt
+
+import "fmt"
+
+// randStruct is the pseudo random number generator of MySQL, see my_rnd in mysys.
+type randStruct struct {
+	seed1       uint64
+	seed2       uint64
+	maxValue    uint64
+	maxValueDbl float64
+}
+
+func newRandStruct(seed1, seed2 uint64) *randStruct {
+	const maxValue = 0x3FFFFFFF
+	return &randStruct{
+		seed1:       seed1 % maxValue,
+		seed2:       seed2 % maxValue,
+		maxValue:    maxValue,
+		maxValueDbl: maxValue,
+	}
+}
+
+func (r *randStruct) next() float64 {
+	r.seed1 = (r.seed1*3 + r.seed2) % r.maxValue
+	r.seed2 = (r.seed1 + r.seed2 + 33) % r.maxValue
+	return float64(r.seed1) / r.maxValueDbl
+}
+
+// hashPassword323 is the password hash algorithm of MySQL 3.23, spaces and tabs in the
+// password are skipped.
+func hashPassword323(password []byte) (uint64, uint64) {
+	nr, add, nr2 := uint64(1345345333), uint64(7), uint64(0x12345671)
+	for _, c := range password {
+		if c == ' ' || c == '\t' {
+			continue
+		}
+		tmp := uint64(c)
+		nr ^= (((nr & 63) + add) * tmp) + (nr << 8)
+		nr2 += (nr2 << 8) ^ nr
+		add += tmp
+	}
+	return nr & (1<<31 - 1), nr2 & (1<<31 - 1)
+}
+
+// OldPassword computes the password hash of MySQL 3.23, which is a 16 hex digits string.
+// See https://dev.mysql.com/doc/refman/5.6/en/encryption-functions.html#function_old-password
+func OldPassword(password []byte) string {
+	nr, nr2 := hashPassword323(password)
+	return fmt.Sprintf("%08x%08x", nr, nr2)
+}
+
+// SQLCrypt is the stream cipher used by ENCODE and DECODE of MySQL.
+type SQLCrypt struct {
+	rand       *randStruct
+	encodeBuff [256]byte
+	decodeBuff [256]byte
+	shift      uint32
+}
+
+// NewSQLCrypt creates a SQLCrypt seeded by the password.
+func NewSQLCrypt(password []byte) *SQLCrypt {
+	nr, nr2 := hashPassword323(password)
+	sc := &SQLCrypt{rand: newRandStruct(nr, nr2)}
+	for i := 0; i < 256; i++ {
+		sc.decodeBuff[i] = byte(i)
+	}
+	for i := 0; i < 256; i++ {
+		idx := uint32(sc.rand.next() * 255.0)
+		sc.decodeBuff[idx], sc.decodeBuff[i] = sc.decodeBuff[i], sc.decodeBuff[idx]
+	}
+	for i := 0; i < 256; i++ {
+		sc.encodeBuff[sc.decodeBuff[i]] = byte(i)
+	}
+	return sc
+}
+
+// Encode encodes str like ENCODE of MySQL does. A SQLCrypt can be used only once.
+func (sc *SQLCrypt) Encode(str []byte) []byte {
+	result := make([]byte, len(str))
+	for i, c := range str {
+		sc.shift ^= uint32(sc.rand.next() * 255.0)
+		idx := uint32(c)
+		result[i] = sc.encodeBuff[idx] ^ byte(sc.shift)
+		sc.shift ^= idx
+	}
+	return result
+}
+
+// Decode decodes str like DECODE of MySQL does. A SQLCrypt can be used only once.
+func (sc *SQLCrypt) Decode(str []byte) []byte {
+	result := make([]byte, len(str))
+	for i, c := range str {
+		sc.shift ^= uint32(sc.rand.next() * 255.0)
+		idx := uint32(c) ^ sc.shift
+		result[i] = sc.decodeBuff[idx]
+		sc.shift ^= uint32(result[i])
+	}
+	return result
+}
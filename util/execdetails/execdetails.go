@@ -0,0 +1,226 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package execdetails
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RuntimeStatsColl collects the runtime statistics of the executors and the coprocessor tasks
+// of a statement, the statistics are keyed by the explain IDs of the plans.
+type RuntimeStatsColl struct {
+	mu        sync.Mutex
+	rootStats map[string]*RuntimeStats
+	copStats  map[string]*CopRuntimeStats
+}
+
+// NewRuntimeStatsColl creates a new RuntimeStatsColl.
+func NewRuntimeStatsColl() *RuntimeStatsColl {
+	return &RuntimeStatsColl{
+		rootStats: make(map[string]*RuntimeStats),
+		copStats:  make(map[string]*CopRuntimeStats),
+	}
+}
+
+// GetRootStats gets the runtime statistics of the executor built from the plan planID,
+// it's created if not exists.
+func (e *RuntimeStatsColl) GetRootStats(planID string) *RuntimeStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	stats, ok := e.rootStats[planID]
+	if !ok {
+		stats = &RuntimeStats{}
+		e.rootStats[planID] = stats
+	}
+	return stats
+}
+
+// GetCopStats gets the runtime statistics of the coprocessor tasks sent for the plan planID,
+// it's created if not exists.
+func (e *RuntimeStatsColl) GetCopStats(planID string) *CopRuntimeStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	stats, ok := e.copStats[planID]
+	if !ok {
+		stats = &CopRuntimeStats{}
+		e.copStats[planID] = stats
+	}
+	return stats
+}
+
+// ExistsRootStats checks whether the runtime statistics of the executor of the plan planID exist.
+func (e *RuntimeStatsColl) ExistsRootStats(planID string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, ok := e.rootStats[planID]
+	return ok
+}
+
+// ExistsCopStats checks whether the runtime statistics of the coprocessor tasks of the plan planID exist.
+func (e *RuntimeStatsColl) ExistsCopStats(planID string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, ok := e.copStats[planID]
+	return ok
+}
+
+// RuntimeStats collects the runtime statistics of an executor.
+type RuntimeStats struct {
+	// loop is the number of times the executor is opened.
+	loop int32
+	// consume is the wall time spent in the executor, including its children, in nanoseconds.
+	consume int64
+	// rows is the number of rows returned by the executor.
+	rows int64
+	// memory is the peak memory usage of the executor in bytes.
+	memory int64
+}
+
+// RecordOpen records an opening of the executor which takes d.
+func (e *RuntimeStats) RecordOpen(d time.Duration) {
+	atomic.AddInt32(&e.loop, 1)
+	atomic.AddInt64(&e.consume, int64(d))
+}
+
+// Record records a call of the executor which takes d and returns rowNum rows.
+func (e *RuntimeStats) Record(d time.Duration, rowNum int) {
+	atomic.AddInt64(&e.consume, int64(d))
+	atomic.AddInt64(&e.rows, int64(rowNum))
+}
+
+// RecordMemory records the memory usage of the executor, only the peak one is kept.
+func (e *RuntimeStats) RecordMemory(bytes int64) {
+	for {
+		old := atomic.LoadInt64(&e.memory)
+		if bytes <= old || atomic.CompareAndSwapInt64(&e.memory, old, bytes) {
+			return
+		}
+	}
+}
+
+// Loop returns the number of times the executor is opened.
+func (e *RuntimeStats) Loop() int32 {
+	return atomic.LoadInt32(&e.loop)
+}
+
+// Rows returns the number of rows returned by the executor.
+func (e *RuntimeStats) Rows() int64 {
+	return atomic.LoadInt64(&e.rows)
+}
+
+// Memory returns the peak memory usage of the executor in bytes.
+func (e *RuntimeStats) Memory() int64 {
+	return atomic.LoadInt64(&e.memory)
+}
+
+// String implements the fmt.Stringer interface.
+func (e *RuntimeStats) String() string {
+	return fmt.Sprintf("time:%v, loops:%d, rows:%d", time.Duration(atomic.LoadInt64(&e.consume)), e.Loop(), e.Rows())
+}
+
+// CopRuntimeStats collects the runtime statistics of the coprocessor tasks sent by an executor.
+type CopRuntimeStats struct {
+	mu sync.Mutex
+	// regionStats maps the region ID to the statistics of the tasks sent to the region.
+	regionStats map[uint64]*copRegionStats
+	tasks       int
+	maxTime     time.Duration
+	totalTime   time.Duration
+}
+
+type copRegionStats struct {
+	storeAddr string
+	tasks     int
+	time      time.Duration
+}
+
+// RecordOneCopTask records a coprocessor task sent to the region regionID on the store storeAddr, which takes d.
+func (s *CopRuntimeStats) RecordOneCopTask(regionID uint64, storeAddr string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.regionStats == nil {
+		s.regionStats = make(map[uint64]*copRegionStats)
+	}
+	stats, ok := s.regionStats[regionID]
+	if !ok {
+		stats = &copRegionStats{}
+		s.regionStats[regionID] = stats
+	}
+	stats.storeAddr = storeAddr
+	stats.tasks++
+	stats.time += d
+	s.tasks++
+	s.totalTime += d
+	if d > s.maxTime {
+		s.maxTime = d
+	}
+}
+
+// Tasks returns the number of the coprocessor tasks.
+func (s *CopRuntimeStats) Tasks() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tasks
+}
+
+// String implements the fmt.Stringer interface.
+func (s *CopRuntimeStats) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tasks == 0 {
+		return "cop_task:{num:0}"
+	}
+	regionIDs := make([]uint64, 0, len(s.regionStats))
+	for id := range s.regionStats {
+		regionIDs = append(regionIDs, id)
+	}
+	sort.Slice(regionIDs, func(i, j int) bool { return regionIDs[i] < regionIDs[j] })
+	buf := bytes.NewBufferString(fmt.Sprintf("cop_task:{num:%d, max:%v, avg:%v, regions:[",
+		s.tasks, s.maxTime, s.totalTime/time.Duration(s.tasks)))
+	for i, id := range regionIDs {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		stats := s.regionStats[id]
+		fmt.Fprintf(buf, "%d@%s:%v", id, stats.storeAddr, stats.time)
+		if stats.tasks > 1 {
+			fmt.Fprintf(buf, "/%d", stats.tasks)
+		}
+	}
+	buf.WriteString("]}")
+	return buf.String()
+}
+
+// FormatBytes formats the size in bytes in a human readable way.
+func FormatBytes(bytes int64) string {
+	const (
+		kb = 1 << 10
+		mb = 1 << 20
+		gb = 1 << 30
+	)
+	switch {
+	case bytes >= gb:
+		return fmt.Sprintf("%.2f GB", float64(bytes)/gb)
+	case bytes >= mb:
+		return fmt.Sprintf("%.2f MB", float64(bytes)/mb)
+	case bytes >= kb:
+		return fmt.Sprintf("%.2f KB", float64(bytes)/kb)
+	}
+	return fmt.Sprintf("%d Bytes", bytes)
+}
@@ -0,0 +1,69 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package execdetails
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testExecDetailsSuite{})
+
+type testExecDetailsSuite struct {
+}
+
+func (s *testExecDetailsSuite) TestRuntimeStatsColl(c *C) {
+	defer testleak.AfterTest(c)()
+	coll := NewRuntimeStatsColl()
+	c.Assert(coll.ExistsRootStats("Sort_1"), IsFalse)
+	stats := coll.GetRootStats("Sort_1")
+	c.Assert(coll.ExistsRootStats("Sort_1"), IsTrue)
+	c.Assert(coll.GetRootStats("Sort_1"), Equals, stats)
+
+	stats.RecordOpen(time.Millisecond)
+	stats.Record(time.Millisecond, 1)
+	stats.Record(time.Millisecond, 1)
+	stats.Record(time.Millisecond, 0)
+	stats.RecordOpen(time.Millisecond)
+	stats.RecordMemory(100)
+	stats.RecordMemory(50)
+	c.Assert(stats.String(), Equals, "time:5ms, loops:2, rows:2")
+	c.Assert(stats.Memory(), Equals, int64(100))
+
+	c.Assert(coll.ExistsCopStats("TableScan_2"), IsFalse)
+	copStats := coll.GetCopStats("TableScan_2")
+	c.Assert(coll.ExistsCopStats("TableScan_2"), IsTrue)
+	c.Assert(copStats.String(), Equals, "cop_task:{num:0}")
+	copStats.RecordOneCopTask(4, "store2", 3*time.Millisecond)
+	copStats.RecordOneCopTask(2, "store1", 2*time.Millisecond)
+	copStats.RecordOneCopTask(2, "store1", 4*time.Millisecond)
+	c.Assert(copStats.Tasks(), Equals, 3)
+	c.Assert(copStats.String(), Equals, "cop_task:{num:3, max:4ms, avg:3ms, regions:[2@store1:6ms/2, 4@store2:3ms]}")
+}
+
+func (s *testExecDetailsSuite) TestFormatBytes(c *C) {
+	defer testleak.AfterTest(c)()
+	c.Assert(FormatBytes(100), Equals, "100 Bytes")
+	c.Assert(FormatBytes(2048), Equals, "2.00 KB")
+	c.Assert(FormatBytes(3*1024*1024+512*1024), Equals, "3.50 MB")
+	c.Assert(FormatBytes(2*1024*1024*1024), Equals, "2.00 GB")
+}
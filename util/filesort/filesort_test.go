@@ -203,6 +203,54 @@ func (s *testFileSortSuite) TestMultipleFiles(c *C) {
 	}
 }
 
+func (s *testFileSortSuite) TestVariableLengthRows(c *C) {
+	defer testleak.AfterTest(c)()
+
+	seed := rand.NewSource(time.Now().UnixNano())
+	r := rand.New(seed)
+
+	sc := new(variable.StatementContext)
+	bufSize := 40 // hold up to 40 items per file
+	byDesc := []bool{false}
+
+	tmpDir, err := ioutil.TempDir("", "util_filesort_test")
+	c.Assert(err, IsNil)
+
+	fsBuilder := new(Builder)
+	fs, err := fsBuilder.SetSC(sc).SetSchema(1, 1).SetBuf(bufSize).SetWorkers(1).SetDesc(byDesc).SetDir(tmpDir).Build()
+	c.Assert(err, IsNil)
+	defer fs.Close()
+
+	nRows := bufSize * 5
+	for i := 0; i < nRows; i++ {
+		str := make([]byte, r.Intn(100))
+		for j := range str {
+			str[j] = byte('a' + r.Intn(26))
+		}
+		key := []types.Datum{types.NewBytesDatum(str)}
+		val := []types.Datum{types.NewBytesDatum(str)}
+		err = fs.Input(key, val, int64(i))
+		c.Assert(err, IsNil)
+	}
+
+	var pkey []types.Datum
+	for i := 0; i < nRows; i++ {
+		key, val, _, err := fs.Output()
+		c.Assert(err, IsNil)
+		c.Assert(key, HasLen, 1)
+		c.Assert(val[0].GetBytes(), DeepEquals, key[0].GetBytes())
+		if pkey != nil {
+			ret, err := lessThan(sc, key, pkey, byDesc)
+			c.Assert(err, IsNil)
+			c.Assert(ret, IsFalse)
+		}
+		pkey = key
+	}
+	key, _, _, err := fs.Output()
+	c.Assert(err, IsNil)
+	c.Assert(key, IsNil)
+}
+
 func (s *testFileSortSuite) TestMultipleWorkers(c *C) {
 	defer testleak.AfterTest(c)()
 
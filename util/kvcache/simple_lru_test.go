@@ -0,0 +1,118 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kvcache
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testLRUCacheSuite{})
+
+type testLRUCacheSuite struct {
+}
+
+type mockCacheKey struct {
+	hash []byte
+	key  int64
+}
+
+func (mk *mockCacheKey) Hash() []byte {
+	if mk.hash != nil {
+		return mk.hash
+	}
+	mk.hash = make([]byte, 8)
+	for i := uint(0); i < 8; i++ {
+		mk.hash[i] = byte((mk.key >> (i * 8)) & 0xff)
+	}
+	return mk.hash
+}
+
+func newMockHashKey(key int64) *mockCacheKey {
+	return &mockCacheKey{
+		key: key,
+	}
+}
+
+func (s *testLRUCacheSuite) TestPutAndGet(c *C) {
+	defer testleak.AfterTest(c)()
+	lru := NewSimpleLRUCache(3)
+
+	keys := make([]*mockCacheKey, 5)
+	for i := 0; i < 5; i++ {
+		keys[i] = newMockHashKey(int64(i))
+		lru.Put(keys[i], i)
+	}
+	c.Assert(lru.Size(), Equals, 3)
+
+	// The first two keys are evicted.
+	for i := 0; i < 2; i++ {
+		_, ok := lru.Get(keys[i])
+		c.Assert(ok, IsFalse)
+	}
+	for i := 2; i < 5; i++ {
+		v, ok := lru.Get(keys[i])
+		c.Assert(ok, IsTrue)
+		c.Assert(v, Equals, i)
+	}
+
+	// Key 2 is the least recently used one now, touch it so that key 3 is evicted.
+	_, ok := lru.Get(keys[2])
+	c.Assert(ok, IsTrue)
+	lru.Put(newMockHashKey(5), 5)
+	_, ok = lru.Get(keys[3])
+	c.Assert(ok, IsFalse)
+	_, ok = lru.Get(keys[2])
+	c.Assert(ok, IsTrue)
+
+	// Putting an existing key updates its value without eviction.
+	lru.Put(newMockHashKey(4), 40)
+	c.Assert(lru.Size(), Equals, 3)
+	v, ok := lru.Get(keys[4])
+	c.Assert(ok, IsTrue)
+	c.Assert(v, Equals, 40)
+}
+
+func (s *testLRUCacheSuite) TestDelete(c *C) {
+	defer testleak.AfterTest(c)()
+	lru := NewSimpleLRUCache(3)
+
+	keys := make([]*mockCacheKey, 3)
+	for i := 0; i < 3; i++ {
+		keys[i] = newMockHashKey(int64(i))
+		lru.Put(keys[i], i)
+	}
+	c.Assert(lru.Size(), Equals, 3)
+
+	lru.Delete(keys[1])
+	_, ok := lru.Get(keys[1])
+	c.Assert(ok, IsFalse)
+	c.Assert(lru.Size(), Equals, 2)
+
+	// Deleting a missing key is a no-op.
+	lru.Delete(keys[1])
+	c.Assert(lru.Size(), Equals, 2)
+
+	_, ok = lru.Get(keys[0])
+	c.Assert(ok, IsTrue)
+	_, ok = lru.Get(keys[2])
+	c.Assert(ok, IsTrue)
+}
@@ -39,6 +39,9 @@ func TestMVMap(t *testing.T) {
 	if m.Len() != 4 {
 		t.FailNow()
 	}
+	if m.MemoryUsage() <= 0 {
+		t.FailNow()
+	}
 
 	results := []string{"abc abc1", "abc abc2", "def def1", "def def2"}
 	it := m.NewIterator()
@@ -311,6 +311,73 @@ func (s *testRangerSuite) TestIndexRange(c *C) {
 	}
 }
 
+func (s *testRangerSuite) TestPrefixIndexRange(c *C) {
+	defer testleak.AfterTest(c)()
+	store, err := newStoreWithBootstrap()
+	defer store.Close()
+	c.Assert(err, IsNil)
+	testKit := testkit.NewTestKit(c, store)
+	testKit.MustExec("use test")
+	testKit.MustExec("drop table if exists t")
+	testKit.MustExec("create table t(a varchar(50), index idx_a(a(3)))")
+
+	// The values are cut to the prefix length, so the ranges must include their ends.
+	tests := []struct {
+		exprStr   string
+		resultStr string
+	}{
+		{
+			exprStr:   "a < 'abcdef'",
+			resultStr: "[[-inf,[97 98 99]]]",
+		},
+		{
+			exprStr:   "a > 'abcdef'",
+			resultStr: "[[[97 98 99],+inf]]",
+		},
+		{
+			exprStr:   "a >= 'ab' and a < 'abcd'",
+			resultStr: "[[ab,[97 98 99]]]",
+		},
+		{
+			exprStr:   "a = 'abcdef'",
+			resultStr: "[[[97 98 99],[97 98 99]]]",
+		},
+	}
+
+	for _, tt := range tests {
+		sql := "select * from t where " + tt.exprStr
+		ctx := testKit.Se.(context.Context)
+		stmts, err := tidb.Parse(ctx, sql)
+		c.Assert(err, IsNil, Commentf("error %v, for expr %s", err, tt.exprStr))
+		c.Assert(stmts, HasLen, 1)
+		is := sessionctx.GetDomain(ctx).InfoSchema()
+		err = plan.ResolveName(stmts[0], is, ctx)
+		c.Assert(err, IsNil, Commentf("error %v, for resolve name, expr %s", err, tt.exprStr))
+		p, err := plan.BuildLogicalPlan(ctx, stmts[0], is)
+		c.Assert(err, IsNil, Commentf("error %v, for build plan, expr %s", err, tt.exprStr))
+		var selection *plan.Selection
+		for _, child := range p.Children() {
+			p, ok := child.(*plan.Selection)
+			if ok {
+				selection = p
+				break
+			}
+		}
+		c.Assert(selection, NotNil, Commentf("expr:%v", tt.exprStr))
+		tbl := selection.Children()[0].(*plan.DataSource).TableInfo()
+		conds := make([]expression.Expression, 0, len(selection.Conditions))
+		for _, cond := range selection.Conditions {
+			conds = append(conds, expression.PushDownNot(cond, false, ctx))
+		}
+		cols, lengths := expression.IndexInfo2Cols(selection.Schema().Columns, tbl.Indices[0])
+		c.Assert(cols, NotNil)
+		result, _, _, err := ranger.BuildRange(new(variable.StatementContext), conds, ranger.IndexRangeType, cols, lengths)
+		c.Assert(err, IsNil)
+		got := fmt.Sprintf("%v", result)
+		c.Assert(got, Equals, tt.resultStr, Commentf("different for expr %s", tt.exprStr))
+	}
+}
+
 func (s *testRangerSuite) TestColumnRange(c *C) {
 	defer testleak.AfterTest(c)()
 	store, err := newStoreWithBootstrap()
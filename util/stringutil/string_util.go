@@ -16,6 +16,7 @@ package stringutil
 import (
 	"bytes"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/juju/errors"
@@ -245,3 +246,45 @@ func RemoveBlanks(s string) string {
 	}
 	return buf.String()
 }
+
+// RemoveExprBlanks removes the blanks of an expression text, returns a new string.
+// Unlike RemoveBlanks, the blanks in the quoted strings are kept, and a blank is kept
+// between two words or quoted strings, e.g. "cast(a  as signed) + 1" is returned as "cast(a as signed)+1".
+func RemoveExprBlanks(s string) string {
+	var buf = new(bytes.Buffer)
+	var quote rune
+	var escaped, blank bool
+	var last rune
+	for _, c := range s {
+		if quote != 0 {
+			buf.WriteRune(c)
+			if escaped {
+				escaped = false
+			} else if c == '\\' && quote != '`' {
+				escaped = true
+			} else if c == quote {
+				quote = 0
+			}
+			last = c
+			continue
+		}
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+			blank = true
+			continue
+		}
+		if blank && isWordOrQuoteRune(last) && isWordOrQuoteRune(c) {
+			buf.WriteByte(' ')
+		}
+		blank = false
+		if c == '\'' || c == '"' || c == '`' {
+			quote = c
+		}
+		buf.WriteRune(c)
+		last = c
+	}
+	return buf.String()
+}
+
+func isWordOrQuoteRune(c rune) bool {
+	return c == '_' || c == '$' || c == '\'' || c == '"' || c == '`' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
@@ -134,3 +134,20 @@ func (s *testStringUtilSuite) TestRemoveBlanks(c *C) {
 		c.Assert(RemoveBlanks(tt.input), Equals, tt.output)
 	}
 }
+
+func (s *testStringUtilSuite) TestRemoveExprBlanks(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		input  string
+		output string
+	}{
+		{"a + 1", "a+1"},
+		{"cast(a ->'$.a'  as\tsigned)", "cast(a->'$.a' as signed)"},
+		{"concat(a, ' b  c', \"d 'e\")", "concat(a,' b  c',\"d 'e\")"},
+		{"concat('a\\' b', c)", "concat('a\\' b',c)"},
+		{"lower(`a b`)\n", "lower(`a b`)"},
+	}
+	for _, tt := range tests {
+		c.Assert(RemoveExprBlanks(tt.input), Equals, tt.output)
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/hack"
+	"github.com/pingcap/tidb/util/types/geo"
 	"github.com/pingcap/tidb/util/types/json"
 )
 
@@ -711,6 +712,8 @@ func (d *Datum) ConvertTo(sc *variable.StatementContext, target *FieldType) (Dat
 		return d.convertToMysqlSet(sc, target)
 	case mysql.TypeJSON:
 		return d.convertToMysqlJSON(sc, target)
+	case mysql.TypeGeometry:
+		return d.convertToMysqlGeometry(sc, target)
 	case mysql.TypeNull:
 		return Datum{}, nil
 	default:
@@ -1283,6 +1286,21 @@ func (d *Datum) convertToMysqlJSON(sc *variable.StatementContext, target *FieldT
 	return ret, errors.Trace(err)
 }
 
+// convertToMysqlGeometry checks whether the datum is a geometry value in the internal binary format,
+// which can be stored into the target column. The result is the binary string itself.
+func (d *Datum) convertToMysqlGeometry(sc *variable.StatementContext, target *FieldType) (Datum, error) {
+	var ret Datum
+	switch d.k {
+	case KindString, KindBytes:
+		g, err := geo.Deserialize(d.GetBytes())
+		if err == nil && target.GeomType.Accepts(g.Type) {
+			ret.SetBytes(d.GetBytes())
+			return ret, nil
+		}
+	}
+	return ret, errors.Trace(geo.ErrCantCreateGeometryObject)
+}
+
 // ToBool converts to a bool.
 // We will use 1 for true, and 0 for false.
 func (d *Datum) ToBool(sc *variable.StatementContext) (int64, error) {
@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/format"
+	"github.com/pingcap/tidb/util/types/geo"
 	"github.com/pingcap/tidb/util/types/json"
 )
 
@@ -51,6 +52,64 @@ type FieldType struct {
 	Collate string
 	// Elems is the element list for enum and set type.
 	Elems []string
+	// GeomType is the geometry type of a spatial column, a GEOMETRY column can store values of all types.
+	GeomType geo.Type
+	// Derivation is the collation derivation of the expression which has this type,
+	// it isn't stored with the column info.
+	Derivation Derivation `json:"-"`
+}
+
+// Derivation is the collation derivation of an expression, it decides which collation is used
+// when the strings with different collations are compared or concatenated.
+// See https://dev.mysql.com/doc/refman/5.7/en/charset-collation-coercibility.html
+type Derivation byte
+
+// The derivations from the lowest coercibility to the highest one, the derivation with lower
+// coercibility takes precedence.
+const (
+	// DerivationUnspecified means the derivation is implied by the type, see FieldType.GetDerivation.
+	DerivationUnspecified Derivation = iota
+	DerivationExplicit
+	DerivationNone
+	DerivationImplicit
+	DerivationSysconst
+	DerivationCoercible
+	DerivationNumeric
+	DerivationIgnorable
+)
+
+// Coercibility returns the coercibility value of the derivation, which is the result of COERCIBILITY().
+func (d Derivation) Coercibility() int64 {
+	return int64(d - DerivationExplicit)
+}
+
+// GetDerivation returns the collation derivation of the field type. If it's unspecified, the
+// derivation is implied by the type like the one of a column: NULL is ignorable, strings are
+// implicit, and numbers and temporal values are numeric.
+func (ft *FieldType) GetDerivation() Derivation {
+	if ft.Derivation != DerivationUnspecified {
+		return ft.Derivation
+	}
+	switch {
+	case ft.Tp == mysql.TypeNull:
+		return DerivationIgnorable
+	case IsTypeChar(ft.Tp) || IsTypeVarchar(ft.Tp) || IsTypeBlob(ft.Tp) || IsTypeJSON(ft.Tp),
+		ft.Tp == mysql.TypeEnum, ft.Tp == mysql.TypeSet, ft.Tp == mysql.TypeGeometry:
+		return DerivationImplicit
+	}
+	return DerivationNumeric
+}
+
+// AggDerivation aggregates the collation derivations of the arguments of a function which returns
+// a string, the result is the one with the lowest coercibility, but at most coercible.
+func AggDerivation(tps []*FieldType) Derivation {
+	d := DerivationCoercible
+	for _, tp := range tps {
+		if argD := tp.GetDerivation(); argD < d {
+			d = argD
+		}
+	}
+	return d
 }
 
 // NewFieldType returns a FieldType,
@@ -234,6 +293,9 @@ func (ft *FieldType) CompactStr() string {
 	case mysql.TypeBit, mysql.TypeShort, mysql.TypeTiny, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeString, mysql.TypeVarString:
 		// Flen is always shown.
 		suffix = fmt.Sprintf("(%d)", displayFlen)
+	case mysql.TypeGeometry:
+		// The column type is the geometry type like point.
+		ts = strings.ToLower(ft.GeomType.String())
 	}
 	return ts + suffix
 }
@@ -0,0 +1,296 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import (
+	"math"
+	"sort"
+)
+
+// The spatial relations are computed in the Cartesian plane like MySQL 5.7 does.
+
+// epsilon is the relative tolerance used to check whether a point is on a segment.
+const epsilon = 1e-9
+
+type segment struct {
+	a Point
+	b Point
+}
+
+// segments returns the segments of g, a Point is regarded as a segment of zero length.
+func (g *Geometry) segments() []segment {
+	switch g.Type {
+	case TypePoint:
+		return []segment{{g.Points[0], g.Points[0]}}
+	case TypeLineString:
+		return lineSegments(nil, g.Points)
+	}
+	var segs []segment
+	for _, ring := range g.Rings {
+		segs = lineSegments(segs, ring)
+	}
+	return segs
+}
+
+func lineSegments(segs []segment, pts []Point) []segment {
+	for i := 1; i < len(pts); i++ {
+		segs = append(segs, segment{pts[i-1], pts[i]})
+	}
+	return segs
+}
+
+// cross returns the cross product of (a - o) and (b - o), its sign tells on which side of the
+// line o->a the point b lies.
+func cross(o, a, b Point) float64 {
+	return (a.X-o.X)*(b.Y-o.Y) - (a.Y-o.Y)*(b.X-o.X)
+}
+
+func dist(a, b Point) float64 {
+	return math.Hypot(a.X-b.X, a.Y-b.Y)
+}
+
+// onSegment checks whether p lies on s.
+func onSegment(p Point, s segment) bool {
+	if p.X < math.Min(s.a.X, s.b.X) || p.X > math.Max(s.a.X, s.b.X) ||
+		p.Y < math.Min(s.a.Y, s.b.Y) || p.Y > math.Max(s.a.Y, s.b.Y) {
+		return false
+	}
+	l := dist(s.a, s.b)
+	if l == 0 {
+		return p == s.a
+	}
+	scale := 1 + math.Max(math.Max(math.Abs(p.X), math.Abs(p.Y)), math.Max(math.Abs(s.a.X), math.Abs(s.a.Y)))
+	return math.Abs(cross(s.a, s.b, p))/l <= epsilon*scale
+}
+
+// segmentsIntersect checks whether s1 and s2 have any point in common.
+func segmentsIntersect(s1, s2 segment) bool {
+	d1, d2 := cross(s2.a, s2.b, s1.a), cross(s2.a, s2.b, s1.b)
+	d3, d4 := cross(s1.a, s1.b, s2.a), cross(s1.a, s1.b, s2.b)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	return onSegment(s1.a, s2) || onSegment(s1.b, s2) || onSegment(s2.a, s1) || onSegment(s2.b, s1)
+}
+
+func pointSegmentDistance(p Point, s segment) float64 {
+	dx, dy := s.b.X-s.a.X, s.b.Y-s.a.Y
+	l2 := dx*dx + dy*dy
+	if l2 == 0 {
+		return dist(p, s.a)
+	}
+	t := ((p.X-s.a.X)*dx + (p.Y-s.a.Y)*dy) / l2
+	t = math.Max(0, math.Min(1, t))
+	return dist(p, Point{s.a.X + t*dx, s.a.Y + t*dy})
+}
+
+func segmentDistance(s1, s2 segment) float64 {
+	if segmentsIntersect(s1, s2) {
+		return 0
+	}
+	return math.Min(
+		math.Min(pointSegmentDistance(s1.a, s2), pointSegmentDistance(s1.b, s2)),
+		math.Min(pointSegmentDistance(s2.a, s1), pointSegmentDistance(s2.b, s1)))
+}
+
+// location is the location of a point relative to a geometry value.
+type location byte
+
+const (
+	exterior location = iota
+	boundary
+	interior
+)
+
+func locateInRing(p Point, ring []Point) location {
+	inside := false
+	for i := 1; i < len(ring); i++ {
+		a, b := ring[i-1], ring[i]
+		if onSegment(p, segment{a, b}) {
+			return boundary
+		}
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	if inside {
+		return interior
+	}
+	return exterior
+}
+
+// locate returns the location of p relative to g.
+func (g *Geometry) locate(p Point) location {
+	switch g.Type {
+	case TypePoint:
+		if p == g.Points[0] {
+			return interior
+		}
+		return exterior
+	case TypeLineString:
+		first, last := g.Points[0], g.Points[len(g.Points)-1]
+		for _, s := range g.segments() {
+			if onSegment(p, s) {
+				// The end points of a line string which isn't closed are its boundary.
+				if first != last && (p == first || p == last) {
+					return boundary
+				}
+				return interior
+			}
+		}
+		return exterior
+	}
+	loc := locateInRing(p, g.Rings[0])
+	if loc != interior {
+		return loc
+	}
+	for _, hole := range g.Rings[1:] {
+		switch locateInRing(p, hole) {
+		case boundary:
+			return boundary
+		case interior:
+			return exterior
+		}
+	}
+	return interior
+}
+
+// Distance returns the minimum Cartesian distance between g1 and g2.
+// See https://dev.mysql.com/doc/refman/5.7/en/spatial-relation-functions-object-shapes.html#function_st-distance
+func Distance(g1, g2 *Geometry) float64 {
+	if g1.Type == TypePolygon && g2.Type != TypePolygon {
+		g1, g2 = g2, g1
+	}
+	// A point of g1 may be in the polygon g2, then the distance is 0.
+	if g2.Type == TypePolygon {
+		for _, p := range g1.points() {
+			if g2.locate(p) != exterior {
+				return 0
+			}
+		}
+		if g1.Type == TypePolygon {
+			for _, p := range g2.points() {
+				if g1.locate(p) != exterior {
+					return 0
+				}
+			}
+		}
+	}
+	d := math.Inf(1)
+	segs2 := g2.segments()
+	for _, s1 := range g1.segments() {
+		for _, s2 := range segs2 {
+			d = math.Min(d, segmentDistance(s1, s2))
+		}
+	}
+	return d
+}
+
+// Contains checks whether g1 completely contains g2, which means no points of g2 lie in the exterior
+// of g1, and at least one point of the interior of g2 lies in the interior of g1.
+// See https://dev.mysql.com/doc/refman/5.7/en/spatial-relation-functions-object-shapes.html#function_st-contains
+func Contains(g1, g2 *Geometry) bool {
+	if g2.Type == TypePolygon && g1.Type != TypePolygon {
+		return false
+	}
+	// Filter out most of the geometries by the MBRs first.
+	if !g1.Envelope().Contains(g2.Envelope()) {
+		return false
+	}
+	hasInterior := false
+	for _, p := range samplePoints(g2, g1) {
+		switch g1.locate(p) {
+		case exterior:
+			return false
+		case interior:
+			hasInterior = true
+		}
+	}
+	if g2.Type != TypePolygon {
+		return hasInterior
+	}
+	// The boundary of g2 is in g1, but a hole of g1 may still be in the interior of g2.
+	for _, hole := range g1.Rings[1:] {
+		h := &Geometry{Type: TypeLineString, Points: hole}
+		for _, p := range samplePoints(h, g2) {
+			if g2.locate(p) == interior {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Within checks whether g1 is completely within g2, it's the inverse of Contains.
+// See https://dev.mysql.com/doc/refman/5.7/en/spatial-relation-functions-object-shapes.html#function_st-within
+func Within(g1, g2 *Geometry) bool {
+	return Contains(g2, g1)
+}
+
+// samplePoints returns the points which can tell the location of g relative to other. The segments
+// of g are split by other, and each piece is sampled at its end points and midpoint, so the
+// location of the whole piece is the same as its midpoint's unless it's on the boundary of other.
+func samplePoints(g, other *Geometry) []Point {
+	if g.Type == TypePoint {
+		return g.Points
+	}
+	otherPts := other.points()
+	otherSegs := other.segments()
+	var samples []Point
+	for _, s := range g.segments() {
+		ts := []float64{0, 1}
+		for _, p := range otherPts {
+			if onSegment(p, s) {
+				ts = append(ts, segmentParam(p, s))
+			}
+		}
+		for _, o := range otherSegs {
+			if t, ok := crossingParam(s, o); ok {
+				ts = append(ts, t)
+			}
+		}
+		sort.Float64s(ts)
+		for i, t := range ts {
+			samples = append(samples, pointAt(s, t))
+			if i > 0 && t > ts[i-1] {
+				samples = append(samples, pointAt(s, (t+ts[i-1])/2))
+			}
+		}
+	}
+	return samples
+}
+
+func pointAt(s segment, t float64) Point {
+	return Point{s.a.X + t*(s.b.X-s.a.X), s.a.Y + t*(s.b.Y-s.a.Y)}
+}
+
+// segmentParam returns t for the point p on s, where p = s.a + t * (s.b - s.a).
+func segmentParam(p Point, s segment) float64 {
+	dx, dy := s.b.X-s.a.X, s.b.Y-s.a.Y
+	l2 := dx*dx + dy*dy
+	if l2 == 0 {
+		return 0
+	}
+	return math.Max(0, math.Min(1, ((p.X-s.a.X)*dx+(p.Y-s.a.Y)*dy)/l2))
+}
+
+// crossingParam returns the parameter on s of the point where s and o cross, the segments which
+// aren't crossing, including the collinear ones, are ignored.
+func crossingParam(s, o segment) (float64, bool) {
+	d1, d2 := cross(o.a, o.b, s.a), cross(o.a, o.b, s.b)
+	if d1 == d2 || !segmentsIntersect(s, o) {
+		return 0, false
+	}
+	return math.Max(0, math.Min(1, d1/(d1-d2))), true
+}
@@ -0,0 +1,146 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package geo implements the spatial data types of MySQL.
+// See https://dev.mysql.com/doc/refman/5.7/en/spatial-types.html
+package geo
+
+import (
+	"math"
+
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+)
+
+// Type is the type of a geometry value, the values are the same as the ones used in WKB.
+type Type byte
+
+// Geometry types. TypeGeometry is the type of a GEOMETRY column, which can store a value of any type.
+const (
+	TypeGeometry   Type = 0
+	TypePoint      Type = 1
+	TypeLineString Type = 2
+	TypePolygon    Type = 3
+)
+
+var typeNames = map[Type]string{
+	TypeGeometry:   "GEOMETRY",
+	TypePoint:      "POINT",
+	TypeLineString: "LINESTRING",
+	TypePolygon:    "POLYGON",
+}
+
+// String implements fmt.Stringer interface.
+func (t Type) String() string {
+	return typeNames[t]
+}
+
+// Accepts checks whether a column of type t can store a value of type vt.
+func (t Type) Accepts(vt Type) bool {
+	return t == TypeGeometry || t == vt
+}
+
+// Point is a point in the plane.
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Geometry is a geometry value.
+type Geometry struct {
+	// SRID is the spatial reference system identifier of the value.
+	SRID uint32
+	Type Type
+	// Points are the points of a Point or a LineString.
+	Points []Point
+	// Rings are the rings of a Polygon, the first one is the exterior ring and the others are holes.
+	// Each ring is closed, which means the first point equals to the last one.
+	Rings [][]Point
+}
+
+// NewPoint creates a Point geometry.
+func NewPoint(x, y float64, srid uint32) *Geometry {
+	return &Geometry{SRID: srid, Type: TypePoint, Points: []Point{{x, y}}}
+}
+
+// points returns all the points of g.
+func (g *Geometry) points() []Point {
+	if g.Type != TypePolygon {
+		return g.Points
+	}
+	var pts []Point
+	for _, ring := range g.Rings {
+		pts = append(pts, ring...)
+	}
+	return pts
+}
+
+// MBR is the minimum bounding rectangle of a geometry value.
+type MBR struct {
+	MinX float64
+	MinY float64
+	MaxX float64
+	MaxY float64
+}
+
+// Envelope returns the minimum bounding rectangle of g.
+func (g *Geometry) Envelope() MBR {
+	m := MBR{MinX: math.Inf(1), MinY: math.Inf(1), MaxX: math.Inf(-1), MaxY: math.Inf(-1)}
+	for _, p := range g.points() {
+		m.MinX = math.Min(m.MinX, p.X)
+		m.MinY = math.Min(m.MinY, p.Y)
+		m.MaxX = math.Max(m.MaxX, p.X)
+		m.MaxY = math.Max(m.MaxY, p.Y)
+	}
+	return m
+}
+
+// Contains checks whether m contains o, the boundaries are included.
+func (m MBR) Contains(o MBR) bool {
+	return m.MinX <= o.MinX && m.MinY <= o.MinY && o.MaxX <= m.MaxX && o.MaxY <= m.MaxY
+}
+
+// Intersects checks whether m and o have any point in common.
+func (m MBR) Intersects(o MBR) bool {
+	return m.MinX <= o.MaxX && o.MinX <= m.MaxX && m.MinY <= o.MaxY && o.MinY <= m.MaxY
+}
+
+// Geometry returns the rectangle as a geometry value, like ST_Envelope does, it's a Point or a
+// LineString if the rectangle is degenerate.
+func (m MBR) Geometry(srid uint32) *Geometry {
+	switch {
+	case m.MinX == m.MaxX && m.MinY == m.MaxY:
+		return NewPoint(m.MinX, m.MinY, srid)
+	case m.MinX == m.MaxX || m.MinY == m.MaxY:
+		return &Geometry{SRID: srid, Type: TypeLineString, Points: []Point{{m.MinX, m.MinY}, {m.MaxX, m.MaxY}}}
+	}
+	ring := []Point{{m.MinX, m.MinY}, {m.MaxX, m.MinY}, {m.MaxX, m.MaxY}, {m.MinX, m.MaxY}, {m.MinX, m.MinY}}
+	return &Geometry{SRID: srid, Type: TypePolygon, Rings: [][]Point{ring}}
+}
+
+var (
+	// ErrCantCreateGeometryObject means the value stored into a geometry column isn't a valid geometry value.
+	ErrCantCreateGeometryObject = terror.ClassGeo.New(mysql.ErrCantCreateGeometryObject, mysql.MySQLErrName[mysql.ErrCantCreateGeometryObject])
+	// ErrInvalidGISData means the argument of a spatial function isn't a valid geometry value.
+	ErrInvalidGISData = terror.ClassGeo.New(mysql.ErrGISInvalidData, mysql.MySQLErrName[mysql.ErrGISInvalidData])
+	// ErrDifferentSRIDs means the arguments of a spatial function have different SRIDs.
+	ErrDifferentSRIDs = terror.ClassGeo.New(mysql.ErrGISDifferentSRIDs, mysql.MySQLErrName[mysql.ErrGISDifferentSRIDs])
+)
+
+func init() {
+	terror.ErrClassToMySQLCodes[terror.ClassGeo] = map[terror.ErrCode]uint16{
+		mysql.ErrCantCreateGeometryObject: mysql.ErrCantCreateGeometryObject,
+		mysql.ErrGISInvalidData:           mysql.ErrGISInvalidData,
+		mysql.ErrGISDifferentSRIDs:        mysql.ErrGISDifferentSRIDs,
+	}
+}
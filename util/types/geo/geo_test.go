@@ -0,0 +1,202 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import (
+	"encoding/hex"
+	"math"
+	"testing"
+
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testGeoSuite{})
+
+type testGeoSuite struct{}
+
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
+func mustParseWKT(s string) *Geometry {
+	g, err := ParseWKT(s, 0)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+func (s *testGeoSuite) TestWKT(c *C) {
+	tbl := []struct {
+		input  string
+		output string
+	}{
+		{"POINT(1 2)", "POINT(1 2)"},
+		{" point ( -1.5  2e3 ) ", "POINT(-1.5 2000)"},
+		{"POINT(1e21 0.25)", "POINT(1e21 0.25)"},
+		{"LineString(0 0, 1 1,2 0)", "LINESTRING(0 0,1 1,2 0)"},
+		{"POLYGON((0 0,10 0,10 10,0 10,0 0),(2 2,2 4,4 4,2 2))", "POLYGON((0 0,10 0,10 10,0 10,0 0),(2 2,2 4,4 4,2 2))"},
+	}
+	for _, t := range tbl {
+		g, err := ParseWKT(t.input, 0)
+		c.Assert(err, IsNil, Commentf("%s", t.input))
+		c.Assert(g.WKT(), Equals, t.output)
+	}
+
+	for _, input := range []string{
+		"",
+		"POINT",
+		"POINT()",
+		"POINT(1)",
+		"POINT(1 2, 3 4)",
+		"POINT(1 2) x",
+		"POINT(a b)",
+		"LINESTRING(0 0)",
+		"POLYGON((0 0,1 0,1 1,0 0)",
+		"POLYGON((0 0,1 0,1 1,0 1))",
+		"POLYGON((0 0,1 0,0 0))",
+		"MULTIPOINT(0 0,1 1)",
+	} {
+		_, err := ParseWKT(input, 0)
+		c.Assert(err, NotNil, Commentf("%s", input))
+	}
+}
+
+func (s *testGeoSuite) TestSerdes(c *C) {
+	g := NewPoint(1, 1, 0)
+	c.Assert(hex.EncodeToString(g.WKB()), Equals, "0101000000000000000000f03f000000000000f03f")
+	c.Assert(hex.EncodeToString(Serialize(g)), Equals, "000000000101000000000000000000f03f000000000000f03f")
+
+	for _, wkt := range []string{
+		"POINT(-1 2.5)",
+		"LINESTRING(0 0,1 1,2 0)",
+		"POLYGON((0 0,10 0,10 10,0 10,0 0),(2 2,2 4,4 4,2 2))",
+	} {
+		g, err := ParseWKT(wkt, 4326)
+		c.Assert(err, IsNil)
+		data := Serialize(g)
+		c.Assert(data, HasLen, 4+len(g.WKB()))
+		g1, err := Deserialize(data)
+		c.Assert(err, IsNil)
+		c.Assert(g1, DeepEquals, g)
+		// Truncated data is invalid.
+		_, err = Deserialize(data[:len(data)-1])
+		c.Assert(err, NotNil)
+		_, err = Deserialize(append(data, 0))
+		c.Assert(err, NotNil)
+	}
+
+	// Big endian WKB.
+	b, err := hex.DecodeString("00000000013ff00000000000004000000000000000")
+	c.Assert(err, IsNil)
+	g, err = ParseWKB(b, 0)
+	c.Assert(err, IsNil)
+	c.Assert(g.WKT(), Equals, "POINT(1 2)")
+
+	for _, input := range []string{
+		"",
+		"02",
+		"0101000000000000000000f03f",
+		"0104000000000000000000f03f000000000000f03f",
+		// A line string with a huge number of points.
+		"0102000000ffffffff",
+	} {
+		b, err := hex.DecodeString(input)
+		c.Assert(err, IsNil)
+		_, err = ParseWKB(b, 0)
+		c.Assert(err, NotNil, Commentf("%s", input))
+	}
+	_, err = Deserialize([]byte{0, 0})
+	c.Assert(err, NotNil)
+}
+
+func (s *testGeoSuite) TestEnvelope(c *C) {
+	g := mustParseWKT("LINESTRING(1 5,3 2,2 8)")
+	m := g.Envelope()
+	c.Assert(m, Equals, MBR{MinX: 1, MinY: 2, MaxX: 3, MaxY: 8})
+	c.Assert(m.Geometry(0).WKT(), Equals, "POLYGON((1 2,3 2,3 8,1 8,1 2))")
+	c.Assert(mustParseWKT("POINT(1 2)").Envelope().Geometry(0).WKT(), Equals, "POINT(1 2)")
+	c.Assert(mustParseWKT("LINESTRING(1 2,1 5)").Envelope().Geometry(0).WKT(), Equals, "LINESTRING(1 2,1 5)")
+
+	c.Assert(m.Contains(MBR{1, 2, 2, 3}), IsTrue)
+	c.Assert(m.Contains(MBR{0, 2, 2, 3}), IsFalse)
+	c.Assert(m.Intersects(MBR{3, 8, 4, 9}), IsTrue)
+	c.Assert(m.Intersects(MBR{3.5, 8, 4, 9}), IsFalse)
+}
+
+func (s *testGeoSuite) TestDistance(c *C) {
+	tbl := []struct {
+		g1       string
+		g2       string
+		distance float64
+	}{
+		{"POINT(0 0)", "POINT(3 4)", 5},
+		{"POINT(0 0)", "LINESTRING(-1 1,1 1)", 1},
+		{"POINT(3 0)", "LINESTRING(0 0,1 1)", math.Sqrt(5)},
+		{"LINESTRING(0 0,2 2)", "LINESTRING(0 2,2 0)", 0},
+		{"LINESTRING(0 0,1 0)", "LINESTRING(0 2,1 3)", 2},
+		{"POINT(5 5)", "POLYGON((0 0,10 0,10 10,0 10,0 0))", 0},
+		{"POINT(12 10)", "POLYGON((0 0,10 0,10 10,0 10,0 0))", 2},
+		{"POLYGON((0 0,10 0,10 10,0 10,0 0),(2 2,2 8,8 8,8 2,2 2))", "POINT(5 4)", 2},
+		{"POLYGON((0 0,1 0,1 1,0 1,0 0))", "POLYGON((3 0,4 0,4 1,3 1,3 0))", 2},
+		{"POLYGON((0 0,10 0,10 10,0 10,0 0))", "POLYGON((3 3,4 3,4 4,3 4,3 3))", 0},
+	}
+	for _, t := range tbl {
+		g1, g2 := mustParseWKT(t.g1), mustParseWKT(t.g2)
+		c.Assert(Distance(g1, g2), Equals, t.distance, Commentf("%s %s", t.g1, t.g2))
+		c.Assert(Distance(g2, g1), Equals, t.distance, Commentf("%s %s", t.g2, t.g1))
+	}
+}
+
+func (s *testGeoSuite) TestContains(c *C) {
+	square := "POLYGON((0 0,10 0,10 10,0 10,0 0))"
+	squareWithHole := "POLYGON((0 0,10 0,10 10,0 10,0 0),(4 4,6 4,6 6,4 6,4 4))"
+	tbl := []struct {
+		g1       string
+		g2       string
+		contains bool
+	}{
+		{"POINT(1 1)", "POINT(1 1)", true},
+		{"POINT(1 1)", "POINT(1 2)", false},
+		{square, "POINT(5 5)", true},
+		{square, "POINT(10 5)", false},
+		{square, "POINT(11 5)", false},
+		{squareWithHole, "POINT(5 5)", false},
+		{squareWithHole, "POINT(2 2)", true},
+		{"LINESTRING(0 0,10 10)", "POINT(5 5)", true},
+		{"LINESTRING(0 0,10 10)", "POINT(0 0)", false},
+		{"LINESTRING(0 0,10 10)", "LINESTRING(2 2,3 3)", true},
+		{"LINESTRING(0 0,5 5,10 0)", "LINESTRING(4 4,6 4)", false},
+		{square, "LINESTRING(1 1,9 9)", true},
+		{square, "LINESTRING(0 0,10 0)", false},
+		{square, "LINESTRING(0 0,10 10)", true},
+		{square, "LINESTRING(5 5,15 5)", false},
+		{squareWithHole, "LINESTRING(1 5,9 5)", false},
+		{squareWithHole, "LINESTRING(1 1,9 1)", true},
+		{"POLYGON((0 0,10 0,10 10,5 5,0 10,0 0))", "LINESTRING(1 9,9 9)", false},
+		{square, square, true},
+		{square, "POLYGON((1 1,9 1,9 9,1 9,1 1))", true},
+		{square, "POLYGON((1 1,11 1,11 9,1 9,1 1))", false},
+		{squareWithHole, "POLYGON((1 1,9 1,9 9,1 9,1 1))", false},
+		{squareWithHole, "POLYGON((1 1,3 1,3 3,1 3,1 1))", true},
+		{squareWithHole, "POLYGON((1 1,9 1,9 9,1 9,1 1),(3 3,7 3,7 7,3 7,3 3))", true},
+		{"POINT(1 1)", square, false},
+		{"LINESTRING(0 0,10 10)", square, false},
+	}
+	for _, t := range tbl {
+		g1, g2 := mustParseWKT(t.g1), mustParseWKT(t.g2)
+		c.Assert(Contains(g1, g2), Equals, t.contains, Commentf("%s %s", t.g1, t.g2))
+		c.Assert(Within(g2, g1), Equals, t.contains, Commentf("%s %s", t.g2, t.g1))
+	}
+}
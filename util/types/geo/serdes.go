@@ -0,0 +1,231 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/juju/errors"
+)
+
+/*
+   The binary format of a geometry value is the same as the one MySQL uses to store it:
+
+   value ::= srid wkb
+
+   srid  ::= uint32  // little endian
+   wkb   ::= byte_order wkb_type geometry_data
+
+   byte_order    ::= 0x00 // big endian
+                  |  0x01 // little endian
+   wkb_type      ::= uint32 // Type
+   geometry_data ::= point | line_string | polygon
+
+   point       ::= double double
+   line_string ::= uint32 point*
+   polygon     ::= uint32 line_string*
+
+   The WKB written by this package is always little endian.
+   See https://dev.mysql.com/doc/refman/5.7/en/gis-data-formats.html#gis-internal-format
+*/
+
+const (
+	wkbBigEndian    = 0
+	wkbLittleEndian = 1
+	sridLen         = 4
+)
+
+// Serialize encodes g into the internal binary format, which is stored into the geometry columns.
+func Serialize(g *Geometry) []byte {
+	buf := make([]byte, sridLen, sridLen+wkbLen(g))
+	binary.LittleEndian.PutUint32(buf, g.SRID)
+	return appendWKB(buf, g)
+}
+
+// Deserialize decodes a geometry value from the internal binary format.
+func Deserialize(data []byte) (*Geometry, error) {
+	if len(data) < sridLen {
+		return nil, errors.New("the geometry value is too short")
+	}
+	g, err := ParseWKB(data[sridLen:], binary.LittleEndian.Uint32(data))
+	return g, errors.Trace(err)
+}
+
+// WKB returns the Well-Known Binary representation of g.
+func (g *Geometry) WKB() []byte {
+	return appendWKB(make([]byte, 0, wkbLen(g)), g)
+}
+
+func wkbLen(g *Geometry) int {
+	const headerLen, pointLen = 5, 16
+	switch g.Type {
+	case TypePoint:
+		return headerLen + pointLen
+	case TypeLineString:
+		return headerLen + 4 + pointLen*len(g.Points)
+	}
+	l := headerLen + 4
+	for _, ring := range g.Rings {
+		l += 4 + pointLen*len(ring)
+	}
+	return l
+}
+
+func appendWKB(buf []byte, g *Geometry) []byte {
+	buf = append(buf, wkbLittleEndian)
+	buf = appendUint32(buf, uint32(g.Type))
+	switch g.Type {
+	case TypePoint:
+		buf = appendPoint(buf, g.Points[0])
+	case TypeLineString:
+		buf = appendPoints(buf, g.Points)
+	case TypePolygon:
+		buf = appendUint32(buf, uint32(len(g.Rings)))
+		for _, ring := range g.Rings {
+			buf = appendPoints(buf, ring)
+		}
+	}
+	return buf
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	return append(buf, b[:]...)
+}
+
+func appendPoint(buf []byte, pt Point) []byte {
+	var b [16]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(pt.X))
+	binary.LittleEndian.PutUint64(b[8:], math.Float64bits(pt.Y))
+	return append(buf, b[:]...)
+}
+
+func appendPoints(buf []byte, pts []Point) []byte {
+	buf = appendUint32(buf, uint32(len(pts)))
+	for _, pt := range pts {
+		buf = appendPoint(buf, pt)
+	}
+	return buf
+}
+
+// ParseWKB parses the Well-Known Binary representation of a geometry value.
+// See https://dev.mysql.com/doc/refman/5.7/en/gis-data-formats.html#gis-wkb-format
+func ParseWKB(wkb []byte, srid uint32) (*Geometry, error) {
+	r := &wkbReader{data: wkb}
+	switch r.byte() {
+	case wkbLittleEndian:
+		r.order = binary.LittleEndian
+	case wkbBigEndian:
+		r.order = binary.BigEndian
+	default:
+		return nil, errors.New("invalid WKB byte order")
+	}
+	g := &Geometry{SRID: srid, Type: Type(r.uint32())}
+	switch g.Type {
+	case TypePoint:
+		g.Points = []Point{r.point()}
+	case TypeLineString:
+		g.Points = r.points()
+		if r.err == nil && len(g.Points) < 2 {
+			return nil, errors.New("a line string must have at least 2 points")
+		}
+	case TypePolygon:
+		n := r.count(4)
+		for i := 0; i < n && r.err == nil; i++ {
+			ring := r.points()
+			if r.err == nil && (len(ring) < 4 || ring[0] != ring[len(ring)-1]) {
+				return nil, errors.New("invalid polygon ring")
+			}
+			g.Rings = append(g.Rings, ring)
+		}
+		if r.err == nil && n == 0 {
+			return nil, errors.New("a polygon must have at least 1 ring")
+		}
+	default:
+		if r.err == nil {
+			return nil, errors.Errorf("unsupported WKB type %d", g.Type)
+		}
+	}
+	if r.err != nil {
+		return nil, errors.Trace(r.err)
+	}
+	if len(r.data) != r.pos {
+		return nil, errors.New("unexpected data after the geometry")
+	}
+	return g, nil
+}
+
+// wkbReader reads WKB, the first error is kept in err and the later reads return zero values.
+type wkbReader struct {
+	data  []byte
+	pos   int
+	order binary.ByteOrder
+	err   error
+}
+
+func (r *wkbReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data)-r.pos < n {
+		r.err = errors.New("the WKB is too short")
+		return nil
+	}
+	r.pos += n
+	return r.data[r.pos-n : r.pos]
+}
+
+func (r *wkbReader) byte() byte {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *wkbReader) uint32() uint32 {
+	if b := r.next(4); b != nil {
+		return r.order.Uint32(b)
+	}
+	return 0
+}
+
+// count reads the count of elements, the count is checked against the remaining data to avoid
+// allocating too much memory for invalid data.
+func (r *wkbReader) count(elemLen int) int {
+	n := int(r.uint32())
+	if r.err == nil && n > (len(r.data)-r.pos)/elemLen {
+		r.err = errors.New("the WKB is too short")
+		return 0
+	}
+	return n
+}
+
+func (r *wkbReader) point() Point {
+	b := r.next(16)
+	if b == nil {
+		return Point{}
+	}
+	return Point{math.Float64frombits(r.order.Uint64(b)), math.Float64frombits(r.order.Uint64(b[8:]))}
+}
+
+func (r *wkbReader) points() []Point {
+	n := r.count(16)
+	pts := make([]Point, 0, n)
+	for i := 0; i < n; i++ {
+		pts = append(pts, r.point())
+	}
+	return pts
+}
@@ -0,0 +1,205 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// ParseWKT parses the Well-Known Text representation of a geometry value.
+// See https://dev.mysql.com/doc/refman/5.7/en/gis-data-formats.html#gis-wkt-format
+func ParseWKT(wkt string, srid uint32) (*Geometry, error) {
+	p := &wktParser{s: wkt}
+	name := strings.ToUpper(p.word())
+	g := &Geometry{SRID: srid}
+	var err error
+	switch name {
+	case "POINT":
+		g.Type = TypePoint
+		g.Points, err = p.points(1)
+	case "LINESTRING":
+		g.Type = TypeLineString
+		g.Points, err = p.points(2)
+	case "POLYGON":
+		g.Type = TypePolygon
+		g.Rings, err = p.rings()
+	default:
+		return nil, errors.Errorf("unsupported geometry type %q", name)
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if g.Type == TypePoint && len(g.Points) != 1 {
+		return nil, errors.New("a point must have exactly one coordinate")
+	}
+	if p.skipSpaces(); p.pos != len(p.s) {
+		return nil, errors.Errorf("unexpected %q after the geometry", p.s[p.pos:])
+	}
+	return g, nil
+}
+
+type wktParser struct {
+	s   string
+	pos int
+}
+
+func (p *wktParser) skipSpaces() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *wktParser) word() string {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.s) && (p.s[p.pos]|0x20 >= 'a' && p.s[p.pos]|0x20 <= 'z') {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *wktParser) expect(c byte) error {
+	p.skipSpaces()
+	if p.pos >= len(p.s) || p.s[p.pos] != c {
+		return errors.Errorf("expect %q at position %d", c, p.pos)
+	}
+	p.pos++
+	return nil
+}
+
+// tryConsume consumes c if it's the next character.
+func (p *wktParser) tryConsume(c byte) bool {
+	p.skipSpaces()
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *wktParser) number() (float64, error) {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.s) && strings.IndexByte("+-.0123456789eE", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+	f, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+	return f, errors.Trace(err)
+}
+
+// points parses a parenthesized list of at least minCount coordinates.
+func (p *wktParser) points(minCount int) ([]Point, error) {
+	if err := p.expect('('); err != nil {
+		return nil, errors.Trace(err)
+	}
+	var pts []Point
+	for {
+		x, err := p.number()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		y, err := p.number()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		pts = append(pts, Point{x, y})
+		if !p.tryConsume(',') {
+			break
+		}
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(pts) < minCount {
+		return nil, errors.Errorf("expect at least %d points", minCount)
+	}
+	return pts, nil
+}
+
+// rings parses a parenthesized list of polygon rings.
+func (p *wktParser) rings() ([][]Point, error) {
+	if err := p.expect('('); err != nil {
+		return nil, errors.Trace(err)
+	}
+	var rings [][]Point
+	for {
+		ring, err := p.points(4)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if ring[0] != ring[len(ring)-1] {
+			return nil, errors.New("the polygon ring isn't closed")
+		}
+		rings = append(rings, ring)
+		if !p.tryConsume(',') {
+			break
+		}
+	}
+	return rings, errors.Trace(p.expect(')'))
+}
+
+// WKT returns the Well-Known Text representation of g.
+func (g *Geometry) WKT() string {
+	var buf bytes.Buffer
+	buf.WriteString(g.Type.String())
+	switch g.Type {
+	case TypePoint:
+		buf.WriteByte('(')
+		writeWKTPoint(&buf, g.Points[0])
+		buf.WriteByte(')')
+	case TypeLineString:
+		writeWKTPoints(&buf, g.Points)
+	case TypePolygon:
+		buf.WriteByte('(')
+		for i, ring := range g.Rings {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeWKTPoints(&buf, ring)
+		}
+		buf.WriteByte(')')
+	}
+	return buf.String()
+}
+
+// String implements fmt.Stringer interface.
+func (g *Geometry) String() string {
+	return g.WKT()
+}
+
+func writeWKTPoints(buf *bytes.Buffer, pts []Point) {
+	buf.WriteByte('(')
+	for i, pt := range pts {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeWKTPoint(buf, pt)
+	}
+	buf.WriteByte(')')
+}
+
+func writeWKTPoint(buf *bytes.Buffer, pt Point) {
+	buf.WriteString(formatCoordinate(pt.X))
+	buf.WriteByte(' ')
+	buf.WriteString(formatCoordinate(pt.Y))
+}
+
+// formatCoordinate formats the coordinate like MySQL does, for example, 1e+21 is formatted as 1e21.
+func formatCoordinate(f float64) string {
+	return strings.Replace(strconv.FormatFloat(f, 'g', -1, 64), "e+", "e", 1)
+}
@@ -193,6 +193,26 @@ func extract(j JSON, pathExpr PathExpression) (ret []JSON) {
 	return
 }
 
+// deepCopy copies j recursively, so the in-place modifications done by
+// Merge, Modify and Remove won't change the JSON held by the caller.
+func deepCopy(j JSON) JSON {
+	switch j.TypeCode {
+	case TypeCodeObject:
+		object := make(map[string]JSON, len(j.Object))
+		for key, value := range j.Object {
+			object[key] = deepCopy(value)
+		}
+		j.Object = object
+	case TypeCodeArray:
+		array := make([]JSON, 0, len(j.Array))
+		for _, value := range j.Array {
+			array = append(array, deepCopy(value))
+		}
+		j.Array = array
+	}
+	return j
+}
+
 // autoWrapAsArray wraps input JSON into an array if needed.
 func autoWrapAsArray(j JSON, hintLength int) JSON {
 	jnew := CreateJSON(nil)
@@ -208,6 +228,7 @@ func autoWrapAsArray(j JSON, hintLength int) JSON {
 // 3) a scalar value is autowrapped as an array before merge;
 // 4) an adjacent array and object are merged by autowrapping the object as an array.
 func (j JSON) Merge(suffixes []JSON) JSON {
+	j = deepCopy(j)
 	if j.TypeCode != TypeCodeArray && j.TypeCode != TypeCodeObject {
 		j = autoWrapAsArray(j, len(suffixes)+1)
 	}
@@ -269,10 +290,10 @@ func (j JSON) Modify(pathExprList []PathExpression, values []JSON, mt ModifyType
 	}
 	for _, pathExpr := range pathExprList {
 		if pathExpr.flags.containsAnyAsterisk() {
-			// TODO: should return 3149(42000)
-			return retj, errors.New("Invalid path expression")
+			return retj, ErrInvalidJSONPathWildcard
 		}
 	}
+	j = deepCopy(j)
 	for i := 0; i < len(pathExprList); i++ {
 		pathExpr, value := pathExprList[i], values[i]
 		j = set(j, pathExpr, value, mt)
@@ -333,9 +354,11 @@ func (j JSON) Remove(pathExprList []PathExpression) (JSON, error) {
 			return j, errors.New("Invalid path expression")
 		}
 		if pathExpr.flags.containsAnyAsterisk() {
-			// TODO: should return 3149(42000)
-			return j, errors.New("Invalid path expression")
+			return j, ErrInvalidJSONPathWildcard
 		}
+	}
+	j = deepCopy(j)
+	for _, pathExpr := range pathExprList {
 		j = remove(j, pathExpr)
 	}
 	return j, nil
@@ -365,3 +388,248 @@ func remove(j JSON, pathExpr PathExpression) JSON {
 	}
 	return j
 }
+
+// ArrayAppend appends values to the end of the arrays indicated by pathExprList.
+// A scalar or object value at the path is autowrapped as an array before appending.
+// All path expressions cannot contain * or ** wildcard.
+// If any error occurs, the input won't be changed.
+func (j JSON) ArrayAppend(pathExprList []PathExpression, values []JSON) (JSON, error) {
+	if len(pathExprList) != len(values) {
+		// TODO: should return 1582(42000)
+		return j, errors.New("Incorrect parameter count")
+	}
+	for _, pathExpr := range pathExprList {
+		if pathExpr.flags.containsAnyAsterisk() {
+			return j, ErrInvalidJSONPathWildcard
+		}
+	}
+	for i, pathExpr := range pathExprList {
+		elem, found := j.Extract([]PathExpression{pathExpr})
+		if !found {
+			continue
+		}
+		if elem.TypeCode == TypeCodeArray {
+			// Limit the capacity so that append won't change the array held by j.
+			elem.Array = append(elem.Array[:len(elem.Array):len(elem.Array)], values[i])
+		} else {
+			elem = autoWrapAsArray(elem, 2)
+			elem.Array = append(elem.Array, values[i])
+		}
+		var err error
+		if j, err = j.Modify([]PathExpression{pathExpr}, []JSON{elem}, ModifyReplace); err != nil {
+			return j, errors.Trace(err)
+		}
+	}
+	return j, nil
+}
+
+// ContainsJSON checks whether target is contained in obj according the following rules:
+// 1) a scalar is contained in another scalar if and only if they are comparable and equal;
+// 2) an array is contained in another array if and only if every element of it is contained in the other;
+// 3) a non-array is contained in an array if and only if it's contained in some element of the array;
+// 4) an object is contained in another object if and only if each key of it is in the other, and
+// the value of the key is contained in the value of the same key in the other.
+func ContainsJSON(obj, target JSON) bool {
+	switch obj.TypeCode {
+	case TypeCodeObject:
+		if target.TypeCode != TypeCodeObject {
+			return false
+		}
+		for key, value := range target.Object {
+			child, ok := obj.Object[key]
+			if !ok || !ContainsJSON(child, value) {
+				return false
+			}
+		}
+		return true
+	case TypeCodeArray:
+		if target.TypeCode == TypeCodeArray {
+			for _, value := range target.Array {
+				if !containedInArray(obj.Array, value) {
+					return false
+				}
+			}
+			return true
+		}
+		return containedInArray(obj.Array, target)
+	}
+	if target.TypeCode == TypeCodeObject || target.TypeCode == TypeCodeArray {
+		return false
+	}
+	// Boolean values are compared with integers in CompareJSON, but they are never equal in JSON.
+	if (obj.TypeCode == TypeCodeLiteral) != (target.TypeCode == TypeCodeLiteral) {
+		return false
+	}
+	cmp, err := CompareJSON(obj, target)
+	return err == nil && cmp == 0
+}
+
+func containedInArray(array []JSON, target JSON) bool {
+	for _, elem := range array {
+		if ContainsJSON(elem, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Keys returns the top-level keys of an object as a JSON array, the keys are sorted.
+// It returns false if j isn't an object.
+func (j JSON) Keys() (JSON, bool) {
+	if j.TypeCode != TypeCodeObject {
+		return JSON{}, false
+	}
+	keys := getSortedKeys(j.Object)
+	ret := JSON{TypeCode: TypeCodeArray, Array: make([]JSON, 0, len(keys))}
+	for _, key := range keys {
+		ret.Array = append(ret.Array, JSON{TypeCode: TypeCodeString, Str: key})
+	}
+	return ret, true
+}
+
+// Length returns the number of elements of an array, the number of members of an object,
+// or 1 for a scalar.
+func (j JSON) Length() int {
+	switch j.TypeCode {
+	case TypeCodeObject:
+		return len(j.Object)
+	case TypeCodeArray:
+		return len(j.Array)
+	}
+	return 1
+}
+
+// Depth returns the maximum depth of j. Scalars and empty arrays or objects have depth 1.
+func (j JSON) Depth() int {
+	depth := 0
+	switch j.TypeCode {
+	case TypeCodeObject:
+		for _, child := range j.Object {
+			if d := child.Depth(); d > depth {
+				depth = d
+			}
+		}
+	case TypeCodeArray:
+		for _, child := range j.Array {
+			if d := child.Depth(); d > depth {
+				depth = d
+			}
+		}
+	}
+	return depth + 1
+}
+
+// Search returns the paths to the strings in j which satisfy match. Only the values
+// indicated by pathExprList are searched, or the whole j if pathExprList is empty.
+// If one is true, it returns after the first matched string is found.
+func (j JSON) Search(pathExprList []PathExpression, match func(s string) bool, one bool) []PathExpression {
+	if len(pathExprList) == 0 {
+		pathExprList = []PathExpression{{}}
+	}
+	var (
+		ret  []PathExpression
+		seen = make(map[string]struct{})
+	)
+	// The wildcards may lead to the same value more than once, so the found paths are deduplicated.
+	visit := func(path PathExpression, value JSON) bool {
+		if value.TypeCode == TypeCodeString && match(value.Str) {
+			key := path.String()
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				ret = append(ret, path)
+			}
+			return one
+		}
+		return false
+	}
+	for _, pathExpr := range pathExprList {
+		stop := walkPath(j, PathExpression{}, pathExpr, func(path PathExpression, value JSON) bool {
+			return walk(value, path, visit)
+		})
+		if stop {
+			break
+		}
+	}
+	return ret
+}
+
+// walkFunc is called for each value visited, the walking stops if it returns true.
+type walkFunc func(path PathExpression, value JSON) (stop bool)
+
+// walk visits j and all its descendants in order, fullPath is the path to j.
+func walk(j JSON, fullPath PathExpression, fn walkFunc) bool {
+	if fn(fullPath, j) {
+		return true
+	}
+	switch j.TypeCode {
+	case TypeCodeObject:
+		for _, key := range getSortedKeys(j.Object) {
+			if walk(j.Object[key], fullPath.pushBackOneLeg(pathLeg{typ: pathLegKey, dotKey: key}), fn) {
+				return true
+			}
+		}
+	case TypeCodeArray:
+		for i, child := range j.Array {
+			if walk(child, fullPath.pushBackOneLeg(pathLeg{typ: pathLegIndex, arrayIndex: i}), fn) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// walkPath is like extract, but it calls fn with the matched values and the paths to them,
+// which contain no wildcards. fullPath is the path to j.
+func walkPath(j JSON, fullPath PathExpression, pathExpr PathExpression, fn walkFunc) bool {
+	if len(pathExpr.legs) == 0 {
+		return fn(fullPath, j)
+	}
+	currentLeg, subPathExpr := pathExpr.popOneLeg()
+	switch currentLeg.typ {
+	case pathLegIndex:
+		// A non-array value is regarded as an array containing only itself.
+		if j.TypeCode != TypeCodeArray {
+			if currentLeg.arrayIndex == 0 || currentLeg.arrayIndex == arrayIndexAsterisk {
+				return walkPath(j, fullPath, subPathExpr, fn)
+			}
+			return false
+		}
+		for i, child := range j.Array {
+			if currentLeg.arrayIndex == i || currentLeg.arrayIndex == arrayIndexAsterisk {
+				if walkPath(child, fullPath.pushBackOneLeg(pathLeg{typ: pathLegIndex, arrayIndex: i}), subPathExpr, fn) {
+					return true
+				}
+			}
+		}
+	case pathLegKey:
+		if j.TypeCode != TypeCodeObject {
+			return false
+		}
+		for _, key := range getSortedKeys(j.Object) {
+			if currentLeg.dotKey == key || currentLeg.dotKey == "*" {
+				if walkPath(j.Object[key], fullPath.pushBackOneLeg(pathLeg{typ: pathLegKey, dotKey: key}), subPathExpr, fn) {
+					return true
+				}
+			}
+		}
+	case pathLegDoubleAsterisk:
+		if walkPath(j, fullPath, subPathExpr, fn) {
+			return true
+		}
+		switch j.TypeCode {
+		case TypeCodeObject:
+			for _, key := range getSortedKeys(j.Object) {
+				if walkPath(j.Object[key], fullPath.pushBackOneLeg(pathLeg{typ: pathLegKey, dotKey: key}), pathExpr, fn) {
+					return true
+				}
+			}
+		case TypeCodeArray:
+			for i, child := range j.Array {
+				if walkPath(child, fullPath.pushBackOneLeg(pathLeg{typ: pathLegIndex, arrayIndex: i}), pathExpr, fn) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -219,8 +219,136 @@ func (s *testJSONSuite) TestJSONModify(c *C) {
 			cmp, err = CompareJSON(obtain, expected)
 			c.Assert(err, IsNil)
 			c.Assert(cmp, Equals, 0)
+			// the base JSON should be kept unchanged.
+			cmp, err = CompareJSON(base, mustParseFromString(tt.base))
+			c.Assert(err, IsNil)
+			c.Assert(cmp, Equals, 0)
 		} else {
 			c.Assert(err, NotNil)
 		}
 	}
 }
+
+func mustParsePathExprs(c *C, pathExprStrings []string) []PathExpression {
+	pathExprList := make([]PathExpression, 0, len(pathExprStrings))
+	for _, peStr := range pathExprStrings {
+		pe, err := ParseJSONPathExpr(peStr)
+		c.Assert(err, IsNil)
+		pathExprList = append(pathExprList, pe)
+	}
+	return pathExprList
+}
+
+func (s *testJSONSuite) TestJSONContains(c *C) {
+	var tests = []struct {
+		obj      string
+		target   string
+		contains bool
+	}{
+		{`1`, `1`, true},
+		{`1`, `1.0`, true},
+		{`1`, `"1"`, false},
+		{`true`, `1`, false},
+		{`[1, 2, [3, 4]]`, `2`, true},
+		{`[1, 2, [3, 4]]`, `[1, 3]`, true},
+		{`[1, 2, [3, 4]]`, `[1, 5]`, false},
+		{`[1, 2, [3, 4]]`, `[[3]]`, true},
+		{`[1, 2]`, `{"a": 1}`, false},
+		{`{"a": 1, "b": {"c": [1, 2]}}`, `{"b": {"c": 2}}`, true},
+		{`{"a": 1, "b": {"c": [1, 2]}}`, `{"a": 1, "c": 1}`, false},
+		{`{"a": 1}`, `1`, false},
+		{`{"a": 1}`, `{}`, true},
+		{`[]`, `[]`, true},
+	}
+	for _, tt := range tests {
+		obj, target := mustParseFromString(tt.obj), mustParseFromString(tt.target)
+		c.Assert(ContainsJSON(obj, target), Equals, tt.contains, Commentf("%s %s", tt.obj, tt.target))
+	}
+}
+
+func (s *testJSONSuite) TestJSONKeysLengthDepth(c *C) {
+	var tests = []struct {
+		input  string
+		keys   string
+		length int
+		depth  int
+	}{
+		{`1`, ``, 1, 1},
+		{`[]`, ``, 0, 1},
+		{`{}`, `[]`, 0, 1},
+		{`[1, [2, [3]]]`, ``, 2, 4},
+		{`{"b": 1, "a": {"c": [1]}}`, `["a", "b"]`, 2, 4},
+	}
+	for _, tt := range tests {
+		j := mustParseFromString(tt.input)
+		keys, ok := j.Keys()
+		c.Assert(ok, Equals, tt.keys != "")
+		if ok {
+			c.Assert(keys.String(), Equals, mustParseFromString(tt.keys).String())
+		}
+		c.Assert(j.Length(), Equals, tt.length)
+		c.Assert(j.Depth(), Equals, tt.depth)
+	}
+}
+
+func (s *testJSONSuite) TestJSONSearch(c *C) {
+	j := mustParseFromString(`["abc", [{"k": "10"}, "def"], {"x": "abc"}, {"y": "bcd"}]`)
+	var tests = []struct {
+		pathExprStrings []string
+		search          string
+		one             bool
+		paths           []string
+	}{
+		{nil, "abc", true, []string{"$[0]"}},
+		{nil, "abc", false, []string{"$[0]", "$[2].x"}},
+		{nil, "ghi", false, nil},
+		{nil, "10", false, []string{"$[1][0].k"}},
+		{[]string{"$[1]"}, "10", false, []string{"$[1][0].k"}},
+		{[]string{"$[2]"}, "10", false, nil},
+		{[]string{"$[*]"}, "abc", false, []string{"$[0]", "$[2].x"}},
+		{[]string{"$**.k"}, "10", false, []string{"$[1][0].k"}},
+		{[]string{"$[0]", "$[0]", "$"}, "abc", false, []string{"$[0]", "$[2].x"}},
+	}
+	for _, tt := range tests {
+		pathExprList := mustParsePathExprs(c, tt.pathExprStrings)
+		paths := j.Search(pathExprList, func(s string) bool { return s == tt.search }, tt.one)
+		c.Assert(paths, HasLen, len(tt.paths))
+		for i, path := range paths {
+			c.Assert(path.String(), Equals, tt.paths[i])
+		}
+	}
+}
+
+func (s *testJSONSuite) TestJSONArrayAppend(c *C) {
+	var tests = []struct {
+		input           string
+		pathExprStrings []string
+		values          []string
+		output          string
+		err             error
+	}{
+		{`[1, [2, 3]]`, []string{"$[1]"}, []string{`4`}, `[1, [2, 3, 4]]`, nil},
+		{`[1, [2, 3]]`, []string{"$[0]"}, []string{`4`}, `[[1, 4], [2, 3]]`, nil},
+		{`[1, [2, 3]]`, []string{"$"}, []string{`4`}, `[1, [2, 3], 4]`, nil},
+		{`[1, [2, 3]]`, []string{"$[5]"}, []string{`4`}, `[1, [2, 3]]`, nil},
+		{`{"a": 1, "b": {"c": 2}}`, []string{"$.b", "$.a"}, []string{`3`, `"x"`}, `{"a": [1, "x"], "b": [{"c": 2}, 3]}`, nil},
+		{`1`, []string{"$"}, []string{`1`}, `[1, 1]`, nil},
+		{`[1]`, []string{"$[*]"}, []string{`1`}, ``, ErrInvalidJSONPathWildcard},
+	}
+	for _, tt := range tests {
+		j := mustParseFromString(tt.input)
+		values := make([]JSON, 0, len(tt.values))
+		for _, v := range tt.values {
+			values = append(values, mustParseFromString(v))
+		}
+		ret, err := j.ArrayAppend(mustParsePathExprs(c, tt.pathExprStrings), values)
+		if tt.err != nil {
+			c.Assert(err, Equals, tt.err)
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Assert(ret.String(), Equals, mustParseFromString(tt.output).String())
+		// The input JSON is left unchanged.
+		c.Assert(j.String(), Equals, mustParseFromString(tt.input).String())
+	}
+}
@@ -14,6 +14,7 @@
 package json
 
 import (
+	"bytes"
 	"regexp"
 	"strconv"
 	"strings"
@@ -49,6 +50,9 @@ import (
 // "[^"\\]*(\\.[^"\\]*)*" matches any string literal which can carry escaped quotes;
 var jsonPathExprLegRe = regexp.MustCompile(`(\.\s*([a-zA-Z_][a-zA-Z0-9_]*|\*|"[^"\\]*(\\.[^"\\]*)*")|(\[\s*([0-9]+|\*)\s*\])|\*\*)`)
 
+// jsonPathKeyIdentifierRe matches the keys which needn't be quoted in a path expression.
+var jsonPathKeyIdentifierRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
 type pathLegType byte
 
 const (
@@ -109,6 +113,50 @@ func (pe PathExpression) popOneLeg() (pathLeg, PathExpression) {
 	return pe.legs[0], newPe
 }
 
+// pushBackOneLeg returns a new PathExpression with leg appended, pe is left unchanged.
+func (pe PathExpression) pushBackOneLeg(leg pathLeg) PathExpression {
+	legs := make([]pathLeg, 0, len(pe.legs)+1)
+	legs = append(legs, pe.legs...)
+	newPe := PathExpression{legs: append(legs, leg), flags: pe.flags}
+	if (leg.typ == pathLegIndex && leg.arrayIndex == arrayIndexAsterisk) || (leg.typ == pathLegKey && leg.dotKey == "*") {
+		newPe.flags |= pathExpressionContainsAsterisk
+	} else if leg.typ == pathLegDoubleAsterisk {
+		newPe.flags |= pathExpressionContainsDoubleAsterisk
+	}
+	return newPe
+}
+
+// ContainsAnyAsterisk returns true if pe contains any asterisk.
+func (pe PathExpression) ContainsAnyAsterisk() bool {
+	return pe.flags.containsAnyAsterisk()
+}
+
+// String implements fmt.Stringer interface.
+func (pe PathExpression) String() string {
+	var buf bytes.Buffer
+	buf.WriteByte('$')
+	for _, leg := range pe.legs {
+		switch leg.typ {
+		case pathLegIndex:
+			if leg.arrayIndex == arrayIndexAsterisk {
+				buf.WriteString("[*]")
+			} else {
+				buf.WriteString("[" + strconv.Itoa(leg.arrayIndex) + "]")
+			}
+		case pathLegKey:
+			buf.WriteByte('.')
+			if leg.dotKey == "*" || jsonPathKeyIdentifierRe.MatchString(leg.dotKey) {
+				buf.WriteString(leg.dotKey)
+			} else {
+				buf.WriteString(CreateJSON(leg.dotKey).String())
+			}
+		case pathLegDoubleAsterisk:
+			buf.WriteString("**")
+		}
+	}
+	return buf.String()
+}
+
 // ParseJSONPathExpr parses a JSON path expression. Returns a PathExpression
 // object which can be used in JSON_EXTRACT, JSON_SET and so on.
 func ParseJSONPathExpr(pathExpr string) (pe PathExpression, err error) {
@@ -62,3 +62,24 @@ func (s *testJSONSuite) TestValidatePathExpr(c *C) {
 		}
 	}
 }
+
+func (s *testJSONSuite) TestPathExpressionString(c *C) {
+	var tests = []struct {
+		exprString string
+		output     string
+	}{
+		{`   $  `, "$"},
+		{"   $ .   key1  [  3  ]\t[*].*.key3", "$.key1[3][*].*.key3"},
+		{`$**[0]`, "$**[0]"},
+		{`$."key1 string"."a"`, `$."key1 string".a`},
+	}
+	for _, tt := range tests {
+		pe, err := ParseJSONPathExpr(tt.exprString)
+		c.Assert(err, IsNil)
+		c.Assert(pe.String(), Equals, tt.output)
+		// The output can be parsed back to the same path expression.
+		pe1, err := ParseJSONPathExpr(pe.String())
+		c.Assert(err, IsNil)
+		c.Assert(pe1, DeepEquals, pe)
+	}
+}
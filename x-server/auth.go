@@ -0,0 +1,286 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xserver
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/hex"
+	"io"
+	"net"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/util/auth"
+	"github.com/pingcap/tipb/go-mysqlx"
+	"github.com/pingcap/tipb/go-mysqlx/Connection"
+	"github.com/pingcap/tipb/go-mysqlx/Datatypes"
+	"github.com/pingcap/tipb/go-mysqlx/Notice"
+	"github.com/pingcap/tipb/go-mysqlx/Session"
+)
+
+// Authentication mechanisms supported by the server.
+const (
+	authMySQL41 = "MYSQL41"
+	authPlain   = "PLAIN"
+)
+
+// handshake negotiates the capabilities with the client and authenticates the session.
+// The client may set capabilities, for example, upgrade the connection to TLS, before it
+// starts the authentication. After handshake, client can send statements to server.
+func (cc *clientConn) handshake() error {
+	for {
+		tp, payload, err := cc.readPacket()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		switch Mysqlx.ClientMessages_Type(tp) {
+		case Mysqlx.ClientMessages_CON_CAPABILITIES_GET:
+			err = cc.handleCapabilitiesGet()
+		case Mysqlx.ClientMessages_CON_CAPABILITIES_SET:
+			err = cc.handleCapabilitiesSet(payload)
+		case Mysqlx.ClientMessages_CON_CLOSE:
+			cc.writeOK()
+			return io.EOF
+		case Mysqlx.ClientMessages_SESS_AUTHENTICATE_START:
+			if err = cc.handleAuthenticate(payload); err != nil {
+				cc.writeError(err)
+			}
+			return errors.Trace(err)
+		default:
+			err = errBadMessage.Gen("Unexpected message received before authentication: %d", tp)
+			cc.writeError(err)
+			return errors.Trace(err)
+		}
+		if err != nil {
+			if err = cc.writeError(err); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+}
+
+// capabilities returns the capabilities of the connection.
+func (cc *clientConn) capabilities() *Mysqlx_Connection.Capabilities {
+	mechanisms := []*Mysqlx_Datatypes.Any{anyString(authMySQL41)}
+	if cc.isTLS {
+		mechanisms = append(mechanisms, anyString(authPlain))
+	}
+	return &Mysqlx_Connection.Capabilities{
+		Capabilities: []*Mysqlx_Connection.Capability{
+			{Name: proto.String("tls"), Value: anyBool(cc.isTLS)},
+			{Name: proto.String("authentication.mechanisms"), Value: &Mysqlx_Datatypes.Any{
+				Type:  Mysqlx_Datatypes.Any_ARRAY.Enum(),
+				Array: &Mysqlx_Datatypes.Array{Value: mechanisms},
+			}},
+			{Name: proto.String("doc.formats"), Value: anyString("text")},
+			{Name: proto.String("node_type"), Value: anyString("mysql")},
+		},
+	}
+}
+
+func (cc *clientConn) handleCapabilitiesGet() error {
+	if err := cc.writeMessage(Mysqlx.ServerMessages_CONN_CAPABILITIES, cc.capabilities()); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.flush())
+}
+
+// handleCapabilitiesSet sets the capabilities sent by client, the only capability
+// can be set is "tls", which upgrades the connection to TLS after the Mysqlx.Ok is sent.
+func (cc *clientConn) handleCapabilitiesSet(payload []byte) error {
+	var msg Mysqlx_Connection.CapabilitiesSet
+	if err := proto.Unmarshal(payload, &msg); err != nil {
+		return errors.Trace(errBadMessage)
+	}
+	upgradeTLS := false
+	for _, c := range msg.GetCapabilities().GetCapabilities() {
+		switch c.GetName() {
+		case "tls":
+			v, ok := scalarBool(c.GetValue().GetScalar())
+			if !ok || c.GetValue().GetType() != Mysqlx_Datatypes.Any_SCALAR {
+				return errCapabilitiesPrepareFailed.GenByArgs(c.GetName())
+			}
+			if v == cc.isTLS {
+				continue
+			}
+			if !v || cc.server.tlsConfig == nil {
+				return errCapabilitiesPrepareFailed.GenByArgs(c.GetName())
+			}
+			upgradeTLS = true
+		case "client.pwd_expire_ok":
+		default:
+			return errCapabilityNotFound.GenByArgs(c.GetName())
+		}
+	}
+	if err := cc.writeOK(); err != nil {
+		return errors.Trace(err)
+	}
+	if upgradeTLS {
+		tlsConn := tls.Server(cc.conn, cc.server.tlsConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			return errors.Trace(err)
+		}
+		cc.conn = tlsConn
+		cc.pkt = newPacketIO(tlsConn)
+		cc.isTLS = true
+	}
+	return nil
+}
+
+// handleAuthenticate authenticates the session by MYSQL41 or PLAIN mechanism.
+//
+// MYSQL41 works like mysql_native_password:
+//
+//	SERVER: send(Mysqlx.Session.AuthenticateContinue{auth_data: salt})
+//	CLIENT: send(Mysqlx.Session.AuthenticateContinue{auth_data: schema\0user\0*hex(scramble(password, salt))})
+//
+// PLAIN sends the password in plaintext, so it's only allowed on TLS connection:
+//
+//	CLIENT: send(Mysqlx.Session.AuthenticateStart{auth_data: schema\0user\0password})
+func (cc *clientConn) handleAuthenticate(payload []byte) error {
+	var msg Mysqlx_Session.AuthenticateStart
+	if err := proto.Unmarshal(payload, &msg); err != nil {
+		return errors.Trace(errBadMessage)
+	}
+	var (
+		schema, user string
+		scramble     []byte
+	)
+	switch msg.GetMechName() {
+	case authMySQL41:
+		err := cc.writeMessage(Mysqlx.ServerMessages_SESS_AUTHENTICATE_CONTINUE,
+			&Mysqlx_Session.AuthenticateContinue{AuthData: cc.salt})
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err = cc.flush(); err != nil {
+			return errors.Trace(err)
+		}
+		tp, data, err := cc.readPacket()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if Mysqlx.ClientMessages_Type(tp) != Mysqlx.ClientMessages_SESS_AUTHENTICATE_CONTINUE {
+			return errBadMessage.Gen("Unexpected message received during authentication: %d", tp)
+		}
+		var cont Mysqlx_Session.AuthenticateContinue
+		if err = proto.Unmarshal(data, &cont); err != nil {
+			return errors.Trace(errBadMessage)
+		}
+		var hexScramble string
+		schema, user, hexScramble, err = parseAuthData(cont.GetAuthData())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if len(hexScramble) > 0 {
+			scramble, err = hex.DecodeString(hexScramble[1:])
+			if err != nil || hexScramble[0] != '*' || len(scramble) != sha1.Size {
+				return errAccessDenied.GenByArgs(user, cc.host(), "YES")
+			}
+		}
+	case authPlain:
+		if !cc.isTLS {
+			return errNotSupportedAuthMode.GenByArgs(msg.GetMechName())
+		}
+		var (
+			password string
+			err      error
+		)
+		schema, user, password, err = parseAuthData(msg.GetAuthData())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		scramble = scramblePassword(cc.salt, password)
+	default:
+		return errNotSupportedAuthMode.GenByArgs(msg.GetMechName())
+	}
+	return errors.Trace(cc.openSession(schema, user, scramble))
+}
+
+// openSession opens the session and checks the user, then tells the client
+// the authentication has succeeded.
+func (cc *clientConn) openSession(schema, user string, scramble []byte) error {
+	var err error
+	cc.user = user
+	cc.ctx, err = cc.server.driver.OpenCtx(uint64(cc.connectionID), 0, cc.collation, schema)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if tlsConn, ok := cc.conn.(*tls.Conn); ok {
+		tlsState := tlsConn.ConnectionState()
+		cc.ctx.SetTLSState(&tlsState)
+	}
+	if !cc.server.skipAuth() {
+		host := cc.host()
+		if !cc.ctx.Auth(&auth.UserIdentity{Username: user, Hostname: host}, scramble, cc.salt) {
+			return errAccessDenied.GenByArgs(user, host, hasPassword(scramble))
+		}
+	}
+	if schema != "" {
+		if err = cc.useDB(schema); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	err = cc.writeSessionStateChanged(Mysqlx_Notice.SessionStateChanged_CLIENT_ID_ASSIGNED, scalarUint(uint64(cc.connectionID)))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err = cc.writeMessage(Mysqlx.ServerMessages_SESS_AUTHENTICATE_OK, &Mysqlx_Session.AuthenticateOk{}); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.flush())
+}
+
+// host returns the host of the client, it's empty if the client isn't connected by TCP.
+func (cc *clientConn) host() string {
+	host, _, err := net.SplitHostPort(cc.conn.RemoteAddr().String())
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+func hasPassword(scramble []byte) string {
+	if len(scramble) > 0 {
+		return "YES"
+	}
+	return "NO"
+}
+
+// parseAuthData parses the authentication data in the format of "schema\0user\0password".
+func parseAuthData(data []byte) (schema, user, password string, err error) {
+	parts := bytes.Split(data, []byte{0})
+	if len(parts) != 3 {
+		return "", "", "", errBadMessage.Gen("Invalid authentication data")
+	}
+	return string(parts[0]), string(parts[1]), string(parts[2]), nil
+}
+
+// scramblePassword computes the scramble of the password like a client does in
+// mysql_native_password authentication, so the plaintext password can be verified
+// the same as MYSQL41.
+func scramblePassword(salt []byte, password string) []byte {
+	if len(password) == 0 {
+		return nil
+	}
+	stage1 := auth.Sha1Hash([]byte(password))
+	stage2 := auth.Sha1Hash(stage1)
+	scramble := auth.Sha1Hash(append(append([]byte{}, salt...), stage2...))
+	for i := range scramble {
+		scramble[i] ^= stage1[i]
+	}
+	return scramble
+}
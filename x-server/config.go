@@ -19,4 +19,8 @@ type Config struct {
 	Socket   string `json:"socket" toml:"socket"`
 	LogLevel string `json:"log_level" toml:"log_level"`
 	SkipAuth bool   `json:"skip_auth" toml:"skip_auth"`
+	// TLSCert and TLSKey are the paths of the certificate and the private key files,
+	// the TLS capability and the PLAIN authentication are enabled only if both are set.
+	TLSCert string `json:"tls_cert" toml:"tls_cert"`
+	TLSKey  string `json:"tls_key" toml:"tls_key"`
 }
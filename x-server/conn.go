@@ -14,20 +14,31 @@
 package xserver
 
 import (
+	"fmt"
 	"io"
 	"net"
+	"runtime"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/server"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/arena"
+	"github.com/pingcap/tipb/go-mysqlx"
+	"github.com/pingcap/tipb/go-mysqlx/Datatypes"
+	"github.com/pingcap/tipb/go-mysqlx/Expect"
+	"github.com/pingcap/tipb/go-mysqlx/Notice"
 )
 
 // clientConn represents a connection between server and client,
 // it maintains connection specific state, handles client query.
 type clientConn struct {
 	conn         net.Conn
+	pkt          *packetIO       // a helper to read and write data in x protocol format.
 	server       *Server         // a reference of server instance.
+	ctx          server.QueryCtx // an interface to execute sql statements.
 	connectionID uint32          // atomically allocated by a global variable, unique in process scope.
 	collation    uint8           // collation used by client, may be different from the collation used by database.
 	user         string          // user of the client.
@@ -35,15 +46,39 @@ type clientConn struct {
 	salt         []byte          // random bytes used for authentication.
 	alloc        arena.Allocator // an memory allocator for reducing memory allocation.
 	killed       bool
+	isTLS        bool          // whether the connection has been upgraded to TLS.
+	expects      []expectBlock // the opened expectation blocks.
+}
+
+// expectBlock is an expectation block opened by Mysqlx.Expect.Open.
+type expectBlock struct {
+	noError bool // whether the following messages should fail after an error.
+	failed  bool // whether an error has occurred in this block.
+}
+
+// expectNoError is the condition key of Mysqlx.Expect.Open for "no error".
+const expectNoError = 1
+
+func (cc *clientConn) String() string {
+	return fmt.Sprintf("id:%d, addr:%s, collation:%s, user:%s",
+		cc.connectionID, cc.conn.RemoteAddr(), mysql.Collations[cc.collation], cc.user)
 }
 
 func (cc *clientConn) Run() {
+	const size = 4096
 	defer func() {
-		recover()
+		r := recover()
+		if r != nil {
+			buf := make([]byte, size)
+			stackSize := runtime.Stack(buf, false)
+			buf = buf[:stackSize]
+			log.Errorf("[%d] %v, %s", cc.connectionID, r, buf)
+		}
 		cc.Close()
 	}()
 
 	for !cc.killed {
+		cc.alloc.Reset()
 		tp, payload, err := cc.readPacket()
 		if err != nil {
 			if terror.ErrorNotEqual(err, io.EOF) {
@@ -53,9 +88,12 @@ func (cc *clientConn) Run() {
 			return
 		}
 		if err = cc.dispatch(tp, payload); err != nil {
-			if terror.ErrorEqual(err, terror.ErrResultUndetermined) {
+			if terror.ErrorEqual(err, io.EOF) {
+				return
+			} else if terror.ErrorEqual(err, terror.ErrResultUndetermined) {
 				log.Errorf("[%d] result undetermined error, close this connection %s",
 					cc.connectionID, errors.ErrorStack(err))
+				return
 			} else if terror.ErrorEqual(err, terror.ErrCritical) {
 				log.Errorf("[%d] critical error, stop the server listener %s",
 					cc.connectionID, errors.ErrorStack(err))
@@ -63,21 +101,21 @@ func (cc *clientConn) Run() {
 				case cc.server.stopListenerCh <- struct{}{}:
 				default:
 				}
+				return
 			}
 			log.Warnf("[%d] dispatch error: %s, %s", cc.connectionID, cc, err)
-			cc.writeError(err)
-			return
+			if err = cc.writeError(err); err != nil {
+				return
+			}
 		}
 	}
 }
 
 func (cc *clientConn) Close() error {
-	err := cc.conn.Close()
-	return errors.Trace(err)
-}
-
-func (cc *clientConn) handshake() error {
-	// TODO: implement it.
+	cc.conn.Close()
+	if cc.ctx != nil {
+		return cc.ctx.Close()
+	}
 	return nil
 }
 
@@ -88,12 +126,175 @@ func (cc *clientConn) handshake() error {
 // ------------------------------------------------------
 // See: https://dev.mysql.com/doc/internals/en/x-protocol-messages-messages.html
 func (cc *clientConn) readPacket() (byte, []byte, error) {
-	return 0x00, nil, nil
+	return cc.pkt.readPacket()
 }
 
+// writeMessage marshals msg and writes it with the type tp, it won't flush the stream.
+func (cc *clientConn) writeMessage(tp Mysqlx.ServerMessages_Type, msg proto.Message) error {
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.pkt.writePacket(byte(tp), payload))
+}
+
+func (cc *clientConn) flush() error {
+	return cc.pkt.flush()
+}
+
+// dispatch handles the message sent by client after the session is authenticated.
 func (cc *clientConn) dispatch(tp byte, payload []byte) error {
+	msgType := Mysqlx.ClientMessages_Type(tp)
+	if n := len(cc.expects); n > 0 && cc.expects[n-1].failed && msgType != Mysqlx.ClientMessages_EXPECT_CLOSE {
+		return errExpectFailed
+	}
+	err := cc.dispatchMessage(msgType, payload)
+	if err != nil && terror.ErrorNotEqual(err, io.EOF) {
+		if n := len(cc.expects); n > 0 && cc.expects[n-1].noError {
+			cc.expects[n-1].failed = true
+		}
+	}
+	return errors.Trace(err)
+}
+
+func (cc *clientConn) dispatchMessage(msgType Mysqlx.ClientMessages_Type, payload []byte) error {
+	switch msgType {
+	case Mysqlx.ClientMessages_CON_CAPABILITIES_GET:
+		return cc.handleCapabilitiesGet()
+	case Mysqlx.ClientMessages_CON_CLOSE, Mysqlx.ClientMessages_SESS_CLOSE:
+		if err := cc.writeOK(); err != nil {
+			return errors.Trace(err)
+		}
+		return io.EOF
+	case Mysqlx.ClientMessages_SESS_RESET:
+		return cc.handleSessionReset()
+	case Mysqlx.ClientMessages_SQL_STMT_EXECUTE:
+		return cc.handleStmtExecute(payload)
+	case Mysqlx.ClientMessages_CRUD_FIND:
+		return cc.handleCrudFind(payload)
+	case Mysqlx.ClientMessages_CRUD_INSERT:
+		return cc.handleCrudInsert(payload)
+	case Mysqlx.ClientMessages_CRUD_UPDATE:
+		return cc.handleCrudUpdate(payload)
+	case Mysqlx.ClientMessages_CRUD_DELETE:
+		return cc.handleCrudDelete(payload)
+	case Mysqlx.ClientMessages_EXPECT_OPEN:
+		return cc.handleExpectOpen(payload)
+	case Mysqlx.ClientMessages_EXPECT_CLOSE:
+		return cc.handleExpectClose()
+	default:
+		return errBadMessage.Gen("Unexpected message received: %d", msgType)
+	}
+}
+
+// handleSessionReset discards the session state by opening a new session for the same user.
+func (cc *clientConn) handleSessionReset() error {
+	ctx, err := cc.server.driver.OpenCtx(uint64(cc.connectionID), 0, cc.collation, cc.dbname)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	cc.ctx.Close()
+	cc.ctx = ctx
+	if cc.dbname != "" {
+		if err = cc.useDB(cc.dbname); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	cc.expects = cc.expects[:0]
+	return cc.writeOK()
+}
+
+func (cc *clientConn) handleExpectOpen(payload []byte) error {
+	var msg Mysqlx_Expect.Open
+	if err := proto.Unmarshal(payload, &msg); err != nil {
+		return errors.Trace(errBadMessage)
+	}
+	var block expectBlock
+	if n := len(cc.expects); n > 0 && msg.GetOp() == Mysqlx_Expect.Open_EXPECT_CTX_COPY_PREV {
+		block.noError = cc.expects[n-1].noError
+	}
+	for _, cond := range msg.GetCond() {
+		if cond.GetConditionKey() != expectNoError {
+			return errExpectBadCondition.GenByArgs(cond.GetConditionKey())
+		}
+		block.noError = cond.GetOp() == Mysqlx_Expect.Open_Condition_EXPECT_OP_SET
+	}
+	cc.expects = append(cc.expects, block)
+	return cc.writeOK()
+}
+
+func (cc *clientConn) handleExpectClose() error {
+	n := len(cc.expects)
+	if n == 0 {
+		return errExpectNotOpen
+	}
+	block := cc.expects[n-1]
+	cc.expects = cc.expects[:n-1]
+	if block.failed {
+		return errExpectFailed
+	}
+	return cc.writeOK()
+}
+
+func (cc *clientConn) useDB(db string) error {
+	_, err := cc.ctx.Execute("use " + quoteIdentifier(db))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	cc.dbname = db
 	return nil
 }
 
-func (cc *clientConn) writeError(e error) {
+// writeOK writes a Mysqlx.Ok message and flushes the stream.
+func (cc *clientConn) writeOK() error {
+	if err := cc.writeMessage(Mysqlx.ServerMessages_OK, &Mysqlx.Ok{}); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.flush())
 }
+
+// writeError writes a Mysqlx.Error message and flushes the stream.
+func (cc *clientConn) writeError(e error) error {
+	var (
+		m  *mysql.SQLError
+		te *terror.Error
+		ok bool
+	)
+	originErr := errors.Cause(e)
+	if te, ok = originErr.(*terror.Error); ok {
+		m = te.ToSQLError()
+	} else {
+		m = mysql.NewErrf(mysql.ErrUnknown, "%s", e.Error())
+	}
+	msg := &Mysqlx.Error{
+		Severity: Mysqlx.Error_ERROR.Enum(),
+		Code:     proto.Uint32(uint32(m.Code)),
+		SqlState: proto.String(m.State),
+		Msg:      proto.String(m.Message),
+	}
+	if err := cc.writeMessage(Mysqlx.ServerMessages_ERROR, msg); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.flush())
+}
+
+// writeSessionStateChanged writes a local notice to tell the client the change of session state,
+// it won't flush the stream.
+func (cc *clientConn) writeSessionStateChanged(param Mysqlx_Notice.SessionStateChanged_Parameter, value *Mysqlx_Datatypes.Scalar) error {
+	changed, err := proto.Marshal(&Mysqlx_Notice.SessionStateChanged{
+		Param: param.Enum(),
+		Value: value,
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	frame := &Mysqlx_Notice.Frame{
+		Type:    proto.Uint32(noticeSessionStateChanged),
+		Scope:   Mysqlx_Notice.Frame_LOCAL.Enum(),
+		Payload: changed,
+	}
+	return errors.Trace(cc.writeMessage(Mysqlx.ServerMessages_NOTICE, frame))
+}
+
+// noticeSessionStateChanged is the type of Mysqlx.Notice.Frame for Mysqlx.Notice.SessionStateChanged.
+const noticeSessionStateChanged = 3
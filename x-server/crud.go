@@ -0,0 +1,402 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xserver
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/juju/errors"
+	"github.com/pingcap/tipb/go-mysqlx/Crud"
+	"github.com/pingcap/tipb/go-mysqlx/Datatypes"
+	"github.com/pingcap/tipb/go-mysqlx/Expr"
+)
+
+// The CRUD messages are translated to SQL statements and executed like Mysqlx.Sql.StmtExecute.
+// A collection is a table created by "create_collection", and its documents are stored in the "doc" column.
+
+func (cc *clientConn) handleCrudFind(payload []byte) error {
+	var msg Mysqlx_Crud.Find
+	if err := proto.Unmarshal(payload, &msg); err != nil {
+		return errors.Trace(errBadMessage)
+	}
+	sql, err := buildFind(&msg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.executeSQL(sql))
+}
+
+func (cc *clientConn) handleCrudInsert(payload []byte) error {
+	var msg Mysqlx_Crud.Insert
+	if err := proto.Unmarshal(payload, &msg); err != nil {
+		return errors.Trace(errBadMessage)
+	}
+	sql, err := buildInsert(&msg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.executeSQL(sql))
+}
+
+func (cc *clientConn) handleCrudUpdate(payload []byte) error {
+	var msg Mysqlx_Crud.Update
+	if err := proto.Unmarshal(payload, &msg); err != nil {
+		return errors.Trace(errBadMessage)
+	}
+	sql, err := buildUpdate(&msg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.executeSQL(sql))
+}
+
+func (cc *clientConn) handleCrudDelete(payload []byte) error {
+	var msg Mysqlx_Crud.Delete
+	if err := proto.Unmarshal(payload, &msg); err != nil {
+		return errors.Trace(errBadMessage)
+	}
+	sql, err := buildDelete(&msg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.executeSQL(sql))
+}
+
+// crudBuilder builds the SQL statement of a CRUD message.
+type crudBuilder struct {
+	buf        bytes.Buffer
+	args       []*Mysqlx_Datatypes.Scalar
+	isDocument bool
+}
+
+func newCrudBuilder(model Mysqlx_Crud.DataModel, args []*Mysqlx_Datatypes.Scalar) *crudBuilder {
+	return &crudBuilder{args: args, isDocument: model == Mysqlx_Crud.DataModel_DOCUMENT}
+}
+
+func (b *crudBuilder) writeExpr(expr *Mysqlx_Expr.Expr) error {
+	s, err := generateExpr(expr, b.args, b.isDocument)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	b.buf.WriteString(s)
+	return nil
+}
+
+func (b *crudBuilder) writeCollection(c *Mysqlx_Crud.Collection) {
+	b.buf.WriteString(qualifiedName(c.GetSchema(), c.GetName()))
+}
+
+func (b *crudBuilder) writeCriteria(criteria *Mysqlx_Expr.Expr) error {
+	if criteria == nil {
+		return nil
+	}
+	b.buf.WriteString(" WHERE ")
+	return errors.Trace(b.writeExpr(criteria))
+}
+
+func (b *crudBuilder) writeOrder(order []*Mysqlx_Crud.Order) error {
+	for i, o := range order {
+		if i == 0 {
+			b.buf.WriteString(" ORDER BY ")
+		} else {
+			b.buf.WriteString(", ")
+		}
+		if err := b.writeExpr(o.GetExpr()); err != nil {
+			return errors.Trace(err)
+		}
+		if o.GetDirection() == Mysqlx_Crud.Order_DESC {
+			b.buf.WriteString(" DESC")
+		}
+	}
+	return nil
+}
+
+// writeLimit writes the LIMIT clause, the offset is only allowed if allowOffset is true,
+// because UPDATE and DELETE statements don't support it.
+func (b *crudBuilder) writeLimit(limit *Mysqlx_Crud.Limit, allowOffset bool) error {
+	if limit == nil {
+		return nil
+	}
+	b.buf.WriteString(" LIMIT ")
+	if limit.GetOffset() != 0 {
+		if !allowOffset {
+			return errExprBadValue.GenByArgs("Invalid parameter: offset value not allowed for this operation")
+		}
+		b.buf.WriteString(strconv.FormatUint(limit.GetOffset(), 10))
+		b.buf.WriteString(", ")
+	}
+	b.buf.WriteString(strconv.FormatUint(limit.GetRowCount(), 10))
+	return nil
+}
+
+// buildFind builds the SELECT statement of Mysqlx.Crud.Find. For a collection, the projection
+// builds a new document by JSON_OBJECT, so the result is still a set of documents.
+func buildFind(msg *Mysqlx_Crud.Find) (string, error) {
+	b := newCrudBuilder(msg.GetDataModel(), msg.GetArgs())
+	b.buf.WriteString("SELECT ")
+	if err := b.writeProjection(msg.GetProjection()); err != nil {
+		return "", errors.Trace(err)
+	}
+	b.buf.WriteString(" FROM ")
+	b.writeCollection(msg.GetCollection())
+	if err := b.writeCriteria(msg.GetCriteria()); err != nil {
+		return "", errors.Trace(err)
+	}
+	for i, expr := range msg.GetGrouping() {
+		if i == 0 {
+			b.buf.WriteString(" GROUP BY ")
+		} else {
+			b.buf.WriteString(", ")
+		}
+		if err := b.writeExpr(expr); err != nil {
+			return "", errors.Trace(err)
+		}
+	}
+	if msg.GetGroupingCriteria() != nil {
+		b.buf.WriteString(" HAVING ")
+		if err := b.writeExpr(msg.GetGroupingCriteria()); err != nil {
+			return "", errors.Trace(err)
+		}
+	}
+	if err := b.writeOrder(msg.GetOrder()); err != nil {
+		return "", errors.Trace(err)
+	}
+	if err := b.writeLimit(msg.GetLimit(), true); err != nil {
+		return "", errors.Trace(err)
+	}
+	return b.buf.String(), nil
+}
+
+func (b *crudBuilder) writeProjection(projection []*Mysqlx_Crud.Projection) error {
+	if len(projection) == 0 {
+		if b.isDocument {
+			b.buf.WriteString(quoteIdentifier(docColumn))
+		} else {
+			b.buf.WriteString("*")
+		}
+		return nil
+	}
+	if b.isDocument {
+		b.buf.WriteString("JSON_OBJECT(")
+	}
+	for i, p := range projection {
+		if i > 0 {
+			b.buf.WriteString(", ")
+		}
+		if b.isDocument {
+			alias := p.GetAlias()
+			if alias == "" {
+				alias = projectionName(p.GetSource())
+				if alias == "" {
+					return errors.Trace(errBadProjection)
+				}
+			}
+			b.buf.WriteString(quoteString(alias))
+			b.buf.WriteString(", ")
+		}
+		if err := b.writeExpr(p.GetSource()); err != nil {
+			return errors.Trace(err)
+		}
+		if !b.isDocument && p.GetAlias() != "" {
+			b.buf.WriteString(" AS ")
+			b.buf.WriteString(quoteIdentifier(p.GetAlias()))
+		}
+	}
+	if b.isDocument {
+		b.buf.WriteString(") AS ")
+		b.buf.WriteString(quoteIdentifier(docColumn))
+	}
+	return nil
+}
+
+// projectionName returns the name of a document member projected without alias,
+// which is the last member of the document path.
+func projectionName(expr *Mysqlx_Expr.Expr) string {
+	if expr.GetType() != Mysqlx_Expr.Expr_IDENT {
+		return ""
+	}
+	path := expr.GetIdentifier().GetDocumentPath()
+	if len(path) == 0 || path[len(path)-1].GetType() != Mysqlx_Expr.DocumentPathItem_MEMBER {
+		return ""
+	}
+	return path[len(path)-1].GetValue()
+}
+
+// buildInsert builds the INSERT statement of Mysqlx.Crud.Insert. For a collection, each row
+// is a document, and a unique "_id" is generated if the document doesn't have one.
+func buildInsert(msg *Mysqlx_Crud.Insert) (string, error) {
+	b := newCrudBuilder(msg.GetDataModel(), msg.GetArgs())
+	b.buf.WriteString("INSERT INTO ")
+	b.writeCollection(msg.GetCollection())
+	if b.isDocument {
+		if len(msg.GetProjection()) > 0 {
+			return "", errBadProjection.Gen("Invalid projection for document operation")
+		}
+		b.buf.WriteString(" (" + quoteIdentifier(docColumn) + ")")
+	} else if len(msg.GetProjection()) > 0 {
+		b.buf.WriteString(" (")
+		for i, col := range msg.GetProjection() {
+			if i > 0 {
+				b.buf.WriteString(", ")
+			}
+			b.buf.WriteString(quoteIdentifier(col.GetName()))
+		}
+		b.buf.WriteString(")")
+	}
+	b.buf.WriteString(" VALUES ")
+	for i, row := range msg.GetRow() {
+		if i > 0 {
+			b.buf.WriteString(", ")
+		}
+		fields := row.GetField()
+		if (b.isDocument && len(fields) != 1) ||
+			(len(msg.GetProjection()) > 0 && len(fields) != len(msg.GetProjection())) {
+			return "", errors.Trace(errBadInsertData)
+		}
+		b.buf.WriteString("(")
+		if b.isDocument {
+			b.buf.WriteString("JSON_INSERT(CAST(")
+			if err := b.writeExpr(fields[0]); err != nil {
+				return "", errors.Trace(err)
+			}
+			b.buf.WriteString(" AS JSON), '$._id', REPLACE(UUID(), '-', ''))")
+		} else {
+			for j, field := range fields {
+				if j > 0 {
+					b.buf.WriteString(", ")
+				}
+				if err := b.writeExpr(field); err != nil {
+					return "", errors.Trace(err)
+				}
+			}
+		}
+		b.buf.WriteString(")")
+	}
+	return b.buf.String(), nil
+}
+
+// buildUpdate builds the UPDATE statement of Mysqlx.Crud.Update. The item operations on the
+// same column are folded into nested JSON functions, so they are applied in order.
+func buildUpdate(msg *Mysqlx_Crud.Update) (string, error) {
+	b := newCrudBuilder(msg.GetDataModel(), msg.GetArgs())
+	b.buf.WriteString("UPDATE ")
+	b.writeCollection(msg.GetCollection())
+	var (
+		columns []string
+		values  = make(map[string]string)
+	)
+	for _, op := range msg.GetOperation() {
+		column := op.GetSource().GetName()
+		if b.isDocument {
+			if column != "" {
+				return "", errors.Trace(errBadTypeOfUpdate)
+			}
+			column = docColumn
+		}
+		value, ok := values[column]
+		if !ok {
+			columns = append(columns, column)
+			value = quoteIdentifier(column)
+		}
+		value, err := b.updateValue(op, value)
+		if err != nil {
+			return "", errors.Trace(err)
+		}
+		values[column] = value
+	}
+	for i, column := range columns {
+		if i == 0 {
+			b.buf.WriteString(" SET ")
+		} else {
+			b.buf.WriteString(", ")
+		}
+		b.buf.WriteString(quoteIdentifier(column) + " = " + values[column])
+	}
+	if err := b.writeCriteria(msg.GetCriteria()); err != nil {
+		return "", errors.Trace(err)
+	}
+	if err := b.writeOrder(msg.GetOrder()); err != nil {
+		return "", errors.Trace(err)
+	}
+	if err := b.writeLimit(msg.GetLimit(), false); err != nil {
+		return "", errors.Trace(err)
+	}
+	return b.buf.String(), nil
+}
+
+// updateValue returns the new value of the column after the update operation is applied,
+// the old value is the column or the result of previous operations on it.
+func (b *crudBuilder) updateValue(op *Mysqlx_Crud.UpdateOperation, old string) (string, error) {
+	var fn string
+	switch op.GetOperation() {
+	case Mysqlx_Crud.UpdateOperation_SET:
+		if b.isDocument || len(op.GetSource().GetDocumentPath()) > 0 {
+			return "", errors.Trace(errBadTypeOfUpdate)
+		}
+		value, err := generateExpr(op.GetValue(), b.args, b.isDocument)
+		return value, errors.Trace(err)
+	case Mysqlx_Crud.UpdateOperation_ITEM_SET:
+		fn = "JSON_SET"
+	case Mysqlx_Crud.UpdateOperation_ITEM_REPLACE:
+		fn = "JSON_REPLACE"
+	case Mysqlx_Crud.UpdateOperation_ITEM_REMOVE:
+		fn = "JSON_REMOVE"
+	case Mysqlx_Crud.UpdateOperation_ITEM_MERGE:
+		value, err := generateExpr(op.GetValue(), b.args, b.isDocument)
+		if err != nil {
+			return "", errors.Trace(err)
+		}
+		return "JSON_MERGE(" + old + ", " + value + ")", nil
+	default:
+		return "", errors.Trace(errBadTypeOfUpdate)
+	}
+	items := op.GetSource().GetDocumentPath()
+	if len(items) == 0 {
+		return "", errors.Trace(errBadMemberToUpdate)
+	}
+	path, err := documentPath(items)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if b.isDocument && path == "$._id" {
+		return "", errors.Trace(errBadMemberToUpdate)
+	}
+	if op.GetOperation() == Mysqlx_Crud.UpdateOperation_ITEM_REMOVE {
+		return fn + "(" + old + ", " + quoteString(path) + ")", nil
+	}
+	value, err := generateExpr(op.GetValue(), b.args, b.isDocument)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return fn + "(" + old + ", " + quoteString(path) + ", " + value + ")", nil
+}
+
+// buildDelete builds the DELETE statement of Mysqlx.Crud.Delete.
+func buildDelete(msg *Mysqlx_Crud.Delete) (string, error) {
+	b := newCrudBuilder(msg.GetDataModel(), msg.GetArgs())
+	b.buf.WriteString("DELETE FROM ")
+	b.writeCollection(msg.GetCollection())
+	if err := b.writeCriteria(msg.GetCriteria()); err != nil {
+		return "", errors.Trace(err)
+	}
+	if err := b.writeOrder(msg.GetOrder()); err != nil {
+		return "", errors.Trace(err)
+	}
+	if err := b.writeLimit(msg.GetLimit(), false); err != nil {
+		return "", errors.Trace(err)
+	}
+	return b.buf.String(), nil
+}
@@ -0,0 +1,415 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xserver
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tipb/go-mysqlx/Datatypes"
+	"github.com/pingcap/tipb/go-mysqlx/Expr"
+)
+
+// docColumn is the column of a collection which stores the documents.
+const docColumn = "doc"
+
+// exprGenerator generates the SQL text of Mysqlx.Expr.Expr.
+type exprGenerator struct {
+	buf bytes.Buffer
+	// args are the values of the placeholders.
+	args []*Mysqlx_Datatypes.Scalar
+	// isDocument indicates the expression is on a collection, where a document path
+	// without column name refers to the documents.
+	isDocument bool
+}
+
+// binaryOperators maps the binary operators to SQL.
+var binaryOperators = map[string]string{
+	"==":         "=",
+	"!=":         "!=",
+	"<>":         "<>",
+	">":          ">",
+	">=":         ">=",
+	"<":          "<",
+	"<=":         "<=",
+	"&&":         "AND",
+	"||":         "OR",
+	"xor":        "XOR",
+	"+":          "+",
+	"-":          "-",
+	"*":          "*",
+	"/":          "/",
+	"div":        "DIV",
+	"%":          "%",
+	"&":          "&",
+	"|":          "|",
+	"^":          "^",
+	"<<":         "<<",
+	">>":         ">>",
+	"is":         "IS",
+	"is_not":     "IS NOT",
+	"regexp":     "REGEXP",
+	"not_regexp": "NOT REGEXP",
+}
+
+// unaryOperators maps the unary operators to SQL.
+var unaryOperators = map[string]string{
+	"!":          "NOT ",
+	"not":        "NOT ",
+	"sign_minus": "-",
+	"sign_plus":  "+",
+	"~":          "~",
+}
+
+var (
+	// castTypeRegexp matches the target types of CAST.
+	castTypeRegexp = regexp.MustCompile(`^(?i)(BINARY|CHAR|DATE|DATETIME|TIME|JSON|SIGNED( INTEGER)?|UNSIGNED( INTEGER)?)(\(\d+\))?$|^(?i)DECIMAL(\(\d+(,\d+)?\))?$`)
+	// intervalUnitRegexp matches the units of INTERVAL.
+	intervalUnitRegexp = regexp.MustCompile(`^(?i)(MICROSECOND|SECOND|MINUTE|HOUR|DAY|WEEK|MONTH|QUARTER|YEAR|SECOND_MICROSECOND|` +
+		`MINUTE_MICROSECOND|MINUTE_SECOND|HOUR_MICROSECOND|HOUR_SECOND|HOUR_MINUTE|DAY_MICROSECOND|DAY_SECOND|DAY_MINUTE|DAY_HOUR|YEAR_MONTH)$`)
+	// identifierRegexp matches the names of functions and document path members which needn't be quoted.
+	identifierRegexp = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$]*$`)
+)
+
+// generateExpr generates the SQL text of the expression.
+func generateExpr(expr *Mysqlx_Expr.Expr, args []*Mysqlx_Datatypes.Scalar, isDocument bool) (string, error) {
+	g := &exprGenerator{args: args, isDocument: isDocument}
+	if err := g.generate(expr); err != nil {
+		return "", errors.Trace(err)
+	}
+	return g.buf.String(), nil
+}
+
+func (g *exprGenerator) generate(expr *Mysqlx_Expr.Expr) error {
+	switch expr.GetType() {
+	case Mysqlx_Expr.Expr_IDENT:
+		return errors.Trace(g.generateIdentifier(expr.GetIdentifier()))
+	case Mysqlx_Expr.Expr_LITERAL:
+		return errors.Trace(g.generateLiteral(expr.GetLiteral()))
+	case Mysqlx_Expr.Expr_PLACEHOLDER:
+		pos := int(expr.GetPosition())
+		if pos >= len(g.args) {
+			return errExprBadValue.GenByArgs("Invalid value of placeholder")
+		}
+		return errors.Trace(g.generateLiteral(g.args[pos]))
+	case Mysqlx_Expr.Expr_FUNC_CALL:
+		return errors.Trace(g.generateFunctionCall(expr.GetFunctionCall()))
+	case Mysqlx_Expr.Expr_OPERATOR:
+		return errors.Trace(g.generateOperator(expr.GetOperator()))
+	case Mysqlx_Expr.Expr_OBJECT:
+		g.buf.WriteString("JSON_OBJECT(")
+		for i, fld := range expr.GetObject().GetFld() {
+			if i > 0 {
+				g.buf.WriteString(", ")
+			}
+			g.buf.WriteString(quoteString(fld.GetKey()))
+			g.buf.WriteString(", ")
+			if err := g.generate(fld.GetValue()); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		g.buf.WriteString(")")
+		return nil
+	case Mysqlx_Expr.Expr_ARRAY:
+		g.buf.WriteString("JSON_ARRAY(")
+		if err := g.generateList(expr.GetArray().GetValue()); err != nil {
+			return errors.Trace(err)
+		}
+		g.buf.WriteString(")")
+		return nil
+	default:
+		return errExprBadValue.GenByArgs("Invalid value for Mysqlx::Expr::Expr_Type " + expr.GetType().String())
+	}
+}
+
+func (g *exprGenerator) generateList(exprs []*Mysqlx_Expr.Expr) error {
+	for i, expr := range exprs {
+		if i > 0 {
+			g.buf.WriteString(", ")
+		}
+		if err := g.generate(expr); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// generateIdentifier generates a column, or the value at the document path of a column.
+func (g *exprGenerator) generateIdentifier(id *Mysqlx_Expr.ColumnIdentifier) error {
+	if len(id.GetDocumentPath()) == 0 {
+		if id.GetName() == "" && g.isDocument {
+			// An empty identifier refers to the whole document.
+			g.buf.WriteString(quoteIdentifier(docColumn))
+			return nil
+		}
+		if id.GetName() == "" {
+			return errExprBadValue.GenByArgs("Column name is required if document path is not specified")
+		}
+		g.generateColumnName(id.GetSchemaName(), id.GetTableName(), id.GetName())
+		return nil
+	}
+	path, err := documentPath(id.GetDocumentPath())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	column := id.GetName()
+	if column == "" {
+		if !g.isDocument {
+			return errExprBadValue.GenByArgs("Column name is required for table")
+		}
+		column = docColumn
+	}
+	if path == "$" {
+		g.generateColumnName(id.GetSchemaName(), id.GetTableName(), column)
+		return nil
+	}
+	g.buf.WriteString("JSON_EXTRACT(")
+	g.generateColumnName(id.GetSchemaName(), id.GetTableName(), column)
+	g.buf.WriteString(", ")
+	g.buf.WriteString(quoteString(path))
+	g.buf.WriteString(")")
+	return nil
+}
+
+func (g *exprGenerator) generateColumnName(schema, table, column string) {
+	if table != "" {
+		if schema != "" {
+			g.buf.WriteString(quoteIdentifier(schema))
+			g.buf.WriteString(".")
+		}
+		g.buf.WriteString(quoteIdentifier(table))
+		g.buf.WriteString(".")
+	}
+	g.buf.WriteString(quoteIdentifier(column))
+}
+
+// documentPath converts the document path items to JSON path expression like "$.a[0].b".
+func documentPath(items []*Mysqlx_Expr.DocumentPathItem) (string, error) {
+	path := "$"
+	for _, item := range items {
+		switch item.GetType() {
+		case Mysqlx_Expr.DocumentPathItem_MEMBER:
+			if identifierRegexp.MatchString(item.GetValue()) {
+				path += "." + item.GetValue()
+			} else {
+				path += "." + strconv.Quote(item.GetValue())
+			}
+		case Mysqlx_Expr.DocumentPathItem_MEMBER_ASTERISK:
+			path += ".*"
+		case Mysqlx_Expr.DocumentPathItem_ARRAY_INDEX:
+			path += "[" + strconv.FormatUint(uint64(item.GetIndex()), 10) + "]"
+		case Mysqlx_Expr.DocumentPathItem_ARRAY_INDEX_ASTERISK:
+			path += "[*]"
+		case Mysqlx_Expr.DocumentPathItem_DOUBLE_ASTERISK:
+			path += "**"
+		default:
+			return "", errExprBadValue.GenByArgs("Invalid document path type " + item.GetType().String())
+		}
+	}
+	return path, nil
+}
+
+func (g *exprGenerator) generateLiteral(s *Mysqlx_Datatypes.Scalar) error {
+	switch s.GetType() {
+	case Mysqlx_Datatypes.Scalar_V_SINT:
+		g.buf.WriteString(strconv.FormatInt(s.GetVSignedInt(), 10))
+	case Mysqlx_Datatypes.Scalar_V_UINT:
+		g.buf.WriteString(strconv.FormatUint(s.GetVUnsignedInt(), 10))
+	case Mysqlx_Datatypes.Scalar_V_NULL:
+		g.buf.WriteString("NULL")
+	case Mysqlx_Datatypes.Scalar_V_OCTETS:
+		if s.GetVOctets().GetContentType() == contentTypeJSON {
+			g.buf.WriteString("CAST(" + quoteString(string(s.GetVOctets().GetValue())) + " AS JSON)")
+		} else {
+			g.buf.WriteString(quoteString(string(s.GetVOctets().GetValue())))
+		}
+	case Mysqlx_Datatypes.Scalar_V_DOUBLE:
+		g.buf.WriteString(strconv.FormatFloat(s.GetVDouble(), 'g', -1, 64))
+	case Mysqlx_Datatypes.Scalar_V_FLOAT:
+		g.buf.WriteString(strconv.FormatFloat(float64(s.GetVFloat()), 'g', -1, 32))
+	case Mysqlx_Datatypes.Scalar_V_BOOL:
+		if s.GetVBool() {
+			g.buf.WriteString("TRUE")
+		} else {
+			g.buf.WriteString("FALSE")
+		}
+	case Mysqlx_Datatypes.Scalar_V_STRING:
+		g.buf.WriteString(quoteString(string(s.GetVString().GetValue())))
+	default:
+		return errExprBadValue.GenByArgs("Invalid value for Mysqlx::Datatypes::Scalar::Type " + s.GetType().String())
+	}
+	return nil
+}
+
+func (g *exprGenerator) generateFunctionCall(f *Mysqlx_Expr.FunctionCall) error {
+	name := f.GetName()
+	if !identifierRegexp.MatchString(name.GetName()) {
+		return errExprBadValue.GenByArgs("Invalid function name " + name.GetName())
+	}
+	if name.GetSchemaName() != "" {
+		g.buf.WriteString(quoteIdentifier(name.GetSchemaName()))
+		g.buf.WriteString(".")
+	}
+	g.buf.WriteString(name.GetName())
+	g.buf.WriteString("(")
+	if err := g.generateList(f.GetParam()); err != nil {
+		return errors.Trace(err)
+	}
+	g.buf.WriteString(")")
+	return nil
+}
+
+func (g *exprGenerator) generateOperator(op *Mysqlx_Expr.Operator) error {
+	name, params := op.GetName(), op.GetParam()
+	if sqlOp, ok := binaryOperators[name]; ok {
+		if name == "*" && len(params) == 0 {
+			// "*" without operands is the asterisk, like the one in COUNT(*).
+			g.buf.WriteString("*")
+			return nil
+		}
+		return errors.Trace(g.generateInfix(name, sqlOp, params))
+	}
+	if sqlOp, ok := unaryOperators[name]; ok {
+		if len(params) != 1 {
+			return errExprBadNumArgs.GenByArgs(name)
+		}
+		g.buf.WriteString("(" + sqlOp)
+		if err := g.generate(params[0]); err != nil {
+			return errors.Trace(err)
+		}
+		g.buf.WriteString(")")
+		return nil
+	}
+	switch name {
+	case "in", "not_in":
+		if len(params) < 2 {
+			return errExprBadNumArgs.GenByArgs(name)
+		}
+		g.buf.WriteString("(")
+		if err := g.generate(params[0]); err != nil {
+			return errors.Trace(err)
+		}
+		if name == "in" {
+			g.buf.WriteString(" IN (")
+		} else {
+			g.buf.WriteString(" NOT IN (")
+		}
+		if err := g.generateList(params[1:]); err != nil {
+			return errors.Trace(err)
+		}
+		g.buf.WriteString("))")
+	case "like", "not_like":
+		if len(params) != 2 && len(params) != 3 {
+			return errExprBadNumArgs.GenByArgs(name)
+		}
+		sqlOp := "LIKE"
+		if name == "not_like" {
+			sqlOp = "NOT LIKE"
+		}
+		if len(params) == 2 {
+			return errors.Trace(g.generateInfix(name, sqlOp, params))
+		}
+		g.buf.WriteString("(")
+		if err := g.generateInfix(name, sqlOp, params[:2]); err != nil {
+			return errors.Trace(err)
+		}
+		g.buf.WriteString(" ESCAPE ")
+		if err := g.generate(params[2]); err != nil {
+			return errors.Trace(err)
+		}
+		g.buf.WriteString(")")
+	case "between", "not_between":
+		if len(params) != 3 {
+			return errExprBadNumArgs.GenByArgs(name)
+		}
+		g.buf.WriteString("(")
+		if err := g.generate(params[0]); err != nil {
+			return errors.Trace(err)
+		}
+		if name == "between" {
+			g.buf.WriteString(" BETWEEN ")
+		} else {
+			g.buf.WriteString(" NOT BETWEEN ")
+		}
+		if err := g.generate(params[1]); err != nil {
+			return errors.Trace(err)
+		}
+		g.buf.WriteString(" AND ")
+		if err := g.generate(params[2]); err != nil {
+			return errors.Trace(err)
+		}
+		g.buf.WriteString(")")
+	case "cast":
+		if len(params) != 2 {
+			return errExprBadNumArgs.GenByArgs(name)
+		}
+		tp, ok := literalString(params[1])
+		if !ok || !castTypeRegexp.MatchString(tp) {
+			return errExprBadValue.GenByArgs("CAST type invalid")
+		}
+		g.buf.WriteString("CAST(")
+		if err := g.generate(params[0]); err != nil {
+			return errors.Trace(err)
+		}
+		g.buf.WriteString(" AS " + strings.ToUpper(tp) + ")")
+	case "date_add", "date_sub":
+		if len(params) != 3 {
+			return errExprBadNumArgs.GenByArgs(name)
+		}
+		unit, ok := literalString(params[2])
+		if !ok || !intervalUnitRegexp.MatchString(unit) {
+			return errExprBadValue.GenByArgs("DATE interval unit invalid")
+		}
+		g.buf.WriteString(strings.ToUpper(name) + "(")
+		if err := g.generate(params[0]); err != nil {
+			return errors.Trace(err)
+		}
+		g.buf.WriteString(", INTERVAL ")
+		if err := g.generate(params[1]); err != nil {
+			return errors.Trace(err)
+		}
+		g.buf.WriteString(" " + strings.ToUpper(unit) + ")")
+	default:
+		return errExprBadOperator.GenByArgs(name)
+	}
+	return nil
+}
+
+// generateInfix generates a binary operation like "(a + b)".
+func (g *exprGenerator) generateInfix(name, sqlOp string, params []*Mysqlx_Expr.Expr) error {
+	if len(params) != 2 {
+		return errExprBadNumArgs.GenByArgs(name)
+	}
+	g.buf.WriteString("(")
+	if err := g.generate(params[0]); err != nil {
+		return errors.Trace(err)
+	}
+	g.buf.WriteString(" " + sqlOp + " ")
+	if err := g.generate(params[1]); err != nil {
+		return errors.Trace(err)
+	}
+	g.buf.WriteString(")")
+	return nil
+}
+
+// literalString gets the string of a literal expression.
+func literalString(expr *Mysqlx_Expr.Expr) (string, bool) {
+	if expr.GetType() != Mysqlx_Expr.Expr_LITERAL {
+		return "", false
+	}
+	return scalarString(expr.GetLiteral())
+}
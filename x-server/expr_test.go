@@ -0,0 +1,164 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xserver
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tipb/go-mysqlx/Datatypes"
+	"github.com/pingcap/tipb/go-mysqlx/Expr"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testExprSuite{})
+
+type testExprSuite struct{}
+
+func member(names ...string) []*Mysqlx_Expr.DocumentPathItem {
+	items := make([]*Mysqlx_Expr.DocumentPathItem, 0, len(names))
+	for _, name := range names {
+		items = append(items, &Mysqlx_Expr.DocumentPathItem{
+			Type:  Mysqlx_Expr.DocumentPathItem_MEMBER.Enum(),
+			Value: proto.String(name),
+		})
+	}
+	return items
+}
+
+func docIdent(names ...string) *Mysqlx_Expr.Expr {
+	return &Mysqlx_Expr.Expr{
+		Type:       Mysqlx_Expr.Expr_IDENT.Enum(),
+		Identifier: &Mysqlx_Expr.ColumnIdentifier{DocumentPath: member(names...)},
+	}
+}
+
+func columnIdent(name string) *Mysqlx_Expr.Expr {
+	return &Mysqlx_Expr.Expr{
+		Type:       Mysqlx_Expr.Expr_IDENT.Enum(),
+		Identifier: &Mysqlx_Expr.ColumnIdentifier{Name: proto.String(name)},
+	}
+}
+
+func literal(s *Mysqlx_Datatypes.Scalar) *Mysqlx_Expr.Expr {
+	return &Mysqlx_Expr.Expr{Type: Mysqlx_Expr.Expr_LITERAL.Enum(), Literal: s}
+}
+
+func scalarInt(v int64) *Mysqlx_Datatypes.Scalar {
+	return &Mysqlx_Datatypes.Scalar{Type: Mysqlx_Datatypes.Scalar_V_SINT.Enum(), VSignedInt: proto.Int64(v)}
+}
+
+func scalarStr(s string) *Mysqlx_Datatypes.Scalar {
+	return anyString(s).GetScalar()
+}
+
+func placeholder(pos uint32) *Mysqlx_Expr.Expr {
+	return &Mysqlx_Expr.Expr{Type: Mysqlx_Expr.Expr_PLACEHOLDER.Enum(), Position: proto.Uint32(pos)}
+}
+
+func operator(name string, params ...*Mysqlx_Expr.Expr) *Mysqlx_Expr.Expr {
+	return &Mysqlx_Expr.Expr{
+		Type:     Mysqlx_Expr.Expr_OPERATOR.Enum(),
+		Operator: &Mysqlx_Expr.Operator{Name: proto.String(name), Param: params},
+	}
+}
+
+func (s *testExprSuite) TestGenerateExpr(c *C) {
+	defer testleak.AfterTest(c)()
+	args := []*Mysqlx_Datatypes.Scalar{scalarInt(1), scalarStr("a'b")}
+	tests := []struct {
+		expr       *Mysqlx_Expr.Expr
+		isDocument bool
+		sql        string
+	}{
+		{docIdent("name"), true, "JSON_EXTRACT(`doc`, '$.name')"},
+		{docIdent("a", "b c"), true, `JSON_EXTRACT(` + "`doc`" + `, '$.a."b c"')`},
+		{docIdent(), true, "`doc`"},
+		{columnIdent("c1"), false, "`c1`"},
+		{literal(scalarInt(-3)), false, "-3"},
+		{literal(scalarStr("it's")), false, `'it\'s'`},
+		{literal(&Mysqlx_Datatypes.Scalar{Type: Mysqlx_Datatypes.Scalar_V_NULL.Enum()}), false, "NULL"},
+		{literal(&Mysqlx_Datatypes.Scalar{Type: Mysqlx_Datatypes.Scalar_V_DOUBLE.Enum(), VDouble: proto.Float64(1.5)}), false, "1.5"},
+		{literal(&Mysqlx_Datatypes.Scalar{
+			Type:    Mysqlx_Datatypes.Scalar_V_OCTETS.Enum(),
+			VOctets: &Mysqlx_Datatypes.Scalar_Octets{Value: []byte(`{"a":1}`), ContentType: proto.Uint32(contentTypeJSON)},
+		}), false, `CAST('{"a":1}' AS JSON)`},
+		{placeholder(1), false, `'a\'b'`},
+		{operator("==", docIdent("age"), placeholder(0)), true, "(JSON_EXTRACT(`doc`, '$.age') = 1)"},
+		{operator("&&", operator(">", columnIdent("a"), literal(scalarInt(1))), operator("!", columnIdent("b"))), false,
+			"((`a` > 1) AND (NOT `b`))"},
+		{operator("in", columnIdent("a"), literal(scalarInt(1)), literal(scalarInt(2))), false, "(`a` IN (1, 2))"},
+		{operator("not_between", columnIdent("a"), literal(scalarInt(1)), literal(scalarInt(2))), false, "(`a` NOT BETWEEN 1 AND 2)"},
+		{operator("like", columnIdent("a"), literal(scalarStr("x%")), literal(scalarStr("!"))), false, "((`a` LIKE 'x%') ESCAPE '!')"},
+		{operator("cast", columnIdent("a"), literal(scalarStr("signed"))), false, "CAST(`a` AS SIGNED)"},
+		{operator("date_add", columnIdent("a"), literal(scalarInt(1)), literal(scalarStr("day"))), false, "DATE_ADD(`a`, INTERVAL 1 DAY)"},
+		{&Mysqlx_Expr.Expr{
+			Type: Mysqlx_Expr.Expr_FUNC_CALL.Enum(),
+			FunctionCall: &Mysqlx_Expr.FunctionCall{
+				Name:  &Mysqlx_Expr.Identifier{Name: proto.String("count")},
+				Param: []*Mysqlx_Expr.Expr{operator("*")},
+			},
+		}, false, "count(*)"},
+		{&Mysqlx_Expr.Expr{
+			Type: Mysqlx_Expr.Expr_OBJECT.Enum(),
+			Object: &Mysqlx_Expr.Object{Fld: []*Mysqlx_Expr.Object_ObjectField{
+				{Key: proto.String("k"), Value: &Mysqlx_Expr.Expr{
+					Type:  Mysqlx_Expr.Expr_ARRAY.Enum(),
+					Array: &Mysqlx_Expr.Array{Value: []*Mysqlx_Expr.Expr{literal(scalarInt(1)), literal(scalarStr("x"))}},
+				}},
+			}},
+		}, false, "JSON_OBJECT('k', JSON_ARRAY(1, 'x'))"},
+	}
+	for _, tt := range tests {
+		sql, err := generateExpr(tt.expr, args, tt.isDocument)
+		c.Assert(err, IsNil, Commentf("%s", tt.sql))
+		c.Assert(sql, Equals, tt.sql)
+	}
+
+	errTests := []struct {
+		expr       *Mysqlx_Expr.Expr
+		isDocument bool
+		err        *terror.Error
+	}{
+		{placeholder(2), false, errExprBadValue},
+		{docIdent("a"), false, errExprBadValue},
+		{operator("foo", columnIdent("a")), false, errExprBadOperator},
+		{operator("==", columnIdent("a")), false, errExprBadNumArgs},
+		{operator("cast", columnIdent("a"), literal(scalarStr("int); drop table t"))), false, errExprBadValue},
+		{&Mysqlx_Expr.Expr{
+			Type: Mysqlx_Expr.Expr_FUNC_CALL.Enum(),
+			FunctionCall: &Mysqlx_Expr.FunctionCall{
+				Name: &Mysqlx_Expr.Identifier{Name: proto.String("sleep(1);")},
+			},
+		}, false, errExprBadValue},
+		{&Mysqlx_Expr.Expr{Type: Mysqlx_Expr.Expr_VARIABLE.Enum(), Variable: proto.String("v")}, false, errExprBadValue},
+	}
+	for _, tt := range errTests {
+		_, err := generateExpr(tt.expr, args, tt.isDocument)
+		c.Assert(tt.err.Equal(err), IsTrue, Commentf("%v", err))
+	}
+}
+
+func (s *testExprSuite) TestQuoteString(c *C) {
+	defer testleak.AfterTest(c)()
+	c.Assert(quoteString("a\x00b\n\r\x1a'\\"), Equals, `'a\0b\n\r\Z\'\\'`)
+	c.Assert(quoteIdentifier("a`b"), Equals, "`a``b`")
+}
@@ -0,0 +1,82 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xserver
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
+)
+
+const (
+	defaultReaderSize = 16 * 1024
+	defaultWriterSize = 16 * 1024
+
+	// maxPayloadLen is the max length of a single message, the same as the
+	// default mysqlx_max_allowed_packet of MySQL.
+	maxPayloadLen = 64 * 1024 * 1024
+)
+
+// packetIO is a helper to read and write messages in x protocol format.
+type packetIO struct {
+	rb *bufio.Reader
+	wb *bufio.Writer
+}
+
+func newPacketIO(conn net.Conn) *packetIO {
+	return &packetIO{
+		rb: bufio.NewReaderSize(conn, defaultReaderSize),
+		wb: bufio.NewWriterSize(conn, defaultWriterSize),
+	}
+}
+
+// readPacket reads a message and returns its type and payload.
+func (p *packetIO) readPacket() (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(p.rb, header[:]); err != nil {
+		return 0, nil, errors.Trace(err)
+	}
+	// The length contains the type byte.
+	length := binary.LittleEndian.Uint32(header[:4])
+	if length < 1 || length > maxPayloadLen {
+		return 0, nil, errors.Trace(mysql.ErrMalformPacket)
+	}
+	payload := make([]byte, length-1)
+	if _, err := io.ReadFull(p.rb, payload); err != nil {
+		return 0, nil, errors.Trace(err)
+	}
+	return header[4], payload, nil
+}
+
+// writePacket writes a message, it won't flush the stream.
+func (p *packetIO) writePacket(tp byte, payload []byte) error {
+	var header [5]byte
+	binary.LittleEndian.PutUint32(header[:4], uint32(len(payload)+1))
+	header[4] = tp
+	if _, err := p.wb.Write(header[:]); err != nil {
+		return errors.Trace(mysql.ErrBadConn)
+	}
+	if _, err := p.wb.Write(payload); err != nil {
+		return errors.Trace(mysql.ErrBadConn)
+	}
+	return nil
+}
+
+func (p *packetIO) flush() error {
+	return errors.Trace(p.wb.Flush())
+}
@@ -0,0 +1,320 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xserver
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/server"
+	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tipb/go-mysqlx"
+	"github.com/pingcap/tipb/go-mysqlx/Resultset"
+)
+
+// Flags of Mysqlx.Resultset.ColumnMetaData.
+const (
+	flagZeroFill      = 0x0001 // for SINT, UINT, FLOAT, DOUBLE and DECIMAL.
+	flagUnsigned      = 0x0002 // for FLOAT, DOUBLE and DECIMAL.
+	flagRightPad      = 0x0001 // for BYTES.
+	flagTimestamp     = 0x0001 // for DATETIME.
+	flagNotNull       = 0x0010
+	flagPrimaryKey    = 0x0020
+	flagUniqueKey     = 0x0040
+	flagMultipleKey   = 0x0080
+	flagAutoIncrement = 0x0100
+)
+
+// contentTypeJSON is the content type of BYTES column for JSON.
+const contentTypeJSON = 2
+
+// columnMetaData converts the ColumnInfo of MySQL protocol to Mysqlx.Resultset.ColumnMetaData.
+func columnMetaData(col *server.ColumnInfo) *Mysqlx_Resultset.ColumnMetaData {
+	var flags uint32
+	tp := Mysqlx_Resultset.ColumnMetaData_BYTES
+	switch col.Type {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong, mysql.TypeYear:
+		tp = Mysqlx_Resultset.ColumnMetaData_SINT
+		if mysql.HasUnsignedFlag(uint(col.Flag)) {
+			tp = Mysqlx_Resultset.ColumnMetaData_UINT
+		}
+		if mysql.HasZerofillFlag(uint(col.Flag)) {
+			flags |= flagZeroFill
+		}
+	case mysql.TypeFloat, mysql.TypeDouble, mysql.TypeNewDecimal, mysql.TypeDecimal:
+		switch col.Type {
+		case mysql.TypeFloat:
+			tp = Mysqlx_Resultset.ColumnMetaData_FLOAT
+		case mysql.TypeDouble:
+			tp = Mysqlx_Resultset.ColumnMetaData_DOUBLE
+		default:
+			tp = Mysqlx_Resultset.ColumnMetaData_DECIMAL
+		}
+		if mysql.HasUnsignedFlag(uint(col.Flag)) {
+			flags |= flagUnsigned
+		}
+		if mysql.HasZerofillFlag(uint(col.Flag)) {
+			flags |= flagZeroFill
+		}
+	case mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp, mysql.TypeNewDate:
+		tp = Mysqlx_Resultset.ColumnMetaData_DATETIME
+		if col.Type == mysql.TypeTimestamp {
+			flags |= flagTimestamp
+		}
+	case mysql.TypeDuration:
+		tp = Mysqlx_Resultset.ColumnMetaData_TIME
+	case mysql.TypeSet:
+		tp = Mysqlx_Resultset.ColumnMetaData_SET
+	case mysql.TypeEnum:
+		tp = Mysqlx_Resultset.ColumnMetaData_ENUM
+	case mysql.TypeBit:
+		tp = Mysqlx_Resultset.ColumnMetaData_BIT
+	case mysql.TypeString:
+		flags |= flagRightPad
+	}
+	if mysql.HasNotNullFlag(uint(col.Flag)) {
+		flags |= flagNotNull
+	}
+	if mysql.HasPriKeyFlag(uint(col.Flag)) {
+		flags |= flagPrimaryKey
+	}
+	if mysql.HasUniKeyFlag(uint(col.Flag)) {
+		flags |= flagUniqueKey
+	}
+	if mysql.HasMultipleKeyFlag(uint(col.Flag)) {
+		flags |= flagMultipleKey
+	}
+	if mysql.HasAutoIncrementFlag(uint(col.Flag)) {
+		flags |= flagAutoIncrement
+	}
+	meta := &Mysqlx_Resultset.ColumnMetaData{
+		Type:          tp.Enum(),
+		Name:          []byte(col.Name),
+		OriginalName:  []byte(col.OrgName),
+		Table:         []byte(col.Table),
+		OriginalTable: []byte(col.OrgTable),
+		Schema:        []byte(col.Schema),
+		Catalog:       []byte("def"),
+		Length:        proto.Uint32(col.ColumnLength),
+		Flags:         proto.Uint32(flags),
+	}
+	switch tp {
+	case Mysqlx_Resultset.ColumnMetaData_BYTES, Mysqlx_Resultset.ColumnMetaData_ENUM, Mysqlx_Resultset.ColumnMetaData_SET:
+		meta.Collation = proto.Uint64(uint64(col.Charset))
+	case Mysqlx_Resultset.ColumnMetaData_FLOAT, Mysqlx_Resultset.ColumnMetaData_DOUBLE, Mysqlx_Resultset.ColumnMetaData_DECIMAL:
+		meta.FractionalDigits = proto.Uint32(uint32(col.Decimal))
+	}
+	if col.Type == mysql.TypeJSON {
+		meta.ContentType = proto.Uint32(contentTypeJSON)
+	}
+	return meta
+}
+
+// dumpRow encodes the row in the format of Mysqlx.Resultset.Row, a NULL value is an empty field.
+func dumpRow(metas []*Mysqlx_Resultset.ColumnMetaData, row []types.Datum) (*Mysqlx_Resultset.Row, error) {
+	fields := make([][]byte, 0, len(row))
+	for i, d := range row {
+		field, err := dumpValue(metas[i].GetType(), d)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		fields = append(fields, field)
+	}
+	return &Mysqlx_Resultset.Row{Field: fields}, nil
+}
+
+// dumpValue encodes a value as the field of Mysqlx.Resultset.Row:
+//
+//	SINT: zigzag encoded varint.
+//	UINT, BIT: varint.
+//	FLOAT, DOUBLE: little endian IEEE 754.
+//	BYTES, ENUM: the bytes followed by '\0'.
+//	DATETIME: varints of year, month, day, hour, minute, second and microsecond.
+//	TIME: a sign byte, followed by varints of hour, minute, second and microsecond.
+//	DECIMAL: a scale byte, followed by BCD digits ending with a sign nibble.
+//	SET: each element is a varint length followed by the bytes.
+//
+// See: https://dev.mysql.com/doc/internals/en/x-protocol-messages-messages.html
+func dumpValue(tp Mysqlx_Resultset.ColumnMetaData_FieldType, d types.Datum) ([]byte, error) {
+	switch d.Kind() {
+	case types.KindNull:
+		return []byte{}, nil
+	case types.KindInt64:
+		if tp == Mysqlx_Resultset.ColumnMetaData_UINT {
+			return proto.EncodeVarint(uint64(d.GetInt64())), nil
+		}
+		return dumpZigzag(d.GetInt64()), nil
+	case types.KindUint64:
+		if tp == Mysqlx_Resultset.ColumnMetaData_SINT {
+			return dumpZigzag(int64(d.GetUint64())), nil
+		}
+		return proto.EncodeVarint(d.GetUint64()), nil
+	case types.KindFloat32, types.KindFloat64:
+		if tp == Mysqlx_Resultset.ColumnMetaData_FLOAT {
+			b := make([]byte, 4)
+			binary.LittleEndian.PutUint32(b, math.Float32bits(float32(d.GetFloat64())))
+			return b, nil
+		}
+		b := make([]byte, 8)
+		binary.LittleEndian.PutUint64(b, math.Float64bits(d.GetFloat64()))
+		return b, nil
+	case types.KindString, types.KindBytes:
+		return dumpBytes(d.GetBytes()), nil
+	case types.KindMysqlDecimal:
+		return dumpDecimal(d.GetMysqlDecimal().String()), nil
+	case types.KindMysqlTime:
+		return dumpDatetime(d.GetMysqlTime()), nil
+	case types.KindMysqlDuration:
+		return dumpTime(d.GetMysqlDuration()), nil
+	case types.KindMysqlEnum:
+		return dumpBytes([]byte(d.GetMysqlEnum().String())), nil
+	case types.KindMysqlSet:
+		return dumpSet(d.GetMysqlSet().String()), nil
+	case types.KindMysqlBit:
+		return proto.EncodeVarint(d.GetMysqlBit().Value), nil
+	case types.KindMysqlHex:
+		return dumpBytes([]byte(d.GetMysqlHex().ToString())), nil
+	case types.KindMysqlJSON:
+		return dumpBytes([]byte(d.GetMysqlJSON().String())), nil
+	default:
+		return nil, errors.Errorf("invalid type %v", d.Kind())
+	}
+}
+
+func dumpZigzag(v int64) []byte {
+	return proto.EncodeVarint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func dumpBytes(b []byte) []byte {
+	data := make([]byte, 0, len(b)+1)
+	data = append(data, b...)
+	return append(data, 0)
+}
+
+func dumpDatetime(t types.Time) []byte {
+	var data []byte
+	data = append(data, proto.EncodeVarint(uint64(t.Time.Year()))...)
+	data = append(data, proto.EncodeVarint(uint64(t.Time.Month()))...)
+	data = append(data, proto.EncodeVarint(uint64(t.Time.Day()))...)
+	if t.Type == mysql.TypeDate {
+		return data
+	}
+	data = append(data, proto.EncodeVarint(uint64(t.Time.Hour()))...)
+	data = append(data, proto.EncodeVarint(uint64(t.Time.Minute()))...)
+	data = append(data, proto.EncodeVarint(uint64(t.Time.Second()))...)
+	if us := t.Time.Microsecond(); us > 0 {
+		data = append(data, proto.EncodeVarint(uint64(us))...)
+	}
+	return data
+}
+
+func dumpTime(d types.Duration) []byte {
+	data := []byte{0}
+	dur := d.Duration
+	if dur < 0 {
+		data[0] = 1
+		dur = -dur
+	}
+	us := uint64(dur.Nanoseconds() / 1000)
+	data = append(data, proto.EncodeVarint(us/3600000000)...)
+	data = append(data, proto.EncodeVarint(us/60000000%60)...)
+	data = append(data, proto.EncodeVarint(us/1000000%60)...)
+	if us%1000000 > 0 {
+		data = append(data, proto.EncodeVarint(us%1000000)...)
+	}
+	return data
+}
+
+// dumpDecimal encodes the decimal string like "-12.345" to 0x03 0x12 0x34 0x5d.
+func dumpDecimal(s string) []byte {
+	sign := byte(0xc)
+	if strings.HasPrefix(s, "-") {
+		sign = 0xd
+		s = s[1:]
+	}
+	scale := 0
+	if pos := strings.IndexByte(s, '.'); pos >= 0 {
+		scale = len(s) - pos - 1
+		s = s[:pos] + s[pos+1:]
+	}
+	data := []byte{byte(scale)}
+	for i := 0; i < len(s); i += 2 {
+		if i+1 < len(s) {
+			data = append(data, (s[i]-'0')<<4|(s[i+1]-'0'))
+		} else {
+			data = append(data, (s[i]-'0')<<4|sign)
+			return data
+		}
+	}
+	return append(data, sign<<4)
+}
+
+// dumpSet encodes the set string like "a,b", an empty set is encoded as 0x01.
+func dumpSet(s string) []byte {
+	if s == "" {
+		return []byte{1}
+	}
+	var data []byte
+	for _, elem := range strings.Split(s, ",") {
+		data = append(data, proto.EncodeVarint(uint64(len(elem)))...)
+		data = append(data, elem...)
+	}
+	return data
+}
+
+// writeResultset writes the column meta data and rows of a result set.
+// If more is true, Mysqlx.Resultset.FetchDoneMoreResultsets is sent instead of
+// Mysqlx.Resultset.FetchDone, which indicates there are more result sets.
+func (cc *clientConn) writeResultset(rs server.ResultSet, more bool) error {
+	defer rs.Close()
+	// We need to call Next before we get columns.
+	// Otherwise, we will get incorrect columns info.
+	row, err := rs.Next()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	columns, err := rs.Columns()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	metas := make([]*Mysqlx_Resultset.ColumnMetaData, 0, len(columns))
+	for _, col := range columns {
+		meta := columnMetaData(col)
+		if err = cc.writeMessage(Mysqlx.ServerMessages_RESULTSET_COLUMN_META_DATA, meta); err != nil {
+			return errors.Trace(err)
+		}
+		metas = append(metas, meta)
+	}
+	for row != nil {
+		msg, err1 := dumpRow(metas, row)
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		if err = cc.writeMessage(Mysqlx.ServerMessages_RESULTSET_ROW, msg); err != nil {
+			return errors.Trace(err)
+		}
+		if row, err = rs.Next(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if more {
+		return errors.Trace(cc.writeMessage(Mysqlx.ServerMessages_RESULTSET_FETCH_DONE_MORE_RESULTSETS,
+			&Mysqlx_Resultset.FetchDoneMoreResultsets{}))
+	}
+	return errors.Trace(cc.writeMessage(Mysqlx.ServerMessages_RESULTSET_FETCH_DONE, &Mysqlx_Resultset.FetchDone{}))
+}
@@ -14,6 +14,7 @@
 package xserver
 
 import (
+	"crypto/tls"
 	"math/rand"
 	"net"
 	"sync"
@@ -24,6 +25,7 @@ import (
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/server"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/arena"
 )
@@ -34,9 +36,33 @@ var (
 	baseConnID uint32
 )
 
+var (
+	errBadMessage                = terror.ClassXServer.New(codeBadMessage, "Invalid message")
+	errCapabilitiesPrepareFailed = terror.ClassXServer.New(codeCapabilitiesPrepareFailed, "Capability prepare failed for '%s'")
+	errCapabilityNotFound        = terror.ClassXServer.New(codeCapabilityNotFound, "Capability '%s' doesn't exist")
+	errCmdNumArguments           = terror.ClassXServer.New(codeCmdNumArguments, "Invalid number of arguments, expected %d but got %d")
+	errCmdArgumentType           = terror.ClassXServer.New(codeCmdArgumentType, "Invalid type for argument '%s' at #%d (should be %s)")
+	errBadTypeOfUpdate           = terror.ClassXServer.New(codeBadTypeOfUpdate, "Invalid type of update operation for document")
+	errBadMemberToUpdate         = terror.ClassXServer.New(codeBadMemberToUpdate, "Forbidden update operation on '$._id' member")
+	errBadProjection             = terror.ClassXServer.New(codeBadProjection, "Invalid projection target name")
+	errBadInsertData             = terror.ClassXServer.New(codeBadInsertData, "Wrong number of fields in row being inserted")
+	errExprBadOperator           = terror.ClassXServer.New(codeExprBadOperator, "Invalid operator %s")
+	errExprBadNumArgs            = terror.ClassXServer.New(codeExprBadNumArgs, "Invalid number of arguments for operator %s")
+	errExprBadValue              = terror.ClassXServer.New(codeExprBadValue, "%s")
+	errInvalidAdminCommand       = terror.ClassXServer.New(codeInvalidAdminCommand, "Unknown mysqlx statement %s")
+	errExpectNotOpen             = terror.ClassXServer.New(codeExpectNotOpen, "Expect block currently not open")
+	errExpectFailed              = terror.ClassXServer.New(codeExpectFailed, "Expectation failed: no_error")
+	errExpectBadCondition        = terror.ClassXServer.New(codeExpectBadCondition, "Unknown condition key %d")
+	errInvalidNamespace          = terror.ClassXServer.New(codeInvalidNamespace, "Unknown namespace %s")
+	errNotSupportedAuthMode      = terror.ClassXServer.New(codeNotSupportedAuthMode, "Invalid authentication method %s")
+	errAccessDenied              = terror.ClassXServer.New(codeAccessDenied, mysql.MySQLErrName[mysql.ErrAccessDenied])
+)
+
 // Server is the MySQL X protocol server
 type Server struct {
 	cfg               *Config
+	driver            server.IDriver
+	tlsConfig         *tls.Config
 	listener          net.Listener
 	rwlock            *sync.RWMutex
 	concurrentLimiter *server.TokenLimiter
@@ -45,13 +71,21 @@ type Server struct {
 }
 
 // NewServer creates a new Server.
-func NewServer(cfg *Config) (s *Server, err error) {
+func NewServer(cfg *Config, driver server.IDriver) (s *Server, err error) {
 	s = &Server{
 		cfg:               cfg,
+		driver:            driver,
 		concurrentLimiter: server.NewTokenLimiter(tokenLimit),
 		rwlock:            &sync.RWMutex{},
 		stopListenerCh:    make(chan struct{}, 1),
 	}
+	if cfg.TLSCert != "" && cfg.TLSKey != "" {
+		cert, err1 := tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey)
+		if err1 != nil {
+			return nil, errors.Trace(err1)
+		}
+		s.tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
 	if cfg.Socket != "" {
 		cfg.SkipAuth = true
 		s.listener, err = net.Listen("unix", cfg.Socket)
@@ -62,7 +96,7 @@ func NewServer(cfg *Config) (s *Server, err error) {
 		return nil, errors.Trace(err)
 	}
 	rand.Seed(time.Now().UTC().UnixNano())
-	log.Infof("Server run MySQL Protocol Listen at [%s]", s.cfg.Addr)
+	log.Infof("Server run MySQL X Protocol Listen at [%s]", s.cfg.Addr)
 	return s, nil
 }
 
@@ -133,5 +167,59 @@ func (s *Server) newConn(conn net.Conn) *clientConn {
 	}
 	log.Infof("[%d] new x protocol connection %s", cc.connectionID, conn.RemoteAddr().String())
 	cc.salt = util.RandomBuf(20)
+	cc.pkt = newPacketIO(conn)
 	return cc
 }
+
+func (s *Server) skipAuth() bool {
+	return s.cfg.SkipAuth
+}
+
+// The error codes are the same as the ones of the MySQL X plugin.
+const (
+	codeBadMessage                = 5000
+	codeCapabilitiesPrepareFailed = 5001
+	codeCapabilityNotFound        = 5002
+	codeCmdNumArguments           = 5015
+	codeCmdArgumentType           = 5016
+	codeBadTypeOfUpdate           = 5051
+	codeBadMemberToUpdate         = 5052
+	codeBadProjection             = 5114
+	codeBadInsertData             = 5115
+	codeExprBadOperator           = 5150
+	codeExprBadNumArgs            = 5151
+	codeExprBadValue              = 5154
+	codeInvalidAdminCommand       = 5157
+	codeExpectNotOpen             = 5158
+	codeExpectFailed              = 5159
+	codeExpectBadCondition        = 5160
+	codeInvalidNamespace          = 5162
+
+	codeNotSupportedAuthMode = mysql.ErrNotSupportedAuthMode
+	codeAccessDenied         = mysql.ErrAccessDenied
+)
+
+func init() {
+	xserverMySQLErrCodes := map[terror.ErrCode]uint16{
+		codeBadMessage:                codeBadMessage,
+		codeCapabilitiesPrepareFailed: codeCapabilitiesPrepareFailed,
+		codeCapabilityNotFound:        codeCapabilityNotFound,
+		codeCmdNumArguments:           codeCmdNumArguments,
+		codeCmdArgumentType:           codeCmdArgumentType,
+		codeBadTypeOfUpdate:           codeBadTypeOfUpdate,
+		codeBadMemberToUpdate:         codeBadMemberToUpdate,
+		codeBadProjection:             codeBadProjection,
+		codeBadInsertData:             codeBadInsertData,
+		codeExprBadOperator:           codeExprBadOperator,
+		codeExprBadNumArgs:            codeExprBadNumArgs,
+		codeExprBadValue:              codeExprBadValue,
+		codeInvalidAdminCommand:       codeInvalidAdminCommand,
+		codeExpectNotOpen:             codeExpectNotOpen,
+		codeExpectFailed:              codeExpectFailed,
+		codeExpectBadCondition:        codeExpectBadCondition,
+		codeInvalidNamespace:          codeInvalidNamespace,
+		codeNotSupportedAuthMode:      codeNotSupportedAuthMode,
+		codeAccessDenied:              codeAccessDenied,
+	}
+	terror.ErrClassToMySQLCodes[terror.ClassXServer] = xserverMySQLErrCodes
+}
@@ -0,0 +1,286 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xserver
+
+import (
+	"encoding/json"
+	"net"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/ngaut/log"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/server"
+	"github.com/pingcap/tipb/go-mysqlx"
+	"github.com/pingcap/tipb/go-mysqlx/Crud"
+	"github.com/pingcap/tipb/go-mysqlx/Datatypes"
+	"github.com/pingcap/tipb/go-mysqlx/Expect"
+	"github.com/pingcap/tipb/go-mysqlx/Expr"
+	"github.com/pingcap/tipb/go-mysqlx/Resultset"
+	"github.com/pingcap/tipb/go-mysqlx/Session"
+	"github.com/pingcap/tipb/go-mysqlx/Sql"
+)
+
+var _ = Suite(&testServerSuite{})
+
+type testServerSuite struct {
+	store  kv.Storage
+	server *Server
+}
+
+func (s *testServerSuite) SetUpSuite(c *C) {
+	log.SetLevelByString("error")
+	store, err := tidb.NewStore("memory:///tmp/tidb_xserver")
+	c.Assert(err, IsNil)
+	s.store = store
+	_, err = tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+	s.server, err = NewServer(&Config{Addr: "127.0.0.1:0"}, server.NewTiDBDriver(store))
+	c.Assert(err, IsNil)
+	go s.server.Run()
+}
+
+func (s *testServerSuite) TearDownSuite(c *C) {
+	s.server.Close()
+	s.store.Close()
+}
+
+// testClient is a minimal X Protocol client.
+type testClient struct {
+	c    *C
+	conn net.Conn
+	pkt  *packetIO
+}
+
+func (s *testServerSuite) connect(c *C, schema string) *testClient {
+	conn, err := net.Dial("tcp", s.server.listener.Addr().String())
+	c.Assert(err, IsNil)
+	cli := &testClient{c: c, conn: conn, pkt: newPacketIO(conn)}
+	cli.send(Mysqlx.ClientMessages_SESS_AUTHENTICATE_START,
+		&Mysqlx_Session.AuthenticateStart{MechName: proto.String(authMySQL41)})
+	tp, _ := cli.recv()
+	c.Assert(tp, Equals, Mysqlx.ServerMessages_SESS_AUTHENTICATE_CONTINUE)
+	cli.send(Mysqlx.ClientMessages_SESS_AUTHENTICATE_CONTINUE,
+		&Mysqlx_Session.AuthenticateContinue{AuthData: []byte(schema + "\x00root\x00")})
+	for {
+		tp, payload := cli.recv()
+		if tp == Mysqlx.ServerMessages_SESS_AUTHENTICATE_OK {
+			break
+		}
+		c.Assert(tp, Equals, Mysqlx.ServerMessages_NOTICE, Commentf("%s", payload))
+	}
+	return cli
+}
+
+func (cli *testClient) close() {
+	cli.send(Mysqlx.ClientMessages_CON_CLOSE, &Mysqlx_Session.Close{})
+	tp, _ := cli.recv()
+	cli.c.Assert(tp, Equals, Mysqlx.ServerMessages_OK)
+	cli.conn.Close()
+}
+
+func (cli *testClient) send(tp Mysqlx.ClientMessages_Type, msg proto.Message) {
+	data, err := proto.Marshal(msg)
+	cli.c.Assert(err, IsNil)
+	cli.c.Assert(cli.pkt.writePacket(byte(tp), data), IsNil)
+	cli.c.Assert(cli.pkt.flush(), IsNil)
+}
+
+func (cli *testClient) recv() (Mysqlx.ServerMessages_Type, []byte) {
+	tp, payload, err := cli.pkt.readPacket()
+	cli.c.Assert(err, IsNil)
+	return Mysqlx.ServerMessages_Type(tp), payload
+}
+
+// result reads the messages until Mysqlx.Sql.StmtExecuteOk, the values of the rows are returned
+// as strings. If an error is received, its message is returned as the error.
+func (cli *testClient) result() ([][]string, string) {
+	var rows [][]string
+	for {
+		tp, payload := cli.recv()
+		switch tp {
+		case Mysqlx.ServerMessages_SQL_STMT_EXECUTE_OK:
+			return rows, ""
+		case Mysqlx.ServerMessages_ERROR:
+			var msg Mysqlx.Error
+			cli.c.Assert(proto.Unmarshal(payload, &msg), IsNil)
+			return nil, msg.GetMsg()
+		case Mysqlx.ServerMessages_RESULTSET_ROW:
+			var msg Mysqlx_Resultset.Row
+			cli.c.Assert(proto.Unmarshal(payload, &msg), IsNil)
+			var row []string
+			for _, field := range msg.GetField() {
+				// The values of the columns in test are all strings, which end with '\0'.
+				row = append(row, string(field[:len(field)-1]))
+			}
+			rows = append(rows, row)
+		}
+	}
+}
+
+func (cli *testClient) execute(namespace, stmt string, args ...*Mysqlx_Datatypes.Any) ([][]string, string) {
+	cli.send(Mysqlx.ClientMessages_SQL_STMT_EXECUTE, &Mysqlx_Sql.StmtExecute{
+		Namespace: proto.String(namespace),
+		Stmt:      []byte(stmt),
+		Args:      args,
+	})
+	return cli.result()
+}
+
+func (cli *testClient) mustExecSQL(sql string) [][]string {
+	rows, msg := cli.execute(namespaceSQL, sql)
+	cli.c.Assert(msg, Equals, "", Commentf("%s", sql))
+	return rows
+}
+
+func (s *testServerSuite) TestSQL(c *C) {
+	cli := s.connect(c, "test")
+	defer cli.close()
+	cli.mustExecSQL("create table xsql (a int, b varchar(10))")
+	cli.mustExecSQL("insert into xsql values (1, 'x'), (2, 'y')")
+	rows, msg := cli.execute(namespaceSQL, "select b from xsql where a = ?", anyString("2"))
+	c.Assert(msg, Equals, "")
+	c.Assert(rows, DeepEquals, [][]string{{"y"}})
+	_, msg = cli.execute(namespaceSQL, "select * from no_such_table")
+	c.Assert(msg, Equals, "Table 'test.no_such_table' doesn't exist")
+	// The connection is still usable after an error.
+	rows = cli.mustExecSQL("select b from xsql order by a")
+	c.Assert(rows, DeepEquals, [][]string{{"x"}, {"y"}})
+	_, msg = cli.execute(namespaceMysqlx, "no_such_command")
+	c.Assert(msg, Equals, "Unknown mysqlx statement no_such_command")
+	_, msg = cli.execute(namespaceMysqlx, "ping")
+	c.Assert(msg, Equals, "")
+}
+
+func (s *testServerSuite) TestCollection(c *C) {
+	cli := s.connect(c, "test")
+	defer cli.close()
+	_, msg := cli.execute(namespaceMysqlx, "create_collection", anyString("test"), anyString("xcoll"))
+	c.Assert(msg, Equals, "")
+	rows, msg := cli.execute(namespaceMysqlx, "list_objects", anyString("test"), anyString("xcoll"))
+	c.Assert(msg, Equals, "")
+	c.Assert(rows, DeepEquals, [][]string{{"xcoll", "COLLECTION"}})
+
+	collection := &Mysqlx_Crud.Collection{Schema: proto.String("test"), Name: proto.String("xcoll")}
+	docModel := Mysqlx_Crud.DataModel_DOCUMENT.Enum()
+	jsonDoc := func(doc string) *Mysqlx_Expr.Expr {
+		return literal(&Mysqlx_Datatypes.Scalar{
+			Type:    Mysqlx_Datatypes.Scalar_V_OCTETS.Enum(),
+			VOctets: &Mysqlx_Datatypes.Scalar_Octets{Value: []byte(doc), ContentType: proto.Uint32(contentTypeJSON)},
+		})
+	}
+	cli.send(Mysqlx.ClientMessages_CRUD_INSERT, &Mysqlx_Crud.Insert{
+		Collection: collection,
+		DataModel:  docModel,
+		Row: []*Mysqlx_Crud.Insert_TypedRow{
+			{Field: []*Mysqlx_Expr.Expr{jsonDoc(`{"_id": "1", "name": "a", "age": 10}`)}},
+			{Field: []*Mysqlx_Expr.Expr{jsonDoc(`{"_id": "2", "name": "b", "age": 20}`)}},
+			{Field: []*Mysqlx_Expr.Expr{jsonDoc(`{"name": "c", "age": 30}`)}},
+		},
+	})
+	_, msg = cli.result()
+	c.Assert(msg, Equals, "")
+
+	// The _id of the third document is generated.
+	rows = cli.mustExecSQL("select _id from xcoll where json_extract(doc, '$.name') = 'c'")
+	c.Assert(rows, HasLen, 1)
+	c.Assert(rows[0][0], HasLen, 32)
+
+	find := func(criteria *Mysqlx_Expr.Expr) []map[string]interface{} {
+		cli.send(Mysqlx.ClientMessages_CRUD_FIND, &Mysqlx_Crud.Find{
+			Collection: collection,
+			DataModel:  docModel,
+			Projection: []*Mysqlx_Crud.Projection{{Source: docIdent("name")}, {Source: docIdent("age"), Alias: proto.String("years")}},
+			Criteria:   criteria,
+			Args:       []*Mysqlx_Datatypes.Scalar{scalarInt(15)},
+			Order:      []*Mysqlx_Crud.Order{{Expr: docIdent("age"), Direction: Mysqlx_Crud.Order_DESC.Enum()}},
+		})
+		rows, msg := cli.result()
+		c.Assert(msg, Equals, "")
+		var docs []map[string]interface{}
+		for _, row := range rows {
+			doc := make(map[string]interface{})
+			c.Assert(json.Unmarshal([]byte(row[0]), &doc), IsNil)
+			docs = append(docs, doc)
+		}
+		return docs
+	}
+	docs := find(operator(">", docIdent("age"), placeholder(0)))
+	c.Assert(docs, DeepEquals, []map[string]interface{}{
+		{"name": "c", "years": float64(30)},
+		{"name": "b", "years": float64(20)},
+	})
+
+	cli.send(Mysqlx.ClientMessages_CRUD_UPDATE, &Mysqlx_Crud.Update{
+		Collection: collection,
+		DataModel:  docModel,
+		Criteria:   operator("==", docIdent("_id"), literal(scalarStr("1"))),
+		Operation: []*Mysqlx_Crud.UpdateOperation{{
+			Source:    &Mysqlx_Expr.ColumnIdentifier{DocumentPath: member("age")},
+			Operation: Mysqlx_Crud.UpdateOperation_ITEM_SET.Enum(),
+			Value:     literal(scalarInt(40)),
+		}},
+	})
+	_, msg = cli.result()
+	c.Assert(msg, Equals, "")
+	docs = find(operator(">", docIdent("age"), placeholder(0)))
+	c.Assert(docs, HasLen, 3)
+	c.Assert(docs[0], DeepEquals, map[string]interface{}{"name": "a", "years": float64(40)})
+
+	cli.send(Mysqlx.ClientMessages_CRUD_UPDATE, &Mysqlx_Crud.Update{
+		Collection: collection,
+		DataModel:  docModel,
+		Operation: []*Mysqlx_Crud.UpdateOperation{{
+			Source:    &Mysqlx_Expr.ColumnIdentifier{DocumentPath: member("_id")},
+			Operation: Mysqlx_Crud.UpdateOperation_ITEM_SET.Enum(),
+			Value:     literal(scalarStr("x")),
+		}},
+	})
+	_, msg = cli.result()
+	c.Assert(msg, Equals, "Forbidden update operation on '$._id' member")
+
+	cli.send(Mysqlx.ClientMessages_CRUD_DELETE, &Mysqlx_Crud.Delete{
+		Collection: collection,
+		DataModel:  docModel,
+		Criteria:   operator("<", docIdent("age"), literal(scalarInt(35))),
+	})
+	_, msg = cli.result()
+	c.Assert(msg, Equals, "")
+	docs = find(nil)
+	c.Assert(docs, DeepEquals, []map[string]interface{}{{"name": "a", "years": float64(40)}})
+
+	_, msg = cli.execute(namespaceMysqlx, "drop_collection", anyString("test"), anyString("xcoll"))
+	c.Assert(msg, Equals, "")
+}
+
+func (s *testServerSuite) TestExpect(c *C) {
+	cli := s.connect(c, "test")
+	defer cli.close()
+	cli.send(Mysqlx.ClientMessages_EXPECT_OPEN, &Mysqlx_Expect.Open{
+		Cond: []*Mysqlx_Expect.Open_Condition{{ConditionKey: proto.Uint32(expectNoError)}},
+	})
+	tp, _ := cli.recv()
+	c.Assert(tp, Equals, Mysqlx.ServerMessages_OK)
+	_, msg := cli.execute(namespaceSQL, "select * from no_such_table")
+	c.Assert(msg, Not(Equals), "")
+	// The following messages in the block fail after an error.
+	_, msg = cli.execute(namespaceSQL, "select 1")
+	c.Assert(msg, Equals, "Expectation failed: no_error")
+	cli.send(Mysqlx.ClientMessages_EXPECT_CLOSE, &Mysqlx_Expect.Close{})
+	tp, _ = cli.recv()
+	c.Assert(tp, Equals, Mysqlx.ServerMessages_ERROR)
+	rows := cli.mustExecSQL("select 1")
+	c.Assert(rows, HasLen, 1)
+}
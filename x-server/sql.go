@@ -0,0 +1,207 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xserver
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/server"
+	"github.com/pingcap/tipb/go-mysqlx"
+	"github.com/pingcap/tipb/go-mysqlx/Datatypes"
+	"github.com/pingcap/tipb/go-mysqlx/Notice"
+	"github.com/pingcap/tipb/go-mysqlx/Sql"
+)
+
+// Namespaces of Mysqlx.Sql.StmtExecute.
+const (
+	namespaceSQL     = "sql"
+	namespaceXPlugin = "xplugin"
+	namespaceMysqlx  = "mysqlx"
+)
+
+func (cc *clientConn) handleStmtExecute(payload []byte) error {
+	var msg Mysqlx_Sql.StmtExecute
+	if err := proto.Unmarshal(payload, &msg); err != nil {
+		return errors.Trace(errBadMessage)
+	}
+	switch msg.GetNamespace() {
+	case namespaceSQL:
+		args := make([]interface{}, 0, len(msg.GetArgs()))
+		for i, arg := range msg.GetArgs() {
+			if arg.GetType() != Mysqlx_Datatypes.Any_SCALAR {
+				return errCmdArgumentType.GenByArgs("args", i+1, "scalar")
+			}
+			args = append(args, scalarArg(arg.GetScalar()))
+		}
+		return errors.Trace(cc.executeSQL(string(msg.GetStmt()), args...))
+	case namespaceXPlugin, namespaceMysqlx:
+		return errors.Trace(cc.handleAdminCommand(string(msg.GetStmt()), msg.GetArgs()))
+	default:
+		return errInvalidNamespace.GenByArgs(msg.GetNamespace())
+	}
+}
+
+// executeSQL executes the sql and writes the results. If args is not empty,
+// the sql is executed as a prepared statement with args as the parameters.
+func (cc *clientConn) executeSQL(sql string, args ...interface{}) error {
+	var (
+		rss []server.ResultSet
+		err error
+	)
+	if len(args) == 0 {
+		rss, err = cc.ctx.Execute(sql)
+	} else {
+		rss, err = cc.executePrepared(sql, args)
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for i, rs := range rss {
+		if err = cc.writeResultset(rs, i != len(rss)-1); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(cc.writeStmtExecuteOK(len(rss) == 0))
+}
+
+func (cc *clientConn) executePrepared(sql string, args []interface{}) ([]server.ResultSet, error) {
+	stmt, _, _, err := cc.ctx.Prepare(sql)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer stmt.Close()
+	rs, err := stmt.Execute(args...)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if rs == nil {
+		return nil, nil
+	}
+	return []server.ResultSet{rs}, nil
+}
+
+// writeStmtExecuteOK writes the notices of the execution and Mysqlx.Sql.StmtExecuteOk, then flushes the stream.
+// The affected rows and the last insert ID are sent only if the statement doesn't return result set.
+func (cc *clientConn) writeStmtExecuteOK(noResultset bool) error {
+	if noResultset {
+		err := cc.writeSessionStateChanged(Mysqlx_Notice.SessionStateChanged_ROWS_AFFECTED, scalarUint(cc.ctx.AffectedRows()))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if id := cc.ctx.LastInsertID(); id > 0 {
+			err = cc.writeSessionStateChanged(Mysqlx_Notice.SessionStateChanged_GENERATED_INSERT_ID, scalarUint(id))
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	if err := cc.writeMessage(Mysqlx.ServerMessages_SQL_STMT_EXECUTE_OK, &Mysqlx_Sql.StmtExecuteOk{}); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cc.flush())
+}
+
+// handleAdminCommand handles the statements in "xplugin" or "mysqlx" namespace.
+func (cc *clientConn) handleAdminCommand(cmd string, args []*Mysqlx_Datatypes.Any) error {
+	switch cmd {
+	case "ping":
+		if _, err := adminStringArgs(args, 0); err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(cc.writeStmtExecuteOK(false))
+	case "create_collection", "ensure_collection":
+		names, err := adminStringArgs(args, 2, "schema", "name")
+		if err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(cc.createCollection(names[0], names[1], cmd == "ensure_collection"))
+	case "drop_collection":
+		names, err := adminStringArgs(args, 2, "schema", "name")
+		if err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(cc.executeSQL("DROP TABLE " + qualifiedName(names[0], names[1])))
+	case "list_objects":
+		names, err := adminStringArgs(args, 0, "schema", "pattern")
+		if err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(cc.listObjects(names))
+	case "enable_notices", "disable_notices":
+		// Only the session state notices are sent, which are always enabled.
+		return errors.Trace(cc.writeStmtExecuteOK(false))
+	default:
+		return errInvalidAdminCommand.GenByArgs(cmd)
+	}
+}
+
+// createCollection creates a collection, which is a table with a JSON column "doc" for
+// the documents, and a generated column "_id" for the unique ID of documents.
+func (cc *clientConn) createCollection(schema, name string, ifNotExists bool) error {
+	sql := "CREATE TABLE "
+	if ifNotExists {
+		sql += "IF NOT EXISTS "
+	}
+	sql += qualifiedName(schema, name) + " (doc JSON, " +
+		"_id VARCHAR(32) GENERATED ALWAYS AS (JSON_UNQUOTE(JSON_EXTRACT(doc, '$._id'))) STORED NOT NULL, " +
+		"UNIQUE KEY (_id)) CHARSET utf8mb4"
+	return errors.Trace(cc.executeSQL(sql))
+}
+
+// listObjects lists the tables and the collections in the schema, whose names match the pattern.
+func (cc *clientConn) listObjects(args []string) error {
+	schema := "DATABASE()"
+	if len(args) > 0 && args[0] != "" {
+		schema = quoteString(args[0])
+	}
+	sql := "SELECT t.table_name AS name, " +
+		"IF(t.table_type = 'VIEW', 'VIEW', IF(COUNT(c.column_name) = 2, 'COLLECTION', 'TABLE')) AS type " +
+		"FROM information_schema.tables AS t LEFT JOIN information_schema.columns AS c " +
+		"ON t.table_schema = c.table_schema AND t.table_name = c.table_name AND " +
+		"((c.column_name = 'doc' AND c.data_type = 'json') OR c.column_name = '_id') " +
+		"WHERE t.table_schema = " + schema
+	if len(args) > 1 {
+		sql += " AND t.table_name LIKE " + quoteString(args[1])
+	}
+	sql += " GROUP BY t.table_name, t.table_type ORDER BY t.table_name"
+	return errors.Trace(cc.executeSQL(sql))
+}
+
+// adminStringArgs checks the arguments of an admin command and converts them to strings,
+// names are the names of the arguments, and the first min arguments are required.
+func adminStringArgs(args []*Mysqlx_Datatypes.Any, min int, names ...string) ([]string, error) {
+	if len(args) < min {
+		return nil, errCmdNumArguments.GenByArgs(min, len(args))
+	}
+	if len(args) > len(names) {
+		return nil, errCmdNumArguments.GenByArgs(len(names), len(args))
+	}
+	strs := make([]string, 0, len(args))
+	for i, arg := range args {
+		s, ok := scalarString(arg.GetScalar())
+		if !ok || arg.GetType() != Mysqlx_Datatypes.Any_SCALAR {
+			return nil, errCmdArgumentType.GenByArgs(names[i], i+1, "string")
+		}
+		strs = append(strs, s)
+	}
+	return strs, nil
+}
+
+// qualifiedName returns the quoted name of the table in the schema.
+func qualifiedName(schema, table string) string {
+	if schema == "" {
+		return quoteIdentifier(table)
+	}
+	return quoteIdentifier(schema) + "." + quoteIdentifier(table)
+}
@@ -0,0 +1,124 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xserver
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pingcap/tipb/go-mysqlx/Datatypes"
+)
+
+func anyString(s string) *Mysqlx_Datatypes.Any {
+	return &Mysqlx_Datatypes.Any{
+		Type: Mysqlx_Datatypes.Any_SCALAR.Enum(),
+		Scalar: &Mysqlx_Datatypes.Scalar{
+			Type:    Mysqlx_Datatypes.Scalar_V_STRING.Enum(),
+			VString: &Mysqlx_Datatypes.Scalar_String{Value: []byte(s)},
+		},
+	}
+}
+
+func anyBool(b bool) *Mysqlx_Datatypes.Any {
+	return &Mysqlx_Datatypes.Any{
+		Type: Mysqlx_Datatypes.Any_SCALAR.Enum(),
+		Scalar: &Mysqlx_Datatypes.Scalar{
+			Type:  Mysqlx_Datatypes.Scalar_V_BOOL.Enum(),
+			VBool: proto.Bool(b),
+		},
+	}
+}
+
+func scalarUint(v uint64) *Mysqlx_Datatypes.Scalar {
+	return &Mysqlx_Datatypes.Scalar{
+		Type:         Mysqlx_Datatypes.Scalar_V_UINT.Enum(),
+		VUnsignedInt: proto.Uint64(v),
+	}
+}
+
+// scalarBool gets a bool from the scalar, integers are also accepted.
+func scalarBool(s *Mysqlx_Datatypes.Scalar) (bool, bool) {
+	switch s.GetType() {
+	case Mysqlx_Datatypes.Scalar_V_BOOL:
+		return s.GetVBool(), true
+	case Mysqlx_Datatypes.Scalar_V_SINT:
+		return s.GetVSignedInt() != 0, true
+	case Mysqlx_Datatypes.Scalar_V_UINT:
+		return s.GetVUnsignedInt() != 0, true
+	}
+	return false, false
+}
+
+// scalarString gets a string from the scalar, octets are also accepted.
+func scalarString(s *Mysqlx_Datatypes.Scalar) (string, bool) {
+	switch s.GetType() {
+	case Mysqlx_Datatypes.Scalar_V_STRING:
+		return string(s.GetVString().GetValue()), true
+	case Mysqlx_Datatypes.Scalar_V_OCTETS:
+		return string(s.GetVOctets().GetValue()), true
+	}
+	return "", false
+}
+
+// scalarArg converts the scalar to an argument of prepared statement.
+func scalarArg(s *Mysqlx_Datatypes.Scalar) interface{} {
+	switch s.GetType() {
+	case Mysqlx_Datatypes.Scalar_V_SINT:
+		return s.GetVSignedInt()
+	case Mysqlx_Datatypes.Scalar_V_UINT:
+		return s.GetVUnsignedInt()
+	case Mysqlx_Datatypes.Scalar_V_DOUBLE:
+		return s.GetVDouble()
+	case Mysqlx_Datatypes.Scalar_V_FLOAT:
+		return s.GetVFloat()
+	case Mysqlx_Datatypes.Scalar_V_BOOL:
+		return s.GetVBool()
+	case Mysqlx_Datatypes.Scalar_V_STRING:
+		return string(s.GetVString().GetValue())
+	case Mysqlx_Datatypes.Scalar_V_OCTETS:
+		return s.GetVOctets().GetValue()
+	}
+	return nil
+}
+
+// quoteIdentifier quotes an identifier with backticks.
+func quoteIdentifier(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+// quoteString quotes a string literal with single quotes, the special characters are escaped.
+func quoteString(s string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case 0:
+			buf.WriteString(`\0`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\032':
+			buf.WriteString(`\Z`)
+		case '\'', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte('\'')
+	return buf.String()
+}